@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/install"
 	"github.com/ctagard/dap-mcp/internal/mcp"
 	"github.com/ctagard/dap-mcp/internal/version"
 )
@@ -19,6 +22,8 @@ func main() {
 	mode := flag.String("mode", "full", "Capability mode: 'readonly' or 'full'")
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	checkUpdate := flag.Bool("check-update", false, "Check for updates and exit")
+	installLang := flag.String("install", "", "Install the debug adapter for a language (go, python, javascript) and exit")
+	writeConfig := flag.Bool("write-config", false, "With -install, write the installed adapter path into the -config file")
 	help := flag.Bool("help", false, "Show help and exit")
 
 	flag.Parse()
@@ -51,6 +56,10 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *installLang != "" {
+		os.Exit(runInstall(*installLang, *configPath, *writeConfig))
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
@@ -64,6 +73,15 @@ func main() {
 		cfg.Mode = config.ModeFull
 	}
 
+	// Refuse to start with settings the server can't run with
+	warnings, err := cfg.Validate()
+	for _, warning := range warnings {
+		log.Printf("Configuration warning: %s", warning)
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	// Start version check in background
 	versionChecker := version.NewChecker()
 	versionChecker.CheckForUpdatesAsync()
@@ -77,6 +95,8 @@ func main() {
 
 	go func() {
 		<-sigCh
+		// Close terminates launched debuggees and detaches from attached ones
+		// (unless terminateAttachedOnShutdown is set)
 		log.Println("Shutting down...")
 		server.Close()
 		os.Exit(0)
@@ -91,6 +111,46 @@ func main() {
 	server.Close()
 }
 
+// runInstall installs a language's debug adapter, printing the installer's
+// progress, and returns the process exit code
+func runInstall(language, configPath string, writeConfig bool) int {
+	if writeConfig && configPath == "" {
+		fmt.Fprintln(os.Stderr, "-write-config requires -config <path>")
+		return 2
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	opts := install.Options{Progress: os.Stderr}
+	if language == "python" {
+		cfg, err := config.LoadConfig(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		if cfg != nil {
+			opts.PythonPath = cfg.Adapters.Python.PythonPath
+		}
+	}
+
+	result, err := install.Install(context.Background(), language, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Installed %s for %s: %s\n", result.Adapter, result.Language, result.Path)
+	if writeConfig {
+		if err := config.SetFileValue(configPath, result.ConfigKey, result.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", configPath, err)
+			return 1
+		}
+		fmt.Printf("Set %s in %s\n", result.ConfigKey, configPath)
+	} else {
+		fmt.Printf("Set %s to this path in your configuration file, or rerun with -write-config\n", result.ConfigKey)
+	}
+	return 0
+}
+
 func printHelp() {
 	fmt.Println(`DAP-MCP: Debug Adapter Protocol MCP Server
 
@@ -103,6 +163,9 @@ USAGE:
 OPTIONS:
     -config <path>     Path to configuration file (JSON)
     -mode <mode>       Capability mode: 'readonly' or 'full' (default: full)
+    -install <lang>    Install the debug adapter for go, python, or javascript
+                       and exit (Delve, debugpy, or vscode-js-debug)
+    -write-config      With -install, write the adapter path into the -config file
     -version           Show version and exit
     -help              Show this help message
 
@@ -156,6 +219,7 @@ TOOLS:
         debug_attach          Attach to an existing adapter
         debug_disconnect      End a debug session
         debug_list_sessions   List active sessions
+        debug_install_adapter Install a language's adapter (with allowInstall)
 
     Inspection (read-only):
         inspect_threads       Get all threads
@@ -1,6 +1,6 @@
 module github.com/ctagard/dap-mcp
 
-go 1.23
+go 1.23.0
 
 require (
 	github.com/google/go-dap v0.12.0
@@ -5,6 +5,10 @@
 //   - Go (via Delve)
 //   - Python (via debugpy)
 //   - JavaScript/TypeScript (via vscode-js-debug for both Node.js and browser targets)
+//   - C/C++/Rust (via lldb-dap, or GDB's native DAP mode)
+//   - Swift (via the Swift toolchain's lldb-dap)
+//   - Elixir/Erlang (via the ElixirLS debug adapter)
+//   - Any other native binary (the "native" target, via lldb-dap or GDB)
 //
 // The Registry type manages the collection of available adapters and provides
 // lookup by language. Adapters handle spawning debug adapter processes and
@@ -13,13 +17,19 @@ package adapters
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"log"
 	"net"
+	"os"
 	"os/exec"
+	"sort"
 	"time"
 
 	"github.com/ctagard/dap-mcp/internal/config"
 	"github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/internal/launchconfig"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
 
@@ -38,6 +48,44 @@ type Adapter interface {
 
 	// BuildAttachArgs builds the attach arguments for the debug adapter
 	BuildAttachArgs(args map[string]interface{}) map[string]interface{}
+
+	// DefaultTarget returns the debug target used when none is requested,
+	// or "" if the adapter has no targets
+	DefaultTarget() string
+
+	// SupportedTargets returns the debug targets the adapter accepts (e.g.
+	// node, chrome, edge), or nil if it has no targets
+	SupportedTargets() []string
+
+	// RequiresSpawn returns true if the adapter can't be reached on a port and
+	// must always be spawned by this server, including for attach
+	RequiresSpawn() bool
+}
+
+// SupportsTarget reports whether the adapter accepts the requested target.
+// An empty target always selects the adapter's default.
+func SupportsTarget(adapter Adapter, target string) bool {
+	if target == "" {
+		return true
+	}
+	for _, t := range adapter.SupportedTargets() {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// intArg returns an integer argument. Arguments decoded from JSON hold numbers
+// as float64, while the attach handler passes Go ints.
+func intArg(args map[string]interface{}, key string) (int, bool) {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
 }
 
 // StdioAdapter extends Adapter for adapters that communicate via stdin/stdout
@@ -53,9 +101,121 @@ type StdioAdapter interface {
 	SpawnStdio(ctx context.Context, program string, args map[string]interface{}) (client *dap.Client, cmd *exec.Cmd, err error)
 }
 
+// EntryBehavior describes how an adapter handles the stopOnEntry launch argument
+type EntryBehavior int
+
+const (
+	// EntryHonored means the adapter stops at entry exactly when stopOnEntry is set
+	EntryHonored EntryBehavior = iota
+	// EntryIgnored means the adapter never stops at entry, so an entry
+	// breakpoint has to stand in for stopOnEntry
+	EntryIgnored
+	// EntryForced means the adapter always stops at entry, so the program has
+	// to be continued when stopOnEntry is not set
+	EntryForced
+)
+
+// EntryAdapter is implemented by adapters that don't simply honor stopOnEntry
+type EntryAdapter interface {
+	Adapter
+
+	// EntryBehavior returns how the adapter handles stopOnEntry for a launch
+	// with the given arguments
+	EntryBehavior(args map[string]interface{}) EntryBehavior
+}
+
+// StopOnEntryBehavior returns how the adapter handles stopOnEntry for a launch.
+// Adapters that don't implement EntryAdapter honor it.
+func StopOnEntryBehavior(adapter Adapter, args map[string]interface{}) EntryBehavior {
+	if entryAdapter, ok := adapter.(EntryAdapter); ok {
+		return entryAdapter.EntryBehavior(args)
+	}
+	return EntryHonored
+}
+
+// EntryFunctionAdapter is implemented by adapters whose entry stop comes
+// before the program's own code, such as Delve's in the Go runtime startup
+type EntryFunctionAdapter interface {
+	Adapter
+
+	// EntryFunction returns the function stopOnEntry should stop in instead
+	// of the adapter's entry stop, or "" to keep the adapter's
+	EntryFunction(args map[string]interface{}) string
+}
+
+// EntryFunction returns the function stopOnEntry should stop in for a launch,
+// or "" if the adapter's own entry stop is used
+func EntryFunction(adapter Adapter, args map[string]interface{}) string {
+	if entryAdapter, ok := adapter.(EntryFunctionAdapter); ok {
+		return entryAdapter.EntryFunction(args)
+	}
+	return ""
+}
+
+// ConfigurationAdapter is implemented by adapters that may answer launch
+// without the initialized event and configurationDone handshake
+type ConfigurationAdapter interface {
+	Adapter
+
+	// RequiresConfigurationDone reports whether the adapter sends the
+	// initialized event and waits for configurationDone before it launches
+	RequiresConfigurationDone() bool
+}
+
+// RequiresConfigurationDone reports whether a launch should go through the
+// initialized/configurationDone handshake. Adapters that don't implement
+// ConfigurationAdapter require it.
+func RequiresConfigurationDone(adapter Adapter) bool {
+	if configurationAdapter, ok := adapter.(ConfigurationAdapter); ok {
+		return configurationAdapter.RequiresConfigurationDone()
+	}
+	return true
+}
+
+// WaitForAdapter is implemented by adapters that can attach to a process as
+// soon as one with a given name starts, instead of by PID
+type WaitForAdapter interface {
+	Adapter
+
+	// SupportsWaitFor reports whether attach accepts waitFor with a program name
+	SupportsWaitFor() bool
+}
+
+// SupportsWaitFor reports whether the adapter can wait for a process to start
+// and attach to it
+func SupportsWaitFor(adapter Adapter) bool {
+	if waitForAdapter, ok := adapter.(WaitForAdapter); ok {
+		return waitForAdapter.SupportsWaitFor()
+	}
+	return false
+}
+
+// SetupAdapter is implemented by adapters that apply some launch and attach
+// options with debugger commands rather than request arguments
+type SetupAdapter interface {
+	Adapter
+
+	// SetupCommands returns the commands to run in the repl context after
+	// initialize and before the launch or attach request
+	SetupCommands(args map[string]interface{}) []string
+}
+
+// SetupCommands returns the commands an adapter needs run before a launch or
+// attach with the given arguments
+func SetupCommands(adapter Adapter, args map[string]interface{}) []string {
+	if setupAdapter, ok := adapter.(SetupAdapter); ok {
+		return setupAdapter.SetupCommands(args)
+	}
+	return nil
+}
+
 // Registry holds all registered adapters
 type Registry struct {
 	adapters map[types.Language]Adapter
+
+	// Native debuggers, selectable per launch via an explicit debugger choice
+	lldb *LLDBAdapter
+	gdb  *GDBAdapter
 }
 
 // NewRegistry creates a new adapter registry with all supported adapters
@@ -75,16 +235,26 @@ func NewRegistry(cfg *config.Config) *Registry {
 	r.adapters[types.LanguageJavaScript] = nodeAdapter
 	r.adapters[types.LanguageTypeScript] = nodeAdapter
 
-	// Register LLDB adapter for native languages (C, C++, Rust)
+	// Register LLDB adapter for native languages (C, C++, others)
 	// LLDB is preferred on macOS and also works well on Linux
 	lldbAdapter := NewLLDBAdapter(cfg.Adapters.LLDB)
 	r.adapters[types.LanguageC] = lldbAdapter
 	r.adapters[types.LanguageCpp] = lldbAdapter
-	r.adapters[types.LanguageRust] = lldbAdapter
+	r.adapters[types.LanguageNative] = lldbAdapter
+	r.lldb = lldbAdapter
+
+	// Register Rust adapter (lldb-dap with the toolchain's Rust formatters, or CodeLLDB)
+	r.adapters[types.LanguageRust] = NewRustAdapter(cfg.Adapters.Rust, cfg.Adapters.LLDB)
+
+	// Register Swift adapter (lldb-dap from the Swift toolchain with Swift settings)
+	r.adapters[types.LanguageSwift] = NewSwiftAdapter(cfg.Adapters.Swift, cfg.Adapters.LLDB)
+
+	// Register Elixir adapter (ElixirLS debug adapter, also debugs Erlang in mix projects)
+	r.adapters[types.LanguageElixir] = NewElixirAdapter(cfg.Adapters.Elixir)
 
-	// GDB adapter is available as an alternative via explicit configuration
-	// Users can override the default LLDB adapter by specifying gdb in launch.json
-	// or by modifying the registry after creation
+	// GDB adapter is available as an alternative via an explicit debugger choice
+	// (see GetWithDebugger) or by modifying the registry after creation
+	r.gdb = NewGDBAdapter(cfg.Adapters.GDB)
 
 	return r
 }
@@ -98,11 +268,47 @@ func (r *Registry) Get(lang types.Language) (Adapter, error) {
 	return adapter, nil
 }
 
+// GetWithDebugger returns the adapter for a language, honoring an explicit
+// native debugger choice ("lldb" or "gdb"). An empty debugger behaves like Get.
+// A debugger can only be chosen for C, C++, Rust and native targets.
+func (r *Registry) GetWithDebugger(lang types.Language, debugger string) (Adapter, error) {
+	if debugger == "" {
+		return r.Get(lang)
+	}
+
+	switch lang {
+	case types.LanguageC, types.LanguageCpp, types.LanguageRust, types.LanguageNative:
+	default:
+		return nil, fmt.Errorf("debugger selection is not supported for language: %s", lang)
+	}
+
+	switch debugger {
+	case "lldb":
+		if lang == types.LanguageRust {
+			return r.Get(lang) // Keeps the Rust formatters
+		}
+		return r.lldb, nil
+	case "gdb":
+		return r.gdb, nil
+	}
+	return nil, fmt.Errorf("unknown debugger %q: expected \"lldb\" or \"gdb\"", debugger)
+}
+
 // Register registers an adapter for a language, overriding any existing adapter
 func (r *Registry) Register(lang types.Language, adapter Adapter) {
 	r.adapters[lang] = adapter
 }
 
+// Languages returns the languages with a registered adapter, sorted
+func (r *Registry) Languages() []types.Language {
+	languages := make([]types.Language, 0, len(r.adapters))
+	for lang := range r.adapters {
+		languages = append(languages, lang)
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i] < languages[j] })
+	return languages
+}
+
 // GetGDBAdapter returns a GDB adapter (useful when user explicitly wants GDB over LLDB)
 func (r *Registry) GetGDBAdapter(cfg config.GDBConfig) *GDBAdapter {
 	return NewGDBAdapter(cfg)
@@ -113,28 +319,120 @@ func (r *Registry) GetLLDBAdapter(cfg config.LLDBConfig) *LLDBAdapter {
 	return NewLLDBAdapter(cfg)
 }
 
-// Connect creates a DAP client connected to the given address via TCP
+// Connect creates a DAP client connected to the given address via TCP and
+// initialized. It retries with exponential backoff and returns an
+// AdapterConnectFailed error once all retries are exhausted, or a
+// DAPInitFailed error if the adapter doesn't answer initialize.
 func Connect(address string, maxRetries int) (*dap.Client, error) {
+	return connect(address, maxRetries, nil, nil)
+}
+
+// connect dials the adapter and sends initialize. Some adapters accept
+// connections before they are ready and close the first one without
+// answering, so the adapter is re-dialed with backoff, up to
+// initializeAttempts times; an adapter that answers initialize with an error
+// is not retried. If exited is non-nil, retrying stops as soon as the adapter
+// process exits. If stderr is non-nil, its captured lines are included in the
+// returned error.
+func connect(address string, maxRetries int, exited <-chan struct{}, stderr *dap.AdapterLog) (*dap.Client, error) {
+	delay := connectInitialBackoff
+	for attempt := 1; ; attempt++ {
+		client, err := dial(address, maxRetries, exited, stderr)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = client.Initialize(dap.ClientID, dap.ClientName)
+		if err == nil {
+			return client, nil
+		}
+		_ = client.Close()
+
+		closed := stderrors.Is(err, dap.ErrAdapterGone)
+		if closed && attempt < initializeAttempts && !hasExited(exited) {
+			log.Printf("DAP adapter at %s closed the connection before answering initialize (attempt %d/%d); reconnecting", address, attempt, initializeAttempts)
+			select {
+			case <-exited:
+			case <-time.After(delay):
+			}
+			delay *= 2
+			continue
+		}
+
+		var stderrLines []string
+		if stderr != nil {
+			stderrLines = stderr.Tail()
+		}
+		return nil, errors.DAPInitFailed(err, stderrLines, closed)
+	}
+}
+
+// hasExited reports whether a watched adapter process has exited. A nil
+// channel means the process isn't watched.
+func hasExited(exited <-chan struct{}) bool {
+	select {
+	case <-exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// dial connects to the adapter with exponential backoff. If exited is
+// non-nil, retrying stops as soon as the adapter process exits.
+func dial(address string, maxRetries int, exited <-chan struct{}, stderr *dap.AdapterLog) (*dap.Client, error) {
 	var transport *dap.Transport
 	var err error
+	processExited := false
+	delay := connectInitialBackoff
 
 	for i := 0; i < maxRetries; i++ {
 		transport, err = dap.NewTCPTransport(address)
 		if err == nil {
+			return dap.NewClient(transport), nil
+		}
+		if i == maxRetries-1 {
 			break
 		}
-		// Increase delay between retries to give the adapter more time
-		time.Sleep(200 * time.Millisecond)
-	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to debug adapter at %s: %w", address, err)
+		// Back off before the next attempt, giving up early if the adapter died
+		select {
+		case <-exited:
+			processExited = true
+		case <-time.After(delay):
+		}
+		if processExited {
+			break
+		}
+		delay *= 2
+		if delay > connectMaxBackoff {
+			delay = connectMaxBackoff
+		}
 	}
 
-	client := dap.NewClient(transport)
-	return client, nil
+	// A nil channel never fires, so this only reports exits we can observe
+	processExited = processExited || hasExited(exited)
+
+	var stderrLines []string
+	if stderr != nil {
+		stderrLines = stderr.Tail()
+	}
+	if err == nil {
+		err = fmt.Errorf("no connection attempts made")
+	}
+	return nil, errors.AdapterConnectFailed(address, err, stderrLines, processExited)
 }
 
+// Backoff bounds for connecting to a spawned adapter
+const (
+	connectInitialBackoff = 100 * time.Millisecond
+	connectMaxBackoff     = 1 * time.Second
+)
+
+// initializeAttempts bounds the connections made to an adapter that closes
+// them before answering initialize
+const initializeAttempts = 3
+
 // SpawnAndConnect spawns an adapter and returns a connected client.
 // For stdio-based adapters, it connects via stdin/stdout pipes.
 // For TCP-based adapters, it connects via the returned address.
@@ -150,8 +448,16 @@ func SpawnAndConnect(ctx context.Context, adapter Adapter, program string, args
 		return nil, nil, err
 	}
 
-	// Connect to the adapter (20 retries * 200ms = 4 seconds max wait)
-	client, err := Connect(address, 20)
+	// Watch the process so we stop retrying as soon as it dies
+	var exited <-chan struct{}
+	var stderr *dap.AdapterLog
+	if cmd != nil && cmd.Process != nil {
+		exited = watchProcess(cmd)
+		stderr, _ = cmd.Stderr.(*dap.AdapterLog)
+	}
+
+	// Connect to the adapter (12 retries with backoff = roughly 9 seconds max wait)
+	client, err := connect(address, 12, exited, stderr)
 	if err != nil {
 		// Kill the spawned process if we can't connect
 		if cmd != nil && cmd.Process != nil {
@@ -163,6 +469,43 @@ func SpawnAndConnect(ctx context.Context, adapter Adapter, program string, args
 	return client, cmd, nil
 }
 
+// DefaultEnvArg is the launch argument holding the configured default
+// environment (map[string]string) for the spawned adapter process. The
+// debuggee receives the same variables through "env".
+const DefaultEnvArg = "defaultEnv"
+
+// WorkspaceFolderArg is the launch argument holding the workspace folder of a
+// launch.json launch, for resolving ${workspaceFolder} in adapter settings
+const WorkspaceFolderArg = "workspaceFolder"
+
+// adapterEnv returns the environment for a spawned adapter process: the
+// server's own environment with the default environment from args applied
+func adapterEnv(args map[string]interface{}) []string {
+	env := os.Environ()
+	defaults, _ := args[DefaultEnvArg].(map[string]string)
+	keys := make([]string, 0, len(defaults))
+	for k := range defaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// exec uses the last value of a repeated variable
+	for _, k := range keys {
+		env = append(env, k+"="+defaults[k])
+	}
+	return env
+}
+
+// watchProcess waits for cmd in the background and returns a channel that is
+// closed once the process has exited. This also reaps the process.
+func watchProcess(cmd *exec.Cmd) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait() // Error ignored: only the exit itself matters here
+		close(done)
+	}()
+	return done
+}
+
 // findAvailablePort finds an available TCP port
 func findAvailablePort() (int, error) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -177,3 +520,95 @@ func findAvailablePort() (int, error) {
 	}
 	return addr.Port, nil
 }
+
+// ProgramArgs returns a launch's program arguments and whether any were given.
+// They may be an array (from tool JSON or a resolved launch.json) or a
+// command-line string, which is split like a shell would. A string with an
+// unterminated quote is an error.
+func ProgramArgs(args map[string]interface{}) ([]string, bool, error) {
+	switch a := args["args"].(type) {
+	case []interface{}:
+		strArgs := make([]string, len(a))
+		for i, v := range a {
+			strArgs[i] = fmt.Sprint(v)
+		}
+		return strArgs, true, nil
+	case []string:
+		return a, true, nil
+	case string:
+		strArgs, err := launchconfig.SplitArgs(a)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid args string: %w", err)
+		}
+		return strArgs, true, nil
+	}
+	return nil, false, nil
+}
+
+// SourceMap returns a launch's source path remapping as [from, to] pairs and
+// whether one was given: from is a path prefix the program was built with
+// (e.g. in a container), to the local path it maps to. It may be an array of
+// pairs, lldb-dap's form, or an object mapping from to to, CodeLLDB's, whose
+// pairs are sorted by from.
+func SourceMap(args map[string]interface{}) ([][]string, bool, error) {
+	var pairs [][]string
+	switch m := args["sourceMap"].(type) {
+	case nil:
+		return nil, false, nil
+	case [][]string:
+		pairs = m
+	case []interface{}:
+		for _, entry := range m {
+			var pair []string
+			switch e := entry.(type) {
+			case []string:
+				pair = e
+			case []interface{}:
+				for _, p := range e {
+					path, ok := p.(string)
+					if !ok {
+						return nil, false, fmt.Errorf("sourceMap paths must be strings, got %v", p)
+					}
+					pair = append(pair, path)
+				}
+			}
+			pairs = append(pairs, pair)
+		}
+	case map[string]string:
+		for from, to := range m {
+			pairs = append(pairs, []string{from, to})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	case map[string]interface{}:
+		for from, to := range m {
+			path, ok := to.(string)
+			if !ok {
+				return nil, false, fmt.Errorf("sourceMap paths must be strings, got %v", to)
+			}
+			pairs = append(pairs, []string{from, path})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	default:
+		return nil, false, fmt.Errorf("sourceMap must be an array of [from, to] pairs or an object, got %T", m)
+	}
+	for _, pair := range pairs {
+		if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+			return nil, false, fmt.Errorf("sourceMap entries must be [from, to] pairs of paths, got %v", pair)
+		}
+	}
+	return pairs, len(pairs) > 0, nil
+}
+
+// sourceMapArg is SourceMap for building request arguments. The tools reject
+// invalid source maps before the adapter sees them.
+func sourceMapArg(args map[string]interface{}) ([][]string, bool) {
+	pairs, ok, err := SourceMap(args)
+	return pairs, ok && err == nil
+}
+
+// programArgs is ProgramArgs for building launch arguments. The launch
+// sequence rejects invalid args strings before the adapter sees them.
+func programArgs(args map[string]interface{}) ([]string, bool) {
+	strArgs, ok, err := ProgramArgs(args)
+	return strArgs, ok && err == nil
+}
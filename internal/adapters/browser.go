@@ -0,0 +1,83 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BrowserTab is a page target listed by a browser started with --remote-debugging-port
+type BrowserTab struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// ListBrowserTabs queries the Chrome DevTools HTTP endpoint of a running
+// Chrome or Edge and returns its open pages (service workers, extensions,
+// and other non-page targets are skipped)
+func ListBrowserTabs(ctx context.Context, host string, port int) ([]BrowserTab, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("http://%s:%d/json/list", host, port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+
+	var targets []BrowserTab
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", endpoint, err)
+	}
+
+	tabs := make([]BrowserTab, 0, len(targets))
+	for _, t := range targets {
+		if t.Type == "page" {
+			tabs = append(tabs, t)
+		}
+	}
+	return tabs, nil
+}
+
+// MatchBrowserTabs returns the tab whose URL equals pattern (ignoring a
+// trailing slash), if any, and all tabs matching pattern the way
+// vscode-js-debug's urlFilter does: '*' is a wildcard, and a pattern without
+// wildcards matches URLs that contain it
+func MatchBrowserTabs(tabs []BrowserTab, pattern string) (*BrowserTab, []BrowserTab) {
+	var exact *BrowserTab
+	for i := range tabs {
+		if strings.TrimSuffix(tabs[i].URL, "/") == strings.TrimSuffix(pattern, "/") {
+			exact = &tabs[i]
+			break
+		}
+	}
+
+	var re *regexp.Regexp
+	if strings.Contains(pattern, "*") {
+		re = regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+	}
+
+	var matches []BrowserTab
+	for _, tab := range tabs {
+		if (re != nil && re.MatchString(tab.URL)) || (re == nil && strings.Contains(tab.URL, pattern)) {
+			matches = append(matches, tab)
+		}
+	}
+	return exact, matches
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/dap"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
 
@@ -35,6 +36,21 @@ func (d *DebugpyAdapter) Language() types.Language {
 	return types.LanguagePython
 }
 
+// DefaultTarget returns "" because debugpy has no debug targets
+func (d *DebugpyAdapter) DefaultTarget() string {
+	return ""
+}
+
+// SupportedTargets returns nil because debugpy has no debug targets
+func (d *DebugpyAdapter) SupportedTargets() []string {
+	return nil
+}
+
+// RequiresSpawn returns false: debugpy can listen on a port that attach connects to
+func (d *DebugpyAdapter) RequiresSpawn() bool {
+	return false
+}
+
 // getPythonPath returns the Python interpreter path, checking args first for venv support.
 // Supports both VS Code's "python" attribute and debugpy's "pythonPath" attribute.
 func (d *DebugpyAdapter) getPythonPath(args map[string]interface{}) string {
@@ -84,7 +100,7 @@ func (d *DebugpyAdapter) Spawn(ctx context.Context, program string, args map[str
 	}
 
 	cmd := exec.CommandContext(ctx, pythonPath, cmdArgs...)
-	cmd.Env = os.Environ()
+	cmd.Env = adapterEnv(args)
 	// Explicitly disconnect stdin to prevent TTY issues when run as MCP server.
 	cmd.Stdin = nil
 	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
@@ -115,8 +131,8 @@ func (d *DebugpyAdapter) Spawn(ctx context.Context, program string, args map[str
 		cmd.Dir = cwd
 	}
 
-	// Capture stderr to help debug issues
-	cmd.Stderr = os.Stderr
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
 
 	if err := cmd.Start(); err != nil {
 		return "", nil, fmt.Errorf("failed to start debugpy: %w", err)
@@ -143,11 +159,7 @@ func (d *DebugpyAdapter) BuildLaunchArgs(program string, args map[string]interfa
 	}
 
 	// Pass through common arguments
-	if programArgs, ok := args["args"].([]any); ok {
-		strArgs := make([]string, len(programArgs))
-		for i, a := range programArgs {
-			strArgs[i] = fmt.Sprint(a)
-		}
+	if strArgs, ok := programArgs(args); ok {
 		launchArgs["args"] = strArgs
 	}
 
@@ -177,6 +189,15 @@ func (d *DebugpyAdapter) BuildLaunchArgs(program string, args map[string]interfa
 		launchArgs["pythonPath"] = pythonPath
 	}
 
+	// debugpy steps into library code only with justMyCode off (default: on)
+	if justMyCode, ok := args["justMyCode"].(bool); ok {
+		launchArgs["justMyCode"] = justMyCode
+	}
+
+	if pathMappings, ok := debugpyPathMappings(args); ok {
+		launchArgs["pathMappings"] = pathMappings
+	}
+
 	return launchArgs
 }
 
@@ -187,21 +208,49 @@ func (d *DebugpyAdapter) BuildAttachArgs(args map[string]interface{}) map[string
 		"request": "attach",
 	}
 
-	// Connect to a debugpy server
-	if host, ok := args["host"].(string); ok {
-		attachArgs["host"] = host
-	} else {
-		attachArgs["host"] = "127.0.0.1"
+	// Connect to a debugpy server, e.g. a program started with
+	// python -m debugpy --listen 5678
+	if port, ok := intArg(args, "port"); ok {
+		host, _ := args["host"].(string)
+		if host == "" {
+			host = "127.0.0.1"
+		}
+		attachArgs["connect"] = map[string]interface{}{
+			"host": host,
+			"port": port,
+		}
+	}
+
+	// Or attach to a process by PID
+	if pid, ok := intArg(args, "pid"); ok {
+		attachArgs["processId"] = pid
 	}
 
-	if port, ok := args["port"].(float64); ok {
-		attachArgs["port"] = int(port)
+	if justMyCode, ok := args["justMyCode"].(bool); ok {
+		attachArgs["justMyCode"] = justMyCode
 	}
 
-	// Or attach to a process by PID
-	if pid, ok := args["pid"].(float64); ok {
-		attachArgs["processId"] = int(pid)
+	if pathMappings, ok := debugpyPathMappings(args); ok {
+		attachArgs["pathMappings"] = pathMappings
 	}
 
 	return attachArgs
 }
+
+// debugpyPathMappings returns debugpy's pathMappings: those given as such
+// (e.g. by a launch.json configuration), or else the source map's, which maps
+// the program's paths (remoteRoot) to local ones (localRoot)
+func debugpyPathMappings(args map[string]interface{}) ([]interface{}, bool) {
+	if pathMappings, ok := args["pathMappings"].([]interface{}); ok {
+		return pathMappings, true
+	}
+	sourceMap, ok := sourceMapArg(args)
+	if !ok {
+		return nil, false
+	}
+	pathMappings := make([]interface{}, len(sourceMap))
+	for i, pair := range sourceMap {
+		pathMappings[i] = map[string]interface{}{"remoteRoot": pair[0], "localRoot": pair[1]}
+	}
+	return pathMappings, true
+}
@@ -3,18 +3,19 @@ package adapters
 import (
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"time"
 
 	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/dap"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
 
 // DelveAdapter implements the Adapter interface for Go/Delve
 type DelveAdapter struct {
-	dlvPath    string
-	buildFlags string
+	dlvPath       string
+	buildFlags    string
+	entryFunction string
 }
 
 // NewDelveAdapter creates a new Delve adapter
@@ -25,8 +26,9 @@ func NewDelveAdapter(cfg config.DelveConfig) *DelveAdapter {
 	}
 
 	return &DelveAdapter{
-		dlvPath:    dlvPath,
-		buildFlags: cfg.BuildFlags,
+		dlvPath:       dlvPath,
+		buildFlags:    cfg.BuildFlags,
+		entryFunction: cfg.EntryFunction,
 	}
 }
 
@@ -35,6 +37,21 @@ func (d *DelveAdapter) Language() types.Language {
 	return types.LanguageGo
 }
 
+// DefaultTarget returns "" because Delve has no debug targets
+func (d *DelveAdapter) DefaultTarget() string {
+	return ""
+}
+
+// SupportedTargets returns nil because Delve has no debug targets
+func (d *DelveAdapter) SupportedTargets() []string {
+	return nil
+}
+
+// RequiresSpawn returns false: Delve can listen on a port that attach connects to
+func (d *DelveAdapter) RequiresSpawn() bool {
+	return false
+}
+
 // Spawn starts a Delve debug adapter process
 func (d *DelveAdapter) Spawn(ctx context.Context, program string, args map[string]interface{}) (string, *exec.Cmd, error) {
 	port, err := findAvailablePort()
@@ -49,17 +66,17 @@ func (d *DelveAdapter) Spawn(ctx context.Context, program string, args map[strin
 		"--listen", address,
 	}
 
-	if d.buildFlags != "" {
-		dlvArgs = append(dlvArgs, "--build-flags", d.buildFlags)
+	if buildFlags := d.resolveBuildFlags(args); buildFlags != "" {
+		dlvArgs = append(dlvArgs, "--build-flags", buildFlags)
 	}
 
 	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
 	cmd := exec.CommandContext(ctx, d.dlvPath, dlvArgs...)
-	cmd.Env = os.Environ()
+	cmd.Env = adapterEnv(args)
 	// Explicitly disconnect stdin to prevent TTY issues when run as MCP server.
 	cmd.Stdin = nil
-	// Capture stderr to help debug issues
-	cmd.Stderr = os.Stderr
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
 	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
 	setProcAttr(cmd)
 
@@ -86,11 +103,7 @@ func (d *DelveAdapter) BuildLaunchArgs(program string, args map[string]interface
 	}
 
 	// Pass through common arguments
-	if programArgs, ok := args["args"].([]interface{}); ok {
-		strArgs := make([]string, len(programArgs))
-		for i, a := range programArgs {
-			strArgs[i] = fmt.Sprint(a)
-		}
+	if strArgs, ok := programArgs(args); ok {
 		launchArgs["args"] = strArgs
 	}
 
@@ -111,21 +124,36 @@ func (d *DelveAdapter) BuildLaunchArgs(program string, args map[string]interface
 	}
 
 	// Delve-specific options
-	if buildFlags, ok := args["buildFlags"].(string); ok {
+	if buildFlags := d.resolveBuildFlags(args); buildFlags != "" {
 		launchArgs["buildFlags"] = buildFlags
 	}
 
 	return launchArgs
 }
 
+// resolveBuildFlags returns the launch's buildFlags (from launch.json or the
+// debug_launch buildFlags parameter) if set, otherwise the configured default
+func (d *DelveAdapter) resolveBuildFlags(args map[string]interface{}) string {
+	if buildFlags, ok := args["buildFlags"].(string); ok && buildFlags != "" {
+		return buildFlags
+	}
+	return d.buildFlags
+}
+
+// EntryFunction returns the configured function stopOnEntry runs to, since
+// Delve's own entry stop is in the runtime before main.main
+func (d *DelveAdapter) EntryFunction(args map[string]interface{}) string {
+	return d.entryFunction
+}
+
 // BuildAttachArgs builds the attach arguments for Delve
 func (d *DelveAdapter) BuildAttachArgs(args map[string]interface{}) map[string]interface{} {
 	attachArgs := map[string]interface{}{
 		"mode": "local",
 	}
 
-	if pid, ok := args["pid"].(float64); ok {
-		attachArgs["processId"] = int(pid)
+	if pid, ok := intArg(args, "pid"); ok {
+		attachArgs["processId"] = pid
 	}
 
 	return attachArgs
@@ -0,0 +1,209 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// ElixirAdapter implements the StdioAdapter interface for Elixir and Erlang via
+// the ElixirLS debug adapter (debug_adapter.sh). ElixirLS runs programs as mix
+// tasks; each BEAM process shows up as a DAP thread.
+type ElixirAdapter struct {
+	debugAdapterPath string
+}
+
+// NewElixirAdapter creates a new Elixir adapter
+func NewElixirAdapter(cfg config.ElixirConfig) *ElixirAdapter {
+	path := cfg.ElixirLsPath
+	if path == "" {
+		path = "debug_adapter.sh"
+	}
+
+	return &ElixirAdapter{
+		debugAdapterPath: path,
+	}
+}
+
+// Language returns the language this adapter supports
+func (e *ElixirAdapter) Language() types.Language {
+	return types.LanguageElixir
+}
+
+// DefaultTarget returns "" because the ElixirLS debug adapter has no debug targets
+func (e *ElixirAdapter) DefaultTarget() string {
+	return ""
+}
+
+// SupportedTargets returns nil because the ElixirLS debug adapter has no debug targets
+func (e *ElixirAdapter) SupportedTargets() []string {
+	return nil
+}
+
+// RequiresSpawn returns true: the ElixirLS debug adapter only speaks DAP over stdio, so attach spawns it too
+func (e *ElixirAdapter) RequiresSpawn() bool {
+	return true
+}
+
+// IsStdio returns true because the ElixirLS debug adapter uses stdio transport
+func (e *ElixirAdapter) IsStdio() bool {
+	return true
+}
+
+// Spawn is implemented for interface compatibility but should not be called directly.
+// Use SpawnStdio instead for stdio-based adapters.
+func (e *ElixirAdapter) Spawn(ctx context.Context, program string, args map[string]interface{}) (string, *exec.Cmd, error) {
+	return "", nil, fmt.Errorf("elixir adapter uses stdio transport, use SpawnStdio instead")
+}
+
+// SpawnStdio starts the ElixirLS debug adapter and returns a DAP client connected via stdin/stdout
+func (e *ElixirAdapter) SpawnStdio(ctx context.Context, program string, args map[string]interface{}) (*dap.Client, *exec.Cmd, error) {
+	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
+	cmd := exec.CommandContext(ctx, e.debugAdapterPath)
+	cmd.Env = adapterEnv(args)
+
+	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
+	setProcAttr(cmd)
+
+	// Mix tasks must run from the project directory
+	if dir := elixirProjectDir(program, args); dir != "" {
+		cmd.Dir = dir
+	}
+
+	// Get stdin pipe (we write to this)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get stdin pipe: %w", err)
+	}
+
+	// Get stdout pipe (we read from this)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = stdin.Close()
+		return nil, nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
+
+	if err := cmd.Start(); err != nil {
+		_ = stdin.Close()
+		_ = stdout.Close()
+		return nil, nil, fmt.Errorf("failed to start elixir-ls debug adapter: %w", err)
+	}
+
+	// Create transport using the process's stdio
+	transport := dap.NewStdioTransport(stdin, stdout)
+	client := dap.NewClient(transport)
+
+	return client, cmd, nil
+}
+
+// BuildLaunchArgs builds the launch arguments for the ElixirLS debug adapter.
+// The program is either a script (.exs/.ex, run via `mix run`) or a mix task
+// name such as "test". launch.json fields task, taskArgs, and mixEnv take precedence.
+func (e *ElixirAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	launchArgs := map[string]interface{}{
+		"type":       "mix_task",
+		"projectDir": elixirProjectDir(program, args),
+	}
+
+	task := "run"
+	var taskArgs []string
+	if isElixirScript(program) {
+		taskArgs = []string{program}
+	} else if program != "" {
+		task = program
+	}
+
+	if t, ok := args["task"].(string); ok && t != "" {
+		task = t
+	}
+	switch a := args["taskArgs"].(type) {
+	case []interface{}:
+		taskArgs = make([]string, len(a))
+		for i, v := range a {
+			taskArgs[i] = fmt.Sprint(v)
+		}
+	case []string:
+		taskArgs = a
+	}
+
+	launchArgs["task"] = task
+	if len(taskArgs) > 0 {
+		launchArgs["taskArgs"] = taskArgs
+	}
+
+	// Mix environment (e.g. "test" for mix test)
+	if mixEnv, ok := args["mixEnv"].(string); ok && mixEnv != "" {
+		launchArgs["mixEnv"] = mixEnv
+	}
+
+	// Environment variables
+	switch env := args["env"].(type) {
+	case map[string]interface{}:
+		envMap := make(map[string]string)
+		for k, v := range env {
+			envMap[k] = fmt.Sprint(v)
+		}
+		launchArgs["env"] = envMap
+	case map[string]string:
+		launchArgs["env"] = env
+	}
+
+	// Stop on entry
+	if stopOnEntry, ok := args["stopOnEntry"].(bool); ok {
+		launchArgs["stopOnEntry"] = stopOnEntry
+	}
+
+	// ElixirLS-specific options passed through from launch.json
+	for _, key := range []string{"startApps", "exitAfterTaskReturns", "requireFiles", "debugInterpretModulesPatterns", "excludeModules", "debugAutoInterpretAllModules"} {
+		if v, ok := args[key]; ok {
+			launchArgs[key] = v
+		}
+	}
+
+	return launchArgs
+}
+
+// BuildAttachArgs builds the attach arguments for the ElixirLS debug adapter
+// (attaching to a running node by name and cookie)
+func (e *ElixirAdapter) BuildAttachArgs(args map[string]interface{}) map[string]interface{} {
+	attachArgs := map[string]interface{}{
+		"type": "mix_task",
+	}
+
+	for _, key := range []string{"remoteNode", "cookie", "projectDir"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			attachArgs[key] = v
+		}
+	}
+
+	return attachArgs
+}
+
+// isElixirScript reports whether the program is a script file rather than a mix task name
+func isElixirScript(program string) bool {
+	return strings.HasSuffix(program, ".exs") || strings.HasSuffix(program, ".ex")
+}
+
+// elixirProjectDir returns the mix project directory: cwd if given, otherwise
+// the directory of a script program
+func elixirProjectDir(program string, args map[string]interface{}) string {
+	if cwd, ok := args["cwd"].(string); ok && cwd != "" {
+		return cwd
+	}
+	if projectDir, ok := args["projectDir"].(string); ok && projectDir != "" {
+		return projectDir
+	}
+	if isElixirScript(program) {
+		return filepath.Dir(program)
+	}
+	return ""
+}
@@ -3,8 +3,8 @@ package adapters
 import (
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
+	"strconv"
 
 	"github.com/ctagard/dap-mcp/internal/config"
 	"github.com/ctagard/dap-mcp/internal/dap"
@@ -36,6 +36,27 @@ func (g *GDBAdapter) Language() types.Language {
 	return types.LanguageC
 }
 
+// DefaultTarget returns "" because gdb has no debug targets
+func (g *GDBAdapter) DefaultTarget() string {
+	return ""
+}
+
+// SupportedTargets returns nil because gdb has no debug targets
+func (g *GDBAdapter) SupportedTargets() []string {
+	return nil
+}
+
+// RequiresSpawn returns true: gdb only speaks DAP over stdio, so attach spawns it too
+func (g *GDBAdapter) RequiresSpawn() bool {
+	return true
+}
+
+// EntryBehavior returns EntryIgnored: GDB before 15 doesn't support stopOnEntry,
+// so a breakpoint on main stands in for it
+func (g *GDBAdapter) EntryBehavior(args map[string]interface{}) EntryBehavior {
+	return EntryIgnored
+}
+
 // IsStdio returns true because GDB DAP uses stdio transport
 func (g *GDBAdapter) IsStdio() bool {
 	return true
@@ -62,7 +83,7 @@ func (g *GDBAdapter) SpawnStdio(ctx context.Context, program string, args map[st
 
 	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
 	cmd := exec.CommandContext(ctx, g.gdbPath, gdbArgs...)
-	cmd.Env = os.Environ()
+	cmd.Env = adapterEnv(args)
 
 	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
 	setProcAttr(cmd)
@@ -85,8 +106,8 @@ func (g *GDBAdapter) SpawnStdio(ctx context.Context, program string, args map[st
 		return nil, nil, fmt.Errorf("failed to get stdout pipe: %w", err)
 	}
 
-	// Capture stderr for debugging
-	cmd.Stderr = os.Stderr
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
 
 	if err := cmd.Start(); err != nil {
 		_ = stdin.Close()
@@ -107,12 +128,8 @@ func (g *GDBAdapter) BuildLaunchArgs(program string, args map[string]interface{}
 		"program": program,
 	}
 
-	// Pass through program arguments
-	if programArgs, ok := args["args"].([]interface{}); ok {
-		strArgs := make([]string, len(programArgs))
-		for i, a := range programArgs {
-			strArgs[i] = fmt.Sprint(a)
-		}
+	// Pass through program arguments (from tool JSON or a resolved launch.json)
+	if strArgs, ok := programArgs(args); ok {
 		launchArgs["args"] = strArgs
 	}
 
@@ -122,12 +139,15 @@ func (g *GDBAdapter) BuildLaunchArgs(program string, args map[string]interface{}
 	}
 
 	// Environment variables (GDB DAP expects object format)
-	if env, ok := args["env"].(map[string]interface{}); ok {
+	switch env := args["env"].(type) {
+	case map[string]interface{}:
 		envMap := make(map[string]string)
 		for k, v := range env {
 			envMap[k] = fmt.Sprint(v)
 		}
 		launchArgs["env"] = envMap
+	case map[string]string:
+		launchArgs["env"] = env
 	}
 
 	// Stop on entry (first instruction)
@@ -148,8 +168,8 @@ func (g *GDBAdapter) BuildAttachArgs(args map[string]interface{}) map[string]int
 	attachArgs := map[string]interface{}{}
 
 	// Attach by process ID
-	if pid, ok := args["pid"].(float64); ok {
-		attachArgs["pid"] = int(pid)
+	if pid, ok := intArg(args, "pid"); ok {
+		attachArgs["pid"] = pid
 	}
 
 	// Program path (for symbol resolution)
@@ -165,3 +185,17 @@ func (g *GDBAdapter) BuildAttachArgs(args map[string]interface{}) map[string]int
 
 	return attachArgs
 }
+
+// SetupCommands maps source paths with substitute-path, since GDB's DAP
+// requests take no source map
+func (g *GDBAdapter) SetupCommands(args map[string]interface{}) []string {
+	sourceMap, ok := sourceMapArg(args)
+	if !ok {
+		return nil
+	}
+	cmds := make([]string, len(sourceMap))
+	for i, pair := range sourceMap {
+		cmds[i] = fmt.Sprintf("set substitute-path %s %s", strconv.Quote(pair[0]), strconv.Quote(pair[1]))
+	}
+	return cmds
+}
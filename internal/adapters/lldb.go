@@ -3,7 +3,6 @@ package adapters
 import (
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
 
 	"github.com/ctagard/dap-mcp/internal/config"
@@ -36,6 +35,27 @@ func (l *LLDBAdapter) Language() types.Language {
 	return types.LanguageC
 }
 
+// DefaultTarget returns "" because lldb-dap has no debug targets
+func (l *LLDBAdapter) DefaultTarget() string {
+	return ""
+}
+
+// SupportedTargets returns nil because lldb-dap has no debug targets
+func (l *LLDBAdapter) SupportedTargets() []string {
+	return nil
+}
+
+// RequiresSpawn returns true: lldb-dap only speaks DAP over stdio, so attach spawns it too
+func (l *LLDBAdapter) RequiresSpawn() bool {
+	return true
+}
+
+// SupportsWaitFor returns true: lldb attaches by name to the next process
+// started with the program's name
+func (l *LLDBAdapter) SupportsWaitFor() bool {
+	return true
+}
+
 // IsStdio returns true because lldb-dap uses stdio transport
 func (l *LLDBAdapter) IsStdio() bool {
 	return true
@@ -54,7 +74,7 @@ func (l *LLDBAdapter) SpawnStdio(ctx context.Context, program string, args map[s
 	// Commands can also be explicitly prefixed with backtick (`)
 	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
 	cmd := exec.CommandContext(ctx, l.lldbDapPath, "--repl-mode=auto")
-	cmd.Env = os.Environ()
+	cmd.Env = adapterEnv(args)
 
 	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
 	setProcAttr(cmd)
@@ -77,8 +97,8 @@ func (l *LLDBAdapter) SpawnStdio(ctx context.Context, program string, args map[s
 		return nil, nil, fmt.Errorf("failed to get stdout pipe: %w", err)
 	}
 
-	// Capture stderr for debugging
-	cmd.Stderr = os.Stderr
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
 
 	if err := cmd.Start(); err != nil {
 		_ = stdin.Close()
@@ -100,11 +120,7 @@ func (l *LLDBAdapter) BuildLaunchArgs(program string, args map[string]interface{
 	}
 
 	// Pass through program arguments
-	if programArgs, ok := args["args"].([]interface{}); ok {
-		strArgs := make([]string, len(programArgs))
-		for i, a := range programArgs {
-			strArgs[i] = fmt.Sprint(a)
-		}
+	if strArgs, ok := programArgs(args); ok {
 		launchArgs["args"] = strArgs
 	}
 
@@ -154,8 +170,8 @@ func (l *LLDBAdapter) BuildLaunchArgs(program string, args map[string]interface{
 		launchArgs["stopCommands"] = cmds
 	}
 
-	// Source path mapping for relocated binaries
-	if sourceMap, ok := args["sourceMap"].([]interface{}); ok {
+	// Source path mapping for binaries built elsewhere, e.g. in a container
+	if sourceMap, ok := sourceMapArg(args); ok {
 		launchArgs["sourceMap"] = sourceMap
 	}
 
@@ -167,8 +183,8 @@ func (l *LLDBAdapter) BuildAttachArgs(args map[string]interface{}) map[string]in
 	attachArgs := map[string]interface{}{}
 
 	// Attach by process ID
-	if pid, ok := args["pid"].(float64); ok {
-		attachArgs["pid"] = int(pid)
+	if pid, ok := intArg(args, "pid"); ok {
+		attachArgs["pid"] = pid
 	}
 
 	// Wait for process to launch (useful for debugging startup)
@@ -187,8 +203,8 @@ func (l *LLDBAdapter) BuildAttachArgs(args map[string]interface{}) map[string]in
 	}
 
 	// Remote debugging via gdb-server protocol
-	if port, ok := args["gdb-remote-port"].(float64); ok {
-		attachArgs["gdb-remote-port"] = int(port)
+	if port, ok := intArg(args, "gdb-remote-port"); ok {
+		attachArgs["gdb-remote-port"] = port
 	}
 	if hostname, ok := args["gdb-remote-hostname"].(string); ok {
 		attachArgs["gdb-remote-hostname"] = hostname
@@ -203,5 +219,10 @@ func (l *LLDBAdapter) BuildAttachArgs(args map[string]interface{}) map[string]in
 		attachArgs["attachCommands"] = cmds
 	}
 
+	// Source path mapping for binaries built elsewhere, e.g. in a container
+	if sourceMap, ok := sourceMapArg(args); ok {
+		attachArgs["sourceMap"] = sourceMap
+	}
+
 	return attachArgs
 }
@@ -3,11 +3,15 @@ package adapters
 import (
 	"context"
 	"fmt"
-	"os"
+	"log"
 	"os/exec"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/launchconfig"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
 
@@ -39,6 +43,57 @@ func (n *NodeAdapter) Language() types.Language {
 	return types.LanguageJavaScript
 }
 
+// Debug targets supported by vscode-js-debug
+const (
+	TargetNode   = "node"
+	TargetChrome = "chrome"
+	TargetEdge   = "edge"
+	// TargetTerminal runs a shell command (a "node-terminal" configuration)
+	// and debugs the Node.js processes it starts
+	TargetTerminal = "terminal"
+)
+
+// DefaultTarget returns the Node.js target
+func (n *NodeAdapter) DefaultTarget() string {
+	return TargetNode
+}
+
+// SupportedTargets returns Node.js, shell commands, and the Chromium browsers
+// vscode-js-debug can drive
+func (n *NodeAdapter) SupportedTargets() []string {
+	return []string{TargetNode, TargetChrome, TargetEdge, TargetTerminal}
+}
+
+// RequiresSpawn returns false: Node.js processes started with --inspect can be
+// attached on their port (browser attach still spawns vscode-js-debug)
+func (n *NodeAdapter) RequiresSpawn() bool {
+	return false
+}
+
+// EntryBehavior reports that browser targets can't stop at entry, and that
+// Node.js always stops at entry when started with --inspect-brk
+func (n *NodeAdapter) EntryBehavior(args map[string]interface{}) EntryBehavior {
+	if t, ok := args["target"].(string); ok && (t == TargetChrome || t == TargetEdge) {
+		return EntryIgnored
+	}
+
+	var runtimeArgs []string
+	switch a := args["runtimeArgs"].(type) {
+	case []interface{}:
+		for _, v := range a {
+			runtimeArgs = append(runtimeArgs, fmt.Sprint(v))
+		}
+	case []string:
+		runtimeArgs = a
+	}
+	for _, arg := range runtimeArgs {
+		if arg == "--inspect-brk" || strings.HasPrefix(arg, "--inspect-brk=") {
+			return EntryForced
+		}
+	}
+	return EntryHonored
+}
+
 // Spawn starts the vscode-js-debug DAP server
 // This spawns vscode-js-debug which provides a proper DAP interface and handles
 // the translation to Chrome DevTools Protocol internally
@@ -60,7 +115,7 @@ func (n *NodeAdapter) Spawn(ctx context.Context, program string, args map[string
 	// Usage: node dapDebugServer.js <port> [host]
 	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
 	cmd := exec.CommandContext(ctx, n.nodePath, n.jsDebugPath, fmt.Sprintf("%d", port), "127.0.0.1")
-	cmd.Env = os.Environ()
+	cmd.Env = adapterEnv(args)
 	// Explicitly disconnect stdin to prevent TTY issues when run as MCP server.
 	cmd.Stdin = nil
 	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
@@ -71,8 +126,8 @@ func (n *NodeAdapter) Spawn(ctx context.Context, program string, args map[string
 		cmd.Dir = cwd
 	}
 
-	// Capture stderr for debugging
-	cmd.Stderr = os.Stderr
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
 
 	if err := cmd.Start(); err != nil {
 		return "", nil, fmt.Errorf("failed to start vscode-js-debug: %w", err)
@@ -88,20 +143,23 @@ func (n *NodeAdapter) Spawn(ctx context.Context, program string, args map[string
 // Supports both Node.js (pwa-node) and browser (pwa-chrome/pwa-msedge) debugging
 func (n *NodeAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
 	// Determine the debug target type
-	target := "node" // default
-	if t, ok := args["target"].(string); ok {
+	target := n.DefaultTarget()
+	if t, ok := args["target"].(string); ok && t != "" {
 		target = t
 	}
 
 	var launchArgs map[string]interface{}
 
 	switch target {
-	case "chrome":
+	case TargetChrome:
 		// Browser debugging - Chrome
 		launchArgs = n.buildBrowserLaunchArgs("pwa-chrome", program, args)
-	case "edge":
+	case TargetEdge:
 		// Browser debugging - Edge
 		launchArgs = n.buildBrowserLaunchArgs("pwa-msedge", program, args)
+	case TargetTerminal:
+		// A command such as "npm start"
+		launchArgs = n.buildTerminalLaunchArgs(program, args)
 	default:
 		// Node.js debugging
 		launchArgs = n.buildNodeLaunchArgs(program, args)
@@ -110,6 +168,30 @@ func (n *NodeAdapter) BuildLaunchArgs(program string, args map[string]interface{
 	return launchArgs
 }
 
+// buildTerminalLaunchArgs builds launch arguments for a "node-terminal"
+// configuration's command. VS Code runs the command in a terminal, which
+// needs the client to support runInTerminal; here it runs in a shell instead.
+// vscode-js-debug debugs every Node.js process the command starts, either way.
+// The command is the configuration's command, else program.
+func (n *NodeAdapter) buildTerminalLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	command := program
+	if c, ok := args["command"].(string); ok && c != "" {
+		command = c
+	}
+
+	launchArgs := n.buildNodeLaunchArgs(program, args)
+	delete(launchArgs, "program")
+	if runtime.GOOS == "windows" {
+		launchArgs["runtimeExecutable"] = "cmd"
+		launchArgs["runtimeArgs"] = []string{"/d", "/s", "/c", command}
+	} else {
+		launchArgs["runtimeExecutable"] = "sh"
+		launchArgs["runtimeArgs"] = []string{"-c", command}
+	}
+	launchArgs["autoAttachChildProcesses"] = true
+	return launchArgs
+}
+
 // buildNodeLaunchArgs builds launch arguments for Node.js debugging
 func (n *NodeAdapter) buildNodeLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
 	launchArgs := map[string]interface{}{
@@ -120,11 +202,7 @@ func (n *NodeAdapter) buildNodeLaunchArgs(program string, args map[string]interf
 	}
 
 	// Pass through common arguments
-	if programArgs, ok := args["args"].([]interface{}); ok {
-		strArgs := make([]string, len(programArgs))
-		for i, a := range programArgs {
-			strArgs[i] = fmt.Sprint(a)
-		}
+	if strArgs, ok := programArgs(args); ok {
 		launchArgs["args"] = strArgs
 	}
 
@@ -210,18 +288,7 @@ func (n *NodeAdapter) buildBrowserLaunchArgs(debugType string, url string, args
 		// sourceMapPathOverrides - maps URLs in source maps to local files
 		// Use custom overrides if provided, otherwise use defaults for common bundlers
 		if len(n.sourceMapPathOverrides) > 0 {
-			// Apply custom overrides, replacing ${webRoot} placeholder
-			overrides := make(map[string]string)
-			for pattern, replacement := range n.sourceMapPathOverrides {
-				// Replace ${webRoot} with actual webRoot value
-				finalReplacement := replacement
-				if replacement == "${webRoot}/*" {
-					finalReplacement = webRoot + "/*"
-				} else if len(replacement) > 11 && replacement[:11] == "${webRoot}/" {
-					finalReplacement = webRoot + replacement[10:]
-				}
-				overrides[pattern] = finalReplacement
-			}
+			overrides := n.resolveSourceMapPathOverrides(webRoot, args)
 			launchArgs["sourceMapPathOverrides"] = overrides
 		} else {
 			// Default overrides for common bundlers: Vite, Webpack (CRA), and others
@@ -250,19 +317,53 @@ func (n *NodeAdapter) buildBrowserLaunchArgs(debugType string, url string, args
 	return launchArgs
 }
 
+// resolveSourceMapPathOverrides resolves the variables in the configured
+// sourceMapPathOverrides: ${webRoot}, and the launch.json variables such as
+// ${workspaceFolder} (the launch's workspace, else its cwd, else the webRoot)
+// and ${env:NAME}. A variable that can't be resolved is left as written.
+func (n *NodeAdapter) resolveSourceMapPathOverrides(webRoot string, args map[string]interface{}) map[string]string {
+	workspace, _ := args[WorkspaceFolderArg].(string)
+	if workspace == "" {
+		workspace, _ = args["cwd"].(string)
+	}
+	if workspace == "" {
+		workspace = webRoot
+	}
+	ctx := &launchconfig.ResolutionContext{
+		WorkspaceFolder: workspace,
+		Variables:       map[string]string{"webRoot": webRoot},
+	}
+
+	overrides := make(map[string]string, len(n.sourceMapPathOverrides))
+	for pattern, replacement := range n.sourceMapPathOverrides {
+		// An empty workspace would turn ${workspaceFolder}/src/* into /src/*
+		if workspace == "" && strings.Contains(replacement, "${workspaceFolder") {
+			log.Printf("Warning: sourceMapPathOverrides[%q]: no workspace, cwd, or webRoot to resolve ${workspaceFolder}; left as written", pattern)
+			overrides[pattern] = replacement
+			continue
+		}
+		resolved, err := launchconfig.ResolveVariables(replacement, ctx)
+		if err != nil {
+			log.Printf("Warning: sourceMapPathOverrides[%q]: %v", pattern, err)
+		}
+		overrides[pattern] = resolved
+	}
+	return overrides
+}
+
 // BuildAttachArgs builds the attach arguments for JavaScript/TypeScript debugging
 // Supports both Node.js and browser (Chrome/Edge) attach
 func (n *NodeAdapter) BuildAttachArgs(args map[string]interface{}) map[string]interface{} {
 	// Determine the debug target type
-	target := "node" // default
-	if t, ok := args["target"].(string); ok {
+	target := n.DefaultTarget()
+	if t, ok := args["target"].(string); ok && t != "" {
 		target = t
 	}
 
 	switch target {
-	case "chrome":
+	case TargetChrome:
 		return n.buildBrowserAttachArgs("pwa-chrome", args)
-	case "edge":
+	case TargetEdge:
 		return n.buildBrowserAttachArgs("pwa-msedge", args)
 	default:
 		return n.buildNodeAttachArgs(args)
@@ -283,15 +384,15 @@ func (n *NodeAdapter) buildNodeAttachArgs(args map[string]interface{}) map[strin
 		attachArgs["address"] = "127.0.0.1"
 	}
 
-	if port, ok := args["port"].(float64); ok {
-		attachArgs["port"] = int(port)
+	if port, ok := intArg(args, "port"); ok {
+		attachArgs["port"] = port
 	} else {
 		attachArgs["port"] = 9229 // Default Node.js inspector port
 	}
 
 	// Process ID attachment
-	if pid, ok := args["pid"].(float64); ok {
-		attachArgs["processId"] = int(pid)
+	if pid, ok := intArg(args, "pid"); ok {
+		attachArgs["processId"] = pid
 	}
 
 	return attachArgs
@@ -309,6 +410,10 @@ func (n *NodeAdapter) buildBrowserAttachArgs(debugType string, args map[string]i
 	if url, ok := args["url"].(string); ok {
 		attachArgs["url"] = url
 	}
+	// Wildcard or partial URL: vscode-js-debug attaches to the first matching tab
+	if urlFilter, ok := args["urlFilter"].(string); ok {
+		attachArgs["urlFilter"] = urlFilter
+	}
 
 	// webRoot for source map resolution
 	if webRoot, ok := args["webRoot"].(string); ok {
@@ -323,18 +428,7 @@ func (n *NodeAdapter) buildBrowserAttachArgs(debugType string, args map[string]i
 		// sourceMapPathOverrides - maps URLs in source maps to local files
 		// Use custom overrides if provided, otherwise use defaults for common bundlers
 		if len(n.sourceMapPathOverrides) > 0 {
-			// Apply custom overrides, replacing ${webRoot} placeholder
-			overrides := make(map[string]string)
-			for pattern, replacement := range n.sourceMapPathOverrides {
-				// Replace ${webRoot} with actual webRoot value
-				finalReplacement := replacement
-				if replacement == "${webRoot}/*" {
-					finalReplacement = webRoot + "/*"
-				} else if len(replacement) > 11 && replacement[:11] == "${webRoot}/" {
-					finalReplacement = webRoot + replacement[10:]
-				}
-				overrides[pattern] = finalReplacement
-			}
+			overrides := n.resolveSourceMapPathOverrides(webRoot, args)
 			attachArgs["sourceMapPathOverrides"] = overrides
 		} else {
 			// Default overrides for common bundlers: Vite, Webpack (CRA), and others
@@ -353,8 +447,8 @@ func (n *NodeAdapter) buildBrowserAttachArgs(debugType string, args map[string]i
 	}
 
 	// Port for Chrome DevTools Protocol
-	if port, ok := args["port"].(float64); ok {
-		attachArgs["port"] = int(port)
+	if port, ok := intArg(args, "port"); ok {
+		attachArgs["port"] = port
 	} else {
 		attachArgs["port"] = 9222 // Default Chrome remote debugging port
 	}
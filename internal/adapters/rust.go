@@ -0,0 +1,176 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// RustAdapter implements the StdioAdapter interface for Rust. By default it
+// runs lldb-dap with the Rust toolchain's LLDB formatters loaded, the way
+// rust-lldb does, so Vec, String, Option and friends show their values instead
+// of raw struct internals. If codelldbPath is configured, it runs CodeLLDB
+// over TCP instead, which ships its own Rust formatters.
+type RustAdapter struct {
+	*LLDBAdapter
+	codelldbPath string
+
+	sysrootOnce sync.Once
+	sysroot     string
+}
+
+// NewRustAdapter creates a new Rust adapter.
+// lldb-dap comes from the LLDB configuration unless codelldb is configured.
+func NewRustAdapter(cfg config.RustConfig, lldbCfg config.LLDBConfig) *RustAdapter {
+	return &RustAdapter{
+		LLDBAdapter:  NewLLDBAdapter(lldbCfg),
+		codelldbPath: cfg.CodelldbPath,
+		sysroot:      cfg.RustcSysroot,
+	}
+}
+
+// Language returns the language this adapter supports
+func (r *RustAdapter) Language() types.Language {
+	return types.LanguageRust
+}
+
+// IsStdio returns true unless CodeLLDB is configured, which uses TCP transport
+func (r *RustAdapter) IsStdio() bool {
+	return r.codelldbPath == ""
+}
+
+// Spawn starts CodeLLDB listening on a local port. Without codelldbPath the
+// adapter uses lldb-dap over stdio and SpawnStdio must be used instead.
+func (r *RustAdapter) Spawn(ctx context.Context, program string, args map[string]interface{}) (string, *exec.Cmd, error) {
+	if r.codelldbPath == "" {
+		return r.LLDBAdapter.Spawn(ctx, program, args)
+	}
+
+	port, err := findAvailablePort()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find available port: %w", err)
+	}
+
+	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
+	cmd := exec.CommandContext(ctx, r.codelldbPath, "--port", fmt.Sprint(port))
+	cmd.Env = adapterEnv(args)
+	cmd.Stdin = nil
+	// Capture stderr for error reports and debug_adapter_log
+	cmd.Stderr = dap.NewAdapterLog()
+	// Set platform-specific process attributes (procattr_unix.go / procattr_windows.go)
+	setProcAttr(cmd)
+
+	if cwd, ok := args["cwd"].(string); ok && cwd != "" {
+		cmd.Dir = cwd
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf("failed to start codelldb: %w", err)
+	}
+
+	return fmt.Sprintf("127.0.0.1:%d", port), cmd, nil
+}
+
+// BuildLaunchArgs builds the launch arguments with the Rust formatters enabled
+func (r *RustAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	launchArgs := r.LLDBAdapter.BuildLaunchArgs(program, args)
+	if r.codelldbPath != "" {
+		// CodeLLDB takes the environment as an object rather than a list
+		if env, ok := args["env"].(map[string]interface{}); ok {
+			envMap := make(map[string]string, len(env))
+			for k, v := range env {
+				envMap[k] = fmt.Sprint(v)
+			}
+			launchArgs["env"] = envMap
+		}
+	}
+	r.addRustSettings(launchArgs)
+	return launchArgs
+}
+
+// BuildAttachArgs builds the attach arguments with the Rust formatters enabled
+func (r *RustAdapter) BuildAttachArgs(args map[string]interface{}) map[string]interface{} {
+	attachArgs := r.LLDBAdapter.BuildAttachArgs(args)
+	if initCommands, ok := args["initCommands"].([]interface{}); ok {
+		cmds := make([]string, len(initCommands))
+		for i, c := range initCommands {
+			cmds[i] = fmt.Sprint(c)
+		}
+		attachArgs["initCommands"] = cmds
+	}
+	r.addRustSettings(attachArgs)
+	return attachArgs
+}
+
+// addRustSettings loads the Rust formatters: CodeLLDB enables its own through
+// sourceLanguages, lldb-dap gets the toolchain's through initCommands
+func (r *RustAdapter) addRustSettings(requestArgs map[string]interface{}) {
+	if r.codelldbPath != "" {
+		requestArgs["sourceLanguages"] = []string{"rust"}
+		// Evaluate mode treats backtick-prefixed input as LLDB commands, like
+		// lldb-dap's auto REPL mode, which debug_execute_command relies on
+		requestArgs["consoleMode"] = "evaluate"
+		// CodeLLDB takes the source map as an object
+		if sourceMap, ok := requestArgs["sourceMap"].([][]string); ok {
+			object := make(map[string]string, len(sourceMap))
+			for _, pair := range sourceMap {
+				object[pair[0]] = pair[1]
+			}
+			requestArgs["sourceMap"] = object
+		}
+		return
+	}
+	if cmds := withRustInitCommands(r.rustcSysroot(), requestArgs["initCommands"]); len(cmds) > 0 {
+		requestArgs["initCommands"] = cmds
+	}
+}
+
+// rustcSysroot returns the configured sysroot, or asks rustc for it once.
+// It returns "" if rustc is not available.
+func (r *RustAdapter) rustcSysroot() string {
+	r.sysrootOnce.Do(func() {
+		if r.sysroot != "" {
+			return
+		}
+		out, err := exec.Command("rustc", "--print", "sysroot").Output()
+		if err == nil {
+			r.sysroot = strings.TrimSpace(string(out))
+		}
+	})
+	return r.sysroot
+}
+
+// rustInitCommands returns the commands rust-lldb runs to load the Rust
+// formatters from a toolchain sysroot
+func rustInitCommands(sysroot string) []string {
+	etc := filepath.Join(sysroot, "lib", "rustlib", "etc")
+	return []string{
+		fmt.Sprintf("command script import %q", filepath.Join(etc, "lldb_lookup.py")),
+		fmt.Sprintf("command source -s 0 %q", filepath.Join(etc, "lldb_commands")),
+	}
+}
+
+// withRustInitCommands prepends the Rust formatter commands to any
+// user-provided initCommands. Without a sysroot only the user's are kept.
+func withRustInitCommands(sysroot string, existing interface{}) []string {
+	var cmds []string
+	if sysroot != "" {
+		cmds = append(cmds, rustInitCommands(sysroot)...)
+	}
+	switch userCmds := existing.(type) {
+	case []string:
+		cmds = append(cmds, userCmds...)
+	case []interface{}:
+		for _, c := range userCmds {
+			cmds = append(cmds, fmt.Sprint(c))
+		}
+	}
+	return cmds
+}
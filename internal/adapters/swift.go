@@ -0,0 +1,72 @@
+package adapters
+
+import (
+	"fmt"
+
+	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// swiftInitCommands are run by lldb-dap before the target is created so that
+// expressions are parsed as Swift and the Swift runtime formatters are active.
+var swiftInitCommands = []string{
+	"settings set target.language swift",
+	"type category enable swift",
+}
+
+// SwiftAdapter implements the StdioAdapter interface for Swift via lldb-dap.
+// It reuses the LLDB spawn path but prefers the lldb-dap shipped with the Swift
+// toolchain and injects Swift-specific initCommands.
+type SwiftAdapter struct {
+	*LLDBAdapter
+}
+
+// NewSwiftAdapter creates a new Swift adapter.
+// If no Swift toolchain lldb-dap is configured, the LLDB configuration is used.
+func NewSwiftAdapter(cfg config.SwiftConfig, lldbCfg config.LLDBConfig) *SwiftAdapter {
+	path := cfg.Path
+	if path == "" {
+		path = lldbCfg.Path
+	}
+
+	return &SwiftAdapter{
+		LLDBAdapter: NewLLDBAdapter(config.LLDBConfig{Path: path}),
+	}
+}
+
+// Language returns the language this adapter supports
+func (s *SwiftAdapter) Language() types.Language {
+	return types.LanguageSwift
+}
+
+// BuildLaunchArgs builds the launch arguments for lldb-dap with Swift settings
+func (s *SwiftAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	launchArgs := s.LLDBAdapter.BuildLaunchArgs(program, args)
+	launchArgs["initCommands"] = withSwiftInitCommands(launchArgs["initCommands"])
+	return launchArgs
+}
+
+// BuildAttachArgs builds the attach arguments for lldb-dap with Swift settings
+func (s *SwiftAdapter) BuildAttachArgs(args map[string]interface{}) map[string]interface{} {
+	attachArgs := s.LLDBAdapter.BuildAttachArgs(args)
+	if initCommands, ok := args["initCommands"].([]interface{}); ok {
+		cmds := make([]string, len(initCommands))
+		for i, c := range initCommands {
+			cmds[i] = fmt.Sprint(c)
+		}
+		attachArgs["initCommands"] = cmds
+	}
+	attachArgs["initCommands"] = withSwiftInitCommands(attachArgs["initCommands"])
+	return attachArgs
+}
+
+// withSwiftInitCommands prepends the Swift initCommands to any user-provided ones,
+// so user commands can still override the defaults.
+func withSwiftInitCommands(existing interface{}) []string {
+	cmds := make([]string, 0, len(swiftInitCommands))
+	cmds = append(cmds, swiftInitCommands...)
+	if userCmds, ok := existing.([]string); ok {
+		cmds = append(cmds, userCmds...)
+	}
+	return cmds
+}
@@ -13,8 +13,11 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -26,6 +29,13 @@ const (
 	ModeFull     CapabilityMode = "full"     // All tools enabled
 )
 
+// Evaluation levels, from the evaluation field or derived from the permission flags
+const (
+	EvaluationNone     = "none"     // No expression evaluation
+	EvaluationReadOnly = "readOnly" // Side-effect-free evaluation, not in the repl context
+	EvaluationFull     = "full"     // Any expression in any context
+)
+
 // Config holds the server configuration
 type Config struct {
 	// Capability levels
@@ -35,12 +45,73 @@ type Config struct {
 	AllowModify  bool           `json:"allowModify"`
 	AllowExecute bool           `json:"allowExecute"`
 
+	// EvaluateReadOnly allows expression evaluation even without allowExecute,
+	// but only of expressions without side effects: adapters that can evaluate
+	// without side effects are asked to, and elsewhere expressions with calls
+	// or assignments are rejected
+	EvaluateReadOnly bool `json:"evaluateReadOnly"`
+
+	// Evaluation sets how expressions may be evaluated: "none", "readOnly",
+	// or "full". If empty it follows allowExecute and evaluateReadOnly.
+	// Readonly mode never evaluates in full, so there "full" (or allowExecute)
+	// means read-only evaluation.
+	Evaluation string `json:"evaluation"`
+
+	// AllowCommands permits running shell commands declared by command-type
+	// ${input:} variables in launch.json, and the tasks.json tasks named by a
+	// configuration's preLaunchTask and postDebugTask
+	AllowCommands bool `json:"allowCommands"`
+
+	// AllowInstall exposes debug_install_adapter, which runs adapter
+	// installers (go install, pip install, or a vscode-js-debug download)
+	AllowInstall bool `json:"allowInstall"`
+
 	// Language-specific adapter configs
 	Adapters AdapterConfigs `json:"adapters"`
 
 	// Limits for safety
 	MaxSessions    int           `json:"maxSessions"`
 	SessionTimeout time.Duration `json:"sessionTimeout"`
+
+	// MaxVariableValueLength truncates longer variable values in tool results (0 = no limit)
+	MaxVariableValueLength int `json:"maxVariableValueLength"`
+
+	// MaxSourceSize caps the bytes of source returned by debug_source (0 = no limit)
+	MaxSourceSize int `json:"maxSourceSize"`
+
+	// RequireVerifiedBreakpoints makes debug_breakpoints and debug_run_to_line
+	// fail when the adapter doesn't verify a breakpoint, instead of returning
+	// it unverified. Either tool's requireVerified parameter overrides it.
+	RequireVerifiedBreakpoints bool `json:"requireVerifiedBreakpoints"`
+
+	// TerminateAttachedOnShutdown makes server shutdown and session timeouts
+	// terminate debuggees of attached sessions; by default they are detached from
+	TerminateAttachedOnShutdown bool `json:"terminateAttachedOnShutdown"`
+
+	// SnapshotHiddenFrames lists function names that mark a thread as idle:
+	// debug_snapshot with hideSystemThreads skips threads whose top frame name
+	// contains one of them
+	SnapshotHiddenFrames []string `json:"snapshotHiddenFrames"`
+
+	// MaxInFlightRequests caps the DAP requests outstanding at once per
+	// adapter, keyed by language or native debugger ("lldb", "gdb") with
+	// "default" for the rest. Further requests queue until one completes.
+	// 0 means no limit.
+	MaxInFlightRequests map[string]int `json:"maxInFlightRequests"`
+
+	// EvaluationContexts sets the context debug_evaluate uses when the caller
+	// passes none, keyed by language or native debugger ("lldb", "gdb") with
+	// "default" for the rest. An explicit context always wins.
+	EvaluationContexts map[string]string `json:"evaluationContexts"`
+
+	// LogpointAutoContinue makes the server continue past logpoints on
+	// adapters that don't support them and stop there instead, after adding
+	// the logged message to the session's output. Adapters with logpoint
+	// support never stop at them.
+	LogpointAutoContinue bool `json:"logpointAutoContinue"`
+
+	// Path is the file the configuration was loaded from (empty for defaults)
+	Path string `json:"-"`
 }
 
 // AdapterConfigs holds configuration for each language adapter
@@ -50,17 +121,26 @@ type AdapterConfigs struct {
 	Node   NodeConfig    `json:"node"`
 	LLDB   LLDBConfig    `json:"lldb"`
 	GDB    GDBConfig     `json:"gdb"`
+	Swift  SwiftConfig   `json:"swift"`
+	Rust   RustConfig    `json:"rust"`
+	Elixir ElixirConfig  `json:"elixir"`
 }
 
 // DelveConfig holds Delve-specific configuration
 type DelveConfig struct {
-	Path       string `json:"path"`
-	BuildFlags string `json:"buildFlags"`
+	Path       string            `json:"path"`
+	BuildFlags string            `json:"buildFlags"`
+	DefaultEnv map[string]string `json:"defaultEnv"`
+
+	// EntryFunction is where stopOnEntry pauses Go programs (default:
+	// main.main); empty keeps Delve's stop in the runtime startup
+	EntryFunction string `json:"entryFunction"`
 }
 
 // DebugpyConfig holds debugpy-specific configuration
 type DebugpyConfig struct {
-	PythonPath string `json:"pythonPath"`
+	PythonPath string            `json:"pythonPath"`
+	DefaultEnv map[string]string `json:"defaultEnv"`
 }
 
 // NodeConfig holds Node.js-specific configuration
@@ -69,16 +149,38 @@ type NodeConfig struct {
 	JsDebugPath            string            `json:"jsDebugPath"` // Path to vscode-js-debug's dapDebugServer.js
 	InspectBrk             bool              `json:"inspectBrk"`
 	SourceMapPathOverrides map[string]string `json:"sourceMapPathOverrides"` // Custom source map path overrides for bundlers
+	DefaultEnv             map[string]string `json:"defaultEnv"`             // Adapter and debuggee environment defaults
 }
 
 // LLDBConfig holds LLDB-specific configuration
 type LLDBConfig struct {
-	Path string `json:"path"` // Path to lldb-dap binary (formerly lldb-vscode)
+	Path       string            `json:"path"`       // Path to lldb-dap binary (formerly lldb-vscode)
+	DefaultEnv map[string]string `json:"defaultEnv"` // Adapter and debuggee environment defaults
 }
 
 // GDBConfig holds GDB-specific configuration
 type GDBConfig struct {
-	Path string `json:"path"` // Path to gdb binary (requires GDB 14.1+ for DAP support)
+	Path       string            `json:"path"`       // Path to gdb binary (requires GDB 14.1+ for DAP support)
+	DefaultEnv map[string]string `json:"defaultEnv"` // Adapter and debuggee environment defaults
+}
+
+// SwiftConfig holds Swift-specific configuration
+type SwiftConfig struct {
+	Path       string            `json:"path"`       // Path to the Swift toolchain's lldb-dap (falls back to the LLDB path)
+	DefaultEnv map[string]string `json:"defaultEnv"` // Adapter and debuggee environment defaults
+}
+
+// RustConfig holds Rust-specific configuration
+type RustConfig struct {
+	CodelldbPath string            `json:"codelldbPath"` // Path to CodeLLDB's codelldb adapter (lldb-dap from the LLDB config is used if empty)
+	RustcSysroot string            `json:"rustcSysroot"` // Rust toolchain sysroot with the LLDB formatters (default: rustc --print sysroot)
+	DefaultEnv   map[string]string `json:"defaultEnv"`   // Adapter and debuggee environment defaults
+}
+
+// ElixirConfig holds ElixirLS-specific configuration
+type ElixirConfig struct {
+	ElixirLsPath string            `json:"elixirLsPath"` // Path to ElixirLS's debug_adapter.sh
+	DefaultEnv   map[string]string `json:"defaultEnv"`   // Adapter and debuggee environment defaults
 }
 
 // findLLDBDap searches for lldb-dap in common locations across platforms
@@ -121,6 +223,28 @@ func findLLDBDap() string {
 	return "lldb-dap"
 }
 
+// findSwiftLLDBDap looks for the lldb-dap binary shipped alongside the Swift toolchain.
+// The toolchain's lldb is built with Swift support, unlike most distribution lldb packages.
+// Returns empty string if no toolchain lldb-dap is found.
+func findSwiftLLDBDap() string {
+	swiftPath, err := exec.LookPath("swift")
+	if err != nil {
+		return ""
+	}
+
+	// Resolve symlinks (e.g. /usr/bin/swift -> /opt/swift/usr/bin/swift)
+	if resolved, err := filepath.EvalSymlinks(swiftPath); err == nil {
+		swiftPath = resolved
+	}
+
+	candidate := filepath.Join(filepath.Dir(swiftPath), "lldb-dap")
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate
+	}
+
+	return ""
+}
+
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
@@ -131,9 +255,39 @@ func DefaultConfig() *Config {
 		AllowExecute:   true,
 		MaxSessions:    10,
 		SessionTimeout: 30 * time.Minute,
+
+		MaxVariableValueLength: 2048,
+		MaxSourceSize:          1 << 20,
+
+		// Where the Go runtime parks goroutines that are waiting or asleep
+		SnapshotHiddenFrames: []string{
+			"runtime.gopark",
+			"runtime.notetsleepg",
+			"runtime.netpoll",
+			"runtime.futex",
+			"runtime.usleep",
+		},
+
+		MaxInFlightRequests: map[string]int{
+			"default": 16,
+		},
+
+		// debugpy and js-debug run statements, not just expressions, in the
+		// repl context. GDB and CodeLLDB take repl input as debugger commands,
+		// so native sessions keep watch.
+		EvaluationContexts: map[string]string{
+			"default":    "watch",
+			"python":     "repl",
+			"javascript": "repl",
+			"typescript": "repl",
+		},
+
+		LogpointAutoContinue: true,
+
 		Adapters: AdapterConfigs{
 			Go: DelveConfig{
-				Path: "dlv",
+				Path:          "dlv",
+				EntryFunction: "main.main",
 			},
 			Python: DebugpyConfig{
 				PythonPath: "python3",
@@ -148,6 +302,12 @@ func DefaultConfig() *Config {
 			GDB: GDBConfig{
 				Path: "gdb",
 			},
+			Swift: SwiftConfig{
+				Path: findSwiftLLDBDap(),
+			},
+			Elixir: ElixirConfig{
+				ElixirLsPath: "debug_adapter.sh",
+			},
 		},
 	}
 }
@@ -168,10 +328,97 @@ func LoadConfig(path string) (*Config, error) {
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
+	cfg.Path = path
 
 	return cfg, nil
 }
 
+// SetFileValue sets a dotted key (e.g. "adapters.go.path") in a JSON
+// configuration file, creating the file and intermediate objects as needed
+// and keeping the file's other settings
+func SetFileValue(path, key string, value interface{}) error {
+	doc := map[string]interface{}{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	parts := strings.Split(key, ".")
+	obj := doc
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := obj[part].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			obj[part] = child
+		}
+		obj = child
+	}
+	obj[parts[len(parts)-1]] = value
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0o644)
+}
+
+// MaxInFlight returns the request limit for an adapter: the entry for its
+// native debugger, else its language, else the default (0 = no limit)
+func (c *Config) MaxInFlight(language, debugger string) int {
+	if n, ok := c.MaxInFlightRequests[debugger]; ok && debugger != "" {
+		return n
+	}
+	if n, ok := c.MaxInFlightRequests[language]; ok {
+		return n
+	}
+	return c.MaxInFlightRequests["default"]
+}
+
+// EvaluationContext returns the default evaluation context for an adapter:
+// the entry for its native debugger, else its language, else "default", and
+// "watch" if none is set
+func (c *Config) EvaluationContext(language, debugger string) string {
+	if context, ok := c.EvaluationContexts[debugger]; ok && debugger != "" && context != "" {
+		return context
+	}
+	if context, ok := c.EvaluationContexts[language]; ok && context != "" {
+		return context
+	}
+	if context := c.EvaluationContexts["default"]; context != "" {
+		return context
+	}
+	return "watch"
+}
+
+// DefaultEnv returns the default environment for a language's sessions: the
+// defaultEnv of its adapter config, or for C, C++, and native sessions that of
+// the native debugger ("gdb" picks GDB, anything else LLDB)
+func (c *Config) DefaultEnv(language, debugger string) map[string]string {
+	switch language {
+	case "go":
+		return c.Adapters.Go.DefaultEnv
+	case "python":
+		return c.Adapters.Python.DefaultEnv
+	case "javascript", "typescript":
+		return c.Adapters.Node.DefaultEnv
+	case "rust":
+		return c.Adapters.Rust.DefaultEnv
+	case "swift":
+		return c.Adapters.Swift.DefaultEnv
+	case "elixir":
+		return c.Adapters.Elixir.DefaultEnv
+	}
+	if debugger == "gdb" {
+		return c.Adapters.GDB.DefaultEnv
+	}
+	return c.Adapters.LLDB.DefaultEnv
+}
+
 // CanUseControlTools returns true if control tools are enabled
 func (c *Config) CanUseControlTools() bool {
 	return c.Mode == ModeFull
@@ -192,7 +439,38 @@ func (c *Config) CanModifyVariables() bool {
 	return c.Mode == ModeFull && c.AllowModify
 }
 
+// EvaluationLevel returns how expressions may be evaluated: EvaluationNone,
+// EvaluationReadOnly, or EvaluationFull
+func (c *Config) EvaluationLevel() string {
+	level := c.Evaluation
+	if level == "" {
+		switch {
+		case c.AllowExecute:
+			level = EvaluationFull
+		case c.EvaluateReadOnly:
+			level = EvaluationReadOnly
+		default:
+			level = EvaluationNone
+		}
+	}
+	if c.Mode == ModeReadOnly && level == EvaluationFull {
+		level = EvaluationReadOnly
+	}
+	return level
+}
+
 // CanEvaluate returns true if expression evaluation is allowed
 func (c *Config) CanEvaluate() bool {
+	return c.EvaluationLevel() != EvaluationNone
+}
+
+// EvaluatesReadOnly returns true if evaluation is limited to expressions
+// without side effects
+func (c *Config) EvaluatesReadOnly() bool {
+	return c.EvaluationLevel() == EvaluationReadOnly
+}
+
+// CanExecute returns true if adapter-specific requests may be sent
+func (c *Config) CanExecute() bool {
 	return c.AllowExecute
 }
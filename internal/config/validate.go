@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// evaluationContexts are the DAP evaluate contexts evaluationContexts may name
+var evaluationContexts = []string{"watch", "hover", "repl", "clipboard", "variables"}
+
+// Validate checks the configuration for settings the server can't run with
+// and settings that contradict each other. It returns warnings for settings
+// that are ignored or look like mistakes, and an error listing every
+// unusable setting, each with how to fix it.
+func (c *Config) Validate() ([]string, error) {
+	var problems, warnings []string
+
+	if c.Mode != ModeReadOnly && c.Mode != ModeFull {
+		problems = append(problems, fmt.Sprintf("mode %q is not %q or %q", c.Mode, ModeReadOnly, ModeFull))
+	}
+	switch c.Evaluation {
+	case "", EvaluationNone, EvaluationReadOnly, EvaluationFull:
+	default:
+		problems = append(problems, fmt.Sprintf("evaluation %q is not %q, %q, or %q (or unset to follow allowExecute and evaluateReadOnly)",
+			c.Evaluation, EvaluationNone, EvaluationReadOnly, EvaluationFull))
+	}
+
+	if c.MaxSessions < 1 {
+		problems = append(problems, fmt.Sprintf("maxSessions is %d, so no session could start; set it to 1 or more", c.MaxSessions))
+	}
+	// JSON durations are nanoseconds, so "sessionTimeout": 30 is 30ns
+	if c.SessionTimeout < time.Second {
+		problems = append(problems, fmt.Sprintf("sessionTimeout is %v, so sessions would expire at once; it is in nanoseconds (30 minutes is %d)",
+			c.SessionTimeout, int64(30*time.Minute)))
+	}
+	if c.MaxVariableValueLength < 0 {
+		problems = append(problems, fmt.Sprintf("maxVariableValueLength is %d; use 0 for no limit", c.MaxVariableValueLength))
+	}
+	if c.MaxSourceSize < 0 {
+		problems = append(problems, fmt.Sprintf("maxSourceSize is %d; use 0 for no limit", c.MaxSourceSize))
+	}
+	for _, key := range sortedKeys(c.MaxInFlightRequests) {
+		if n := c.MaxInFlightRequests[key]; n < 0 {
+			problems = append(problems, fmt.Sprintf("maxInFlightRequests[%q] is %d; use 0 for no limit", key, n))
+		}
+	}
+	for _, key := range sortedKeys(c.EvaluationContexts) {
+		context := c.EvaluationContexts[key]
+		valid := false
+		for _, name := range evaluationContexts {
+			valid = valid || context == name
+		}
+		if !valid {
+			problems = append(problems, fmt.Sprintf("evaluationContexts[%q] is %q, not one of %s", key, context, strings.Join(evaluationContexts, ", ")))
+		}
+	}
+
+	if c.Mode == ModeReadOnly {
+		if c.AllowModify {
+			warnings = append(warnings, "allowModify has no effect in readonly mode, which never modifies variables")
+		}
+		if c.Evaluation == EvaluationFull {
+			warnings = append(warnings, `evaluation "full" is read-only in readonly mode`)
+		}
+	}
+	if c.Evaluation != "" && c.EvaluateReadOnly {
+		warnings = append(warnings, fmt.Sprintf("evaluateReadOnly is ignored because evaluation is set to %q", c.Evaluation))
+	}
+	if !c.AllowSpawn && !c.AllowAttach {
+		warnings = append(warnings, "allowSpawn and allowAttach are both false, so no debug session can be started")
+	}
+	for _, name := range c.SnapshotHiddenFrames {
+		if strings.TrimSpace(name) == "" {
+			warnings = append(warnings, "snapshotHiddenFrames contains an empty name, which matches every frame, so hideSystemThreads hides every thread")
+			break
+		}
+	}
+
+	if c.Adapters.Node.JsDebugPath == "" {
+		warnings = append(warnings, "adapters.node.jsDebugPath is not set, so JavaScript and TypeScript sessions can't start; run dap-mcp -install javascript -write-config to set it")
+	} else if _, err := os.Stat(c.Adapters.Node.JsDebugPath); err != nil {
+		warnings = append(warnings, fmt.Sprintf("adapters.node.jsDebugPath %s can't be read (%v), so JavaScript and TypeScript sessions will fail", c.Adapters.Node.JsDebugPath, err))
+	}
+
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return warnings, nil
+}
+
+// sortedKeys returns a map's keys in order, so problems are listed stably
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
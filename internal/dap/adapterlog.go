@@ -0,0 +1,92 @@
+package dap
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// Line limits for captured adapter output
+const (
+	adapterLogLines = 500
+	stderrTailLines = 20
+)
+
+// AdapterLog is an io.Writer used as a spawned adapter's stderr. It keeps the
+// most recent lines in memory so launch failures and debug_adapter_log can
+// report what the adapter printed. Output is never forwarded to the server's
+// own stdout/stderr, which would interfere with the MCP stdio stream.
+type AdapterLog struct {
+	mu       sync.Mutex
+	lines    []string
+	partial  []byte
+	maxLines int
+	dropped  int
+}
+
+// NewAdapterLog creates an empty adapter log
+func NewAdapterLog() *AdapterLog {
+	return &AdapterLog{
+		maxLines: adapterLogLines,
+	}
+}
+
+// Write implements io.Writer
+func (l *AdapterLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.partial = append(l.partial, p...)
+	for {
+		idx := bytes.IndexByte(l.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		l.addLine(string(l.partial[:idx]))
+		l.partial = l.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+// addLine appends a line, dropping the oldest one once the limit is reached
+func (l *AdapterLog) addLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return
+	}
+	l.lines = append(l.lines, line)
+	if len(l.lines) > l.maxLines {
+		l.dropped += len(l.lines) - l.maxLines
+		l.lines = l.lines[len(l.lines)-l.maxLines:]
+	}
+}
+
+// Lines returns the captured lines, including any unterminated final line
+func (l *AdapterLog) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines := make([]string, len(l.lines), len(l.lines)+1)
+	copy(lines, l.lines)
+	if last := strings.TrimSpace(string(l.partial)); last != "" {
+		lines = append(lines, last)
+	}
+	return lines
+}
+
+// Tail returns the last lines captured, for inclusion in error messages
+func (l *AdapterLog) Tail() []string {
+	lines := l.Lines()
+	if len(lines) > stderrTailLines {
+		lines = lines[len(lines)-stderrTailLines:]
+	}
+	return lines
+}
+
+// Dropped returns the number of older lines discarded to stay within the limit
+func (l *AdapterLog) Dropped() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.dropped
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/go-dap"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
 )
 
 // StoppedInfo contains information about why the debugger stopped
@@ -16,7 +20,47 @@ type StoppedInfo struct {
 	Reason      string
 	ThreadID    int
 	Description string
+	Text        string // Details such as an exception's message
 	AllStopped  bool
+
+	// HitBreakpointIDs lists the breakpoints the thread stopped at, if the adapter says
+	HitBreakpointIDs []int
+}
+
+// ProcessInfo describes the debuggee process reported by a "process" event
+type ProcessInfo struct {
+	Name           string
+	PID            int
+	StartMethod    string
+	IsLocalProcess bool
+}
+
+// Thread lifecycle states tracked from thread events and threads responses
+const (
+	ThreadStarted = "started" // reported by a thread event, not yet listed by the adapter
+	ThreadRunning = "running" // listed in a threads response
+	ThreadExited  = "exited"
+)
+
+// ThreadState describes a debuggee thread's lifecycle
+type ThreadState struct {
+	ID       int
+	Name     string
+	Status   string
+	ExitedAt time.Time
+}
+
+// LoadedModule is a module the adapter reported, with the sequence number of
+// the report that first listed it (higher numbers were loaded later)
+type LoadedModule struct {
+	dap.Module
+	Seq int
+}
+
+// ChildCounts is how many children the adapter reported for a variablesReference
+type ChildCounts struct {
+	Indexed int
+	Named   int
 }
 
 // Client provides a high-level API for DAP operations
@@ -25,14 +69,65 @@ type Client struct {
 
 	// Response handling
 	pendingRequests map[int]chan dap.Message
+	pendingCommands map[int]string // request seq -> command, for timeout reports
 	mu              sync.Mutex
 
-	// Event handling
-	eventHandler func(dap.Message)
+	// requestSlots bounds the requests outstanding at the adapter; requests
+	// beyond the limit queue for a slot. nil means no limit.
+	requestSlots chan struct{}
+
+	// Event handling, guarded by mu
+	eventHandlers []func(dap.Message)
 
 	// Capabilities from initialize response
 	capabilities dap.Capabilities
 
+	// Breakpoints by source path, from setBreakpoints responses and breakpoint events
+	breakpoints map[string][]dap.Breakpoint
+
+	// Logpoints by source path that the client logs and continues past, for
+	// adapters that stop at them (see SetEmulateLogpoints)
+	emulateLogpoints  bool
+	logpoints         map[string][]emulatedLogpoint
+	logpointEvaluator LogpointEvaluator
+
+	// Live thread set, from thread events and threads responses
+	threads map[int]*ThreadState
+
+	// Live module set by module id, from module events and modules
+	// responses; moduleSeq numbers the modules as they are first seen
+	modules   map[string]*LoadedModule
+	moduleSeq int
+
+	// Child counts by variablesReference, from the scopes, variables, and
+	// evaluate responses that handed the references out
+	childCounts map[int]ChildCounts
+
+	// Recent output events, from the debuggee and the adapter
+	output outputLog
+
+	// Execution state, from stopped events and the requests that resume the
+	// program. lastStop is the stop the program is paused at, nil while running;
+	// stopCount numbers the stops.
+	running   bool
+	lastStop  *StoppedInfo
+	stopCount int
+
+	// Threads stopped since the program last resumed, in the order their
+	// stopped events arrived; all-stop adapters can report several at once
+	stoppedThreads []*StoppedInfo
+
+	// Debuggee lifecycle, from exited and terminated events
+	exited     bool
+	exitCode   int
+	terminated bool
+
+	// When the last message arrived from the adapter
+	lastActivity time.Time
+
+	// The successful initialize response, once there is one
+	initResponse *dap.InitializeResponse
+
 	// Initialization synchronization
 	initialized     chan struct{}
 	initializedOnce sync.Once
@@ -41,19 +136,46 @@ type Client struct {
 	stoppedChan chan *StoppedInfo
 	stoppedMu   sync.Mutex
 
+	// Debuggee process reported by the adapter's process event
+	processInfo    *ProcessInfo
+	processStarted chan struct{}
+	processOnce    sync.Once
+
+	// Closed when the read loop exits, i.e. the adapter connection is gone
+	readDone chan struct{}
+
 	// Context for shutdown
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
+// ErrAdapterGone is returned for requests made after the debug adapter's
+// connection has closed (for example because the adapter crashed)
+var ErrAdapterGone = fmt.Errorf("debug adapter connection is closed")
+
+// ErrStopTimeout is returned when an expected stopped event doesn't arrive in
+// time. The program may still stop later.
+var ErrStopTimeout = fmt.Errorf("timeout waiting for stopped event")
+
+// ErrProgramEnded is returned when the debuggee exits or the debug session
+// ends while waiting for it to stop
+var ErrProgramEnded = fmt.Errorf("the program ended")
+
 // NewClient creates a new DAP client with the given transport
 func NewClient(transport *Transport) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &Client{
 		transport:       transport,
 		pendingRequests: make(map[int]chan dap.Message),
+		pendingCommands: make(map[int]string),
+		breakpoints:     make(map[string][]dap.Breakpoint),
+		threads:         make(map[int]*ThreadState),
+		modules:         make(map[string]*LoadedModule),
+		childCounts:     make(map[int]ChildCounts),
 		initialized:     make(chan struct{}),
+		processStarted:  make(chan struct{}),
+		readDone:        make(chan struct{}),
 		ctx:             ctx,
 		cancel:          cancel,
 	}
@@ -65,14 +187,45 @@ func NewClient(transport *Transport) *Client {
 	return c
 }
 
-// SetEventHandler sets the handler for DAP events
-func (c *Client) SetEventHandler(handler func(dap.Message)) {
-	c.eventHandler = handler
+// SetMaxInFlight limits the requests outstanding at the adapter at once, for
+// adapters that drop or reorder responses under load. Further requests wait
+// for one to complete, within their own timeout. n <= 0 removes the limit.
+// LaunchAsync and AttachAsync don't take a slot, since adapters may hold
+// their response until configurationDone. Call it before making requests.
+func (c *Client) SetMaxInFlight(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n <= 0 {
+		c.requestSlots = nil
+		return
+	}
+	c.requestSlots = make(chan struct{}, n)
+}
+
+// AddEventHandler registers a handler for DAP events. Handlers run on the
+// read loop in the order they were added, so they must not block on requests.
+func (c *Client) AddEventHandler(handler func(dap.Message)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventHandlers = append(c.eventHandlers, handler)
+}
+
+// dispatchEvent passes an event to the registered handlers
+func (c *Client) dispatchEvent(msg dap.Message) {
+	c.mu.Lock()
+	handlers := c.eventHandlers
+	c.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(msg)
+	}
 }
 
 // readLoop continuously reads messages from the transport
 func (c *Client) readLoop() {
 	defer c.wg.Done()
+	defer close(c.readDone)
 
 	consecutiveErrors := 0
 	const maxConsecutiveErrors = 5
@@ -117,6 +270,10 @@ func (c *Client) handleMessage(msg dap.Message) {
 	var requestSeq int
 	var isResponse bool
 
+	c.mu.Lock()
+	c.lastActivity = time.Now()
+	c.mu.Unlock()
+
 	switch m := msg.(type) {
 	case *dap.InitializeResponse:
 		requestSeq, isResponse = m.RequestSeq, true
@@ -160,35 +317,102 @@ func (c *Client) handleMessage(msg dap.Message) {
 		requestSeq, isResponse = m.RequestSeq, true
 	case *dap.ErrorResponse:
 		requestSeq, isResponse = m.RequestSeq, true
+	case dap.ResponseMessage:
+		// Other responses, e.g. to custom requests
+		requestSeq, isResponse = m.GetResponse().RequestSeq, true
 	case *dap.InitializedEvent:
 		// Signal that we received the initialized event
 		c.initializedOnce.Do(func() {
 			close(c.initialized)
 		})
-		if c.eventHandler != nil {
-			c.eventHandler(msg)
+		c.dispatchEvent(msg)
+		return
+	case *dap.ProcessEvent:
+		// Record the debuggee process; only the first event describes the launch
+		c.processOnce.Do(func() {
+			c.mu.Lock()
+			c.processInfo = &ProcessInfo{
+				Name:           m.Body.Name,
+				PID:            m.Body.SystemProcessId,
+				StartMethod:    m.Body.StartMethod,
+				IsLocalProcess: m.Body.IsLocalProcess,
+			}
+			c.mu.Unlock()
+			close(c.processStarted)
+		})
+		c.dispatchEvent(msg)
+		return
+	case *dap.CapabilitiesEvent:
+		// Adapters may add capabilities (e.g. exception filters) after initialize
+		c.mu.Lock()
+		c.capabilities = mergeCapabilities(c.capabilities, m.Body.Capabilities)
+		c.mu.Unlock()
+		c.dispatchEvent(msg)
+		return
+	case *dap.BreakpointEvent:
+		// Breakpoints are often verified later, e.g. once their module loads
+		c.updateBreakpoint(m.Body.Reason, m.Body.Breakpoint)
+		c.dispatchEvent(msg)
+		return
+	case *dap.OutputEvent:
+		c.recordOutput(m.Body)
+		c.dispatchEvent(msg)
+		return
+	case *dap.ThreadEvent:
+		c.updateThread(m.Body.Reason, m.Body.ThreadId)
+		c.dispatchEvent(msg)
+		return
+	case *dap.ModuleEvent:
+		// Modules loaded at runtime (dlopen, imports) are only reported here
+		c.updateModule(m.Body.Reason, m.Body.Module)
+		c.dispatchEvent(msg)
+		return
+	case *dap.ExitedEvent:
+		c.mu.Lock()
+		c.exited, c.exitCode = true, m.Body.ExitCode
+		c.output.wake()
+		c.mu.Unlock()
+		c.dispatchEvent(msg)
+		return
+	case *dap.TerminatedEvent:
+		c.mu.Lock()
+		c.terminated = true
+		c.output.wake()
+		c.mu.Unlock()
+		c.dispatchEvent(msg)
+		return
+	case *dap.ContinuedEvent:
+		// The adapter resumed the program on its own; the last stop and its
+		// variable references no longer apply
+		c.mu.Lock()
+		c.childCounts = make(map[int]ChildCounts)
+		c.running = true
+		c.lastStop = nil
+		if m.Body.AllThreadsContinued {
+			c.stoppedThreads = nil
+		} else {
+			c.stoppedThreads = withoutStoppedThread(c.stoppedThreads, m.Body.ThreadId)
 		}
+		c.mu.Unlock()
+		c.dispatchEvent(msg)
 		return
 	case *dap.StoppedEvent:
-		// Notify any waiters that we've stopped
 		info := &StoppedInfo{
 			Reason:      m.Body.Reason,
 			ThreadID:    m.Body.ThreadId,
 			Description: m.Body.Description,
+			Text:        m.Body.Text,
 			AllStopped:  m.Body.AllThreadsStopped,
+
+			HitBreakpointIDs: m.Body.HitBreakpointIds,
 		}
-		c.stoppedMu.Lock()
-		if c.stoppedChan != nil {
-			select {
-			case c.stoppedChan <- info:
-			default:
-				// Channel full, skip
-			}
-		}
-		c.stoppedMu.Unlock()
-		if c.eventHandler != nil {
-			c.eventHandler(msg)
+		if info.Reason == "breakpoint" && c.hasLogpoints() {
+			// Telling a logpoint stop apart takes requests, whose responses
+			// this loop has to read
+			go c.handleBreakpointStop(m, info)
+			return
 		}
+		c.deliverStop(m, info)
 		return
 	}
 
@@ -197,76 +421,75 @@ func (c *Client) handleMessage(msg dap.Message) {
 		if ch, ok := c.pendingRequests[requestSeq]; ok {
 			ch <- msg
 			delete(c.pendingRequests, requestSeq)
+			delete(c.pendingCommands, requestSeq)
 		}
 		c.mu.Unlock()
 		return
 	}
 
 	// Handle other events
-	if c.eventHandler != nil {
-		c.eventHandler(msg)
+	c.dispatchEvent(msg)
+}
+
+// deliverStop records a stop and reports it to the waiters and event handlers
+func (c *Client) deliverStop(event *dap.StoppedEvent, info *StoppedInfo) {
+	// Variable references from the previous stop are no longer valid
+	c.mu.Lock()
+	c.childCounts = make(map[int]ChildCounts)
+	if c.running {
+		c.stoppedThreads = nil
+	}
+	c.stoppedThreads = withStoppedThread(c.stoppedThreads, info)
+	c.running = false
+	c.lastStop = info
+	c.stopCount++
+	c.output.wake()
+	c.mu.Unlock()
+
+	// Notify any waiters that we've stopped
+	c.stoppedMu.Lock()
+	if c.stoppedChan != nil {
+		select {
+		case c.stoppedChan <- info:
+		default:
+			// Channel full, skip
+		}
 	}
+	c.stoppedMu.Unlock()
+	c.dispatchEvent(event)
 }
 
 // sendRequest sends a request and waits for the response
 func (c *Client) sendRequest(req dap.RequestMessage, timeout time.Duration) (dap.Message, error) {
-	seq := c.transport.NextSeq()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
 
-	// Set the sequence number on the request
-	switch r := req.(type) {
-	case *dap.InitializeRequest:
-		r.Seq = seq
-	case *dap.LaunchRequest:
-		r.Seq = seq
-	case *dap.AttachRequest:
-		r.Seq = seq
-	case *dap.DisconnectRequest:
-		r.Seq = seq
-	case *dap.ConfigurationDoneRequest:
-		r.Seq = seq
-	case *dap.ThreadsRequest:
-		r.Seq = seq
-	case *dap.StackTraceRequest:
-		r.Seq = seq
-	case *dap.ScopesRequest:
-		r.Seq = seq
-	case *dap.VariablesRequest:
-		r.Seq = seq
-	case *dap.EvaluateRequest:
-		r.Seq = seq
-	case *dap.SetBreakpointsRequest:
-		r.Seq = seq
-	case *dap.SetFunctionBreakpointsRequest:
-		r.Seq = seq
-	case *dap.ContinueRequest:
-		r.Seq = seq
-	case *dap.NextRequest:
-		r.Seq = seq
-	case *dap.StepInRequest:
-		r.Seq = seq
-	case *dap.StepOutRequest:
-		r.Seq = seq
-	case *dap.PauseRequest:
-		r.Seq = seq
-	case *dap.SetVariableRequest:
-		r.Seq = seq
-	case *dap.SourceRequest:
-		r.Seq = seq
-	case *dap.ModulesRequest:
-		r.Seq = seq
-	}
-
-	// Create response channel
-	respCh := make(chan dap.Message, 1)
+	// Wait for a slot when the adapter has as many requests as it may take
 	c.mu.Lock()
-	c.pendingRequests[seq] = respCh
+	slots := c.requestSlots
 	c.mu.Unlock()
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-deadline.C:
+			// Still queued, so the request has no seq yet
+			return nil, c.requestTimeout(req.GetRequest().Command, 0, timeout)
+		case <-c.readDone:
+			return nil, ErrAdapterGone
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		}
+	}
+
+	seq, respCh, err := c.register(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Send the request
 	if err := c.transport.Send(req); err != nil {
-		c.mu.Lock()
-		delete(c.pendingRequests, seq)
-		c.mu.Unlock()
+		c.removePending(seq)
 		return nil, err
 	}
 
@@ -274,18 +497,230 @@ func (c *Client) sendRequest(req dap.RequestMessage, timeout time.Duration) (dap
 	select {
 	case resp := <-respCh:
 		return resp, nil
-	case <-time.After(timeout):
-		c.mu.Lock()
-		delete(c.pendingRequests, seq)
-		c.mu.Unlock()
-		return nil, fmt.Errorf("request timeout")
+	case <-deadline.C:
+		c.removePending(seq)
+		return nil, c.requestTimeout(req.GetRequest().Command, seq, timeout)
+	case <-c.readDone:
+		// The response may have been delivered just before the connection closed
+		select {
+		case resp := <-respCh:
+			return resp, nil
+		default:
+		}
+		c.removePending(seq)
+		return nil, ErrAdapterGone
 	case <-c.ctx.Done():
 		return nil, c.ctx.Err()
 	}
 }
 
-// Initialize sends the initialize request
+// sendResumeRequest sends a request that resumes the program. The program is
+// marked running before the request is sent, so a stopped event that arrives
+// right after the response is not overwritten; if the request fails, the
+// program is still where it was and the stopped state is restored.
+func (c *Client) sendResumeRequest(req dap.RequestMessage, timeout time.Duration) (dap.Message, error) {
+	c.mu.Lock()
+	running, lastStop, stoppedThreads := c.running, c.lastStop, c.stoppedThreads
+	c.running, c.lastStop, c.stoppedThreads = true, nil, nil
+	c.mu.Unlock()
+
+	resp, err := c.sendRequest(req, timeout)
+	failed := err != nil
+	if r, ok := resp.(dap.ResponseMessage); ok && !r.GetResponse().Success {
+		failed = true
+	}
+	if failed {
+		c.mu.Lock()
+		// Unless a stopped event has arrived since
+		if c.running && c.lastStop == nil {
+			c.running, c.lastStop, c.stoppedThreads = running, lastStop, stoppedThreads
+		}
+		c.mu.Unlock()
+	}
+	return resp, err
+}
+
+// Running reports whether the program is running: it was resumed (by launch,
+// configurationDone, continue, a step, or the adapter itself) and has not
+// stopped since
+func (c *Client) Running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.running
+}
+
+// LastStop returns the stop the program is paused at, or nil while it runs
+// or before it first stops
+func (c *Client) LastStop() *StoppedInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lastStop == nil {
+		return nil
+	}
+	stop := *c.lastStop
+	return &stop
+}
+
+// StopCount returns the number of stopped events received, which identifies
+// the stop the program is paused at
+func (c *Client) StopCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopCount
+}
+
+// StoppedThreads returns every thread stopped since the program last resumed,
+// each with the reason of its own stopped event, oldest first
+func (c *Client) StoppedThreads() []StoppedInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stops := make([]StoppedInfo, len(c.stoppedThreads))
+	for i, stop := range c.stoppedThreads {
+		stops[i] = *stop
+	}
+	return stops
+}
+
+// withStoppedThread adds a stop to the stopped threads, replacing an earlier
+// stop of the same thread
+func withStoppedThread(stops []*StoppedInfo, info *StoppedInfo) []*StoppedInfo {
+	stops = withoutStoppedThread(stops, info.ThreadID)
+	return append(stops, info)
+}
+
+// withoutStoppedThread removes a thread from the stopped threads
+func withoutStoppedThread(stops []*StoppedInfo, threadID int) []*StoppedInfo {
+	kept := stops[:0:0]
+	for _, stop := range stops {
+		if stop.ThreadID != threadID {
+			kept = append(kept, stop)
+		}
+	}
+	return kept
+}
+
+// DebuggeeExited reports whether the adapter said the debuggee exited, and
+// its exit code
+func (c *Client) DebuggeeExited() (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.exitCode, c.exited
+}
+
+// Terminated reports whether the adapter said the debug session ended
+func (c *Client) Terminated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.terminated
+}
+
+// LastActivity returns when the last message arrived from the adapter, or the
+// zero time if none has
+func (c *Client) LastActivity() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastActivity
+}
+
+// Ping sends a threads request, the cheapest request every adapter answers,
+// and returns how long the response took. Any response counts, including a
+// failed one: it shows the adapter is reading and answering requests.
+func (c *Client) Ping(timeout time.Duration) (time.Duration, error) {
+	req := &dap.ThreadsRequest{
+		Request: dap.Request{
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
+			Command:         "threads",
+		},
+	}
+
+	start := time.Now()
+	if _, err := c.sendRequest(req, timeout); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// Alive reports whether the connection to the debug adapter is still open
+func (c *Client) Alive() bool {
+	select {
+	case <-c.readDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// register assigns a request its seq and registers the channel its response
+// is delivered on. Every request goes through it, so seqs are allocated and
+// registered in one step. A seq that is already pending is an error rather
+// than a silent overwrite that would lose the other request's response.
+func (c *Client) register(req dap.RequestMessage) (int, chan dap.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := req.GetRequest()
+	seq := c.transport.NextSeq()
+	if pending, ok := c.pendingCommands[seq]; ok {
+		log.Printf("DAP %s request got seq %d, which the pending %s request already has", r.Command, seq, pending)
+		return 0, nil, errors.DAPSeqCollision(r.Command, seq, pending)
+	}
+	r.Seq = seq
+
+	respCh := make(chan dap.Message, 1)
+	c.pendingRequests[seq] = respCh
+	c.pendingCommands[seq] = r.Command
+	return seq, respCh, nil
+}
+
+// removePending drops a request that will no longer be waited on
+func (c *Client) removePending(seq int) {
+	c.mu.Lock()
+	delete(c.pendingRequests, seq)
+	delete(c.pendingCommands, seq)
+	c.mu.Unlock()
+}
+
+// requestTimeout builds the error for a timed-out request and logs the requests
+// still waiting on the adapter, so a stuck adapter or deadlock is visible
+func (c *Client) requestTimeout(command string, seq int, timeout time.Duration) error {
+	c.mu.Lock()
+	seqs := make([]int, 0, len(c.pendingCommands))
+	for s := range c.pendingCommands {
+		seqs = append(seqs, s)
+	}
+	sort.Ints(seqs)
+	pending := make([]string, len(seqs))
+	for i, s := range seqs {
+		pending[i] = fmt.Sprintf("%s (seq %d)", c.pendingCommands[s], s)
+	}
+	c.mu.Unlock()
+
+	if len(pending) > 0 {
+		log.Printf("DAP %s request (seq %d) timed out after %s; still pending: %s", command, seq, timeout, strings.Join(pending, ", "))
+	} else {
+		log.Printf("DAP %s request (seq %d) timed out after %s; no other requests pending", command, seq, timeout)
+	}
+
+	return errors.DAPRequestTimeout(command, seq, timeout, pending)
+}
+
+// ClientID and ClientName identify the server in initialize requests
+const (
+	ClientID   = "dap-mcp"
+	ClientName = "DAP-MCP Server"
+)
+
+// Initialize sends the initialize request. Once it has succeeded, later calls
+// return the same response without sending another, since TCP adapters are
+// initialized while connecting (see adapters.Connect).
 func (c *Client) Initialize(clientID, clientName string) (*dap.InitializeResponse, error) {
+	c.mu.Lock()
+	done := c.initResponse
+	c.mu.Unlock()
+	if done != nil {
+		return done, nil
+	}
+
 	req := &dap.InitializeRequest{
 		Request: dap.Request{
 			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
@@ -301,6 +736,7 @@ func (c *Client) Initialize(clientID, clientName string) (*dap.InitializeRespons
 			PathFormat:                   "path",
 			SupportsVariableType:         true,
 			SupportsVariablePaging:       true,
+			SupportsMemoryReferences:     true,
 			SupportsRunInTerminalRequest: false,
 		},
 	}
@@ -319,7 +755,10 @@ func (c *Client) Initialize(clientID, clientName string) (*dap.InitializeRespons
 		return nil, fmt.Errorf("initialize failed: %s", initResp.Message)
 	}
 
+	c.mu.Lock()
 	c.capabilities = initResp.Body
+	c.initResponse = initResp
+	c.mu.Unlock()
 
 	return initResp, nil
 }
@@ -336,9 +775,9 @@ func (c *Client) WaitInitialized(timeout time.Duration) error {
 	}
 }
 
-// Launch sends a launch request
-// Note: After calling Launch, caller should wait for InitializedEvent, then call ConfigurationDone
-// The launch response may not arrive until after ConfigurationDone is sent
+// Launch sends a launch request and waits for the response. It is for adapters
+// that answer launch without the initialized/configurationDone handshake;
+// others (e.g. debugpy) don't respond until configurationDone, so use LaunchAsync.
 func (c *Client) Launch(args map[string]interface{}) (*dap.LaunchResponse, error) {
 	argsJSON, err := json.Marshal(args)
 	if err != nil {
@@ -354,21 +793,31 @@ func (c *Client) Launch(args map[string]interface{}) (*dap.LaunchResponse, error
 	}
 
 	// Send the request but use a longer timeout since debugpy may not respond until after configurationDone
-	resp, err := c.sendRequest(req, 30*time.Second)
+	resp, err := c.sendResumeRequest(req, 30*time.Second)
 	if err != nil {
 		return nil, err
 	}
 
-	launchResp, ok := resp.(*dap.LaunchResponse)
-	if !ok {
-		return nil, fmt.Errorf("unexpected response type: %T", resp)
-	}
+	return launchResult(resp)
+}
 
-	if !launchResp.Success {
-		return nil, fmt.Errorf("launch failed: %s", launchResp.Message)
+// launchResult returns the launch response, or the adapter's reason for
+// failing the launch. Failed responses decode as ErrorResponse.
+func launchResult(resp dap.Message) (*dap.LaunchResponse, error) {
+	switch r := resp.(type) {
+	case *dap.LaunchResponse:
+		if !r.Success {
+			return nil, fmt.Errorf("launch failed: %s", r.Message)
+		}
+		return r, nil
+	case *dap.ErrorResponse:
+		if r.Body.Error != nil && r.Body.Error.Format != "" {
+			return nil, fmt.Errorf("launch failed: %s", r.Body.Error.Format)
+		}
+		return nil, fmt.Errorf("launch failed: %s", r.Message)
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
 	}
-
-	return launchResp, nil
 }
 
 // LaunchAsync sends a launch request without waiting for response
@@ -379,45 +828,50 @@ func (c *Client) LaunchAsync(args map[string]interface{}) (chan dap.Message, err
 		return nil, fmt.Errorf("failed to marshal launch args: %w", err)
 	}
 
-	seq := c.transport.NextSeq()
-
 	req := &dap.LaunchRequest{
 		Request: dap.Request{
-			ProtocolMessage: dap.ProtocolMessage{Type: "request", Seq: seq},
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
 			Command:         "launch",
 		},
 		Arguments: argsJSON,
 	}
 
-	// Create response channel
-	respCh := make(chan dap.Message, 1)
-	c.mu.Lock()
-	c.pendingRequests[seq] = respCh
-	c.mu.Unlock()
+	seq, respCh, err := c.register(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Send the request
 	if err := c.transport.Send(req); err != nil {
-		c.mu.Lock()
-		delete(c.pendingRequests, seq)
-		c.mu.Unlock()
+		c.removePending(seq)
 		return nil, err
 	}
 
 	return respCh, nil
 }
 
+// WaitInitializedOrLaunch waits for the initialized event or the launch
+// response, whichever arrives first. It returns true if the launch response
+// came first, which adapters that skip the configuration handshake do.
+func (c *Client) WaitInitializedOrLaunch(respCh chan dap.Message, timeout time.Duration) (bool, error) {
+	select {
+	case <-c.initialized:
+		return false, nil
+	case resp := <-respCh:
+		_, err := launchResult(resp)
+		return true, err
+	case <-time.After(timeout):
+		return false, fmt.Errorf("timeout waiting for initialized event")
+	case <-c.ctx.Done():
+		return false, c.ctx.Err()
+	}
+}
+
 // WaitForLaunchResponse waits for the launch response on the channel
 func (c *Client) WaitForLaunchResponse(respCh chan dap.Message, timeout time.Duration) (*dap.LaunchResponse, error) {
 	select {
 	case resp := <-respCh:
-		launchResp, ok := resp.(*dap.LaunchResponse)
-		if !ok {
-			return nil, fmt.Errorf("unexpected response type: %T", resp)
-		}
-		if !launchResp.Success {
-			return nil, fmt.Errorf("launch failed: %s", launchResp.Message)
-		}
-		return launchResp, nil
+		return launchResult(resp)
 	case <-time.After(timeout):
 		return nil, fmt.Errorf("launch response timeout")
 	case <-c.ctx.Done():
@@ -425,6 +879,43 @@ func (c *Client) WaitForLaunchResponse(respCh chan dap.Message, timeout time.Dur
 	}
 }
 
+// launchSettleTimeout is how long WaitForLaunch still waits for the launch
+// response once the debuggee has started, since it can report a failure
+const launchSettleTimeout = 250 * time.Millisecond
+
+// WaitForLaunch waits until the launch is confirmed, either by a successful
+// launch response or by a process event reporting the debuggee has started,
+// whichever arrives first. After a process event, a launch response arriving
+// within launchSettleTimeout still decides the outcome.
+func (c *Client) WaitForLaunch(respCh chan dap.Message, timeout time.Duration) error {
+	select {
+	case resp := <-respCh:
+		_, err := launchResult(resp)
+		return err
+	case <-c.processStarted:
+	case <-time.After(timeout):
+		return fmt.Errorf("launch response timeout")
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+
+	select {
+	case resp := <-respCh:
+		_, err := launchResult(resp)
+		return err
+	case <-time.After(launchSettleTimeout):
+		return nil
+	}
+}
+
+// ProcessInfo returns the debuggee process reported by the adapter, or nil if
+// no process event has been received
+func (c *Client) ProcessInfo() *ProcessInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.processInfo
+}
+
 // Attach sends an attach request
 func (c *Client) Attach(args map[string]interface{}) (*dap.AttachResponse, error) {
 	argsJSON, err := json.Marshal(args)
@@ -465,27 +956,22 @@ func (c *Client) AttachAsync(args map[string]interface{}) (chan dap.Message, err
 		return nil, fmt.Errorf("failed to marshal attach args: %w", err)
 	}
 
-	seq := c.transport.NextSeq()
-
 	req := &dap.AttachRequest{
 		Request: dap.Request{
-			ProtocolMessage: dap.ProtocolMessage{Type: "request", Seq: seq},
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
 			Command:         "attach",
 		},
 		Arguments: argsJSON,
 	}
 
-	// Create response channel
-	respCh := make(chan dap.Message, 1)
-	c.mu.Lock()
-	c.pendingRequests[seq] = respCh
-	c.mu.Unlock()
+	seq, respCh, err := c.register(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Send the request
 	if err := c.transport.Send(req); err != nil {
-		c.mu.Lock()
-		delete(c.pendingRequests, seq)
-		c.mu.Unlock()
+		c.removePending(seq)
 		return nil, err
 	}
 
@@ -511,8 +997,20 @@ func (c *Client) WaitForAttachResponse(respCh chan dap.Message, timeout time.Dur
 	}
 }
 
-// ConfigurationDone signals that configuration is complete
+// SupportsConfigurationDone reports whether the adapter accepts the
+// configurationDone request (its supportsConfigurationDoneRequest capability)
+func (c *Client) SupportsConfigurationDone() bool {
+	return c.Capabilities().SupportsConfigurationDoneRequest
+}
+
+// ConfigurationDone signals that configuration is complete. It is skipped for
+// adapters without the capability: they would reject the request, and end
+// their configuration phase on their own.
 func (c *Client) ConfigurationDone() error {
+	if !c.SupportsConfigurationDone() {
+		return nil
+	}
+
 	req := &dap.ConfigurationDoneRequest{
 		Request: dap.Request{
 			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
@@ -520,7 +1018,7 @@ func (c *Client) ConfigurationDone() error {
 		},
 	}
 
-	resp, err := c.sendRequest(req, 10*time.Second)
+	resp, err := c.sendResumeRequest(req, 10*time.Second)
 	if err != nil {
 		return err
 	}
@@ -539,6 +1037,11 @@ func (c *Client) ConfigurationDone() error {
 
 // Disconnect ends the debug session
 func (c *Client) Disconnect(terminateDebuggee bool) error {
+	// A crashed adapter cannot answer; don't wait for the request timeout
+	if !c.Alive() {
+		return ErrAdapterGone
+	}
+
 	req := &dap.DisconnectRequest{
 		Request: dap.Request{
 			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
@@ -589,9 +1092,68 @@ func (c *Client) Threads() ([]dap.Thread, error) {
 		return nil, fmt.Errorf("threads request failed: %s", threadsResp.Message)
 	}
 
+	c.mu.Lock()
+	for _, t := range threadsResp.Body.Threads {
+		state, ok := c.threads[t.Id]
+		if !ok {
+			state = &ThreadState{ID: t.Id}
+			c.threads[t.Id] = state
+		}
+		state.Name = t.Name
+		// An exit event may race ahead of a threads response that still lists the thread
+		if state.Status != ThreadExited {
+			state.Status = ThreadRunning
+		}
+	}
+	c.mu.Unlock()
+
 	return threadsResp.Body.Threads, nil
 }
 
+// updateThread applies a thread event
+func (c *Client) updateThread(reason string, threadID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.threads[threadID]
+	if !ok {
+		state = &ThreadState{ID: threadID}
+		c.threads[threadID] = state
+	}
+
+	switch reason {
+	case "started":
+		// Thread IDs can be reused once a thread has exited
+		state.Status = ThreadStarted
+		state.ExitedAt = time.Time{}
+	case "exited":
+		state.Status = ThreadExited
+		state.ExitedAt = time.Now()
+	}
+}
+
+// ThreadStates returns the tracked threads ordered by ID
+func (c *Client) ThreadStates() []ThreadState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	states := make([]ThreadState, 0, len(c.threads))
+	for _, state := range c.threads {
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
+
+// ThreadExited reports whether a thread event said the thread has exited
+func (c *Client) ThreadExited(threadID int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.threads[threadID]
+	return ok && state.Status == ThreadExited
+}
+
 // StackTrace gets the stack trace for a thread
 func (c *Client) StackTrace(threadID, startFrame, levels int) ([]dap.StackFrame, int, error) {
 	req := &dap.StackTraceRequest{
@@ -615,12 +1177,103 @@ func (c *Client) StackTrace(threadID, startFrame, levels int) ([]dap.StackFrame,
 	if !ok {
 		return nil, 0, fmt.Errorf("unexpected response type: %T", resp)
 	}
-
-	if !stackResp.Success {
-		return nil, 0, fmt.Errorf("stackTrace request failed: %s", stackResp.Message)
+
+	if !stackResp.Success {
+		return nil, 0, fmt.Errorf("stackTrace request failed: %s", stackResp.Message)
+	}
+
+	return stackResp.Body.StackFrames, stackResp.Body.TotalFrames, nil
+}
+
+// stackCountPageSize is the fewest frames requested per page when counting a
+// stack whose adapter left totalFrames out
+const stackCountPageSize = 100
+
+// maxStackCountFrames bounds the frames counted to synthesize a stack's total
+const maxStackCountFrames = 10000
+
+// StackPage is a page of a thread's stack, with the stack's depth
+type StackPage struct {
+	Frames []dap.StackFrame
+	// TotalFrames is the depth of the stack. When the adapter reported none,
+	// StackTracePage counts it by paging through the stack, and
+	// StackTracePeek leaves it 0 if the stack goes on past Frames.
+	TotalFrames int
+	// HasMore reports whether the stack goes on past Frames
+	HasMore bool
+}
+
+// StackTracePage gets up to levels frames of a thread's stack from startFrame,
+// along with the stack's depth. Some adapters report a totalFrames of 0 even
+// when more frames exist; when one returns a full page without a total, the
+// following pages are requested until one comes back short, and the total is
+// the number of frames seen.
+func (c *Client) StackTracePage(threadID, startFrame, levels int) (*StackPage, error) {
+	frames, total, err := c.StackTrace(threadID, startFrame, levels)
+	if err != nil {
+		return nil, err
+	}
+	page := &StackPage{Frames: frames, TotalFrames: total}
+	end := startFrame + len(frames)
+	if total > 0 {
+		page.HasMore = end < total
+		return page, nil
+	}
+	// Levels 0 asks for every frame
+	if levels <= 0 || len(frames) < levels {
+		page.TotalFrames = end
+		return page, nil
+	}
+
+	size := max(levels, stackCountPageSize)
+	count := end
+	for count < startFrame+maxStackCountFrames {
+		more, _, err := c.StackTrace(threadID, count, size)
+		if err != nil || len(more) == 0 {
+			break
+		}
+		// An adapter that ignores startFrame returns the same frames again
+		if len(frames) > 0 && more[0].Id == frames[0].Id {
+			break
+		}
+		count += len(more)
+		if len(more) < size {
+			break
+		}
+	}
+	page.TotalFrames = count
+	page.HasMore = count > end
+	return page, nil
+}
+
+// StackTracePeek is StackTracePage without the counting: when the adapter
+// reports no total for a full page, a one-frame request past it tells
+// whether the stack goes on. It costs at most two requests, for callers that
+// read many stacks.
+func (c *Client) StackTracePeek(threadID, startFrame, levels int) (*StackPage, error) {
+	frames, total, err := c.StackTrace(threadID, startFrame, levels)
+	if err != nil {
+		return nil, err
+	}
+	page := &StackPage{Frames: frames, TotalFrames: total}
+	end := startFrame + len(frames)
+	if total > 0 {
+		page.HasMore = end < total
+		return page, nil
+	}
+	if levels <= 0 || len(frames) < levels {
+		page.TotalFrames = end
+		return page, nil
 	}
 
-	return stackResp.Body.StackFrames, stackResp.Body.TotalFrames, nil
+	more, _, err := c.StackTrace(threadID, end, 1)
+	// An adapter that ignores startFrame returns the same frames again
+	if err == nil && len(more) > 0 && (len(frames) == 0 || more[0].Id != frames[0].Id) {
+		page.HasMore = true
+	} else {
+		page.TotalFrames = end
+	}
+	return page, nil
 }
 
 // Scopes gets the scopes for a stack frame
@@ -649,6 +1302,10 @@ func (c *Client) Scopes(frameID int) ([]dap.Scope, error) {
 		return nil, fmt.Errorf("scopes request failed: %s", scopesResp.Message)
 	}
 
+	for _, scope := range scopesResp.Body.Scopes {
+		c.recordChildCounts(scope.VariablesReference, scope.IndexedVariables, scope.NamedVariables)
+	}
+
 	return scopesResp.Body.Scopes, nil
 }
 
@@ -666,7 +1323,20 @@ func (c *Client) Variables(variablesRef int, filter string, start, count int) ([
 	if count > 0 {
 		args.Count = count
 	}
+	return c.variables(args)
+}
 
+// VariablesWithFormat gets all variables for a reference with their values
+// formatted as requested, e.g. in hex. Adapters without
+// supportsValueFormattingOptions ignore the format.
+func (c *Client) VariablesWithFormat(variablesRef int, format *dap.ValueFormat) ([]dap.Variable, error) {
+	return c.variables(dap.VariablesArguments{
+		VariablesReference: variablesRef,
+		Format:             format,
+	})
+}
+
+func (c *Client) variables(args dap.VariablesArguments) ([]dap.Variable, error) {
 	req := &dap.VariablesRequest{
 		Request: dap.Request{
 			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
@@ -689,9 +1359,34 @@ func (c *Client) Variables(variablesRef int, filter string, start, count int) ([
 		return nil, fmt.Errorf("variables request failed: %s", varsResp.Message)
 	}
 
+	for _, v := range varsResp.Body.Variables {
+		c.recordChildCounts(v.VariablesReference, v.IndexedVariables, v.NamedVariables)
+	}
+
 	return varsResp.Body.Variables, nil
 }
 
+// recordChildCounts remembers the child counts reported for a reference so
+// pages of its children can report the totals
+func (c *Client) recordChildCounts(variablesRef, indexed, named int) {
+	if variablesRef <= 0 || (indexed == 0 && named == 0) {
+		return
+	}
+	c.mu.Lock()
+	c.childCounts[variablesRef] = ChildCounts{Indexed: indexed, Named: named}
+	c.mu.Unlock()
+}
+
+// ChildCounts returns the child counts the adapter reported along with a
+// variablesReference, if it reported any since the program last stopped
+func (c *Client) ChildCounts(variablesRef int) (ChildCounts, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts, ok := c.childCounts[variablesRef]
+	return counts, ok
+}
+
 // Evaluate evaluates an expression
 func (c *Client) Evaluate(expression string, frameID int, context string) (*dap.EvaluateResponseBody, error) {
 	req := &dap.EvaluateRequest{
@@ -711,6 +1406,14 @@ func (c *Client) Evaluate(expression string, frameID int, context string) (*dap.
 		return nil, err
 	}
 
+	// Failed responses decode as ErrorResponse, which carries the reason
+	if errResp, ok := resp.(*dap.ErrorResponse); ok {
+		if errResp.Body.Error != nil && errResp.Body.Error.Format != "" {
+			return nil, fmt.Errorf("evaluate failed: %s", errResp.Body.Error.Format)
+		}
+		return nil, fmt.Errorf("evaluate failed: %s", errResp.Message)
+	}
+
 	evalResp, ok := resp.(*dap.EvaluateResponse)
 	if !ok {
 		return nil, fmt.Errorf("unexpected response type: %T", resp)
@@ -720,6 +1423,8 @@ func (c *Client) Evaluate(expression string, frameID int, context string) (*dap.
 		return nil, fmt.Errorf("evaluate failed: %s", evalResp.Message)
 	}
 
+	c.recordChildCounts(evalResp.Body.VariablesReference, evalResp.Body.IndexedVariables, evalResp.Body.NamedVariables)
+
 	return &evalResp.Body, nil
 }
 
@@ -750,9 +1455,69 @@ func (c *Client) SetBreakpoints(source dap.Source, breakpoints []dap.SourceBreak
 		return nil, fmt.Errorf("setBreakpoints failed: %s", bpResp.Message)
 	}
 
+	c.mu.Lock()
+	c.breakpoints[source.Path] = append([]dap.Breakpoint(nil), bpResp.Body.Breakpoints...)
+	c.mu.Unlock()
+	c.trackLogpoints(source.Path, breakpoints, bpResp.Body.Breakpoints)
+
 	return bpResp.Body.Breakpoints, nil
 }
 
+// Breakpoints returns the current state of the source breakpoints by path,
+// including verification reported by breakpoint events after they were set
+func (c *Client) Breakpoints() map[string][]dap.Breakpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	all := make(map[string][]dap.Breakpoint, len(c.breakpoints))
+	for path, bps := range c.breakpoints {
+		all[path] = append([]dap.Breakpoint(nil), bps...)
+	}
+	return all
+}
+
+// updateBreakpoint applies a breakpoint event. Changed and removed breakpoints
+// are found by ID; the event's other fields are the new values.
+func (c *Client) updateBreakpoint(reason string, bp dap.Breakpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if reason == "new" {
+		if bp.Source != nil && bp.Source.Path != "" {
+			c.breakpoints[bp.Source.Path] = append(c.breakpoints[bp.Source.Path], bp)
+		}
+		return
+	}
+	if bp.Id == 0 {
+		return // Breakpoints without an ID can't be matched
+	}
+
+	for path, bps := range c.breakpoints {
+		for i := range bps {
+			if bps[i].Id != bp.Id {
+				continue
+			}
+			if reason == "removed" {
+				c.breakpoints[path] = append(bps[:i:i], bps[i+1:]...)
+				return
+			}
+			tracked := &bps[i]
+			tracked.Verified = bp.Verified
+			tracked.Message = bp.Message
+			if bp.Line != 0 {
+				tracked.Line = bp.Line
+			}
+			if bp.Column != 0 {
+				tracked.Column = bp.Column
+			}
+			if bp.EndLine != 0 {
+				tracked.EndLine = bp.EndLine
+			}
+			return
+		}
+	}
+}
+
 // SetFunctionBreakpoints sets function breakpoints
 func (c *Client) SetFunctionBreakpoints(breakpoints []dap.FunctionBreakpoint) ([]dap.Breakpoint, error) {
 	req := &dap.SetFunctionBreakpointsRequest{
@@ -784,17 +1549,25 @@ func (c *Client) SetFunctionBreakpoints(breakpoints []dap.FunctionBreakpoint) ([
 
 // Continue continues execution
 func (c *Client) Continue(threadID int) (bool, error) {
+	return c.ContinueWithOptions(threadID, false)
+}
+
+// ContinueWithOptions resumes execution. When singleThread is true, only the
+// given thread is resumed; this requires the adapter to report
+// SupportsSingleThreadExecutionRequests. Returns whether all threads continued.
+func (c *Client) ContinueWithOptions(threadID int, singleThread bool) (bool, error) {
 	req := &dap.ContinueRequest{
 		Request: dap.Request{
 			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
 			Command:         "continue",
 		},
 		Arguments: dap.ContinueArguments{
-			ThreadId: threadID,
+			ThreadId:     threadID,
+			SingleThread: singleThread,
 		},
 	}
 
-	resp, err := c.sendRequest(req, 10*time.Second)
+	resp, err := c.sendResumeRequest(req, 10*time.Second)
 	if err != nil {
 		return false, err
 	}
@@ -823,7 +1596,7 @@ func (c *Client) Next(threadID int) error {
 		},
 	}
 
-	resp, err := c.sendRequest(req, 10*time.Second)
+	resp, err := c.sendResumeRequest(req, 10*time.Second)
 	if err != nil {
 		return err
 	}
@@ -852,7 +1625,7 @@ func (c *Client) StepIn(threadID int) error {
 		},
 	}
 
-	resp, err := c.sendRequest(req, 10*time.Second)
+	resp, err := c.sendResumeRequest(req, 10*time.Second)
 	if err != nil {
 		return err
 	}
@@ -881,7 +1654,7 @@ func (c *Client) StepOut(threadID int) error {
 		},
 	}
 
-	resp, err := c.sendRequest(req, 10*time.Second)
+	resp, err := c.sendResumeRequest(req, 10*time.Second)
 	if err != nil {
 		return err
 	}
@@ -958,6 +1731,63 @@ func (c *Client) SetVariable(variablesRef int, name, value string) (*dap.SetVari
 	return &setResp.Body, nil
 }
 
+// rawRequest is a request for a command without a typed go-dap request,
+// such as an adapter-specific custom request
+type rawRequest struct {
+	dap.Request
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// SendRaw sends a command with raw JSON arguments and waits up to timeout for
+// the response with the matching request seq. Typed responses are re-encoded,
+// so the result always carries the raw body; failed responses are returned
+// with Success false rather than as an error.
+func (c *Client) SendRaw(command string, args json.RawMessage, timeout time.Duration) (*RawResponse, error) {
+	req := &rawRequest{
+		Request: dap.Request{
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
+			Command:         command,
+		},
+		Arguments: args,
+	}
+
+	resp, err := c.sendRequest(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := resp.(*RawResponse); ok {
+		return raw, nil
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s response: %w", command, err)
+	}
+	var raw RawResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", command, err)
+	}
+	return &raw, nil
+}
+
+// CustomRequest sends an arbitrary command with raw JSON arguments and returns
+// the raw body of the adapter's response
+func (c *Client) CustomRequest(command string, args json.RawMessage) (json.RawMessage, error) {
+	resp, err := c.SendRaw(command, args, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		var body dap.ErrorResponseBody
+		if json.Unmarshal(resp.Body, &body) == nil && body.Error != nil && body.Error.Format != "" {
+			return nil, fmt.Errorf("%s failed: %s", command, body.Error.Format)
+		}
+		return nil, fmt.Errorf("%s failed: %s", command, resp.Message)
+	}
+	return resp.Body, nil
+}
+
 // Source gets source code
 func (c *Client) Source(sourceRef int, path string) (string, string, error) {
 	req := &dap.SourceRequest{
@@ -1018,17 +1848,235 @@ func (c *Client) Modules(startModule, moduleCount int) ([]dap.Module, int, error
 		return nil, 0, fmt.Errorf("modules request failed: %s", modulesResp.Message)
 	}
 
+	for _, module := range modulesResp.Body.Modules {
+		c.updateModule("new", module)
+	}
+
 	return modulesResp.Body.Modules, modulesResp.Body.TotalModules, nil
 }
 
+// updateModule applies a module event (or a module listed by a modules
+// response, as "new") to the tracked module set
+func (c *Client) updateModule(reason string, module dap.Module) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := fmt.Sprint(module.Id)
+	if reason == "removed" {
+		delete(c.modules, id)
+		return
+	}
+	if loaded, ok := c.modules[id]; ok {
+		loaded.Module = module
+		return
+	}
+	c.moduleSeq++
+	c.modules[id] = &LoadedModule{Module: module, Seq: c.moduleSeq}
+}
+
+// LoadedModules returns the tracked modules in the order they were first
+// reported, and the sequence number of the latest
+func (c *Client) LoadedModules() ([]LoadedModule, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modules := make([]LoadedModule, 0, len(c.modules))
+	for _, module := range c.modules {
+		modules = append(modules, *module)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Seq < modules[j].Seq })
+	return modules, c.moduleSeq
+}
+
+// LoadedSources gets the sources the adapter has loaded
+func (c *Client) LoadedSources() ([]dap.Source, error) {
+	req := &dap.LoadedSourcesRequest{
+		Request: dap.Request{
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
+			Command:         "loadedSources",
+		},
+	}
+
+	resp, err := c.sendRequest(req, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesResp, ok := resp.(*dap.LoadedSourcesResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	if !sourcesResp.Success {
+		return nil, fmt.Errorf("loadedSources request failed: %s", sourcesResp.Message)
+	}
+
+	return sourcesResp.Body.Sources, nil
+}
+
+// ExceptionInfo gets details of the exception a thread is stopped on
+func (c *Client) ExceptionInfo(threadID int) (*dap.ExceptionInfoResponseBody, error) {
+	req := &dap.ExceptionInfoRequest{
+		Request: dap.Request{
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
+			Command:         "exceptionInfo",
+		},
+		Arguments: dap.ExceptionInfoArguments{
+			ThreadId: threadID,
+		},
+	}
+
+	resp, err := c.sendRequest(req, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	infoResp, ok := resp.(*dap.ExceptionInfoResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	if !infoResp.Success {
+		return nil, fmt.Errorf("exceptionInfo request failed: %s", infoResp.Message)
+	}
+
+	return &infoResp.Body, nil
+}
+
+// ReadMemory reads count bytes at offset from a memory reference. The data in
+// the result is base64 encoded and may be shorter than count.
+func (c *Client) ReadMemory(memoryReference string, offset, count int) (*dap.ReadMemoryResponseBody, error) {
+	req := &dap.ReadMemoryRequest{
+		Request: dap.Request{
+			ProtocolMessage: dap.ProtocolMessage{Type: "request"},
+			Command:         "readMemory",
+		},
+		Arguments: dap.ReadMemoryArguments{
+			MemoryReference: memoryReference,
+			Offset:          offset,
+			Count:           count,
+		},
+	}
+
+	resp, err := c.sendRequest(req, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryResp, ok := resp.(*dap.ReadMemoryResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	if !memoryResp.Success {
+		return nil, fmt.Errorf("readMemory request failed: %s", memoryResp.Message)
+	}
+
+	return &memoryResp.Body, nil
+}
+
 // Capabilities returns the capabilities from the initialize response
 func (c *Client) Capabilities() dap.Capabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.capabilities
 }
 
+// mergeCapabilities applies the capabilities from a capabilities event on top
+// of the current set. Per the DAP spec, the event only carries capabilities that
+// changed. The decoded event can't distinguish an omitted flag from false, so
+// only flags and lists that are set in the event are applied.
+func mergeCapabilities(current, update dap.Capabilities) dap.Capabilities {
+	changed, err := json.Marshal(update) // omitempty drops unset fields
+	if err != nil {
+		return current
+	}
+	merged := current
+	if err := json.Unmarshal(changed, &merged); err != nil {
+		return current
+	}
+	return merged
+}
+
 // WaitForStopped waits for the debugger to stop (hit breakpoint, step complete, etc.)
 func (c *Client) WaitForStopped(timeout time.Duration) (*StoppedInfo, error) {
-	// Create channel to receive stopped event
+	return c.ExpectStopped()(timeout)
+}
+
+// ExpectStopped starts listening for the next stopped event and returns a
+// function that waits for it. Use it when the event may arrive before the
+// caller is ready to wait, e.g. a stop at entry right after configurationDone.
+func (c *Client) ExpectStopped() func(timeout time.Duration) (*StoppedInfo, error) {
+	stoppedCh := make(chan *StoppedInfo, 1)
+
+	c.stoppedMu.Lock()
+	c.stoppedChan = stoppedCh
+	c.stoppedMu.Unlock()
+
+	return func(timeout time.Duration) (*StoppedInfo, error) {
+		defer func() {
+			c.stoppedMu.Lock()
+			if c.stoppedChan == stoppedCh {
+				c.stoppedChan = nil
+			}
+			c.stoppedMu.Unlock()
+		}()
+
+		select {
+		case info := <-stoppedCh:
+			return info, nil
+		case <-time.After(timeout):
+			return nil, ErrStopTimeout
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		}
+	}
+}
+
+// ContinueAndWait continues execution and waits for the program to stop
+func (c *Client) ContinueAndWait(threadID int, timeout time.Duration) (*StoppedInfo, error) {
+	return c.ContinueAndWaitContext(context.Background(), threadID, timeout)
+}
+
+// ContinueAndWaitContext is ContinueAndWait that also stops waiting when ctx
+// is done, returning ctx's error. The program keeps running in that case.
+func (c *Client) ContinueAndWaitContext(ctx context.Context, threadID int, timeout time.Duration) (*StoppedInfo, error) {
+	// Set up to receive stopped event before continuing
+	stoppedCh := make(chan *StoppedInfo, 1)
+
+	c.stoppedMu.Lock()
+	c.stoppedChan = stoppedCh
+	c.stoppedMu.Unlock()
+
+	defer func() {
+		c.stoppedMu.Lock()
+		c.stoppedChan = nil
+		c.stoppedMu.Unlock()
+	}()
+
+	// Send continue request
+	_, err := c.Continue(threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for stopped event
+	select {
+	case info := <-stoppedCh:
+		return info, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("%w after continue", ErrStopTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+}
+
+// PauseAndWait pauses a thread and waits for the resulting stopped event
+func (c *Client) PauseAndWait(threadID int, timeout time.Duration) (*StoppedInfo, error) {
+	// Set up to receive stopped event before pausing
 	stoppedCh := make(chan *StoppedInfo, 1)
 
 	c.stoppedMu.Lock()
@@ -1041,19 +2089,24 @@ func (c *Client) WaitForStopped(timeout time.Duration) (*StoppedInfo, error) {
 		c.stoppedMu.Unlock()
 	}()
 
+	if err := c.Pause(threadID); err != nil {
+		return nil, err
+	}
+
 	select {
 	case info := <-stoppedCh:
 		return info, nil
 	case <-time.After(timeout):
-		return nil, fmt.Errorf("timeout waiting for stopped event")
+		return nil, fmt.Errorf("%w after pause", ErrStopTimeout)
 	case <-c.ctx.Done():
 		return nil, c.ctx.Err()
 	}
 }
 
-// ContinueAndWait continues execution and waits for the program to stop
-func (c *Client) ContinueAndWait(threadID int, timeout time.Duration) (*StoppedInfo, error) {
-	// Set up to receive stopped event before continuing
+// StepOutAndWait steps out of the current function and waits for the resulting
+// stopped event
+func (c *Client) StepOutAndWait(threadID int, timeout time.Duration) (*StoppedInfo, error) {
+	// Set up to receive stopped event before stepping
 	stoppedCh := make(chan *StoppedInfo, 1)
 
 	c.stoppedMu.Lock()
@@ -1066,26 +2119,89 @@ func (c *Client) ContinueAndWait(threadID int, timeout time.Duration) (*StoppedI
 		c.stoppedMu.Unlock()
 	}()
 
-	// Send continue request
-	_, err := c.Continue(threadID)
-	if err != nil {
+	if err := c.StepOut(threadID); err != nil {
 		return nil, err
 	}
 
-	// Wait for stopped event
 	select {
 	case info := <-stoppedCh:
 		return info, nil
 	case <-time.After(timeout):
-		return nil, fmt.Errorf("timeout waiting for stopped event after continue")
+		return nil, fmt.Errorf("%w after stepOut", ErrStopTimeout)
 	case <-c.ctx.Done():
 		return nil, c.ctx.Err()
 	}
 }
 
+// StepAndWaitContext steps a thread ("over", "into", or "out") and waits for
+// the resulting stop. It returns ErrProgramEnded if the program exits first,
+// and ctx's error if ctx is done first, leaving the program running.
+func (c *Client) StepAndWaitContext(ctx context.Context, threadID int, stepType string, timeout time.Duration) (*StoppedInfo, error) {
+	// Set up to receive stopped event before stepping
+	stoppedCh := make(chan *StoppedInfo, 1)
+
+	c.stoppedMu.Lock()
+	c.stoppedChan = stoppedCh
+	c.stoppedMu.Unlock()
+
+	defer func() {
+		c.stoppedMu.Lock()
+		c.stoppedChan = nil
+		c.stoppedMu.Unlock()
+	}()
+
+	var err error
+	switch stepType {
+	case "over":
+		err = c.Next(threadID)
+	case "into":
+		err = c.StepIn(threadID)
+	case "out":
+		err = c.StepOut(threadID)
+	default:
+		err = fmt.Errorf("unknown step type %q", stepType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		// Stops, exits, and output all wake the output log
+		c.mu.Lock()
+		ended := c.exited || c.terminated
+		changed := c.output.waitChannel()
+		c.mu.Unlock()
+
+		select {
+		case info := <-stoppedCh:
+			return info, nil
+		default:
+		}
+		if ended {
+			return nil, ErrProgramEnded
+		}
+
+		select {
+		case info := <-stoppedCh:
+			return info, nil
+		case <-changed:
+		case <-timer.C:
+			return nil, fmt.Errorf("%w after step %s", ErrStopTimeout, stepType)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.readDone:
+			return nil, ErrAdapterGone
+		}
+	}
+}
+
 // Close shuts down the client
 func (c *Client) Close() error {
 	c.cancel()
+	// Closing the transport unblocks a read loop waiting on the adapter
+	err := c.transport.Close()
 	c.wg.Wait()
-	return c.transport.Close()
+	return err
 }
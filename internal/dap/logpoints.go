@@ -0,0 +1,206 @@
+package dap
+
+import (
+	"strings"
+
+	"github.com/google/go-dap"
+)
+
+// emulatedLogpoint is a logpoint set on an adapter without supportsLogPoints,
+// which stops at it like at any breakpoint
+type emulatedLogpoint struct {
+	id      int
+	line    int
+	message string
+}
+
+// SetEmulateLogpoints makes the client treat logpoints as non-breaking on
+// adapters that don't support them: when every breakpoint a stop hit is a
+// logpoint, the client evaluates the message's {expression} parts, records
+// it as console output, and continues the thread instead of reporting the
+// stop. Adapters that report supportsLogPoints log without stopping and are
+// unaffected. Call it before setting breakpoints.
+func (c *Client) SetEmulateLogpoints(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.emulateLogpoints = enabled
+}
+
+// LogpointEvaluator evaluates an {expression} of an emulated logpoint's
+// message in a frame. It returns false when the expression may not be
+// evaluated, and the expression is then logged as written.
+type LogpointEvaluator func(expression string, frameID int) (string, bool)
+
+// SetLogpointEvaluator sets how emulated logpoints evaluate the expressions in
+// their messages, so the caller can apply its evaluation policy. Without one,
+// they are evaluated in the watch context.
+func (c *Client) SetLogpointEvaluator(evaluate LogpointEvaluator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logpointEvaluator = evaluate
+}
+
+// evaluateLogExpression evaluates an expression of a logpoint message with the
+// logpoint evaluator, if one is set
+func (c *Client) evaluateLogExpression(expression string, frameID int) (string, bool) {
+	c.mu.Lock()
+	evaluate := c.logpointEvaluator
+	c.mu.Unlock()
+	if evaluate != nil {
+		return evaluate(expression, frameID)
+	}
+	result, err := c.Evaluate(expression, frameID, "watch")
+	if err != nil {
+		return "<" + err.Error() + ">", true
+	}
+	return result.Result, true
+}
+
+// trackLogpoints records the logpoints of a setBreakpoints request the
+// adapter will stop at, replacing those previously set in the source
+func (c *Client) trackLogpoints(path string, requested []dap.SourceBreakpoint, set []dap.Breakpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.emulateLogpoints || c.capabilities.SupportsLogPoints {
+		return
+	}
+	var logpoints []emulatedLogpoint
+	for i, bp := range requested {
+		if bp.LogMessage == "" {
+			continue
+		}
+		lp := emulatedLogpoint{line: bp.Line, message: bp.LogMessage}
+		// Breakpoints are reported in the order they were requested
+		if i < len(set) {
+			lp.id = set[i].Id
+			if set[i].Line != 0 {
+				lp.line = set[i].Line
+			}
+		}
+		logpoints = append(logpoints, lp)
+	}
+	if len(logpoints) == 0 {
+		delete(c.logpoints, path)
+		return
+	}
+	if c.logpoints == nil {
+		c.logpoints = make(map[string][]emulatedLogpoint)
+	}
+	c.logpoints[path] = logpoints
+}
+
+// hasLogpoints reports whether any emulated logpoint is set
+func (c *Client) hasLogpoints() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.logpoints) > 0
+}
+
+// handleBreakpointStop continues past a stop caused only by logpoints, after
+// logging their messages, and reports any other stop. It makes requests, so
+// it must not run on the read loop.
+func (c *Client) handleBreakpointStop(event *dap.StoppedEvent, info *StoppedInfo) {
+	frames, _, err := c.StackTrace(info.ThreadID, 0, 1)
+	if err != nil || len(frames) == 0 || frames[0].Source == nil {
+		c.deliverStop(event, info)
+		return
+	}
+	frame := frames[0]
+
+	logpoints := c.logpointsHit(frame.Source.Path, frame.Line, info.HitBreakpointIDs)
+	if len(logpoints) == 0 {
+		c.deliverStop(event, info)
+		return
+	}
+
+	for _, lp := range logpoints {
+		message := interpolateLogMessage(lp.message, func(expression string) (string, bool) {
+			return c.evaluateLogExpression(expression, frame.Id)
+		})
+		c.recordOutput(dap.OutputEventBody{
+			Category: "console",
+			Output:   message + "\n",
+			Source:   frame.Source,
+			Line:     frame.Line,
+		})
+	}
+
+	if _, err := c.Continue(info.ThreadID); err != nil {
+		// The program is still paused, so report the stop after all
+		c.deliverStop(event, info)
+	}
+}
+
+// logpointsHit returns the logpoints a stop at a location hit, or nil if it
+// hit any other breakpoint. Adapters that don't report the breakpoints a stop
+// hit are matched by the location.
+func (c *Client) logpointsHit(path string, line int, hitIDs []int) []emulatedLogpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(hitIDs) == 0 {
+		for _, lp := range c.logpoints[path] {
+			if lp.line == line {
+				return []emulatedLogpoint{lp}
+			}
+		}
+		return nil
+	}
+
+	var hit []emulatedLogpoint
+	for _, id := range hitIDs {
+		found := false
+		for _, logpoints := range c.logpoints {
+			for _, lp := range logpoints {
+				if lp.id == id && id != 0 {
+					hit = append(hit, lp)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return hit
+}
+
+// interpolateLogMessage replaces each {expression} in a logpoint message
+// with its value, keeping those evaluate refuses as written
+func interpolateLogMessage(message string, evaluate func(expression string) (string, bool)) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(message, '{')
+		if start < 0 {
+			break
+		}
+		// Find the matching brace, so expressions may contain braces
+		end, depth := -1, 0
+		for i := start; i < len(message) && end < 0; i++ {
+			switch message[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+		b.WriteString(message[:start])
+		if expression := strings.TrimSpace(message[start+1 : end]); expression != "" {
+			if value, ok := evaluate(expression); ok {
+				b.WriteString(value)
+			} else {
+				b.WriteString(message[start : end+1])
+			}
+		}
+		message = message[end+1:]
+	}
+	b.WriteString(message)
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package dap
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-dap"
+)
+
+// outputLogEntries is the number of output events a client keeps
+const outputLogEntries = 1000
+
+// OutputEntry is an output event from the debuggee or the adapter
+type OutputEntry struct {
+	// Seq numbers the session's output events from 1, so callers can ask for
+	// only the output that arrived since their last read
+	Seq int
+	// Category is the event's category: "console", "stdout", "stderr",
+	// "important", or an adapter-specific one. Adapters may omit it, which
+	// means "console".
+	Category string
+	Output   string
+	// Group is "start", "startCollapsed", or "end" for output that opens or
+	// closes a group
+	Group string
+	// Source, Line, and Column locate the code that produced the output, for
+	// adapters that report it (e.g. a console.log call or a failed assertion)
+	Source string
+	Line   int
+	Column int
+}
+
+// IsError reports whether the output is on stderr or marked important by the
+// adapter
+func (e OutputEntry) IsError() bool {
+	return e.Category == "stderr" || e.Category == "important"
+}
+
+// outputLog keeps the most recent output events
+type outputLog struct {
+	entries []OutputEntry
+	seq     int
+	dropped int
+
+	// Closed and replaced when output arrives or the program stops or exits,
+	// to wake WaitForOutput callers
+	changed chan struct{}
+}
+
+// wake wakes the callers waiting for output
+func (l *outputLog) wake() {
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
+
+// waitChannel returns a channel closed on the next wake
+func (l *outputLog) waitChannel() <-chan struct{} {
+	if l.changed == nil {
+		l.changed = make(chan struct{})
+	}
+	return l.changed
+}
+
+// add records an output event, dropping the oldest once the limit is reached
+func (l *outputLog) add(body dap.OutputEventBody) {
+	category := body.Category
+	if category == "" {
+		category = "console"
+	}
+	l.seq++
+	entry := OutputEntry{
+		Seq:      l.seq,
+		Category: category,
+		Output:   body.Output,
+		Group:    body.Group,
+		Line:     body.Line,
+		Column:   body.Column,
+	}
+	if body.Source != nil {
+		entry.Source = body.Source.Path
+		if entry.Source == "" {
+			entry.Source = body.Source.Name
+		}
+	}
+
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > outputLogEntries {
+		l.dropped += len(l.entries) - outputLogEntries
+		l.entries = l.entries[len(l.entries)-outputLogEntries:]
+	}
+	l.wake()
+}
+
+// recordOutput buffers an output event. Telemetry is not program output and
+// is ignored.
+func (c *Client) recordOutput(body dap.OutputEventBody) {
+	if body.Category == "telemetry" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.output.add(body)
+}
+
+// Output returns the buffered output events, oldest first, and the number of
+// older events discarded to stay within the limit
+func (c *Client) Output() ([]OutputEntry, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]OutputEntry, len(c.output.entries))
+	copy(entries, c.output.entries)
+	return entries, c.output.dropped
+}
+
+// OutputSeq returns the seq of the latest output event, or 0 if there is none
+func (c *Client) OutputSeq() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.output.seq
+}
+
+// OutputMatch is a line of output that WaitForOutput matched
+type OutputMatch struct {
+	Entry OutputEntry
+	Line  string // The matching line of the entry's output, without its newline
+}
+
+// WaitForOutput waits for a line of output after seq since that match
+// accepts, checking each line of an event's output separately, while the
+// program runs. It returns nil without an error once the program stops or
+// exits or the session terminates, ctx's error when it is done, and
+// ErrAdapterGone if the adapter connection closes.
+func (c *Client) WaitForOutput(ctx context.Context, since int, match func(line string) bool) (*OutputMatch, error) {
+	for {
+		c.mu.Lock()
+		for _, e := range c.output.entries {
+			if e.Seq <= since {
+				continue
+			}
+			since = e.Seq
+			for _, line := range strings.Split(strings.TrimRight(e.Output, "\r\n"), "\n") {
+				line = strings.TrimRight(line, "\r")
+				if match(line) {
+					c.mu.Unlock()
+					return &OutputMatch{Entry: e, Line: line}, nil
+				}
+			}
+		}
+		if !c.running || c.exited || c.terminated {
+			c.mu.Unlock()
+			return nil, nil
+		}
+		changed := c.output.waitChannel()
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-c.readDone:
+			return nil, ErrAdapterGone
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
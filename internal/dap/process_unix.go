@@ -29,6 +29,16 @@ func killProcessGroup(pid int, cmd *exec.Cmd) error {
 	return nil
 }
 
+// ProcessAlive reports whether a process exists. A process we may not signal
+// (EPERM) still exists.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
 // setProcAttr sets platform-specific process attributes.
 // On Unix, we create a new session so the process becomes a process group leader.
 func setProcAttr(cmd *exec.Cmd) {
@@ -3,6 +3,7 @@
 package dap
 
 import (
+	"os"
 	"os/exec"
 	"syscall"
 )
@@ -22,6 +23,20 @@ func killProcessGroup(pid int, cmd *exec.Cmd) error {
 	return nil
 }
 
+// ProcessAlive reports whether a process exists. On Windows, finding a process
+// opens a handle to it, which fails once it is gone.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
+
 // setProcAttr sets platform-specific process attributes.
 // On Windows, we create a new process group so we can potentially signal child processes.
 func setProcAttr(cmd *exec.Cmd) {
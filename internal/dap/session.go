@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/go-dap"
 	"github.com/google/uuid"
 
 	"github.com/ctagard/dap-mcp/pkg/types"
@@ -22,10 +23,73 @@ type Session struct {
 	Status    types.SessionStatus
 	Client    *Client
 	Process   *exec.Cmd
-	PID       int
+	PID       int // Debug adapter process ID
 	Program   string
 	CreatedAt time.Time
 
+	// Native debugger backing the session ("lldb" or "gdb"), empty for other adapters
+	Debugger string
+
+	// Debuggee process as reported by the adapter's process event
+	DebuggeePID  int
+	DebuggeeName string
+
+	// Number of times the session was relaunched after the debuggee failed (restartOnExit)
+	Restarts int
+
+	// Attached is true if the session attached to a running process instead of
+	// launching it; such debuggees are left running when the session is cleaned up
+	Attached bool
+
+	// snapshotDigest holds value digests from the previous debug_snapshot,
+	// used to report only what changed between snapshots
+	snapshotDigest map[string]string
+
+	// snapshots holds the most recent debug_snapshot states, oldest first,
+	// for debug_diff to compare; snapshotSeq numbers their IDs
+	snapshots   []StoredSnapshot
+	snapshotSeq int
+
+	// sourceBreakpoints holds the breakpoints last set per source path, since
+	// setBreakpoints replaces every breakpoint in a file
+	sourceBreakpoints map[string][]dap.SourceBreakpoint
+
+	// sourceRoot is the directory relative breakpoint paths are resolved
+	// against; adapterSourcePaths maps resolved paths to the path the adapter
+	// reported for them, where it differs (e.g. with symlinks resolved)
+	sourceRoot         string
+	adapterSourcePaths map[string]string
+
+	// functionBreakpoints holds the function breakpoints last set, since
+	// setFunctionBreakpoints replaces them all; regexBreakpoints holds the
+	// patterns of regex breakpoints created with native debugger commands
+	functionBreakpoints []dap.FunctionBreakpoint
+	regexBreakpoints    []string
+
+	// launchRequest holds the debug_launch arguments that started the session,
+	// so it can be exported and launched again
+	launchRequest map[string]interface{}
+
+	// debugOptions holds launch options set for the session, such as
+	// justMyCode, which are passed to every later launch of the program;
+	// launchedDebugOptions are the ones the running program was launched with
+	debugOptions         map[string]interface{}
+	launchedDebugOptions map[string]interface{}
+
+	// cleanups run once the session ends, e.g. to stop a background
+	// preLaunchTask started for it
+	cleanups []func()
+
+	// focusThread and focusFrame are the defaults set with debug_focus. They
+	// apply to the stop they were set at, focusStop of focusClient.
+	focusThread, focusFrame, focusStop int
+	focusClient                        *Client
+
+	// modulesSeen is the latest module sequence number of modulesClient
+	// that debug_modules has reported
+	modulesSeen   int
+	modulesClient *Client
+
 	mu sync.RWMutex
 }
 
@@ -46,6 +110,9 @@ type SessionManager struct {
 	maxSessions    int
 	sessionTimeout time.Duration
 
+	// terminateAttached makes cleanup terminate attached debuggees too
+	terminateAttached bool
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -146,12 +213,20 @@ func (sm *SessionManager) ListSessions() []*Session {
 
 // TerminateSession terminates a session and cleans up resources
 func (sm *SessionManager) TerminateSession(id string, terminateDebuggee bool) error {
+	_, err := sm.DisconnectSession(id, terminateDebuggee)
+	return err
+}
+
+// DisconnectSession terminates a session like TerminateSession and reports
+// whether the debug adapter was already gone (crashed or exited), in which case
+// no DAP disconnect was sent. Cleanup always completes either way.
+func (sm *SessionManager) DisconnectSession(id string, terminateDebuggee bool) (adapterGone bool, err error) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	session, ok := sm.sessions[id]
 	if !ok {
-		return fmt.Errorf("session not found: %s", id)
+		return false, fmt.Errorf("session not found: %s", id)
 	}
 
 	// Check if this session is part of a compound with stopAll enabled
@@ -171,14 +246,7 @@ func (sm *SessionManager) TerminateSession(id string, terminateDebuggee bool) er
 	}
 
 	// Disconnect from the debug adapter
-	if session.Client != nil {
-		if err := session.Client.Disconnect(terminateDebuggee); err != nil {
-			log.Printf("Warning: failed to disconnect session %s: %v (continuing cleanup)", id, err)
-		}
-		if err := session.Client.Close(); err != nil {
-			log.Printf("Warning: failed to close client for session %s: %v (continuing cleanup)", id, err)
-		}
-	}
+	adapterGone = disconnectClient(session, terminateDebuggee)
 
 	// Kill the spawned process group if any
 	// Uses platform-specific implementation (process_unix.go / process_windows.go)
@@ -186,27 +254,50 @@ func (sm *SessionManager) TerminateSession(id string, terminateDebuggee bool) er
 		log.Printf("Warning: failed to kill process group for session %s (PID %d): %v", id, session.PID, err)
 	}
 
+	session.runCleanups()
 	session.Status = types.SessionStatusTerminated
 	delete(sm.sessions, id)
 
-	return nil
+	return adapterGone, nil
 }
 
-// terminateSessionLocked terminates a session (must be called with lock held)
+// disconnectClient sends a DAP disconnect and closes the session's client. The
+// disconnect is skipped when the adapter connection is already gone, so a
+// crashed adapter can't stall cleanup. Returns whether the adapter was gone.
+func disconnectClient(session *Session, terminateDebuggee bool) bool {
+	client := session.GetClient()
+	if client == nil {
+		return false
+	}
+
+	// The read loop stops once the adapter process dies and its pipes or socket close
+	adapterGone := !client.Alive()
+	if !adapterGone {
+		if err := client.Disconnect(terminateDebuggee); err != nil {
+			if err == ErrAdapterGone {
+				adapterGone = true
+			} else {
+				log.Printf("Warning: failed to disconnect session %s: %v (continuing cleanup)", session.ID, err)
+			}
+		}
+	}
+	if err := client.Close(); err != nil && !adapterGone {
+		log.Printf("Warning: failed to close client for session %s: %v (continuing cleanup)", session.ID, err)
+	}
+
+	return adapterGone
+}
+
+// terminateSessionLocked terminates a session (must be called with lock held).
+// Launched debuggees are terminated; attached ones are detached from and keep
+// running unless terminateAttached is set.
 func (sm *SessionManager) terminateSessionLocked(id string) {
 	session, ok := sm.sessions[id]
 	if !ok {
 		return
 	}
 
-	if session.Client != nil {
-		if err := session.Client.Disconnect(true); err != nil {
-			log.Printf("Warning: failed to disconnect session %s during cleanup: %v", id, err)
-		}
-		if err := session.Client.Close(); err != nil {
-			log.Printf("Warning: failed to close client for session %s during cleanup: %v", id, err)
-		}
-	}
+	disconnectClient(session, !session.Attached || sm.terminateAttached)
 
 	// Kill the spawned process group
 	// Uses platform-specific implementation (process_unix.go / process_windows.go)
@@ -214,6 +305,7 @@ func (sm *SessionManager) terminateSessionLocked(id string) {
 		log.Printf("Warning: failed to kill process group for session %s (PID %d) during cleanup: %v", id, session.PID, err)
 	}
 
+	session.runCleanups()
 	session.Status = types.SessionStatusTerminated
 	delete(sm.sessions, id)
 }
@@ -269,7 +361,9 @@ func (sm *SessionManager) SetSessionClient(id string, client *Client) error {
 		return fmt.Errorf("session not found: %s", id)
 	}
 
+	session.mu.Lock()
 	session.Client = client
+	session.mu.Unlock()
 	return nil
 }
 
@@ -288,6 +382,99 @@ func (sm *SessionManager) SetSessionProcess(id string, cmd *exec.Cmd, pid int) e
 	return nil
 }
 
+// ReplaceSessionAdapter swaps a session's adapter for a freshly spawned one,
+// as when the session is relaunched after the debuggee exited. The old adapter
+// is disconnected and its process group killed. Returns the new restart count.
+func (sm *SessionManager) ReplaceSessionAdapter(id string, client *Client, cmd *exec.Cmd) (int, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[id]
+	if !ok {
+		return 0, fmt.Errorf("session not found: %s", id)
+	}
+
+	disconnectClient(session, true)
+	if err := killProcessGroup(session.PID, session.Process); err != nil {
+		log.Printf("Warning: failed to kill process group for session %s (PID %d) during restart: %v", id, session.PID, err)
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.Client = client
+	session.Process = cmd
+	session.PID = 0
+	if cmd != nil && cmd.Process != nil {
+		session.PID = cmd.Process.Pid
+	}
+	session.DebuggeePID = 0
+	session.DebuggeeName = ""
+	session.snapshotDigest = nil
+	session.Status = types.SessionStatusRunning
+	session.Restarts++
+
+	return session.Restarts, nil
+}
+
+// SetSessionDebugger records which native debugger (lldb or gdb) runs the session
+func (sm *SessionManager) SetSessionDebugger(id string, debugger string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[id]
+	if !ok {
+		return fmt.Errorf("session not found: %s", id)
+	}
+
+	session.Debugger = debugger
+	return nil
+}
+
+// SetSessionAttached marks a session as attached to an existing process
+func (sm *SessionManager) SetSessionAttached(id string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[id]
+	if !ok {
+		return fmt.Errorf("session not found: %s", id)
+	}
+
+	session.mu.Lock()
+	session.Attached = true
+	session.mu.Unlock()
+
+	return nil
+}
+
+// SetTerminateAttached controls whether Close and session timeouts terminate
+// the debuggees of attached sessions. By default they only detach from them.
+func (sm *SessionManager) SetTerminateAttached(terminate bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.terminateAttached = terminate
+}
+
+// SetSessionDebuggee records the debuggee process reported by the adapter
+func (sm *SessionManager) SetSessionDebuggee(id string, pid int, name string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[id]
+	if !ok {
+		return fmt.Errorf("session not found: %s", id)
+	}
+
+	session.mu.Lock()
+	session.DebuggeePID = pid
+	session.DebuggeeName = name
+	session.mu.Unlock()
+
+	return nil
+}
+
 // UpdateSessionStatus updates the status of a session
 func (sm *SessionManager) UpdateSessionStatus(id string, status types.SessionStatus) error {
 	sm.mu.Lock()
@@ -305,7 +492,8 @@ func (sm *SessionManager) UpdateSessionStatus(id string, status types.SessionSta
 	return nil
 }
 
-// Close shuts down the session manager and all sessions
+// Close shuts down the session manager and all sessions. Launched debuggees
+// are terminated; attached ones are detached from (see SetTerminateAttached).
 func (sm *SessionManager) Close() {
 	sm.cancel()
 
@@ -317,18 +505,352 @@ func (sm *SessionManager) Close() {
 	}
 }
 
+// GetClient returns the session's current DAP client, which changes when the
+// session restarts
+func (s *Session) GetClient() *Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Client
+}
+
 // GetSessionInfo returns session info for a session
 func (s *Session) GetInfo() types.SessionInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return types.SessionInfo{
-		SessionID: s.ID,
-		Language:  s.Language,
-		Status:    s.Status,
-		PID:       s.PID,
-		Program:   s.Program,
+	info := types.SessionInfo{
+		SessionID:    s.ID,
+		Language:     s.Language,
+		Status:       s.Status,
+		PID:          s.PID,
+		Program:      s.Program,
+		DebuggeePID:  s.DebuggeePID,
+		DebuggeeName: s.DebuggeeName,
+		Restarts:     s.Restarts,
+		Attached:     s.Attached,
+	}
+
+	// The program stops and resumes between tool calls, e.g. at a breakpoint
+	if s.Client != nil && (info.Status == types.SessionStatusRunning || info.Status == types.SessionStatusStopped) {
+		if s.Client.Running() {
+			info.Status = types.SessionStatusRunning
+		} else if s.Client.LastStop() != nil {
+			info.Status = types.SessionStatusStopped
+		}
+	}
+
+	// The process event may arrive after the launch was confirmed
+	if info.DebuggeePID == 0 && s.Client != nil {
+		if p := s.Client.ProcessInfo(); p != nil {
+			info.DebuggeePID = p.PID
+			info.DebuggeeName = p.Name
+		}
+	}
+
+	return info
+}
+
+// SnapshotDigest returns the digest recorded by the previous snapshot, or nil if none
+func (s *Session) SnapshotDigest() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshotDigest
+}
+
+// SetSnapshotDigest records the digest of the latest snapshot
+func (s *Session) SetSnapshotDigest(digest map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshotDigest = digest
+}
+
+// MaxStoredSnapshots is how many snapshots a session keeps for debug_diff
+const MaxStoredSnapshots = 5
+
+// StoredSnapshot is the state a debug_snapshot captured, kept so that later
+// calls can compare snapshots without the caller resending them
+type StoredSnapshot struct {
+	ID      string
+	TakenAt time.Time
+
+	// State is keyed like the snapshot digest, whatever the snapshot returned
+	State map[string]string
+
+	// Partial is true when the snapshot captured only some threads
+	Partial bool
+}
+
+// AddSnapshot stores a snapshot's state, dropping the oldest beyond
+// MaxStoredSnapshots, and returns its ID
+func (s *Session) AddSnapshot(state map[string]string, partial bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshotSeq++
+	stored := StoredSnapshot{
+		ID:      fmt.Sprintf("snap-%d", s.snapshotSeq),
+		TakenAt: time.Now(),
+		State:   state,
+		Partial: partial,
+	}
+	s.snapshots = append(s.snapshots, stored)
+	if len(s.snapshots) > MaxStoredSnapshots {
+		s.snapshots = s.snapshots[len(s.snapshots)-MaxStoredSnapshots:]
+	}
+	return stored.ID
+}
+
+// Snapshots returns the stored snapshots, oldest first
+func (s *Session) Snapshots() []StoredSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]StoredSnapshot(nil), s.snapshots...)
+}
+
+// SourceRoot returns the directory relative source paths are resolved against
+func (s *Session) SourceRoot() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sourceRoot
+}
+
+// SetSourceRoot sets the directory relative source paths are resolved against
+func (s *Session) SetSourceRoot(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sourceRoot = dir
+}
+
+// SetAdapterSourcePath records the path the adapter reported for a source
+func (s *Session) SetAdapterSourcePath(path, adapterPath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.adapterSourcePaths == nil {
+		s.adapterSourcePaths = make(map[string]string)
+	}
+	s.adapterSourcePaths[path] = adapterPath
+}
+
+// AdapterSourcePaths returns the recorded adapter paths by source path
+func (s *Session) AdapterSourcePaths() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	paths := make(map[string]string, len(s.adapterSourcePaths))
+	for path, adapterPath := range s.adapterSourcePaths {
+		paths[path] = adapterPath
+	}
+	return paths
+}
+
+// SourceBreakpoints returns the breakpoints last set in the given source file
+func (s *Session) SourceBreakpoints(path string) []dap.SourceBreakpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]dap.SourceBreakpoint(nil), s.sourceBreakpoints[path]...)
+}
+
+// AllSourceBreakpoints returns the breakpoints last set in every source file
+func (s *Session) AllSourceBreakpoints() map[string][]dap.SourceBreakpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make(map[string][]dap.SourceBreakpoint, len(s.sourceBreakpoints))
+	for path, bps := range s.sourceBreakpoints {
+		all[path] = append([]dap.SourceBreakpoint(nil), bps...)
+	}
+	return all
+}
+
+// SetSourceBreakpoints records the breakpoints set in the given source file
+func (s *Session) SetSourceBreakpoints(path string, breakpoints []dap.SourceBreakpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sourceBreakpoints == nil {
+		s.sourceBreakpoints = make(map[string][]dap.SourceBreakpoint)
+	}
+	s.sourceBreakpoints[path] = breakpoints
+}
+
+// FunctionBreakpoints returns the function breakpoints last set
+func (s *Session) FunctionBreakpoints() []dap.FunctionBreakpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]dap.FunctionBreakpoint(nil), s.functionBreakpoints...)
+}
+
+// SetFunctionBreakpoints records the function breakpoints set in the session
+func (s *Session) SetFunctionBreakpoints(breakpoints []dap.FunctionBreakpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.functionBreakpoints = breakpoints
+}
+
+// RegexBreakpoints returns the patterns of the session's regex breakpoints
+func (s *Session) RegexBreakpoints() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.regexBreakpoints...)
+}
+
+// AddRegexBreakpoint records a regex breakpoint created in the session
+func (s *Session) AddRegexBreakpoint(pattern string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.regexBreakpoints = append(s.regexBreakpoints, pattern)
+}
+
+// LaunchRequest returns the debug_launch arguments that started the session,
+// or nil for attached sessions
+func (s *Session) LaunchRequest() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyOptions(s.launchRequest)
+}
+
+// SetLaunchRequest records the debug_launch arguments that started the session
+func (s *Session) SetLaunchRequest(args map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.launchRequest = copyOptions(args)
+}
+
+// OnEnd registers a function to run once the session ends, however it ends:
+// disconnected, timed out, or shut down with the server
+func (s *Session) OnEnd(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanups = append(s.cleanups, fn)
+}
+
+// runCleanups runs the functions registered with OnEnd, once
+func (s *Session) runCleanups() {
+	s.mu.Lock()
+	cleanups := s.cleanups
+	s.cleanups = nil
+	s.mu.Unlock()
+
+	for _, fn := range cleanups {
+		fn()
+	}
+}
+
+// DebugOptions returns the launch options set for the session
+func (s *Session) DebugOptions() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyOptions(s.debugOptions)
+}
+
+// LaunchedDebugOptions returns the options the running program was launched with
+func (s *Session) LaunchedDebugOptions() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyOptions(s.launchedDebugOptions)
+}
+
+// LaunchDebugOptions returns the options to launch the program with and
+// records them as the ones it is running with
+func (s *Session) LaunchDebugOptions() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.launchedDebugOptions = copyOptions(s.debugOptions)
+	return copyOptions(s.debugOptions)
+}
+
+func copyOptions(options map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(options))
+	for name, value := range options {
+		copied[name] = value
 	}
+	return copied
+}
+
+// SetDebugOption records a launch option for later launches of the program
+func (s *Session) SetDebugOption(name string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.debugOptions == nil {
+		s.debugOptions = make(map[string]interface{})
+	}
+	s.debugOptions[name] = value
+}
+
+// SetFocus sets the thread and frame that tools default to until the program
+// next stops
+func (s *Session) SetFocus(threadID, frameID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.focusThread, s.focusFrame = threadID, frameID
+	s.focusClient = s.Client
+	if s.Client != nil {
+		s.focusStop = s.Client.StopCount()
+	}
+}
+
+// Focus returns the thread and frame set with SetFocus, unless the program
+// has stopped again (or the adapter was replaced) since
+func (s *Session) Focus() (threadID, frameID int, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.focusClient == nil || s.focusClient != s.Client || s.focusStop != s.Client.StopCount() {
+		return 0, 0, false
+	}
+	return s.focusThread, s.focusFrame, true
+}
+
+// ModulesSeen returns the latest module sequence number reported with
+// SetModulesSeen, or 0 if none was for the session's current adapter
+func (s *Session) ModulesSeen() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.modulesClient == nil || s.modulesClient != s.Client {
+		return 0
+	}
+	return s.modulesSeen
+}
+
+// SetModulesSeen records the latest module sequence number reported to the caller
+func (s *Session) SetModulesSeen(seq int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.modulesSeen, s.modulesClient = seq, s.Client
+}
+
+// AdapterLog returns the adapter's captured stderr, or nil if the session did
+// not spawn its adapter (attach by port) or output was not captured
+func (s *Session) AdapterLog() *AdapterLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Process == nil {
+		return nil
+	}
+	captured, _ := s.Process.Stderr.(*AdapterLog)
+	return captured
 }
 
 // SpawnAdapter spawns a debug adapter process and returns the address to connect to
@@ -351,8 +873,13 @@ func (d *DelveSpawner) Spawn(ctx context.Context, session *Session, args map[str
 		"--listen", address,
 	}
 
-	if d.BuildFlags != "" {
-		dlvArgs = append(dlvArgs, "--build-flags", d.BuildFlags)
+	// Per-launch buildFlags override the configured default
+	buildFlags := d.BuildFlags
+	if flags, ok := args["buildFlags"].(string); ok && flags != "" {
+		buildFlags = flags
+	}
+	if buildFlags != "" {
+		dlvArgs = append(dlvArgs, "--build-flags", buildFlags)
 	}
 
 	//nolint:gosec // G204: This is a debug adapter that intentionally spawns subprocesses
@@ -360,6 +887,8 @@ func (d *DelveSpawner) Spawn(ctx context.Context, session *Session, args map[str
 	cmd.Env = os.Environ()
 	setProcAttr(cmd)
 
+	cmd.Stderr = NewAdapterLog()
+
 	if err := cmd.Start(); err != nil {
 		return "", nil, fmt.Errorf("failed to start dlv: %w", err)
 	}
@@ -409,6 +938,8 @@ func (d *DebugpySpawner) Spawn(ctx context.Context, session *Session, args map[s
 		cmd.Dir = cwd
 	}
 
+	cmd.Stderr = NewAdapterLog()
+
 	if err := cmd.Start(); err != nil {
 		return "", nil, fmt.Errorf("failed to start debugpy: %w", err)
 	}
@@ -461,6 +992,8 @@ func (n *NodeSpawner) Spawn(ctx context.Context, session *Session, args map[stri
 		cmd.Dir = cwd
 	}
 
+	cmd.Stderr = NewAdapterLog()
+
 	if err := cmd.Start(); err != nil {
 		return "", nil, fmt.Errorf("failed to start node: %w", err)
 	}
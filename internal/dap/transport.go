@@ -11,6 +11,8 @@ package dap
 
 import (
 	"bufio"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net"
@@ -106,12 +108,32 @@ func (t *Transport) Send(msg dap.Message) error {
 	return nil
 }
 
-// Receive receives a DAP message
+// RawResponse is a response to a command go-dap has no typed response for,
+// such as an adapter-specific custom request. Its body is left undecoded.
+type RawResponse struct {
+	dap.Response
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Receive receives a DAP message. Responses to commands go-dap doesn't know
+// are returned as *RawResponse so they still reach their pending request.
 func (t *Transport) Receive() (dap.Message, error) {
-	msg, err := dap.ReadProtocolMessage(t.reader)
+	content, err := dap.ReadBaseMessage(t.reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read DAP message: %w", err)
 	}
+
+	msg, err := dap.DecodeProtocolMessage(content)
+	if err != nil {
+		var fieldErr *dap.DecodeProtocolMessageFieldError
+		if stderrors.As(err, &fieldErr) && fieldErr.SubType == "Response" {
+			var raw RawResponse
+			if jsonErr := json.Unmarshal(content, &raw); jsonErr == nil {
+				return &raw, nil
+			}
+		}
+		return nil, fmt.Errorf("failed to decode DAP message: %w", err)
+	}
 	return msg, nil
 }
 
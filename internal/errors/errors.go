@@ -7,6 +7,7 @@ import (
 	stderrors "errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // ErrorCode represents a category of error for programmatic handling
@@ -18,11 +19,13 @@ const (
 	CodeSessionLimitReached ErrorCode = "SESSION_LIMIT_REACHED"
 	CodeSessionNoClient     ErrorCode = "SESSION_NO_CLIENT"
 	CodeSessionTerminated   ErrorCode = "SESSION_TERMINATED"
+	CodeSessionRunning      ErrorCode = "SESSION_RUNNING"
 
 	// Adapter errors
 	CodeAdapterNotSupported  ErrorCode = "ADAPTER_NOT_SUPPORTED"
 	CodeAdapterSpawnFailed   ErrorCode = "ADAPTER_SPAWN_FAILED"
 	CodeAdapterConnectFailed ErrorCode = "ADAPTER_CONNECT_FAILED"
+	CodeAdapterInstallFailed ErrorCode = "ADAPTER_INSTALL_FAILED"
 
 	// DAP protocol errors
 	CodeDAPInitFailed    ErrorCode = "DAP_INIT_FAILED"
@@ -45,10 +48,14 @@ const (
 	CodeMissingInputs  ErrorCode = "MISSING_INPUTS"
 
 	// Runtime errors
-	CodeBreakpointFailed ErrorCode = "BREAKPOINT_FAILED"
-	CodeEvaluationFailed ErrorCode = "EVALUATION_FAILED"
-	CodeStepFailed       ErrorCode = "STEP_FAILED"
-	CodeNoThreads        ErrorCode = "NO_THREADS"
+	CodeBreakpointFailed        ErrorCode = "BREAKPOINT_FAILED"
+	CodeEvaluationFailed        ErrorCode = "EVALUATION_FAILED"
+	CodeFunctionCallUnsupported ErrorCode = "FUNCTION_CALL_UNSUPPORTED"
+	CodeStepFailed              ErrorCode = "STEP_FAILED"
+	CodeNoThreads               ErrorCode = "NO_THREADS"
+	CodeSourceUnavailable       ErrorCode = "SOURCE_UNAVAILABLE"
+	CodeNoException             ErrorCode = "NO_EXCEPTION"
+	CodeNoSnapshots             ErrorCode = "NO_SNAPSHOTS"
 )
 
 // DebugError is a structured error type that includes helpful information
@@ -141,6 +148,32 @@ func SessionNoClient(sessionID string) *DebugError {
 	}
 }
 
+// SessionRunning creates an error for inspecting a program that is running,
+// which only has stacks, scopes, and variables while it is paused
+func SessionRunning(sessionID string) *DebugError {
+	return &DebugError{
+		Code:    CodeSessionRunning,
+		Message: fmt.Sprintf("session '%s' is running; pause it or wait for it to stop first", sessionID),
+		Hint:    "Use debug_pause to stop the program now, or set a breakpoint with debug_breakpoints (or use debug_run_to_line) and inspect once it stops.",
+		Details: map[string]interface{}{
+			"sessionId": sessionID,
+		},
+	}
+}
+
+// SessionAdapterGone creates an error for a session whose debug adapter
+// connection has closed, e.g. because the adapter crashed
+func SessionAdapterGone(sessionID string) *DebugError {
+	return &DebugError{
+		Code:    CodeSessionTerminated,
+		Message: fmt.Sprintf("the debug adapter for session '%s' is no longer connected", sessionID),
+		Hint:    "Check debug_adapter_log for why the adapter stopped, then use debug_disconnect to clean up and debug_launch to start a new session.",
+		Details: map[string]interface{}{
+			"sessionId": sessionID,
+		},
+	}
+}
+
 // --- Adapter Errors ---
 
 // AdapterNotSupported creates an error for unsupported languages
@@ -161,7 +194,7 @@ func AdapterSpawnFailed(language string, err error) *DebugError {
 	return &DebugError{
 		Code:    CodeAdapterSpawnFailed,
 		Message: fmt.Sprintf("failed to spawn debug adapter for %s: %v", language, err),
-		Hint:    "Ensure the debug adapter is installed. For Go: install Delve (go install github.com/go-delve/delve/cmd/dlv@latest). For Python: install debugpy (pip install debugpy). For JavaScript: vscode-js-debug should be bundled.",
+		Hint:    "Ensure the debug adapter is installed. For Go: install Delve (go install github.com/go-delve/delve/cmd/dlv@latest). For Python: install debugpy (pip install debugpy). For JavaScript: vscode-js-debug should be bundled. For Elixir: install ElixirLS (https://github.com/elixir-lsp/elixir-ls/releases) and set adapters.elixir.elixirLsPath to its debug_adapter.sh. Go, Python, and JavaScript adapters can be installed with 'dap-mcp -install <language>' or, when allowInstall is set, the debug_install_adapter tool.",
 		Cause:   err,
 		Details: map[string]interface{}{
 			"language": language,
@@ -169,28 +202,88 @@ func AdapterSpawnFailed(language string, err error) *DebugError {
 	}
 }
 
-// AdapterConnectFailed creates an error when connecting to adapter fails
-func AdapterConnectFailed(address string, err error) *DebugError {
+// AdapterInstallFailed creates an error when installing a debug adapter fails
+func AdapterInstallFailed(language string, err error) *DebugError {
 	return &DebugError{
-		Code:    CodeAdapterConnectFailed,
-		Message: fmt.Sprintf("failed to connect to debug adapter at %s: %v", address, err),
-		Hint:    "The debug adapter may have failed to start or crashed. Check that the program path is correct and the file exists.",
+		Code:    CodeAdapterInstallFailed,
+		Message: fmt.Sprintf("failed to install debug adapter for %s: %v", language, err),
+		Hint:    "Check the installer output above. Installing needs network access, and 'go' for Go or a Python with pip for Python. The adapter can also be installed by hand as described in the README.",
 		Cause:   err,
 		Details: map[string]interface{}{
-			"address": address,
+			"language": language,
 		},
 	}
 }
 
+// AdapterConnectFailed creates an error when connecting to adapter fails.
+// stderrTail holds the last lines the adapter wrote to stderr (if captured) and
+// exited reports whether the adapter process had already exited.
+func AdapterConnectFailed(address string, err error, stderrTail []string, exited bool) *DebugError {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "failed to connect to debug adapter at %s: %v", address, err)
+	if exited {
+		sb.WriteString(" (the adapter process has exited)")
+	}
+	if len(stderrTail) > 0 {
+		sb.WriteString("; adapter stderr:\n")
+		sb.WriteString(strings.Join(stderrTail, "\n"))
+	}
+
+	hint := "The debug adapter may have failed to start or crashed. Check that the program path is correct and the file exists."
+	if exited {
+		hint = "The debug adapter exited before accepting a connection. Check the adapter stderr above and that the adapter is installed correctly."
+	}
+
+	details := map[string]interface{}{
+		"address":       address,
+		"processExited": exited,
+	}
+	if len(stderrTail) > 0 {
+		details["stderr"] = stderrTail
+	}
+
+	return &DebugError{
+		Code:    CodeAdapterConnectFailed,
+		Message: sb.String(),
+		Hint:    hint,
+		Cause:   err,
+		Details: details,
+	}
+}
+
 // --- DAP Protocol Errors ---
 
-// DAPInitFailed creates an error for DAP initialization failures
-func DAPInitFailed(err error) *DebugError {
+// DAPInitFailed creates an error for DAP initialization failures. closed
+// says the adapter closed the connection before answering initialize.
+func DAPInitFailed(err error, stderrTail []string, closed bool) *DebugError {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "debug adapter initialization failed: %v", err)
+	if closed {
+		sb.WriteString(" (the adapter closed the connection before it was ready)")
+	}
+	if len(stderrTail) > 0 {
+		sb.WriteString("; adapter stderr:\n")
+		sb.WriteString(strings.Join(stderrTail, "\n"))
+	}
+
+	hint := "The debug adapter may be incompatible or crashed during startup. Try disconnecting and launching a new session."
+	if closed {
+		hint = "The debug adapter kept closing the connection during startup. Check the adapter stderr above and that the adapter is installed correctly; on a slow system, launching again may succeed."
+	}
+
+	details := map[string]interface{}{
+		"connectionClosed": closed,
+	}
+	if len(stderrTail) > 0 {
+		details["stderr"] = stderrTail
+	}
+
 	return &DebugError{
 		Code:    CodeDAPInitFailed,
-		Message: fmt.Sprintf("debug adapter initialization failed: %v", err),
-		Hint:    "The debug adapter may be incompatible or crashed during startup. Try disconnecting and launching a new session.",
+		Message: sb.String(),
+		Hint:    hint,
 		Cause:   err,
+		Details: details,
 	}
 }
 
@@ -217,6 +310,51 @@ func DAPAttachFailed(err error) *DebugError {
 	}
 }
 
+// ProcessNotStarted creates an error when an attach waiting for a process to
+// start gave up
+func ProcessNotStarted(program string, timeout time.Duration, err error) *DebugError {
+	return &DebugError{
+		Code:    CodeDAPAttachFailed,
+		Message: fmt.Sprintf("no process named %q was attached to within %s: %v", program, timeout, err),
+		Hint:    "Start the program, or the parent that spawns it, while debug_attach is waiting, or raise timeout. The name must match the process name the system reports, usually the executable's file name.",
+		Cause:   err,
+		Details: map[string]interface{}{
+			"program": program,
+		},
+	}
+}
+
+// BrowserNotReachable creates an error when no browser answers on its remote debugging port
+func BrowserNotReachable(browser, host string, port int, err error) *DebugError {
+	return &DebugError{
+		Code:    CodeDAPAttachFailed,
+		Message: fmt.Sprintf("no %s remote debugging endpoint at %s:%d: %v", browser, host, port, err),
+		Hint:    fmt.Sprintf("Start the browser with --remote-debugging-port=%d (e.g. chrome --remote-debugging-port=%d --user-data-dir=/tmp/chrome-debug), or pass the port it uses.", port, port),
+		Cause:   err,
+		Details: map[string]interface{}{
+			"host": host,
+			"port": port,
+		},
+	}
+}
+
+// BrowserTabNotFound creates an error when no open tab matches the url filter
+func BrowserTabNotFound(url string, tabURLs []string) *DebugError {
+	hint := "No tabs are open. Open the page in the browser, then attach again."
+	if len(tabURLs) > 0 {
+		hint = fmt.Sprintf("Open tabs: %s. Pass one of these as url, or a pattern with * wildcards (e.g. http://localhost:3000/*).", strings.Join(tabURLs, ", "))
+	}
+	return &DebugError{
+		Code:    CodeDAPAttachFailed,
+		Message: fmt.Sprintf("no browser tab matches url %q", url),
+		Hint:    hint,
+		Details: map[string]interface{}{
+			"url":  url,
+			"tabs": tabURLs,
+		},
+	}
+}
+
 // DAPTimeout creates an error for DAP timeouts
 func DAPTimeout(operation string, timeoutSeconds int) *DebugError {
 	return &DebugError{
@@ -230,6 +368,40 @@ func DAPTimeout(operation string, timeoutSeconds int) *DebugError {
 	}
 }
 
+// DAPRequestTimeout creates an error for a DAP request the adapter never answered.
+// pending lists the other requests still waiting when the timeout fired.
+func DAPRequestTimeout(command string, seq int, timeout time.Duration, pending []string) *DebugError {
+	details := map[string]interface{}{
+		"command":        command,
+		"seq":            seq,
+		"timeoutSeconds": timeout.Seconds(),
+	}
+	if len(pending) > 0 {
+		details["pendingRequests"] = pending
+	}
+	return &DebugError{
+		Code:    CodeDAPTimeout,
+		Message: fmt.Sprintf("%s request timed out after %s", command, timeout),
+		Hint:    "The debug adapter did not respond. The program may be running or stuck, or the adapter may have hung. Try debug_pause, or disconnect and relaunch if the adapter is unresponsive.",
+		Details: details,
+	}
+}
+
+// DAPSeqCollision creates an error for a request given the seq of a request
+// still waiting for its response
+func DAPSeqCollision(command string, seq int, pendingCommand string) *DebugError {
+	return &DebugError{
+		Code:    CodeDAPProtocolError,
+		Message: fmt.Sprintf("%s request was given seq %d, which the pending %s request already has", command, seq, pendingCommand),
+		Hint:    "This is a bug in the DAP client's sequence numbering. Retry the request; if it persists, disconnect and relaunch the session.",
+		Details: map[string]interface{}{
+			"command":        command,
+			"seq":            seq,
+			"pendingCommand": pendingCommand,
+		},
+	}
+}
+
 // --- Parameter Errors ---
 
 // MissingParameter creates an error for missing required parameters
@@ -283,9 +455,17 @@ func PermissionDenied(operation, mode string) *DebugError {
 	case "attach":
 		hint = "The server is configured to disallow attaching to processes. Ask the administrator to enable 'allowAttach' in the configuration."
 	case "evaluate":
-		hint = "Expression evaluation is disabled in the current server mode. This may be intentional for security reasons."
+		hint = "Expression evaluation is disabled in the current server mode. This may be intentional for security reasons. Ask the administrator to set 'evaluation' to \"readOnly\" (or enable 'evaluateReadOnly') to allow evaluation without side effects."
+	case "repl evaluation":
+		hint = "Only read-only evaluation is allowed, which excludes the repl context where debuggers run statements and commands. Evaluate in the \"watch\" or \"hover\" context instead."
 	case "modify":
 		hint = "Variable modification is disabled in the current server mode. The server may be in read-only mode."
+	case "execute":
+		hint = "Custom DAP requests are disabled. Ask the administrator to enable 'allowExecute' in the configuration."
+	case "function call":
+		hint = "Calling functions runs the program's code. Ask the administrator to enable 'allowExecute' in the configuration."
+	case "install":
+		hint = "Installing debug adapters is disabled. Ask the administrator to enable 'allowInstall' in the configuration, or run 'dap-mcp -install <language>'."
 	default:
 		hint = fmt.Sprintf("This operation is not allowed in '%s' mode.", mode)
 	}
@@ -364,6 +544,29 @@ func BreakpointFailed(path string, line int, reason string) *DebugError {
 	}
 }
 
+// BreakpointsUnverified creates an error for breakpoints the adapter set but
+// did not verify, when the caller asked for verified breakpoints only.
+// messages maps each unverified line to the adapter's message for it.
+func BreakpointsUnverified(path string, lines []int, messages []string) *DebugError {
+	reasons := make([]string, len(lines))
+	for i, line := range lines {
+		reasons[i] = fmt.Sprintf("line %d", line)
+		if messages[i] != "" {
+			reasons[i] += ": " + messages[i]
+		}
+	}
+	return &DebugError{
+		Code:    CodeBreakpointFailed,
+		Message: fmt.Sprintf("%d breakpoint(s) in %s were not verified (%s)", len(lines), path, strings.Join(reasons, "; ")),
+		Hint:    "The breakpoints are still set and may be verified later, e.g. once their module loads. Move them to lines with executable code, or pass requireVerified=false to accept unverified breakpoints.",
+		Details: map[string]interface{}{
+			"path":     path,
+			"lines":    lines,
+			"messages": messages,
+		},
+	}
+}
+
 // EvaluationFailed creates an error for expression evaluation failures
 func EvaluationFailed(expression string, err error) *DebugError {
 	return &DebugError{
@@ -377,6 +580,53 @@ func EvaluationFailed(expression string, err error) *DebugError {
 	}
 }
 
+// EvaluationResultError creates an error for an evaluation the adapter
+// reported as succeeding with an error message as its result
+func EvaluationResultError(expression string, err error) *DebugError {
+	return &DebugError{
+		Code:    CodeEvaluationFailed,
+		Message: fmt.Sprintf("failed to evaluate expression '%s': the adapter returned an error as its result: %v", expression, err),
+		Hint:    "Check that the expression syntax is correct for the target language and that referenced variables are in scope. If the result is a value that only looks like an error (e.g. a string), evaluate again with rawResult=true.",
+		Cause:   err,
+		Details: map[string]interface{}{
+			"expression": expression,
+		},
+	}
+}
+
+// SideEffectRejected creates an error for an expression refused by read-only evaluation
+func SideEffectRejected(expression, construct string) *DebugError {
+	return &DebugError{
+		Code:    CodePermissionDenied,
+		Message: fmt.Sprintf("expression '%s' looks like it has side effects (%s); only read-only evaluation is allowed", expression, construct),
+		Hint:    "The server is configured with evaluateReadOnly. Evaluate variables, fields, indexes, and operators without calls or assignments, or read values with debug_snapshot.",
+		Details: map[string]interface{}{
+			"expression": expression,
+			"construct":  construct,
+		},
+	}
+}
+
+// FunctionCallUnsupported creates an error when a session can't call
+// functions in the debuggee. reason is the adapter's error, or "" when the
+// session's language has no call support.
+func FunctionCallUnsupported(language, reason string) *DebugError {
+	msg := fmt.Sprintf("debug_call_function only works with Go sessions (Delve); current session language: %s", language)
+	hint := "Use debug_evaluate to call functions in languages whose debuggers evaluate calls directly, e.g. Python or JavaScript."
+	if reason != "" {
+		msg = fmt.Sprintf("Delve can't call functions in this session: %s", reason)
+		hint = "Delve calls functions only in programs built with Go 1.11 or later, on backends that support call injection (not core files or rr recordings). Inspect values with debug_evaluate instead."
+	}
+	return &DebugError{
+		Code:    CodeFunctionCallUnsupported,
+		Message: msg,
+		Hint:    hint,
+		Details: map[string]interface{}{
+			"language": language,
+		},
+	}
+}
+
 // StepFailed creates an error for step failures
 func StepFailed(stepType string, err error) *DebugError {
 	var hint string
@@ -411,6 +661,60 @@ func NoThreads() *DebugError {
 	}
 }
 
+// NotEnoughSnapshots creates an error when a diff needs two stored snapshots
+// and the session has fewer
+func NotEnoughSnapshots(stored int) *DebugError {
+	return &DebugError{
+		Code:    CodeNoSnapshots,
+		Message: fmt.Sprintf("session has %d stored snapshot(s); debug_diff compares two", stored),
+		Hint:    "Call debug_snapshot before and after the step, then debug_diff with their snapshotIds (or none to compare the two latest).",
+		Details: map[string]interface{}{
+			"stored": stored,
+		},
+	}
+}
+
+// NoException creates an error when a thread is not stopped on an exception.
+// reason is the reason of the session's last stop, if known.
+func NoException(threadID int, reason string) *DebugError {
+	msg := fmt.Sprintf("thread %d is not stopped on an exception", threadID)
+	if reason != "" {
+		msg += fmt.Sprintf(" (stopped on %s)", reason)
+	}
+	return &DebugError{
+		Code:    CodeNoException,
+		Message: msg,
+		Hint:    "debug_exception needs a thread stopped with reason \"exception\". Continue until the program raises, or pass the threadId of the thread that did.",
+		Details: map[string]interface{}{
+			"threadId": threadID,
+			"reason":   reason,
+		},
+	}
+}
+
+// SourceUnavailable creates an error when source content can't be read from
+// disk or fetched from the adapter
+func SourceUnavailable(path string, sourceRef int, err error) *DebugError {
+	return &DebugError{
+		Code:    CodeSourceUnavailable,
+		Message: fmt.Sprintf("could not get source for %s: %v", sourceLabel(path, sourceRef), err),
+		Hint:    "Use the path or sourceReference reported in a debug_snapshot stack frame. Sources without a file on disk (sourceReference > 0) are only available while the session is active.",
+		Cause:   err,
+		Details: map[string]interface{}{
+			"path":            path,
+			"sourceReference": sourceRef,
+		},
+	}
+}
+
+// sourceLabel describes a source by path, or by reference if it has none
+func sourceLabel(path string, sourceRef int) string {
+	if path != "" {
+		return path
+	}
+	return fmt.Sprintf("sourceReference %d", sourceRef)
+}
+
 // --- Helper for wrapping generic errors ---
 
 // Wrap wraps a generic error with context
@@ -0,0 +1,301 @@
+// Package install installs debug adapters for the languages whose adapters
+// can be fetched without a system package manager: Delve for Go, debugpy for
+// Python, and vscode-js-debug for JavaScript/TypeScript.
+//
+// Each installer runs the same command the documentation recommends and
+// reports the adapter path together with the configuration key it belongs
+// in, so callers can write it into a dap-mcp config file.
+package install
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ctagard/dap-mcp/internal/version"
+)
+
+// JSDebugReleaseURL is the GitHub API URL of the latest vscode-js-debug release
+const JSDebugReleaseURL = "https://api.github.com/repos/microsoft/vscode-js-debug/releases/latest"
+
+// Options controls where and how adapters are installed
+type Options struct {
+	// Dir is where downloaded adapters are unpacked
+	// (default: ~/.local/share/dap-mcp)
+	Dir string
+
+	// PythonPath is the interpreter debugpy is installed into (default: python3)
+	PythonPath string
+
+	// ReleaseURL overrides JSDebugReleaseURL
+	ReleaseURL string
+
+	// Progress receives installer output as it runs (may be nil)
+	Progress io.Writer
+}
+
+// Result describes an installed adapter
+type Result struct {
+	Language  string `json:"language"`
+	Adapter   string `json:"adapter"`
+	Path      string `json:"path"`
+	ConfigKey string `json:"configKey"` // Dotted config key the path belongs in
+}
+
+// Languages lists the languages Install supports
+func Languages() []string {
+	return []string{"go", "python", "javascript", "typescript"}
+}
+
+// Install installs the debug adapter for a language
+func Install(ctx context.Context, language string, opts Options) (*Result, error) {
+	if opts.Progress == nil {
+		opts.Progress = io.Discard
+	}
+
+	switch strings.ToLower(language) {
+	case "go":
+		return installDelve(ctx, opts)
+	case "python":
+		return installDebugpy(ctx, opts)
+	case "javascript", "typescript", "node":
+		return installJSDebug(ctx, opts)
+	case "c", "cpp", "rust", "objc", "objective-c", "swift", "lldb":
+		return nil, fmt.Errorf("lldb-dap is not installed by dap-mcp: install LLDB with your system package manager (xcode-select --install, apt install lldb, or dnf install lldb)")
+	case "gdb":
+		return nil, fmt.Errorf("gdb is not installed by dap-mcp: install GDB 14.1 or later with your system package manager")
+	case "elixir", "erlang":
+		return nil, fmt.Errorf("ElixirLS is not installed by dap-mcp: download a release from https://github.com/elixir-lsp/elixir-ls/releases, unzip it, and set adapters.elixir.elixirLsPath to its debug_adapter.sh")
+	default:
+		return nil, fmt.Errorf("no installer for language %q (supported: %s)", language, strings.Join(Languages(), ", "))
+	}
+}
+
+// installDelve runs go install for dlv and finds the installed binary
+func installDelve(ctx context.Context, opts Options) (*Result, error) {
+	if err := run(ctx, opts.Progress, "go", "install", "github.com/go-delve/delve/cmd/dlv@latest"); err != nil {
+		return nil, fmt.Errorf("go install dlv failed: %w", err)
+	}
+
+	binDir, err := goEnv(ctx, "GOBIN")
+	if err != nil {
+		return nil, err
+	}
+	if binDir == "" {
+		gopath, err := goEnv(ctx, "GOPATH")
+		if err != nil {
+			return nil, err
+		}
+		// GOPATH may list several directories; go install uses the first
+		binDir = filepath.Join(filepath.SplitList(gopath)[0], "bin")
+	}
+
+	name := "dlv"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(binDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("dlv was installed but not found at %s: %w", path, err)
+	}
+
+	return &Result{Language: "go", Adapter: "delve", Path: path, ConfigKey: "adapters.go.path"}, nil
+}
+
+// installDebugpy runs pip install debugpy and reports the interpreter it went into
+func installDebugpy(ctx context.Context, opts Options) (*Result, error) {
+	python := opts.PythonPath
+	if python == "" {
+		python = "python3"
+	}
+
+	if err := run(ctx, opts.Progress, python, "-m", "pip", "install", "--upgrade", "debugpy"); err != nil {
+		return nil, fmt.Errorf("pip install debugpy failed: %w", err)
+	}
+
+	// The adapter path for debugpy is the interpreter that can import it
+	out, err := exec.CommandContext(ctx, python, "-c", "import debugpy, sys; print(sys.executable)").Output()
+	if err != nil {
+		return nil, fmt.Errorf("debugpy was installed but cannot be imported by %s: %w", python, err)
+	}
+	path := strings.TrimSpace(string(out))
+
+	return &Result{Language: "python", Adapter: "debugpy", Path: path, ConfigKey: "adapters.python.pythonPath"}, nil
+}
+
+// githubRelease is the part of a GitHub release the installer reads
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// installJSDebug downloads the latest vscode-js-debug DAP server release
+// and unpacks it into the install directory
+func installJSDebug(ctx context.Context, opts Options) (*Result, error) {
+	dir, err := installDir(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseURL := opts.ReleaseURL
+	if releaseURL == "" {
+		releaseURL = JSDebugReleaseURL
+	}
+
+	fmt.Fprintf(opts.Progress, "Fetching latest vscode-js-debug release from %s\n", releaseURL)
+	body, err := download(ctx, releaseURL)
+	if err != nil {
+		return nil, err
+	}
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+
+	var assetURL, assetName string
+	for _, asset := range release.Assets {
+		if strings.HasPrefix(asset.Name, "js-debug-dap-") && strings.HasSuffix(asset.Name, ".tar.gz") {
+			assetURL, assetName = asset.BrowserDownloadURL, asset.Name
+			break
+		}
+	}
+	if assetURL == "" {
+		return nil, fmt.Errorf("release %s has no js-debug-dap-*.tar.gz asset", release.TagName)
+	}
+
+	fmt.Fprintf(opts.Progress, "Downloading %s\n", assetName)
+	archive, err := download(ctx, assetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The archive unpacks to js-debug/; replace any earlier install
+	target := filepath.Join(dir, "js-debug")
+	if err := os.RemoveAll(target); err != nil {
+		return nil, fmt.Errorf("failed to remove previous install: %w", err)
+	}
+	fmt.Fprintf(opts.Progress, "Extracting to %s\n", dir)
+	if err := extractTarGz(archive, dir); err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", assetName, err)
+	}
+
+	path := filepath.Join(target, "src", "dapDebugServer.js")
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%s did not contain js-debug/src/dapDebugServer.js", assetName)
+	}
+
+	return &Result{Language: "javascript", Adapter: "vscode-js-debug", Path: path, ConfigKey: "adapters.node.jsDebugPath"}, nil
+}
+
+// installDir returns the directory downloads are unpacked into, creating it
+func installDir(dir string) (string, error) {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine install directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "share", "dap-mcp")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// download fetches a URL into memory
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "dap-mcp/"+version.Version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extractTarGz unpacks a gzipped tarball into dir, refusing entries that
+// would land outside it
+func extractTarGz(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	root := filepath.Clean(dir) + string(os.PathSeparator)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, hdr.Name)
+		if !strings.HasPrefix(target, root) {
+			return fmt.Errorf("archive entry %q is outside the install directory", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0o777|0o600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// run runs an installer command, streaming its output to progress
+func run(ctx context.Context, progress io.Writer, name string, args ...string) error {
+	fmt.Fprintf(progress, "Running: %s %s\n", name, strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = progress
+	cmd.Stderr = progress
+	return cmd.Run()
+}
+
+// goEnv reads a variable from go env
+func goEnv(ctx context.Context, name string) (string, error) {
+	out, err := exec.CommandContext(ctx, "go", "env", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("go env %s failed: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,90 @@
+package launchconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ArgList is a program's argument list. launch.json files may give it as an
+// array or as a single command-line string, which is split with SplitArgs.
+type ArgList []string
+
+// UnmarshalJSON accepts either a JSON array of strings or a single string.
+func (a *ArgList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		args, err := SplitArgs(s)
+		if err != nil {
+			return fmt.Errorf("invalid args string: %w", err)
+		}
+		*a = args
+		return nil
+	}
+
+	var arr []string
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return fmt.Errorf("args must be an array of strings or a string: %w", err)
+	}
+	*a = arr
+	return nil
+}
+
+// SplitArgs splits a command line into arguments the way a POSIX shell does,
+// without expanding anything: whitespace separates arguments, single quotes
+// keep their contents literally, double quotes keep their contents except
+// that \" and \\ are escapes, and outside quotes a backslash escapes the next
+// character. An unterminated quote is an error.
+func SplitArgs(s string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg := false // Distinguishes an empty quoted argument from no argument
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				current.WriteRune(runes[i])
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -12,6 +12,8 @@ const (
 	LaunchJSONFileName = "launch.json"
 	// VSCodeDirName is the VS Code configuration directory name.
 	VSCodeDirName = ".vscode"
+	// LaunchJSONVersion is the launch.json schema version understood by this package.
+	LaunchJSONVersion = "0.2.0"
 )
 
 // LoadFromPath loads a launch.json file from an explicit path.
@@ -21,8 +23,9 @@ func LoadFromPath(path string) (*LaunchJSON, error) {
 		return nil, fmt.Errorf("failed to read launch.json: %w", err)
 	}
 
+	// launch.json is JSON with comments and may have trailing commas
 	var lj LaunchJSON
-	if err := json.Unmarshal(data, &lj); err != nil {
+	if err := json.Unmarshal(stripJSONC(data), &lj); err != nil {
 		return nil, fmt.Errorf("failed to parse launch.json: %w", err)
 	}
 
@@ -205,7 +208,7 @@ func ValidateConfiguration(cfg *DebugConfiguration) error {
 	if cfg.Request != "launch" && cfg.Request != "attach" {
 		return fmt.Errorf("configuration request must be 'launch' or 'attach', got %q", cfg.Request)
 	}
-	return nil
+	return cfg.CheckType()
 }
 
 // ValidateLaunchJSON performs validation on the entire launch.json.
@@ -236,5 +239,39 @@ func ValidateLaunchJSON(lj *LaunchJSON) []error {
 		}
 	}
 
+	// Validate ${input:} references point at declared inputs
+	inputIDs := make(map[string]bool)
+	for _, input := range lj.Inputs {
+		inputIDs[input.ID] = true
+	}
+
+	for i := range lj.Configurations {
+		cfg := &lj.Configurations[i]
+		for _, id := range FindAllRequiredInputsInConfig(cfg) {
+			if !inputIDs[id] {
+				errors = append(errors, fmt.Errorf("configuration %q references undefined input %q", cfg.Name, id))
+			}
+		}
+	}
+
 	return errors
 }
+
+// ValidationWarnings runs the pre-flight checks on a launch.json and returns
+// them as human-readable warnings. In addition to ValidateLaunchJSON, it flags
+// a missing or unsupported schema version.
+func ValidationWarnings(lj *LaunchJSON) []string {
+	var warnings []string
+
+	if lj.Version == "" {
+		warnings = append(warnings, fmt.Sprintf("version is missing, expected %q", LaunchJSONVersion))
+	} else if lj.Version != LaunchJSONVersion {
+		warnings = append(warnings, fmt.Sprintf("version %q is not supported, expected %q", lj.Version, LaunchJSONVersion))
+	}
+
+	for _, err := range ValidateLaunchJSON(lj) {
+		warnings = append(warnings, err.Error())
+	}
+
+	return warnings
+}
@@ -27,10 +27,14 @@ func ResolveConfiguration(cfg *DebugConfiguration, ctx *ResolutionContext) (*Res
 		ctx = &ResolutionContext{}
 	}
 
-	// Check for missing input values first
+	// Fill in inputs that were not provided, then check for any still missing
+	ctx, err := applyInputFallbacks(cfg, ctx)
+	if err != nil {
+		return nil, err
+	}
 	missingInputs := ValidateInputsProvided(cfg, ctx.InputValues)
 	if len(missingInputs) > 0 {
-		return nil, &MissingInputsError{Inputs: missingInputs}
+		return nil, newMissingInputsError(missingInputs, ctx.Inputs)
 	}
 
 	// Create a copy of the configuration
@@ -47,9 +51,9 @@ func ResolveConfiguration(cfg *DebugConfiguration, ctx *ResolutionContext) (*Res
 		RedirectOutput: cfg.RedirectOutput,
 		SourceMaps:     cfg.SourceMaps,
 		Presentation:   cfg.Presentation,
-	}
 
-	var err error
+		StopAtBeginningOfMainSubprogram: cfg.StopAtBeginningOfMainSubprogram,
+	}
 
 	// Resolve string fields
 	resolved.Program, err = ResolveStringField(cfg.Program, ctx)
@@ -139,6 +143,14 @@ func ResolveConfiguration(cfg *DebugConfiguration, ctx *ResolutionContext) (*Res
 		return nil, fmt.Errorf("failed to resolve runtimeArgs: %w", err)
 	}
 
+	for _, pair := range cfg.SourceMap {
+		resolvedPair, err := ResolveStringSlice(pair, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve sourceMap: %w", err)
+		}
+		resolved.SourceMap = append(resolved.SourceMap, resolvedPair)
+	}
+
 	// Resolve map fields
 	resolved.Env, err = ResolveStringMap(cfg.Env, ctx)
 	if err != nil {
@@ -165,12 +177,17 @@ func ResolveConfiguration(cfg *DebugConfiguration, ctx *ResolutionContext) (*Res
 	}, nil
 }
 
+// maxResolveDepth bounds how deeply nested the arrays and objects in extra
+// fields may be. Real launch configurations nest a few levels; anything deeper
+// is malformed, or a map that contains itself.
+const maxResolveDepth = 32
+
 // resolveExtraFields recursively resolves variables in extra fields.
 func resolveExtraFields(extra map[string]interface{}, ctx *ResolutionContext) (map[string]interface{}, error) {
 	result := make(map[string]interface{}, len(extra))
 
 	for k, v := range extra {
-		resolved, err := resolveValue(v, ctx)
+		resolved, err := resolveValue(v, ctx, 1)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve extra[%s]: %w", k, err)
 		}
@@ -180,15 +197,19 @@ func resolveExtraFields(extra map[string]interface{}, ctx *ResolutionContext) (m
 	return result, nil
 }
 
-// resolveValue resolves variables in a value of any type.
-func resolveValue(v interface{}, ctx *ResolutionContext) (interface{}, error) {
+// resolveValue resolves variables in a value of any type. depth is the value's
+// nesting level within the extra fields.
+func resolveValue(v interface{}, ctx *ResolutionContext, depth int) (interface{}, error) {
 	switch val := v.(type) {
 	case string:
 		return ResolveVariables(val, ctx)
 	case []interface{}:
+		if depth > maxResolveDepth {
+			return nil, fmt.Errorf("value is nested more than %d levels deep", maxResolveDepth)
+		}
 		result := make([]interface{}, len(val))
 		for i, item := range val {
-			resolved, err := resolveValue(item, ctx)
+			resolved, err := resolveValue(item, ctx, depth+1)
 			if err != nil {
 				return nil, err
 			}
@@ -196,9 +217,12 @@ func resolveValue(v interface{}, ctx *ResolutionContext) (interface{}, error) {
 		}
 		return result, nil
 	case map[string]interface{}:
+		if depth > maxResolveDepth {
+			return nil, fmt.Errorf("value is nested more than %d levels deep", maxResolveDepth)
+		}
 		result := make(map[string]interface{}, len(val))
 		for k, item := range val {
-			resolved, err := resolveValue(item, ctx)
+			resolved, err := resolveValue(item, ctx, depth+1)
 			if err != nil {
 				return nil, err
 			}
@@ -211,9 +235,72 @@ func resolveValue(v interface{}, ctx *ResolutionContext) (interface{}, error) {
 	}
 }
 
+// applyInputFallbacks returns a copy of ctx whose InputValues also contain values
+// for inputs the caller did not provide: the input's default if it has one, or
+// the output of its command for command-type inputs when commands are allowed.
+func applyInputFallbacks(cfg *DebugConfiguration, ctx *ResolutionContext) (*ResolutionContext, error) {
+	missing := ValidateInputsProvided(cfg, ctx.InputValues)
+	if len(missing) == 0 || len(ctx.Inputs) == 0 {
+		return ctx, nil
+	}
+
+	values := make(map[string]string, len(ctx.InputValues)+len(missing))
+	for k, v := range ctx.InputValues {
+		values[k] = v
+	}
+
+	for _, id := range missing {
+		input := findInputConfig(ctx.Inputs, id)
+		if input == nil {
+			continue
+		}
+		switch {
+		case input.Default != "":
+			values[id] = input.Default
+		case input.Type == "command" && input.Command != "" && ctx.AllowCommands:
+			value, err := resolveCommandVariable(input.Command, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve input %q: %w", id, err)
+			}
+			values[id] = value
+		}
+	}
+
+	resolvedCtx := *ctx
+	resolvedCtx.InputValues = values
+	return &resolvedCtx, nil
+}
+
+// findInputConfig finds an input definition by ID, returning nil if it is not declared.
+func findInputConfig(inputs []InputConfig, id string) *InputConfig {
+	for i := range inputs {
+		if inputs[i].ID == id {
+			return &inputs[i]
+		}
+	}
+	return nil
+}
+
 // MissingInputsError is returned when required ${input:} values are not provided.
 type MissingInputsError struct {
 	Inputs []string
+
+	// Definitions holds the launch.json definition of each missing input, in the
+	// same order as Inputs. Undeclared inputs only have their ID set.
+	Definitions []InputConfig
+}
+
+// newMissingInputsError creates a MissingInputsError with the definitions of the missing inputs.
+func newMissingInputsError(missing []string, inputs []InputConfig) *MissingInputsError {
+	definitions := make([]InputConfig, len(missing))
+	for i, id := range missing {
+		if input := findInputConfig(inputs, id); input != nil {
+			definitions[i] = *input
+		} else {
+			definitions[i] = InputConfig{ID: id}
+		}
+	}
+	return &MissingInputsError{Inputs: missing, Definitions: definitions}
 }
 
 func (e *MissingInputsError) Error() string {
@@ -238,7 +325,7 @@ func (r *ResolvedConfiguration) ToLaunchArgs() map[string]interface{} {
 		args["program"] = r.Program
 	}
 	if len(r.Args) > 0 {
-		args["args"] = r.Args
+		args["args"] = []string(r.Args)
 	}
 	if r.Cwd != "" {
 		args["cwd"] = r.Cwd
@@ -275,6 +362,11 @@ func (r *ResolvedConfiguration) ToLaunchArgs() map[string]interface{} {
 		args["buildFlags"] = r.BuildFlags
 	}
 
+	// GDB fields
+	if r.StopAtBeginningOfMainSubprogram {
+		args["stopAtBeginningOfMainSubprogram"] = true
+	}
+
 	// Python fields - output both "python" (VS Code) and "pythonPath" (debugpy) for compatibility
 	// "python" takes precedence if both are set
 	pythonInterpreter := r.Python
@@ -304,6 +396,11 @@ func (r *ResolvedConfiguration) ToLaunchArgs() map[string]interface{} {
 		args["debugAdapterPath"] = r.DebugAdapterPath
 	}
 
+	// Source path remapping (lldb, gdb, and debugpy's pathMappings)
+	if len(r.SourceMap) > 0 {
+		args["sourceMap"] = r.SourceMap
+	}
+
 	// Source maps
 	if r.SourceMaps != nil {
 		args["sourceMaps"] = *r.SourceMaps
@@ -335,6 +432,14 @@ func (r *ResolvedConfiguration) ToAttachArgs() map[string]interface{} {
 		args["processId"] = r.ProcessID
 	}
 
+	// Wait for a process with the program's name to start (lldb)
+	if r.WaitFor {
+		args["waitFor"] = true
+		if r.Program != "" {
+			args["program"] = r.Program
+		}
+	}
+
 	// Browser fields
 	if r.URL != "" {
 		args["url"] = r.URL
@@ -348,6 +453,11 @@ func (r *ResolvedConfiguration) ToAttachArgs() map[string]interface{} {
 		args["target"] = r.Target
 	}
 
+	// Source path remapping (lldb, gdb, and debugpy's pathMappings)
+	if len(r.SourceMap) > 0 {
+		args["sourceMap"] = r.SourceMap
+	}
+
 	// Source maps
 	if r.SourceMaps != nil {
 		args["sourceMaps"] = *r.SourceMaps
@@ -375,9 +485,10 @@ func (cfg *DebugConfiguration) Clone() *DebugConfiguration {
 
 // MergeOverrides applies override values to a configuration.
 // This allows tool arguments to override values from launch.json.
-func MergeOverrides(cfg *DebugConfiguration, overrides map[string]interface{}) *DebugConfiguration {
+// An args string with an unterminated quote is an error.
+func MergeOverrides(cfg *DebugConfiguration, overrides map[string]interface{}) (*DebugConfiguration, error) {
 	if len(overrides) == 0 {
-		return cfg
+		return cfg, nil
 	}
 
 	// Clone the configuration first
@@ -391,7 +502,13 @@ func MergeOverrides(cfg *DebugConfiguration, overrides map[string]interface{}) *
 				result.Program = s
 			}
 		case "args":
-			if arr, ok := v.([]interface{}); ok {
+			if str, ok := v.(string); ok {
+				args, err := SplitArgs(str)
+				if err != nil {
+					return nil, fmt.Errorf("invalid args string: %w", err)
+				}
+				result.Args = args
+			} else if arr, ok := v.([]interface{}); ok {
 				args := make([]string, len(arr))
 				for i, item := range arr {
 					if s, ok := item.(string); ok {
@@ -439,5 +556,5 @@ func MergeOverrides(cfg *DebugConfiguration, overrides map[string]interface{}) *
 		}
 	}
 
-	return result
+	return result, nil
 }
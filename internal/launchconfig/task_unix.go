@@ -0,0 +1,36 @@
+//go:build !windows
+
+package launchconfig
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// shellCommand runs a command line with the POSIX shell
+func shellCommand(line string) *exec.Cmd {
+	return exec.Command("sh", "-c", line)
+}
+
+// shellQuote quotes an argument for the POSIX shell if it needs it
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`&|;<>()*?[]#~") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// setTaskProcAttr puts a task in its own process group, so the processes a
+// shell task starts can be killed with it
+func setTaskProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killTask kills a task's process group
+func killTask(cmd *exec.Cmd) error {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
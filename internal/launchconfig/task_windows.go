@@ -0,0 +1,38 @@
+//go:build windows
+
+package launchconfig
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// shellCommand runs a command line with cmd.exe
+func shellCommand(line string) *exec.Cmd {
+	return exec.Command("cmd", "/C", line)
+}
+
+// shellQuote quotes an argument for cmd.exe if it needs it
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"&|<>^") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+}
+
+// setTaskProcAttr starts a task in a new process group
+func setTaskProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// killTask kills a task's process. Windows has no Unix-style process groups,
+// so processes the task started may outlive it.
+func killTask(cmd *exec.Cmd) error {
+	if err := cmd.Process.Kill(); err != nil && err.Error() != "os: process already finished" {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package launchconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// taskOutputLines is how many of a task's last output lines are kept for errors
+const taskOutputLines = 20
+
+// taskStopTimeout bounds the wait for a killed task to exit
+const taskStopTimeout = 5 * time.Second
+
+// RunningTask is a background task started by StartTask. It keeps running
+// until Stop is called.
+type RunningTask struct {
+	Label string
+
+	cmd    *exec.Cmd
+	output *taskOutput
+	done   chan struct{} // Closed once the process has exited
+	err    error         // The process's exit error, set before done is closed
+	stop   sync.Once
+}
+
+// taskOutput keeps a task's last output lines and reports the first line
+// matching a readiness pattern
+type taskOutput struct {
+	mu    sync.Mutex
+	lines []string
+
+	ready     *regexp.Regexp
+	readyCh   chan struct{}
+	readyOnce sync.Once
+	eof       chan struct{} // Closed once every writer of the output is gone
+
+	failure []failureMatcher
+	errors  []string // The first output lines that reported errors
+}
+
+func (o *taskOutput) scan(f *os.File) {
+	defer close(o.eof)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		o.mu.Lock()
+		o.lines = append(o.lines, line)
+		if len(o.lines) > taskOutputLines {
+			o.lines = o.lines[len(o.lines)-taskOutputLines:]
+		}
+		if len(o.errors) < taskOutputLines {
+			for _, m := range o.failure {
+				if m.isError(line) {
+					o.errors = append(o.errors, line)
+					break
+				}
+			}
+		}
+		o.mu.Unlock()
+		if o.ready != nil && o.ready.MatchString(line) {
+			o.readyOnce.Do(func() { close(o.readyCh) })
+		}
+	}
+}
+
+// tail returns the last output lines, for error messages
+func (o *taskOutput) tail() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.lines) == 0 {
+		return "(no output)"
+	}
+	return strings.Join(o.lines, "\n")
+}
+
+// errorLines returns the output lines that reported errors
+func (o *taskOutput) errorLines() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.errors
+}
+
+// startTask starts a task's process with its stdout and stderr read into one
+// taskOutput, which collects the lines the failure matchers report as errors
+func startTask(t *TaskConfig, workspace string, ready *regexp.Regexp, failure []failureMatcher) (*RunningTask, error) {
+	if t.Command == "" {
+		return nil, fmt.Errorf("task %q has no command", t.Label)
+	}
+
+	var cmd *exec.Cmd
+	if t.Type == "process" {
+		cmd = exec.Command(t.Command, t.Args...)
+	} else {
+		// Shell tasks run the command line with the args appended
+		line := t.Command
+		for _, arg := range t.Args {
+			line += " " + shellQuote(arg)
+		}
+		cmd = shellCommand(line)
+	}
+	cmd.Dir = workspace
+	cmd.Env = os.Environ()
+	if t.Options != nil {
+		if t.Options.Cwd != "" {
+			cmd.Dir = t.Options.Cwd
+		}
+		for k, v := range t.Options.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	setTaskProcAttr(cmd)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start task %q: %w", t.Label, err)
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Start(); err != nil {
+		_ = r.Close()
+		_ = w.Close()
+		return nil, fmt.Errorf("failed to start task %q: %w", t.Label, err)
+	}
+	_ = w.Close() // The task holds the only writer now
+
+	task := &RunningTask{
+		Label: t.Label,
+		cmd:   cmd,
+		output: &taskOutput{
+			ready:   ready,
+			readyCh: make(chan struct{}),
+			eof:     make(chan struct{}),
+			failure: failure,
+		},
+		done: make(chan struct{}),
+	}
+	go task.output.scan(r)
+	go func() {
+		task.err = cmd.Wait()
+		close(task.done)
+	}()
+	return task, nil
+}
+
+// RunTask runs a task to completion, killing it after timeout. A task that
+// fails or times out returns an error with its last output lines. A task with
+// a failurePattern or problem matcher pattern also fails, even if it exits 0,
+// when it printed an error line, returning those lines; a compiler that
+// reports errors without failing would otherwise leave a stale build to debug.
+func RunTask(t *TaskConfig, workspace string, timeout time.Duration) error {
+	failure, err := t.failureMatchers()
+	if err != nil {
+		return err
+	}
+	task, err := startTask(t, workspace, nil, failure)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-task.done:
+	case <-time.After(timeout):
+		task.Stop()
+		return fmt.Errorf("task %q did not finish within %v; last output:\n%s", t.Label, timeout, task.output.tail())
+	}
+	// Let the reader catch up, unless a leftover child still holds the output open
+	select {
+	case <-task.output.eof:
+	case <-time.After(time.Second):
+	}
+	if task.err != nil {
+		return fmt.Errorf("task %q failed: %v; last output:\n%s", t.Label, task.err, task.output.tail())
+	}
+	if errors := task.output.errorLines(); len(errors) > 0 {
+		return fmt.Errorf("task %q exited 0 but printed errors:\n%s", t.Label, strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
+// StartTask starts a background task, such as a watcher, and leaves it
+// running. It returns once an output line matches the task's ready pattern
+// (see BackgroundReadyPattern), or at once if it has none. A task that exits
+// first, or isn't ready within readyTimeout, is an error with its last
+// output lines.
+func StartTask(t *TaskConfig, workspace string, readyTimeout time.Duration) (*RunningTask, error) {
+	var ready *regexp.Regexp
+	if pattern := t.BackgroundReadyPattern(); pattern != "" {
+		var err error
+		if ready, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("task %q has an invalid ready pattern %q: %w", t.Label, pattern, err)
+		}
+	}
+
+	task, err := startTask(t, workspace, ready, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ready == nil {
+		return task, nil
+	}
+
+	select {
+	case <-task.output.readyCh:
+		return task, nil
+	case <-task.done:
+		// The ready line may have been the last thing it printed
+		select {
+		case <-task.output.eof:
+		case <-time.After(time.Second):
+		}
+		select {
+		case <-task.output.readyCh:
+			return task, nil
+		default:
+		}
+		return nil, fmt.Errorf("task %q exited before it was ready (%v); last output:\n%s", t.Label, exitStatus(task.err), task.output.tail())
+	case <-time.After(readyTimeout):
+		task.Stop()
+		return nil, fmt.Errorf("task %q printed no line matching %q within %v; last output:\n%s", t.Label, ready, readyTimeout, task.output.tail())
+	}
+}
+
+// exitStatus describes how a task's process exited
+func exitStatus(err error) string {
+	if err == nil {
+		return "exit status 0"
+	}
+	return err.Error()
+}
+
+// Stop kills the task and the processes it started, and waits briefly for it
+// to exit. It is safe to call more than once.
+func (t *RunningTask) Stop() {
+	t.stop.Do(func() {
+		select {
+		case <-t.done:
+			return
+		default:
+		}
+		_ = killTask(t.cmd) // Error ignored: the task may have just exited
+		select {
+		case <-t.done:
+		case <-time.After(taskStopTimeout):
+		}
+	})
+}
+
+// Exited reports whether the task's process has exited.
+func (t *RunningTask) Exited() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,244 @@
+package launchconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TasksJSONFileName is the standard name for the VS Code tasks file.
+const TasksJSONFileName = "tasks.json"
+
+// TasksJSON represents a VS Code tasks.json file structure.
+type TasksJSON struct {
+	Version string       `json:"version"`
+	Tasks   []TaskConfig `json:"tasks"`
+}
+
+// TaskConfig represents a single task in tasks.json.
+type TaskConfig struct {
+	Label   string       `json:"label"`
+	Type    string       `json:"type"` // "shell" or "process"
+	Command string       `json:"command"`
+	Args    ArgList      `json:"args,omitempty"`
+	Options *TaskOptions `json:"options,omitempty"`
+
+	// IsBackground marks a long-running task, such as a watcher, that is
+	// started and left running instead of awaited
+	IsBackground bool `json:"isBackground,omitempty"`
+
+	// ProblemMatcher is a matcher name, a matcher object, or an array of
+	// either. For background tasks, background.endsPattern marks readiness.
+	ProblemMatcher json.RawMessage `json:"problemMatcher,omitempty"`
+
+	// ReadyPattern is a regular expression for the output line that shows a
+	// background task is ready, used instead of the problem matcher's
+	// endsPattern (a dap-mcp extension; VS Code ignores it)
+	ReadyPattern string `json:"readyPattern,omitempty"`
+
+	// FailurePattern is a regular expression for output lines that report
+	// errors: a task that prints one fails even if it exits 0 (a dap-mcp
+	// extension; VS Code ignores it)
+	FailurePattern string `json:"failurePattern,omitempty"`
+}
+
+// TaskOptions holds a task's working directory and environment.
+type TaskOptions struct {
+	Cwd string            `json:"cwd,omitempty"`
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// LoadTasksFromPath loads a tasks.json file from an explicit path.
+func LoadTasksFromPath(path string) (*TasksJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks.json: %w", err)
+	}
+
+	// tasks.json is JSON with comments, like launch.json
+	var tj TasksJSON
+	if err := json.Unmarshal(stripJSONC(data), &tj); err != nil {
+		return nil, fmt.Errorf("failed to parse tasks.json: %w", err)
+	}
+
+	return &tj, nil
+}
+
+// TasksPathFor returns the path of the tasks.json next to a launch.json.
+func TasksPathFor(launchJSONPath string) string {
+	return filepath.Join(filepath.Dir(launchJSONPath), TasksJSONFileName)
+}
+
+// FindTask finds a task by label in the TasksJSON.
+func FindTask(tj *TasksJSON, label string) (*TaskConfig, error) {
+	for i := range tj.Tasks {
+		if tj.Tasks[i].Label == label {
+			return &tj.Tasks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("task %q not found", label)
+}
+
+// BackgroundReadyPattern returns the pattern of the output line that shows a
+// background task is ready: ReadyPattern if set, otherwise the endsPattern of
+// the first problem matcher with a background section. Empty means the task
+// has no readiness signal.
+func (t *TaskConfig) BackgroundReadyPattern() string {
+	if t.ReadyPattern != "" {
+		return t.ReadyPattern
+	}
+	if len(t.ProblemMatcher) == 0 {
+		return ""
+	}
+
+	var matchers []json.RawMessage
+	if err := json.Unmarshal(t.ProblemMatcher, &matchers); err != nil {
+		matchers = []json.RawMessage{t.ProblemMatcher}
+	}
+	for _, raw := range matchers {
+		// Named matchers (e.g. "$tsc-watch") are strings and don't unmarshal
+		var matcher struct {
+			Background *struct {
+				EndsPattern json.RawMessage `json:"endsPattern"`
+			} `json:"background"`
+		}
+		if json.Unmarshal(raw, &matcher) != nil || matcher.Background == nil {
+			continue
+		}
+		if pattern := patternString(matcher.Background.EndsPattern); pattern != "" {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// failureMatcher recognizes the output lines of a task that report errors
+type failureMatcher struct {
+	pattern *regexp.Regexp
+	// severityGroup is the pattern's group holding a line's severity, 0 if none
+	severityGroup int
+	// severity is the severity of lines without a severity group; empty is error
+	severity string
+}
+
+// isError reports whether a line matches and is an error, not a warning or info
+func (m failureMatcher) isError(line string) bool {
+	match := m.pattern.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+	severity := m.severity
+	if m.severityGroup > 0 && m.severityGroup < len(match) && match[m.severityGroup] != "" {
+		severity = match[m.severityGroup]
+	}
+	severity = strings.ToLower(severity)
+	for _, benign := range []string{"warn", "info", "note", "hint"} {
+		if strings.HasPrefix(severity, benign) {
+			return false
+		}
+	}
+	return true
+}
+
+// failureMatchers returns the matchers for output lines that fail the task
+// even when it exits 0: FailurePattern, and the pattern of each problem
+// matcher object. Named matchers (e.g. "$gcc") are defined by VS Code and its
+// extensions, so they aren't recognized; a task with neither fails on its exit
+// status alone.
+func (t *TaskConfig) failureMatchers() ([]failureMatcher, error) {
+	var matchers []failureMatcher
+	if t.FailurePattern != "" {
+		pattern, err := regexp.Compile(t.FailurePattern)
+		if err != nil {
+			return nil, fmt.Errorf("task %q has an invalid failurePattern %q: %w", t.Label, t.FailurePattern, err)
+		}
+		matchers = append(matchers, failureMatcher{pattern: pattern})
+	}
+	if len(t.ProblemMatcher) == 0 {
+		return matchers, nil
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(t.ProblemMatcher, &raws); err != nil {
+		raws = []json.RawMessage{t.ProblemMatcher}
+	}
+	for _, raw := range raws {
+		var matcher struct {
+			Severity string          `json:"severity"`
+			Pattern  json.RawMessage `json:"pattern"`
+		}
+		if json.Unmarshal(raw, &matcher) != nil || len(matcher.Pattern) == 0 {
+			continue
+		}
+		// A multiline pattern's first line is the one that starts a problem
+		var lines []json.RawMessage
+		if err := json.Unmarshal(matcher.Pattern, &lines); err != nil {
+			lines = []json.RawMessage{matcher.Pattern}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		expr := patternString(lines[0])
+		if expr == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("task %q has an invalid problemMatcher pattern %q: %w", t.Label, expr, err)
+		}
+		var groups struct {
+			Severity int `json:"severity"`
+		}
+		_ = json.Unmarshal(lines[0], &groups) // A string pattern has no groups
+		matchers = append(matchers, failureMatcher{pattern: pattern, severityGroup: groups.Severity, severity: matcher.Severity})
+	}
+	return matchers, nil
+}
+
+// patternString reads a problem matcher pattern, either a regular expression
+// string or an object with a regexp field
+func patternString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var p struct {
+		Regexp string `json:"regexp"`
+	}
+	if json.Unmarshal(raw, &p) == nil {
+		return p.Regexp
+	}
+	return ""
+}
+
+// ResolveTask resolves all variables in a task.
+func ResolveTask(t *TaskConfig, ctx *ResolutionContext) (*TaskConfig, error) {
+	resolved := *t
+	var err error
+
+	resolved.Command, err = ResolveStringField(t.Command, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve command: %w", err)
+	}
+	resolved.Args, err = ResolveStringSlice(t.Args, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve args: %w", err)
+	}
+	if t.Options != nil {
+		options := &TaskOptions{}
+		options.Cwd, err = ResolveStringField(t.Options.Cwd, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve options.cwd: %w", err)
+		}
+		options.Env, err = ResolveStringMap(t.Options.Env, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve options.env: %w", err)
+		}
+		resolved.Options = options
+	}
+
+	return &resolved, nil
+}
@@ -3,6 +3,9 @@ package launchconfig
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 )
 
 // LaunchJSON represents a VS Code launch.json file structure.
@@ -22,7 +25,7 @@ type DebugConfiguration struct {
 
 	// Common optional fields
 	Program     string            `json:"program,omitempty"`
-	Args        []string          `json:"args,omitempty"`
+	Args        ArgList           `json:"args,omitempty"` // Array or command-line string
 	Cwd         string            `json:"cwd,omitempty"`
 	Env         map[string]string `json:"env,omitempty"`
 	StopOnEntry bool              `json:"stopOnEntry,omitempty"`
@@ -116,12 +119,16 @@ type PresentationConfig struct {
 
 // ResolutionContext provides context for variable resolution.
 type ResolutionContext struct {
-	WorkspaceFolder string            // Root folder of the workspace
-	CurrentFile     string            // Currently active file (for ${file} variables)
-	LineNumber      int               // Current line number (for ${lineNumber})
-	SelectedText    string            // Currently selected text (for ${selectedText})
-	InputValues     map[string]string // Pre-provided values for ${input:} variables
-	EnvOverrides    map[string]string // Override environment variables
+	WorkspaceFolder  string            // Root folder of the workspace
+	WorkspaceFolders map[string]string // Named folders of a multi-root workspace (for ${workspaceFolder:name})
+	CurrentFile      string            // Currently active file (for ${file} variables)
+	LineNumber       int               // Current line number (for ${lineNumber})
+	SelectedText     string            // Currently selected text (for ${selectedText})
+	InputValues      map[string]string // Pre-provided values for ${input:} variables
+	EnvOverrides     map[string]string // Override environment variables
+	Inputs           []InputConfig     // Input definitions from launch.json (for defaults and command inputs)
+	AllowCommands    bool              // Allow running command-type inputs that were not provided
+	Variables        map[string]string // Variables an adapter defines, such as js-debug's ${webRoot}
 }
 
 // UnmarshalJSON implements custom unmarshaling to capture unknown fields.
@@ -222,8 +229,10 @@ var TypeToLanguage = map[string]string{
 	"go": "go",
 
 	// JavaScript/TypeScript/Node.js
-	"node":     "javascript",
-	"pwa-node": "javascript",
+	"node":          "javascript",
+	"pwa-node":      "javascript",
+	"node2":         "javascript", // Legacy Node.js debugger
+	"node-terminal": "javascript", // Runs a command and debugs the Node.js processes it starts
 
 	// Browser debugging
 	"chrome":     "javascript",
@@ -232,20 +241,62 @@ var TypeToLanguage = map[string]string{
 	"pwa-msedge": "javascript",
 
 	// C/C++/Rust via LLDB
-	"lldb":     "c", // Generic LLDB
-	"lldb-dap": "c", // LLDB DAP server
-	"codelldb": "c", // CodeLLDB extension
+	"lldb":        "c", // Generic LLDB
+	"lldb-dap":    "c", // LLDB DAP server
+	"lldb-vscode": "c", // LLDB DAP server before it was renamed lldb-dap
+	"codelldb":    "c", // CodeLLDB extension
 
 	// C/C++/Rust via GDB
 	"gdb":    "c",   // Native GDB DAP (GDB 14.1+)
 	"cppdbg": "cpp", // Microsoft cpptools (GDB/LLDB via MI)
 
+	// Swift via the Swift toolchain's lldb-dap
+	"swift":      "swift",
+	"swift-lldb": "swift", // Swift extension before 2.0
+
+	// Elixir/Erlang via ElixirLS
+	"mix_task": "elixir",
+
+	// Generic native binary (Zig, Nim, Crystal, ...) via LLDB or GDB
+	"native": "native",
+
 	// Explicit language types
 	"c":    "c",
 	"cpp":  "cpp",
 	"rust": "rust",
 }
 
+// UnsupportedTypes maps VS Code debug types dap-mcp recognizes but can't run
+// to the reason why.
+var UnsupportedTypes = map[string]string{
+	"extensionHost":     "VS Code extension hosts can only be started by VS Code itself",
+	"pwa-extensionHost": "VS Code extension hosts can only be started by VS Code itself",
+	"cppvsdbg":          "the Visual Studio debugger has no standalone debug adapter; use cppdbg, lldb, or gdb",
+}
+
+// RecognizedTypes returns the VS Code debug types dap-mcp can run, sorted.
+func RecognizedTypes() []string {
+	types := make([]string, 0, len(TypeToLanguage))
+	for t := range TypeToLanguage {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// CheckType returns an error if dap-mcp can't run this configuration's type,
+// listing the types it recognizes.
+func (c *DebugConfiguration) CheckType() error {
+	if _, ok := TypeToLanguage[c.Type]; ok {
+		return nil
+	}
+	recognized := strings.Join(RecognizedTypes(), ", ")
+	if reason, ok := UnsupportedTypes[c.Type]; ok {
+		return fmt.Errorf("debug type %q is not supported: %s. Supported types: %s", c.Type, reason, recognized)
+	}
+	return fmt.Errorf("unknown debug type %q. Supported types: %s", c.Type, recognized)
+}
+
 // IsLaunchRequest returns true if this is a launch configuration (not attach).
 func (c *DebugConfiguration) IsLaunchRequest() bool {
 	return c.Request == "launch"
@@ -274,23 +325,25 @@ func (c *DebugConfiguration) GetLanguage() string {
 	return c.Type
 }
 
-// GetTarget returns the debug target type (node, chrome, edge) for browser configurations.
+// GetTarget returns the debug target type (node, chrome, edge, terminal) for JavaScript configurations.
 func (c *DebugConfiguration) GetTarget() string {
 	switch c.Type {
 	case "chrome", "pwa-chrome":
 		return "chrome"
 	case "msedge", "pwa-msedge":
 		return "edge"
-	case "node", "pwa-node":
+	case "node", "pwa-node", "node2":
 		return "node"
+	case "node-terminal":
+		return "terminal"
 	}
 	return ""
 }
 
-// IsNativeLanguage returns true if this configuration targets a native language (C, C++, Rust).
+// IsNativeLanguage returns true if this configuration targets a native language (C, C++, Rust, Swift, native).
 func (c *DebugConfiguration) IsNativeLanguage() bool {
 	switch c.Type {
-	case "lldb", "lldb-dap", "codelldb", "gdb", "cppdbg", "c", "cpp", "rust":
+	case "lldb", "lldb-dap", "lldb-vscode", "codelldb", "gdb", "cppdbg", "c", "cpp", "rust", "swift", "swift-lldb", "native":
 		return true
 	}
 	return false
@@ -299,7 +352,7 @@ func (c *DebugConfiguration) IsNativeLanguage() bool {
 // IsLLDBType returns true if this configuration uses LLDB-based debugging.
 func (c *DebugConfiguration) IsLLDBType() bool {
 	switch c.Type {
-	case "lldb", "lldb-dap", "codelldb":
+	case "lldb", "lldb-dap", "lldb-vscode", "codelldb":
 		return true
 	}
 	// cppdbg can use either GDB or LLDB based on MIMode
@@ -333,7 +386,7 @@ func (c *DebugConfiguration) GetNativeDebugger() string {
 	}
 	// For explicit language types without a specified debugger, prefer LLDB
 	switch c.Type {
-	case "c", "cpp", "rust":
+	case "c", "cpp", "rust", "swift", "swift-lldb", "native":
 		return "lldb"
 	}
 	return ""
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -15,14 +16,25 @@ import (
 // Variable pattern matches ${...} expressions
 var variablePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
 
-// ResolveVariables replaces all ${...} variables in the given text.
+// maxResolvedLength bounds the length of a string after variable resolution,
+// so that a command or setting producing huge output fails instead of being
+// copied into the launch arguments.
+const maxResolvedLength = 1 << 20
+
+// ResolveVariables replaces all ${...} variables in the given text. Resolution
+// is a single pass: ${...} in a variable's value is left as is.
 func ResolveVariables(text string, ctx *ResolutionContext) (string, error) {
 	if ctx == nil {
 		ctx = &ResolutionContext{}
 	}
 
 	var lastErr error
+	size := len(text)
 	result := variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if size > maxResolvedLength {
+			return match
+		}
+
 		// Extract the variable expression (without ${ and })
 		expr := match[2 : len(match)-1]
 
@@ -31,9 +43,13 @@ func ResolveVariables(text string, ctx *ResolutionContext) (string, error) {
 			lastErr = err
 			return match // Keep original if error
 		}
+		size += len(resolved) - len(match)
 		return resolved
 	})
 
+	if size > maxResolvedLength {
+		return text, fmt.Errorf("resolved value is longer than %d bytes", maxResolvedLength)
+	}
 	return result, lastErr
 }
 
@@ -47,6 +63,17 @@ func resolveVariable(expr string, ctx *ResolutionContext) (string, error) {
 	case expr == "workspaceFolderBasename":
 		return filepath.Base(ctx.WorkspaceFolder), nil
 
+	case strings.HasPrefix(expr, "workspaceFolder:"):
+		// ${workspaceFolder:NAME} - a named folder of a multi-root workspace
+		return namedWorkspaceFolder(strings.TrimPrefix(expr, "workspaceFolder:"), ctx)
+
+	case strings.HasPrefix(expr, "workspaceFolderBasename:"):
+		folder, err := namedWorkspaceFolder(strings.TrimPrefix(expr, "workspaceFolderBasename:"), ctx)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Base(folder), nil
+
 	case expr == "file":
 		return ctx.CurrentFile, nil
 
@@ -147,10 +174,34 @@ func resolveVariable(expr string, ctx *ResolutionContext) (string, error) {
 		return "", fmt.Errorf("missing input value for ${input:%s}", inputID)
 
 	default:
+		if val, ok := ctx.Variables[expr]; ok {
+			return val, nil
+		}
 		return "", fmt.Errorf("unknown variable: ${%s}", expr)
 	}
 }
 
+// namedWorkspaceFolder returns the path of a named workspace folder. As in VS
+// Code, a single-folder workspace can be referred to by its folder's name.
+func namedWorkspaceFolder(name string, ctx *ResolutionContext) (string, error) {
+	if path, ok := ctx.WorkspaceFolders[name]; ok {
+		return path, nil
+	}
+	if ctx.WorkspaceFolder != "" && filepath.Base(ctx.WorkspaceFolder) == name {
+		return ctx.WorkspaceFolder, nil
+	}
+
+	known := make([]string, 0, len(ctx.WorkspaceFolders))
+	for folder := range ctx.WorkspaceFolders {
+		known = append(known, folder)
+	}
+	sort.Strings(known)
+	if len(known) == 0 {
+		return "", fmt.Errorf("unknown workspace folder %q in ${workspaceFolder:%s}: no named workspace folders were provided", name, name)
+	}
+	return "", fmt.Errorf("unknown workspace folder %q in ${workspaceFolder:%s} (known folders: %s)", name, name, strings.Join(known, ", "))
+}
+
 // resolveConfigVariable attempts to read a VS Code setting.
 func resolveConfigVariable(settingID, workspaceFolder string) (string, error) {
 	if workspaceFolder == "" {
@@ -0,0 +1,431 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Reasons reported for unverified breakpoints
+const (
+	bpReasonInvalidCondition = "invalidCondition"
+	bpReasonNoCode           = "noCode"
+	bpReasonPending          = "pending"
+	bpReasonUnverified       = "unverified"
+)
+
+// conditionErrorMarkers are message fragments adapters use when a breakpoint
+// condition fails to parse or evaluate
+var conditionErrorMarkers = []string{
+	"condition", "syntax", "parse", "invalid expression", "could not evaluate",
+	"cannot evaluate", "undefined", "not defined", "compile", "unexpected token",
+}
+
+// noCodeMarkers are message fragments adapters use when a line has no executable code
+var noCodeMarkers = []string{
+	"no code", "no executable code", "could not find", "not found", "no line",
+	"invalid line", "no statement", "breakpoint moved",
+}
+
+// pendingMarkers are message fragments adapters use when the source is not loaded yet
+var pendingMarkers = []string{
+	"pending", "not yet loaded", "not loaded", "deferred",
+}
+
+// breakpointReason classifies why a breakpoint is unverified. The adapter's
+// message is the only signal DAP gives us, so this matches common wording.
+func breakpointReason(message, condition string) string {
+	msg := strings.ToLower(message)
+	containsAny := func(markers []string) bool {
+		for _, m := range markers {
+			if strings.Contains(msg, m) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case condition != "" && msg != "" && containsAny(conditionErrorMarkers):
+		return bpReasonInvalidCondition
+	case containsAny(pendingMarkers):
+		return bpReasonPending
+	case msg == "" || containsAny(noCodeMarkers):
+		return bpReasonNoCode
+	default:
+		return bpReasonUnverified
+	}
+}
+
+// rejectedBreakpoint records a conditional breakpoint the adapter refused
+type rejectedBreakpoint struct {
+	index   int
+	message string
+}
+
+// setBreakpointsIsolatingConditions is used after an adapter rejected a whole
+// setBreakpoints call that contained conditions. It probes each conditional
+// breakpoint on top of the unconditional ones to find the conditions the adapter
+// refuses, then sets everything that was accepted. The returned breakpoints
+// line up with the returned indexes into the original request.
+func setBreakpointsIsolatingConditions(client *internaldap.Client, source dap.Source, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, []int, []rejectedBreakpoint, error) {
+	var accepted []int
+	for i, bp := range breakpoints {
+		if bp.Condition == "" {
+			accepted = append(accepted, i)
+		}
+	}
+
+	subset := func(indexes []int) []dap.SourceBreakpoint {
+		bps := make([]dap.SourceBreakpoint, len(indexes))
+		for i, idx := range indexes {
+			bps[i] = breakpoints[idx]
+		}
+		return bps
+	}
+
+	// If even the unconditional breakpoints fail, conditions are not the problem
+	if _, err := client.SetBreakpoints(source, subset(accepted)); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var rejected []rejectedBreakpoint
+	for i, bp := range breakpoints {
+		if bp.Condition == "" {
+			continue
+		}
+		candidate := append(append([]int{}, accepted...), i)
+		if _, err := client.SetBreakpoints(source, subset(candidate)); err != nil {
+			rejected = append(rejected, rejectedBreakpoint{index: i, message: err.Error()})
+			continue
+		}
+		accepted = candidate
+	}
+
+	// Keep the request order so results can be merged back by index
+	ordered := make([]int, 0, len(accepted))
+	for i := range breakpoints {
+		for _, idx := range accepted {
+			if idx == i {
+				ordered = append(ordered, i)
+				break
+			}
+		}
+	}
+
+	bps, err := client.SetBreakpoints(source, subset(ordered))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return bps, ordered, rejected, nil
+}
+
+// sourceRoot returns the directory a session's relative source paths are
+// resolved against: the launch's cwd, else its workspace folder, else the
+// program's directory (or the program itself, for Go package directories)
+func sourceRoot(cwd, workspace, program string) string {
+	switch {
+	case cwd != "":
+		return cwd
+	case workspace != "":
+		return workspace
+	case filepath.IsAbs(program):
+		if info, err := os.Stat(program); err == nil && info.IsDir() {
+			return program
+		}
+		return filepath.Dir(program)
+	}
+	return ""
+}
+
+// resolveSourcePath makes a relative source path absolute against the
+// session's source root, or the server's working directory without one.
+// Absolute paths and URLs (such as webpack:// sources) are left as they are.
+func resolveSourcePath(session *internaldap.Session, path string) string {
+	if path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+	if root := session.SourceRoot(); root != "" {
+		return filepath.Join(root, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// recordAdapterSourcePath records the path an adapter reported for a source
+// its breakpoints were set in, if it differs from the path that was sent,
+// and returns it ("" if it doesn't differ)
+func recordAdapterSourcePath(session *internaldap.Session, path string, bps []dap.Breakpoint) string {
+	for _, bp := range bps {
+		if bp.Source != nil && bp.Source.Path != "" && bp.Source.Path != path {
+			session.SetAdapterSourcePath(path, bp.Source.Path)
+			return bp.Source.Path
+		}
+	}
+	return ""
+}
+
+// breakpointReport describes the adapter's current breakpoints, merged with
+// the conditions and lines that were requested for them and the paths the
+// adapter reported for their sources
+func breakpointReport(tracked map[string][]dap.Breakpoint, requested map[string][]dap.SourceBreakpoint, adapterPaths map[string]string) map[string]interface{} {
+	paths := make([]string, 0, len(tracked))
+	for path := range tracked {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	result := make([]map[string]interface{}, 0)
+	verified := 0
+	for _, path := range paths {
+		bps := tracked[path]
+		// Requested breakpoints line up with the adapter's answer by position
+		reqs := requested[path]
+		if len(reqs) != len(bps) {
+			reqs = nil
+		}
+		for i, bp := range bps {
+			entry := map[string]interface{}{
+				"path":     path,
+				"id":       bp.Id,
+				"line":     bp.Line,
+				"verified": bp.Verified,
+			}
+			if bp.Column > 0 {
+				entry["column"] = bp.Column
+			}
+			if adapterPath := adapterPaths[path]; adapterPath != "" {
+				entry["adapterPath"] = adapterPath
+			}
+			if bp.Message != "" {
+				entry["message"] = bp.Message
+			}
+			condition := ""
+			if reqs != nil {
+				condition = reqs[i].Condition
+				if reqs[i].Line != bp.Line {
+					entry["requestedLine"] = reqs[i].Line
+				}
+				if reqs[i].Column > 0 && reqs[i].Column != bp.Column {
+					entry["requestedColumn"] = reqs[i].Column
+				}
+				if condition != "" {
+					entry["condition"] = condition
+				}
+				if reqs[i].HitCondition != "" {
+					entry["hitCondition"] = reqs[i].HitCondition
+				}
+				if reqs[i].LogMessage != "" {
+					entry["logMessage"] = reqs[i].LogMessage
+				}
+			}
+			if bp.Verified {
+				verified++
+			} else {
+				entry["reason"] = breakpointReason(bp.Message, condition)
+			}
+			result = append(result, entry)
+		}
+	}
+
+	return map[string]interface{}{
+		"breakpoints": result,
+		"verified":    verified,
+		"unverified":  len(result) - verified,
+	}
+}
+
+// breakpointVerifyTimeout bounds how long debug_launch waits for breakpoints
+// to be verified with verifyBreakpoints
+const breakpointVerifyTimeout = 2 * time.Second
+
+// launchBreakpoints are the breakpoints debug_launch sets during the
+// configuration phase, before the program runs
+type launchBreakpoints struct {
+	source   map[string][]dap.SourceBreakpoint
+	function []dap.FunctionBreakpoint
+	regex    []string
+}
+
+// parseLaunchBreakpointParams reads debug_launch's breakpoints,
+// functionBreakpoints, and regexBreakpoints parameters
+func (s *Server) parseLaunchBreakpointParams(request mcp.CallToolRequest, adapter adapters.Adapter) (*launchBreakpoints, error) {
+	bps := &launchBreakpoints{}
+	if bpsJSON, err := request.RequireString("breakpoints"); err == nil && bpsJSON != "" {
+		if bps.source, err = parseLaunchBreakpoints(bpsJSON); err != nil {
+			return nil, err
+		}
+	}
+	if functionsJSON, err := request.RequireString("functionBreakpoints"); err == nil && functionsJSON != "" {
+		if bps.function, err = parseFunctionBreakpoints(functionsJSON); err != nil {
+			return nil, errors.InvalidJSON("functionBreakpoints", err, `["main", {"name": "parse", "condition": "n > 1"}]`)
+		}
+	}
+	if patternsJSON, err := request.RequireString("regexBreakpoints"); err == nil && patternsJSON != "" {
+		if err := json.Unmarshal([]byte(patternsJSON), &bps.regex); err != nil {
+			return nil, errors.InvalidJSON("regexBreakpoints", err, `["^Foo::bar"]`)
+		}
+		if len(bps.regex) > 0 && nativeDebugger(adapter) == "" {
+			return nil, errors.InvalidParameter("regexBreakpoints", patternsJSON, "a GDB or LLDB session")
+		}
+		// They are set with debugger commands in the repl context
+		if len(bps.regex) > 0 && s.config.EvaluatesReadOnly() {
+			return nil, errors.PermissionDenied("repl evaluation", string(s.config.Mode))
+		}
+		for _, pattern := range bps.regex {
+			if err := checkRegexPattern(pattern); err != nil {
+				return nil, errors.InvalidParameter("regexBreakpoints", pattern, err.Error())
+			}
+		}
+	}
+	return bps, nil
+}
+
+// record stores the breakpoints on the session for the launch sequence to
+// set, with relative paths resolved against the session's source root
+func (b *launchBreakpoints) record(session *internaldap.Session) {
+	for path, bps := range b.source {
+		session.SetSourceBreakpoints(resolveSourcePath(session, path), bps)
+	}
+	if len(b.function) > 0 {
+		session.SetFunctionBreakpoints(b.function)
+	}
+	for _, pattern := range b.regex {
+		session.AddRegexBreakpoint(pattern)
+	}
+}
+
+// parseLaunchBreakpoints parses debug_launch's breakpoints parameter into
+// source breakpoints grouped by path
+func parseLaunchBreakpoints(bpsJSON string) (map[string][]dap.SourceBreakpoint, error) {
+	var bpRequests []struct {
+		Path         string `json:"path"`
+		Line         int    `json:"line"`
+		Column       int    `json:"column,omitempty"`
+		Condition    string `json:"condition,omitempty"`
+		HitCondition string `json:"hitCondition,omitempty"`
+		LogMessage   string `json:"logMessage,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(bpsJSON), &bpRequests); err != nil {
+		return nil, errors.InvalidJSON("breakpoints", err, `[{"path": "/src/main.go", "line": 10}, {"path": "/src/util.go", "line": 20, "condition": "x > 5"}]`)
+	}
+
+	byPath := make(map[string][]dap.SourceBreakpoint)
+	for _, bp := range bpRequests {
+		if bp.Path == "" || bp.Line < 1 || bp.Column < 0 {
+			return nil, errors.InvalidParameter("breakpoints", bp, "each breakpoint needs a path and a line of 1 or more, and an optional column of 1 or more")
+		}
+		byPath[bp.Path] = append(byPath[bp.Path], dap.SourceBreakpoint{
+			Line:         bp.Line,
+			Column:       bp.Column,
+			Condition:    bp.Condition,
+			HitCondition: bp.HitCondition,
+			LogMessage:   bp.LogMessage,
+		})
+	}
+	return byPath, nil
+}
+
+// waitForBreakpointVerification waits until the adapter has verified every
+// tracked breakpoint (through breakpoint events) or the timeout passes
+func waitForBreakpointVerification(client *internaldap.Client, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := false
+		for _, bps := range client.Breakpoints() {
+			for _, bp := range bps {
+				if !bp.Verified {
+					pending = true
+				}
+			}
+		}
+		if !pending || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// numberLiteral matches the decimal and hex number literals every supported
+// language accepts, unlike e.g. NaN, Inf, or 1_000
+var numberLiteral = regexp.MustCompile(`^-?(?:0[xX][0-9a-fA-F]+|(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?)$`)
+
+// conditionLiteral converts an evaluated value into a literal that can be
+// compared with == in a breakpoint condition for the session's language. It
+// only handles scalars (numbers, booleans, strings, null-like values).
+func conditionLiteral(lang types.Language, value, valueType string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", false
+	}
+
+	if numberLiteral.MatchString(value) {
+		return value, true
+	}
+
+	switch value {
+	case "true", "false":
+		if lang != types.LanguagePython {
+			return value, true
+		}
+	case "True", "False", "None":
+		if lang == types.LanguagePython {
+			return value, true
+		}
+	case "null", "undefined":
+		if lang == types.LanguageJavaScript || lang == types.LanguageTypeScript {
+			return value, true
+		}
+	case "nil":
+		if lang == types.LanguageGo || lang == types.LanguageElixir {
+			return value, true
+		}
+	}
+
+	// C and C++ strings are char pointers and can't be compared with ==
+	if lang == types.LanguageC || lang == types.LanguageCpp {
+		return "", false
+	}
+
+	quoted := len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]
+	switch {
+	case quoted && value[0] == '"':
+		return value, true
+	case quoted && (lang == types.LanguagePython || lang == types.LanguageJavaScript || lang == types.LanguageTypeScript):
+		return value, true
+	case quoted:
+		unquoted := value[1 : len(value)-1]
+		if strings.ContainsAny(unquoted, "\\\"") {
+			return "", false
+		}
+		return strconv.Quote(unquoted), true
+	case isStringType(valueType):
+		return strconv.Quote(value), true
+	}
+
+	return "", false
+}
+
+// isStringType reports whether an adapter-reported type names a string type
+func isStringType(valueType string) bool {
+	switch strings.ToLower(strings.TrimSpace(valueType)) {
+	case "str", "string", "&str", "bitstring":
+		return true
+	}
+	return false
+}
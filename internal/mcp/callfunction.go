@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// delveCallUnsupported matches the errors Delve returns when the program or
+// backend can't have calls injected, as opposed to a call that failed
+var delveCallUnsupported = []string{
+	"function calls not supported",
+	"does not support function calls",
+}
+
+// handleDebugCallFunction calls a function in a Go debuggee with Delve's
+// "call" command and returns its result. Calls run the debuggee's code, so
+// they need allowExecute.
+func (s *Server) handleDebugCallFunction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.config.CanExecute() {
+		return mcp.NewToolResultError(errors.PermissionDenied("function call", string(s.config.Mode)).Error()), nil
+	}
+
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if session.Language != types.LanguageGo {
+		return mcp.NewToolResultError(errors.FunctionCallUnsupported(string(session.Language), "").Error()), nil
+	}
+
+	function, err := request.RequireString("function")
+	if err != nil || strings.TrimSpace(function) == "" {
+		return mcp.NewToolResultError(errors.MissingParameter("function",
+			"The function to call, e.g. \"strings.ToUpper\" or a method value such as \"srv.Addr\".").Error()), nil
+	}
+
+	var args []string
+	if argsJSON, _ := request.RequireString("args"); argsJSON != "" {
+		if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("args", err, `["name", "\"literal\"", "42"]`).Error()), nil
+		}
+	}
+	expression := "call " + strings.TrimSpace(function) + "(" + strings.Join(args, ", ") + ")"
+
+	// Read-only evaluation refuses calls even with allowExecute
+	evalContext, _, debugErr := s.evaluationMode(session, expression, "repl")
+	if debugErr != nil {
+		return mcp.NewToolResultError(debugErr.Error()), nil
+	}
+
+	// Delve injects the call into the goroutine of the frame, by default the
+	// focused frame
+	frameID, ok := requestFrame(request, session, client)
+	if !ok {
+		return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+	}
+
+	result, err := client.Evaluate(expression, frameID, evalContext)
+	if err != nil {
+		for _, unsupported := range delveCallUnsupported {
+			if strings.Contains(err.Error(), unsupported) {
+				return mcp.NewToolResultError(errors.FunctionCallUnsupported(string(session.Language), err.Error()).Error()), nil
+			}
+		}
+		return mcp.NewToolResultError(errors.EvaluationFailed(expression, err).Error()), nil
+	}
+
+	response := map[string]interface{}{
+		"sessionId":          session.ID,
+		"expression":         expression,
+		"frameId":            frameID,
+		"type":               result.Type,
+		"variablesReference": result.VariablesReference,
+	}
+	s.setValue(response, "result", result.Result)
+	addChildCounts(response, result.IndexedVariables, result.NamedVariables)
+	if result.Result == "" && result.VariablesReference == 0 {
+		response["note"] = "The function returned no values."
+	}
+	return jsonResult(response)
+}
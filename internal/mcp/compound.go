@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/internal/launchconfig"
+)
+
+// What a compound launch does with the sessions that started when one of its
+// configurations fails
+const (
+	// partialFailureRollback terminates them, so the compound starts whole or not at all
+	partialFailureRollback = "rollback"
+	// partialFailureKeep leaves them running and launches the remaining configurations
+	partialFailureKeep = "keep"
+)
+
+// handleCompoundLaunch launches each configuration of a launch.json compound
+// in order, as debug_launch with configName would, and reports the session or
+// error of every configuration. With onPartialFailure "rollback" (the default)
+// the first failure terminates the sessions already started; with "keep" they
+// keep running and the remaining configurations are still launched.
+func (s *Server) handleCompoundLaunch(ctx context.Context, request mcp.CallToolRequest, compoundName string) (*mcp.CallToolResult, error) {
+	onPartialFailure := partialFailureRollback
+	if v, err := request.RequireString("onPartialFailure"); err == nil && v != "" {
+		onPartialFailure = v
+	}
+	if onPartialFailure != partialFailureRollback && onPartialFailure != partialFailureKeep {
+		return mcp.NewToolResultError(errors.InvalidParameter("onPartialFailure", onPartialFailure, "'rollback' or 'keep'").Error()), nil
+	}
+
+	workspace, _ := request.RequireString("workspace")
+	configPath, _ := request.RequireString("configPath")
+
+	var lj *launchconfig.LaunchJSON
+	var err error
+	if configPath != "" {
+		lj, err = launchconfig.LoadFromPath(configPath)
+	} else if workspace != "" {
+		lj, configPath, err = launchconfig.LoadAndDiscover(workspace)
+	} else {
+		return mcp.NewToolResultError("workspace or configPath is required when using compoundName"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to load launch.json: %v", err)), nil
+	}
+
+	compound, err := launchconfig.FindCompound(lj, compoundName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%v (compounds: %s)", err, strings.Join(launchconfig.ListCompoundNames(lj), ", "))), nil
+	}
+	if len(compound.Configurations) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("compound %q lists no configurations", compoundName)), nil
+	}
+
+	var launched []map[string]interface{}
+	var failed []map[string]interface{}
+	var notLaunched []string
+	for i, configName := range compound.Configurations {
+		if len(failed) > 0 && onPartialFailure == partialFailureRollback {
+			notLaunched = compound.Configurations[i:]
+			break
+		}
+
+		// Each configuration launches like debug_launch with its configName,
+		// sharing the compound's workspace, inputs, and breakpoints
+		args := make(map[string]interface{}, len(request.GetArguments())+1)
+		for k, v := range request.GetArguments() {
+			args[k] = v
+		}
+		delete(args, "compoundName")
+		delete(args, "onPartialFailure")
+		args["configName"] = configName
+		args["configPath"] = configPath
+		var configRequest mcp.CallToolRequest
+		configRequest.Params.Name = request.Params.Name
+		configRequest.Params.Arguments = args
+
+		result, err := s.handleConfigBasedLaunch(ctx, configRequest, configName)
+		if err != nil {
+			return nil, err
+		}
+		text := toolResultText(result)
+		if result.IsError {
+			failed = append(failed, map[string]interface{}{"configName": configName, "error": text})
+			continue
+		}
+		var session map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &session); err != nil {
+			session = map[string]interface{}{"configName": configName, "result": text}
+		}
+		launched = append(launched, session)
+	}
+
+	report := map[string]interface{}{
+		"compound":         compoundName,
+		"onPartialFailure": onPartialFailure,
+	}
+	if compound.PreLaunchTask != "" {
+		report["note"] = fmt.Sprintf("The compound's preLaunchTask %q was not run; the configurations' own preLaunchTasks were.", compound.PreLaunchTask)
+	}
+
+	if len(failed) == 0 {
+		s.trackCompound(compound, launched)
+		report["status"] = "launched"
+		report["sessions"] = launched
+		return jsonResult(report)
+	}
+
+	report["failed"] = failed
+	if len(notLaunched) > 0 {
+		report["notLaunched"] = notLaunched
+	}
+	if onPartialFailure == partialFailureRollback {
+		var rolledBack []string
+		for _, session := range launched {
+			if id, ok := session["sessionId"].(string); ok {
+				s.stopWatchingForRestart(id)
+				_ = s.sessionManager.TerminateSession(id, true)
+				rolledBack = append(rolledBack, id)
+			}
+		}
+		report["status"] = "rolledBack"
+		report["rolledBack"] = rolledBack
+		return compoundFailed(report, "Every session the compound started was terminated; pass onPartialFailure \"keep\" to keep them running.")
+	}
+
+	report["sessions"] = launched
+	if len(launched) == 0 {
+		report["status"] = "failed"
+		return compoundFailed(report, "No configuration of the compound launched.")
+	}
+	s.trackCompound(compound, launched)
+	report["status"] = "partial"
+	return jsonResult(report)
+}
+
+// trackCompound registers the sessions a compound launched with the session
+// manager, so that with the compound's stopAll ending one ends them all
+func (s *Server) trackCompound(compound *launchconfig.CompoundConfig, launched []map[string]interface{}) {
+	var ids []string
+	for _, session := range launched {
+		if id, ok := session["sessionId"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	s.sessionManager.TrackCompoundSession(compound.Name, ids, compound.StopAll)
+}
+
+// compoundFailed returns a compound launch report as an error result
+func compoundFailed(report map[string]interface{}, summary string) (*mcp.CallToolResult, error) {
+	var failures []string
+	for _, f := range report["failed"].([]map[string]interface{}) {
+		failures = append(failures, fmt.Sprintf("%s: %s", f["configName"], f["error"]))
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("compound %q failed to launch %s. %s\n%s",
+		report["compound"], strings.Join(failures, "; "), summary, data)), nil
+}
+
+// toolResultText returns the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.TextContent); ok {
+			text += c.Text
+		}
+	}
+	return text
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// sessionLifecycleCommands are DAP requests debug_custom_request refuses to
+// send, because the session's own tools must manage them
+var sessionLifecycleCommands = []string{"initialize", "launch", "attach", "disconnect"}
+
+// handleDebugCustomRequest sends an adapter-specific DAP request, such as
+// Delve's dlvCommand, and returns the raw response body
+func (s *Server) handleDebugCustomRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.config.CanExecute() {
+		return mcp.NewToolResultError(errors.PermissionDenied("execute", string(s.config.Mode)).Error()), nil
+	}
+
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	command, err := request.RequireString("command")
+	if err != nil || command == "" {
+		return mcp.NewToolResultError(errors.MissingParameter("command",
+			"The DAP command to send, e.g. 'dlvCommand' for Delve. Check the adapter's documentation for its custom requests.").Error()), nil
+	}
+	for _, lifecycle := range sessionLifecycleCommands {
+		if command == lifecycle {
+			return mcp.NewToolResultError(errors.InvalidParameter("command", command,
+				"a command other than "+strings.Join(sessionLifecycleCommands, ", ")+" (use debug_launch, debug_attach, or debug_disconnect)").Error()), nil
+		}
+	}
+
+	var args json.RawMessage
+	if argsJSON, err := request.RequireString("arguments"); err == nil && argsJSON != "" {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(argsJSON), &obj); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("arguments", err, `{"command": "goroutines"}`).Error()), nil
+		}
+		args = json.RawMessage(argsJSON)
+	}
+
+	body, err := client.CustomRequest(command, args)
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, fmt.Sprintf("custom request %q failed: %v", command, err),
+			"Check the command name and arguments against the adapter's documentation. debug_adapter_log may show why the adapter rejected it.", err).Error()), nil
+	}
+
+	return jsonResult(map[string]interface{}{
+		"sessionId": session.ID,
+		"command":   command,
+		"body":      body,
+	})
+}
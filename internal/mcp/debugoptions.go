@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// debugpyJustMyCodeDefault is debugpy's justMyCode when a launch doesn't set it
+const debugpyJustMyCodeDefault = true
+
+// handleDebugSetDebugOptions changes a session's debug options. debugpy only
+// reads justMyCode from the launch or attach request, so the option is kept on
+// the session and passed to every later launch of the program.
+func (s *Server) handleDebugSetDebugOptions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, _, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if session.Language != types.LanguagePython {
+		return mcp.NewToolResultError(errors.InvalidParameter("sessionId", session.ID,
+			"a Python session (justMyCode is a debugpy option; other adapters use skipFiles or step filters in launch.json)").Error()), nil
+	}
+
+	justMyCode, ok := request.GetArguments()["justMyCode"].(bool)
+	if !ok {
+		return mcp.NewToolResultError(errors.MissingParameter("justMyCode",
+			"Set justMyCode=false to step into library code, or true to stay in your own code.").Error()), nil
+	}
+
+	running, ok := session.LaunchedDebugOptions()["justMyCode"].(bool)
+	if !ok {
+		running = debugpyJustMyCodeDefault
+	}
+	session.SetDebugOption("justMyCode", justMyCode)
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"options":   map[string]interface{}{"justMyCode": justMyCode},
+		"running":   map[string]interface{}{"justMyCode": running},
+	}
+	if running != justMyCode {
+		result["pending"] = true
+		result["note"] = "debugpy reads justMyCode when the program launches: the running program keeps the previous setting until the session relaunches it (restartOnExit relaunches use the new one). To apply it now, disconnect and call debug_launch with justMyCode."
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,353 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// snapshotState is a stored snapshot's digest parsed back into threads,
+// stacks, and variables
+type snapshotState struct {
+	names   map[int]string
+	stopped map[int]string          // Stop reason of threads with a stopped event
+	stacks  map[int][]snapshotFrame // Innermost frame first
+	// Variables by thread and frame index, keyed "<scope>/<name>", with
+	// values as "<type>=<value>"
+	variables map[int]map[int]map[string]string
+}
+
+// snapshotFrame is a frame of a stored stack
+type snapshotFrame struct {
+	Name string
+	Line int
+}
+
+// variableChange is a variable that differs between two snapshots
+type variableChange struct {
+	Frame    int    `json:"frame"` // Frame index in the later snapshot
+	Function string `json:"function"`
+	Scope    string `json:"scope"`
+	Name     string `json:"name"`
+	Change   string `json:"change"` // "changed", "added", or "removed"
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Type     string `json:"type,omitempty"`
+	FromType string `json:"fromType,omitempty"` // Set when the type changed
+}
+
+// handleDebugDiff compares two stored snapshots and returns only what
+// differs: thread changes, pushed, popped, and moved frames, and variables
+func (s *Server) handleDebugDiff(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, _, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	stored := session.Snapshots()
+	if len(stored) < 2 {
+		return mcp.NewToolResultError(errors.NotEnoughSnapshots(len(stored)).Error()), nil
+	}
+
+	// Without IDs, compare the two latest snapshots
+	from, to := &stored[len(stored)-2], &stored[len(stored)-1]
+	if id, err := request.RequireString("from"); err == nil && id != "" {
+		if from = findSnapshot(stored, id); from == nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("from", id, storedSnapshotIDs(stored)).Error()), nil
+		}
+	}
+	if id, err := request.RequireString("to"); err == nil && id != "" {
+		if to = findSnapshot(stored, id); to == nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("to", id, storedSnapshotIDs(stored)).Error()), nil
+		}
+	}
+
+	before, after := parseSnapshotState(from.State), parseSnapshotState(to.State)
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"from":      from.ID,
+		"to":        to.ID,
+	}
+
+	// A snapshot of some threads says nothing about the others
+	partial := from.Partial || to.Partial
+	if partial {
+		result["note"] = "One of the snapshots captured only some threads, so only threads in both are compared."
+	} else {
+		if added := threadList(after, before); len(added) > 0 {
+			result["threadsAdded"] = added
+		}
+		if removed := threadList(before, after); len(removed) > 0 {
+			result["threadsRemoved"] = removed
+		}
+	}
+
+	var threads []map[string]interface{}
+	for _, tid := range sortedThreadIDs(after.names) {
+		if _, ok := before.names[tid]; !ok {
+			continue
+		}
+		if changes := diffThread(tid, before, after); changes != nil {
+			threads = append(threads, changes)
+		}
+	}
+	if len(threads) > 0 {
+		result["threads"] = threads
+	}
+	if len(threads) == 0 && result["threadsAdded"] == nil && result["threadsRemoved"] == nil {
+		result["unchanged"] = true
+	}
+	return jsonResult(result)
+}
+
+// findSnapshot returns the stored snapshot with an ID, or nil
+func findSnapshot(stored []internaldap.StoredSnapshot, id string) *internaldap.StoredSnapshot {
+	for i := range stored {
+		if stored[i].ID == id {
+			return &stored[i]
+		}
+	}
+	return nil
+}
+
+// storedSnapshotIDs describes the IDs a snapshot parameter accepts
+func storedSnapshotIDs(stored []internaldap.StoredSnapshot) string {
+	ids := make([]string, len(stored))
+	for i, snap := range stored {
+		ids[i] = snap.ID
+	}
+	return fmt.Sprintf("one of the session's last %d snapshots: %s", internaldap.MaxStoredSnapshots, strings.Join(ids, ", "))
+}
+
+// parseSnapshotState parses a snapshot digest (see snapshotDiff)
+func parseSnapshotState(state map[string]string) *snapshotState {
+	parsed := &snapshotState{
+		names:     make(map[int]string),
+		stopped:   make(map[int]string),
+		stacks:    make(map[int][]snapshotFrame),
+		variables: make(map[int]map[int]map[string]string),
+	}
+	for key, value := range state {
+		parts := strings.SplitN(key, "/", 4)
+		tid, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		switch {
+		case len(parts) == 1:
+			parsed.names[tid] = value
+		case len(parts) == 2 && parts[1] == "stack":
+			parsed.stacks[tid] = parseStackDigest(value)
+		case len(parts) == 2 && parts[1] == "stopped":
+			parsed.stopped[tid] = value
+		case len(parts) == 4:
+			frame, err := strconv.Atoi(parts[1])
+			if err != nil {
+				continue
+			}
+			if parsed.variables[tid] == nil {
+				parsed.variables[tid] = make(map[int]map[string]string)
+			}
+			if parsed.variables[tid][frame] == nil {
+				parsed.variables[tid][frame] = make(map[string]string)
+			}
+			parsed.variables[tid][frame][parts[2]+"/"+parts[3]] = value
+		}
+	}
+	return parsed
+}
+
+// parseStackDigest parses a stack digest of name@line entries
+func parseStackDigest(value string) []snapshotFrame {
+	if value == "" {
+		return nil
+	}
+	entries := strings.Split(value, ";")
+	frames := make([]snapshotFrame, len(entries))
+	for i, entry := range entries {
+		name, line := entry, ""
+		if at := strings.LastIndex(entry, "@"); at >= 0 {
+			name, line = entry[:at], entry[at+1:]
+		}
+		frames[i].Name = name
+		frames[i].Line, _ = strconv.Atoi(line)
+	}
+	return frames
+}
+
+// threadList lists the threads of a that b lacks
+func threadList(a, b *snapshotState) []map[string]interface{} {
+	var list []map[string]interface{}
+	for _, tid := range sortedThreadIDs(a.names) {
+		if _, ok := b.names[tid]; !ok {
+			list = append(list, map[string]interface{}{"id": tid, "name": a.names[tid]})
+		}
+	}
+	return list
+}
+
+// sortedThreadIDs returns a snapshot's thread IDs in order
+func sortedThreadIDs(names map[int]string) []int {
+	ids := make([]int, 0, len(names))
+	for tid := range names {
+		ids = append(ids, tid)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// diffThread returns a thread's changes between two snapshots, or nil if
+// there are none
+func diffThread(tid int, before, after *snapshotState) map[string]interface{} {
+	changes := map[string]interface{}{
+		"threadId": tid,
+		"name":     after.names[tid],
+	}
+	changed := false
+
+	if before.names[tid] != after.names[tid] {
+		changes["previousName"] = before.names[tid]
+		changed = true
+	}
+	if before.stopped[tid] != after.stopped[tid] {
+		changes["stopReason"] = map[string]string{"from": before.stopped[tid], "to": after.stopped[tid]}
+		changed = true
+	}
+
+	oldStack, newStack := before.stacks[tid], after.stacks[tid]
+	matches := matchFrames(oldStack, newStack)
+
+	var pushed, popped, moved []map[string]interface{}
+	matchedOld := make(map[int]bool, len(matches))
+	matchedNew := make(map[int]bool, len(matches))
+	for _, m := range matches {
+		matchedOld[m[0]], matchedNew[m[1]] = true, true
+		if oldStack[m[0]].Line != newStack[m[1]].Line {
+			moved = append(moved, map[string]interface{}{
+				"frame":    m[1],
+				"name":     newStack[m[1]].Name,
+				"fromLine": oldStack[m[0]].Line,
+				"toLine":   newStack[m[1]].Line,
+			})
+		}
+	}
+	for i, f := range newStack {
+		if !matchedNew[i] {
+			pushed = append(pushed, map[string]interface{}{"frame": i, "name": f.Name, "line": f.Line})
+		}
+	}
+	for i, f := range oldStack {
+		if !matchedOld[i] {
+			popped = append(popped, map[string]interface{}{"frame": i, "name": f.Name, "line": f.Line})
+		}
+	}
+	if len(pushed) > 0 {
+		changes["framesAdded"] = pushed
+	}
+	if len(popped) > 0 {
+		changes["framesRemoved"] = popped
+	}
+	if len(moved) > 0 {
+		changes["framesMoved"] = moved
+	}
+	changed = changed || len(pushed) > 0 || len(popped) > 0 || len(moved) > 0
+
+	// Variables are compared within frames that are the same call in both
+	var vars []variableChange
+	for _, m := range matches {
+		vars = append(vars, diffFrameVariables(m[1], newStack[m[1]].Name,
+			before.variables[tid][m[0]], after.variables[tid][m[1]])...)
+	}
+	if len(vars) > 0 {
+		changes["variables"] = vars
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return changes
+}
+
+// matchFrames pairs the frames of two stacks that are the same call, as the
+// longest common subsequence of function names. Each pair holds the frame's
+// index in the old stack, then in the new one.
+func matchFrames(oldStack, newStack []snapshotFrame) [][2]int {
+	// lengths[i][j] is the LCS length of oldStack[i:] and newStack[j:]
+	lengths := make([][]int, len(oldStack)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newStack)+1)
+	}
+	for i := len(oldStack) - 1; i >= 0; i-- {
+		for j := len(newStack) - 1; j >= 0; j-- {
+			if oldStack[i].Name == newStack[j].Name {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := 0, 0; i < len(oldStack) && j < len(newStack); {
+		switch {
+		case oldStack[i].Name == newStack[j].Name:
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+// diffFrameVariables compares the variables of one frame in two snapshots
+func diffFrameVariables(frame int, function string, before, after map[string]string) []variableChange {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []variableChange
+	for _, key := range keys {
+		oldValue, inBefore := before[key]
+		newValue, inAfter := after[key]
+		if inBefore && inAfter && oldValue == newValue {
+			continue
+		}
+
+		scope, name, _ := strings.Cut(key, "/")
+		change := variableChange{Frame: frame, Function: function, Scope: scope, Name: name}
+		oldType, oldVal, _ := strings.Cut(oldValue, "=")
+		newType, newVal, _ := strings.Cut(newValue, "=")
+		switch {
+		case !inBefore:
+			change.Change, change.To, change.Type = "added", newVal, newType
+		case !inAfter:
+			change.Change, change.From, change.Type = "removed", oldVal, oldType
+		default:
+			change.Change, change.From, change.To, change.Type = "changed", oldVal, newVal, newType
+			if oldType != newType {
+				change.FromType = oldType
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/go-dap"
+
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// entryStopTimeout is how long a launch waits for the program to stop at entry
+const entryStopTimeout = 5 * time.Second
+
+// entryFunction is the function an entry breakpoint is set on for native
+// programs whose adapter ignores stopOnEntry
+const entryFunction = "main"
+
+// entryStop is the outcome of stopOnEntry for a launch
+type entryStop struct {
+	// stopped is set when the program is paused at entry, or at whatever
+	// paused it first (see reason)
+	stopped *internaldap.StoppedInfo
+	// note explains why a requested stop at entry did not happen
+	note string
+	// frame is where the program paused, once run to the adapter's entry function
+	frame *dap.StackFrame
+	// reason is set when the program paused somewhere other than at entry
+	reason string
+}
+
+// apply adds the entry stop, if any, to a launch result
+func (e *entryStop) apply(result map[string]interface{}) {
+	if e == nil {
+		return
+	}
+	if e.stopped != nil {
+		result["status"] = "stopped"
+		result["reason"] = "entry"
+		if e.reason != "" {
+			result["reason"] = e.reason
+		} else {
+			result["stoppedAtEntry"] = true
+		}
+		result["threadId"] = e.stopped.ThreadID
+	}
+	if e.frame != nil {
+		frame := map[string]interface{}{
+			"name": e.frame.Name,
+			"line": e.frame.Line,
+		}
+		if e.frame.Source != nil {
+			frame["path"] = e.frame.Source.Path
+		}
+		result["entryFrame"] = frame
+	}
+	if e.note != "" {
+		result["entryNote"] = e.note
+	}
+}
+
+// launchedStatus returns the status of a session that just launched
+func launchedStatus(entry *entryStop) types.SessionStatus {
+	if entry != nil && entry.stopped != nil {
+		return types.SessionStatusStopped
+	}
+	return types.SessionStatusRunning
+}
+
+// setEntryBreakpoint stands in for stopOnEntry on adapters that ignore it by
+// breaking on main, alongside the session's own function breakpoints. It
+// returns false for programs without a main function to break on, such as
+// pages in a browser.
+func setEntryBreakpoint(client *internaldap.Client, adapter adapters.Adapter, functionBreakpoints []dap.FunctionBreakpoint) bool {
+	if nativeDebugger(adapter) == "" {
+		return false
+	}
+	return breakOnFunction(client, entryFunction, functionBreakpoints)
+}
+
+// breakOnFunction sets an entry breakpoint on a function, alongside the
+// session's own function breakpoints
+func breakOnFunction(client *internaldap.Client, function string, functionBreakpoints []dap.FunctionBreakpoint) bool {
+	breakpoints := append(functionBreakpoints, dap.FunctionBreakpoint{Name: function})
+	if _, err := client.SetFunctionBreakpoints(breakpoints); err != nil {
+		log.Printf("Warning: failed to set entry breakpoint on %s: %v", function, err)
+		return false
+	}
+	return true
+}
+
+// clearEntryBreakpoint removes an entry breakpoint so later calls to the
+// function don't stop, keeping the session's function breakpoints
+func clearEntryBreakpoint(client *internaldap.Client, function string, functionBreakpoints []dap.FunctionBreakpoint) {
+	if _, err := client.SetFunctionBreakpoints(functionBreakpoints); err != nil {
+		log.Printf("Warning: failed to clear entry breakpoint on %s: %v", function, err)
+	}
+}
+
+// runToEntryFunction continues a program paused at the adapter's entry stop
+// until it reaches the entry function, so stopOnEntry pauses at the start of
+// the program's own code. The stop it returns is wherever the program paused,
+// which is before the function if a breakpoint was hit on the way.
+func runToEntryFunction(client *internaldap.Client, stopped *internaldap.StoppedInfo, function string) (*internaldap.StoppedInfo, *dap.StackFrame, error) {
+	if frame := topFrame(client, stopped.ThreadID); frame != nil && frame.Name == function {
+		return stopped, frame, nil
+	}
+	next, err := client.ContinueAndWait(stopped.ThreadID, entryStopTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	return next, topFrame(client, next.ThreadID), nil
+}
+
+// topFrame returns a thread's innermost stack frame, or nil if it is unavailable
+func topFrame(client *internaldap.Client, threadID int) *dap.StackFrame {
+	frames, _, err := client.StackTrace(threadID, 0, 1)
+	if err != nil || len(frames) == 0 {
+		return nil
+	}
+	return &frames[0]
+}
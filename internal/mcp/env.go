@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	"github.com/ctagard/dap-mcp/internal/launchconfig"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// sessionDefaultEnv returns the configured default environment for a
+// session's adapter. Variables are resolved when the launch comes from a
+// launch.json, whose workspace is known; otherwise values are used as written.
+func (s *Server) sessionDefaultEnv(lang types.Language, adapter adapters.Adapter, resCtx *launchconfig.ResolutionContext) (map[string]string, error) {
+	defaults := s.config.DefaultEnv(string(lang), nativeDebugger(adapter))
+	if len(defaults) == 0 || resCtx == nil {
+		return defaults, nil
+	}
+	return launchconfig.ResolveStringMap(defaults, resCtx)
+}
+
+// applyDefaultEnv gives the spawned adapter the default environment and
+// merges it into the debuggee's env, where the launch's own variables win
+func applyDefaultEnv(args map[string]interface{}, defaults map[string]string) {
+	if len(defaults) == 0 {
+		return
+	}
+	args[adapters.DefaultEnvArg] = defaults
+
+	env := make(map[string]interface{}, len(defaults))
+	for k, v := range defaults {
+		env[k] = v
+	}
+	switch launchEnv := args["env"].(type) {
+	case map[string]interface{}:
+		for k, v := range launchEnv {
+			env[k] = v
+		}
+	case map[string]string:
+		for k, v := range launchEnv {
+			env[k] = v
+		}
+	}
+	args["env"] = env
+}
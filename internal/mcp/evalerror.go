@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/google/go-dap"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Error presentations in successful evaluate responses. Adapters report some
+// failed evaluations as a result string instead of failing the request.
+var (
+	// debugpy: a traceback, or an exception formatted as "NameError: ..."
+	pythonErrorResult = regexp.MustCompile(`^(Traceback \(most recent call last\)|[A-Za-z_][\w.]*(Error|Exception): )`)
+	// js-debug: a thrown exception, with or without the console's "Uncaught"
+	jsErrorResult = regexp.MustCompile(`^(Uncaught |(Eval|Range|Reference|Syntax|Type|URI)Error: )`)
+	// lldb-dap and CodeLLDB: the expression parser's diagnostics
+	lldbErrorResult = regexp.MustCompile(`^error: `)
+	// ElixirLS: an exception formatted as "** (CompileError) ..."
+	elixirErrorResult = regexp.MustCompile(`^\*\* \(\w[\w.]*\)`)
+)
+
+// evaluationError reports an evaluate result that is itself an error: one
+// marked failedEvaluation, or a result in the session's adapter's error format
+// with no children (an exception object has children; a message does not).
+// It returns nil for results that are values.
+func evaluationError(session *internaldap.Session, result *dap.EvaluateResponseBody) error {
+	if result.PresentationHint != nil && slices.Contains(result.PresentationHint.Attributes, "failedEvaluation") {
+		return fmt.Errorf("%s", result.Result)
+	}
+	if result.VariablesReference > 0 {
+		return nil
+	}
+
+	var pattern *regexp.Regexp
+	switch {
+	case session.Language == types.LanguagePython:
+		pattern = pythonErrorResult
+	case session.Language == types.LanguageJavaScript || session.Language == types.LanguageTypeScript:
+		pattern = jsErrorResult
+	case session.Language == types.LanguageElixir:
+		pattern = elixirErrorResult
+	case session.Debugger == "lldb":
+		pattern = lldbErrorResult
+	default:
+		return nil
+	}
+	if pattern.MatchString(result.Result) {
+		return fmt.Errorf("%s", result.Result)
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package mcp
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// exceptionLevel is one exception of a chain, which runs from the exception
+// the program stopped on down to its root cause
+type exceptionLevel struct {
+	Type    string           `json:"type,omitempty"`
+	Message string           `json:"message,omitempty"`
+	Frames  []exceptionFrame `json:"frames,omitempty"` // Innermost call first
+
+	// Relation says how the level relates to the one before it: "cause"
+	// (raise ... from, Java's Caused by, JavaScript's cause), "context" (raised
+	// while Python handled it), or "inner" (an adapter's inner exception)
+	Relation string `json:"relation,omitempty"`
+}
+
+// exceptionFrame is a stack frame parsed from an exception's stack trace text
+type exceptionFrame struct {
+	Name   string `json:"name,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// handleDebugException returns the exception a thread is stopped on as a
+// chain of exceptions with their frames, from exceptionInfo where the adapter
+// supports it and otherwise from the stopped event's description
+func (s *Server) handleDebugException(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lastStop := client.LastStop()
+	threadID, ok := requestThread(request, session, client)
+	if !ok {
+		return mcp.NewToolResultError(errors.MissingParameter("threadId",
+			"The session's last stop didn't name a thread. Pass the threadId of the thread that raised, from debug_threads.").Error()), nil
+	}
+
+	// Threads stopped only because another thread stopped have no reason
+	var stop *internaldap.StoppedInfo
+	stops := client.StoppedThreads()
+	for i := range stops {
+		if stops[i].ThreadID == threadID {
+			stop = &stops[i]
+		}
+	}
+	if stop == nil && lastStop != nil && lastStop.ThreadID == threadID {
+		stop = lastStop
+	}
+	reason := ""
+	if stop != nil {
+		reason = stop.Reason
+	}
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"threadId":  threadID,
+	}
+
+	if client.Capabilities().SupportsExceptionInfoRequest {
+		info, err := client.ExceptionInfo(threadID)
+		if err == nil {
+			result["exceptionId"] = info.ExceptionId
+			if info.Description != "" {
+				result["description"] = info.Description
+			}
+			if info.BreakMode != "" {
+				result["breakMode"] = string(info.BreakMode)
+			}
+			result["chain"] = exceptionChain(info)
+			return jsonResult(result)
+		}
+		// Adapters refuse exceptionInfo for threads that didn't raise
+		if reason != "exception" {
+			return mcp.NewToolResultError(errors.NoException(threadID, reason).Error()), nil
+		}
+		result["exceptionInfoError"] = err.Error()
+	}
+
+	if reason != "exception" {
+		return mcp.NewToolResultError(errors.NoException(threadID, reason).Error()), nil
+	}
+
+	// The stopped event is all that is left; its text, where given, holds the
+	// exception and the description a summary such as "Paused on exception"
+	summary := stop.Text
+	if summary == "" {
+		summary = stop.Description
+	}
+	chain := parseExceptionTrace(summary)
+	if len(chain) == 0 {
+		chain = []exceptionLevel{{Message: summary}}
+	}
+	result["chain"] = chain
+	result["note"] = "The adapter gave no exception details, so the chain comes from the stopped event. Use debug_snapshot for the stack."
+	return jsonResult(result)
+}
+
+// exceptionChain builds the chain of an exceptionInfo response
+func exceptionChain(info *dap.ExceptionInfoResponseBody) []exceptionLevel {
+	if info.Details == nil {
+		return []exceptionLevel{{Type: info.ExceptionId, Message: info.Description}}
+	}
+
+	chain := detailsChain(*info.Details, "")
+	if chain[0].Type == "" {
+		chain[0].Type = info.ExceptionId
+	}
+	if chain[0].Message == "" {
+		chain[0].Message = info.Description
+	}
+	return chain
+}
+
+// detailsChain builds the chain of an exception's details: the exception
+// itself, then its inner exceptions, or without those the causes its stack
+// trace text shows
+func detailsChain(details dap.ExceptionDetails, relation string) []exceptionLevel {
+	parsed := parseExceptionTrace(details.StackTrace)
+
+	var top exceptionLevel
+	if len(parsed) > 0 {
+		top = parsed[0]
+	}
+	top.Relation = relation
+	// The adapter's fields describe the exception more reliably than the text
+	if details.FullTypeName != "" {
+		top.Type = details.FullTypeName
+	} else if details.TypeName != "" {
+		top.Type = details.TypeName
+	}
+	if details.Message != "" {
+		top.Message = details.Message
+	}
+
+	chain := []exceptionLevel{top}
+	if len(details.InnerException) == 0 {
+		if len(parsed) > 1 {
+			chain = append(chain, parsed[1:]...)
+		}
+		return chain
+	}
+	for _, inner := range details.InnerException {
+		chain = append(chain, detailsChain(inner, "inner")...)
+	}
+	return chain
+}
+
+// pythonTracebackHeader starts each exception of a Python traceback
+const pythonTracebackHeader = "Traceback (most recent call last):"
+
+// pythonChainSeparators are the lines between the exceptions of a Python
+// traceback, and the relation they give the later exception's cause
+var pythonChainSeparators = map[string]string{
+	"The above exception was the direct cause of the following exception:": "cause",
+	"During handling of the above exception, another exception occurred:":  "context",
+}
+
+var (
+	// File "app.py", line 12, in handler
+	pythonFramePattern     = regexp.MustCompile(`^\s*File "(.+)", line (\d+)(?:, in (.+))?$`)
+	pythonFrameLinePattern = regexp.MustCompile(`(?m)^\s*File ".+", line \d+`)
+
+	// at com.example.App.run(App.java:42)
+	javaFramePattern = regexp.MustCompile(`^\s*at ([^\s(]+)\(([^)]*)\)$`)
+
+	// at handler (/src/app.js:12:5) or at /src/app.js:12:5
+	v8FramePattern = regexp.MustCompile(`^\s*at (?:(.+?) \()?(.+?):(\d+):(\d+)\)?$`)
+)
+
+// parseExceptionTrace parses stack trace text into a chain of exceptions,
+// outermost first. Python tracebacks, Java stack traces with "Caused by:"
+// sections, and V8 stacks (with Node's "[cause]:" sections) are recognized.
+// It returns nil for text with neither an exception line nor frames.
+func parseExceptionTrace(text string) []exceptionLevel {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	// debugpy sends the frames alone, without the traceback header
+	if strings.Contains(text, pythonTracebackHeader) || pythonFrameLinePattern.MatchString(text) {
+		return parsePythonTraceback(text)
+	}
+	return parseStackText(text)
+}
+
+// parsePythonTraceback parses a Python traceback. Python prints the root
+// cause first and the most recent call last in each exception; the chain is
+// returned outermost first with innermost frames first.
+func parsePythonTraceback(text string) []exceptionLevel {
+	var printed []exceptionLevel
+	var relations []string // relations[i] links printed[i] to printed[i+1]
+	var current exceptionLevel
+	started := false
+
+	flush := func() {
+		if started {
+			printed = append(printed, current)
+		}
+		current = exceptionLevel{}
+		started = false
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if relation, ok := pythonChainSeparators[trimmed]; ok {
+			flush()
+			relations = append(relations, relation)
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+		started = true
+
+		if m := pythonFramePattern.FindStringSubmatch(line); m != nil {
+			lineNum, _ := strconv.Atoi(m[2])
+			current.Frames = append(current.Frames, exceptionFrame{Name: m[3], Path: m[1], Line: lineNum})
+			continue
+		}
+		// Skip the header and the indented source and caret lines under frames
+		if trimmed == pythonTracebackHeader || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		if current.Type == "" && current.Message == "" {
+			current.Type, current.Message = splitExceptionLine(trimmed)
+		} else {
+			current.Message += "\n" + trimmed
+		}
+	}
+	flush()
+
+	chain := make([]exceptionLevel, len(printed))
+	for i := range printed {
+		level := printed[len(printed)-1-i]
+		for l, r := 0, len(level.Frames)-1; l < r; l, r = l+1, r-1 {
+			level.Frames[l], level.Frames[r] = level.Frames[r], level.Frames[l]
+		}
+		if i > 0 && len(printed)-1-i < len(relations) {
+			level.Relation = relations[len(printed)-1-i]
+		}
+		chain[i] = level
+	}
+	return chain
+}
+
+// parseStackText parses Java and V8 stack traces, where the exception line
+// comes first and each cause starts a section of its own
+func parseStackText(text string) []exceptionLevel {
+	var chain []exceptionLevel
+	current := &exceptionLevel{}
+	started := false
+	skipping := false // Inside a Java "Suppressed:" section
+
+	for _, line := range strings.Split(text, "\n") {
+		// Node ends the last frame before an error's properties with " {"
+		line = strings.TrimSuffix(strings.TrimRight(line, "\r"), " {")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "}" || strings.HasPrefix(trimmed, "...") {
+			continue
+		}
+
+		if cause, ok := cutCausePrefix(trimmed); ok {
+			chain = append(chain, *current)
+			current = &exceptionLevel{Relation: "cause"}
+			current.Type, current.Message = splitExceptionLine(cause)
+			started, skipping = true, false
+			continue
+		}
+		if strings.HasPrefix(trimmed, "Suppressed:") {
+			skipping = true
+			continue
+		}
+		if skipping {
+			continue
+		}
+
+		if frame, ok := parseStackFrame(line); ok {
+			current.Frames = append(current.Frames, frame)
+			started = true
+			continue
+		}
+		// Lines before the first frame belong to the exception line; later
+		// ones are properties Node prints after the stack
+		if len(current.Frames) > 0 {
+			continue
+		}
+		if current.Type == "" && current.Message == "" {
+			current.Type, current.Message = splitExceptionLine(trimmed)
+		} else {
+			current.Message += "\n" + trimmed
+		}
+		started = true
+	}
+	if !started {
+		return nil
+	}
+	return append(chain, *current)
+}
+
+// cutCausePrefix returns the exception line of a cause section
+func cutCausePrefix(line string) (string, bool) {
+	for _, prefix := range []string{"Caused by: ", "[cause]: "} {
+		if cause, ok := strings.CutPrefix(line, prefix); ok {
+			return cause, true
+		}
+	}
+	return "", false
+}
+
+// parseStackFrame parses a Java or V8 frame line
+func parseStackFrame(line string) (exceptionFrame, bool) {
+	if m := javaFramePattern.FindStringSubmatch(line); m != nil {
+		frame := exceptionFrame{Name: m[1], Path: m[2]}
+		// App.java:42, or "Native Method" and "Unknown Source" without a file
+		if file, lineNum, ok := strings.Cut(m[2], ":"); ok {
+			if n, err := strconv.Atoi(lineNum); err == nil {
+				frame.Path, frame.Line = file, n
+			}
+		} else if !strings.Contains(m[2], ".") {
+			frame.Path = ""
+		}
+		return frame, true
+	}
+	if m := v8FramePattern.FindStringSubmatch(line); m != nil {
+		lineNum, _ := strconv.Atoi(m[3])
+		column, _ := strconv.Atoi(m[4])
+		return exceptionFrame{Name: m[1], Path: m[2], Line: lineNum, Column: column}, true
+	}
+	return exceptionFrame{}, false
+}
+
+// splitExceptionLine splits "Type: message" into its type and message. Lines
+// whose text before the colon isn't a type name are all message.
+func splitExceptionLine(line string) (string, string) {
+	name, message, found := strings.Cut(line, ":")
+	if strings.ContainsAny(name, " \t'\"(") || name == "" {
+		return "", line
+	}
+	if !found {
+		// A bare name such as KeyboardInterrupt, if it looks like a type
+		if strings.HasSuffix(name, "Error") || strings.HasSuffix(name, "Exception") || strings.HasSuffix(name, "Interrupt") || strings.HasSuffix(name, "Exit") {
+			return name, ""
+		}
+		return "", line
+	}
+	return name, strings.TrimSpace(message)
+}
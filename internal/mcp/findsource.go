@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// maxSourceCandidates bounds the candidates debug_find_source returns
+const maxSourceCandidates = 50
+
+// pythonDottedName matches a Python module or attribute path such as
+// "pkg.mod.Class.method", the only names debug_find_source puts in an expression
+var pythonDottedName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// sourceCandidates collects debug_find_source's results without duplicates
+type sourceCandidates struct {
+	list []map[string]interface{}
+	seen map[string]bool
+}
+
+// add records a candidate source, keeping the first one found for a path
+func (c *sourceCandidates) add(candidate map[string]interface{}) {
+	key := fmt.Sprint(candidate["path"], "#", candidate["sourceReference"])
+	if c.seen[key] || len(c.list) >= maxSourceCandidates {
+		return
+	}
+	c.seen[key] = true
+	c.list = append(c.list, candidate)
+}
+
+// handleDebugFindSource finds where the source of a module or function lives,
+// so breakpoints can be set in code the caller only knows by name. Python
+// sessions ask the interpreter for the module's __file__ (and a function's
+// first line), Go sessions ask Delve for the package's files; the adapter's
+// modules and loaded sources are then searched by name.
+func (s *Server) handleDebugFindSource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	name, err := request.RequireString("name")
+	if err != nil || strings.TrimSpace(name) == "" {
+		return mcp.NewToolResultError(errors.MissingParameter("name",
+			"Provide a module, package, or function name, e.g. \"requests.sessions\", \"net/http\", or \"pkg.Func\".").Error()), nil
+	}
+	name = strings.TrimSpace(name)
+
+	candidates := &sourceCandidates{seen: make(map[string]bool)}
+	var searched []string
+
+	// Asking the debuggee needs a stopped program and full evaluation
+	if !client.Running() && s.config.CanEvaluate() && !s.config.EvaluatesReadOnly() {
+		frameID, _ := focusFrame(session, client)
+		switch session.Language {
+		case types.LanguagePython:
+			searched = append(searched, "python")
+			pythonSources(client, frameID, name, candidates)
+		case types.LanguageGo:
+			searched = append(searched, "delve")
+			delveSources(client, frameID, name, candidates)
+		}
+	}
+
+	caps := client.Capabilities()
+	if caps.SupportsModulesRequest {
+		_, _, _ = client.Modules(0, 0)
+	}
+	// Modules from the modules request and from module events
+	if modules, _ := client.LoadedModules(); len(modules) > 0 || caps.SupportsModulesRequest {
+		searched = append(searched, "modules")
+		for _, m := range modules {
+			if m.Path != "" && (sourceNameMatches(m.Name, name) || sourceNameMatches(m.Path, name)) {
+				candidates.add(map[string]interface{}{"path": m.Path, "module": m.Name, "origin": "modules"})
+			}
+		}
+	}
+	if caps.SupportsLoadedSourcesRequest {
+		searched = append(searched, "loadedSources")
+		if sources, err := client.LoadedSources(); err == nil {
+			for _, src := range sources {
+				if !sourceNameMatches(src.Path, name) && !sourceNameMatches(src.Name, name) {
+					continue
+				}
+				candidate := map[string]interface{}{"path": src.Path, "origin": "loadedSources"}
+				if src.SourceReference > 0 {
+					candidate["sourceReference"] = src.SourceReference
+				}
+				candidates.add(candidate)
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"sessionId":  session.ID,
+		"name":       name,
+		"candidates": candidates.list,
+		"searched":   searched,
+	}
+	if len(candidates.list) == 0 {
+		result["note"] = "No source found. Python and Go lookups need the program to be stopped and full evaluation; otherwise only the adapter's modules and loaded sources are searched."
+	}
+	if len(candidates.list) >= maxSourceCandidates {
+		result["truncated"] = true
+	}
+	return jsonResult(result)
+}
+
+// pythonSources asks the interpreter for the file of the longest module
+// prefix of a dotted name, and the first line of the function the rest of
+// the name refers to
+func pythonSources(client *internaldap.Client, frameID int, name string, candidates *sourceCandidates) {
+	if !pythonDottedName.MatchString(name) {
+		return
+	}
+	parts := strings.Split(name, ".")
+	for i := len(parts); i > 0; i-- {
+		module := strings.Join(parts[:i], ".")
+		moduleExpr := fmt.Sprintf("__import__('sys').modules['%s']", module)
+		evaluated, err := client.Evaluate(moduleExpr+".__file__", frameID, "watch")
+		if err != nil {
+			continue
+		}
+		file := strings.Trim(evaluated.Result, `'"`)
+		if file == "" || file == "None" {
+			continue
+		}
+		candidate := map[string]interface{}{"path": file, "module": module, "origin": "python"}
+		if attrs := parts[i:]; len(attrs) > 0 {
+			code := moduleExpr + "." + strings.Join(attrs, ".") + ".__code__.co_firstlineno"
+			if line, err := client.Evaluate(code, frameID, "watch"); err == nil {
+				if n, err := strconv.Atoi(line.Result); err == nil {
+					candidate["line"] = n
+				}
+			}
+		}
+		candidates.add(candidate)
+		return
+	}
+}
+
+// delveSources lists the files of a Go package with Delve's "dlv sources"
+// command. The package is the name up to the function, e.g. "net/http" for
+// "net/http.Get"; its files are matched by their directory.
+func delveSources(client *internaldap.Client, frameID int, name string, candidates *sourceCandidates) {
+	pkg := name
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		if dot := strings.Index(name[slash:], "."); dot >= 0 {
+			pkg = name[:slash+dot]
+		}
+	} else if dot := strings.Index(name, "."); dot >= 0 {
+		pkg = name[:dot]
+	}
+	pattern := "/" + regexp.QuoteMeta(path.Base(pkg)) + `/[^/]+\.go$`
+
+	evaluated, err := client.Evaluate("dlv sources "+pattern, frameID, "repl")
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(evaluated.Result, "\n") {
+		if file := strings.TrimSpace(line); strings.HasSuffix(file, ".go") {
+			candidates.add(map[string]interface{}{"path": file, "module": pkg, "origin": "delve"})
+		}
+	}
+}
+
+// sourceNameMatches reports whether a module name or source path refers to a
+// name, compared case-insensitively, with a dotted name also matched as a
+// path ("pkg.mod" matches ".../pkg/mod.py")
+func sourceNameMatches(s, name string) bool {
+	if s == "" {
+		return false
+	}
+	s, name = strings.ToLower(s), strings.ToLower(name)
+	return strings.Contains(s, name) || strings.Contains(s, strings.ReplaceAll(name, ".", "/"))
+}
@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// handleDebugFocus sets the thread and frame that tools use when threadId or
+// frameId is omitted, like an IDE's selected stack frame, and returns the
+// current focus. Without arguments it only reports the focus. The focus
+// follows the program: on each stop it moves to the stopped thread's top frame.
+func (s *Server) handleDebugFocus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	threadID := 0
+	if t, err := requireInt(request, "threadId"); err == nil {
+		threadID = t
+	}
+	_, indexErr := requireInt(request, "index")
+	set := threadID != 0 || indexErr == nil
+
+	var frames []dap.StackFrame
+	index := 0
+	if f, err := requireInt(request, "frameId"); err == nil {
+		threadID, frames, index, err = findFrame(client, threadID, f)
+		if err != nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("frameId", f,
+				"the id of a frame in a paused thread's stack (see debug_snapshot)").Error()), nil
+		}
+		set = true
+	} else if set {
+		if threadID == 0 {
+			threadID = focusThread(session, client)
+		}
+		if n, err := requireInt(request, "index"); err == nil {
+			index = n
+		}
+		frames, _, err = client.StackTrace(threadID, 0, maxFrameStackDepth)
+		if err != nil {
+			return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter, fmt.Sprintf("failed to get the stack of thread %d", threadID),
+				"The thread must be paused; use debug_threads to list threads.", err).Error()), nil
+		}
+		if index < 0 || index >= len(frames) {
+			return mcp.NewToolResultError(errors.InvalidParameter("index", index,
+				"a frame index in the thread's stack, from 0 (innermost)").Error()), nil
+		}
+	} else {
+		frameID, ok := focusFrame(session, client)
+		if !ok {
+			return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+		}
+		threadID, frames, index, err = findFrame(client, focusThread(session, client), frameID)
+		if err != nil {
+			return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+		}
+	}
+
+	if set {
+		session.SetFocus(threadID, frames[index].Id)
+	}
+	_, _, selected := session.Focus()
+
+	return jsonResult(map[string]interface{}{
+		"sessionId": session.ID,
+		"threadId":  threadID,
+		"index":     index,
+		"frame":     frameInfo(frames[index]),
+		// false: the focus is the default, the top frame of the thread that last stopped
+		"selected": selected,
+	})
+}
+
+// requestThread returns the thread a tool acts on: threadId if given, else the
+// session's focus thread. ok is false if there is no thread to default to.
+func requestThread(request mcp.CallToolRequest, session *internaldap.Session, client *internaldap.Client) (int, bool) {
+	if t, err := requireInt(request, "threadId"); err == nil {
+		return t, true
+	}
+	threadID := focusThread(session, client)
+	return threadID, threadID != 0
+}
+
+// requestFrame returns the frame a tool evaluates in: frameId if given, else
+// the top frame of threadId if given, else the session's focus frame. ok is
+// false if there is no frame to default to, e.g. while the program runs.
+func requestFrame(request mcp.CallToolRequest, session *internaldap.Session, client *internaldap.Client) (int, bool) {
+	if f, err := requireInt(request, "frameId"); err == nil {
+		return f, true
+	}
+	if t, err := requireInt(request, "threadId"); err == nil {
+		return topFrameID(client, t)
+	}
+	return focusFrame(session, client)
+}
+
+// focusThread returns the session's focus thread: the one set with
+// debug_focus, else the thread that last stopped, else the first thread. It
+// returns 0 if the program has no threads.
+func focusThread(session *internaldap.Session, client *internaldap.Client) int {
+	if threadID, _, ok := session.Focus(); ok {
+		return threadID
+	}
+	if stop := client.LastStop(); stop != nil && stop.ThreadID != 0 {
+		return stop.ThreadID
+	}
+	if threads, err := client.Threads(); err == nil && len(threads) > 0 {
+		return threads[0].Id
+	}
+	return 0
+}
+
+// focusFrame returns the session's focus frame: the one set with debug_focus,
+// else the top frame of the focus thread
+func focusFrame(session *internaldap.Session, client *internaldap.Client) (int, bool) {
+	if _, frameID, ok := session.Focus(); ok {
+		return frameID, true
+	}
+	threadID := focusThread(session, client)
+	if threadID == 0 {
+		return 0, false
+	}
+	return topFrameID(client, threadID)
+}
+
+// topFrameID returns the id of a paused thread's innermost frame
+func topFrameID(client *internaldap.Client, threadID int) (int, bool) {
+	frames, _, err := client.StackTrace(threadID, 0, 1)
+	if err != nil || len(frames) == 0 {
+		return 0, false
+	}
+	return frames[0].Id, true
+}
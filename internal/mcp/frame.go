@@ -0,0 +1,242 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// Limits for debug_frame
+const (
+	defaultFrameContextLines = 5
+	maxFrameContextLines     = 100
+	// maxFrameStackDepth bounds the frames fetched to find a frame and its neighbors
+	maxFrameStackDepth = 200
+	maxFrameVariables  = 50
+)
+
+// handleDebugFrame returns one stack frame with the source around its line and
+// its local variables, so a frame can be read in one call. The frame is given
+// by frameId or by index in a thread's stack, and direction "up" (toward the
+// caller) or "down" (toward the callee) moves from it to a neighbor.
+func (s *Server) handleDebugFrame(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	contextLines := defaultFrameContextLines
+	if n, err := requireInt(request, "contextLines"); err == nil && n >= 0 {
+		contextLines = min(n, maxFrameContextLines)
+	}
+
+	threadID := 0
+	if t, err := requireInt(request, "threadId"); err == nil {
+		threadID = t
+	}
+
+	var frames []dap.StackFrame
+	index := 0
+	n, indexErr := requireInt(request, "index")
+	if f, err := requireInt(request, "frameId"); err == nil {
+		threadID, frames, index, err = findFrame(client, threadID, f)
+		if err != nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("frameId", f,
+				"the id of a frame in a paused thread's stack (see debug_snapshot)").Error()), nil
+		}
+	} else if _, frameID, ok := session.Focus(); ok && threadID == 0 && indexErr != nil {
+		// Start from the frame set with debug_focus
+		threadID, frames, index, err = findFrame(client, focusThread(session, client), frameID)
+		if err != nil {
+			return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+		}
+	} else {
+		if threadID == 0 {
+			threadID = focusThread(session, client)
+			if threadID == 0 {
+				return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+			}
+		}
+		if indexErr == nil {
+			index = n
+		}
+		frames, _, err = client.StackTrace(threadID, 0, maxFrameStackDepth)
+		if err != nil {
+			return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter, fmt.Sprintf("failed to get the stack of thread %d", threadID),
+				"The thread must be paused; use debug_threads to list threads.", err).Error()), nil
+		}
+	}
+
+	direction, _ := request.RequireString("direction")
+	switch direction {
+	case "":
+	case "up":
+		index++
+	case "down":
+		index--
+	default:
+		return mcp.NewToolResultError(errors.InvalidParameter("direction", direction, "'up' (toward the caller) or 'down' (toward the callee)").Error()), nil
+	}
+	if index < 0 || index >= len(frames) {
+		return mcp.NewToolResultError(errors.InvalidParameter("index", index,
+			fmt.Sprintf("a frame index from 0 (innermost) to %d in thread %d", len(frames)-1, threadID)).Error()), nil
+	}
+	frame := frames[index]
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"threadId":  threadID,
+		"index":     index,
+		"frame":     frameInfo(frame),
+		// Whether moving up or down from this frame leads anywhere
+		"hasCaller": index+1 < len(frames),
+		"hasCallee": index > 0,
+	}
+	if source := s.frameSource(client, frame, contextLines); source != nil {
+		result["source"] = source
+	}
+	result["scopes"] = s.frameScopes(client, frame.Id)
+	return jsonResult(result)
+}
+
+// findFrame finds the stack containing a frame, in the given thread or, with
+// threadID 0, in any thread. It returns the thread, its stack, and the frame's
+// index in it.
+func findFrame(client *internaldap.Client, threadID, frameID int) (int, []dap.StackFrame, int, error) {
+	threadIDs := []int{threadID}
+	if threadID == 0 {
+		threads, err := client.Threads()
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		threadIDs = threadIDs[:0]
+		for _, t := range threads {
+			threadIDs = append(threadIDs, t.Id)
+		}
+	}
+
+	for _, id := range threadIDs {
+		frames, _, err := client.StackTrace(id, 0, maxFrameStackDepth)
+		if err != nil {
+			continue
+		}
+		for i, f := range frames {
+			if f.Id == frameID {
+				return id, frames, i, nil
+			}
+		}
+	}
+	return 0, nil, 0, fmt.Errorf("frame %d not found", frameID)
+}
+
+// frameInfo describes a stack frame the way debug_snapshot does
+func frameInfo(f dap.StackFrame) map[string]interface{} {
+	frame := map[string]interface{}{
+		"id":   f.Id,
+		"name": f.Name,
+		"line": f.Line,
+	}
+	if f.Column > 0 {
+		frame["column"] = f.Column
+	}
+	if f.Source != nil {
+		source := map[string]interface{}{
+			"path": f.Source.Path,
+			"name": f.Source.Name,
+		}
+		if f.Source.SourceReference > 0 {
+			source["sourceReference"] = f.Source.SourceReference
+		}
+		frame["source"] = source
+	}
+	return frame
+}
+
+// frameSource returns the lines around a frame's line, numbered and with the
+// current line marked by ">". It returns nil for frames without a source and
+// reports a source that can't be read in an error field.
+func (s *Server) frameSource(client *internaldap.Client, f dap.StackFrame, contextLines int) map[string]interface{} {
+	if f.Source == nil || f.Line <= 0 || (f.Source.Path == "" && f.Source.SourceReference <= 0) {
+		return nil
+	}
+
+	start := max(f.Line-contextLines, 1)
+	end := f.Line + contextLines
+	var lines sourceLines
+	var err error
+	if f.Source.SourceReference <= 0 && isRegularFile(f.Source.Path) {
+		lines, _, err = readSourceFileLines(f.Source.Path, start, end)
+	} else {
+		var content string
+		content, _, err = client.Source(f.Source.SourceReference, f.Source.Path)
+		if err == nil {
+			lines = sliceSourceLines(content, start, end)
+		}
+	}
+	if err != nil {
+		return map[string]interface{}{"error": errors.SourceUnavailable(f.Source.Path, f.Source.SourceReference, err).Error()}
+	}
+	if lines.first > lines.total {
+		return map[string]interface{}{"error": fmt.Sprintf("line %d is past the end of the source's %d lines", f.Line, lines.total)}
+	}
+
+	var b strings.Builder
+	for i, line := range strings.SplitAfter(strings.TrimSuffix(lines.content, "\n"), "\n") {
+		number := lines.first + i
+		marker := " "
+		if number == f.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s%5d  %s", marker, number, strings.TrimRight(line, "\r\n")+"\n")
+	}
+	return map[string]interface{}{
+		"startLine": lines.first,
+		"endLine":   lines.last,
+		"content":   b.String(),
+	}
+}
+
+// frameScopes lists a frame's scopes with the variables of the inexpensive
+// ones; expensive scopes (e.g. globals) keep their variablesReference for
+// debug_evaluate or debug_inspect_tree
+func (s *Server) frameScopes(client *internaldap.Client, frameID int) []map[string]interface{} {
+	scopes, err := client.Scopes(frameID)
+	if err != nil {
+		return []map[string]interface{}{}
+	}
+
+	result := make([]map[string]interface{}, 0, len(scopes))
+	for _, scope := range scopes {
+		scopeInfo := map[string]interface{}{
+			"name":               scope.Name,
+			"variablesReference": scope.VariablesReference,
+		}
+		if scope.Expensive {
+			scopeInfo["expensive"] = true
+		} else if scope.VariablesReference > 0 {
+			vars, err := client.Variables(scope.VariablesReference, "", 0, maxFrameVariables)
+			if err == nil {
+				varsList := make([]map[string]interface{}, 0, len(vars))
+				for _, v := range vars {
+					varInfo := map[string]interface{}{
+						"name":               v.Name,
+						"type":               v.Type,
+						"variablesReference": v.VariablesReference,
+					}
+					s.setValue(varInfo, "value", v.Value)
+					addPresentationHint(varInfo, v.PresentationHint)
+					varsList = append(varsList, varInfo)
+				}
+				scopeInfo["variables"] = varsList
+			}
+		}
+		result = append(result, scopeInfo)
+	}
+	return result
+}
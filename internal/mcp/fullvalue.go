@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// Limits for debug_get_full_value
+const (
+	// maxFullValueBytes bounds the text returned for one value
+	maxFullValueBytes = 4 << 20
+	// fullValuePageSize is how many children are read per variables request
+	fullValuePageSize = 1000
+)
+
+// adapterCut matches the end of a value the adapter cut short: Delve ends
+// long strings in ...+N more, others in ...
+var adapterCut = regexp.MustCompile(`\.\.\.(\+\d+ more)?["']?$`)
+
+// fullValue is the value debug_get_full_value assembles text from
+type fullValue struct {
+	name               string
+	value              string
+	variablesReference int
+	indexed            int
+	memoryReference    string
+}
+
+// handleDebugGetFullValue returns the complete text of a value as plain text:
+// the escape hatch for values cut short by maxVariableValueLength or by the
+// adapter, such as a long traceback. The value is an expression, evaluated in
+// the clipboard context where the adapter supports it; a variable by name
+// under a variablesReference; or the children of a variablesReference. A
+// collection comes back one element per line, its children read page by page,
+// and a value the adapter still cut short is read from memory when it can be.
+// Quoted strings are unquoted, so escaped newlines become line breaks.
+func (s *Server) handleDebugGetFullValue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var target fullValue
+	name, _ := request.RequireString("name")
+	if ref, err := requireInt(request, "variablesReference"); err == nil && ref > 0 {
+		if name == "" {
+			// The children of the reference itself, however many there are
+			target = fullValue{name: fmt.Sprintf("reference %d", ref), variablesReference: ref, indexed: -1}
+		} else {
+			variables, err := client.Variables(ref, "", 0, 0)
+			if err != nil {
+				return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, fmt.Sprintf("failed to get the variables of reference %d", ref),
+					"The reference may be stale; references are only valid while the program stays stopped.", err).Error()), nil
+			}
+			found := false
+			for _, v := range variables {
+				if v.Name == name {
+					target = fullValue{name: name, value: v.Value, variablesReference: v.VariablesReference,
+						indexed: v.IndexedVariables, memoryReference: v.MemoryReference}
+					found = true
+					break
+				}
+			}
+			if !found {
+				return mcp.NewToolResultError(errors.InvalidParameter("name", name,
+					fmt.Sprintf("the name of a variable under reference %d", ref)).Error()), nil
+			}
+		}
+	} else {
+		expression, err := request.RequireString("expression")
+		if err != nil || expression == "" {
+			return mcp.NewToolResultError(errors.MissingParameter("expression",
+				"Provide an expression to evaluate (e.g. \"traceback.format_exc()\"), or a variablesReference with or without a name.").Error()), nil
+		}
+		if !s.config.CanEvaluate() {
+			return mcp.NewToolResultError(errors.PermissionDenied("evaluate", string(s.config.Mode)).Error()), nil
+		}
+		frameID, _ := requestFrame(request, session, client)
+
+		// Adapters return values in full in the clipboard context
+		evalContext := "watch"
+		if client.Capabilities().SupportsClipboardContext {
+			evalContext = "clipboard"
+		}
+		evalContext, _, debugErr := s.evaluationMode(session, expression, evalContext)
+		if debugErr != nil {
+			return mcp.NewToolResultError(debugErr.Error()), nil
+		}
+		evaluated, err := client.Evaluate(expression, frameID, evalContext)
+		if err != nil {
+			return mcp.NewToolResultError(errors.EvaluationFailed(expression, err).Error()), nil
+		}
+		if resultErr := evaluationError(session, evaluated); resultErr != nil {
+			return mcp.NewToolResultError(errors.EvaluationResultError(expression, resultErr).Error()), nil
+		}
+		target = fullValue{name: expression, value: evaluated.Result, variablesReference: evaluated.VariablesReference,
+			indexed: evaluated.IndexedVariables, memoryReference: evaluated.MemoryReference}
+	}
+
+	// Adapters that don't count a collection's elements may still cut its value short
+	if target.variablesReference > 0 && target.indexed == 0 && adapterCut.MatchString(target.value) {
+		target.indexed = -1
+	}
+
+	var text string
+	if memory, ok := memoryText(client, target); ok {
+		text = memory
+	} else if target.variablesReference > 0 && target.indexed != 0 {
+		text, err = collectionText(client, target)
+		if err != nil {
+			return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, fmt.Sprintf("failed to read the elements of %s", target.name),
+				"The reference may be stale; references are only valid while the program stays stopped.", err).Error()), nil
+		}
+	} else {
+		text = unquoteValue(target.value)
+	}
+
+	if len(text) > maxFullValueBytes {
+		limit := maxFullValueBytes
+		for limit > 0 && !utf8.RuneStart(text[limit]) {
+			limit--
+		}
+		text = fmt.Sprintf("%s\n...(cut at %d of %d bytes)", text[:limit], limit, len(text))
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// collectionText reads a collection's elements, a page at a time, and returns
+// them one per line. With an unknown number of elements every child is read
+// at once, and named children are labeled.
+func collectionText(client *internaldap.Client, target fullValue) (string, error) {
+	var children []dap.Variable
+	if target.indexed < 0 {
+		variables, err := client.Variables(target.variablesReference, "", 0, 0)
+		if err != nil {
+			return "", err
+		}
+		children = variables
+	} else {
+		size := 0
+		for start := 0; start < target.indexed && size <= maxFullValueBytes; start += fullValuePageSize {
+			page, err := client.Variables(target.variablesReference, "indexed", start, min(fullValuePageSize, target.indexed-start))
+			if err != nil {
+				return "", err
+			}
+			if len(page) == 0 {
+				break
+			}
+			for _, v := range page {
+				size += len(v.Value)
+			}
+			children = append(children, page...)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range children {
+		if target.indexed < 0 && !isIndexName(v.Name) {
+			fmt.Fprintf(&b, "%s: ", v.Name)
+		}
+		line := unquoteValue(v.Value)
+		b.WriteString(line)
+		// Lines of a formatted traceback already end in a newline
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// memoryText reads a value the adapter cut short from its memory, for strings
+// and buffers whose length is their number of elements. It reports whether the
+// memory was read and is text.
+func memoryText(client *internaldap.Client, target fullValue) (string, bool) {
+	if !adapterCut.MatchString(target.value) || target.memoryReference == "" || target.indexed <= 0 ||
+		!client.Capabilities().SupportsReadMemoryRequest {
+		return "", false
+	}
+	memory, err := client.ReadMemory(target.memoryReference, 0, min(target.indexed, maxFullValueBytes))
+	if err != nil {
+		return "", false
+	}
+	data, err := base64.StdEncoding.DecodeString(memory.Data)
+	if err != nil || !utf8.Valid(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// isIndexName reports whether a child's name is an index, e.g. "3" or "[3]"
+func isIndexName(name string) bool {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "["), "]")
+	_, err := strconv.Atoi(name)
+	return err == nil
+}
+
+// unquoteValue returns a string value's contents. Adapters show strings
+// quoted, with escapes such as \n: in Go, C, and JavaScript syntax ("...") or
+// Python's ('...'). Other values, and strings that don't unquote, are
+// returned as they are.
+func unquoteValue(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch first, last := value[0], value[len(value)-1]; {
+	case first == '"' && last == '"':
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+	case first == '\'' && last == '\'':
+		// Requote Python's single-quoted syntax as a double-quoted string
+		inner := strings.ReplaceAll(value[1:len(value)-1], `\'`, `'`)
+		inner = strings.ReplaceAll(inner, `"`, `\"`)
+		if s, err := strconv.Unquote(`"` + inner + `"`); err == nil {
+			return s
+		}
+	}
+	return value
+}
@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// handleDebugSetFunctionBreakpoints sets breakpoints on functions by name, or
+// with regex=true on every function matching a pattern (GDB and LLDB only),
+// which catches all overloads and instantiations of mangled C++/Rust symbols.
+func (s *Server) handleDebugSetFunctionBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	functionsJSON, err := request.RequireString("functions")
+	if err != nil {
+		return mcp.NewToolResultError(errors.MissingParameter("functions",
+			`Provide a JSON array of function names, e.g. ["main", "Foo::bar"], or objects with a condition: [{"name": "parse", "condition": "len > 10"}].`).Error()), nil
+	}
+	breakpoints, err := parseFunctionBreakpoints(functionsJSON)
+	if err != nil {
+		return mcp.NewToolResultError(errors.InvalidJSON("functions", err, `["main", {"name": "Foo::bar", "condition": "x > 0"}]`).Error()), nil
+	}
+
+	if request.GetBool("regex", false) {
+		return s.setRegexBreakpoints(session, client, breakpoints)
+	}
+
+	if !client.Capabilities().SupportsFunctionBreakpoints {
+		return mcp.NewToolResultError(errors.InvalidParameter("sessionId", session.ID,
+			"a session whose adapter supports function breakpoints (see debug_capabilities); set a source breakpoint with debug_breakpoints instead").Error()), nil
+	}
+
+	bps, err := client.SetFunctionBreakpoints(breakpoints)
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeBreakpointFailed, "failed to set function breakpoints",
+			"Check the function names; C++ and Rust names may need their namespace or module path, or use regex=true.", err).Error()), nil
+	}
+	session.SetFunctionBreakpoints(breakpoints)
+
+	result := make([]map[string]interface{}, 0, len(bps))
+	verified := 0
+	for i, bp := range bps {
+		entry := map[string]interface{}{
+			"id":       bp.Id,
+			"verified": bp.Verified,
+		}
+		if i < len(breakpoints) {
+			entry["function"] = breakpoints[i].Name
+		}
+		if bp.Source != nil && bp.Source.Path != "" {
+			entry["path"] = bp.Source.Path
+		}
+		if bp.Line > 0 {
+			entry["line"] = bp.Line
+		}
+		if bp.Message != "" {
+			entry["message"] = bp.Message
+		}
+		if bp.Verified {
+			verified++
+		}
+		result = append(result, entry)
+	}
+
+	return jsonResult(map[string]interface{}{
+		"sessionId":   session.ID,
+		"breakpoints": result,
+		"verified":    verified,
+	})
+}
+
+// setRegexBreakpoints creates a breakpoint on every function matching each
+// pattern with the native debugger's own command, since DAP function
+// breakpoints only take exact names
+func (s *Server) setRegexBreakpoints(session *internaldap.Session, client *internaldap.Client, breakpoints []dap.FunctionBreakpoint) (*mcp.CallToolResult, error) {
+	if session.Debugger != "lldb" && session.Debugger != "gdb" {
+		return mcp.NewToolResultError(errors.InvalidParameter("regex", true,
+			"a GDB or LLDB session; other adapters only set function breakpoints by exact name").Error()), nil
+	}
+	// Regex breakpoints are debugger commands run in the repl context
+	if s.config.EvaluatesReadOnly() {
+		return mcp.NewToolResultError(errors.PermissionDenied("repl evaluation", string(s.config.Mode)).Error()), nil
+	}
+	for _, bp := range breakpoints {
+		if err := checkRegexPattern(bp.Name); err != nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("functions", bp.Name, err.Error()).Error()), nil
+		}
+	}
+
+	results := make([]map[string]interface{}, 0, len(breakpoints))
+	total := 0
+	for _, bp := range breakpoints {
+		if bp.Condition != "" || bp.HitCondition != "" {
+			return mcp.NewToolResultError(errors.InvalidParameter("functions", bp.Name,
+				"regex patterns without condition or hitCondition").Error()), nil
+		}
+
+		ids, locations, err := setRegexBreakpoint(client, session.Debugger, bp.Name)
+		if err != nil {
+			return mcp.NewToolResultError(errors.Wrap(errors.CodeBreakpointFailed, fmt.Sprintf("failed to set regex breakpoint %q", bp.Name),
+				"Check the pattern; it is matched against function names by the debugger.", err).Error()), nil
+		}
+		session.AddRegexBreakpoint(bp.Name)
+		total += len(locations)
+
+		entry := map[string]interface{}{
+			"pattern":   bp.Name,
+			"ids":       ids,
+			"locations": locations,
+			"count":     len(locations),
+		}
+		if len(locations) == 0 {
+			entry["note"] = "no function matches yet; the breakpoint stays pending until a matching function is loaded"
+		}
+		results = append(results, entry)
+	}
+
+	deleteCommand := "breakpoint delete <id>"
+	if session.Debugger == "gdb" {
+		deleteCommand = "delete <id>"
+	}
+	return jsonResult(map[string]interface{}{
+		"sessionId":   session.ID,
+		"regex":       true,
+		"breakpoints": results,
+		"locations":   total,
+		"note":        fmt.Sprintf("Regex breakpoints are managed by %s and are added to, not replaced by, later calls. Remove one with debug_execute_command %q.", session.Debugger, deleteCommand),
+	})
+}
+
+var (
+	// lldbBreakpointID matches "Breakpoint 3: ..." in LLDB's breakpoint set output
+	lldbBreakpointID = regexp.MustCompile(`^Breakpoint (\d+):`)
+	// lldbLocation matches a location line of "breakpoint list <id>"
+	lldbLocation = regexp.MustCompile(`^\s*\d+\.\d+: where = (.*?)(?:, address = .*)?$`)
+	// gdbBreakpoint matches the lines rbreak prints for each breakpoint it sets
+	gdbBreakpoint = regexp.MustCompile(`^Breakpoint (\d+) at (.*?)\.?$`)
+)
+
+// checkRegexPattern rejects a regex breakpoint pattern that could run more
+// than the breakpoint command: the pattern is part of a debugger command line,
+// so a newline or other control character would start another command
+func checkRegexPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("a non-empty pattern")
+	}
+	for _, r := range pattern {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("a pattern on one line, without control characters such as newlines")
+		}
+	}
+	return nil
+}
+
+// setRegexBreakpoint runs the debugger command that breaks on every function
+// matching pattern, returning the breakpoint IDs and resolved locations
+func setRegexBreakpoint(client *internaldap.Client, debugger, pattern string) ([]int, []string, error) {
+	if err := checkRegexPattern(pattern); err != nil {
+		return nil, nil, fmt.Errorf("invalid pattern %q: expected %v", pattern, err)
+	}
+	if debugger == "gdb" {
+		out, err := client.Evaluate("rbreak "+pattern, 0, "repl")
+		if err != nil {
+			return nil, nil, err
+		}
+		var ids []int
+		locations := make([]string, 0)
+		for _, line := range strings.Split(out.Result, "\n") {
+			if m := gdbBreakpoint.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				id, _ := strconv.Atoi(m[1])
+				ids = append(ids, id)
+				locations = append(locations, m[2])
+			}
+		}
+		return ids, locations, nil
+	}
+
+	// The backtick makes lldb-dap run the input as an LLDB command
+	out, err := client.Evaluate("`breakpoint set --func-regex "+lldbQuote(pattern), 0, "repl")
+	if err != nil {
+		return nil, nil, err
+	}
+	m := lldbBreakpointID.FindStringSubmatch(strings.TrimSpace(out.Result))
+	if m == nil {
+		return nil, nil, fmt.Errorf("unexpected output: %s", strings.TrimSpace(out.Result))
+	}
+	id, _ := strconv.Atoi(m[1])
+
+	listed, err := client.Evaluate("`breakpoint list "+m[1], 0, "repl")
+	if err != nil {
+		return []int{id}, nil, err
+	}
+	locations := make([]string, 0)
+	for _, line := range strings.Split(listed.Result, "\n") {
+		if lm := lldbLocation.FindStringSubmatch(line); lm != nil {
+			locations = append(locations, lm[1])
+		}
+	}
+	return []int{id}, locations, nil
+}
+
+// lldbQuote quotes a command argument for LLDB. Single quotes keep regex
+// backslashes literal; patterns containing one are double-quoted instead.
+func lldbQuote(arg string) string {
+	if !strings.Contains(arg, "'") {
+		return "'" + arg + "'"
+	}
+	return strconv.Quote(arg)
+}
+
+// parseFunctionBreakpoints parses the functions parameter: a JSON array of
+// names or of {name, condition, hitCondition} objects
+func parseFunctionBreakpoints(functionsJSON string) ([]dap.FunctionBreakpoint, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(functionsJSON), &items); err != nil {
+		return nil, err
+	}
+
+	breakpoints := make([]dap.FunctionBreakpoint, 0, len(items))
+	for _, item := range items {
+		var bp dap.FunctionBreakpoint
+		var name string
+		if err := json.Unmarshal(item, &name); err == nil {
+			bp.Name = name
+		} else if err := json.Unmarshal(item, &bp); err != nil {
+			return nil, err
+		}
+		if bp.Name == "" {
+			return nil, fmt.Errorf("function breakpoint without a name: %s", item)
+		}
+		breakpoints = append(breakpoints, bp)
+	}
+	return breakpoints, nil
+}
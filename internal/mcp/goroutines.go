@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Limits for debug_goroutines and debug_goroutine_stack
+const (
+	defaultMaxGoroutines = 100
+	// goroutineLocationDepth bounds the frames read to find a goroutine's
+	// location outside the runtime
+	goroutineLocationDepth     = 20
+	defaultGoroutineStackDepth = 50
+)
+
+// delveThreadName matches the names Delve gives the threads it reports for
+// goroutines: "* [Go 7] main.worker (Thread 1234)", where * marks the selected
+// goroutine and the thread is the OS thread it was running on, if any
+var delveThreadName = regexp.MustCompile(`^(\* )?\[Go (\d+)([^\]]*)\] ?(.*?)(?: \(Thread (\d+)\))?$`)
+
+// goroutine is a goroutine as described by the name of its Delve thread
+type goroutine struct {
+	id       int
+	selected bool
+	// labels is whatever Delve adds after the id, e.g. pprof labels
+	labels string
+	// function is the goroutine's current function outside the runtime
+	function string
+	// thread is the OS thread the goroutine was running on, 0 if none
+	thread int
+}
+
+// parseGoroutine reads a goroutine from a Delve thread. Threads whose name
+// isn't in Delve's format keep their id and name.
+func parseGoroutine(thread dap.Thread) goroutine {
+	m := delveThreadName.FindStringSubmatch(thread.Name)
+	if m == nil {
+		return goroutine{id: thread.Id, function: thread.Name}
+	}
+	g := goroutine{id: thread.Id, selected: m[1] != "", labels: strings.TrimSpace(m[3]), function: m[4]}
+	if id, err := strconv.Atoi(m[2]); err == nil {
+		g.id = id
+	}
+	if m[5] != "" {
+		g.thread, _ = strconv.Atoi(m[5])
+	}
+	return g
+}
+
+// handleDebugGoroutines lists a Go session's goroutines with their state,
+// current function and location, and the function they were started with.
+// Delve reports goroutines as DAP threads, with the goroutine id as the thread
+// id, so they are read from the threads and stackTrace requests; Delve's DAP
+// server has no goroutine request of its own.
+func (s *Server) handleDebugGoroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if session.Language != types.LanguageGo {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"debug_goroutines only works with Go (Delve) sessions; use debug_threads for other languages. "+
+				"Current session language: %s.", session.Language)), nil
+	}
+
+	maxGoroutines := defaultMaxGoroutines
+	if n, err := requireInt(request, "maxGoroutines"); err == nil {
+		if n < 1 {
+			return mcp.NewToolResultError(errors.InvalidParameter("maxGoroutines", n, "a number of 1 or more").Error()), nil
+		}
+		maxGoroutines = n
+	}
+	filter, _ := request.RequireString("filter")
+	filter = strings.ToLower(filter)
+	includeLocation := request.GetBool("includeLocation", true)
+
+	threads, err := client.Threads()
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, "failed to list goroutines",
+			"The program must be paused; use debug_pause first.", err).Error()), nil
+	}
+
+	goroutines := make([]map[string]interface{}, 0)
+	counts := map[string]int{}
+	matched := 0
+	for _, thread := range threads {
+		g := parseGoroutine(thread)
+		if filter != "" && !strings.Contains(strings.ToLower(g.function), filter) {
+			continue
+		}
+		matched++
+		state := "waiting"
+		if g.thread != 0 {
+			state = "running"
+		}
+		counts[state]++
+		if len(goroutines) >= maxGoroutines {
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"id":       g.id,
+			"state":    state,
+			"function": g.function,
+		}
+		if g.selected {
+			entry["selected"] = true
+		}
+		if g.thread != 0 {
+			entry["thread"] = g.thread
+		}
+		if g.labels != "" {
+			entry["labels"] = g.labels
+		}
+		if includeLocation {
+			addGoroutineLocation(client, thread.Id, entry)
+		}
+		goroutines = append(goroutines, entry)
+	}
+
+	result := map[string]interface{}{
+		"sessionId":  session.ID,
+		"goroutines": goroutines,
+		"total":      len(threads),
+		"counts":     counts,
+	}
+	if filter != "" {
+		result["matched"] = matched
+	}
+	if omitted := matched - len(goroutines); omitted > 0 {
+		result["omitted"] = omitted
+	}
+	return jsonResult(result)
+}
+
+// addGoroutineLocation adds a goroutine's location, the first frame outside
+// the runtime, and its start function, the outermost frame before
+// runtime.goexit. Delve doesn't report where a goroutine was created over DAP,
+// so the start function is the closest it gets.
+func addGoroutineLocation(client *internaldap.Client, threadID int, entry map[string]interface{}) {
+	page, err := client.StackTracePage(threadID, 0, goroutineLocationDepth)
+	if err != nil || len(page.Frames) == 0 {
+		return
+	}
+	location := page.Frames[0]
+	for _, f := range page.Frames {
+		if !strings.HasPrefix(f.Name, "runtime.") {
+			location = f
+			break
+		}
+	}
+	entry["location"] = frameInfo(location)
+	if location.Name != page.Frames[0].Name {
+		// Where a waiting goroutine is parked, e.g. runtime.gopark
+		entry["topFunction"] = page.Frames[0].Name
+	}
+
+	outer := page.Frames
+	if page.HasMore {
+		last, _, err := client.StackTrace(threadID, max(page.TotalFrames-2, 0), 2)
+		if err != nil {
+			return
+		}
+		outer = last
+	}
+	for i := len(outer) - 1; i >= 0; i-- {
+		if outer[i].Name != "runtime.goexit" {
+			entry["startFunction"] = outer[i].Name
+			break
+		}
+	}
+	entry["totalFrames"] = page.TotalFrames
+}
+
+// handleDebugGoroutineStack returns the stack of any goroutine, not only the
+// one the program stopped on, a page at a time
+func (s *Server) handleDebugGoroutineStack(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if session.Language != types.LanguageGo {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"debug_goroutine_stack only works with Go (Delve) sessions; use debug_frame with a threadId for other languages. "+
+				"Current session language: %s.", session.Language)), nil
+	}
+
+	id, err := requireInt(request, "goroutineId")
+	if err != nil {
+		return mcp.NewToolResultError(errors.MissingParameter("goroutineId", "Provide a goroutine id from debug_goroutines.").Error()), nil
+	}
+	startFrame := 0
+	if n, err := requireInt(request, "startFrame"); err == nil {
+		if n < 0 {
+			return mcp.NewToolResultError(errors.InvalidParameter("startFrame", n, "a frame index of 0 (innermost) or more").Error()), nil
+		}
+		startFrame = n
+	}
+	levels := defaultGoroutineStackDepth
+	if n, err := requireInt(request, "maxStackDepth"); err == nil {
+		if n < 1 {
+			return mcp.NewToolResultError(errors.InvalidParameter("maxStackDepth", n, "a number of frames of 1 or more").Error()), nil
+		}
+		levels = n
+	}
+
+	page, err := client.StackTracePage(id, startFrame, levels)
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter, fmt.Sprintf("failed to get the stack of goroutine %d", id),
+			"Use debug_goroutines to list goroutine ids; goroutines that have exited have no stack.", err).Error()), nil
+	}
+
+	frames := make([]map[string]interface{}, len(page.Frames))
+	for i, f := range page.Frames {
+		frames[i] = frameInfo(f)
+	}
+	return jsonResult(map[string]interface{}{
+		"sessionId":   session.ID,
+		"goroutineId": id,
+		"startFrame":  startFrame,
+		"frames":      frames,
+		"totalFrames": page.TotalFrames,
+		"hasMore":     page.HasMore,
+	})
+}
@@ -3,9 +3,14 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"log"
 	"os/exec"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-dap"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -14,6 +19,7 @@ import (
 	internaldap "github.com/ctagard/dap-mcp/internal/dap"
 	"github.com/ctagard/dap-mcp/internal/errors"
 	"github.com/ctagard/dap-mcp/internal/launchconfig"
+	"github.com/ctagard/dap-mcp/internal/version"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
 
@@ -21,6 +27,9 @@ import (
 
 func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Check if this is a config-based launch
+	if compoundName, _ := request.RequireString("compoundName"); compoundName != "" {
+		return s.handleCompoundLaunch(ctx, request, compoundName)
+	}
 	configName, _ := request.RequireString("configName")
 	if configName != "" {
 		return s.handleConfigBasedLaunch(ctx, request, configName)
@@ -30,21 +39,67 @@ func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequ
 	langStr, err := request.RequireString("language")
 	if err != nil {
 		return mcp.NewToolResultError(errors.MissingParameter("language",
-			"Specify the programming language: 'go', 'python', 'javascript', 'typescript', 'c', or 'rust'. Alternatively, use configName to load from launch.json.").Error()), nil
+			"Specify the programming language: 'go', 'python', 'javascript', 'typescript', 'c', 'cpp', 'rust', 'swift', 'elixir', or 'native' for any other compiled binary. Alternatively, use configName to load from launch.json.").Error()), nil
 	}
 
 	program, err := request.RequireString("program")
 	if err != nil {
 		return mcp.NewToolResultError(errors.MissingParameter("program",
-			"Specify the path to the program to debug. For Go: path to main package directory. For Python/JS: path to the script file. Alternatively, use configName to load from launch.json.").Error()), nil
+			"Specify the path to the program to debug. For Go: path to main package directory. For Python/JS: path to the script file. For Elixir: a script or mix task name (e.g. 'test'). Alternatively, use configName to load from launch.json.").Error()), nil
 	}
 
 	lang := types.Language(langStr)
 
+	// Native targets may pick their debugger explicitly (lldb or gdb)
+	debugger, _ := request.RequireString("debugger")
+	if debugger != "" && debugger != "lldb" && debugger != "gdb" {
+		return mcp.NewToolResultError(errors.InvalidParameter("debugger", debugger,
+			"'lldb' or 'gdb'").Error()), nil
+	}
+
+	restartOnExit := request.GetBool("restartOnExit", false)
+	maxRestarts := defaultMaxRestarts
+	if n, err := requireInt(request, "maxRestarts"); err == nil {
+		if n < 1 || n > maxRestartsLimit {
+			return mcp.NewToolResultError(errors.InvalidParameter("maxRestarts", n,
+				fmt.Sprintf("a number from 1 to %d", maxRestartsLimit)).Error()), nil
+		}
+		maxRestarts = n
+	}
+
+	var programArgs []string
+	if argsStr, err := request.RequireString("args"); err == nil && argsStr != "" {
+		programArgs, err = parseProgramArgs(argsStr)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	verifyBreakpoints := request.GetBool("verifyBreakpoints", false)
+
 	// Get the adapter for this language
-	adapter, err := s.adapterReg.Get(lang)
+	adapter, err := s.adapterReg.GetWithDebugger(lang, debugger)
+	if err != nil {
+		if debugger != "" {
+			return mcp.NewToolResultError(errors.InvalidParameter("debugger", debugger,
+				"no debugger (a debugger can only be chosen for c, cpp, rust, or native sessions)").Error()), nil
+		}
+		return mcp.NewToolResultError(errors.AdapterNotSupported(langStr, []string{"go", "python", "javascript", "typescript", "c", "cpp", "rust", "swift", "elixir", "native"}).Error()), nil
+	}
+
+	target, _ := request.RequireString("target")
+	if targetErr := checkTarget(adapter, lang, target); targetErr != nil {
+		return mcp.NewToolResultError(targetErr.Error()), nil
+	}
+
+	// Breakpoints to set during the configuration phase, before the program runs
+	launchBreakpoints, err := s.parseLaunchBreakpointParams(request, adapter)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sourceMap, err := parseSourceMapParam(request)
 	if err != nil {
-		return mcp.NewToolResultError(errors.AdapterNotSupported(langStr, []string{"go", "python", "javascript", "typescript", "c", "rust"}).Error()), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	// Create a new session
@@ -52,17 +107,35 @@ func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequ
 	if err != nil {
 		return mcp.NewToolResultError(errors.SessionLimitReached(10).Error()), nil // Uses default max; ideally would get actual max
 	}
+	_ = s.sessionManager.SetSessionDebugger(session.ID, nativeDebugger(adapter))
+	session.SetLaunchRequest(request.GetArguments())
 
 	// Build launch arguments from request
 	args := make(map[string]interface{})
-	if cwd, err := request.RequireString("cwd"); err == nil {
+	if programArgs != nil {
+		args["args"] = programArgs
+	}
+	cwd, _ := request.RequireString("cwd")
+	if cwd != "" {
 		args["cwd"] = cwd
 	}
+	workspace, _ := request.RequireString("workspace")
+	session.SetSourceRoot(sourceRoot(cwd, workspace, program))
 	if stopOnEntry := request.GetBool("stopOnEntry", false); stopOnEntry {
 		args["stopOnEntry"] = true
 	}
+	if buildFlags, err := request.RequireString("buildFlags"); err == nil {
+		args["buildFlags"] = buildFlags
+	}
+	if sourceMap != nil {
+		args["sourceMap"] = sourceMap
+	}
+	if justMyCode, ok := request.GetArguments()["justMyCode"].(bool); ok {
+		// Kept on the session so debug_set_debug_options knows what is in effect
+		session.SetDebugOption("justMyCode", justMyCode)
+	}
 	// Browser debugging options
-	if target, err := request.RequireString("target"); err == nil {
+	if target != "" {
 		args["target"] = target
 	}
 	if webRoot, err := request.RequireString("webRoot"); err == nil {
@@ -77,6 +150,8 @@ func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequ
 		args["python"] = python     // VS Code style takes precedence
 		args["pythonPath"] = python // Also set debugpy style
 	}
+	defaultEnv, _ := s.sessionDefaultEnv(lang, adapter, nil)
+	applyDefaultEnv(args, defaultEnv)
 
 	// Spawn the debug adapter if allowed
 	if !s.config.CanSpawn() {
@@ -88,6 +163,10 @@ func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequ
 	client, cmd, err := adapters.SpawnAndConnect(ctx, adapter, program, args)
 	if err != nil {
 		_ = s.sessionManager.TerminateSession(session.ID, false)
+		// Connection and initialize failures already carry the adapter's stderr and exit state
+		if debugErr, ok := err.(*errors.DebugError); ok {
+			return mcp.NewToolResultError(debugErr.Error()), nil
+		}
 		return mcp.NewToolResultError(errors.AdapterSpawnFailed(langStr, err).Error()), nil
 	}
 
@@ -96,42 +175,26 @@ func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	_ = s.sessionManager.SetSessionClient(session.ID, client)
+	s.configureClient(session, client, adapter)
 
-	// Initialize the debug adapter
-	_, err = client.Initialize("dap-mcp", "DAP-MCP Server")
-	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(errors.DAPInitFailed(err).Error()), nil
-	}
-
-	// Launch the program asynchronously - debugpy won't respond until after configurationDone
-	launchArgs := adapter.BuildLaunchArgs(program, args)
-	launchRespCh, err := client.LaunchAsync(launchArgs)
-	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(errors.DAPLaunchFailed(program, err).Error()), nil
-	}
-
-	// Wait for initialized event
-	if err := client.WaitInitialized(10 * time.Second); err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(errors.DAPTimeout("waiting for initialized event", 10).Error()), nil
-	}
+	// Recorded breakpoints are set by the launch sequence (and on restarts)
+	launchBreakpoints.record(session)
 
-	// Signal configuration done - debugpy needs this before it will send launch response
-	if err := client.ConfigurationDone(); err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, "configuration done failed", "The debug adapter rejected the configuration. Try launching with simpler options.", err).Error()), nil
+	if restartOnExit {
+		s.watchForRestart(session.ID, client, &restartPolicy{
+			adapter:     adapter,
+			program:     program,
+			args:        args,
+			maxRestarts: maxRestarts,
+		})
 	}
 
-	// Now wait for the launch response
-	_, err = client.WaitForLaunchResponse(launchRespCh, 10*time.Second)
-	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(errors.DAPLaunchFailed(program, err).Error()), nil
+	entry, debugErr := s.runLaunchSequence(session, client, adapter, program, args)
+	if debugErr != nil {
+		return s.launchFailed(session.ID, debugErr.Error())
 	}
 
-	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
+	_ = s.sessionManager.UpdateSessionStatus(session.ID, launchedStatus(entry))
 
 	result := map[string]interface{}{
 		"sessionId": session.ID,
@@ -139,13 +202,31 @@ func (s *Server) handleDebugLaunch(ctx context.Context, request mcp.CallToolRequ
 		"language":  string(lang),
 		"program":   program,
 	}
-	if cmd != nil && cmd.Process != nil {
-		result["pid"] = cmd.Process.Pid
+	entry.apply(result)
+	if restartOnExit {
+		result["restartOnExit"] = true
+		result["maxRestarts"] = maxRestarts
 	}
+	if verifyBreakpoints && len(launchBreakpoints.source) > 0 {
+		// Adapters often verify breakpoints only once the code is loaded
+		waitForBreakpointVerification(client, breakpointVerifyTimeout)
+		result["breakpointReport"] = breakpointReport(client.Breakpoints(), session.AllSourceBreakpoints(), session.AdapterSourcePaths())
+	}
+	s.addProcessInfo(result, session.ID, cmd, client)
 
 	return jsonResult(result)
 }
 
+// Timeouts for the attach handshake
+const (
+	// attachResponseTimeout bounds the wait for an adapter to attach
+	attachResponseTimeout = 10 * time.Second
+	// defaultWaitForTimeout and maxWaitForTimeout bound the wait for a
+	// process to start when attaching with waitFor
+	defaultWaitForTimeout = 60 * time.Second
+	maxWaitForTimeout     = 10 * time.Minute
+)
+
 func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	langStr, err := request.RequireString("language")
 	if err != nil {
@@ -159,7 +240,22 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 
 	lang := types.Language(langStr)
 
-	adapter, err := s.adapterReg.Get(lang)
+	debugger, _ := request.RequireString("debugger")
+	if debugger != "" && debugger != "lldb" && debugger != "gdb" {
+		return mcp.NewToolResultError(errors.InvalidParameter("debugger", debugger,
+			"'lldb' or 'gdb'").Error()), nil
+	}
+
+	adapter, err := s.adapterReg.GetWithDebugger(lang, debugger)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	target, _ := request.RequireString("target")
+	if targetErr := checkTarget(adapter, lang, target); targetErr != nil {
+		return mcp.NewToolResultError(targetErr.Error()), nil
+	}
+	sourceMap, err := parseSourceMapParam(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -168,6 +264,8 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	_ = s.sessionManager.SetSessionAttached(session.ID)
+	_ = s.sessionManager.SetSessionDebugger(session.ID, nativeDebugger(adapter))
 
 	// Get connection details
 	host := "127.0.0.1"
@@ -175,10 +273,47 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 		host = h
 	}
 
-	port, err := request.RequireFloat("port")
-	if err != nil {
+	// Go and stdio-only adapters (lldb-dap, gdb) can attach by PID alone: the
+	// adapter is spawned locally instead of dialing an existing one
+	port, portErr := requireInt(request, "port")
+	pid, pidErr := requireInt(request, "pid")
+
+	// lldb can instead wait for a process with the program's name to start
+	program, _ := request.RequireString("program")
+	waitFor := request.GetBool("waitFor", false)
+	attachTimeout := attachResponseTimeout
+	if waitFor {
+		if !adapters.SupportsWaitFor(adapter) {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			return mcp.NewToolResultError(errors.InvalidParameter("waitFor", true,
+				"false: waiting for a process to start needs the lldb debugger, used for c, cpp, rust, swift, and native").Error()), nil
+		}
+		if program == "" {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			return mcp.NewToolResultError(errors.MissingParameter("program",
+				"waitFor attaches to the next process started with this name, e.g. \"server\" or \"/usr/local/bin/server\".").Error()), nil
+		}
+		if pidErr == nil {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			return mcp.NewToolResultError(errors.InvalidParameter("pid", pid,
+				"omitted with waitFor: either attach to a running process by pid, or wait for one by name").Error()), nil
+		}
+		attachTimeout = defaultWaitForTimeout
+		if t, err := requireNumber(request, "timeout"); err == nil && t > 0 {
+			attachTimeout = min(time.Duration(t*float64(time.Second)), maxWaitForTimeout)
+		}
+	}
+	localAttach := (lang == types.LanguageGo || adapter.RequiresSpawn()) && portErr != nil && (pidErr == nil || waitFor)
+
+	// Browsers started with --remote-debugging-port default to 9222
+	browserTarget := target == adapters.TargetChrome || target == adapters.TargetEdge
+	if browserTarget && portErr != nil {
+		port, portErr = 9222, nil
+	}
+
+	if portErr != nil && !localAttach {
 		_ = s.sessionManager.TerminateSession(session.ID, false)
-		return mcp.NewToolResultError("port is required for attach"), nil
+		return mcp.NewToolResultError("port is required for attach (for Go and native languages, provide pid instead to attach to a local process)"), nil
 	}
 
 	// Build attach args early to check target type
@@ -186,14 +321,21 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 		"host": host,
 		"port": port,
 	}
-	if pid, err := request.RequireFloat("pid"); err == nil {
+	if pidErr == nil {
 		args["pid"] = pid
 	}
+	if program != "" {
+		args["program"] = program
+	}
+	if waitFor {
+		args["waitFor"] = true
+	}
+	if sourceMap != nil {
+		args["sourceMap"] = sourceMap
+	}
 
 	// Browser debugging options
-	target := ""
-	if t, err := request.RequireString("target"); err == nil {
-		target = t
+	if target != "" {
 		args["target"] = target
 	}
 	if url, err := request.RequireString("url"); err == nil {
@@ -202,19 +344,49 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 	if webRoot, err := request.RequireString("webRoot"); err == nil {
 		args["webRoot"] = webRoot
 	}
+	// Only a spawned adapter takes the default environment; the debuggee is already running
+	if defaultEnv, _ := s.sessionDefaultEnv(lang, adapter, nil); len(defaultEnv) > 0 {
+		args[adapters.DefaultEnvArg] = defaultEnv
+	}
 
 	var client *internaldap.Client
 	var address string
+	var attachedTab *adapters.BrowserTab
+	var candidateTabs []adapters.BrowserTab
 
 	// For browser targets (chrome/edge), we need to spawn vscode-js-debug first
 	// because Chrome speaks CDP (Chrome DevTools Protocol), not DAP
-	if target == "chrome" || target == "edge" {
+	if browserTarget {
 		// Check if spawning is allowed (needed for vscode-js-debug)
 		if !s.config.CanSpawn() {
 			_ = s.sessionManager.TerminateSession(session.ID, false)
 			return mcp.NewToolResultError("spawning debug adapters is not allowed (required for browser attach)"), nil
 		}
 
+		// Check the browser is reachable and pick the tab before spawning the adapter
+		tabs, err := adapters.ListBrowserTabs(ctx, host, port)
+		if err != nil {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			return mcp.NewToolResultError(errors.BrowserNotReachable(target, host, port, err).Error()), nil
+		}
+		candidateTabs = tabs
+		if url, ok := args["url"].(string); ok && url != "" {
+			exact, matches := adapters.MatchBrowserTabs(tabs, url)
+			switch {
+			case exact != nil:
+				attachedTab = exact
+				args["url"] = exact.URL
+			case len(matches) > 0:
+				// Not an exact URL: let vscode-js-debug pick the first tab matching the filter
+				delete(args, "url")
+				args["urlFilter"] = url
+				attachedTab = &matches[0]
+			default:
+				_ = s.sessionManager.TerminateSession(session.ID, false)
+				return mcp.NewToolResultError(errors.BrowserTabNotFound(url, tabURLs(tabs)).Error()), nil
+			}
+		}
+
 		// Spawn vscode-js-debug as the DAP-to-CDP translator
 		// We pass empty program since we're attaching, not launching
 		var cmd *exec.Cmd
@@ -231,13 +403,32 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 		// Connect to vscode-js-debug (not Chrome directly)
 		client, err = adapters.Connect(address, 20)
 		if err != nil {
-			_ = s.sessionManager.TerminateSession(session.ID, true)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to connect to adapter: %v", err)), nil
+			return s.launchFailed(session.ID, fmt.Sprintf("failed to connect to adapter: %v", err))
+		}
+	} else if localAttach {
+		// Spawn the adapter ourselves; the attach request then targets the PID
+		if !s.config.CanSpawn() {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			return mcp.NewToolResultError(errors.PermissionDenied("spawn", string(s.config.Mode)).Error()), nil
+		}
+
+		var cmd *exec.Cmd
+		client, cmd, err = adapters.SpawnAndConnect(ctx, adapter, "", args)
+		if err != nil {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			if debugErr, ok := err.(*errors.DebugError); ok {
+				return mcp.NewToolResultError(debugErr.Error()), nil
+			}
+			return mcp.NewToolResultError(errors.AdapterSpawnFailed(langStr, err).Error()), nil
+		}
+
+		if cmd != nil && cmd.Process != nil {
+			_ = s.sessionManager.SetSessionProcess(session.ID, cmd, cmd.Process.Pid)
 		}
 	} else {
-		// For Node.js attach, connect directly to the debug port
-		// Node.js with --inspect speaks DAP-compatible protocol
-		address = fmt.Sprintf("%s:%d", host, int(port))
+		// Connect directly to the debug port: Node.js with --inspect speaks a
+		// DAP-compatible protocol, and debugpy --listen serves its adapter there
+		address = fmt.Sprintf("%s:%d", host, port)
 		client, err = adapters.Connect(address, 10)
 		if err != nil {
 			_ = s.sessionManager.TerminateSession(session.ID, false)
@@ -246,42 +437,48 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	_ = s.sessionManager.SetSessionClient(session.ID, client)
+	s.configureClient(session, client, adapter)
 
 	// Initialize the DAP session
-	_, err = client.Initialize("dap-mcp", "DAP-MCP Server")
+	_, err = client.Initialize(internaldap.ClientID, internaldap.ClientName)
 	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize: %v", err)), nil
+		return s.launchFailed(session.ID, fmt.Sprintf("failed to initialize: %v", err))
 	}
 
+	runSetupCommands(client, adapter, args)
+
 	// Build and send attach request
 	attachArgs := adapter.BuildAttachArgs(args)
 
-	// For browser attach, use async pattern like launch does
-	if target == "chrome" || target == "edge" {
+	// For browser, local PID, and debugpy attach, use async pattern like
+	// launch does: debugpy answers attach only after configurationDone
+	if browserTarget || localAttach || lang == types.LanguagePython {
 		attachRespCh, err := client.AttachAsync(attachArgs)
 		if err != nil {
-			_ = s.sessionManager.TerminateSession(session.ID, true)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to attach: %v", err)), nil
+			return s.launchFailed(session.ID, fmt.Sprintf("failed to attach: %v", err))
 		}
 
-		// Wait for initialized event
-		if err := client.WaitInitialized(10 * time.Second); err != nil {
-			_ = s.sessionManager.TerminateSession(session.ID, true)
-			return mcp.NewToolResultError(fmt.Sprintf("failed waiting for initialized: %v", err)), nil
+		// Wait for initialized event; lldb-dap only sends it once a waitFor
+		// attach found the process
+		if err := client.WaitInitialized(attachTimeout); err != nil {
+			if waitFor {
+				return s.launchFailed(session.ID, errors.ProcessNotStarted(program, attachTimeout, err).Error())
+			}
+			return s.launchFailed(session.ID, fmt.Sprintf("failed waiting for initialized: %v", err))
 		}
 
 		// Signal configuration done
 		if err := client.ConfigurationDone(); err != nil {
-			_ = s.sessionManager.TerminateSession(session.ID, true)
-			return mcp.NewToolResultError(fmt.Sprintf("configuration failed: %v", err)), nil
+			return s.launchFailed(session.ID, fmt.Sprintf("configuration failed: %v", err))
 		}
 
 		// Wait for attach response
-		_, err = client.WaitForAttachResponse(attachRespCh, 10*time.Second)
+		_, err = client.WaitForAttachResponse(attachRespCh, attachTimeout)
 		if err != nil {
-			_ = s.sessionManager.TerminateSession(session.ID, true)
-			return mcp.NewToolResultError(fmt.Sprintf("attach failed: %v", err)), nil
+			if waitFor {
+				return s.launchFailed(session.ID, errors.ProcessNotStarted(program, attachTimeout, err).Error())
+			}
+			return s.launchFailed(session.ID, fmt.Sprintf("attach failed: %v", err))
 		}
 	} else {
 		// For Node.js, use synchronous attach
@@ -299,11 +496,52 @@ func (s *Server) handleDebugAttach(ctx context.Context, request mcp.CallToolRequ
 
 	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
 
-	return jsonResult(map[string]interface{}{
+	result := map[string]interface{}{
 		"sessionId": session.ID,
 		"status":    "attached",
 		"language":  string(lang),
-	})
+	}
+	if localAttach && !waitFor {
+		result["pid"] = pid
+	}
+	if waitFor {
+		// Report the process that started, as the adapter described it
+		found := map[string]interface{}{"name": program}
+		if info := client.ProcessInfo(); info != nil {
+			if info.Name != "" {
+				found["name"] = info.Name
+			}
+			if info.PID > 0 {
+				found["pid"] = info.PID
+				result["pid"] = info.PID
+			}
+		}
+		result["waitedFor"] = program
+		result["process"] = found
+		result["message"] = fmt.Sprintf("Process %v started and was attached to", found["name"])
+	}
+	if attachedTab != nil {
+		result["tab"] = map[string]interface{}{"title": attachedTab.Title, "url": attachedTab.URL}
+	}
+	// List the tabs when the url didn't identify one exactly, so the right one can be chosen
+	if browserTarget && (attachedTab == nil || args["urlFilter"] != nil) {
+		tabs := make([]map[string]interface{}, len(candidateTabs))
+		for i, tab := range candidateTabs {
+			tabs[i] = map[string]interface{}{"title": tab.Title, "url": tab.URL}
+		}
+		result["tabs"] = tabs
+	}
+
+	return jsonResult(result)
+}
+
+// tabURLs returns the URLs of browser tabs, for error reports
+func tabURLs(tabs []adapters.BrowserTab) []string {
+	urls := make([]string, len(tabs))
+	for i, tab := range tabs {
+		urls[i] = tab.URL
+	}
+	return urls
 }
 
 func (s *Server) handleDebugDisconnect(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -314,14 +552,22 @@ func (s *Server) handleDebugDisconnect(ctx context.Context, request mcp.CallTool
 
 	terminateDebuggee := request.GetBool("terminateDebuggee", false)
 
-	if err := s.sessionManager.TerminateSession(sessionID, terminateDebuggee); err != nil {
+	// An intentional disconnect must not look like a crash to restartOnExit
+	s.stopWatchingForRestart(sessionID)
+
+	adapterGone, err := s.sessionManager.DisconnectSession(sessionID, terminateDebuggee)
+	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return jsonResult(map[string]interface{}{
+	result := map[string]interface{}{
 		"sessionId": sessionID,
 		"status":    "disconnected",
-	})
+	}
+	if adapterGone {
+		result["note"] = "The debug adapter had already exited; the session was cleaned up without a DAP disconnect."
+	}
+	return jsonResult(result)
 }
 
 func (s *Server) handleDebugListSessions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -329,15 +575,25 @@ func (s *Server) handleDebugListSessions(ctx context.Context, request mcp.CallTo
 
 	result := make([]map[string]interface{}, len(sessions))
 	for i, session := range sessions {
+		info := session.GetInfo()
 		result[i] = map[string]interface{}{
 			"sessionId": session.ID,
 			"language":  string(session.Language),
-			"status":    string(session.Status),
+			"status":    string(info.Status),
 			"program":   session.Program,
 		}
 		if session.PID > 0 {
 			result[i]["pid"] = session.PID
 		}
+		if info.DebuggeePID > 0 {
+			result[i]["debuggeePid"] = info.DebuggeePID
+		}
+		if info.Restarts > 0 {
+			result[i]["restarts"] = info.Restarts
+		}
+		if info.Attached {
+			result[i]["attached"] = true
+		}
 	}
 
 	response := map[string]interface{}{
@@ -346,7 +602,7 @@ func (s *Server) handleDebugListSessions(ctx context.Context, request mcp.CallTo
 
 	// Include version and update info
 	if s.versionChecker != nil {
-		response["version"] = s.versionChecker.GetUpdateInfo().CurrentVersion
+		response["version"] = version.Version
 		if info := s.versionChecker.GetUpdateInfo(); info != nil && info.UpdateAvailable {
 			response["update_available"] = map[string]interface{}{
 				"latest_version": info.LatestVersion,
@@ -363,14 +619,15 @@ func (s *Server) handleDebugListSessions(ctx context.Context, request mcp.CallTo
 
 // handleDebugStep consolidates step_over, step_into, step_out into one tool with type parameter
 func (s *Server) handleDebugStep(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	_, client, err := s.getSessionClient(request)
+	session, client, err := s.getSessionClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	threadID, err := request.RequireFloat("threadId")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	threadID, ok := requestThread(request, session, client)
+	if !ok {
+		return mcp.NewToolResultError(errors.MissingParameter("threadId",
+			"No thread has stopped to default to. Pass a threadId from debug_threads, or select one with debug_focus.").Error()), nil
 	}
 
 	stepType, err := request.RequireString("type")
@@ -401,13 +658,114 @@ func (s *Server) handleDebugStep(ctx context.Context, request mcp.CallToolReques
 	})
 }
 
+// handleDebugCapabilities returns the capabilities the adapter reported in its initialize response
+func (s *Server) handleDebugCapabilities(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(map[string]interface{}{
+		"sessionId":    session.ID,
+		"language":     string(session.Language),
+		"capabilities": client.Capabilities(),
+	})
+}
+
+// handleDebugAdapterLog returns the stderr captured from a session's debug adapter
+func (s *Server) handleDebugAdapterLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID, err := request.RequireString("sessionId")
+	if err != nil {
+		return mcp.NewToolResultError(errors.MissingParameter("sessionId", "Provide the sessionId returned from debug_launch or debug_attach. Use debug_list_sessions to see active sessions.").Error()), nil
+	}
+
+	session, err := s.sessionManager.GetSession(sessionID)
+	if err != nil {
+		return mcp.NewToolResultError(errors.SessionNotFound(sessionID).Error()), nil
+	}
+
+	captured := session.AdapterLog()
+	if captured == nil {
+		return jsonResult(map[string]interface{}{
+			"sessionId": session.ID,
+			"captured":  false,
+			"lines":     []string{},
+			"note":      "No adapter output was captured. The adapter was not spawned by this server (e.g. attach by port).",
+		})
+	}
+
+	lines := captured.Lines()
+	total := len(lines)
+	if n, err := requireInt(request, "lines"); err == nil && n > 0 && n < total {
+		lines = lines[total-n:]
+	}
+
+	result := map[string]interface{}{
+		"sessionId":  session.ID,
+		"captured":   true,
+		"lines":      lines,
+		"totalLines": total,
+	}
+	if dropped := captured.Dropped(); dropped > 0 {
+		result["droppedLines"] = dropped
+	}
+	return jsonResult(result)
+}
+
+// handleDebugThreads lists the session's threads with their lifecycle status
+func (s *Server) handleDebugThreads(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	includeExited := request.GetBool("includeExited", true)
+
+	// Refresh from the adapter; thread events alone miss threads that existed before attach
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+	}
+	if _, err := client.Threads(); err != nil {
+		result["note"] = fmt.Sprintf("threads request failed (%v); showing threads known from thread events", err)
+	}
+
+	threads := make([]map[string]interface{}, 0)
+	counts := map[string]int{}
+	for _, state := range client.ThreadStates() {
+		counts[state.Status]++
+		if state.Status == internaldap.ThreadExited && !includeExited {
+			continue
+		}
+		thread := map[string]interface{}{
+			"id":     state.ID,
+			"status": state.Status,
+		}
+		if state.Name != "" {
+			thread["name"] = state.Name
+		}
+		if !state.ExitedAt.IsZero() {
+			thread["exitedAt"] = state.ExitedAt.Format(time.RFC3339)
+		}
+		threads = append(threads, thread)
+	}
+
+	result["threads"] = threads
+	result["counts"] = counts
+	return jsonResult(result)
+}
+
 // handleDebugEvaluate consolidates single and batch expression evaluation
 func (s *Server) handleDebugEvaluate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Paging through the children of an earlier result only reads variables
+	if ref, err := requireInt(request, "variablesReference"); err == nil && ref > 0 {
+		return s.handleVariablesPage(request, ref)
+	}
+
 	if !s.config.CanEvaluate() {
 		return mcp.NewToolResultError(errors.PermissionDenied("evaluate", string(s.config.Mode)).Error()), nil
 	}
 
-	_, client, err := s.getSessionClient(request)
+	session, client, err := s.getStoppedSessionClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -420,23 +778,30 @@ func (s *Server) handleDebugEvaluate(ctx context.Context, request mcp.CallToolRe
 			return mcp.NewToolResultError(errors.InvalidJSON("expressions", err, `["x", "y", "len(arr)"]`).Error()), nil
 		}
 
-		frameID := 0
-		if f, err := request.RequireFloat("frameId"); err == nil {
-			frameID = int(f)
-		} else {
-			// Try to get the top frame automatically
-			threads, err := client.Threads()
-			if err == nil && len(threads) > 0 {
-				frames, _, err := client.StackTrace(threads[0].Id, 0, 1)
-				if err == nil && len(frames) > 0 {
-					frameID = frames[0].Id
-				}
-			}
-		}
+		// Default to the focused frame
+		frameID, _ := requestFrame(request, session, client)
 
+		rawResult := request.GetBool("rawResult", false)
+		defaultContext := s.defaultEvaluationContext(session)
 		results := make([]map[string]interface{}, len(expressions))
+		mode := evaluateModeFull
 		for i, expr := range expressions {
-			result, err := client.Evaluate(expr, frameID, "watch")
+			evalContext, exprMode, debugErr := s.evaluationMode(session, expr, defaultContext)
+			mode = exprMode
+			if debugErr != nil {
+				results[i] = map[string]interface{}{
+					"expression": expr,
+					"error":      debugErr.Error(),
+				}
+				continue
+			}
+
+			result, err := client.Evaluate(expr, frameID, evalContext)
+			if err == nil && !rawResult {
+				if resultErr := evaluationError(session, result); resultErr != nil {
+					err = errors.EvaluationResultError(expr, resultErr)
+				}
+			}
 			if err != nil {
 				results[i] = map[string]interface{}{
 					"expression": expr,
@@ -445,16 +810,19 @@ func (s *Server) handleDebugEvaluate(ctx context.Context, request mcp.CallToolRe
 			} else {
 				results[i] = map[string]interface{}{
 					"expression":         expr,
-					"result":             result.Result,
 					"type":               result.Type,
 					"variablesReference": result.VariablesReference,
 				}
+				s.setValue(results[i], "result", result.Result)
+				addChildCounts(results[i], result.IndexedVariables, result.NamedVariables)
+				addPresentationHint(results[i], result.PresentationHint)
 			}
 		}
 
 		return jsonResult(map[string]interface{}{
-			"evaluations": results,
-			"frameId":     frameID,
+			"evaluations":  results,
+			"frameId":      frameID,
+			"evaluateMode": mode,
 		})
 	}
 
@@ -466,30 +834,214 @@ func (s *Server) handleDebugEvaluate(ctx context.Context, request mcp.CallToolRe
 	}
 
 	frameID := 0
-	if f, err := request.RequireFloat("frameId"); err == nil {
-		frameID = int(f)
+	if f, err := requireInt(request, "frameId"); err == nil {
+		frameID = f
+	} else if _, f, ok := session.Focus(); ok {
+		// A frame selected with debug_focus; otherwise the adapter's default
+		frameID = f
 	}
 
-	evalContext := "watch"
-	if c, err := request.RequireString("context"); err == nil {
+	evalContext := s.defaultEvaluationContext(session)
+	if c, err := request.RequireString("context"); err == nil && c != "" {
 		evalContext = c
 	}
 
+	// Debuggers run statements and commands in the repl context
+	if evalContext == "repl" && s.config.EvaluatesReadOnly() {
+		return mcp.NewToolResultError(errors.PermissionDenied("repl evaluation", string(s.config.Mode)).Error()), nil
+	}
+
+	requestedContext := evalContext
+	evalContext, mode, debugErr := s.evaluationMode(session, expression, evalContext)
+	if debugErr != nil {
+		return mcp.NewToolResultError(debugErr.Error()), nil
+	}
+
 	result, err := client.Evaluate(expression, frameID, evalContext)
 	if err != nil {
 		return mcp.NewToolResultError(errors.EvaluationFailed(expression, err).Error()), nil
 	}
+	if !request.GetBool("rawResult", false) {
+		if resultErr := evaluationError(session, result); resultErr != nil {
+			return mcp.NewToolResultError(errors.EvaluationResultError(expression, resultErr).Error()), nil
+		}
+	}
 
-	return jsonResult(map[string]interface{}{
-		"result":             result.Result,
+	evalResult := map[string]interface{}{
 		"type":               result.Type,
 		"variablesReference": result.VariablesReference,
-	})
+		"evaluateMode":       mode,
+	}
+	// The clipboard context is how truncated values are fetched in full
+	if requestedContext == "clipboard" {
+		evalResult["result"] = result.Result
+	} else {
+		s.setValue(evalResult, "result", result.Result)
+	}
+	addChildCounts(evalResult, result.IndexedVariables, result.NamedVariables)
+	addPresentationHint(evalResult, result.PresentationHint)
+
+	return jsonResult(evalResult)
+}
+
+// Maximum number of children returned by a single page of an evaluated result
+const (
+	defaultPageCount = 100
+	maxPageCount     = 1000
+)
+
+// handleVariablesPage returns one page of the children of a variablesReference
+// returned by an earlier evaluation, so large collections can be read in chunks.
+func (s *Server) handleVariablesPage(request mcp.CallToolRequest, variablesRef int) (*mcp.CallToolResult, error) {
+	_, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if nameContains, _ := request.RequireString("nameContains"); nameContains != "" {
+		return s.handleVariablesSearch(request, client, variablesRef, nameContains)
+	}
+
+	start := 0
+	if v, err := requireInt(request, "start"); err == nil && v > 0 {
+		start = v
+	}
+
+	count := defaultPageCount
+	if v, err := requireInt(request, "count"); err == nil && v > 0 {
+		count = v
+	}
+	if count > maxPageCount {
+		count = maxPageCount
+	}
+
+	// Paging (start/count) only applies to indexed children in DAP
+	filter := "indexed"
+	if f, err := request.RequireString("filter"); err == nil && f != "" {
+		if f != "indexed" && f != "named" {
+			return mcp.NewToolResultError(errors.InvalidParameter("filter", f, "'indexed' or 'named'").Error()), nil
+		}
+		filter = f
+	}
+
+	vars, err := client.Variables(variablesRef, filter, start, count)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get variables: %v", err)), nil
+	}
+
+	varsList := make([]map[string]interface{}, len(vars))
+	for i, v := range vars {
+		varsList[i] = s.pagedVariable(v)
+	}
+
+	result := map[string]interface{}{
+		"variablesReference": variablesRef,
+		"filter":             filter,
+		"start":              start,
+		"count":              count,
+		"variables":          varsList,
+	}
+
+	// With the totals from the scope or value that handed out the reference,
+	// whether more pages remain is known rather than guessed from a full page
+	hasMore := filter == "indexed" && len(vars) == count
+	if counts, ok := client.ChildCounts(variablesRef); ok {
+		addChildCounts(result, counts.Indexed, counts.Named)
+		total := counts.Indexed
+		if filter == "named" {
+			total = counts.Named
+		}
+		if total > 0 {
+			hasMore = start+len(vars) < total
+		}
+	}
+	result["hasMore"] = hasMore
+	if hasMore {
+		result["nextStart"] = start + len(vars)
+	}
+
+	return jsonResult(result)
+}
+
+// pagedVariable describes a child returned by paging or searching a reference
+func (s *Server) pagedVariable(v dap.Variable) map[string]interface{} {
+	variable := map[string]interface{}{
+		"name":               v.Name,
+		"type":               v.Type,
+		"variablesReference": v.VariablesReference,
+	}
+	s.setValue(variable, "value", v.Value)
+	addChildCounts(variable, v.IndexedVariables, v.NamedVariables)
+	addPresentationHint(variable, v.PresentationHint)
+	return variable
+}
+
+// setValue stores a variable value or evaluation result under key, truncated to
+// maxVariableValueLength so huge strings don't flood responses. Truncated values
+// are flagged; the full value is available via debug_get_full_value, or
+// debug_evaluate with context "clipboard".
+func (s *Server) setValue(result map[string]interface{}, key, value string) {
+	value, truncated := s.truncatedValue(value)
+	result[key] = value
+	if truncated {
+		result["truncated"] = true
+	}
+}
+
+// truncatedValue cuts a value to maxVariableValueLength, reporting whether it did
+func (s *Server) truncatedValue(value string) (string, bool) {
+	limit := s.config.MaxVariableValueLength
+	if limit <= 0 || len(value) <= limit {
+		return value, false
+	}
+
+	// Don't cut a multi-byte character in half
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return fmt.Sprintf("%s...(truncated, %d bytes)", value[:limit], len(value)), true
+}
+
+// addChildCounts reports how many children a value has, so callers can page
+// through large collections instead of expanding them all at once
+func addChildCounts(result map[string]interface{}, indexed, named int) {
+	if indexed > 0 {
+		result["indexedVariables"] = indexed
+	}
+	if named > 0 {
+		result["namedVariables"] = named
+	}
+}
+
+// addPresentationHint reports how the adapter classifies a value (e.g. kind
+// "property" or "method", attributes "readOnly" or "constant", visibility
+// "private"), so methods aren't mistaken for data and read-only values for
+// settable ones
+func addPresentationHint(result map[string]interface{}, hint *dap.VariablePresentationHint) {
+	if hint == nil {
+		return
+	}
+	info := map[string]interface{}{}
+	if hint.Kind != "" {
+		info["kind"] = hint.Kind
+	}
+	if len(hint.Attributes) > 0 {
+		info["attributes"] = hint.Attributes
+	}
+	if hint.Visibility != "" {
+		info["visibility"] = hint.Visibility
+	}
+	if hint.Lazy {
+		info["lazy"] = true
+	}
+	if len(info) > 0 {
+		result["presentationHint"] = info
+	}
 }
 
 // handleDebugBreakpoints handles setting breakpoints (renamed from control_set_breakpoints)
 func (s *Server) handleDebugBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	_, client, err := s.getSessionClient(request)
+	session, client, err := s.getSessionClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -506,10 +1058,13 @@ func (s *Server) handleDebugBreakpoints(ctx context.Context, request mcp.CallToo
 
 	var bpRequests []struct {
 		Line         int    `json:"line"`
+		Column       int    `json:"column,omitempty"`
 		Condition    string `json:"condition,omitempty"`
 		HitCondition string `json:"hitCondition,omitempty"`
 		LogMessage   string `json:"logMessage,omitempty"`
 	}
+	requestedPath := path
+	path = resolveSourcePath(session, path)
 
 	if err := json.Unmarshal([]byte(bpsJSON), &bpRequests); err != nil {
 		return mcp.NewToolResultError(errors.InvalidJSON("breakpoints", err, `[{"line": 10}, {"line": 20, "condition": "x > 5"}]`).Error()), nil
@@ -520,59 +1075,301 @@ func (s *Server) handleDebugBreakpoints(ctx context.Context, request mcp.CallToo
 	}
 
 	breakpoints := make([]dap.SourceBreakpoint, len(bpRequests))
+	hasColumns := false
 	for i, bp := range bpRequests {
+		if bp.Column < 0 {
+			return mcp.NewToolResultError(errors.InvalidParameter("breakpoints", bp.Column,
+				"a column of 1 or more (the first character of the line is column 1), or no column to break on the whole line").Error()), nil
+		}
+		hasColumns = hasColumns || bp.Column > 0
 		breakpoints[i] = dap.SourceBreakpoint{
 			Line:         bp.Line,
+			Column:       bp.Column,
 			Condition:    bp.Condition,
 			HitCondition: bp.HitCondition,
 			LogMessage:   bp.LogMessage,
 		}
 	}
 
-	bps, err := client.SetBreakpoints(source, breakpoints)
+	hasConditions := false
+	for _, bp := range breakpoints {
+		if bp.Condition != "" {
+			hasConditions = true
+			break
+		}
+	}
+
+	// Map each returned breakpoint back to its request; adapters answer in request order
+	indexes := make([]int, len(breakpoints))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	var rejected []rejectedBreakpoint
+	bps, err := client.SetBreakpoints(source, breakpoints)
+	if err != nil && hasConditions {
+		// Some adapters reject the whole request over one bad condition
+		bps, indexes, rejected, err = setBreakpointsIsolatingConditions(client, source, breakpoints)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(errors.Wrap(errors.CodeBreakpointFailed, fmt.Sprintf("failed to set breakpoints in %s", path), "Ensure the file path is correct and the line numbers contain executable code.", err).Error()), nil
 	}
 
-	result := make([]map[string]interface{}, len(bps))
+	set := make([]dap.SourceBreakpoint, len(indexes))
+	for i, idx := range indexes {
+		set[i] = breakpoints[idx]
+	}
+	session.SetSourceBreakpoints(path, set)
+
+	result := make([]map[string]interface{}, len(breakpoints))
 	for i, bp := range bps {
-		result[i] = map[string]interface{}{
+		if i >= len(indexes) {
+			break
+		}
+		req := bpRequests[indexes[i]]
+		entry := map[string]interface{}{
 			"id":       bp.Id,
 			"verified": bp.Verified,
 			"line":     bp.Line,
 		}
+		if bp.Column > 0 {
+			entry["column"] = bp.Column
+		}
+		// Adapters move a column to the nearest statement, or drop it
+		if req.Column > 0 && bp.Column != req.Column {
+			entry["requestedColumn"] = req.Column
+		}
 		if bp.Message != "" {
-			result[i]["message"] = bp.Message
+			entry["message"] = bp.Message
+		}
+		if req.Condition != "" {
+			entry["condition"] = req.Condition
+		}
+		if !bp.Verified {
+			entry["reason"] = breakpointReason(bp.Message, req.Condition)
+		}
+		result[indexes[i]] = entry
+	}
+	for _, r := range rejected {
+		req := bpRequests[r.index]
+		result[r.index] = map[string]interface{}{
+			"verified":  false,
+			"line":      req.Line,
+			"condition": req.Condition,
+			"reason":    bpReasonInvalidCondition,
+			"message":   r.message,
 		}
 	}
 
-	return jsonResult(map[string]interface{}{
-		"breakpoints": result,
-	})
+	// Unverified breakpoints by requested line, for requireVerified
+	var unverifiedLines []int
+	var unverifiedMessages []string
+	for i, entry := range result {
+		if entry != nil && entry["verified"] == false {
+			message, _ := entry["message"].(string)
+			unverifiedLines = append(unverifiedLines, bpRequests[i].Line)
+			unverifiedMessages = append(unverifiedMessages, message)
+		}
+	}
+
+	// Drop slots the adapter did not answer for
+	compact := result[:0]
+	for _, entry := range result {
+		if entry != nil {
+			compact = append(compact, entry)
+		}
+	}
+
+	response := map[string]interface{}{
+		"path":        path,
+		"breakpoints": compact,
+	}
+	if path != requestedPath {
+		response["requestedPath"] = requestedPath
+	}
+	if adapterPath := recordAdapterSourcePath(session, path, bps); adapterPath != "" {
+		response["adapterPath"] = adapterPath
+	}
+	if len(rejected) > 0 {
+		response["note"] = fmt.Sprintf("The adapter rejected the whole request; %d breakpoint(s) with invalid conditions were not set. Fix the condition and set breakpoints again.", len(rejected))
+	} else if hasColumns && !client.Capabilities().SupportsBreakpointLocationsRequest {
+		// Adapters that list breakpoint locations within a line honor columns
+		response["note"] = "The adapter does not report column support (supportsBreakpointLocationsRequest), so breakpoints with a column may break anywhere on their line."
+	}
+	if len(unverifiedLines) > 0 && request.GetBool("requireVerified", s.config.RequireVerifiedBreakpoints) {
+		return mcp.NewToolResultError(errors.BreakpointsUnverified(path, unverifiedLines, unverifiedMessages).Error()), nil
+	}
+
+	return jsonResult(response)
 }
 
-// handleDebugContinue handles continuing execution (renamed from control_continue)
-func (s *Server) handleDebugContinue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// handleDebugListBreakpoints reports the current state of the session's source
+// breakpoints, including verification that arrived after they were set
+func (s *Server) handleDebugListBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(breakpointReport(client.Breakpoints(), session.AllSourceBreakpoints(), session.AdapterSourcePaths()))
+}
+
+// handleDebugBreakWhen evaluates an expression and sets a breakpoint that fires
+// the next time the expression has its current value
+func (s *Server) handleDebugBreakWhen(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.config.CanEvaluate() {
+		return mcp.NewToolResultError(errors.PermissionDenied("evaluate", string(s.config.Mode)).Error()), nil
+	}
+
 	session, client, err := s.getSessionClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	threadID, err := request.RequireFloat("threadId")
+	expression, err := request.RequireString("expression")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	path, err := request.RequireString("path")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	allContinued, err := client.Continue(int(threadID))
+	line, err := requireInt(request, "line")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("continue failed: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	requestedPath := path
+	path = resolveSourcePath(session, path)
+
+	frameID := 0
+	if f, err := requireInt(request, "frameId"); err == nil {
+		frameID = f
+	} else if _, f, ok := session.Focus(); ok {
+		frameID = f
+	}
+
+	// The adapter evaluates the condition on every hit with no read-only
+	// context to rely on, so it is checked for side effects whatever the adapter
+	if s.config.EvaluatesReadOnly() {
+		if construct := sideEffectSyntax(expression, session.Language != types.LanguagePython); construct != "" {
+			return mcp.NewToolResultError(errors.SideEffectRejected(expression, construct).Error()), nil
+		}
+	}
+
+	evaluated, err := client.Evaluate(expression, frameID, "watch")
+	if err != nil {
+		return mcp.NewToolResultError(errors.EvaluationFailed(expression, err).Error()), nil
+	}
+
+	literal, ok := "", evaluated.VariablesReference == 0
+	if ok {
+		literal, ok = conditionLiteral(session.Language, evaluated.Result, evaluated.Type)
+	}
+	if !ok {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter,
+			fmt.Sprintf("cannot build a condition from the value of %q (type %s)", expression, evaluated.Type),
+			"Only numbers, booleans, strings, and null values are supported. Write the condition yourself with debug_breakpoints, e.g. comparing a field or length.", nil).Error()), nil
+	}
+	// Parenthesized so operators binding looser than == (e.g. &, ||) apply first
+	condition := fmt.Sprintf("(%s) == %s", expression, literal)
+
+	// setBreakpoints replaces the file's breakpoints, so keep the others in place
+	breakpoints := session.SourceBreakpoints(path)
+	replaced := false
+	for i := range breakpoints {
+		if breakpoints[i].Line == line {
+			breakpoints[i].Condition = condition
+			replaced = true
+		}
+	}
+	if !replaced {
+		breakpoints = append(breakpoints, dap.SourceBreakpoint{Line: line, Condition: condition})
+	}
+
+	bps, err := client.SetBreakpoints(dap.Source{Path: path}, breakpoints)
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeBreakpointFailed, fmt.Sprintf("failed to set breakpoint at %s:%d", path, line),
+			fmt.Sprintf("The adapter may not accept the condition %q. Set it manually with debug_breakpoints.", condition), err).Error()), nil
+	}
+	session.SetSourceBreakpoints(path, breakpoints)
+
+	result := map[string]interface{}{
+		"expression": expression,
+		"condition":  condition,
+		"path":       path,
+		"line":       line,
+	}
+	if path != requestedPath {
+		result["requestedPath"] = requestedPath
+	}
+	if adapterPath := recordAdapterSourcePath(session, path, bps); adapterPath != "" {
+		result["adapterPath"] = adapterPath
+	}
+	s.setValue(result, "value", evaluated.Result)
+	for i, bp := range bps {
+		if i < len(breakpoints) && breakpoints[i].Line == line {
+			result["verified"] = bp.Verified
+			if bp.Message != "" {
+				result["message"] = bp.Message
+			}
+			if !bp.Verified {
+				result["reason"] = breakpointReason(bp.Message, condition)
+			}
+			break
+		}
+	}
+
+	return jsonResult(result)
+}
+
+// handleDebugContinue handles continuing execution (renamed from control_continue)
+func (s *Server) handleDebugContinue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	continueAll := request.GetBool("continueAll", false)
+	singleThread := request.GetBool("singleThread", false)
+
+	threadID, ok := requestThread(request, session, client)
+	if !ok && !continueAll {
+		return mcp.NewToolResultError(errors.MissingParameter("threadId",
+			"No thread has stopped to default to. Pass a threadId from debug_threads, or select one with debug_focus.").Error()), nil
+	}
+
+	if continueAll && singleThread {
+		return mcp.NewToolResultError(errors.InvalidParameter("singleThread", true,
+			"singleThread cannot be combined with continueAll").Error()), nil
+	}
+	if singleThread && !client.Capabilities().SupportsSingleThreadExecutionRequests {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter,
+			"this debug adapter does not support resuming a single thread",
+			"Omit singleThread to continue normally, or use continueAll=true to resume every thread.", nil).Error()), nil
+	}
+
+	result := map[string]interface{}{}
+	if continueAll {
+		allContinued, continued, err := continueAllThreads(client, int(threadID))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("continue failed: %v", err)), nil
+		}
+		result["allThreadsContinued"] = allContinued
+		result["continuedThreads"] = continued
+	} else {
+		allContinued, err := client.ContinueWithOptions(int(threadID), singleThread)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("continue failed: %v", err)), nil
+		}
+		result["allThreadsContinued"] = allContinued
 	}
 
 	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
 
-	return jsonResult(map[string]interface{}{
-		"allThreadsContinued": allContinued,
-	})
+	return jsonResult(result)
 }
 
 // handleDebugPause handles pausing execution (renamed from control_pause)
@@ -582,20 +1379,107 @@ func (s *Server) handleDebugPause(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	threadID, err := request.RequireFloat("threadId")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	pauseAll := request.GetBool("pauseAll", false)
+
+	threadID, ok := requestThread(request, session, client)
+	if !ok && !pauseAll {
+		return mcp.NewToolResultError(errors.MissingParameter("threadId",
+			"No thread has stopped to default to. Pass a threadId from debug_threads, or select one with debug_focus.").Error()), nil
 	}
 
-	if err := client.Pause(int(threadID)); err != nil {
+	result := map[string]interface{}{
+		"status": "paused",
+	}
+	if pauseAll {
+		allStopped, err := pauseAllThreads(client, int(threadID))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("pause failed: %v", err)), nil
+		}
+		result["allThreadsStopped"] = allStopped
+	} else if err := client.Pause(int(threadID)); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("pause failed: %v", err)), nil
 	}
 
 	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
 
-	return jsonResult(map[string]interface{}{
-		"status": "paused",
-	})
+	return jsonResult(result)
+}
+
+// continueAllThreads resumes the whole program. The first continue goes to
+// threadID (or the first thread if 0); if the adapter reports that only that
+// thread resumed, the remaining threads are continued individually.
+// Returns whether all threads are running and the IDs that were continued.
+func continueAllThreads(client *internaldap.Client, threadID int) (bool, []int, error) {
+	threads, err := client.Threads()
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get threads: %w", err)
+	}
+	if len(threads) == 0 {
+		return false, nil, errors.NoThreads()
+	}
+	if threadID == 0 {
+		threadID = threads[0].Id
+	}
+
+	allContinued, err := client.ContinueWithOptions(threadID, false)
+	if err != nil {
+		return false, nil, err
+	}
+	continued := []int{threadID}
+	if allContinued {
+		return true, continued, nil
+	}
+
+	// Non all-stop adapter: resume the other threads one by one
+	allContinued = true
+	for _, t := range threads {
+		if t.Id == threadID {
+			continue
+		}
+		if _, err := client.Continue(t.Id); err != nil {
+			allContinued = false
+			continue
+		}
+		continued = append(continued, t.Id)
+	}
+	return allContinued, continued, nil
+}
+
+// pauseAllThreads freezes the whole program. It pauses threadID (or the first
+// thread if 0) and, unless the stopped event reports that all threads stopped,
+// pauses the remaining threads individually.
+func pauseAllThreads(client *internaldap.Client, threadID int) (bool, error) {
+	threads, err := client.Threads()
+	if err != nil {
+		return false, fmt.Errorf("failed to get threads: %w", err)
+	}
+	if len(threads) == 0 {
+		return false, errors.NoThreads()
+	}
+	if threadID == 0 {
+		threadID = threads[0].Id
+	}
+
+	info, err := client.PauseAndWait(threadID, 2*time.Second)
+	if err == nil && info.AllStopped {
+		return true, nil
+	}
+	// A missing stopped event is not fatal; a rejected pause is
+	if err != nil && !stderrors.Is(err, internaldap.ErrStopTimeout) {
+		return false, err
+	}
+
+	// No all-stop confirmation: pause the other threads one by one
+	allStopped := true
+	for _, t := range threads {
+		if t.Id == threadID {
+			continue
+		}
+		if err := client.Pause(t.Id); err != nil {
+			allStopped = false
+		}
+	}
+	return allStopped, nil
 }
 
 // handleDebugSetVariable handles modifying variables (renamed from control_set_variable)
@@ -609,7 +1493,7 @@ func (s *Server) handleDebugSetVariable(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	varsRef, err := request.RequireFloat("variablesReference")
+	varsRef, err := requireInt(request, "variablesReference")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -624,7 +1508,7 @@ func (s *Server) handleDebugSetVariable(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	result, err := client.SetVariable(int(varsRef), name, value)
+	result, err := client.SetVariable(varsRef, name, value)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("set variable failed: %v", err)), nil
 	}
@@ -638,18 +1522,45 @@ func (s *Server) handleDebugSetVariable(ctx context.Context, request mcp.CallToo
 
 // Convenience Handlers
 
+// defaultSnapshotMaxThreads is the number of threads debug_snapshot expands by default
+const defaultSnapshotMaxThreads = 50
+
 func (s *Server) handleDebugSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	session, client, err := s.getSessionClient(request)
+	session, client, err := s.getStoppedSessionClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	maxStackDepth := 10
-	if d, err := request.RequireFloat("maxStackDepth"); err == nil {
-		maxStackDepth = int(d)
+	if d, err := requireInt(request, "maxStackDepth"); err == nil {
+		maxStackDepth = d
 	}
 
 	expandVariables := request.GetBool("expandVariables", true)
+	delta := request.GetBool("delta", false)
+	hideSystem := request.GetBool("hideSystemThreads", false)
+	countFrames := request.GetBool("countFrames", false)
+
+	maxBytes := 0
+	if m, err := requireInt(request, "maxBytes"); err == nil && m > 0 {
+		maxBytes = m
+	}
+
+	hiddenFrames := s.config.SnapshotHiddenFrames
+	if framesJSON, err := request.RequireString("hiddenFrames"); err == nil && framesJSON != "" {
+		if err := json.Unmarshal([]byte(framesJSON), &hiddenFrames); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("hiddenFrames", err, `["runtime.gopark", "netpoll"]`).Error()), nil
+		}
+		hideSystem = true
+	}
+
+	var scopeFilter []string
+	if scopesJSON, err := request.RequireString("scopes"); err == nil && scopesJSON != "" {
+		if err := json.Unmarshal([]byte(scopesJSON), &scopeFilter); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("scopes", err, `["Locals", "Arguments"]`).Error()), nil
+		}
+	}
+	skippedScopes := make(map[string]bool)
 
 	// Get all threads
 	threads, err := client.Threads()
@@ -659,8 +1570,8 @@ func (s *Server) handleDebugSnapshot(ctx context.Context, request mcp.CallToolRe
 
 	// Filter to specific thread if requested
 	var targetThreadID *int
-	if tid, err := request.RequireFloat("threadId"); err == nil {
-		t := int(tid)
+	if tid, err := requireInt(request, "threadId"); err == nil {
+		t := tid
 		targetThreadID = &t
 	}
 
@@ -669,27 +1580,89 @@ func (s *Server) handleDebugSnapshot(ctx context.Context, request mcp.CallToolRe
 		"status":    string(session.Status),
 	}
 
+	// Runtimes like the BEAM report thousands of threads; only expand the first few
+	maxThreads := defaultSnapshotMaxThreads
+	if m, err := requireInt(request, "maxThreads"); err == nil && m > 0 {
+		maxThreads = m
+	}
+	truncated := false
+
+	hiddenThreads := 0
+	if hideSystem && targetThreadID == nil {
+		// Threads past the first maxThreads visible ones aren't checked
+		totalThreads := len(threads)
+		var unchecked int
+		threads, hiddenThreads, unchecked = hideSystemThreads(client, threads, hiddenFrames, maxThreads)
+		snapshot["hiddenThreads"] = hiddenThreads
+		if unchecked > 0 {
+			snapshot["totalThreads"] = totalThreads
+			snapshot["threadsOmitted"] = unchecked
+			truncated = true
+		}
+	}
+
+	if targetThreadID == nil && len(threads) > maxThreads {
+		snapshot["totalThreads"] = len(threads)
+		snapshot["threadsOmitted"] = len(threads) - maxThreads
+		threads = threads[:maxThreads]
+		truncated = true
+	}
+
+	// Every snapshot records a digest so the next one can be a delta
+	diff := newSnapshotDiff(session.SnapshotDigest())
+	// Without a previous snapshot there is nothing to diff against, so return everything
+	deltaMode := delta && diff.hasBaseline()
+
 	threadsInfo := make([]map[string]interface{}, 0)
 	stacks := make(map[string]interface{})
 	scopes := make(map[string]interface{})
 	variables := make(map[string]interface{})
+	visitedThreads := make([]int, 0, len(threads))
+	unchangedThreads := 0
+
+	exitedThreads := 0
+
+	// Counting a stack the adapter gives no totalFrames for pages through it,
+	// so only the stacks of threads that stopped are counted by default
+	stoppedIDs := make(map[int]bool)
+	for _, stop := range client.StoppedThreads() {
+		stoppedIDs[stop.ThreadID] = true
+	}
 
 	for _, thread := range threads {
 		if targetThreadID != nil && thread.Id != *targetThreadID {
 			continue
 		}
-
-		threadsInfo = append(threadsInfo, map[string]interface{}{
-			"id":   thread.Id,
-			"name": thread.Name,
-		})
+		// Threads can exit between the threads response and their stack trace
+		if client.ThreadExited(thread.Id) {
+			exitedThreads++
+			continue
+		}
+		visitedThreads = append(visitedThreads, thread.Id)
+		threadChanged := diff.recordThread(thread.Id, thread.Name)
 
 		// Get stack trace
-		frames, _, err := client.StackTrace(thread.Id, 0, maxStackDepth)
+		stackPage := client.StackTracePeek
+		if countFrames || stoppedIDs[thread.Id] {
+			stackPage = client.StackTracePage
+		}
+		page, err := stackPage(thread.Id, 0, maxStackDepth)
 		if err != nil {
+			if deltaMode && !threadChanged {
+				unchangedThreads++
+				continue
+			}
+			threadsInfo = append(threadsInfo, map[string]interface{}{
+				"id":   thread.Id,
+				"name": thread.Name,
+			})
 			continue
 		}
+		frames := page.Frames
 
+		// Changed variables are listed with their thread and stack, so a
+		// delta never has scopes that no frame in it refers to
+		scopesChanged := false
 		framesList := make([]map[string]interface{}, len(frames))
 		for i, f := range frames {
 			frame := map[string]interface{}{
@@ -698,10 +1671,15 @@ func (s *Server) handleDebugSnapshot(ctx context.Context, request mcp.CallToolRe
 				"line": f.Line,
 			}
 			if f.Source != nil {
-				frame["source"] = map[string]interface{}{
+				source := map[string]interface{}{
 					"path": f.Source.Path,
 					"name": f.Source.Name,
 				}
+				// Sources without a file on disk are fetched with debug_source
+				if f.Source.SourceReference > 0 {
+					source["sourceReference"] = f.Source.SourceReference
+				}
+				frame["source"] = source
 			}
 			framesList[i] = frame
 
@@ -709,47 +1687,143 @@ func (s *Server) handleDebugSnapshot(ctx context.Context, request mcp.CallToolRe
 			if i < 3 {
 				frameScopes, err := client.Scopes(f.Id)
 				if err == nil {
-					scopesList := make([]map[string]interface{}, len(frameScopes))
-					for j, scope := range frameScopes {
-						scopesList[j] = map[string]interface{}{
+					scopesList := make([]map[string]interface{}, 0, len(frameScopes))
+					for _, scope := range frameScopes {
+						scopeInfo := map[string]interface{}{
 							"name":               scope.Name,
 							"variablesReference": scope.VariablesReference,
 						}
+						if scope.Expensive {
+							scopeInfo["expensive"] = true
+						}
 
 						// Expand variables if requested
-						if expandVariables && scope.VariablesReference > 0 && !scope.Expensive {
+						scopeChanged := false
+						expand := expandVariables && scope.VariablesReference > 0 && wantScope(scope, scopeFilter)
+						if expandVariables && !expand {
+							skippedScopes[scope.Name] = true
+						}
+						if expand {
 							vars, err := client.Variables(scope.VariablesReference, "", 0, 50)
 							if err == nil {
-								varsList := make([]map[string]interface{}, len(vars))
-								for k, v := range vars {
-									varsList[k] = map[string]interface{}{
+								varsList := make([]map[string]interface{}, 0, len(vars))
+								for _, v := range vars {
+									if !diff.recordVariable(thread.Id, i, scope.Name, v.Name, v.Type, v.Value) && deltaMode {
+										continue
+									}
+									varInfo := map[string]interface{}{
 										"name":               v.Name,
-										"value":              v.Value,
 										"type":               v.Type,
 										"variablesReference": v.VariablesReference,
 									}
+									s.setValue(varInfo, "value", v.Value)
+									addPresentationHint(varInfo, v.PresentationHint)
+									varsList = append(varsList, varInfo)
+								}
+								if len(varsList) > 0 || !deltaMode {
+									variables[fmt.Sprintf("%d", scope.VariablesReference)] = varsList
+									scopeChanged = true
 								}
-								variables[fmt.Sprintf("%d", scope.VariablesReference)] = varsList
 							}
 						}
+
+						// In delta mode, only scopes with changed variables are listed
+						if !deltaMode || scopeChanged {
+							scopesList = append(scopesList, scopeInfo)
+						}
+					}
+					if len(scopesList) > 0 || !deltaMode {
+						scopes[fmt.Sprintf("%d", f.Id)] = scopesList
+						scopesChanged = scopesChanged || len(scopesList) > 0
 					}
-					scopes[fmt.Sprintf("%d", f.Id)] = scopesList
 				}
 			}
 		}
+
+		stackChanged := diff.recordStack(thread.Id, framesList)
+		if deltaMode && !threadChanged && !stackChanged && !scopesChanged {
+			unchangedThreads++
+			continue
+		}
+		// hasMore tells a stack cut at maxStackDepth from a complete one;
+		// totalFrames is left out when the depth wasn't counted
+		threadInfo := map[string]interface{}{
+			"id":      thread.Id,
+			"name":    thread.Name,
+			"hasMore": page.HasMore,
+		}
+		if page.TotalFrames > 0 {
+			threadInfo["totalFrames"] = page.TotalFrames
+		}
+		threadsInfo = append(threadsInfo, threadInfo)
 		stacks[fmt.Sprintf("%d", thread.Id)] = framesList
 	}
 
 	snapshot["threads"] = threadsInfo
+	stops := client.StoppedThreads()
+	if len(stops) > 0 {
+		snapshot["stoppedThreads"] = stoppedThreadsList(stops)
+	}
+	if exitedThreads > 0 {
+		snapshot["exitedThreads"] = exitedThreads
+	}
 	snapshot["stacks"] = stacks
 	snapshot["scopes"] = scopes
 	if expandVariables {
 		snapshot["variables"] = variables
+		if len(skippedScopes) > 0 {
+			names := make([]string, 0, len(skippedScopes))
+			for name := range skippedScopes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			snapshot["skippedScopes"] = names
+		}
+	}
+
+	if delta {
+		summary := map[string]interface{}{
+			"baseline": !deltaMode,
+		}
+		if deltaMode {
+			summary["unchangedThreads"] = unchangedThreads
+			summary["unchangedStacks"] = diff.unchangedStacks
+			summary["unchangedVariables"] = diff.unchangedVariables
+			summary["removedVariables"] = diff.removedVariables(visitedThreads)
+		}
+		snapshot["delta"] = summary
+	}
+
+	// A resource read (see resources.go) records nothing
+	if isResourceRead(ctx) {
+		return jsonResult(snapshot)
+	}
+
+	// debug_diff compares everything captured, even what delta or maxBytes left out
+	partial := targetThreadID != nil || truncated || hiddenThreads > 0
+	snapshot["snapshotId"] = session.AddSnapshot(diff.state(stops), partial)
+
+	// A snapshot cut to fit maxBytes keeps the previous delta baseline, so
+	// what it left out isn't taken as seen
+	if maxBytes > 0 && fitSnapshot(snapshot, maxBytes) {
+		return jsonResult(snapshot)
 	}
+	session.SetSnapshotDigest(diff.digest(targetThreadID != nil || truncated, visitedThreads))
 
 	return jsonResult(snapshot)
 }
 
+// Limits for debug_run_to_line
+const (
+	defaultRunToLineTimeout = 30 * time.Second
+	maxRunToLineTimeout     = 10 * time.Minute
+	// maxRunToLineHits bounds the stops in other calls a frame-scoped run resumes from
+	maxRunToLineHits = 100
+)
+
+// handleDebugRunToLine runs to a line with a temporary breakpoint and returns
+// the stack and locals there. With a frameId the run is scoped to that call,
+// resuming from hits in recursive calls or other threads.
 func (s *Server) handleDebugRunToLine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	session, client, err := s.getSessionClient(request)
 	if err != nil {
@@ -761,36 +1835,124 @@ func (s *Server) handleDebugRunToLine(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	line, err := request.RequireFloat("line")
+	line, err := requireInt(request, "line")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Set a temporary breakpoint
+	timeout := defaultRunToLineTimeout
+	if t, err := requireNumber(request, "timeout"); err == nil && t > 0 {
+		timeout = min(time.Duration(t*float64(time.Second)), maxRunToLineTimeout)
+	}
+
+	requestedPath := path
+	path = resolveSourcePath(session, path)
+
+	// With a frameId, only a stop in that call counts: the line must be
+	// reached in the frame's thread with the frame on top of the stack, so
+	// stops in recursive calls (deeper) are resumed
+	scoped := false
+	targetDepth := 0
+	threadID := 0
+	if f, err := requireInt(request, "frameId"); err == nil {
+		tid, _, index, err := findFrame(client, 0, f)
+		if err != nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("frameId", f,
+				"the id of a frame in a paused thread's stack (see debug_snapshot)").Error()), nil
+		}
+		depth, err := stackDepth(client, tid)
+		if err != nil {
+			return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, fmt.Sprintf("failed to get the stack of thread %d", tid),
+				"The thread must be paused; use debug_threads to list threads.", err).Error()), nil
+		}
+		scoped, threadID, targetDepth = true, tid, depth-index
+	}
+
+	// Set a temporary breakpoint alongside the file's own breakpoints, which
+	// stay active during the run and are restored without it afterward
 	source := dap.Source{Path: path}
-	bps, err := client.SetBreakpoints(source, []dap.SourceBreakpoint{{Line: int(line)}})
+	existing := session.SourceBreakpoints(path)
+	withTemp := make([]dap.SourceBreakpoint, 0, len(existing)+1)
+	for _, bp := range existing {
+		// The unconditional temporary breakpoint stands in for one on its line
+		if bp.Line != line {
+			withTemp = append(withTemp, bp)
+		}
+	}
+	tempIndex := len(withTemp)
+	withTemp = append(withTemp, dap.SourceBreakpoint{Line: line})
+	bps, err := client.SetBreakpoints(source, withTemp)
+	defer func() {
+		_, _ = client.SetBreakpoints(source, session.SourceBreakpoints(path))
+	}()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to set breakpoint: %v", err)), nil
 	}
 
-	if len(bps) == 0 || !bps[0].Verified {
-		return mcp.NewToolResultError("could not set breakpoint at specified line"), nil
+	if len(bps) <= tempIndex {
+		return mcp.NewToolResultError(errors.BreakpointFailed(path, line, "the adapter returned no breakpoint").Error()), nil
 	}
-
-	// Get threads and continue the first stopped one
-	threads, err := client.Threads()
-	if err != nil {
-		return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, "failed to get threads", "The program may have terminated. Use debug_snapshot to check session status.", err).Error()), nil
+	tempBP := bps[tempIndex]
+	// An unverified breakpoint may still be hit once its code loads
+	warning := ""
+	if !tempBP.Verified {
+		if request.GetBool("requireVerified", s.config.RequireVerifiedBreakpoints) {
+			return mcp.NewToolResultError(errors.BreakpointsUnverified(path, []int{line}, []string{tempBP.Message}).Error()), nil
+		}
+		warning = fmt.Sprintf("The breakpoint at line %d was not verified", line)
+		if tempBP.Message != "" {
+			warning += " (" + tempBP.Message + ")"
+		}
+		warning += "; the program may not stop there. Pass requireVerified=true to fail instead."
 	}
 
-	if len(threads) == 0 {
-		return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+	if !scoped {
+		var ok bool
+		if threadID, ok = requestThread(request, session, client); !ok {
+			return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+		}
 	}
 
-	// Continue and wait for stop (30 second timeout)
-	stoppedInfo, err := client.ContinueAndWait(threads[0].Id, 30*time.Second)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("run to line failed: %v", err)), nil
+	// Continue until the line is reached, the program stops elsewhere, or the
+	// run times out or is cancelled
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
+	var stoppedInfo *internaldap.StoppedInfo
+	note := ""
+	for hits := 1; ; hits++ {
+		stoppedInfo, err = client.ContinueAndWaitContext(runCtx, threadID, timeout)
+		if err != nil {
+			// Pause so the program is left stopped, as after any other stop
+			_, pauseErr := client.PauseAndWait(threadID, 5*time.Second)
+			if pauseErr == nil {
+				_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+			}
+			reason := fmt.Sprintf("run to line failed: %v", err)
+			if stderrors.Is(err, context.DeadlineExceeded) || stderrors.Is(err, internaldap.ErrStopTimeout) {
+				reason = fmt.Sprintf("line %d was not reached within %s", line, timeout)
+			} else if stderrors.Is(err, context.Canceled) {
+				reason = "run to line was cancelled"
+			}
+			if pauseErr == nil {
+				reason += "; the program was paused"
+			}
+			return mcp.NewToolResultError(reason), nil
+		}
+		if !scoped || !stoppedAtBreakpoint(client, stoppedInfo, tempBP, path) {
+			break
+		}
+		depth, err := stackDepth(client, stoppedInfo.ThreadID)
+		if stoppedInfo.ThreadID == threadID && err == nil && depth <= targetDepth {
+			if depth < targetDepth {
+				note = "The frame returned before reaching the line; stopped at the line in a caller's call instead."
+			}
+			break
+		}
+		if hits == maxRunToLineHits {
+			note = fmt.Sprintf("Stopped at the line in a different call after %d hits in other calls (recursion or other threads); run again to keep going.", hits)
+			break
+		}
 	}
 
 	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
@@ -799,10 +1961,22 @@ func (s *Server) handleDebugRunToLine(ctx context.Context, request mcp.CallToolR
 	snapshot := map[string]interface{}{
 		"sessionId": session.ID,
 		"status":    "stopped",
-		"stoppedAt": bps[0].Line,
+		"stoppedAt": tempBP.Line,
 		"reason":    stoppedInfo.Reason,
 		"path":      path,
 	}
+	if path != requestedPath {
+		snapshot["requestedPath"] = requestedPath
+	}
+	if warning != "" {
+		snapshot["warning"] = warning
+	}
+	if note != "" {
+		snapshot["note"] = note
+	}
+	if stops := client.StoppedThreads(); len(stops) > 1 {
+		snapshot["stoppedThreads"] = stoppedThreadsList(stops)
+	}
 
 	// Get stack trace for stopped thread
 	frames, _, err := client.StackTrace(stoppedInfo.ThreadID, 0, 5)
@@ -832,10 +2006,11 @@ func (s *Server) handleDebugRunToLine(ctx context.Context, request mcp.CallToolR
 							varsList := make([]map[string]interface{}, len(vars))
 							for i, v := range vars {
 								varsList[i] = map[string]interface{}{
-									"name":  v.Name,
-									"value": v.Value,
-									"type":  v.Type,
+									"name": v.Name,
+									"type": v.Type,
 								}
+								s.setValue(varsList[i], "value", v.Value)
+								addPresentationHint(varsList[i], v.PresentationHint)
 							}
 							snapshot["locals"] = varsList
 						}
@@ -849,18 +2024,52 @@ func (s *Server) handleDebugRunToLine(ctx context.Context, request mcp.CallToolR
 	return jsonResult(snapshot)
 }
 
+// stoppedAtBreakpoint reports whether a stop is at a breakpoint: one the
+// adapter reports hitting or, for adapters that don't report hits, a
+// breakpoint stop on its line
+func stoppedAtBreakpoint(client *internaldap.Client, stop *internaldap.StoppedInfo, bp dap.Breakpoint, path string) bool {
+	if stop.Reason != "breakpoint" {
+		return false
+	}
+	for _, id := range stop.HitBreakpointIDs {
+		if id == bp.Id {
+			return true
+		}
+	}
+	if len(stop.HitBreakpointIDs) > 0 && bp.Id != 0 {
+		return false
+	}
+	frames, _, err := client.StackTrace(stop.ThreadID, 0, 1)
+	return err == nil && len(frames) > 0 && frames[0].Line == bp.Line &&
+		frames[0].Source != nil && frames[0].Source.Path == path
+}
+
+// stackDepth returns the number of frames on a paused thread's stack
+func stackDepth(client *internaldap.Client, threadID int) (int, error) {
+	page, err := client.StackTracePage(threadID, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	return page.TotalFrames, nil
+}
+
 // handleDebugExecuteCommand executes a native debugger CLI command (GDB/LLDB only)
 func (s *Server) handleDebugExecuteCommand(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Commands run in the repl context
+	if s.config.EvaluatesReadOnly() {
+		return mcp.NewToolResultError(errors.PermissionDenied("repl evaluation", string(s.config.Mode)).Error()), nil
+	}
+
 	session, client, err := s.getSessionClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Validate this is a GDB or LLDB session (C, C++, Rust, etc.)
+	// Validate this is a GDB or LLDB session (C, C++, Rust, Swift, native)
 	lang := session.Language
-	if lang != types.LanguageC && lang != types.LanguageRust {
+	if !isNativeDebuggerLanguage(lang) && session.Debugger == "" {
 		return mcp.NewToolResultError(fmt.Sprintf(
-			"debug_execute_command only works with GDB/LLDB sessions (C, C++, Rust). "+
+			"debug_execute_command only works with GDB/LLDB sessions (C, C++, Rust, Swift, native). "+
 				"Current session language: %s. Use debug_evaluate for Go/Python/JavaScript.", lang)), nil
 	}
 
@@ -869,24 +2078,16 @@ func (s *Server) handleDebugExecuteCommand(ctx context.Context, request mcp.Call
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Get frame ID for context, default to finding the top frame
-	frameID := 0
-	if f, err := request.RequireFloat("frameId"); err == nil {
-		frameID = int(f)
-	} else {
-		// Try to get the top frame automatically
-		threads, err := client.Threads()
-		if err == nil && len(threads) > 0 {
-			frames, _, err := client.StackTrace(threads[0].Id, 0, 1)
-			if err == nil && len(frames) > 0 {
-				frameID = frames[0].Id
-			}
-		}
-	}
+	// Get frame ID for context, default to the focused frame
+	frameID, _ := requestFrame(request, session, client)
 
 	// For LLDB, use backtick prefix to ensure command mode
-	// lldb-dap with --repl-mode=auto will execute this as a command
-	evalCommand := "`" + command
+	// lldb-dap with --repl-mode=auto will execute this as a command.
+	// GDB always treats repl evaluations as CLI commands.
+	evalCommand := command
+	if session.Debugger != "gdb" {
+		evalCommand = "`" + command
+	}
 
 	// Execute the command using the repl context
 	result, err := client.Evaluate(evalCommand, frameID, "repl")
@@ -903,6 +2104,80 @@ func (s *Server) handleDebugExecuteCommand(ctx context.Context, request mcp.Call
 
 // Helper functions
 
+// addProcessInfo adds the adapter and debuggee process IDs to a launch result and
+// records the debuggee on the session. "pid" is the debug adapter process.
+func (s *Server) addProcessInfo(result map[string]interface{}, sessionID string, cmd *exec.Cmd, client *internaldap.Client) {
+	if cmd != nil && cmd.Process != nil {
+		result["pid"] = cmd.Process.Pid
+	}
+	if p := client.ProcessInfo(); p != nil {
+		_ = s.sessionManager.SetSessionDebuggee(sessionID, p.PID, p.Name)
+		if p.PID != 0 {
+			result["debuggeePid"] = p.PID
+		}
+		if p.Name != "" {
+			result["debuggeeName"] = p.Name
+		}
+	}
+}
+
+// configureClient applies the configured in-flight request limit for an
+// adapter and logpoint handling to a session's client. Emulated logpoints
+// evaluate their messages under the same policy as debug_evaluate, and log
+// the expressions it refuses as written.
+func (s *Server) configureClient(session *internaldap.Session, client *internaldap.Client, adapter adapters.Adapter) {
+	client.SetMaxInFlight(s.config.MaxInFlight(string(session.Language), nativeDebugger(adapter)))
+	client.SetEmulateLogpoints(s.config.LogpointAutoContinue)
+	client.SetLogpointEvaluator(func(expression string, frameID int) (string, bool) {
+		if !s.config.CanEvaluate() {
+			return "", false
+		}
+		context, _, debugErr := s.evaluationMode(session, expression, "watch")
+		if debugErr != nil {
+			return "", false
+		}
+		result, err := client.Evaluate(expression, frameID, context)
+		if err != nil {
+			return "<" + err.Error() + ">", true
+		}
+		return result.Result, true
+	})
+}
+
+// nativeDebugger returns the native debugger an adapter runs, or "" for other adapters
+func nativeDebugger(adapter adapters.Adapter) string {
+	switch adapter.(type) {
+	case *adapters.GDBAdapter:
+		return "gdb"
+	case *adapters.LLDBAdapter, *adapters.SwiftAdapter, *adapters.RustAdapter:
+		return "lldb"
+	}
+	return ""
+}
+
+// isNativeDebuggerLanguage returns true if sessions for this language run under
+// GDB or LLDB and therefore accept native debugger CLI commands.
+func isNativeDebuggerLanguage(lang types.Language) bool {
+	switch lang {
+	case types.LanguageC, types.LanguageCpp, types.LanguageRust, types.LanguageSwift, types.LanguageNative:
+		return true
+	}
+	return false
+}
+
+// checkTarget returns an InvalidParameter error if the adapter doesn't support
+// the requested debug target (e.g. target=chrome for a Go session)
+func checkTarget(adapter adapters.Adapter, lang types.Language, target string) *errors.DebugError {
+	if adapters.SupportsTarget(adapter, target) {
+		return nil
+	}
+	expected := fmt.Sprintf("no target (%s sessions have no debug targets)", lang)
+	if targets := adapter.SupportedTargets(); len(targets) > 0 {
+		expected = fmt.Sprintf("one of %s (default: %s)", strings.Join(targets, ", "), adapter.DefaultTarget())
+	}
+	return errors.InvalidParameter("target", target, expected)
+}
+
 func (s *Server) getSessionClient(request mcp.CallToolRequest) (*internaldap.Session, *internaldap.Client, error) {
 	sessionID, err := request.RequireString("sessionId")
 	if err != nil {
@@ -914,11 +2189,286 @@ func (s *Server) getSessionClient(request mcp.CallToolRequest) (*internaldap.Ses
 		return nil, nil, errors.SessionNotFound(sessionID)
 	}
 
-	if session.Client == nil {
+	client := session.GetClient()
+	if client == nil {
 		return nil, nil, errors.SessionNoClient(sessionID)
 	}
 
-	return session, session.Client, nil
+	return session, client, nil
+}
+
+// getStoppedSessionClient is getSessionClient for tools that read the stack,
+// scopes, or variables. Those requests fail or hang while the program runs,
+// so a running session is reported at once instead, as is an adapter whose
+// connection has closed.
+func (s *Server) getStoppedSessionClient(request mcp.CallToolRequest) (*internaldap.Session, *internaldap.Client, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !client.Alive() {
+		return nil, nil, errors.SessionAdapterGone(session.ID)
+	}
+	if client.Running() {
+		return nil, nil, errors.SessionRunning(session.ID)
+	}
+	return session, client, nil
+}
+
+// initializedGrace is how long a launch waits for the initialized event after
+// the adapter has already answered the launch request
+const initializedGrace = 500 * time.Millisecond
+
+// runLaunchSequence initializes a freshly connected adapter and launches the
+// program: initialize, launch, wait for initialized, restore the session's
+// breakpoints, configurationDone, and wait for the launch to be confirmed.
+// Adapters that don't require the configuration handshake are launched
+// synchronously, as are adapters that answer launch without sending
+// initialized; breakpoints are then set once the program is running.
+// stopOnEntry behaves the same for every adapter: the returned entryStop says
+// whether the program is paused at entry.
+func (s *Server) runLaunchSequence(session *internaldap.Session, client *internaldap.Client, adapter adapters.Adapter, program string, args map[string]interface{}) (*entryStop, *errors.DebugError) {
+	// Options changed with debug_set_debug_options apply to every later launch
+	if options := session.LaunchDebugOptions(); len(options) > 0 {
+		merged := make(map[string]interface{}, len(args)+len(options))
+		for k, v := range args {
+			merged[k] = v
+		}
+		for k, v := range options {
+			merged[k] = v
+		}
+		args = merged
+	}
+
+	// Adapters can't fail building launch arguments, so a command-line string
+	// that doesn't split (e.g. from an imported session) is rejected here
+	if _, _, err := adapters.ProgramArgs(args); err != nil {
+		return nil, errors.InvalidParameter("args", args["args"], "a JSON array of strings or a command line with matching quotes")
+	}
+
+	stopOnEntry, _ := args["stopOnEntry"].(bool)
+	behavior := adapters.StopOnEntryBehavior(adapter, args)
+
+	// Initialize the debug adapter; TCP adapters were initialized while
+	// connecting, and launchFailed reports the adapter's stderr
+	if _, err := client.Initialize(internaldap.ClientID, internaldap.ClientName); err != nil {
+		return nil, errors.DAPInitFailed(err, nil, stderrors.Is(err, internaldap.ErrAdapterGone))
+	}
+
+	runSetupCommands(client, adapter, args)
+
+	launchArgs := adapter.BuildLaunchArgs(program, args)
+	if behavior == adapters.EntryIgnored {
+		// An entry breakpoint stands in for it, and a newer adapter honoring it too would stop twice
+		delete(launchArgs, "stopOnEntry")
+	}
+
+	// Listen for the entry stop before it can happen
+	var waitForEntry func(time.Duration) (*internaldap.StoppedInfo, error)
+	if stopOnEntry || behavior == adapters.EntryForced {
+		waitForEntry = client.ExpectStopped()
+	}
+
+	handshake := adapters.RequiresConfigurationDone(adapter)
+	launched := false
+	var launchRespCh chan dap.Message
+	if handshake {
+		// Launch the program asynchronously - debugpy won't respond until after configurationDone
+		var err error
+		launchRespCh, err = client.LaunchAsync(launchArgs)
+		if err != nil {
+			return nil, errors.DAPLaunchFailed(program, err)
+		}
+
+		// Wait for initialized event
+		launched, err = client.WaitInitializedOrLaunch(launchRespCh, 10*time.Second)
+		if err != nil {
+			if launched {
+				return nil, errors.DAPLaunchFailed(program, err)
+			}
+			return nil, errors.DAPTimeout("waiting for initialized event", 10)
+		}
+		if launched && client.WaitInitialized(initializedGrace) != nil {
+			// The adapter answered launch synchronously and has no configuration phase
+			handshake = false
+		}
+	} else {
+		if _, err := client.Launch(launchArgs); err != nil {
+			return nil, errors.DAPLaunchFailed(program, err)
+		}
+		launched = true
+		// Fall back to the handshake if the adapter sends initialized after all
+		handshake = client.WaitInitialized(initializedGrace) == nil
+	}
+
+	// Breakpoints passed to debug_launch, or set earlier in the session on a restart
+	for path, bps := range session.AllSourceBreakpoints() {
+		if _, err := client.SetBreakpoints(dap.Source{Path: path}, bps); err != nil {
+			log.Printf("Warning: failed to restore breakpoints in %s for session %s: %v", path, session.ID, err)
+		}
+	}
+	functionBreakpoints := session.FunctionBreakpoints()
+	if len(functionBreakpoints) > 0 {
+		if _, err := client.SetFunctionBreakpoints(functionBreakpoints); err != nil {
+			log.Printf("Warning: failed to restore function breakpoints for session %s: %v", session.ID, err)
+		}
+	}
+	for _, pattern := range session.RegexBreakpoints() {
+		if _, _, err := setRegexBreakpoint(client, nativeDebugger(adapter), pattern); err != nil {
+			log.Printf("Warning: failed to restore regex breakpoint %q for session %s: %v", pattern, session.ID, err)
+		}
+	}
+
+	entry := &entryStop{}
+	entryBreakpoint := false
+	// Adapters like Delve stop before the program's own code, so stopOnEntry
+	// runs on to their entry function
+	runToFunction := ""
+	if stopOnEntry && behavior == adapters.EntryHonored && handshake {
+		if function := adapters.EntryFunction(adapter, args); function != "" && breakOnFunction(client, function, functionBreakpoints) {
+			runToFunction = function
+		}
+	}
+	if stopOnEntry && behavior == adapters.EntryIgnored {
+		if handshake {
+			entryBreakpoint = setEntryBreakpoint(client, adapter, functionBreakpoints)
+			if !entryBreakpoint {
+				entry.note = "this target can't stop at entry; set a breakpoint instead"
+			}
+		} else {
+			entry.note = "the adapter starts the program as it launches, before an entry breakpoint can be set; set a breakpoint instead"
+		}
+		if entry.note != "" {
+			waitForEntry = nil
+		}
+	}
+
+	if handshake {
+		// Signal configuration done - debugpy needs this before it will send launch response.
+		// It is skipped for adapters without supportsConfigurationDoneRequest, which answer launch on their own
+		if err := client.ConfigurationDone(); err != nil {
+			return nil, errors.Wrap(errors.CodeDAPProtocolError, "configuration done failed", "The debug adapter rejected the configuration. Try launching with simpler options.", err)
+		}
+	}
+
+	if !launched {
+		// Now wait for the launch response (or the debuggee's process event)
+		if err := client.WaitForLaunch(launchRespCh, 10*time.Second); err != nil {
+			return nil, errors.DAPLaunchFailed(program, err)
+		}
+	}
+
+	if waitForEntry == nil {
+		return entry, nil
+	}
+	stopped, err := waitForEntry(entryStopTimeout)
+	if runToFunction != "" && err == nil {
+		functionStop, frame, runErr := runToEntryFunction(client, stopped, runToFunction)
+		clearEntryBreakpoint(client, runToFunction, functionBreakpoints)
+		if runErr != nil {
+			// The program is running on past the entry stop, or it exited
+			entry.note = fmt.Sprintf("the program did not stop at %s: %v", runToFunction, runErr)
+			return entry, nil
+		}
+		stopped, entry.frame = functionStop, frame
+		if frame == nil || frame.Name != runToFunction {
+			entry.reason = functionStop.Reason
+			entry.note = fmt.Sprintf("the program stopped (%s) before reaching %s", functionStop.Reason, runToFunction)
+		}
+	} else if runToFunction != "" {
+		clearEntryBreakpoint(client, runToFunction, functionBreakpoints)
+	}
+	if entryBreakpoint {
+		clearEntryBreakpoint(client, entryFunction, functionBreakpoints)
+	}
+	if err != nil {
+		if stopOnEntry {
+			entry.note = "the program did not stop at entry; it may have stopped elsewhere or exited"
+		}
+		return entry, nil
+	}
+	// The first stop is the entry stop only if the adapter says so; a
+	// breakpoint in code that runs first may pause the program before it
+	atEntry := stopped.Reason == "entry" || runToFunction != "" || entryBreakpoint
+	if !stopOnEntry && atEntry {
+		// The adapter stopped although stopOnEntry was not requested
+		if _, err := client.Continue(stopped.ThreadID); err != nil {
+			log.Printf("Warning: failed to continue past entry for session %s: %v", session.ID, err)
+		}
+		return entry, nil
+	}
+	entry.stopped = stopped
+	if !atEntry {
+		entry.reason = stopped.Reason
+	}
+	return entry, nil
+}
+
+// parseProgramArgs parses debug_launch's args parameter, which is either a
+// JSON array of strings or a command line to split into arguments
+func parseProgramArgs(argsStr string) ([]string, error) {
+	if strings.HasPrefix(strings.TrimSpace(argsStr), "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(argsStr), &args); err != nil {
+			return nil, errors.InvalidJSON("args", err, `["--port", "8080"]`)
+		}
+		return args, nil
+	}
+
+	args, err := launchconfig.SplitArgs(argsStr)
+	if err != nil {
+		return nil, errors.InvalidParameter("args", argsStr, "a JSON array of strings or a command line with matching quotes")
+	}
+	return args, nil
+}
+
+// parseSourceMapParam parses the sourceMap parameter of debug_launch and
+// debug_attach: [from, to] path pairs or an object mapping from to to
+func parseSourceMapParam(request mcp.CallToolRequest) ([][]string, error) {
+	sourceMapJSON, err := request.RequireString("sourceMap")
+	if err != nil || sourceMapJSON == "" {
+		return nil, nil
+	}
+	var sourceMap interface{}
+	if err := json.Unmarshal([]byte(sourceMapJSON), &sourceMap); err != nil {
+		return nil, errors.InvalidJSON("sourceMap", err, `[["/build/src", "/home/me/project/src"]]`)
+	}
+	pairs, _, err := adapters.SourceMap(map[string]interface{}{"sourceMap": sourceMap})
+	if err != nil {
+		return nil, errors.InvalidParameter("sourceMap", sourceMapJSON, "[from, to] pairs of paths or an object mapping one path to the other")
+	}
+	return pairs, nil
+}
+
+// runSetupCommands runs the commands an adapter needs before the launch or
+// attach request, such as GDB's substitute-path for a source map
+func runSetupCommands(client *internaldap.Client, adapter adapters.Adapter, args map[string]interface{}) {
+	for _, command := range adapters.SetupCommands(adapter, args) {
+		if _, err := client.Evaluate(command, 0, "repl"); err != nil {
+			log.Printf("Warning: setup command %q failed: %v", command, err)
+		}
+	}
+}
+
+// launchFailed ends a session whose launch or attach failed and reports the
+// error along with the last lines the adapter wrote to stderr
+func (s *Server) launchFailed(sessionID string, message string) (*mcp.CallToolResult, error) {
+	var stderr []string
+	// A failed attach detaches: the process was running before we arrived
+	terminateDebuggee := true
+	if session, err := s.sessionManager.GetSession(sessionID); err == nil {
+		if captured := session.AdapterLog(); captured != nil {
+			stderr = captured.Tail()
+		}
+		terminateDebuggee = !session.GetInfo().Attached
+	}
+	s.stopWatchingForRestart(sessionID)
+	_ = s.sessionManager.TerminateSession(sessionID, terminateDebuggee)
+
+	if len(stderr) > 0 {
+		message += "\nadapter stderr:\n" + strings.Join(stderr, "\n")
+	}
+	return mcp.NewToolResultError(message), nil
 }
 
 func jsonResult(data interface{}) (*mcp.CallToolResult, error) {
@@ -931,6 +2481,62 @@ func jsonResult(data interface{}) (*mcp.CallToolResult, error) {
 
 // Launch.json Configuration Handlers
 
+// formatMissingInputs describes missing ${input:} values using their launch.json
+// definitions so the values can be asked for sensibly
+func formatMissingInputs(e *launchconfig.MissingInputsError) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "missing input values: %v. Provide them via inputValues parameter.", e.Inputs)
+	for _, input := range e.Definitions {
+		fmt.Fprintf(&b, "\n- %s", input.ID)
+		if input.Type != "" {
+			fmt.Fprintf(&b, " (%s)", input.Type)
+		}
+		if input.Description != "" {
+			fmt.Fprintf(&b, ": %s", input.Description)
+		}
+		if len(input.Options) > 0 {
+			fmt.Fprintf(&b, " [options: %s]", strings.Join(input.Options, ", "))
+		}
+		if input.Type == "command" && input.Command != "" {
+			fmt.Fprintf(&b, " [command %q not run; set allowCommands to enable]", input.Command)
+		}
+	}
+	return b.String()
+}
+
+// handleDebugListConfigs lists launch.json configurations and reports pre-flight validation warnings
+func (s *Server) handleDebugListConfigs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	workspace, _ := request.RequireString("workspace")
+	configPath, _ := request.RequireString("configPath")
+
+	var lj *launchconfig.LaunchJSON
+	var err error
+
+	if configPath != "" {
+		lj, err = launchconfig.LoadFromPath(configPath)
+	} else if workspace != "" {
+		lj, configPath, err = launchconfig.LoadAndDiscover(workspace)
+	} else {
+		return mcp.NewToolResultError("workspace or configPath is required"), nil
+	}
+
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to load launch.json: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"configPath":     configPath,
+		"version":        lj.Version,
+		"configurations": launchconfig.ListConfigurations(lj),
+		"compounds":      launchconfig.ListCompounds(lj),
+	}
+	if warnings := launchconfig.ValidationWarnings(lj); len(warnings) > 0 {
+		result["validationWarnings"] = warnings
+	}
+
+	return jsonResult(result)
+}
+
 // handleConfigBasedLaunch handles launching a debug session from a launch.json configuration
 func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallToolRequest, configName string) (*mcp.CallToolResult, error) {
 	// Get workspace and config path
@@ -959,6 +2565,10 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 		return mcp.NewToolResultError(fmt.Sprintf("configuration not found: %v", err)), nil
 	}
 
+	if err := cfg.CheckType(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("configuration %q: %v", configName, err)), nil
+	}
+
 	// Validate it's a launch configuration
 	if !cfg.IsLaunchRequest() {
 		return mcp.NewToolResultError(fmt.Sprintf("configuration %q is an attach configuration, use debug_attach instead", configName)), nil
@@ -967,6 +2577,8 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 	// Build resolution context
 	resCtx := &launchconfig.ResolutionContext{
 		WorkspaceFolder: workspace,
+		Inputs:          lj.Inputs,
+		AllowCommands:   s.config.AllowCommands,
 	}
 
 	// If workspace not provided, derive from configPath
@@ -974,6 +2586,15 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 		resCtx.WorkspaceFolder = launchconfig.GetWorkspaceFolder(configPath)
 	}
 
+	// Named folders of a multi-root workspace
+	if foldersJSON, err := request.RequireString("workspaceFolders"); err == nil && foldersJSON != "" {
+		var folders map[string]string
+		if err := json.Unmarshal([]byte(foldersJSON), &folders); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("workspaceFolders", err, `{"backend": "/path/to/backend"}`).Error()), nil
+		}
+		resCtx.WorkspaceFolders = folders
+	}
+
 	// Parse input values if provided
 	if inputValuesJSON, err := request.RequireString("inputValues"); err == nil && inputValuesJSON != "" {
 		var inputValues map[string]string
@@ -993,7 +2614,7 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 	if err != nil {
 		// Check if it's a missing inputs error
 		if missingErr, ok := launchconfig.IsMissingInputsError(err); ok {
-			return mcp.NewToolResultError(fmt.Sprintf("missing input values: %v. Provide them via inputValues parameter.", missingErr.Inputs)), nil
+			return mcp.NewToolResultError(formatMissingInputs(missingErr)), nil
 		}
 		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve configuration: %v", err)), nil
 	}
@@ -1001,25 +2622,73 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 	// Get the language
 	lang := types.Language(resolved.Language)
 
-	// Get the adapter for this language
-	adapter, err := s.adapterReg.Get(lang)
+	// Get the adapter for this language, honoring gdb/cppdbg configuration types
+	debugger := ""
+	if cfg.IsGDBType() {
+		debugger = "gdb"
+	}
+	adapter, err := s.adapterReg.GetWithDebugger(lang, debugger)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if targetErr := checkTarget(adapter, lang, resolved.Target); targetErr != nil {
+		return mcp.NewToolResultError(targetErr.Error()), nil
+	}
+
+	launchBreakpoints, err := s.parseLaunchBreakpointParams(request, adapter)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sourceMap, err := parseSourceMapParam(request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	// Tasks run shell commands, so they need allowCommands like command inputs
+	var tasks *launchTasks
+	tasksNote := ""
+	if s.config.AllowCommands {
+		tasks, err = loadLaunchTasks(resolved, configPath, resCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load tasks: %v", err)), nil
+		}
+	} else if resolved.PreLaunchTask != "" || resolved.PostDebugTask != "" {
+		tasksNote = "The configuration's preLaunchTask and postDebugTask were not run; set allowCommands to enable tasks.json tasks."
+	}
+
 	// Create a new session
 	session, err := s.sessionManager.CreateSession(lang, resolved.Program)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	_ = s.sessionManager.SetSessionDebugger(session.ID, nativeDebugger(adapter))
+	session.SetLaunchRequest(request.GetArguments())
 
 	// Build launch arguments from resolved configuration
 	args := resolved.ToLaunchArgs()
+	session.SetSourceRoot(sourceRoot(resolved.Cwd, resCtx.WorkspaceFolder, resolved.Program))
+	if resolved.JustMyCode != nil {
+		session.SetDebugOption("justMyCode", *resolved.JustMyCode)
+	}
 
 	// Add target if browser debugging
 	if resolved.Target != "" {
 		args["target"] = resolved.Target
 	}
+	// A sourceMap parameter replaces the configuration's
+	if sourceMap != nil {
+		args["sourceMap"] = sourceMap
+	}
+
+	defaultEnv, err := s.sessionDefaultEnv(lang, adapter, resCtx)
+	if err != nil {
+		_ = s.sessionManager.TerminateSession(session.ID, false)
+		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve defaultEnv: %v", err)), nil
+	}
+	applyDefaultEnv(args, defaultEnv)
+	if resCtx.WorkspaceFolder != "" {
+		args[adapters.WorkspaceFolderArg] = resCtx.WorkspaceFolder
+	}
 
 	// Spawn the debug adapter if allowed
 	if !s.config.CanSpawn() {
@@ -1027,6 +2696,13 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 		return mcp.NewToolResultError("spawning debug adapters is not allowed"), nil
 	}
 
+	if tasks != nil {
+		if err := tasks.start(session); err != nil {
+			_ = s.sessionManager.TerminateSession(session.ID, false)
+			return mcp.NewToolResultError(fmt.Sprintf("preLaunchTask failed: %v", err)), nil
+		}
+	}
+
 	// SpawnAndConnect handles both TCP and stdio-based adapters
 	client, cmd, err := adapters.SpawnAndConnect(ctx, adapter, resolved.Program, args)
 	if err != nil {
@@ -1039,42 +2715,15 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 	}
 
 	_ = s.sessionManager.SetSessionClient(session.ID, client)
+	s.configureClient(session, client, adapter)
+	launchBreakpoints.record(session)
 
-	// Initialize the debug adapter
-	_, err = client.Initialize("dap-mcp", "DAP-MCP Server")
-	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize: %v", err)), nil
-	}
-
-	// Launch the program asynchronously
-	launchArgs := adapter.BuildLaunchArgs(resolved.Program, args)
-	launchRespCh, err := client.LaunchAsync(launchArgs)
-	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to launch: %v", err)), nil
-	}
-
-	// Wait for initialized event
-	if err := client.WaitInitialized(10 * time.Second); err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(fmt.Sprintf("failed waiting for initialized: %v", err)), nil
-	}
-
-	// Signal configuration done
-	if err := client.ConfigurationDone(); err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(fmt.Sprintf("configuration failed: %v", err)), nil
-	}
-
-	// Wait for the launch response
-	_, err = client.WaitForLaunchResponse(launchRespCh, 10*time.Second)
-	if err != nil {
-		_ = s.sessionManager.TerminateSession(session.ID, true)
-		return mcp.NewToolResultError(fmt.Sprintf("launch failed: %v", err)), nil
+	entry, debugErr := s.runLaunchSequence(session, client, adapter, resolved.Program, args)
+	if debugErr != nil {
+		return s.launchFailed(session.ID, debugErr.Error())
 	}
 
-	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
+	_ = s.sessionManager.UpdateSessionStatus(session.ID, launchedStatus(entry))
 
 	result := map[string]interface{}{
 		"sessionId":  session.ID,
@@ -1083,9 +2732,17 @@ func (s *Server) handleConfigBasedLaunch(ctx context.Context, request mcp.CallTo
 		"program":    resolved.Program,
 		"configName": configName,
 	}
-	if cmd != nil && cmd.Process != nil {
-		result["pid"] = cmd.Process.Pid
+	if tasks != nil && tasks.preLaunchTask != nil {
+		result["preLaunchTask"] = tasks.preLaunchTask.Label
+		if tasks.preLaunchTask.IsBackground {
+			result["backgroundTask"] = true
+		}
+	}
+	if tasksNote != "" {
+		result["note"] = tasksNote
 	}
+	entry.apply(result)
+	s.addProcessInfo(result, session.ID, cmd, client)
 
 	return jsonResult(result)
 }
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/internal/install"
+)
+
+const (
+	// installTimeout bounds a debug_install_adapter run, download included
+	installTimeout = 10 * time.Minute
+
+	// installOutputLines is how much installer output a result carries
+	installOutputLines = 40
+)
+
+// handleDebugInstallAdapter installs the debug adapter for a language and
+// optionally records its path in the server's configuration file. The running
+// server keeps the adapter paths it started with.
+func (s *Server) handleDebugInstallAdapter(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.config.AllowInstall {
+		return mcp.NewToolResultError(errors.PermissionDenied("install", string(s.config.Mode)).Error()), nil
+	}
+
+	language, err := request.RequireString("language")
+	if err != nil {
+		return mcp.NewToolResultError(errors.MissingParameter("language",
+			"Specify the language whose adapter to install: 'go', 'python', 'javascript', or 'typescript'.").Error()), nil
+	}
+	language = strings.ToLower(strings.TrimSpace(language))
+	writeConfig := request.GetBool("writeConfig", false)
+	if writeConfig && s.config.Path == "" {
+		return mcp.NewToolResultError(errors.InvalidParameter("writeConfig", true,
+			"the server was started without -config, so there is no configuration file to write to").Error()), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, installTimeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	opts := install.Options{Progress: &output}
+	if language == "python" {
+		opts.PythonPath = s.config.Adapters.Python.PythonPath
+	}
+
+	installed, err := install.Install(ctx, language, opts)
+	lines := lastLines(output.String(), installOutputLines)
+	if err != nil {
+		msg := errors.AdapterInstallFailed(language, err).Error()
+		if len(lines) > 0 {
+			msg += "\nInstaller output:\n" + strings.Join(lines, "\n")
+		}
+		return mcp.NewToolResultError(msg), nil
+	}
+
+	result := map[string]interface{}{
+		"language":  installed.Language,
+		"adapter":   installed.Adapter,
+		"path":      installed.Path,
+		"configKey": installed.ConfigKey,
+		"output":    lines,
+	}
+	if writeConfig {
+		if err := config.SetFileValue(s.config.Path, installed.ConfigKey, installed.Path); err != nil {
+			result["configError"] = err.Error()
+		} else {
+			result["configPath"] = s.config.Path
+		}
+	}
+	if writeConfig && result["configError"] == nil {
+		result["note"] = "The path was written to the configuration file. Restart the server to launch sessions with it."
+	} else {
+		result["note"] = "Set " + installed.ConfigKey + " to this path in the configuration file (or pass writeConfig) and restart the server to launch sessions with it."
+	}
+	return jsonResult(result)
+}
+
+// lastLines returns up to n trailing non-empty lines of text
+func lastLines(text string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
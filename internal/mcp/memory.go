@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// maxReadMemoryBytes bounds the bytes debug_read_variable_bytes reads in one call
+const maxReadMemoryBytes = 1 << 20
+
+// handleDebugReadVariableBytes reads a value's full contents from memory, for
+// strings and byte buffers whose value the adapter cuts short. The value is an
+// expression to evaluate or a variable by name under a variablesReference; it
+// must expose a memoryReference. The length is count, else the value's number
+// of indexed children (the elements of a byte slice or array).
+func (s *Server) handleDebugReadVariableBytes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !client.Capabilities().SupportsReadMemoryRequest {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter,
+			"this debug adapter does not support reading memory",
+			"Use debug_evaluate with context 'clipboard' to get the full value as a string.", nil).Error()), nil
+	}
+
+	name, _ := request.RequireString("name")
+	var memoryReference string
+	length := 0
+	if ref, err := requireInt(request, "variablesReference"); err == nil && ref > 0 {
+		if name == "" {
+			return mcp.NewToolResultError(errors.MissingParameter("name",
+				"Provide the name of the variable under variablesReference to read.").Error()), nil
+		}
+		variables, err := client.Variables(ref, "", 0, 0)
+		if err != nil {
+			return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, fmt.Sprintf("failed to get the variables of reference %d", ref),
+				"The reference may be stale; references are only valid while the program stays stopped.", err).Error()), nil
+		}
+		found := false
+		for _, v := range variables {
+			if v.Name == name {
+				memoryReference, length, found = v.MemoryReference, v.IndexedVariables, true
+				break
+			}
+		}
+		if !found {
+			return mcp.NewToolResultError(errors.InvalidParameter("name", name,
+				fmt.Sprintf("the name of a variable under reference %d", ref)).Error()), nil
+		}
+	} else {
+		expression, err := request.RequireString("expression")
+		if err != nil {
+			return mcp.NewToolResultError(errors.MissingParameter("expression",
+				"Provide an expression to evaluate (e.g. \"buf\") or a variablesReference and name.").Error()), nil
+		}
+		if !s.config.CanEvaluate() {
+			return mcp.NewToolResultError(errors.PermissionDenied("evaluate", string(s.config.Mode)).Error()), nil
+		}
+
+		frameID, _ := requestFrame(request, session, client)
+
+		evalContext, _, debugErr := s.evaluationMode(session, expression, "watch")
+		if debugErr != nil {
+			return mcp.NewToolResultError(debugErr.Error()), nil
+		}
+		evaluated, err := client.Evaluate(expression, frameID, evalContext)
+		if err != nil {
+			return mcp.NewToolResultError(errors.EvaluationFailed(expression, err).Error()), nil
+		}
+		memoryReference, length = evaluated.MemoryReference, evaluated.IndexedVariables
+		name = expression
+	}
+
+	if memoryReference == "" {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeInvalidParameter,
+			fmt.Sprintf("%s has no memoryReference", name),
+			"Only values backed by memory the adapter can address can be read; try the value's data pointer, or debug_evaluate with context 'clipboard'.", nil).Error()), nil
+	}
+
+	offset := 0
+	if o, err := requireInt(request, "offset"); err == nil && o > 0 {
+		offset = o
+	}
+	if c, err := requireInt(request, "count"); err == nil && c > 0 {
+		length = c
+	} else {
+		length -= offset
+	}
+	if length <= 0 {
+		return mcp.NewToolResultError(errors.MissingParameter("count",
+			fmt.Sprintf("The length of %s is unknown; pass the number of bytes to read, e.g. the result of len(%s).", name, name)).Error()), nil
+	}
+	truncated := length > maxReadMemoryBytes
+	length = min(length, maxReadMemoryBytes)
+
+	memory, err := client.ReadMemory(memoryReference, offset, length)
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, fmt.Sprintf("failed to read memory at %s", memoryReference),
+			"The memory may not be readable; check offset and count.", err).Error()), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(memory.Data)
+	if err != nil {
+		return mcp.NewToolResultError(errors.Wrap(errors.CodeDAPProtocolError, "the adapter returned invalid base64 data", "", err).Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"sessionId":       session.ID,
+		"name":            name,
+		"memoryReference": memoryReference,
+		"address":         memory.Address,
+		"offset":          offset,
+		"bytesRead":       len(data),
+	}
+	// Text is returned as is; anything else stays base64 so no byte is lost
+	if utf8.Valid(data) {
+		result["encoding"] = "utf-8"
+		result["data"] = string(data)
+	} else {
+		result["encoding"] = "base64"
+		result["data"] = memory.Data
+	}
+	if memory.UnreadableBytes > 0 {
+		result["unreadableBytes"] = memory.UnreadableBytes
+	}
+	if truncated {
+		result["truncated"] = true
+		result["nextOffset"] = offset + length
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handleDebugModules lists the modules (libraries, Python modules, scripts)
+// the debuggee has loaded. The list is kept live from the adapter's module
+// events, so modules loaded after launch (dlopen, imports) are included, and
+// refreshed with a modules request where the adapter supports one. Modules
+// first reported since the session's previous call are flagged new.
+func (s *Server) handleDebugModules(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if client.Capabilities().SupportsModulesRequest {
+		// Failures leave the list to the module events
+		_, _, _ = client.Modules(0, 0)
+	}
+
+	since := session.ModulesSeen()
+	if n, err := requireInt(request, "since"); err == nil && n >= 0 {
+		since = n
+	}
+	filter, _ := request.RequireString("filter")
+	filter = strings.ToLower(filter)
+
+	modules, lastSeq := client.LoadedModules()
+	list := make([]map[string]interface{}, 0, len(modules))
+	newCount := 0
+	for _, m := range modules {
+		if filter != "" && !strings.Contains(strings.ToLower(m.Name), filter) && !strings.Contains(strings.ToLower(m.Path), filter) {
+			continue
+		}
+		entry := map[string]interface{}{
+			"id":   m.Id,
+			"name": m.Name,
+		}
+		if m.Path != "" {
+			entry["path"] = m.Path
+		}
+		if m.Version != "" {
+			entry["version"] = m.Version
+		}
+		if m.SymbolStatus != "" {
+			entry["symbolStatus"] = m.SymbolStatus
+		}
+		if m.IsUserCode {
+			entry["isUserCode"] = true
+		}
+		if m.Seq > since {
+			entry["new"] = true
+			newCount++
+		}
+		list = append(list, entry)
+	}
+	session.SetModulesSeen(lastSeq)
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"modules":   list,
+		"total":     len(modules),
+		"new":       newCount,
+		"lastSeq":   lastSeq,
+	}
+	if len(modules) == 0 {
+		result["note"] = "The adapter has reported no modules. Delve does not report modules; debugpy, lldb-dap, and js-debug do."
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// handleDebugGetOutput returns the program output buffered from the adapter's
+// output events, tagged with its category and source location. stderr and
+// "important" output is flagged as an error.
+func (s *Server) handleDebugGetOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var categories []string
+	if categoriesJSON, err := request.RequireString("categories"); err == nil && categoriesJSON != "" {
+		if err := json.Unmarshal([]byte(categoriesJSON), &categories); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("categories", err, `["stderr", "important"]`).Error()), nil
+		}
+	}
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[strings.ToLower(category)] = true
+	}
+
+	since := 0
+	if n, err := requireInt(request, "since"); err == nil && n > 0 {
+		since = n
+	}
+
+	entries, dropped := client.Output()
+	lastSeq := since
+	counts := make(map[string]int)
+	output := make([]map[string]interface{}, 0)
+	errorCount := 0
+	for _, e := range entries {
+		if e.Seq <= since {
+			continue
+		}
+		lastSeq = e.Seq
+		counts[e.Category]++
+		if len(wanted) > 0 && !wanted[e.Category] {
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"seq":      e.Seq,
+			"category": e.Category,
+			"output":   e.Output,
+		}
+		if e.Group != "" {
+			entry["group"] = e.Group
+		}
+		if e.Source != "" || e.Line > 0 {
+			location := map[string]interface{}{"path": e.Source}
+			if e.Line > 0 {
+				location["line"] = e.Line
+			}
+			if e.Column > 0 {
+				location["column"] = e.Column
+			}
+			entry["source"] = location
+		}
+		if e.IsError() {
+			entry["error"] = true
+			errorCount++
+		}
+		output = append(output, entry)
+	}
+
+	if n, err := requireInt(request, "lines"); err == nil && n > 0 && n < len(output) {
+		output = output[len(output)-n:]
+	}
+
+	result := map[string]interface{}{
+		"sessionId":  session.ID,
+		"output":     output,
+		"categories": counts,
+		"errorCount": errorCount,
+		"lastSeq":    lastSeq,
+	}
+	if dropped > 0 {
+		result["droppedEntries"] = dropped
+	}
+	return jsonResult(result)
+}
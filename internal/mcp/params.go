@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// fractionalParams are the number parameters that may have a fractional part;
+// every other number parameter is an integer, such as an ID, line, or count
+var fractionalParams = map[string]bool{
+	"timeout": true, // Seconds
+}
+
+// requireInt returns an integer parameter. JSON numbers usually arrive as
+// float64, but clients may also send Go integers, json.Number, or numeric
+// strings. It returns a MissingParameter error if the parameter is absent, and
+// an InvalidParameter error if it isn't a whole number.
+func requireInt(request mcp.CallToolRequest, key string) (int, error) {
+	n, err := requireNumber(request, key)
+	if err != nil {
+		return 0, err
+	}
+	if n != math.Trunc(n) || math.Abs(n) > 1<<53 {
+		return 0, errors.InvalidParameter(key, request.GetArguments()[key], "an integer")
+	}
+	return int(n), nil
+}
+
+// requireNumber is requireInt for parameters that may be fractional, such as
+// a timeout in seconds
+func requireNumber(request mcp.CallToolRequest, key string) (float64, error) {
+	value, ok := request.GetArguments()[key]
+	if !ok || value == nil {
+		return 0, errors.MissingParameter(key, "Provide a number.")
+	}
+
+	var n float64
+	var err error
+	switch v := value.(type) {
+	case float64:
+		n = v
+	case float32:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case json.Number:
+		n, err = v.Float64()
+	case string:
+		n, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+	default:
+		err = errors.InvalidParameter(key, value, "a number")
+	}
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, errors.InvalidParameter(key, value, "a number")
+	}
+	return n, nil
+}
+
+// numberParams returns the names of a tool's number parameters
+func numberParams(tool mcp.Tool) []string {
+	var names []string
+	for name, prop := range tool.InputSchema.Properties {
+		if p, ok := prop.(map[string]interface{}); ok && p["type"] == "number" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// addTool registers a tool whose handler only runs once the number parameters
+// the call provides are valid. Handlers can then take a failed requireInt for
+// an optional parameter to mean it was omitted.
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	numbers := numberParams(tool)
+	s.mcpServer.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		for _, key := range numbers {
+			if value, ok := args[key]; !ok || value == nil {
+				continue
+			}
+			var err error
+			if fractionalParams[key] {
+				_, err = requireNumber(request, key)
+			} else {
+				_, err = requireInt(request, key)
+			}
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		return handler(ctx, request)
+	})
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Bounds for debug_ping's wait on the adapter
+const (
+	defaultPingTimeout = 2 * time.Second
+	maxPingTimeout     = 30 * time.Second
+)
+
+// handleDebugPing checks a session is alive without fetching stacks or
+// variables: whether the adapter connection is open and answers a threads
+// request, whether the debuggee is still running, and when the adapter was
+// last heard from
+func (s *Server) handleDebugPing(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	timeout := defaultPingTimeout
+	if ms, err := requireInt(request, "timeoutMs"); err == nil && ms > 0 {
+		timeout = min(time.Duration(ms)*time.Millisecond, maxPingTimeout)
+	}
+
+	info := session.GetInfo()
+	adapterAlive := client.Alive()
+	result := map[string]interface{}{
+		"sessionId":    session.ID,
+		"status":       string(info.Status),
+		"adapterAlive": adapterAlive,
+	}
+	if adapterAlive {
+		latency, err := client.Ping(timeout)
+		result["adapterResponsive"] = err == nil
+		if err == nil {
+			result["latencyMs"] = latency.Milliseconds()
+		} else {
+			result["error"] = err.Error()
+		}
+	}
+	result["debuggeeAlive"] = debuggeeAlive(client, info)
+	if code, exited := client.DebuggeeExited(); exited {
+		result["exitCode"] = code
+	}
+	if last := client.LastActivity(); !last.IsZero() {
+		result["lastActivity"] = last.Format(time.RFC3339Nano)
+		result["idleMs"] = time.Since(last).Milliseconds()
+	}
+	return jsonResult(result)
+}
+
+// debuggeeAlive reports whether the debuggee is still running: not if the
+// adapter said it exited or ended the session, otherwise by its process when
+// it is local, and otherwise by whether its adapter is still connected
+func debuggeeAlive(client *internaldap.Client, info types.SessionInfo) bool {
+	if _, exited := client.DebuggeeExited(); exited || client.Terminated() {
+		return false
+	}
+	if p := client.ProcessInfo(); p != nil && p.IsLocalProcess && p.PID > 0 {
+		return internaldap.ProcessAlive(p.PID)
+	}
+	return client.Alive() && info.Status != types.SessionStatusTerminated
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// debugPrompt is a debugging workflow offered as an MCP prompt: guidance on
+// which of this server's tools to call, and in what order, for a common task
+type debugPrompt struct {
+	name        string
+	description string
+	arguments   []promptArgument
+	// steps renders the workflow from the prompt's arguments
+	steps func(args map[string]string) string
+}
+
+// promptArgument is an argument a debugPrompt is parameterized by
+type promptArgument struct {
+	name        string
+	description string
+	required    bool
+}
+
+// sessionArgument names the session a workflow runs in; without it the
+// workflow starts with debug_launch
+var sessionArgument = promptArgument{
+	name:        "sessionId",
+	description: "The debug session to work in; omit to launch one",
+}
+
+// debugPrompts are the workflows registered as prompts
+var debugPrompts = []debugPrompt{
+	{
+		name:        "diagnose_crash",
+		description: "Find the cause of a crash or panic: run the program until it fails, then inspect the failing frame and how it was reached",
+		arguments: []promptArgument{
+			sessionArgument,
+			{name: "program", description: "The program to launch, if there is no session yet"},
+		},
+		steps: func(args map[string]string) string {
+			var b strings.Builder
+			b.WriteString("Diagnose why the program crashes.\n\n")
+			b.WriteString(startSession(args))
+			b.WriteString(`2. Call debug_continue and wait for the program to stop. If it exits instead, call debug_get_output with categories ["stderr"] to read the error it printed, and set a breakpoint near the reported location with debug_breakpoints before relaunching.
+3. Once stopped, call debug_exception to get the exception or panic message and its chain of causes.
+4. Call debug_snapshot to see every thread's stack and the top frame's variables.
+5. Walk up the failing thread's stack with debug_frame (direction "up") until you reach the program's own code, reading each frame's source and locals.
+6. Use debug_evaluate on suspicious values (nil pointers, out-of-range indexes, unexpected types) in that frame.
+7. Explain the root cause: the value that was wrong, where it was set, and the line that failed because of it.
+`)
+			return b.String()
+		},
+	},
+	{
+		name:        "find_variable_change",
+		description: "Find the line where a variable gets an unexpected value, by stopping each time it changes",
+		arguments: []promptArgument{
+			sessionArgument,
+			{name: "variable", description: "The variable or expression to watch, e.g. \"total\" or \"user.name\"", required: true},
+			{name: "file", description: "The source file the variable is used in"},
+		},
+		steps: func(args map[string]string) string {
+			variable := args["variable"]
+			file := args["file"]
+			if file == "" {
+				file = "the file that uses it"
+			}
+			var b strings.Builder
+			fmt.Fprintf(&b, "Find where %s changes to an unexpected value.\n\n", variable)
+			b.WriteString(startSession(args))
+			fmt.Fprintf(&b, `2. Set a breakpoint in %[2]s where %[1]s is first in scope with debug_breakpoints, and run to it with debug_continue (or use debug_run_to_line).
+3. Call debug_evaluate with expression %[1]q to record its current value.
+4. Call debug_break_when with expression %[1]q and a path and line in %[2]s after it is assigned, so the program breaks when the value differs.
+5. Alternatively, step with debug_step (type "over") and call debug_diff after each step: it reports only the variables that changed.
+6. At each stop, call debug_evaluate on %[1]q and debug_frame to see the line and locals, until the value turns wrong.
+7. Report the line that set the wrong value and the values it was computed from.
+`, variable, file)
+			return b.String()
+		},
+	},
+	{
+		name:        "trace_exception",
+		description: "Trace an exception back to where it was raised and the state that caused it",
+		arguments: []promptArgument{
+			sessionArgument,
+			{name: "file", description: "The source file the exception is raised or reported in"},
+		},
+		steps: func(args map[string]string) string {
+			var b strings.Builder
+			b.WriteString("Trace the exception to its origin.\n\n")
+			b.WriteString(startSession(args))
+			if file := args["file"]; file != "" {
+				fmt.Fprintf(&b, "2. Set breakpoints in %s around where the exception is raised with debug_breakpoints, then call debug_continue until the program stops.\n", file)
+			} else {
+				b.WriteString("2. Call debug_continue until the program stops on the exception.\n")
+			}
+			b.WriteString(`3. Call debug_exception for the exception's type, message, and chain of causes.
+4. Call debug_snapshot and find the frame that raised it; debug_source shows the code around a frame's line.
+5. Move through the stack with debug_frame, checking each caller's arguments with debug_evaluate, until you find the value that led to the exception.
+6. If the exception was caught and re-raised, set a breakpoint at the original raise with debug_breakpoints and relaunch to see the state before it was wrapped.
+7. Summarize where the exception originates, the chain it went through, and the fix.
+`)
+			return b.String()
+		},
+	},
+}
+
+// startSession renders a workflow's first step: using the given session, or
+// launching the program
+func startSession(args map[string]string) string {
+	if id := args["sessionId"]; id != "" {
+		return fmt.Sprintf("Use sessionId %q for every tool call.\n\n1. Call debug_list_sessions to check the session is still active and whether it is stopped or running.\n", id)
+	}
+	if program := args["program"]; program != "" {
+		return fmt.Sprintf("1. Call debug_launch with program %q (or a launch.json configuration) and use the returned sessionId for every tool call.\n", program)
+	}
+	return "1. Call debug_launch with the program (or a launch.json configuration) and use the returned sessionId for every tool call.\n"
+}
+
+// registerPrompts registers the debugging workflows as MCP prompts
+func (s *Server) registerPrompts() {
+	for _, p := range debugPrompts {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(p.description)}
+		for _, arg := range p.arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.description)}
+			if arg.required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.name, argOpts...))
+		}
+		s.mcpServer.AddPrompt(mcp.NewPrompt(p.name, opts...), p.handler())
+	}
+}
+
+// handler returns the prompt's handler, which checks its required arguments
+// and renders the workflow
+func (p debugPrompt) handler() server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := request.Params.Arguments
+		if args == nil {
+			args = map[string]string{}
+		}
+		for _, arg := range p.arguments {
+			if arg.required && strings.TrimSpace(args[arg.name]) == "" {
+				return nil, fmt.Errorf("prompt %s requires the %s argument: %s", p.name, arg.name, arg.description)
+			}
+		}
+		return mcp.NewGetPromptResult(p.description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(p.steps(args))),
+		}), nil
+	}
+}
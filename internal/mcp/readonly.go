@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"strings"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Evaluation modes reported in evaluate results
+const (
+	// evaluateModeFull evaluates any expression
+	evaluateModeFull = "full"
+	// evaluateModeReadOnly lets the adapter prevent side effects
+	evaluateModeReadOnly = "readOnly"
+	// evaluateModeReadOnlyChecked rejects expressions that look like they have
+	// side effects, for adapters that can't prevent them
+	evaluateModeReadOnlyChecked = "readOnlyChecked"
+)
+
+// defaultEvaluationContext returns the context to evaluate in when the caller
+// passes none: the evaluationContexts entry for the session's debugger or
+// language. Read-only evaluation refuses the repl context, so there a repl
+// default falls back to watch rather than failing.
+func (s *Server) defaultEvaluationContext(session *internaldap.Session) string {
+	context := s.config.EvaluationContext(string(session.Language), session.Debugger)
+	if context == "repl" && s.config.EvaluatesReadOnly() {
+		return "watch"
+	}
+	return context
+}
+
+// evaluationMode decides how to evaluate an expression. Unless evaluation is
+// read-only (evaluateReadOnly, or readonly mode), everything is evaluated as
+// requested; the repl context is refused before this is reached.
+// Otherwise adapters that can evaluate without side effects are asked to:
+// js-debug throws on side effects and lldb-dap only resolves variable paths
+// in the hover context, and Delve only calls functions for "call" commands.
+// For other adapters, expressions with calls or assignments are rejected.
+// It returns the context to evaluate in and the mode to report, which is the
+// session's mode even when the expression is rejected.
+func (s *Server) evaluationMode(session *internaldap.Session, expression, context string) (string, string, *errors.DebugError) {
+	if !s.config.EvaluatesReadOnly() {
+		return context, evaluateModeFull, nil
+	}
+
+	switch {
+	case session.Language == types.LanguageJavaScript || session.Language == types.LanguageTypeScript || session.Debugger == "lldb":
+		return "hover", evaluateModeReadOnly, nil
+	case session.Language == types.LanguageGo:
+		if fields := strings.Fields(expression); len(fields) > 0 && fields[0] == "call" {
+			return "", evaluateModeReadOnly, errors.SideEffectRejected(expression, "function call")
+		}
+		return context, evaluateModeReadOnly, nil
+	}
+
+	if construct := sideEffectSyntax(expression, session.Language != types.LanguagePython); construct != "" {
+		return "", evaluateModeReadOnlyChecked, errors.SideEffectRejected(expression, construct)
+	}
+	return context, evaluateModeReadOnlyChecked, nil
+}
+
+// sideEffectSyntax returns the construct that makes an expression look like it
+// has side effects (a function call or an assignment), or "" if there is none.
+// String literals are skipped. Being syntactic, it can't see code that runs
+// behind a property or an overloaded operator. With increments set, ++ and --
+// count as assignments (C-like languages).
+func sideEffectSyntax(expression string, increments bool) string {
+	var quote byte
+	prev := func(i int) byte {
+		for i--; i >= 0; i-- {
+			if expression[i] != ' ' && expression[i] != '\t' {
+				return expression[i]
+			}
+		}
+		return 0
+	}
+
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(':
+			p := prev(i)
+			if p == ']' || isIdentByte(p) {
+				if !isOperatorWord(precedingWord(expression[:i])) {
+					return "function call"
+				}
+			}
+		case '=':
+			next := byte(0)
+			if i+1 < len(expression) {
+				next = expression[i+1]
+			}
+			if next == '=' {
+				i++ // == or ===
+				continue
+			}
+			if i > 0 {
+				switch expression[i-1] {
+				case '!', '=':
+					continue
+				case '<', '>':
+					// <= and >= compare; <<= and >>= assign
+					if i < 2 || expression[i-2] != expression[i-1] {
+						continue
+					}
+				}
+			}
+			return "assignment"
+		case '+', '-':
+			if increments && i+1 < len(expression) && expression[i+1] == c {
+				return "assignment"
+			}
+		}
+	}
+	return ""
+}
+
+// precedingWord returns the identifier that ends expr, ignoring trailing spaces
+func precedingWord(expr string) string {
+	expr = strings.TrimRight(expr, " \t")
+	start := len(expr)
+	for start > 0 && isIdentByte(expr[start-1]) {
+		start--
+	}
+	return expr[start:]
+}
+
+// isOperatorWord reports whether a word before a parenthesis is an operator
+// or keyword rather than a function name, e.g. "not (a or b)" or "sizeof(x)"
+func isOperatorWord(word string) bool {
+	switch word {
+	case "and", "or", "not", "in", "is", "if", "else", "sizeof", "typeof", "instanceof", "return":
+		return true
+	}
+	return false
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
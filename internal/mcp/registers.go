@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// handleDebugRegisters returns the CPU registers of a frame from the
+// adapter's Registers scope (GDB and LLDB sessions only)
+func (s *Server) handleDebugRegisters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !isNativeDebuggerLanguage(session.Language) && session.Debugger == "" {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"debug_registers only works with GDB/LLDB sessions (C, C++, Rust, Swift, native). "+
+				"Current session language: %s.", session.Language)), nil
+	}
+
+	var names []string
+	if namesJSON, err := request.RequireString("names"); err == nil && namesJSON != "" {
+		if err := json.Unmarshal([]byte(namesJSON), &names); err != nil {
+			return mcp.NewToolResultError(errors.InvalidJSON("names", err, `["rip", "rsp", "rax"]`).Error()), nil
+		}
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.ToLower(name)] = true
+	}
+
+	var format *dap.ValueFormat
+	hex := request.GetBool("hex", false)
+	if hex {
+		format = &dap.ValueFormat{Hex: true}
+	}
+
+	// Default to the top frame of the requested thread, or the focused frame
+	frameID, ok := requestFrame(request, session, client)
+	if !ok {
+		return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+	}
+
+	scopes, err := client.Scopes(frameID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get scopes for frame %d: %v", frameID, err)), nil
+	}
+	var registersScope *dap.Scope
+	for i, scope := range scopes {
+		if isRegistersScope(scope) {
+			registersScope = &scopes[i]
+			break
+		}
+	}
+	if registersScope == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("frame %d has no Registers scope; the adapter doesn't expose registers for it", frameID)), nil
+	}
+
+	vars, err := client.VariablesWithFormat(registersScope.VariablesReference, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get registers: %v", err)), nil
+	}
+
+	// LLDB groups registers into sets ("General Purpose Registers", ...); GDB
+	// lists them directly. Without a name filter only the first set is expanded,
+	// since the others hold hundreds of vector and floating point registers.
+	registers := make([]map[string]interface{}, 0)
+	var otherGroups []string
+	addRegister := func(v dap.Variable, group string) {
+		if len(wanted) > 0 && !wanted[strings.ToLower(v.Name)] {
+			return
+		}
+		register := map[string]interface{}{
+			"name":  v.Name,
+			"value": v.Value,
+		}
+		if v.Type != "" {
+			register["type"] = v.Type
+		}
+		if group != "" {
+			register["group"] = group
+		}
+		registers = append(registers, register)
+	}
+	expandedGroups := 0
+	for _, v := range vars {
+		if v.VariablesReference == 0 {
+			addRegister(v, "")
+			continue
+		}
+		if len(wanted) == 0 && expandedGroups > 0 {
+			otherGroups = append(otherGroups, v.Name)
+			continue
+		}
+		groupVars, err := client.VariablesWithFormat(v.VariablesReference, format)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get %s: %v", v.Name, err)), nil
+		}
+		expandedGroups++
+		for _, gv := range groupVars {
+			addRegister(gv, v.Name)
+		}
+	}
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"frameId":   frameID,
+		"registers": registers,
+	}
+	if hex {
+		result["format"] = "hex"
+		if !client.Capabilities().SupportsValueFormattingOptions {
+			result["formatNote"] = "the adapter doesn't support value formatting, so values are as it formats them"
+		}
+	}
+	if len(otherGroups) > 0 {
+		result["otherGroups"] = otherGroups
+	}
+	if len(wanted) > 0 {
+		found := make(map[string]bool, len(registers))
+		for _, r := range registers {
+			found[strings.ToLower(r["name"].(string))] = true
+		}
+		var missing []string
+		for _, name := range names {
+			if !found[strings.ToLower(name)] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			result["notFound"] = missing
+		}
+	}
+
+	return jsonResult(result)
+}
+
+// isRegistersScope reports whether a scope holds CPU registers
+func isRegistersScope(scope dap.Scope) bool {
+	return scope.PresentationHint == "registers" || strings.HasPrefix(strings.ToLower(scope.Name), "register")
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Resource URIs
+const (
+	sessionsResourceURI         = "dap://sessions"
+	sessionSnapshotResourceURI  = "dap://sessions/{id}/snapshot"
+	sessionSnapshotResourceBase = "dap://sessions/"
+)
+
+// resourceReadKey marks the context of a tool handler run to read a resource.
+// Such reads leave the session's snapshot history and delta baseline alone,
+// so reading a resource doesn't change what tool calls see.
+type resourceReadKey struct{}
+
+// isResourceRead reports whether a handler runs to read a resource
+func isResourceRead(ctx context.Context) bool {
+	return ctx.Value(resourceReadKey{}) != nil
+}
+
+// registerResources exposes session state as read-only MCP resources: the
+// session list and each stopped session's snapshot. They return the same
+// JSON as debug_list_sessions and debug_snapshot.
+func (s *Server) registerResources() {
+	s.mcpServer.AddResource(
+		mcp.NewResource(sessionsResourceURI, "Debug sessions",
+			mcp.WithResourceDescription("The active debug sessions with their language, status, and program, as returned by debug_list_sessions"),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return s.toolResource(ctx, request.Params.URI, s.handleDebugListSessions, nil)
+		},
+	)
+
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(sessionSnapshotResourceURI, "Debug session snapshot",
+			mcp.WithTemplateDescription("The threads, stacks, and variables of a stopped debug session, as returned by debug_snapshot"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			id := strings.TrimSuffix(strings.TrimPrefix(request.Params.URI, sessionSnapshotResourceBase), "/snapshot")
+			if id == "" || strings.Contains(id, "/") {
+				return nil, fmt.Errorf("invalid session snapshot URI %q, expected %s", request.Params.URI, sessionSnapshotResourceURI)
+			}
+			return s.toolResource(ctx, request.Params.URI, s.handleDebugSnapshot, map[string]interface{}{"sessionId": id})
+		},
+	)
+}
+
+// toolResource reads a resource by running a tool handler, returning its JSON
+// result as the resource's content and its error result as an error
+func (s *Server) toolResource(ctx context.Context, uri string, handler server.ToolHandlerFunc, args map[string]interface{}) ([]mcp.ResourceContents, error) {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+
+	result, err := handler(context.WithValue(ctx, resourceReadKey{}, true), request)
+	if err != nil {
+		return nil, err
+	}
+	text := toolResultText(result)
+	if result.IsError {
+		return nil, fmt.Errorf("%s", text)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: text},
+	}, nil
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/go-dap"
+
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Bounds for restartOnExit
+const (
+	defaultMaxRestarts = 3
+	maxRestartsLimit   = 10
+)
+
+// restartPolicy holds what is needed to relaunch a session launched with
+// restartOnExit: the adapter and the original launch arguments
+type restartPolicy struct {
+	adapter     adapters.Adapter
+	program     string
+	args        map[string]interface{}
+	maxRestarts int
+}
+
+// watchForRestart relaunches the session whenever the debuggee exits with a
+// non-zero code, until maxRestarts is reached. It must be called before the
+// launch sequence so no exited event is missed.
+func (s *Server) watchForRestart(sessionID string, client *internaldap.Client, policy *restartPolicy) {
+	s.restartMu.Lock()
+	s.restartPolicies[sessionID] = policy
+	s.restartMu.Unlock()
+
+	client.AddEventHandler(s.restartOnFailedExit(sessionID, client))
+}
+
+// restartOnFailedExit returns an event handler that restarts the session when
+// the client reports a non-zero exit code
+func (s *Server) restartOnFailedExit(sessionID string, client *internaldap.Client) func(dap.Message) {
+	return func(msg dap.Message) {
+		exited, ok := msg.(*dap.ExitedEvent)
+		if !ok || exited.Body.ExitCode == 0 {
+			return
+		}
+		// Events are delivered on the client's read loop, which must keep
+		// running for the relaunch requests to get responses
+		go s.restartSession(sessionID, client, exited.Body.ExitCode)
+	}
+}
+
+// stopWatchingForRestart disables restartOnExit for a session, e.g. before an
+// explicit disconnect kills the debuggee
+func (s *Server) stopWatchingForRestart(sessionID string) {
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+
+	delete(s.restartPolicies, sessionID)
+}
+
+// restartSession spawns a new adapter for the session and reruns the launch
+// sequence with the stored arguments, restoring the session's breakpoints
+func (s *Server) restartSession(sessionID string, exitedClient *internaldap.Client, exitCode int) {
+	// Serialize restarts so duplicate exit events can't relaunch twice
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+
+	policy, ok := s.restartPolicies[sessionID]
+	if !ok {
+		return
+	}
+	session, err := s.sessionManager.GetSession(sessionID)
+	if err != nil || session.GetClient() != exitedClient {
+		return // Session ended or was already restarted
+	}
+
+	if session.GetInfo().Restarts >= policy.maxRestarts {
+		log.Printf("Session %s: debuggee exited with code %d; restart limit of %d reached", sessionID, exitCode, policy.maxRestarts)
+		delete(s.restartPolicies, sessionID)
+		_ = s.sessionManager.UpdateSessionStatus(sessionID, types.SessionStatusTerminated)
+		return
+	}
+
+	client, cmd, err := adapters.SpawnAndConnect(context.Background(), policy.adapter, policy.program, policy.args)
+	if err != nil {
+		log.Printf("Session %s: failed to respawn adapter after exit code %d: %v", sessionID, exitCode, err)
+		delete(s.restartPolicies, sessionID)
+		_ = s.sessionManager.UpdateSessionStatus(sessionID, types.SessionStatusTerminated)
+		return
+	}
+
+	restarts, err := s.sessionManager.ReplaceSessionAdapter(sessionID, client, cmd)
+	if err != nil {
+		_ = client.Close()
+		if cmd != nil && cmd.Process != nil {
+			_ = cmd.Process.Kill() // Error ignored: best-effort cleanup
+		}
+		return
+	}
+	log.Printf("Session %s: debuggee exited with code %d; restarting (%d/%d)", sessionID, exitCode, restarts, policy.maxRestarts)
+
+	s.configureClient(session, client, policy.adapter)
+	client.AddEventHandler(s.restartOnFailedExit(sessionID, client))
+
+	entry, debugErr := s.runLaunchSequence(session, client, policy.adapter, policy.program, policy.args)
+	if debugErr != nil {
+		log.Printf("Session %s: relaunch failed: %v", sessionID, debugErr)
+		delete(s.restartPolicies, sessionID)
+		_ = s.sessionManager.UpdateSessionStatus(sessionID, types.SessionStatusTerminated)
+		return
+	}
+	_ = s.sessionManager.UpdateSessionStatus(sessionID, launchedStatus(entry))
+	if p := client.ProcessInfo(); p != nil {
+		_ = s.sessionManager.SetSessionDebuggee(sessionID, p.PID, p.Name)
+	}
+}
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// handleDebugContinueToReturn steps out of the current function and reports the
+// value it returned, read from the variables adapters add after a step out
+func (s *Server) handleDebugContinueToReturn(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	threadID, ok := requestThread(request, session, client)
+	if !ok {
+		return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+	}
+
+	// Remember which function we are leaving
+	function := ""
+	if frames, _, err := client.StackTrace(threadID, 0, 1); err == nil && len(frames) > 0 {
+		function = frames[0].Name
+	}
+
+	stoppedInfo, err := client.StepOutAndWait(threadID, 30*time.Second)
+	if err != nil {
+		return mcp.NewToolResultError(errors.StepFailed("out", err).Error()), nil
+	}
+	_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"status":    "stopped",
+		"reason":    stoppedInfo.Reason,
+		"threadId":  stoppedInfo.ThreadID,
+	}
+	if function != "" {
+		result["function"] = function
+	}
+
+	frames, _, err := client.StackTrace(stoppedInfo.ThreadID, 0, 1)
+	if err != nil || len(frames) == 0 {
+		result["note"] = "Stopped, but the stack trace is unavailable, so the return value could not be read."
+		return jsonResult(result)
+	}
+	frame := frames[0]
+	location := map[string]interface{}{
+		"frameId": frame.Id,
+		"name":    frame.Name,
+		"line":    frame.Line,
+	}
+	if frame.Source != nil {
+		location["source"] = frame.Source.Path
+	}
+	result["location"] = location
+
+	// A breakpoint inside the function can stop the thread before it returns
+	if stoppedInfo.Reason != "step" {
+		result["note"] = "Stopped with reason '" + stoppedInfo.Reason + "' before the function returned; call debug_continue_to_return again to finish stepping out."
+		return jsonResult(result)
+	}
+
+	returned := s.returnValues(client, frame.Id)
+	if len(returned) == 0 {
+		result["note"] = returnValueNote(session.Language)
+		return jsonResult(result)
+	}
+	result["returnValues"] = returned
+	if len(returned) == 1 {
+		result["returnValue"] = returned[0]["value"]
+		result["returnType"] = returned[0]["type"]
+	}
+	return jsonResult(result)
+}
+
+// returnValues looks up the return values an adapter exposes in the frame's
+// scopes after a step out: a dedicated scope, or marked variables among the locals
+func (s *Server) returnValues(client *internaldap.Client, frameID int) []map[string]interface{} {
+	scopes, err := client.Scopes(frameID)
+	if err != nil {
+		return nil
+	}
+
+	var returned []map[string]interface{}
+	for _, scope := range scopes {
+		if scope.VariablesReference == 0 || (scope.Expensive && !isReturnValueName(scope.Name)) {
+			continue
+		}
+		vars, err := client.Variables(scope.VariablesReference, "", 0, 0)
+		if err != nil {
+			continue
+		}
+		wholeScope := isReturnValueName(scope.Name)
+		for _, v := range vars {
+			if !wholeScope && !isReturnValueName(v.Name) {
+				continue
+			}
+			returned = append(returned, s.returnValueInfo(v))
+		}
+	}
+	return returned
+}
+
+// returnValueInfo describes a return value variable
+func (s *Server) returnValueInfo(v dap.Variable) map[string]interface{} {
+	info := map[string]interface{}{
+		"name": v.Name,
+		"type": v.Type,
+	}
+	s.setValue(info, "value", v.Value)
+	if v.VariablesReference > 0 {
+		info["variablesReference"] = v.VariablesReference
+	}
+	addPresentationHint(info, v.PresentationHint)
+	return info
+}
+
+// isReturnValueName reports whether a variable or scope name marks a return
+// value: Delve names them ~r0, ~r1, ...; debugpy lists "(return) func"; lldb-dap
+// and others use "(Return Value)" or a "Return Value" scope
+func isReturnValueName(name string) bool {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	return strings.HasPrefix(name, "~r") ||
+		strings.HasPrefix(lower, "(return)") ||
+		strings.Contains(lower, "return value") ||
+		lower == "__return__"
+}
+
+// returnValueNote explains a missing return value for the session's adapter
+func returnValueNote(lang types.Language) string {
+	switch lang {
+	case types.LanguagePython:
+		return "The function returned, but debugpy reported no return value. It is only shown when the launch configuration doesn't disable showReturnValue."
+	case types.LanguageGo:
+		return "The function returned, but Delve reported no return value (functions without results, or results optimized away)."
+	}
+	return "The function returned, but this adapter does not expose return values. Use debug_snapshot or debug_evaluate to inspect the caller's state."
+}
@@ -8,21 +8,64 @@
 //   - debug_attach: Attach to an existing process or browser
 //   - debug_disconnect: Disconnect from a session
 //   - debug_list_sessions: List active sessions
+//   - debug_ping: Check a session's adapter and debuggee are alive
+//   - debug_server_info: Get the server's version, mode, permissions, and limits
+//   - debug_list_configs: List and validate launch.json configurations
+//   - debug_export_session: Export a session's launch arguments and breakpoints
+//   - debug_import_session: Launch a session from an export
+//   - debug_install_adapter: Install a language's debug adapter (with allowInstall)
 //
 // Inspection (always available):
 //   - debug_snapshot: Get complete debug state (threads, stacks, variables)
+//   - debug_frame: Show a stack frame with its source and locals, moving up or down
+//   - debug_focus: Set the thread and frame tools default to
 //   - debug_evaluate: Evaluate expressions in debug context
+//   - debug_inspect_tree: Render a nested value as an indented text tree
+//   - debug_read_variable_bytes: Read a truncated value's full bytes from memory
+//   - debug_get_full_value: Get a value's complete text, such as a long traceback
+//   - debug_capabilities: Get the debug adapter's DAP capabilities
+//   - debug_adapter_log: Get the debug adapter's captured stderr
+//   - debug_get_output: Get the program's output, by category and source location
+//   - debug_list_breakpoints: List breakpoints and their current verification state
+//   - debug_threads: List threads with their lifecycle status
+//   - debug_goroutines: List a Go session's goroutines with their state and location
+//   - debug_goroutine_stack: Get the stack of any goroutine
+//   - debug_modules: List loaded modules, flagging those loaded since the last call
+//   - debug_source: Get source content from disk or the adapter
+//   - debug_find_source: Find the source files of a module or function by name
+//   - debug_registers: Read CPU registers (GDB/LLDB sessions)
+//   - debug_exception: Get the current exception and its chain of causes
+//   - debug_diff: Compare two snapshots, returning only what changed
 //
 // Control (full mode only):
 //   - debug_breakpoints: Set/clear breakpoints
+//   - debug_set_function_breakpoints: Break on functions by name or regex
+//   - debug_break_when: Break when an expression next has its current value
 //   - debug_step: Step over/into/out
+//   - debug_trace_expression: Step repeatedly, recording an expression's value at each step
+//   - debug_continue_to_return: Step out and report the function's return value
 //   - debug_continue: Resume execution
 //   - debug_pause: Pause execution
 //   - debug_set_variable: Modify variable values
 //   - debug_run_to_line: Run to a specific line
+//   - debug_wait_for_output: Continue until the program prints a matching line
+//   - debug_custom_request: Send an adapter-specific DAP request
+//   - debug_set_debug_options: Change debug options such as justMyCode
+//   - debug_call_function: Call a function in a Go program (with allowExecute)
+//
+// Resources:
+//   - dap://sessions: The active sessions, as from debug_list_sessions
+//   - dap://sessions/{id}/snapshot: A stopped session's state, as from debug_snapshot
+//
+// Prompts:
+//   - diagnose_crash: Find the cause of a crash or panic
+//   - find_variable_change: Find the line where a variable gets a wrong value
+//   - trace_exception: Trace an exception back to where it was raised
 package mcp
 
 import (
+	"sync"
+
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/ctagard/dap-mcp/internal/adapters"
@@ -38,6 +81,10 @@ type Server struct {
 	adapterReg     *adapters.Registry
 	config         *config.Config
 	versionChecker *version.Checker
+
+	// Sessions launched with restartOnExit, by session ID
+	restartPolicies map[string]*restartPolicy
+	restartMu       sync.Mutex
 }
 
 // NewServer creates a new DAP-MCP server
@@ -47,25 +94,33 @@ func NewServer(cfg *config.Config, versionChecker *version.Checker) *Server {
 		"dap-mcp",
 		version.Version,
 		server.WithToolCapabilities(true),
+		// mcp-go doesn't handle resources/subscribe, so subscriptions
+		// aren't offered
+		server.WithResourceCapabilities(false, false),
+		server.WithPromptCapabilities(false),
 		server.WithRecovery(),
 	)
 
 	// Create session manager
 	sessionManager := dap.NewSessionManager(cfg.MaxSessions, cfg.SessionTimeout)
+	sessionManager.SetTerminateAttached(cfg.TerminateAttachedOnShutdown)
 
 	// Create adapter registry
 	adapterReg := adapters.NewRegistry(cfg)
 
 	s := &Server{
-		mcpServer:      mcpServer,
-		sessionManager: sessionManager,
-		adapterReg:     adapterReg,
-		config:         cfg,
-		versionChecker: versionChecker,
+		mcpServer:       mcpServer,
+		sessionManager:  sessionManager,
+		adapterReg:      adapterReg,
+		config:          cfg,
+		versionChecker:  versionChecker,
+		restartPolicies: make(map[string]*restartPolicy),
 	}
 
-	// Register all tools
+	// Register all tools, resources, and prompts
 	s.registerTools()
+	s.registerResources()
+	s.registerPrompts()
 
 	return s
 }
@@ -87,6 +142,11 @@ func (s *Server) Close() {
 	s.sessionManager.Close()
 }
 
+// GetMCPServer returns the underlying MCP server
+func (s *Server) GetMCPServer() *server.MCPServer {
+	return s.mcpServer
+}
+
 // GetSessionManager returns the session manager
 func (s *Server) GetSessionManager() *dap.SessionManager {
 	return s.sessionManager
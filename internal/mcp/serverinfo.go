@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/version"
+)
+
+// handleDebugServerInfo reports the server's version, mode, permissions,
+// limits, and languages, so a client can tell what is allowed before trying
+// it, along with the result of the background update check
+func (s *Server) handleDebugServerInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cfg := s.config
+
+	languages := s.adapterReg.Languages()
+	names := make([]string, len(languages))
+	for i, lang := range languages {
+		names[i] = string(lang)
+	}
+
+	result := map[string]interface{}{
+		"name":         "dap-mcp",
+		"version":      version.Version,
+		"mode":         string(cfg.Mode),
+		"controlTools": cfg.CanUseControlTools(),
+		"permissions": map[string]interface{}{
+			"spawn":      cfg.CanSpawn(),
+			"attach":     cfg.CanAttach(),
+			"modify":     cfg.CanModifyVariables(),
+			"execute":    cfg.CanExecute(),
+			"evaluation": cfg.EvaluationLevel(),
+			"commands":   cfg.AllowCommands,
+			"install":    cfg.AllowInstall,
+		},
+		"limits": map[string]interface{}{
+			"maxSessions":            cfg.MaxSessions,
+			"activeSessions":         len(s.sessionManager.ListSessions()),
+			"sessionTimeout":         cfg.SessionTimeout.String(),
+			"maxVariableValueLength": cfg.MaxVariableValueLength,
+			"maxSourceSize":          cfg.MaxSourceSize,
+		},
+		"languages": names,
+	}
+	if cfg.Path != "" {
+		result["configPath"] = cfg.Path
+	}
+	if restrictions := serverRestrictions(cfg); len(restrictions) > 0 {
+		result["restrictions"] = restrictions
+	}
+
+	update := map[string]interface{}{"checked": false}
+	if s.versionChecker != nil {
+		if info := s.versionChecker.GetUpdateInfo(); info != nil {
+			update = map[string]interface{}{
+				"checked":         true,
+				"updateAvailable": info.UpdateAvailable,
+				"checkedAt":       info.CheckedAt,
+			}
+			if info.LatestVersion != "" {
+				update["latestVersion"] = info.LatestVersion
+			}
+			if info.UpdateAvailable {
+				update["releaseUrl"] = info.ReleaseURL
+				update["message"] = info.UpdateMessage()
+			}
+			if info.Error != "" {
+				update["error"] = info.Error
+			}
+		}
+	}
+	result["update"] = update
+
+	return jsonResult(result)
+}
+
+// serverRestrictions describes what the configuration doesn't allow, and the
+// setting that would allow it
+func serverRestrictions(cfg *config.Config) []string {
+	var restrictions []string
+	if !cfg.CanUseControlTools() {
+		restrictions = append(restrictions, "Readonly mode: breakpoints, stepping, continuing, and the other control tools are not available (mode \"full\" enables them)")
+	}
+	if !cfg.CanSpawn() {
+		restrictions = append(restrictions, "debug_launch cannot start debug adapters (allowSpawn)")
+	}
+	if !cfg.CanAttach() {
+		restrictions = append(restrictions, "debug_attach is not allowed (allowAttach)")
+	}
+	if cfg.CanUseControlTools() && !cfg.CanModifyVariables() {
+		restrictions = append(restrictions, "debug_set_variable is not allowed (allowModify)")
+	}
+	switch cfg.EvaluationLevel() {
+	case config.EvaluationNone:
+		restrictions = append(restrictions, "Expressions cannot be evaluated (evaluation or evaluateReadOnly)")
+	case config.EvaluationReadOnly:
+		restrictions = append(restrictions, "Only expressions without side effects can be evaluated, and debug_execute_command is not allowed (evaluation \"full\")")
+	}
+	if !cfg.CanExecute() {
+		restrictions = append(restrictions, "debug_custom_request and debug_call_function are not allowed (allowExecute)")
+	}
+	if !cfg.AllowCommands {
+		restrictions = append(restrictions, "launch.json command inputs and tasks.json tasks are not run (allowCommands)")
+	}
+	return restrictions
+}
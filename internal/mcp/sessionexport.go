@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// sessionExportVersion is the version of the blob debug_export_session writes
+const sessionExportVersion = 1
+
+// launchBreakpointParams are the debug_launch parameters an export carries
+// separately, as the breakpoints the session has now rather than at launch
+var launchBreakpointParams = []string{"breakpoints", "functionBreakpoints", "regexBreakpoints"}
+
+// sessionExport is the blob written by debug_export_session and read by
+// debug_import_session
+type sessionExport struct {
+	Version             int                      `json:"version"`
+	Language            string                   `json:"language"`
+	Program             string                   `json:"program"`
+	Launch              map[string]interface{}   `json:"launch"`
+	Breakpoints         []exportedBreakpoint     `json:"breakpoints"`
+	FunctionBreakpoints []dap.FunctionBreakpoint `json:"functionBreakpoints"`
+	RegexBreakpoints    []string                 `json:"regexBreakpoints"`
+}
+
+// exportedBreakpoint is a source breakpoint in debug_launch's breakpoints format
+type exportedBreakpoint struct {
+	Path         string `json:"path"`
+	Line         int    `json:"line"`
+	Column       int    `json:"column,omitempty"`
+	Condition    string `json:"condition,omitempty"`
+	HitCondition string `json:"hitCondition,omitempty"`
+	LogMessage   string `json:"logMessage,omitempty"`
+}
+
+// handleDebugExportSession captures how a session was launched and the
+// breakpoints it has now, so debug_import_session can start it again later
+// or on another machine
+func (s *Server) handleDebugExportSession(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID, err := request.RequireString("sessionId")
+	if err != nil {
+		return mcp.NewToolResultError(errors.MissingParameter("sessionId", "Provide the sessionId returned from debug_launch. Use debug_list_sessions to see active sessions.").Error()), nil
+	}
+	session, err := s.sessionManager.GetSession(sessionID)
+	if err != nil {
+		return mcp.NewToolResultError(errors.SessionNotFound(sessionID).Error()), nil
+	}
+
+	launch := session.LaunchRequest()
+	if len(launch) == 0 {
+		return mcp.NewToolResultError(errors.InvalidParameter("sessionId", sessionID,
+			"a session started with debug_launch; attached sessions can't be exported").Error()), nil
+	}
+	for _, name := range launchBreakpointParams {
+		delete(launch, name)
+	}
+	// Options changed since launch (e.g. justMyCode) apply to the next launch
+	for name, value := range session.DebugOptions() {
+		launch[name] = value
+	}
+
+	export := sessionExport{
+		Version:             sessionExportVersion,
+		Language:            string(session.Language),
+		Program:             session.Program,
+		Launch:              launch,
+		Breakpoints:         make([]exportedBreakpoint, 0),
+		FunctionBreakpoints: session.FunctionBreakpoints(),
+		RegexBreakpoints:    session.RegexBreakpoints(),
+	}
+	sourceBreakpoints := session.AllSourceBreakpoints()
+	paths := make([]string, 0, len(sourceBreakpoints))
+	for path := range sourceBreakpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, bp := range sourceBreakpoints[path] {
+			export.Breakpoints = append(export.Breakpoints, exportedBreakpoint{
+				Path:         path,
+				Line:         bp.Line,
+				Column:       bp.Column,
+				Condition:    bp.Condition,
+				HitCondition: bp.HitCondition,
+				LogMessage:   bp.LogMessage,
+			})
+		}
+	}
+	if export.FunctionBreakpoints == nil {
+		export.FunctionBreakpoints = make([]dap.FunctionBreakpoint, 0)
+	}
+	if export.RegexBreakpoints == nil {
+		export.RegexBreakpoints = make([]string, 0)
+	}
+
+	return jsonResult(map[string]interface{}{
+		"sessionId": session.ID,
+		"session":   export,
+	})
+}
+
+// handleDebugImportSession launches a new session from a debug_export_session
+// blob, with the exported launch arguments and breakpoints
+func (s *Server) handleDebugImportSession(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	blob, err := request.RequireString("session")
+	if err != nil {
+		return mcp.NewToolResultError(errors.MissingParameter("session", "Provide the session object returned by debug_export_session, as a JSON string.").Error()), nil
+	}
+
+	var export sessionExport
+	if err := json.Unmarshal([]byte(blob), &export); err != nil {
+		return mcp.NewToolResultError(errors.InvalidJSON("session", err, `{"version": 1, "language": "go", "program": "/src/main.go", "launch": {...}, "breakpoints": [...]}`).Error()), nil
+	}
+	if export.Version != sessionExportVersion {
+		return mcp.NewToolResultError(errors.InvalidParameter("session.version", export.Version, "1, from debug_export_session").Error()), nil
+	}
+	if len(export.Launch) == 0 {
+		return mcp.NewToolResultError(errors.InvalidParameter("session.launch", export.Launch, "the debug_launch arguments from debug_export_session").Error()), nil
+	}
+
+	args := make(map[string]interface{}, len(export.Launch)+len(launchBreakpointParams))
+	for name, value := range export.Launch {
+		args[name] = value
+	}
+	for _, name := range launchBreakpointParams {
+		delete(args, name)
+	}
+	if len(export.Breakpoints) > 0 {
+		args["breakpoints"] = mustMarshal(export.Breakpoints)
+	}
+	if len(export.FunctionBreakpoints) > 0 {
+		args["functionBreakpoints"] = mustMarshal(export.FunctionBreakpoints)
+	}
+	if len(export.RegexBreakpoints) > 0 {
+		args["regexBreakpoints"] = mustMarshal(export.RegexBreakpoints)
+	}
+
+	return s.handleDebugLaunch(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "debug_launch", Arguments: args},
+	})
+}
+
+// mustMarshal encodes a value that always marshals as a JSON string parameter
+func mustMarshal(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
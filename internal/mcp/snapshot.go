@@ -0,0 +1,368 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-dap"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+)
+
+// snapshotDiff tracks value digests while a snapshot is built so that delta
+// snapshots can report only threads, frames, and variables that changed.
+//
+// Digest keys are rooted at the thread ID so a snapshot filtered to one thread
+// only replaces that thread's entries:
+//
+//	"<threadId>"                           -> thread name
+//	"<threadId>/stack"                     -> frame names and lines
+//	"<threadId>/<frame>/<scope>/<variable>" -> variable type and value
+//
+// Variables are keyed by frame position and name rather than by
+// variablesReference, because references are reassigned on every stop.
+type snapshotDiff struct {
+	prev    map[string]string
+	current map[string]string
+
+	unchangedStacks    int
+	unchangedVariables int
+}
+
+// newSnapshotDiff creates a diff against the previous snapshot digest (may be nil)
+func newSnapshotDiff(prev map[string]string) *snapshotDiff {
+	return &snapshotDiff{
+		prev:    prev,
+		current: make(map[string]string),
+	}
+}
+
+// hasBaseline reports whether there is a previous snapshot to compare against
+func (d *snapshotDiff) hasBaseline() bool {
+	return d.prev != nil
+}
+
+// record stores a digest entry and reports whether it differs from the previous snapshot
+func (d *snapshotDiff) record(key, value string) bool {
+	d.current[key] = value
+	if d.prev == nil {
+		return true
+	}
+	old, ok := d.prev[key]
+	return !ok || old != value
+}
+
+// recordThread records a thread's name
+func (d *snapshotDiff) recordThread(threadID int, name string) bool {
+	return d.record(fmt.Sprintf("%d", threadID), name)
+}
+
+// recordStack records the shape of a thread's call stack
+func (d *snapshotDiff) recordStack(threadID int, frames []map[string]interface{}) bool {
+	parts := make([]string, len(frames))
+	for i, f := range frames {
+		parts[i] = fmt.Sprintf("%v@%v", f["name"], f["line"])
+	}
+	changed := d.record(fmt.Sprintf("%d/stack", threadID), strings.Join(parts, ";"))
+	if !changed {
+		d.unchangedStacks++
+	}
+	return changed
+}
+
+// recordVariable records a variable's type and value within a frame scope
+func (d *snapshotDiff) recordVariable(threadID, frameIndex int, scopeName, name, varType, value string) bool {
+	key := fmt.Sprintf("%d/%d/%s/%s", threadID, frameIndex, scopeName, name)
+	changed := d.record(key, varType+"="+value)
+	if !changed {
+		d.unchangedVariables++
+	}
+	return changed
+}
+
+// state returns what this snapshot captured, for debug_diff, with the stop
+// reason of each captured thread that has one under "<threadId>/stopped"
+func (d *snapshotDiff) state(stops []internaldap.StoppedInfo) map[string]string {
+	state := make(map[string]string, len(d.current)+len(stops))
+	for key, value := range d.current {
+		state[key] = value
+	}
+	for _, stop := range stops {
+		if _, ok := d.current[fmt.Sprintf("%d", stop.ThreadID)]; ok {
+			state[fmt.Sprintf("%d/stopped", stop.ThreadID)] = stop.Reason
+		}
+	}
+	return state
+}
+
+// removedVariables counts variables present in the previous snapshot of the
+// given threads that are no longer present
+func (d *snapshotDiff) removedVariables(threadIDs []int) int {
+	removed := 0
+	for key := range d.prev {
+		if _, ok := d.current[key]; ok || strings.HasSuffix(key, "/stack") {
+			continue
+		}
+		for _, tid := range threadIDs {
+			if strings.HasPrefix(key, fmt.Sprintf("%d/", tid)) {
+				removed++
+				break
+			}
+		}
+	}
+	return removed
+}
+
+// digest returns the digest to store for the next snapshot. When only some
+// threads were captured, entries for the other threads are carried over.
+func (d *snapshotDiff) digest(partial bool, threadIDs []int) map[string]string {
+	if !partial {
+		return d.current
+	}
+
+	merged := make(map[string]string, len(d.prev)+len(d.current))
+	for key, value := range d.prev {
+		keep := true
+		for _, tid := range threadIDs {
+			prefix := fmt.Sprintf("%d", tid)
+			if key == prefix || strings.HasPrefix(key, prefix+"/") {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			merged[key] = value
+		}
+	}
+	for key, value := range d.current {
+		merged[key] = value
+	}
+	return merged
+}
+
+// stoppedThreadsList lists the threads of a stop with the reason each one's
+// stopped event gave, for results to report as stoppedThreads
+func stoppedThreadsList(stops []internaldap.StoppedInfo) []map[string]interface{} {
+	list := make([]map[string]interface{}, len(stops))
+	for i, stop := range stops {
+		entry := map[string]interface{}{
+			"threadId": stop.ThreadID,
+			"reason":   stop.Reason,
+		}
+		if stop.Description != "" {
+			entry["description"] = stop.Description
+		}
+		if stop.Text != "" {
+			entry["text"] = stop.Text
+		}
+		if len(stop.HitBreakpointIDs) > 0 {
+			entry["hitBreakpointIds"] = stop.HitBreakpointIDs
+		}
+		list[i] = entry
+	}
+	return list
+}
+
+// wantScope reports whether a snapshot should expand a scope's variables. With
+// no filter, only locals and arguments are expanded. A filter lists scope names
+// (case-insensitive, matching by prefix so "Local" matches "Local: main"), or
+// "*" for every scope. Expensive scopes are only expanded when named explicitly.
+func wantScope(scope dap.Scope, filter []string) bool {
+	name := strings.ToLower(scope.Name)
+	if len(filter) == 0 {
+		if scope.Expensive {
+			return false
+		}
+		switch scope.PresentationHint {
+		case "locals", "arguments":
+			return true
+		case "registers":
+			return false
+		}
+		return strings.HasPrefix(name, "local") || strings.HasPrefix(name, "argument")
+	}
+
+	for _, f := range filter {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "*" && !scope.Expensive {
+			return true
+		}
+		if f != "" && f != "*" && strings.HasPrefix(name, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// hideSystemThreads drops threads whose top frame is in one of the hidden
+// functions (matched by substring, so "netpoll" matches runtime.netpollblock),
+// such as goroutines parked by the runtime. Threads whose stack can't be read
+// are kept. Each thread takes a stackTrace request to check, so checking
+// stops once limit threads are kept. It returns the kept threads, how many
+// were hidden, and how many were left unchecked.
+func hideSystemThreads(client *internaldap.Client, threads []dap.Thread, hiddenFrames []string, limit int) ([]dap.Thread, int, int) {
+	if len(hiddenFrames) == 0 {
+		return threads, 0, 0
+	}
+
+	kept := make([]dap.Thread, 0, min(len(threads), limit))
+	hidden := 0
+	for i, thread := range threads {
+		if len(kept) == limit {
+			return kept, hidden, len(threads) - i
+		}
+		frames, _, err := client.StackTrace(thread.Id, 0, 1)
+		if err != nil || len(frames) == 0 || !isHiddenFrame(frames[0].Name, hiddenFrames) {
+			kept = append(kept, thread)
+		} else {
+			hidden++
+		}
+	}
+	return kept, hidden, 0
+}
+
+// isHiddenFrame reports whether a frame name contains one of the hidden functions
+func isHiddenFrame(name string, hiddenFrames []string) bool {
+	for _, f := range hiddenFrames {
+		if f != "" && strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotBudgetReserve is room kept within maxBytes for the truncated summary
+const snapshotBudgetReserve = 512
+
+// fitSnapshot cuts a snapshot down to about maxBytes of JSON. It keeps what is
+// most useful first: the thread list, then frames from the top of every stack
+// down, then each kept frame's scopes and variables. Everything after the
+// first item that doesn't fit is left out and counted in a truncated summary.
+// It reports whether anything was cut.
+func fitSnapshot(snapshot map[string]interface{}, maxBytes int) bool {
+	if full, err := json.Marshal(snapshot); err != nil || len(full) <= maxBytes {
+		return false
+	}
+
+	threads, _ := snapshot["threads"].([]map[string]interface{})
+	stacks, _ := snapshot["stacks"].(map[string]interface{})
+	scopes, _ := snapshot["scopes"].(map[string]interface{})
+	variables, hasVariables := snapshot["variables"].(map[string]interface{})
+
+	keptThreads := make([]map[string]interface{}, 0, len(threads))
+	keptStacks := make(map[string]interface{})
+	keptScopes := make(map[string]interface{})
+	keptVariables := make(map[string]interface{})
+	snapshot["threads"] = keptThreads
+	snapshot["stacks"] = keptStacks
+	snapshot["scopes"] = keptScopes
+	if hasVariables {
+		snapshot["variables"] = keptVariables
+	}
+	base, _ := json.Marshal(snapshot)
+
+	used := len(base) + snapshotBudgetReserve
+	full := false
+	// fits adds an item's size, plus its key or separating comma, if it fits
+	// in the budget; once one doesn't, nothing more is added
+	fits := func(item interface{}, key string, first bool) bool {
+		if full {
+			return false
+		}
+		data, _ := json.Marshal(item)
+		size := len(data) + 1
+		if first && key != "" {
+			size += len(key) + 5 // "key":[...]
+		}
+		if used+size > maxBytes {
+			full = true
+			return false
+		}
+		used += size
+		return true
+	}
+	// appendItem adds an item to the list under key in a kept map
+	appendItem := func(kept map[string]interface{}, key string, item map[string]interface{}) {
+		list, _ := kept[key].([]map[string]interface{})
+		kept[key] = append(list, item)
+	}
+
+	for _, thread := range threads {
+		if fits(thread, "", false) {
+			keptThreads = append(keptThreads, thread)
+		}
+	}
+	snapshot["threads"] = keptThreads
+
+	// Frames from the top of every stack down, so each thread shows where it is
+	depth := 0
+	for _, thread := range keptThreads {
+		frames, _ := stacks[fmt.Sprint(thread["id"])].([]map[string]interface{})
+		depth = max(depth, len(frames))
+	}
+	for i := 0; i < depth; i++ {
+		for _, thread := range keptThreads {
+			key := fmt.Sprint(thread["id"])
+			frames, _ := stacks[key].([]map[string]interface{})
+			if i < len(frames) && fits(frames[i], key, i == 0) {
+				appendItem(keptStacks, key, frames[i])
+			}
+		}
+	}
+
+	// Then scopes and their variables, frame by frame
+	for _, thread := range keptThreads {
+		frames, _ := keptStacks[fmt.Sprint(thread["id"])].([]map[string]interface{})
+		for _, frame := range frames {
+			frameKey := fmt.Sprint(frame["id"])
+			frameScopes, _ := scopes[frameKey].([]map[string]interface{})
+			for i, scope := range frameScopes {
+				if !fits(scope, frameKey, i == 0) {
+					continue
+				}
+				appendItem(keptScopes, frameKey, scope)
+				if !hasVariables {
+					continue
+				}
+				refKey := fmt.Sprint(scope["variablesReference"])
+				vars, ok := variables[refKey].([]map[string]interface{})
+				if !ok {
+					continue
+				}
+				if len(vars) == 0 {
+					if fits(vars, refKey, true) {
+						keptVariables[refKey] = vars
+					}
+					continue
+				}
+				for j, v := range vars {
+					if fits(v, refKey, j == 0) {
+						appendItem(keptVariables, refKey, v)
+					}
+				}
+			}
+		}
+	}
+
+	snapshot["truncated"] = map[string]interface{}{
+		"maxBytes":         maxBytes,
+		"omittedThreads":   len(threads) - len(keptThreads),
+		"omittedFrames":    countItems(stacks) - countItems(keptStacks),
+		"omittedScopes":    countItems(scopes) - countItems(keptScopes),
+		"omittedVariables": countItems(variables) - countItems(keptVariables),
+		"hint": "Raise maxBytes or narrow the snapshot with threadId, maxStackDepth, or scopes. " +
+			"Fetch omitted variables with debug_evaluate and a scope's variablesReference, and omitted frames with debug_frame.",
+	}
+	return true
+}
+
+// countItems counts the items in a map of lists, such as a snapshot's stacks
+func countItems(lists map[string]interface{}) int {
+	n := 0
+	for _, list := range lists {
+		items, _ := list.([]map[string]interface{})
+		n += len(items)
+	}
+	return n
+}
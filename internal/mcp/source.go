@@ -0,0 +1,323 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// Origins reported by debug_source
+const (
+	sourceOriginDisk    = "disk"
+	sourceOriginAdapter = "adapter"
+)
+
+// handleDebugSource returns a source's content, reading files on disk directly
+// so it works with adapters that don't implement the source request
+func (s *Server) handleDebugSource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	path, _ := request.RequireString("path")
+	sourceRef := 0
+	if ref, err := requireInt(request, "sourceReference"); err == nil {
+		sourceRef = ref
+	}
+	if path == "" && sourceRef <= 0 {
+		return mcp.NewToolResultError(errors.MissingParameter("path", "Provide the path or sourceReference of a stack frame's source (see debug_snapshot).").Error()), nil
+	}
+
+	// Optional 1-based, inclusive line range; 0 means from the start / to the end
+	startLine, endLine := 0, 0
+	if n, err := requireInt(request, "startLine"); err == nil {
+		if n < 1 {
+			return mcp.NewToolResultError(errors.InvalidParameter("startLine", n, "a line number of 1 or more").Error()), nil
+		}
+		startLine = n
+	}
+	if n, err := requireInt(request, "endLine"); err == nil {
+		if n < 1 || (startLine > 0 && n < startLine) {
+			return mcp.NewToolResultError(errors.InvalidParameter("endLine", n, "a line number of 1 or more, not before startLine").Error()), nil
+		}
+		endLine = n
+	}
+	ranged := startLine > 0 || endLine > 0
+	if startLine == 0 {
+		startLine = 1
+	}
+
+	// Only the debuggee's sources are served by path, not any file on the host
+	if sourceRef <= 0 && !s.isSessionSource(session, client, path) {
+		return mcp.NewToolResultError(errors.InvalidParameter("path", path,
+			"a file under the session's source root or in one of its stack frames or loaded sources; use a frame's sourceReference for other sources").Error()), nil
+	}
+
+	var content, mimeType, origin string
+	var size int
+	var truncated bool
+	var lines sourceLines
+
+	if sourceRef <= 0 && isRegularFile(path) {
+		if ranged {
+			lines, size, err = readSourceFileLines(path, startLine, endLine)
+			content = lines.content
+		} else {
+			content, size, truncated, err = readSourceFile(path, s.config.MaxSourceSize)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(errors.SourceUnavailable(path, sourceRef, err).Error()), nil
+		}
+		origin = sourceOriginDisk
+	} else {
+		content, mimeType, err = client.Source(sourceRef, path)
+		if err != nil {
+			return mcp.NewToolResultError(errors.SourceUnavailable(path, sourceRef, err).Error()), nil
+		}
+		size = len(content)
+		if ranged {
+			lines = sliceSourceLines(content, startLine, endLine)
+			content = lines.content
+		}
+		origin = sourceOriginAdapter
+	}
+
+	if ranged && lines.first > lines.total {
+		return mcp.NewToolResultError(errors.InvalidParameter("startLine", startLine, fmt.Sprintf("a line number within the source's %d lines", lines.total)).Error()), nil
+	}
+	if !truncated {
+		content, truncated = truncateSource(content, s.config.MaxSourceSize)
+	}
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"content":   content,
+		"origin":    origin,
+		"size":      size,
+	}
+	if path != "" {
+		result["path"] = path
+	}
+	if sourceRef > 0 {
+		result["sourceReference"] = sourceRef
+	}
+	if mimeType != "" {
+		result["mimeType"] = mimeType
+	}
+	if ranged {
+		// Absolute line numbers of the returned content
+		last := lines.last
+		if truncated && strings.Count(content, "\n") > 0 {
+			last = lines.first + strings.Count(content, "\n") - 1
+		}
+		result["startLine"] = lines.first
+		result["endLine"] = last
+		result["totalLines"] = lines.total
+	}
+	if truncated {
+		result["truncated"] = true
+		result["note"] = fmt.Sprintf("content was cut at the configured maxSourceSize of %d bytes", s.config.MaxSourceSize)
+	}
+	return jsonResult(result)
+}
+
+// sourceCheckDepth bounds the frames of each stopped thread searched for a
+// source path
+const sourceCheckDepth = 100
+
+// isSessionSource reports whether debug_source may serve a path: it lies under
+// the session's source root (its cwd, workspace, or program directory), or the
+// adapter reported it as the source of a stopped thread's frame or as a loaded
+// source. Paths are compared with symlinks resolved, so a link under the
+// source root can't lead elsewhere.
+func (s *Server) isSessionSource(session *internaldap.Session, client *internaldap.Client, path string) bool {
+	path = canonicalPath(path)
+	if path == "" {
+		return false
+	}
+	roots := []string{session.SourceRoot()}
+	if filepath.IsAbs(session.Program) {
+		roots = append(roots, filepath.Dir(session.Program))
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(canonicalPath(root), path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	if !client.Running() {
+		for _, stop := range client.StoppedThreads() {
+			frames, _, err := client.StackTrace(stop.ThreadID, 0, sourceCheckDepth)
+			if err != nil {
+				continue
+			}
+			for _, f := range frames {
+				if f.Source != nil && f.Source.Path != "" && canonicalPath(f.Source.Path) == path {
+					return true
+				}
+			}
+		}
+	}
+	if client.Capabilities().SupportsLoadedSourcesRequest {
+		if sources, err := client.LoadedSources(); err == nil {
+			for _, source := range sources {
+				if source.Path != "" && canonicalPath(source.Path) == path {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// canonicalPath returns an absolute, clean path with symlinks resolved where
+// the file exists, or "" for paths that aren't file paths, such as URLs
+func canonicalPath(path string) string {
+	if path == "" || strings.Contains(path, "://") {
+		return ""
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// isRegularFile reports whether path names an existing file on disk
+func isRegularFile(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// readSourceFile reads at most maxSize bytes of a file (0 = no limit) and
+// returns the content, the file's full size, and whether it was truncated
+func readSourceFile(path string, maxSize int) (string, int, bool, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: reading debuggee sources is the point
+	if err != nil {
+		return "", 0, false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, false, err
+	}
+	size := int(info.Size())
+
+	var reader io.Reader = f
+	if maxSize > 0 {
+		// Read one byte past the limit so truncateSource can tell a cut happened
+		reader = io.LimitReader(f, int64(maxSize)+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	content, truncated := truncateSource(string(data), maxSize)
+	return content, size, truncated, nil
+}
+
+// sourceLines is a range of lines taken from a source, with absolute 1-based
+// line numbers
+type sourceLines struct {
+	content string
+	first   int
+	last    int
+	total   int
+}
+
+// sliceSourceLines returns lines start through end (inclusive, 0 = to the end)
+// of content. When start is past the last line, the result is empty and first
+// is greater than total.
+func sliceSourceLines(content string, start, end int) sourceLines {
+	all := strings.SplitAfter(content, "\n")
+	if all[len(all)-1] == "" {
+		all = all[:len(all)-1] // content ended with a line break
+	}
+
+	total := len(all)
+	if end == 0 || end > total {
+		end = total
+	}
+	if start > total {
+		return sourceLines{first: start, last: start - 1, total: total}
+	}
+	return sourceLines{
+		content: strings.Join(all[start-1:end], ""),
+		first:   start,
+		last:    end,
+		total:   total,
+	}
+}
+
+// readSourceFileLines reads lines start through end (inclusive, 0 = to the
+// end) of a file without holding the rest of it in memory. It also returns the
+// file's full size.
+func readSourceFileLines(path string, start, end int) (sourceLines, int, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: reading debuggee sources is the point
+	if err != nil {
+		return sourceLines{}, 0, err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	reader := bufio.NewReader(f)
+	size, total := 0, 0
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			size += len(line)
+			total++
+			if total >= start && (end == 0 || total <= end) {
+				b.WriteString(line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sourceLines{}, 0, err
+		}
+	}
+
+	if end == 0 || end > total {
+		end = total
+	}
+	if start > total {
+		return sourceLines{first: start, last: start - 1, total: total}, size, nil
+	}
+	return sourceLines{content: b.String(), first: start, last: end, total: total}, size, nil
+}
+
+// truncateSource cuts content to at most maxSize bytes (0 = no limit), at the
+// last line break before the limit so no partial line is returned
+func truncateSource(content string, maxSize int) (string, bool) {
+	if maxSize <= 0 || len(content) <= maxSize {
+		return content, false
+	}
+	cut := content[:maxSize]
+	if i := strings.LastIndexByte(cut, '\n'); i >= 0 {
+		cut = cut[:i+1]
+	}
+	return cut, true
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/launchconfig"
+)
+
+// Bounds for running launch.json tasks
+const (
+	preLaunchTaskTimeout = 10 * time.Minute
+	// taskReadyTimeout bounds the wait for a background task's ready line
+	taskReadyTimeout     = 2 * time.Minute
+	postDebugTaskTimeout = 2 * time.Minute
+)
+
+// launchTasks are a configuration's preLaunchTask and postDebugTask, resolved
+// from the tasks.json next to its launch.json
+type launchTasks struct {
+	workspace     string
+	preLaunchTask *launchconfig.TaskConfig
+	postDebugTask *launchconfig.TaskConfig
+}
+
+// loadLaunchTasks finds and resolves a configuration's tasks. It returns nil
+// if the configuration has none.
+func loadLaunchTasks(resolved *launchconfig.ResolvedConfiguration, configPath string, resCtx *launchconfig.ResolutionContext) (*launchTasks, error) {
+	if resolved.PreLaunchTask == "" && resolved.PostDebugTask == "" {
+		return nil, nil
+	}
+
+	tj, err := launchconfig.LoadTasksFromPath(launchconfig.TasksPathFor(configPath))
+	if err != nil {
+		return nil, err
+	}
+	tasks := &launchTasks{workspace: resCtx.WorkspaceFolder}
+	find := func(label string) (*launchconfig.TaskConfig, error) {
+		task, err := launchconfig.FindTask(tj, label)
+		if err != nil {
+			return nil, err
+		}
+		return launchconfig.ResolveTask(task, resCtx)
+	}
+	if resolved.PreLaunchTask != "" {
+		if tasks.preLaunchTask, err = find(resolved.PreLaunchTask); err != nil {
+			return nil, fmt.Errorf("preLaunchTask: %w", err)
+		}
+	}
+	if resolved.PostDebugTask != "" {
+		if tasks.postDebugTask, err = find(resolved.PostDebugTask); err != nil {
+			return nil, fmt.Errorf("postDebugTask: %w", err)
+		}
+	}
+	return tasks, nil
+}
+
+// start runs the preLaunchTask before the adapter is spawned: to completion,
+// or for a background task (isBackground) until it prints its ready line,
+// leaving it running. The background task is stopped and the postDebugTask
+// run once the session ends.
+func (t *launchTasks) start(session *internaldap.Session) error {
+	var background *launchconfig.RunningTask
+	if task := t.preLaunchTask; task != nil {
+		if task.IsBackground {
+			var err error
+			if background, err = launchconfig.StartTask(task, t.workspace, taskReadyTimeout); err != nil {
+				return err
+			}
+		} else if err := launchconfig.RunTask(task, t.workspace, preLaunchTaskTimeout); err != nil {
+			return err
+		}
+	}
+
+	post := t.postDebugTask
+	session.OnEnd(func() {
+		if background == nil && post == nil {
+			return
+		}
+		// Sessions end under the session manager's lock, and stopping the
+		// background task can wait for it to exit; don't hold the lock
+		go func() {
+			if background != nil {
+				background.Stop()
+			}
+			if post != nil {
+				if err := launchconfig.RunTask(post, t.workspace, postDebugTaskTimeout); err != nil {
+					log.Printf("Session %s: postDebugTask failed: %v", session.ID, err)
+				}
+			}
+		}()
+	})
+	return nil
+}
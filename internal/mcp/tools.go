@@ -6,24 +6,58 @@ import (
 
 // registerTools registers the consolidated 12-tool debug API
 func (s *Server) registerTools() {
-	// Session Management (4 tools - both modes)
+	// Session Management (9 tools - both modes, plus debug_install_adapter with allowInstall)
 	s.registerDebugLaunch()
 	s.registerDebugAttach()
 	s.registerDebugDisconnect()
 	s.registerDebugListSessions()
+	s.registerDebugPing()
+	s.registerDebugServerInfo()
+	s.registerDebugListConfigs()
+	s.registerDebugExportSession()
+	s.registerDebugImportSession()
+	if s.config.AllowInstall {
+		s.registerDebugInstallAdapter()
+	}
 
-	// Inspection (2 tools - both modes)
+	// Inspection (20 tools - both modes)
 	s.registerDebugSnapshot()
+	s.registerDebugFrame()
+	s.registerDebugFocus()
 	s.registerDebugEvaluate()
+	s.registerDebugInspectTree()
+	s.registerDebugReadVariableBytes()
+	s.registerDebugGetFullValue()
+	s.registerDebugCapabilities()
+	s.registerDebugAdapterLog()
+	s.registerDebugGetOutput()
+	s.registerDebugListBreakpoints()
+	s.registerDebugThreads()
+	s.registerDebugGoroutines()
+	s.registerDebugGoroutineStack()
+	s.registerDebugModules()
+	s.registerDebugSource()
+	s.registerDebugFindSource()
+	s.registerDebugRegisters()
+	s.registerDebugException()
+	s.registerDebugDiff()
 
-	// Control (6 tools - full mode only)
+	// Control (15 tools - full mode only)
 	if s.config.CanUseControlTools() {
 		s.registerDebugBreakpoints()
+		s.registerDebugSetFunctionBreakpoints()
+		s.registerDebugBreakWhen()
 		s.registerDebugStep()
+		s.registerDebugTraceExpression()
+		s.registerDebugContinueToReturn()
 		s.registerDebugContinue()
 		s.registerDebugPause()
 		s.registerDebugSetVariable()
 		s.registerDebugRunToLine()
+		s.registerDebugWaitForOutput()
+		s.registerDebugCustomRequest()
+		s.registerDebugSetDebugOptions()
+		s.registerDebugCallFunction()
 		s.registerDebugExecuteCommand()
 	}
 }
@@ -34,13 +68,16 @@ func (s *Server) registerDebugLaunch() {
 	tool := mcp.NewTool("debug_launch",
 		mcp.WithDescription("Launch a new debug session. Can use direct arguments OR reference a VS Code launch.json configuration. Returns sessionId needed for all other tools. Use stopOnEntry=true to pause at first line."),
 		mcp.WithString("language",
-			mcp.Description("Programming language: go, python, javascript, or typescript. Not required if configName is provided."),
+			mcp.Description("Programming language: go, python, javascript, typescript, c, cpp, rust, swift, elixir, or native (any compiled binary, e.g. Zig, Nim, Crystal). Not required if configName is provided."),
 		),
 		mcp.WithString("program",
-			mcp.Description("Path to the program to debug, OR URL for browser debugging. Not required if configName is provided."),
+			mcp.Description("Path to the program to debug, OR URL for browser debugging. For Elixir: a script (.exs) or mix task name (e.g. 'test'). Not required if configName is provided."),
 		),
 		mcp.WithString("target",
-			mcp.Description("Debug target: 'node' (default for JS/TS), 'chrome', or 'edge'. Use chrome/edge for React, Svelte, Vue apps"),
+			mcp.Description("Debug target: 'node' (default for JS/TS), 'chrome', 'edge', or 'terminal'. Use chrome/edge for React, Svelte, Vue apps; 'terminal' runs program as a shell command (e.g. 'npm start') and debugs the Node.js processes it starts"),
+		),
+		mcp.WithString("args",
+			mcp.Description("Program arguments: a JSON array (e.g. [\"--port\", \"8080\"]) or a command line that is split like a shell would (e.g. --name \"John Smith\" -v)"),
 		),
 		mcp.WithString("cwd",
 			mcp.Description("Working directory for the program"),
@@ -49,7 +86,37 @@ func (s *Server) registerDebugLaunch() {
 			mcp.Description("Root of web app source files (for browser debugging source maps)"),
 		),
 		mcp.WithBoolean("stopOnEntry",
-			mcp.Description("Stop on entry point (default: false)"),
+			mcp.Description("Stop on entry point (default: false). The result has stoppedAtEntry=true and the threadId when the program is paused at entry."),
+		),
+		mcp.WithString("debugger",
+			mcp.Description("Native debugger for c, cpp, rust, or native sessions: 'lldb' (default) or 'gdb'"),
+		),
+		mcp.WithString("buildFlags",
+			mcp.Description("Go only: build flags for this launch (e.g. '-tags integration'), overriding the configured adapters.go.buildFlags"),
+		),
+		mcp.WithBoolean("justMyCode",
+			mcp.Description("Python only: debug only your own code (default: true). Set false to step into libraries"),
+		),
+		mcp.WithString("sourceMap",
+			mcp.Description("Source path remapping for a program built elsewhere (e.g. in a container), so its files resolve to local sources: JSON [from, to] pairs or an object. Example: [[\"/build/src\", \"/home/me/project/src\"]]. Native sessions (lldb or gdb) and Python (as pathMappings). Replaces a launch.json configuration's sourceMap"),
+		),
+		mcp.WithString("breakpoints",
+			mcp.Description("JSON array of breakpoints to set before the program starts, so early code can't run past them. Example: [{\"path\": \"/src/main.go\", \"line\": 10}, {\"path\": \"/src/util.go\", \"line\": 20, \"condition\": \"x > 5\"}]. Each may also have a column, hitCondition, or logMessage."),
+		),
+		mcp.WithString("functionBreakpoints",
+			mcp.Description("JSON array of function names (or {\"name\", \"condition\", \"hitCondition\"} objects) to break on from the start. Example: [\"main\", \"Foo::bar\"]"),
+		),
+		mcp.WithString("regexBreakpoints",
+			mcp.Description("GDB/LLDB only: JSON array of regular expressions; a breakpoint is set on every matching function from the start. They are debugger commands, so they are refused when evaluation is read-only. Example: [\"^Parser::\"]"),
+		),
+		mcp.WithBoolean("verifyBreakpoints",
+			mcp.Description("After launching, wait briefly for the adapter to verify the breakpoints and return a breakpointReport showing which ones bound (default: false)"),
+		),
+		mcp.WithBoolean("restartOnExit",
+			mcp.Description("Relaunch the program (keeping breakpoints) when it exits with a non-zero code, for debugging startup crashes. debug_list_sessions reports the restart count. Default: false"),
+		),
+		mcp.WithNumber("maxRestarts",
+			mcp.Description("Maximum relaunches with restartOnExit (1-10, default: 3)"),
 		),
 		// Python venv support
 		mcp.WithString("pythonPath",
@@ -62,40 +129,64 @@ func (s *Server) registerDebugLaunch() {
 		mcp.WithString("configName",
 			mcp.Description("Name of configuration in launch.json to use. If provided, loads settings from launch.json."),
 		),
+		mcp.WithString("compoundName",
+			mcp.Description("Name of a compound in launch.json: launches each of its configurations, returning a session per configuration and the error of any that failed."),
+		),
+		mcp.WithString("onPartialFailure",
+			mcp.Description("With compoundName, what to do when a configuration fails to launch: 'rollback' (default) terminates the sessions already started, 'keep' leaves them running and launches the rest."),
+		),
 		mcp.WithString("workspace",
 			mcp.Description("Workspace root for variable resolution (e.g., ${workspaceFolder}) and config discovery."),
 		),
+		mcp.WithString("workspaceFolders",
+			mcp.Description("JSON object mapping the folder names of a multi-root workspace to their paths, for ${workspaceFolder:name} variables in launch.json. Example: {\"backend\": \"/repo/backend\", \"web\": \"/repo/web\"}"),
+		),
 		mcp.WithString("inputValues",
 			mcp.Description("JSON object with values for ${input:} variables in launch.json. Example: {\"testFile\": \"test_main.py\"}"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugLaunch)
+	s.addTool(tool, s.handleDebugLaunch)
 }
 
 func (s *Server) registerDebugAttach() {
 	tool := mcp.NewTool("debug_attach",
 		mcp.WithDescription("Attach to an existing debug adapter, process, or browser. Can use direct arguments OR reference a VS Code launch.json configuration."),
 		mcp.WithString("language",
-			mcp.Description("Programming language: go, python, javascript, or typescript. Not required if configName is provided."),
+			mcp.Description("Programming language: go, python, javascript, typescript, c, cpp, rust, swift, or native. Not required if configName is provided."),
 		),
 		mcp.WithString("target",
 			mcp.Description("Debug target: 'node' (default), 'chrome', or 'edge'. Use chrome/edge for React, Svelte, Vue apps"),
 		),
+		mcp.WithString("debugger",
+			mcp.Description("Native debugger for c, cpp, rust, or native sessions: 'lldb' (default) or 'gdb'"),
+		),
 		mcp.WithString("host",
 			mcp.Description("Host address of the debug adapter (default: 127.0.0.1)"),
 		),
 		mcp.WithNumber("port",
-			mcp.Description("Port of the debug adapter (default: 9229 for Node, 9222 for Chrome/Edge)"),
+			mcp.Description("Port of the debug adapter (default: 9229 for Node, 9222 for Chrome/Edge; for Python, the port given to debugpy --listen). Omit for Go and native languages to attach by pid."),
 		),
 		mcp.WithNumber("pid",
-			mcp.Description("Process ID to attach to (Node.js only)"),
+			mcp.Description("Process ID to attach to. For Go or native languages without a port, a local Delve, lldb-dap, or gdb adapter is spawned and attached to this process."),
+		),
+		mcp.WithString("program",
+			mcp.Description("Name or path of the program. With waitFor, the process to wait for; otherwise used by native debuggers to load symbols."),
+		),
+		mcp.WithBoolean("waitFor",
+			mcp.Description("Wait for a process named program to start and attach to it as soon as it does, instead of attaching by pid. For processes spawned by a parent you don't control. Native languages with the lldb debugger only."),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description("Seconds to wait for the process with waitFor (default: 60, max: 600)"),
 		),
 		mcp.WithString("url",
-			mcp.Description("URL pattern to match for browser tab selection"),
+			mcp.Description("Browser tab to attach to: an exact URL, or a pattern with * wildcards (e.g. http://localhost:3000/*). If it matches no open tab, the error lists the open tabs"),
 		),
 		mcp.WithString("webRoot",
 			mcp.Description("Root of web app source files (for source maps)"),
 		),
+		mcp.WithString("sourceMap",
+			mcp.Description("Source path remapping for a program built elsewhere (e.g. in a container): JSON [from, to] pairs or an object. Example: [[\"/build/src\", \"/home/me/project/src\"]]. Native sessions (lldb or gdb) and Python (as pathMappings)"),
+		),
 		// Launch.json configuration support
 		mcp.WithString("configPath",
 			mcp.Description("Path to launch.json file. Auto-discovers from workspace if not provided."),
@@ -110,7 +201,7 @@ func (s *Server) registerDebugAttach() {
 			mcp.Description("JSON object with values for ${input:} variables in launch.json."),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugAttach)
+	s.addTool(tool, s.handleDebugAttach)
 }
 
 func (s *Server) registerDebugDisconnect() {
@@ -124,21 +215,94 @@ func (s *Server) registerDebugDisconnect() {
 			mcp.Description("Terminate the debugged process (default: false)"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugDisconnect)
+	s.addTool(tool, s.handleDebugDisconnect)
 }
 
 func (s *Server) registerDebugListSessions() {
 	tool := mcp.NewTool("debug_list_sessions",
 		mcp.WithDescription("List all active debug sessions"),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugListSessions)
+	s.addTool(tool, s.handleDebugListSessions)
+}
+
+func (s *Server) registerDebugPing() {
+	tool := mcp.NewTool("debug_ping",
+		mcp.WithDescription("Check that a session is alive, without fetching stacks or variables. Returns the session status, adapterAlive (connection open), adapterResponsive and latencyMs (answered a threads request), debuggeeAlive, exitCode once the debuggee exited, and lastActivity/idleMs since the adapter last sent a message. Use it as a fast heartbeat before heavier calls."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("timeoutMs",
+			mcp.Description("How long to wait for the adapter to answer, in milliseconds. Default: 2000, max: 30000"),
+		),
+	)
+	s.addTool(tool, s.handleDebugPing)
+}
+
+func (s *Server) registerDebugServerInfo() {
+	tool := mcp.NewTool("debug_server_info",
+		mcp.WithDescription("Get the server's version, mode (readonly or full), permissions (spawn, attach, modify, execute, evaluation level), session limits, supported languages, and whether an update is available. restrictions lists what the configuration doesn't allow, so you can avoid calls that would fail with a permission error."),
+	)
+	s.addTool(tool, s.handleDebugServerInfo)
+}
+
+func (s *Server) registerDebugListConfigs() {
+	tool := mcp.NewTool("debug_list_configs",
+		mcp.WithDescription("List the configurations and compounds in a VS Code launch.json, with validationWarnings for problems "+
+			"such as an unsupported version, compounds referencing unknown configurations, or undefined ${input:} variables."),
+		mcp.WithString("configPath",
+			mcp.Description("Path to launch.json file. Auto-discovers from workspace if not provided."),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace root used to discover .vscode/launch.json."),
+		),
+	)
+	s.addTool(tool, s.handleDebugListConfigs)
+}
+
+func (s *Server) registerDebugExportSession() {
+	tool := mcp.NewTool("debug_export_session",
+		mcp.WithDescription("Export a launched session as a JSON object: its launch arguments and its current source, function, and regex breakpoints. Pass the object to debug_import_session to launch the same session again, e.g. after a restart or to share a reproduction."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+	)
+	s.addTool(tool, s.handleDebugExportSession)
+}
+
+func (s *Server) registerDebugImportSession() {
+	tool := mcp.NewTool("debug_import_session",
+		mcp.WithDescription("Launch a new debug session from an object returned by debug_export_session, with its launch arguments and breakpoints set before the program runs. Returns the same result as debug_launch."),
+		mcp.WithString("session",
+			mcp.Required(),
+			mcp.Description("The exported session object, as a JSON string"),
+		),
+	)
+	s.addTool(tool, s.handleDebugImportSession)
+}
+
+func (s *Server) registerDebugInstallAdapter() {
+	tool := mcp.NewTool("debug_install_adapter",
+		mcp.WithDescription("Install the debug adapter for a language when a launch fails because it is missing: Delve (go install) for Go, debugpy (pip install) for Python, "+
+			"or the latest vscode-js-debug release for JavaScript/TypeScript. Returns the adapter path, the config key it belongs in, and the installer output. "+
+			"With writeConfig the path is written into the server's configuration file; the server must be restarted to use it."),
+		mcp.WithString("language",
+			mcp.Required(),
+			mcp.Description("The language whose adapter to install: 'go', 'python', 'javascript', or 'typescript'"),
+		),
+		mcp.WithBoolean("writeConfig",
+			mcp.Description("Write the installed path into the server's configuration file (requires the server to be started with -config). Default: false"),
+		),
+	)
+	s.addTool(tool, s.handleDebugInstallAdapter)
 }
 
 // Inspection Tools
 
 func (s *Server) registerDebugSnapshot() {
 	tool := mcp.NewTool("debug_snapshot",
-		mcp.WithDescription("Get complete debug state in ONE call: all threads, stack traces, scopes, and variables. This is the primary inspection tool - use it instead of making multiple individual calls. Returns: {threads, stacks, scopes, variables}."),
+		mcp.WithDescription("Get complete debug state in ONE call: all threads, stack traces, scopes, and variables. This is the primary inspection tool - use it instead of making multiple individual calls. Returns: {snapshotId, threads, stacks, scopes, variables}, plus stoppedThreads listing each thread that stopped with its own reason, since all-stop debuggers can stop several threads at once."),
 		mcp.WithString("sessionId",
 			mcp.Required(),
 			mcp.Description("The session ID"),
@@ -147,18 +311,40 @@ func (s *Server) registerDebugSnapshot() {
 			mcp.Description("Specific thread ID, or omit for all threads"),
 		),
 		mcp.WithNumber("maxStackDepth",
-			mcp.Description("Maximum stack depth to return (default: 10)"),
+			mcp.Description("Maximum stack depth to return (default: 10). Each thread reports hasMore when its stack goes deeper, and its totalFrames when the adapter reports it or the stack was counted."),
+		),
+		mcp.WithBoolean("countFrames",
+			mcp.Description("Count the depth of every thread's stack when the adapter doesn't report it, which pages through each stack (default: false, only threads that stopped are counted)."),
+		),
+		mcp.WithNumber("maxThreads",
+			mcp.Description("Maximum number of threads to expand when threadId is omitted (default: 50). Extra threads are counted in threadsOmitted."),
+		),
+		mcp.WithBoolean("hideSystemThreads",
+			mcp.Description("Skip threads whose top frame is an idle runtime function, such as goroutines parked in runtime.gopark, and count them in hiddenThreads (default: false). The functions come from the snapshotHiddenFrames setting. Threads are checked until maxThreads visible ones are found, and the rest are counted in threadsOmitted unchecked. Ignored when threadId is set."),
+		),
+		mcp.WithString("hiddenFrames",
+			mcp.Description("JSON array of function names to hide threads by instead of the configured list, matched as substrings of the top frame name: [\"runtime.\", \"netpoll\"]. Implies hideSystemThreads."),
 		),
 		mcp.WithBoolean("expandVariables",
 			mcp.Description("Expand first level of complex variables (default: true)"),
 		),
+		mcp.WithBoolean("delta",
+			mcp.Description("Return only threads, stacks, and variables that changed since the previous snapshot of this session, plus counts of unchanged items (default: false). A thread with changed variables is listed with its stack, so their scopes can be matched to frames. Use when stepping through loops to save tokens."),
+		),
+		mcp.WithString("scopes",
+			mcp.Description("JSON array of scope names whose variables to expand, matched case-insensitively by prefix: [\"Locals\", \"Globals\"], or [\"*\"] for all. Default: locals and arguments only. Expensive scopes (e.g. Registers) are only expanded when named. Scopes not expanded are listed in skippedScopes."),
+		),
+		mcp.WithNumber("maxBytes",
+			mcp.Description("Keep the snapshot to about this many bytes of JSON, to fit a context window. Threads are kept first, then frames from the top of each stack, then scopes and variables; what is left out is counted in truncated, with how to fetch it. Default: no limit"),
+		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugSnapshot)
+	s.addTool(tool, s.handleDebugSnapshot)
 }
 
 func (s *Server) registerDebugEvaluate() {
 	tool := mcp.NewTool("debug_evaluate",
-		mcp.WithDescription("Evaluate one or more expressions in current debug context. Supports single expression OR batch mode for multiple expressions at once."),
+		mcp.WithDescription("Evaluate one or more expressions in current debug context. Supports single expression OR batch mode for multiple expressions at once. "+
+			"Large collections report indexedVariables: page through them by calling again with variablesReference, start, and count (e.g. 100 at a time) instead of evaluating the whole collection. Pages report the total counts and hasMore; continue from nextStart while hasMore is true."),
 		mcp.WithString("sessionId",
 			mcp.Required(),
 			mcp.Description("The session ID"),
@@ -170,34 +356,468 @@ func (s *Server) registerDebugEvaluate() {
 			mcp.Description("JSON array of expressions for batch evaluation: [\"x\", \"y\", \"len(arr)\"]"),
 		),
 		mcp.WithNumber("frameId",
-			mcp.Description("Stack frame ID for context (default: top frame)"),
+			mcp.Description("Stack frame ID for context (default: the focused frame, see debug_focus)"),
 		),
 		mcp.WithString("context",
-			mcp.Description("Evaluation context: 'watch', 'hover', or 'repl' (default: 'watch')"),
+			mcp.Description("Evaluation context: 'watch', 'hover', 'repl', or 'clipboard' (default: the evaluationContexts setting for the session's language: 'repl' for Python and JavaScript/TypeScript, which run statements there, 'watch' for the rest). Use 'clipboard' to get the full value of a result marked truncated. 'repl' is refused when evaluation is read-only."),
+		),
+		mcp.WithBoolean("rawResult",
+			mcp.Description("Return the adapter's result as a value even when it looks like an error message (e.g. 'NameError: ...'), which is otherwise reported as a failed evaluation. Default: false"),
+		),
+		// Paging through the children of a previous result
+		mcp.WithNumber("variablesReference",
+			mcp.Description("Page the children of a previous result instead of evaluating. Use the variablesReference from an earlier evaluation."),
+		),
+		mcp.WithNumber("start",
+			mcp.Description("Index of the first child to return when paging (default: 0)"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of children to return when paging (default: 100, max: 1000)"),
+		),
+		mcp.WithString("filter",
+			mcp.Description("Which children to page: 'indexed' (default, array elements) or 'named' (fields)"),
+		),
+		mcp.WithString("nameContains",
+			mcp.Description("With variablesReference, return only the children whose names contain this text (case-insensitive), scanning pages of children instead of returning one. The result says whether the scan was complete."),
+		),
+		mcp.WithBoolean("nameRegex",
+			mcp.Description("Treat nameContains as a regular expression (RE2 syntax, case-sensitive; prefix (?i) to ignore case). Default: false"),
+		),
+		mcp.WithNumber("maxPages",
+			mcp.Description("Most pages of indexed children a nameContains search reads (default: 10, at most 100, of count children each, default 1000). At most 500 matches are returned; continue an incomplete search from nextStart"),
+		),
+	)
+	s.addTool(tool, s.handleDebugEvaluate)
+}
+
+func (s *Server) registerDebugCapabilities() {
+	tool := mcp.NewTool("debug_capabilities",
+		mcp.WithDescription("Get the debug adapter's DAP capabilities for a session (e.g. supportsConditionalBreakpoints, supportsSetVariable, "+
+			"supportsDisassembleRequest, exceptionBreakpointFilters), including capabilities the adapter added after initialize. Check this before using optional features; absent flags are unsupported."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+	)
+	s.addTool(tool, s.handleDebugCapabilities)
+}
+
+func (s *Server) registerDebugAdapterLog() {
+	tool := mcp.NewTool("debug_adapter_log",
+		mcp.WithDescription("Get the stderr output captured from a session's debug adapter. Use this to diagnose adapter crashes, launch failures, or unexpected behavior."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("lines",
+			mcp.Description("Only return the last N lines (default: all captured lines, up to 500)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugAdapterLog)
+}
+
+func (s *Server) registerDebugInspectTree() {
+	tool := mcp.NewTool("debug_inspect_tree",
+		mcp.WithDescription("Expand a nested value to a given depth and return it as an indented text tree, one \"name: value (type)\" line per node. Much more compact than JSON for deep objects. Nodes that were not fully expanded end in ... with their ref; pass it as variablesReference to continue from there."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("expression",
+			mcp.Description("Expression to evaluate and expand, e.g. \"user\" or \"self.cache\""),
+		),
+		mcp.WithNumber("variablesReference",
+			mcp.Description("Expand the value with this variablesReference (from debug_snapshot, debug_evaluate, or a ref in an earlier tree) instead of evaluating an expression"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Label for the root when using variablesReference"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Stack frame to evaluate the expression in (default: the focused frame, see debug_focus)"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Levels of children to expand (default: 3, max: 10)"),
+		),
+		mcp.WithNumber("maxChildren",
+			mcp.Description("Children to show per node; the rest are counted in a '... N more' line (default: 20)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugInspectTree)
+}
+
+func (s *Server) registerDebugReadVariableBytes() {
+	tool := mcp.NewTool("debug_read_variable_bytes",
+		mcp.WithDescription("Read the full contents of a string or byte buffer from memory when its value was truncated by the adapter or by maxVariableValueLength. The value must have a memoryReference (the adapter must support readMemory, see debug_capabilities). "+
+			"Returns the bytes as text with encoding 'utf-8' when they are valid UTF-8, else base64 with encoding 'base64'. Reads at most 1 MiB per call; if truncated, call again with offset=nextOffset."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("expression",
+			mcp.Description("Expression whose memory to read, e.g. \"buf\" or \"&s[0]\""),
+		),
+		mcp.WithNumber("variablesReference",
+			mcp.Description("Read a variable under this variablesReference (from debug_snapshot or debug_evaluate) instead of evaluating an expression; requires name"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the variable under variablesReference"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Stack frame to evaluate the expression in (default: the focused frame, see debug_focus)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Byte offset from the value's memoryReference to start reading at (default: 0)"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of bytes to read (default: the value's number of elements, for byte slices and arrays)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugReadVariableBytes)
+}
+
+func (s *Server) registerDebugGetFullValue() {
+	tool := mcp.NewTool("debug_get_full_value",
+		mcp.WithDescription("Get the complete, untruncated text of a value as plain text, e.g. a long traceback or error message cut short in other results (marked truncated). "+
+			"Strings are unquoted so escaped newlines become line breaks; a collection (e.g. a list of traceback lines) is returned one element per line, reading its elements page by page. "+
+			"Values the adapter itself cut short are read from memory where possible. Results over 4 MiB are cut."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("expression",
+			mcp.Description("Expression whose value to get, e.g. \"traceback.format_exc()\" or \"err.Error()\". Evaluated in the clipboard context where the adapter supports it"),
+		),
+		mcp.WithNumber("variablesReference",
+			mcp.Description("Get a variable under this variablesReference (from debug_snapshot or debug_evaluate) by name, or without name every child of the reference, instead of evaluating an expression"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the variable under variablesReference"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Stack frame to evaluate the expression in (default: the focused frame, see debug_focus)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugGetFullValue)
+}
+
+func (s *Server) registerDebugGetOutput() {
+	tool := mcp.NewTool("debug_get_output",
+		mcp.WithDescription("Get the program's output (stdout, stderr, console) captured from the debug adapter, oldest first. Each entry has its category, and the source location that produced it where the adapter reports one (e.g. a console.log call or a failed assertion). stderr and \"important\" output is flagged with error=true. Poll for new output by passing the previous lastSeq as since."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("categories",
+			mcp.Description("JSON array of categories to return, e.g. [\"stderr\", \"important\"] for errors only. Common categories: console, stdout, stderr, important. Default: all"),
+		),
+		mcp.WithNumber("since",
+			mcp.Description("Only return output after this seq (the lastSeq of a previous call)"),
+		),
+		mcp.WithNumber("lines",
+			mcp.Description("Only return the last N matching entries (default: all, up to the last 1000 events)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugGetOutput)
+}
+
+func (s *Server) registerDebugListBreakpoints() {
+	tool := mcp.NewTool("debug_list_breakpoints",
+		mcp.WithDescription("List the session's source breakpoints with their current state. Breakpoints that were unverified when set (e.g. before their module loaded) show as verified here once the adapter confirms them."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+	)
+	s.addTool(tool, s.handleDebugListBreakpoints)
+}
+
+func (s *Server) registerDebugThreads() {
+	tool := mcp.NewTool("debug_threads",
+		mcp.WithDescription("List the session's threads with their lifecycle status: 'started' (reported by a thread event), 'running' (listed by the adapter), or 'exited'. Use this to avoid stepping or inspecting threads that have already exited."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithBoolean("includeExited",
+			mcp.Description("Include threads that have exited (default: true)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugThreads)
+}
+
+func (s *Server) registerDebugGoroutines() {
+	tool := mcp.NewTool("debug_goroutines",
+		mcp.WithDescription("List a Go session's goroutines (Delve only). Each has its id, state ('running' on an OS thread when the program stopped, or 'waiting': parked or not scheduled), current function, location outside the runtime, and startFunction, the function it was started with. "+
+			"Pass an id to debug_goroutine_stack for its stack, or to debug_focus as threadId to evaluate in it. The program must be paused."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("filter",
+			mcp.Description("Only list goroutines whose current function contains this text (case-insensitive), e.g. 'worker'"),
+		),
+		mcp.WithBoolean("includeLocation",
+			mcp.Description("Read each listed goroutine's stack for its location and startFunction (default: true). Turn off for a quick list of thousands of goroutines."),
+		),
+		mcp.WithNumber("maxGoroutines",
+			mcp.Description("Maximum number of goroutines to list (default: 100). The rest are counted in omitted."),
+		),
+	)
+	s.addTool(tool, s.handleDebugGoroutines)
+}
+
+func (s *Server) registerDebugGoroutineStack() {
+	tool := mcp.NewTool("debug_goroutine_stack",
+		mcp.WithDescription("Get the stack of any goroutine of a Go session (Delve only), not just the one the program stopped on. Frames come a page at a time; totalFrames and hasMore tell whether more remain."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("goroutineId",
+			mcp.Required(),
+			mcp.Description("Goroutine id from debug_goroutines"),
+		),
+		mcp.WithNumber("startFrame",
+			mcp.Description("Index of the first frame to return, from 0 (innermost) (default: 0)"),
+		),
+		mcp.WithNumber("maxStackDepth",
+			mcp.Description("Maximum number of frames to return (default: 50)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugGoroutineStack)
+}
+
+func (s *Server) registerDebugModules() {
+	tool := mcp.NewTool("debug_modules",
+		mcp.WithDescription("List the modules the program has loaded (shared libraries, Python modules, scripts), kept live from the adapter's module events so modules loaded at runtime (dlopen, imports, plugins) are included. "+
+			"Modules first reported since this session's previous debug_modules call are marked new=true; 'new' counts them."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("filter",
+			mcp.Description("Only list modules whose name or path contains this text (case-insensitive)"),
+		),
+		mcp.WithNumber("since",
+			mcp.Description("Mark modules reported after this lastSeq as new, instead of those since the previous call"),
+		),
+	)
+	s.addTool(tool, s.handleDebugModules)
+}
+
+func (s *Server) registerDebugFrame() {
+	tool := mcp.NewTool("debug_frame",
+		mcp.WithDescription("Show one stack frame in ONE call: the frame, the source lines around its current line (marked '>'), and its local variables. "+
+			"Select the frame by frameId, or by index in a thread's stack (0 = innermost). Use direction 'up' (toward the caller) or 'down' (toward the callee) to move from it; hasCaller and hasCallee tell whether there is a frame that way."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Frame ID from debug_snapshot or an earlier debug_frame result"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("Thread whose stack to use (default: the thread containing frameId, else the focused frame)"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Frame index in the thread's stack when no frameId is given (default: 0, the innermost frame)"),
+		),
+		mcp.WithString("direction",
+			mcp.Description("Move from the selected frame: 'up' to its caller or 'down' to its callee"),
+		),
+		mcp.WithNumber("contextLines",
+			mcp.Description("Source lines to show before and after the current line (default: 5, max: 100)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugFrame)
+}
+
+func (s *Server) registerDebugFocus() {
+	tool := mcp.NewTool("debug_focus",
+		mcp.WithDescription("Select the thread and frame that tools use when threadId or frameId is omitted (debug_evaluate, debug_step, debug_continue, debug_inspect_tree, and others), like an IDE's selected stack frame. "+
+			"Select by frameId, or by threadId and/or index in the thread's stack. Without either, returns the current focus. On each stop the focus moves to the stopped thread's top frame; selected=false means it is that default."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Frame ID from debug_snapshot or debug_frame"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("Thread to focus (default: the focused thread)"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Frame index in the thread's stack when no frameId is given (default: 0, the innermost frame)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugFocus)
+}
+
+func (s *Server) registerDebugSource() {
+	tool := mcp.NewTool("debug_source",
+		mcp.WithDescription("Get the content of a source file, e.g. to read the code around a stack frame. Files on disk are read directly; sources without a file (sourceReference > 0, such as generated or decompiled code) are fetched from the adapter. The 'origin' field reports which was used."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("path",
+			mcp.Description("The source file path, as reported by a stack frame. Only files under the session's cwd, workspace, or program directory, or in a stopped thread's stack or the loaded sources, can be read by path."),
+		),
+		mcp.WithNumber("sourceReference",
+			mcp.Description("The sourceReference reported by a stack frame (required for sources without a path on disk)"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Description("First line to return (1-based, default: 1). Use with endLine to get the code around a stack frame's line"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("Last line to return, inclusive (default: end of file)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugSource)
+}
+
+func (s *Server) registerDebugFindSource() {
+	tool := mcp.NewTool("debug_find_source",
+		mcp.WithDescription("Find the source files of a module, package, or function known only by name, e.g. installed libraries or code outside the workspace, to set breakpoints in it. "+
+			"Python sessions ask the interpreter for the module's __file__ and a function's first line; Go sessions ask Delve for the package's files (both need the program stopped and full evaluation). The adapter's modules and loaded sources are searched by name as well. Returns candidates with their path, line where known, and origin."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Module, package, or function name, e.g. \"requests.sessions.Session.send\", \"net/http.Get\", or \"libfoo\""),
+		),
+	)
+	s.addTool(tool, s.handleDebugFindSource)
+}
+
+func (s *Server) registerDebugRegisters() {
+	tool := mcp.NewTool("debug_registers",
+		mcp.WithDescription("Read CPU registers of a stack frame from the adapter's Registers scope. ONLY for GDB/LLDB sessions (C, C++, Rust, Swift, native). "+
+			"Without names, LLDB's first register set (general purpose) is returned and the other sets are listed in otherGroups; pass names to read any register."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Stack frame to read registers from (default: top frame of threadId)"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("Thread whose top frame to use when frameId is not given (default: the focused frame, see debug_focus)"),
+		),
+		mcp.WithString("names",
+			mcp.Description("JSON array of register names to return, case-insensitive. Example: [\"rip\", \"rsp\", \"rax\"]"),
+		),
+		mcp.WithBoolean("hex",
+			mcp.Description("Format values in hexadecimal, if the adapter supports value formatting (default: false)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugRegisters)
+}
+
+func (s *Server) registerDebugException() {
+	tool := mcp.NewTool("debug_exception",
+		mcp.WithDescription("Get the exception a thread is stopped on as a chain: the exception itself, then each cause down to the root cause "+
+			"(Python's raise ... from and implicit context, Java's Caused by, JavaScript's cause, or the adapter's inner exceptions). "+
+			"Each level has type, message, frames parsed from its stack trace (innermost call first), and relation to the level before it. "+
+			"Adapters without exceptionInfo give a single level from the stopped event."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("Thread stopped on the exception (default: the focused thread, see debug_focus)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugException)
+}
+
+func (s *Server) registerDebugDiff() {
+	tool := mcp.NewTool("debug_diff",
+		mcp.WithDescription("Compare two debug_snapshot results and return only what differs: threads added or removed, stop reasons, frames pushed, popped, or moved to another line, and variables changed, added, or removed (in frames that are the same call in both). "+
+			"Every debug_snapshot returns a snapshotId; the session keeps its last 5. Use it to see what a step or continue did without rereading whole snapshots. "+
+			"Variables are compared for the frames and scopes the snapshots expanded."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("from",
+			mcp.Description("snapshotId of the earlier snapshot (default: the second latest)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("snapshotId of the later snapshot (default: the latest)"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugEvaluate)
+	s.addTool(tool, s.handleDebugDiff)
 }
 
 // Control Tools (Full mode only)
 
 func (s *Server) registerDebugBreakpoints() {
 	tool := mcp.NewTool("debug_breakpoints",
-		mcp.WithDescription("Set breakpoints in a source file. Supports conditional breakpoints with 'condition' field. Note: This REPLACES all breakpoints in the file - include all desired breakpoints in each call."),
+		mcp.WithDescription("Set breakpoints in a source file. Supports conditional breakpoints with 'condition' field. Unverified breakpoints include a 'reason' (invalidCondition, noCode, pending, or unverified) and echo back their condition. Relative paths are resolved to the absolute 'path' returned (with 'requestedPath'); 'adapterPath' shows where the adapter placed the source if it differs. Note: This REPLACES all breakpoints in the file - include all desired breakpoints in each call."),
 		mcp.WithString("sessionId",
 			mcp.Required(),
 			mcp.Description("The session ID"),
 		),
 		mcp.WithString("path",
 			mcp.Required(),
-			mcp.Description("The source file path"),
+			mcp.Description("The source file path (absolute, or relative to the launch's cwd, workspace, or program directory)"),
 		),
 		mcp.WithString("breakpoints",
 			mcp.Required(),
-			mcp.Description("JSON array of breakpoints: [{line: number, condition?: string, hitCondition?: string, logMessage?: string}]"),
+			mcp.Description("JSON array of breakpoints: [{line: number, column?: number, condition?: string, hitCondition?: string, logMessage?: string}]. A column (1-based) breaks on one statement of a line with several, such as a call in minified JavaScript; adapters without column support break on the whole line."),
+		),
+		mcp.WithBoolean("requireVerified",
+			mcp.Description("Fail with the adapter's messages if any breakpoint is not verified, instead of returning it unverified (default: the requireVerifiedBreakpoints setting, false). The breakpoints stay set either way."),
+		),
+	)
+	s.addTool(tool, s.handleDebugBreakpoints)
+}
+
+func (s *Server) registerDebugSetFunctionBreakpoints() {
+	tool := mcp.NewTool("debug_set_function_breakpoints",
+		mcp.WithDescription("Set breakpoints on functions by name. This REPLACES the session's previous function breakpoints. With regex=true (GDB/LLDB sessions), each name is a regular expression and a breakpoint is set on every matching function, e.g. all overloads of Foo::bar; the resolved locations and their count are returned. Regex breakpoints are added to earlier ones rather than replacing them."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("functions",
+			mcp.Required(),
+			mcp.Description("JSON array of function names, or objects with a condition: [\"main\", {\"name\": \"parse\", \"condition\": \"len > 10\", \"hitCondition\": \"3\"}]. Pass [] to clear them."),
+		),
+		mcp.WithBoolean("regex",
+			mcp.Description("Treat each name as a regular expression matched against function names (GDB and LLDB only, no conditions). Patterns are run as debugger commands, so this is refused when evaluation is read-only and patterns can't contain newlines. Default: false"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugBreakpoints)
+	s.addTool(tool, s.handleDebugSetFunctionBreakpoints)
+}
+
+func (s *Server) registerDebugBreakWhen() {
+	tool := mcp.NewTool("debug_break_when",
+		mcp.WithDescription("Break the next time an expression has its current value. Evaluates the expression now, then sets a conditional breakpoint at path:line with condition '(expression) == <current value>'. Other breakpoints in the file are kept. Only scalar values (numbers, booleans, strings, null) are supported."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("The expression to evaluate and compare, e.g. 'user.id'"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("The source file path for the breakpoint (absolute, or relative to the launch's cwd, workspace, or program directory)"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number for the breakpoint"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Stack frame to evaluate the expression in (default: the focused frame, see debug_focus)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugBreakWhen)
 }
 
 func (s *Server) registerDebugStep() {
@@ -208,15 +828,55 @@ func (s *Server) registerDebugStep() {
 			mcp.Description("The session ID"),
 		),
 		mcp.WithNumber("threadId",
-			mcp.Required(),
-			mcp.Description("The thread ID to step"),
+			mcp.Description("The thread ID to step (default: the focused thread, see debug_focus)"),
 		),
 		mcp.WithString("type",
 			mcp.Required(),
 			mcp.Description("Step type: 'over' (next line), 'into' (enter function), 'out' (exit function)"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugStep)
+	s.addTool(tool, s.handleDebugStep)
+}
+
+func (s *Server) registerDebugTraceExpression() {
+	tool := mcp.NewTool("debug_trace_expression",
+		mcp.WithDescription("Step a thread repeatedly and record an expression's value after each step, to watch how it evolves in one call. Returns the line, function, and value at every stop, with changed=true where the value differs from the previous step. Ends early if a breakpoint or exception interrupts a step (endedBy is the stop reason), the program exits, or the timeout passes (the program is then paused)."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("The expression to evaluate after each step, e.g. 'total' or 'len(items)'"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Step type: 'over' (default), 'into', or 'out'"),
+		),
+		mcp.WithNumber("steps",
+			mcp.Description("Maximum number of steps, from 1 to 200 (default: 20)"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("The thread ID to step (default: the focused thread, see debug_focus)"),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description("Seconds the whole trace may take (default: 30, max: 300)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugTraceExpression)
+}
+
+func (s *Server) registerDebugContinueToReturn() {
+	tool := mcp.NewTool("debug_continue_to_return",
+		mcp.WithDescription("Run until the current function returns (step out) and report the value it returned, for adapters that expose return values (Go/Delve, Python/debugpy, lldb-dap). Stops early if a breakpoint is hit first."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("The thread ID to step out of (default: the focused thread, see debug_focus)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugContinueToReturn)
 }
 
 func (s *Server) registerDebugContinue() {
@@ -227,11 +887,16 @@ func (s *Server) registerDebugContinue() {
 			mcp.Description("The session ID"),
 		),
 		mcp.WithNumber("threadId",
-			mcp.Required(),
-			mcp.Description("The thread ID to continue"),
+			mcp.Description("The thread ID to continue (default: the focused thread, see debug_focus)"),
+		),
+		mcp.WithBoolean("continueAll",
+			mcp.Description("Resume every thread, even on adapters that only resume the given thread (default: false)"),
+		),
+		mcp.WithBoolean("singleThread",
+			mcp.Description("Resume only the given thread and keep others paused. Only works if the debug adapter supports single-thread execution (default: false)"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugContinue)
+	s.addTool(tool, s.handleDebugContinue)
 }
 
 func (s *Server) registerDebugPause() {
@@ -242,16 +907,18 @@ func (s *Server) registerDebugPause() {
 			mcp.Description("The session ID"),
 		),
 		mcp.WithNumber("threadId",
-			mcp.Required(),
-			mcp.Description("The thread ID to pause"),
+			mcp.Description("The thread ID to pause (default: the focused thread, see debug_focus)"),
+		),
+		mcp.WithBoolean("pauseAll",
+			mcp.Description("Freeze every thread in the program (default: false)"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugPause)
+	s.addTool(tool, s.handleDebugPause)
 }
 
 func (s *Server) registerDebugSetVariable() {
 	tool := mcp.NewTool("debug_set_variable",
-		mcp.WithDescription("Modify the value of a variable during debugging. Use variablesReference from debug_snapshot to identify the scope."),
+		mcp.WithDescription("Modify the value of a variable during debugging. Use variablesReference from debug_snapshot to identify the scope. Variables whose presentationHint attributes include 'readOnly' or 'constant' usually can't be set."),
 		mcp.WithString("sessionId",
 			mcp.Required(),
 			mcp.Description("The session ID"),
@@ -269,26 +936,117 @@ func (s *Server) registerDebugSetVariable() {
 			mcp.Description("The new value to set"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugSetVariable)
+	s.addTool(tool, s.handleDebugSetVariable)
 }
 
 func (s *Server) registerDebugRunToLine() {
 	tool := mcp.NewTool("debug_run_to_line",
-		mcp.WithDescription("Run until execution reaches a specific line. Sets temp breakpoint, continues, waits for stop, and returns a snapshot with stack and local variables. More efficient than set breakpoint + continue + snapshot."),
+		mcp.WithDescription("Run until execution reaches a specific line. Sets temp breakpoint, continues, waits for stop, and returns a snapshot with stack and local variables. More efficient than set breakpoint + continue + snapshot. "+
+			"Pass frameId to stop only in that call, e.g. when the function returns to its caller at the line, skipping hits in recursive calls and other threads. The file's own breakpoints stay set; the temporary breakpoint is removed afterward. If the line isn't reached within timeout, the program is paused."),
 		mcp.WithString("sessionId",
 			mcp.Required(),
 			mcp.Description("The session ID"),
 		),
 		mcp.WithString("path",
 			mcp.Required(),
-			mcp.Description("The source file path"),
+			mcp.Description("The source file path (absolute, or relative to the launch's cwd, workspace, or program directory)"),
 		),
 		mcp.WithNumber("line",
 			mcp.Required(),
 			mcp.Description("The line number to run to"),
 		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Only stop when the line is reached in this frame's call (the frame is on top of its thread's stack), e.g. a caller's frame to run until the current function returns to it"),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description("Seconds to wait for the line before pausing the program (default: 30, max: 600)"),
+		),
+		mcp.WithBoolean("requireVerified",
+			mcp.Description("Fail with the adapter's message if the temporary breakpoint is not verified, instead of continuing with a warning (default: the requireVerifiedBreakpoints setting, false)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugRunToLine)
+}
+
+func (s *Server) registerDebugWaitForOutput() {
+	tool := mcp.NewTool("debug_wait_for_output",
+		mcp.WithDescription("Resume the program (if stopped) and wait until a line of its output matches a regular expression, e.g. a server's 'listening on' log line, instead of sleeping. "+
+			"Returns the matching line and the program's status. Waiting ends early if the program stops at a breakpoint or exits; only output printed after the call counts."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("Regular expression (RE2 syntax) matched against each line of output, e.g. 'listening on :\\d+'"),
+		),
+		mcp.WithNumber("threadId",
+			mcp.Description("Thread to continue if the program is stopped (default: the focused thread, see debug_focus)"),
+		),
+		mcp.WithNumber("timeoutMs",
+			mcp.Description("How long to wait for a match, in milliseconds. Default: 30000, max: 300000"),
+		),
+	)
+	s.addTool(tool, s.handleDebugWaitForOutput)
+}
+
+func (s *Server) registerDebugCustomRequest() {
+	tool := mcp.NewTool("debug_custom_request",
+		mcp.WithDescription("Send an adapter-specific DAP request that has no dedicated tool and return the raw response body. "+
+			"Examples: Delve's 'dlvCommand' with {\"command\": \"goroutines\"}, Flutter's 'hotReload'. "+
+			"Requires allowExecute."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description("The DAP command to send"),
+		),
+		mcp.WithString("arguments",
+			mcp.Description("JSON object with the request's arguments. Example: {\"command\": \"goroutines\"}"),
+		),
+	)
+	s.addTool(tool, s.handleDebugCustomRequest)
+}
+
+func (s *Server) registerDebugSetDebugOptions() {
+	tool := mcp.NewTool("debug_set_debug_options",
+		mcp.WithDescription("Change a session's debug options, such as turning justMyCode off to step into library code (Python/debugpy). "+
+			"debugpy reads justMyCode when the program launches, so the change applies from the session's next launch (e.g. a restartOnExit relaunch); returns the options now in effect and the ones pending."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID"),
+		),
+		mcp.WithBoolean("justMyCode",
+			mcp.Required(),
+			mcp.Description("Step and break only in your own code (true) or in library code too (false)"),
+		),
+	)
+	s.addTool(tool, s.handleDebugSetDebugOptions)
+}
+
+func (s *Server) registerDebugCallFunction() {
+	tool := mcp.NewTool("debug_call_function",
+		mcp.WithDescription("Call a function in a stopped Go program with Delve's 'call' command and return its result. "+
+			"The call runs the program's code on the frame's goroutine, so it can change program state. Requires allowExecute. "+
+			"ONLY for Go sessions; the program must be built with Go 1.11 or later and not debugged from a core file."),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("The session ID (must be a Go session)"),
+		),
+		mcp.WithString("function",
+			mcp.Required(),
+			mcp.Description("The function to call, e.g. 'strings.ToUpper', 'main.checksum', or a method such as 'req.Header.Get'"),
+		),
+		mcp.WithString("args",
+			mcp.Description("JSON array of Go expressions to pass as arguments, e.g. [\"name\", \"len(buf)\", \"42\"] (default: no arguments)"),
+		),
+		mcp.WithNumber("frameId",
+			mcp.Description("Stack frame whose goroutine and scope the call runs in (default: the focused frame, see debug_focus)"),
+		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugRunToLine)
+	s.addTool(tool, s.handleDebugCallFunction)
 }
 
 func (s *Server) registerDebugExecuteCommand() {
@@ -306,8 +1064,8 @@ func (s *Server) registerDebugExecuteCommand() {
 			mcp.Description("The debugger command to execute"),
 		),
 		mcp.WithNumber("frameId",
-			mcp.Description("Stack frame ID for context (default: top frame of first thread)"),
+			mcp.Description("Stack frame ID for context (default: the focused frame, see debug_focus)"),
 		),
 	)
-	s.mcpServer.AddTool(tool, s.handleDebugExecuteCommand)
+	s.addTool(tool, s.handleDebugExecuteCommand)
 }
@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Limits for debug_trace_expression
+const (
+	defaultTraceSteps   = 20
+	maxTraceSteps       = 200
+	defaultTraceTimeout = 30 * time.Second
+	maxTraceTimeout     = 5 * time.Minute
+)
+
+// handleDebugTraceExpression steps a thread up to a number of times and
+// evaluates an expression after each step, returning the line and value at
+// every stop. The trace ends early when the program stops for another reason
+// (a breakpoint or exception), exits, or runs out of time; a program still
+// running then is paused.
+func (s *Server) handleDebugTraceExpression(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.config.CanEvaluate() {
+		return mcp.NewToolResultError(errors.PermissionDenied("evaluate", string(s.config.Mode)).Error()), nil
+	}
+
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	expression, err := request.RequireString("expression")
+	if err != nil || expression == "" {
+		return mcp.NewToolResultError(errors.MissingParameter("expression",
+			"Provide the expression to record after each step, e.g. \"total\" or \"len(items)\".").Error()), nil
+	}
+	evalContext, _, debugErr := s.evaluationMode(session, expression, "watch")
+	if debugErr != nil {
+		return mcp.NewToolResultError(debugErr.Error()), nil
+	}
+
+	stepType := "over"
+	if t, err := request.RequireString("type"); err == nil && t != "" {
+		stepType = t
+	}
+	if stepType != "over" && stepType != "into" && stepType != "out" {
+		return mcp.NewToolResultError(errors.InvalidParameter("type", stepType, "'over', 'into', or 'out'").Error()), nil
+	}
+
+	steps := defaultTraceSteps
+	if n, err := requireInt(request, "steps"); err == nil {
+		if n < 1 || n > maxTraceSteps {
+			return mcp.NewToolResultError(errors.InvalidParameter("steps", n,
+				fmt.Sprintf("a number of steps from 1 to %d", maxTraceSteps)).Error()), nil
+		}
+		steps = n
+	}
+
+	timeout := defaultTraceTimeout
+	if t, err := requireNumber(request, "timeout"); err == nil && t > 0 {
+		timeout = min(time.Duration(t*float64(time.Second)), maxTraceTimeout)
+	}
+
+	threadID, ok := requestThread(request, session, client)
+	if !ok {
+		return mcp.NewToolResultError(errors.MissingParameter("threadId",
+			"No thread has stopped to default to. Pass a threadId from debug_threads, or select one with debug_focus.").Error()), nil
+	}
+
+	trace := []map[string]interface{}{s.traceEntry(client, threadID, expression, evalContext, 0, nil)}
+	result := map[string]interface{}{
+		"sessionId":  session.ID,
+		"expression": expression,
+		"type":       stepType,
+		"threadId":   threadID,
+	}
+
+	traceCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	endedBy := "steps"
+	for step := 1; step <= steps; step++ {
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
+		stoppedInfo, err := client.StepAndWaitContext(traceCtx, threadID, stepType, timeout)
+		if err != nil {
+			endedBy = s.endTrace(session, client, threadID, err, result)
+			break
+		}
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+
+		entry := s.traceEntry(client, stoppedInfo.ThreadID, expression, evalContext, step, trace[len(trace)-1])
+		trace = append(trace, entry)
+
+		// A breakpoint or exception interrupted the step
+		if stoppedInfo.Reason != "step" {
+			endedBy = stoppedInfo.Reason
+			if stoppedInfo.ThreadID != threadID {
+				entry["threadId"] = stoppedInfo.ThreadID
+			}
+			break
+		}
+	}
+
+	result["trace"] = trace
+	result["stepsTaken"] = len(trace) - 1
+	result["endedBy"] = endedBy
+	return jsonResult(result)
+}
+
+// traceEntry records the line and the expression's value at a thread's top
+// frame, marking whether the value changed since the previous entry
+func (s *Server) traceEntry(client *internaldap.Client, threadID int, expression, evalContext string, step int, previous map[string]interface{}) map[string]interface{} {
+	entry := map[string]interface{}{"step": step}
+
+	frames, _, err := client.StackTrace(threadID, 0, 1)
+	if err != nil || len(frames) == 0 {
+		entry["error"] = "the stack trace is unavailable"
+		return entry
+	}
+	frame := frames[0]
+	entry["line"] = frame.Line
+	entry["function"] = frame.Name
+	if frame.Source != nil && frame.Source.Path != "" {
+		entry["source"] = frame.Source.Path
+	}
+
+	evaluated, err := client.Evaluate(expression, frame.Id, evalContext)
+	if err != nil {
+		// The expression may be out of scope in this frame, e.g. after stepping into a call
+		entry["error"] = err.Error()
+	} else {
+		s.setValue(entry, "value", evaluated.Result)
+	}
+	if previous != nil && fmt.Sprint(entry["value"], entry["error"]) != fmt.Sprint(previous["value"], previous["error"]) {
+		entry["changed"] = true
+	}
+	return entry
+}
+
+// endTrace handles a step that didn't stop, pausing the program if the step
+// timed out or was cancelled, and returns why the trace ended
+func (s *Server) endTrace(session *internaldap.Session, client *internaldap.Client, threadID int, err error, result map[string]interface{}) string {
+	if stderrors.Is(err, internaldap.ErrProgramEnded) {
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusTerminated)
+		if code, exited := client.DebuggeeExited(); exited {
+			result["exitCode"] = code
+		}
+		return "exited"
+	}
+
+	endedBy := "timeout"
+	if stderrors.Is(err, context.Canceled) {
+		endedBy = "cancelled"
+	} else if !stderrors.Is(err, context.DeadlineExceeded) && !stderrors.Is(err, internaldap.ErrStopTimeout) {
+		// The step was rejected, so the program is still where it stopped
+		result["error"] = errors.StepFailed(fmt.Sprint(result["type"]), err).Error()
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+		return "error"
+	}
+
+	// Pause so the program is left stopped, as after any other stop
+	if _, pauseErr := client.PauseAndWait(threadID, 5*time.Second); pauseErr == nil {
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+		result["paused"] = true
+	}
+	return endedBy
+}
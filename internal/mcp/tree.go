@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// Limits for debug_inspect_tree
+const (
+	defaultTreeDepth    = 3
+	maxTreeDepth        = 10
+	defaultTreeChildren = 20
+	// maxTreeRequests bounds the variables requests one tree can make
+	maxTreeRequests = 500
+)
+
+// handleDebugInspectTree expands a value to a given depth and renders it as an
+// indented text tree, which is more compact and readable than nested JSON for
+// deep objects. The value is either a variablesReference from an earlier
+// result or an expression to evaluate.
+func (s *Server) handleDebugInspectTree(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getStoppedSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	depth := defaultTreeDepth
+	if d, err := requireInt(request, "maxDepth"); err == nil && d > 0 {
+		depth = min(d, maxTreeDepth)
+	}
+	maxChildren := defaultTreeChildren
+	if n, err := requireInt(request, "maxChildren"); err == nil && n > 0 {
+		maxChildren = n
+	}
+
+	tree := &textTree{server: s, client: client, maxChildren: maxChildren}
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+	}
+
+	if ref, err := requireInt(request, "variablesReference"); err == nil && ref > 0 {
+		name, _ := request.RequireString("name")
+		if name == "" {
+			name = fmt.Sprintf("<%d>", ref)
+		}
+		counts, _ := client.ChildCounts(ref)
+		tree.render(dap.Variable{
+			Name:               name,
+			VariablesReference: ref,
+			IndexedVariables:   counts.Indexed,
+			NamedVariables:     counts.Named,
+		}, 0, depth)
+	} else {
+		expression, err := request.RequireString("expression")
+		if err != nil {
+			return mcp.NewToolResultError(errors.MissingParameter("expression",
+				"Provide an expression to evaluate (e.g. \"user\") or the variablesReference of an earlier result.").Error()), nil
+		}
+		if !s.config.CanEvaluate() {
+			return mcp.NewToolResultError(errors.PermissionDenied("evaluate", string(s.config.Mode)).Error()), nil
+		}
+
+		frameID, _ := requestFrame(request, session, client)
+
+		evalContext, mode, debugErr := s.evaluationMode(session, expression, "watch")
+		if debugErr != nil {
+			return mcp.NewToolResultError(debugErr.Error()), nil
+		}
+		evaluated, err := client.Evaluate(expression, frameID, evalContext)
+		if err != nil {
+			return mcp.NewToolResultError(errors.EvaluationFailed(expression, err).Error()), nil
+		}
+		tree.render(dap.Variable{
+			Name:               expression,
+			Value:              evaluated.Result,
+			Type:               evaluated.Type,
+			VariablesReference: evaluated.VariablesReference,
+			IndexedVariables:   evaluated.IndexedVariables,
+			NamedVariables:     evaluated.NamedVariables,
+		}, 0, depth)
+		result["evaluateMode"] = mode
+	}
+
+	result["tree"] = strings.TrimRight(tree.b.String(), "\n")
+	result["nodes"] = tree.nodes
+	if tree.truncated {
+		result["truncated"] = true
+		result["note"] = "Parts of the tree marked ... were cut short: raise maxDepth or maxChildren, or call again with a node's ref as variablesReference"
+	}
+	return jsonResult(result)
+}
+
+// textTree renders variables as indented lines of "name: value (type)"
+type textTree struct {
+	server      *Server
+	client      *internaldap.Client
+	maxChildren int
+
+	b         strings.Builder
+	nodes     int
+	requests  int
+	truncated bool
+}
+
+// render writes a variable and, while depth remains, its children
+func (t *textTree) render(v dap.Variable, level, depth int) {
+	t.nodes++
+	indent := strings.Repeat("  ", level)
+	value, cut := t.server.truncatedValue(v.Value)
+	fmt.Fprintf(&t.b, "%s%s:", indent, v.Name)
+	if value != "" {
+		fmt.Fprintf(&t.b, " %s", value)
+	}
+	if v.Type != "" {
+		fmt.Fprintf(&t.b, " (%s)", v.Type)
+	}
+	if cut {
+		t.truncated = true
+	}
+
+	if v.VariablesReference == 0 {
+		t.b.WriteString("\n")
+		return
+	}
+	if level >= depth || t.requests >= maxTreeRequests {
+		// Not expanded: the ref lets a later call continue from here
+		fmt.Fprintf(&t.b, " [ref %d] ...\n", v.VariablesReference)
+		t.truncated = true
+		return
+	}
+	t.b.WriteString("\n")
+
+	// One extra child tells whether there are more when the adapter gave no counts
+	t.requests++
+	children, err := t.client.Variables(v.VariablesReference, "", 0, t.maxChildren+1)
+	if err != nil {
+		fmt.Fprintf(&t.b, "%s  <error: %v>\n", indent, err)
+		return
+	}
+	shown := children
+	if len(shown) > t.maxChildren {
+		shown = shown[:t.maxChildren]
+	}
+	for _, child := range shown {
+		t.render(child, level+1, depth)
+	}
+
+	total := v.IndexedVariables + v.NamedVariables
+	switch {
+	case total > len(shown):
+		fmt.Fprintf(&t.b, "%s  ... %d more [ref %d]\n", indent, total-len(shown), v.VariablesReference)
+		t.truncated = true
+	case len(children) > len(shown):
+		fmt.Fprintf(&t.b, "%s  ... more [ref %d]\n", indent, v.VariablesReference)
+		t.truncated = true
+	}
+}
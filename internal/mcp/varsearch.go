@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-dap"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// Bounds for name searches, so searching a huge collection can't run
+// unbounded: the pages of indexed children read, and the matches returned
+const (
+	defaultSearchPages = 10
+	maxSearchPages     = 100
+	maxSearchMatches   = 500
+)
+
+// handleVariablesSearch returns the children of a variablesReference whose
+// names match nameContains. DAP can't filter children by name, so pages are
+// fetched and filtered here, up to maxPages pages of indexed children.
+func (s *Server) handleVariablesSearch(request mcp.CallToolRequest, client *internaldap.Client, variablesRef int, nameContains string) (*mcp.CallToolResult, error) {
+	match := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), strings.ToLower(nameContains))
+	}
+	if request.GetBool("nameRegex", false) {
+		re, err := regexp.Compile(nameContains)
+		if err != nil {
+			return mcp.NewToolResultError(errors.InvalidParameter("nameContains", nameContains, "a valid regular expression (RE2 syntax)").Error()), nil
+		}
+		match = re.MatchString
+	}
+
+	start := 0
+	if v, err := requireInt(request, "start"); err == nil && v > 0 {
+		start = v
+	}
+	pageSize := maxPageCount
+	if v, err := requireInt(request, "count"); err == nil && v > 0 && v < maxPageCount {
+		pageSize = v
+	}
+	maxPages := defaultSearchPages
+	if v, err := requireInt(request, "maxPages"); err == nil && v > 0 {
+		maxPages = min(v, maxSearchPages)
+	}
+
+	// Without a filter, search the kinds of children the reference has; if
+	// that isn't known, one unfiltered request returns what the adapter shows
+	filter, _ := request.RequireString("filter")
+	if filter != "" && filter != "indexed" && filter != "named" {
+		return mcp.NewToolResultError(errors.InvalidParameter("filter", filter, "'indexed' or 'named'").Error()), nil
+	}
+	counts, known := client.ChildCounts(variablesRef)
+	var kinds []string
+	switch {
+	case filter != "":
+		kinds = []string{filter}
+	case known && (counts.Named > 0 || counts.Indexed > 0):
+		if counts.Named > 0 {
+			kinds = append(kinds, "named")
+		}
+		if counts.Indexed > 0 {
+			kinds = append(kinds, "indexed")
+		}
+	default:
+		kinds = []string{""}
+	}
+
+	matches := []map[string]interface{}{}
+	scanned, pages, indexedPages := 0, 0, 0
+	complete, truncated := true, false
+	nextStart := -1
+	// addMatches adds the matching variables and returns how many were
+	// scanned, stopping at the first match past maxSearchMatches
+	addMatches := func(vars []dap.Variable) int {
+		for i, v := range vars {
+			if !match(v.Name) {
+				continue
+			}
+			if len(matches) == maxSearchMatches {
+				truncated = true
+				scanned += i
+				return i
+			}
+			matches = append(matches, s.pagedVariable(v))
+		}
+		scanned += len(vars)
+		return len(vars)
+	}
+
+	for _, kind := range kinds {
+		// Only indexed children are paged in DAP
+		if kind != "indexed" {
+			vars, err := client.Variables(variablesRef, kind, 0, 0)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to get variables: %v", err)), nil
+			}
+			pages++
+			addMatches(vars)
+			if truncated {
+				complete = false
+				break
+			}
+			continue
+		}
+
+		for offset := start; ; offset += pageSize {
+			if indexedPages >= maxPages {
+				complete, nextStart = false, offset
+				break
+			}
+			vars, err := client.Variables(variablesRef, "indexed", offset, pageSize)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to get variables: %v", err)), nil
+			}
+			pages++
+			indexedPages++
+			if n := addMatches(vars); truncated {
+				complete, nextStart = false, offset+n
+				break
+			}
+			end := len(vars) < pageSize
+			if known && counts.Indexed > 0 {
+				end = offset+len(vars) >= counts.Indexed
+			}
+			if end || len(vars) == 0 {
+				break
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"variablesReference": variablesRef,
+		"nameContains":       nameContains,
+		"variables":          matches,
+		"matches":            len(matches),
+		"scanned":            scanned,
+		"pages":              pages,
+		"scanComplete":       complete,
+	}
+	if known {
+		addChildCounts(result, counts.Indexed, counts.Named)
+	}
+	if nextStart >= 0 {
+		result["nextStart"] = nextStart
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	switch {
+	case truncated && nextStart >= 0:
+		result["note"] = fmt.Sprintf("Stopped after %d matches; narrow nameContains, or search the rest by calling again with start set to nextStart.", maxSearchMatches)
+	case truncated:
+		result["note"] = fmt.Sprintf("Stopped after %d matches; narrow nameContains to see the rest.", maxSearchMatches)
+	case !complete:
+		result["note"] = "Stopped after maxPages pages of indexed children; search the rest by calling again with start set to nextStart."
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// Bounds for how long debug_wait_for_output waits, in milliseconds
+const (
+	defaultWaitForOutputMs = 30000
+	maxWaitForOutputMs     = 300000
+)
+
+// handleDebugWaitForOutput resumes a stopped program and waits until a line
+// of its output matches a pattern, for programs that signal readiness by
+// logging. Waiting ends early if the program stops or exits.
+func (s *Server) handleDebugWaitForOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, client, err := s.getSessionClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !client.Alive() {
+		return mcp.NewToolResultError(errors.SessionAdapterGone(session.ID).Error()), nil
+	}
+
+	pattern, err := request.RequireString("pattern")
+	if err != nil || pattern == "" {
+		return mcp.NewToolResultError(errors.MissingParameter("pattern",
+			"A regular expression matching the output line to wait for, e.g. \"listening on :8080\".").Error()), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(errors.InvalidParameter("pattern", pattern, "a valid regular expression (RE2 syntax)").Error()), nil
+	}
+
+	timeoutMs := defaultWaitForOutputMs
+	if v, err := requireInt(request, "timeoutMs"); err == nil && v > 0 {
+		timeoutMs = min(v, maxWaitForOutputMs)
+	}
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+
+	// Only output that arrives from now on counts
+	since := client.OutputSeq()
+
+	result := map[string]interface{}{
+		"sessionId": session.ID,
+		"pattern":   pattern,
+	}
+
+	if !client.Running() {
+		threadID, ok := requestThread(request, session, client)
+		if !ok {
+			return mcp.NewToolResultError(errors.NoThreads().Error()), nil
+		}
+		if _, err := client.Continue(threadID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("continue failed: %v", err)), nil
+		}
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusRunning)
+		result["continued"] = true
+	}
+
+	// A breakpoint hit while waiting ends the wait, like the program exiting
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	match, err := client.WaitForOutput(waitCtx, since, re.MatchString)
+	if err == internaldap.ErrAdapterGone {
+		return mcp.NewToolResultError(errors.SessionAdapterGone(session.ID).Error()), nil
+	}
+	if match != nil {
+		result["matched"] = true
+		result["line"] = match.Line
+		result["seq"] = match.Entry.Seq
+		result["category"] = match.Entry.Category
+	} else {
+		result["matched"] = false
+	}
+
+	// Report where the program is now
+	switch code, exited := client.DebuggeeExited(); {
+	case exited:
+		result["status"] = "exited"
+		result["exitCode"] = code
+	case client.Terminated():
+		result["status"] = "terminated"
+	case !client.Running():
+		result["status"] = "stopped"
+		if stop := client.LastStop(); stop != nil {
+			result["reason"] = stop.Reason
+			result["threadId"] = stop.ThreadID
+		}
+		_ = s.sessionManager.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+	default:
+		result["status"] = "running"
+	}
+
+	if match == nil {
+		switch result["status"] {
+		case "running":
+			result["note"] = fmt.Sprintf("No output matched within %s; the program is still running. Call again to keep waiting, or debug_get_output to see what it printed.", timeout)
+		case "stopped":
+			result["note"] = "The program stopped before printing a matching line; inspect it with debug_snapshot, then call again to resume and keep waiting."
+		default:
+			result["note"] = "The program ended before printing a matching line; see debug_get_output for what it printed."
+		}
+	}
+	return jsonResult(result)
+}
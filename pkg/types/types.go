@@ -1,7 +1,7 @@
 // Package types defines shared data types used across the DAP-MCP server.
 //
 // This package provides type definitions for:
-//   - Language: Supported programming languages (Go, Python, JavaScript, TypeScript)
+//   - Language: Supported programming languages (Go, Python, JavaScript, TypeScript, C, C++, Rust, Swift, Elixir, native)
 //   - SessionStatus: Debug session states (initializing, running, stopped, terminated)
 //   - Request types: LaunchRequest, AttachRequest, BreakpointRequest
 //   - Info types: SessionInfo, ThreadInfo, StackFrame, Variable, Scope, etc.
@@ -22,6 +22,11 @@ const (
 	LanguageRust       Language = "rust"
 	LanguageC          Language = "c"
 	LanguageCpp        Language = "cpp"
+	LanguageSwift      Language = "swift"
+	LanguageElixir     Language = "elixir"
+	// LanguageNative is a generic target for any native binary LLDB or GDB can
+	// debug (Zig, Nim, Crystal, ...) without a dedicated language mapping.
+	LanguageNative Language = "native"
 )
 
 // SessionStatus represents the status of a debug session
@@ -57,8 +62,18 @@ type SessionInfo struct {
 	SessionID string        `json:"sessionId"`
 	Language  Language      `json:"language"`
 	Status    SessionStatus `json:"status"`
-	PID       int           `json:"pid,omitempty"`
+	PID       int           `json:"pid,omitempty"` // Debug adapter process ID
 	Program   string        `json:"program,omitempty"`
+
+	// Debuggee process, when reported by the adapter's process event
+	DebuggeePID  int    `json:"debuggeePid,omitempty"`
+	DebuggeeName string `json:"debuggeeName,omitempty"`
+
+	// Relaunches after the debuggee failed, for sessions launched with restartOnExit
+	Restarts int `json:"restarts,omitempty"`
+
+	// Attached is true for sessions that attached to a running process
+	Attached bool `json:"attached,omitempty"`
 }
 
 // ThreadInfo represents information about a thread
@@ -1,10 +1,23 @@
 package test
 
 import (
+	"context"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ctagard/dap-mcp/internal/adapters"
 	"github.com/ctagard/dap-mcp/internal/config"
+	"github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+	"github.com/ctagard/dap-mcp/internal/launchconfig"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
 
@@ -108,6 +121,251 @@ func TestRegistry_NodeAdapter(t *testing.T) {
 	}
 }
 
+// TestRegistry_SwiftAdapter verifies the Swift adapter is registered and injects Swift initCommands.
+func TestRegistry_SwiftAdapter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	reg := adapters.NewRegistry(cfg)
+
+	adapter, err := reg.Get(types.LanguageSwift)
+	if err != nil {
+		t.Fatalf("failed to get Swift adapter: %v", err)
+	}
+	if adapter.Language() != types.LanguageSwift {
+		t.Errorf("expected language swift, got %s", adapter.Language())
+	}
+	if _, ok := adapter.(adapters.StdioAdapter); !ok {
+		t.Error("expected Swift adapter to be a StdioAdapter")
+	}
+
+	args := adapter.BuildLaunchArgs("/path/to/App", map[string]interface{}{
+		"initCommands": []interface{}{"settings set target.max-children-count 50"},
+	})
+	cmds, ok := args["initCommands"].([]string)
+	if !ok {
+		t.Fatalf("expected initCommands []string, got %T", args["initCommands"])
+	}
+	if len(cmds) != 3 {
+		t.Fatalf("expected 3 initCommands, got %v", cmds)
+	}
+	if cmds[0] != "settings set target.language swift" {
+		t.Errorf("expected Swift language setting first, got %q", cmds[0])
+	}
+	if cmds[2] != "settings set target.max-children-count 50" {
+		t.Errorf("expected user initCommand last, got %q", cmds[2])
+	}
+
+	attachArgs := adapter.BuildAttachArgs(map[string]interface{}{"pid": float64(1234)})
+	if attachCmds, ok := attachArgs["initCommands"].([]string); !ok || len(attachCmds) != 2 {
+		t.Errorf("expected Swift initCommands on attach, got %v", attachArgs["initCommands"])
+	}
+}
+
+// TestRegistry_RustAdapter verifies that Rust sessions load the Rust formatters
+// through lldb-dap initCommands, or through CodeLLDB when it is configured.
+func TestRegistry_RustAdapter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Adapters.Rust.RustcSysroot = "/opt/rust"
+	reg := adapters.NewRegistry(cfg)
+
+	adapter, err := reg.Get(types.LanguageRust)
+	if err != nil {
+		t.Fatalf("failed to get Rust adapter: %v", err)
+	}
+	if adapter.Language() != types.LanguageRust {
+		t.Errorf("expected language rust, got %s", adapter.Language())
+	}
+	if lldb, _ := reg.GetWithDebugger(types.LanguageRust, "lldb"); lldb != adapter {
+		t.Errorf("expected debugger lldb to keep the Rust adapter, got %T", lldb)
+	}
+
+	args := adapter.BuildLaunchArgs("/proj/target/debug/app", map[string]interface{}{
+		"initCommands": []interface{}{"settings set target.max-children-count 50"},
+	})
+	cmds, ok := args["initCommands"].([]string)
+	if !ok || len(cmds) != 3 {
+		t.Fatalf("expected 2 Rust initCommands and the user's, got %v", args["initCommands"])
+	}
+	if !strings.HasPrefix(cmds[0], "command script import") || !strings.Contains(cmds[0], filepath.Join("/opt/rust", "lib", "rustlib", "etc", "lldb_lookup.py")) {
+		t.Errorf("expected the Rust formatters to be imported first, got %q", cmds[0])
+	}
+	if !strings.Contains(cmds[1], "lldb_commands") || cmds[2] != "settings set target.max-children-count 50" {
+		t.Errorf("expected lldb_commands then the user's initCommand, got %v", cmds)
+	}
+	if attachCmds, ok := adapter.BuildAttachArgs(map[string]interface{}{"pid": float64(1234)})["initCommands"].([]string); !ok || len(attachCmds) != 2 {
+		t.Errorf("expected Rust initCommands on attach, got %v", attachCmds)
+	}
+
+	// CodeLLDB ships its own Rust formatters and listens on TCP
+	cfg.Adapters.Rust.CodelldbPath = "/opt/codelldb/adapter/codelldb"
+	adapter, _ = adapters.NewRegistry(cfg).Get(types.LanguageRust)
+	if stdio, ok := adapter.(adapters.StdioAdapter); !ok || stdio.IsStdio() {
+		t.Error("expected the CodeLLDB Rust adapter to use TCP")
+	}
+	args = adapter.BuildLaunchArgs("/proj/target/debug/app", map[string]interface{}{
+		"env": map[string]interface{}{"RUST_BACKTRACE": "1"},
+	})
+	if langs, _ := args["sourceLanguages"].([]string); len(langs) != 1 || langs[0] != "rust" {
+		t.Errorf("expected sourceLanguages [rust], got %v", args["sourceLanguages"])
+	}
+	if env, _ := args["env"].(map[string]string); env["RUST_BACKTRACE"] != "1" {
+		t.Errorf("expected env as an object for CodeLLDB, got %v", args["env"])
+	}
+	if _, ok := args["initCommands"]; ok {
+		t.Errorf("expected no formatter initCommands for CodeLLDB, got %v", args["initCommands"])
+	}
+}
+
+// TestRegistry_ElixirAdapter verifies the ElixirLS adapter and its mix task launch args.
+func TestRegistry_ElixirAdapter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	reg := adapters.NewRegistry(cfg)
+
+	adapter, err := reg.Get(types.LanguageElixir)
+	if err != nil {
+		t.Fatalf("failed to get Elixir adapter: %v", err)
+	}
+	if adapter.Language() != types.LanguageElixir {
+		t.Errorf("expected language elixir, got %s", adapter.Language())
+	}
+	if _, ok := adapter.(adapters.StdioAdapter); !ok {
+		t.Error("expected Elixir adapter to be a StdioAdapter")
+	}
+
+	// A script runs via mix run from its directory
+	args := adapter.BuildLaunchArgs("/proj/scripts/seed.exs", map[string]interface{}{})
+	if args["task"] != "run" {
+		t.Errorf("expected task run for a script, got %v", args["task"])
+	}
+	if taskArgs, ok := args["taskArgs"].([]string); !ok || len(taskArgs) != 1 || taskArgs[0] != "/proj/scripts/seed.exs" {
+		t.Errorf("expected script in taskArgs, got %v", args["taskArgs"])
+	}
+	if args["projectDir"] != "/proj/scripts" {
+		t.Errorf("expected projectDir /proj/scripts, got %v", args["projectDir"])
+	}
+
+	// A task name with launch.json-style extras
+	args = adapter.BuildLaunchArgs("test", map[string]interface{}{
+		"cwd":       "/proj",
+		"taskArgs":  []interface{}{"--trace", "test/foo_test.exs"},
+		"mixEnv":    "test",
+		"startApps": true,
+	})
+	if args["task"] != "test" || args["mixEnv"] != "test" || args["projectDir"] != "/proj" {
+		t.Errorf("expected mix test in /proj with MIX_ENV test, got %v", args)
+	}
+	if taskArgs, ok := args["taskArgs"].([]string); !ok || len(taskArgs) != 2 {
+		t.Errorf("expected 2 taskArgs, got %v", args["taskArgs"])
+	}
+	if args["startApps"] != true {
+		t.Errorf("expected startApps to pass through, got %v", args["startApps"])
+	}
+}
+
+// TestRegistry_Targets verifies each adapter's debug targets and spawn requirement.
+func TestRegistry_Targets(t *testing.T) {
+	reg := adapters.NewRegistry(config.DefaultConfig())
+
+	tests := []struct {
+		lang          types.Language
+		defaultTarget string
+		supports      []string
+		rejects       []string
+		requiresSpawn bool
+	}{
+		{types.LanguageGo, "", nil, []string{"chrome", "node"}, false},
+		{types.LanguagePython, "", nil, []string{"edge"}, false},
+		{types.LanguageJavaScript, "node", []string{"node", "chrome", "edge"}, []string{"firefox"}, false},
+		{types.LanguageTypeScript, "node", []string{"chrome"}, []string{"safari"}, false},
+		{types.LanguageRust, "", nil, []string{"chrome"}, true},
+		{types.LanguageSwift, "", nil, []string{"node"}, true},
+		{types.LanguageElixir, "", nil, []string{"node"}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.lang), func(t *testing.T) {
+			adapter, err := reg.Get(tc.lang)
+			if err != nil {
+				t.Fatalf("failed to get adapter: %v", err)
+			}
+			if got := adapter.DefaultTarget(); got != tc.defaultTarget {
+				t.Errorf("expected default target %q, got %q", tc.defaultTarget, got)
+			}
+			if !adapters.SupportsTarget(adapter, "") {
+				t.Error("expected an empty target to select the default")
+			}
+			for _, target := range tc.supports {
+				if !adapters.SupportsTarget(adapter, target) {
+					t.Errorf("expected target %q to be supported", target)
+				}
+			}
+			for _, target := range tc.rejects {
+				if adapters.SupportsTarget(adapter, target) {
+					t.Errorf("expected target %q to be rejected", target)
+				}
+			}
+			if got := adapter.RequiresSpawn(); got != tc.requiresSpawn {
+				t.Errorf("expected RequiresSpawn %v, got %v", tc.requiresSpawn, got)
+			}
+		})
+	}
+
+	gdb, err := reg.GetWithDebugger(types.LanguageC, "gdb")
+	if err != nil {
+		t.Fatalf("failed to get gdb adapter: %v", err)
+	}
+	if !gdb.RequiresSpawn() || gdb.SupportedTargets() != nil {
+		t.Errorf("expected gdb to require spawning and have no targets")
+	}
+}
+
+// TestRegistry_NativeTarget verifies the generic native target and explicit debugger selection.
+func TestRegistry_NativeTarget(t *testing.T) {
+	cfg := config.DefaultConfig()
+	reg := adapters.NewRegistry(cfg)
+
+	adapter, err := reg.Get(types.LanguageNative)
+	if err != nil {
+		t.Fatalf("failed to get native adapter: %v", err)
+	}
+	if _, ok := adapter.(*adapters.LLDBAdapter); !ok {
+		t.Errorf("expected native target to default to LLDB, got %T", adapter)
+	}
+
+	tests := []struct {
+		name     string
+		lang     types.Language
+		debugger string
+		wantGDB  bool
+		wantErr  bool
+	}{
+		{"native default", types.LanguageNative, "", false, false},
+		{"native lldb", types.LanguageNative, "lldb", false, false},
+		{"native gdb", types.LanguageNative, "gdb", true, false},
+		{"rust gdb", types.LanguageRust, "gdb", true, false},
+		{"unknown debugger", types.LanguageNative, "windbg", false, true},
+		{"go with debugger", types.LanguageGo, "gdb", false, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			adapter, err := reg.GetWithDebugger(tc.lang, tc.debugger)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, isGDB := adapter.(*adapters.GDBAdapter)
+			if isGDB != tc.wantGDB {
+				t.Errorf("expected GDB=%v, got %T", tc.wantGDB, adapter)
+			}
+		})
+	}
+}
+
 // TestDelveAdapter_BuildLaunchArgs verifies Go launch argument building.
 func TestDelveAdapter_BuildLaunchArgs(t *testing.T) {
 	cfg := config.DefaultConfig()
@@ -140,6 +398,48 @@ func TestDelveAdapter_BuildLaunchArgs(t *testing.T) {
 	}
 }
 
+// TestBuildLaunchArgs_ProgramArgs verifies that every adapter passes program
+// arguments given as JSON arrays, string slices, or shell-style strings.
+func TestBuildLaunchArgs_ProgramArgs(t *testing.T) {
+	reg := adapters.NewRegistry(config.DefaultConfig())
+	want := []string{"--name", "John Smith", "-v"}
+
+	inputs := map[string]interface{}{
+		"json array":   []interface{}{"--name", "John Smith", "-v"},
+		"string slice": []string{"--name", "John Smith", "-v"}, // From a resolved launch.json
+		"string":       `--name "John Smith" -v`,
+	}
+
+	for _, lang := range []types.Language{types.LanguageGo, types.LanguagePython, types.LanguageJavaScript, types.LanguageC} {
+		for _, debugger := range []string{"", "gdb"} {
+			if debugger != "" && lang != types.LanguageC {
+				continue
+			}
+			adapter, err := reg.GetWithDebugger(lang, debugger)
+			if err != nil {
+				t.Fatalf("GetWithDebugger(%s, %q) failed: %v", lang, debugger, err)
+			}
+			for name, input := range inputs {
+				got, _ := adapter.BuildLaunchArgs("/path/to/program", map[string]interface{}{"args": input})["args"].([]string)
+				if strings.Join(got, "|") != strings.Join(want, "|") {
+					t.Errorf("%s %s with %s args: expected %q, got %q", lang, debugger, name, want, got)
+				}
+			}
+		}
+	}
+}
+
+// TestProgramArgs_UnterminatedQuote verifies an args string that doesn't split
+// is an error rather than a guess.
+func TestProgramArgs_UnterminatedQuote(t *testing.T) {
+	if _, _, err := adapters.ProgramArgs(map[string]interface{}{"args": `--name "John`}); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+	if _, ok, err := adapters.ProgramArgs(map[string]interface{}{}); ok || err != nil {
+		t.Errorf("expected no args and no error, got ok=%v err=%v", ok, err)
+	}
+}
+
 // TestDelveAdapter_BuildAttachArgs verifies Go attach argument building.
 func TestDelveAdapter_BuildAttachArgs(t *testing.T) {
 	cfg := config.DefaultConfig()
@@ -158,6 +458,12 @@ func TestDelveAdapter_BuildAttachArgs(t *testing.T) {
 	if args["mode"] != "local" {
 		t.Errorf("expected mode local, got %v", args["mode"])
 	}
+
+	// debug_attach passes the pid as an int
+	args = adapter.BuildAttachArgs(map[string]interface{}{"pid": 12345})
+	if args["processId"] != 12345 {
+		t.Errorf("expected processId 12345 from an int pid, got %v", args["processId"])
+	}
 }
 
 // TestDebugpyAdapter_BuildLaunchArgs verifies Python launch argument building.
@@ -195,13 +501,61 @@ func TestDebugpyAdapter_BuildAttachArgs(t *testing.T) {
 		"port": float64(5678), // JSON unmarshals integers as float64
 	})
 
-	if args["host"] != "localhost" {
-		t.Errorf("expected host localhost, got %v", args["host"])
+	// debugpy takes the server address as connect, with the port converted to int
+	connect, ok := args["connect"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected connect arguments, got %v", args)
 	}
+	if connect["host"] != "localhost" {
+		t.Errorf("expected host localhost, got %v", connect["host"])
+	}
+	if connect["port"] != 5678 {
+		t.Errorf("expected port 5678, got %v", connect["port"])
+	}
+}
 
-	// Port should be included (converted to int)
-	if args["port"] != 5678 {
-		t.Errorf("expected port 5678, got %v", args["port"])
+// TestSourceMap verifies that a source map in either form reaches lldb-dap
+// as pairs, CodeLLDB as an object, and debugpy as pathMappings.
+func TestSourceMap(t *testing.T) {
+	pairs := map[string]interface{}{
+		"sourceMap": []interface{}{[]interface{}{"/build/src", "/home/me/src"}},
+	}
+	object := map[string]interface{}{
+		"sourceMap": map[string]interface{}{"/build/src": "/home/me/src"},
+	}
+
+	lldb := adapters.NewLLDBAdapter(config.LLDBConfig{})
+	for name, args := range map[string]map[string]interface{}{"pairs": pairs, "object": object} {
+		for request, built := range map[string]map[string]interface{}{
+			"launch": lldb.BuildLaunchArgs("/src/app", args),
+			"attach": lldb.BuildAttachArgs(args),
+		} {
+			got, ok := built["sourceMap"].([][]string)
+			if !ok || len(got) != 1 || got[0][0] != "/build/src" || got[0][1] != "/home/me/src" {
+				t.Errorf("%s %s: expected the source map as pairs, got %v", name, request, built["sourceMap"])
+			}
+		}
+	}
+
+	codelldb := adapters.NewRustAdapter(config.RustConfig{CodelldbPath: "/path/to/codelldb", RustcSysroot: "/sysroot"}, config.LLDBConfig{})
+	launchArgs := codelldb.BuildLaunchArgs("/src/app", pairs)
+	if got, ok := launchArgs["sourceMap"].(map[string]string); !ok || got["/build/src"] != "/home/me/src" {
+		t.Errorf("expected CodeLLDB's source map as an object, got %v", launchArgs["sourceMap"])
+	}
+
+	debugpy, _ := adapters.NewRegistry(config.DefaultConfig()).Get(types.LanguagePython)
+	attachArgs := debugpy.BuildAttachArgs(pairs)
+	mappings, ok := attachArgs["pathMappings"].([]interface{})
+	if !ok || len(mappings) != 1 {
+		t.Fatalf("expected one path mapping, got %v", attachArgs["pathMappings"])
+	}
+	mapping := mappings[0].(map[string]interface{})
+	if mapping["remoteRoot"] != "/build/src" || mapping["localRoot"] != "/home/me/src" {
+		t.Errorf("expected the program's path as remoteRoot, got %v", mapping)
+	}
+
+	if _, _, err := adapters.SourceMap(map[string]interface{}{"sourceMap": []interface{}{[]interface{}{"/build/src"}}}); err == nil {
+		t.Error("expected an error for an entry that isn't a pair")
 	}
 }
 
@@ -243,6 +597,98 @@ func TestNodeAdapter_BuildLaunchArgs_Browser(t *testing.T) {
 	}
 }
 
+// TestNodeAdapter_BuildLaunchArgs_Terminal verifies a node-terminal command
+// runs in a shell under a Node.js launch.
+func TestNodeAdapter_BuildLaunchArgs_Terminal(t *testing.T) {
+	cfg := config.DefaultConfig()
+	reg := adapters.NewRegistry(cfg)
+	adapter, _ := reg.Get(types.LanguageJavaScript)
+
+	args := adapter.BuildLaunchArgs("", map[string]interface{}{
+		"target":  "terminal",
+		"command": "npm start",
+		"cwd":     "/project",
+	})
+
+	if args["type"] != "pwa-node" {
+		t.Errorf("expected a pwa-node launch, got %v", args["type"])
+	}
+	if _, ok := args["program"]; ok {
+		t.Errorf("expected no program, got %v", args["program"])
+	}
+	runtimeArgs, _ := args["runtimeArgs"].([]string)
+	if len(runtimeArgs) == 0 || runtimeArgs[len(runtimeArgs)-1] != "npm start" {
+		t.Errorf("expected the command as the shell's last argument, got %v", args["runtimeArgs"])
+	}
+	if args["cwd"] != "/project" {
+		t.Errorf("expected cwd /project, got %v", args["cwd"])
+	}
+}
+
+// TestNodeAdapter_SourceMapPathOverrides verifies the variables in configured
+// sourceMapPathOverrides are resolved for browser launches and attaches.
+func TestNodeAdapter_SourceMapPathOverrides(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Adapters.Node.SourceMapPathOverrides = map[string]string{
+		"webpack:///src/*": "${workspaceFolder}/src/*",
+		"/app/*":           "${webRoot}/*",
+		"/lib/*":           "${webRoot}/vendor/lib/*",
+		"vite:///*":        "${webRoot}",
+		"/@fs/*":           "*",
+		"/gen/*":           "${outDir}/*",
+	}
+	reg := adapters.NewRegistry(cfg)
+	adapter, _ := reg.Get(types.LanguageJavaScript)
+
+	want := map[string]string{
+		"webpack:///src/*": "/project/src/*",
+		"/app/*":           "/project/web/*",
+		"/lib/*":           "/project/web/vendor/lib/*",
+		"vite:///*":        "/project/web",
+		"/@fs/*":           "*",
+		"/gen/*":           "${outDir}/*", // Unknown variables are left as written
+	}
+	check := func(t *testing.T, args map[string]interface{}) {
+		t.Helper()
+		overrides, _ := args["sourceMapPathOverrides"].(map[string]string)
+		for pattern, replacement := range want {
+			if overrides[pattern] != replacement {
+				t.Errorf("expected %s -> %s, got %q", pattern, replacement, overrides[pattern])
+			}
+		}
+	}
+
+	t.Run("launch", func(t *testing.T) {
+		check(t, adapter.BuildLaunchArgs("http://localhost:3000", map[string]interface{}{
+			"target":                    "chrome",
+			"webRoot":                   "/project/web",
+			adapters.WorkspaceFolderArg: "/project",
+		}))
+	})
+
+	// Without a launch.json workspace, ${workspaceFolder} is the cwd
+	t.Run("attach", func(t *testing.T) {
+		check(t, adapter.BuildAttachArgs(map[string]interface{}{
+			"target":  "chrome",
+			"webRoot": "/project/web",
+			"cwd":     "/project",
+		}))
+	})
+
+	// With neither a workspace nor a cwd, ${workspaceFolder} falls back to the
+	// webRoot rather than resolving to "" (which would make /src/* absolute)
+	t.Run("webRoot only", func(t *testing.T) {
+		args := adapter.BuildLaunchArgs("http://localhost:3000", map[string]interface{}{
+			"target":  "chrome",
+			"webRoot": "/project/web",
+		})
+		overrides, _ := args["sourceMapPathOverrides"].(map[string]string)
+		if got := overrides["webpack:///src/*"]; got != "/project/web/src/*" {
+			t.Errorf("expected ${workspaceFolder} to fall back to the webRoot, got %q", got)
+		}
+	})
+}
+
 // TestNodeAdapter_BuildAttachArgs verifies Node attach argument building.
 func TestNodeAdapter_BuildAttachArgs(t *testing.T) {
 	cfg := config.DefaultConfig()
@@ -289,6 +735,126 @@ func TestConnect_InvalidAddress(t *testing.T) {
 	}
 }
 
+// TestConnect_ReturnsStructuredError verifies Connect failures use AdapterConnectFailed.
+func TestConnect_ReturnsStructuredError(t *testing.T) {
+	_, err := adapters.Connect("127.0.0.1:59999", 2)
+	if err == nil {
+		t.Fatal("expected error connecting to invalid address")
+	}
+
+	var debugErr *errors.DebugError
+	if !stderrors.As(err, &debugErr) {
+		t.Fatalf("expected *errors.DebugError, got %T", err)
+	}
+	if debugErr.Code != errors.CodeAdapterConnectFailed {
+		t.Errorf("expected code %s, got %s", errors.CodeAdapterConnectFailed, debugErr.Code)
+	}
+}
+
+// TestSpawnAndConnect_AdapterExitsImmediately verifies that when the adapter process
+// dies before accepting a connection, the error reports its stderr and exit.
+func TestSpawnAndConnect_AdapterExitsImmediately(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake adapter")
+	}
+
+	// A fake "python" that prints an error and exits, like a missing debugpy module
+	fakePython := filepath.Join(t.TempDir(), "python")
+	script := "#!/bin/sh\necho 'No module named debugpy.adapter' >&2\nexit 1\n"
+	if err := os.WriteFile(fakePython, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake adapter: %v", err)
+	}
+
+	adapter := adapters.NewDebugpyAdapter(config.DebugpyConfig{PythonPath: fakePython})
+
+	start := time.Now()
+	_, _, err := adapters.SpawnAndConnect(context.Background(), adapter, "main.py", map[string]interface{}{})
+	if err == nil {
+		t.Fatal("expected error when adapter exits immediately")
+	}
+
+	var debugErr *errors.DebugError
+	if !stderrors.As(err, &debugErr) {
+		t.Fatalf("expected *errors.DebugError, got %T: %v", err, err)
+	}
+	if debugErr.Code != errors.CodeAdapterConnectFailed {
+		t.Errorf("expected code %s, got %s", errors.CodeAdapterConnectFailed, debugErr.Code)
+	}
+	if exited, _ := debugErr.Details["processExited"].(bool); !exited {
+		t.Error("expected processExited to be true")
+	}
+	if !strings.Contains(err.Error(), "No module named debugpy.adapter") {
+		t.Errorf("expected adapter stderr in error, got: %v", err)
+	}
+
+	// Retrying should stop as soon as the process is gone, well before the full backoff
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected early failure after adapter exit, took %v", elapsed)
+	}
+}
+
+// TestDelveAdapter_LaunchBuildFlags verifies that launch.json buildFlags
+// override the configured build flags for that session only.
+func TestDelveAdapter_LaunchBuildFlags(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake adapter")
+	}
+
+	// A fake dlv that prints one argument per line to stderr and exits
+	fakeDlv := filepath.Join(t.TempDir(), "dlv")
+	script := "#!/bin/sh\nfor a in \"$@\"; do echo \"$a\" >&2; done\n"
+	if err := os.WriteFile(fakeDlv, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake adapter: %v", err)
+	}
+
+	adapter := adapters.NewDelveAdapter(config.DelveConfig{Path: fakeDlv, BuildFlags: "-race"})
+
+	buildFlagsOf := func(args map[string]interface{}) string {
+		t.Helper()
+		_, cmd, err := adapter.Spawn(context.Background(), "./cmd/app", args)
+		if err != nil {
+			t.Fatalf("Spawn failed: %v", err)
+		}
+		_ = cmd.Wait()
+
+		lines := cmd.Stderr.(*dap.AdapterLog).Lines()
+		for i, line := range lines {
+			if line == "--build-flags" && i+1 < len(lines) {
+				return lines[i+1]
+			}
+		}
+		return ""
+	}
+
+	cfg := &launchconfig.DebugConfiguration{
+		Type:       "go",
+		Request:    "launch",
+		Name:       "Integration",
+		Program:    "${workspaceFolder}/cmd/app",
+		BuildFlags: "-tags integration",
+	}
+	resolved, err := launchconfig.ResolveConfiguration(cfg, &launchconfig.ResolutionContext{WorkspaceFolder: "/ws"})
+	if err != nil {
+		t.Fatalf("ResolveConfiguration failed: %v", err)
+	}
+	launchArgs := resolved.ToLaunchArgs()
+
+	if got := buildFlagsOf(launchArgs); got != "-tags integration" {
+		t.Errorf("expected launch.json buildFlags passed to dlv, got %q", got)
+	}
+	if got := adapter.BuildLaunchArgs("/ws/cmd/app", launchArgs)["buildFlags"]; got != "-tags integration" {
+		t.Errorf("expected launch.json buildFlags in launch request, got %v", got)
+	}
+
+	// The next session without buildFlags still uses the configured default
+	if got := buildFlagsOf(map[string]interface{}{}); got != "-race" {
+		t.Errorf("expected configured buildFlags for other sessions, got %q", got)
+	}
+	if got := adapter.BuildLaunchArgs("/ws/cmd/app", map[string]interface{}{})["buildFlags"]; got != "-race" {
+		t.Errorf("expected configured buildFlags in launch request, got %v", got)
+	}
+}
+
 // TestAdapterLanguageConstants verifies language constant values.
 func TestAdapterLanguageConstants(t *testing.T) {
 	// Ensure language constants have expected string values
@@ -300,6 +866,9 @@ func TestAdapterLanguageConstants(t *testing.T) {
 		{types.LanguagePython, "python"},
 		{types.LanguageJavaScript, "javascript"},
 		{types.LanguageTypeScript, "typescript"},
+		{types.LanguageSwift, "swift"},
+		{types.LanguageElixir, "elixir"},
+		{types.LanguageNative, "native"},
 	}
 
 	for _, tc := range tests {
@@ -324,3 +893,93 @@ func TestDebugpyAdapter_BuildLaunchArgs_PythonPath(t *testing.T) {
 		t.Errorf("expected pythonPath /custom/venv/bin/python3, got %v", args["pythonPath"])
 	}
 }
+
+// TestDebugpyAdapter_BuildLaunchArgs_JustMyCode verifies justMyCode is passed
+// through only when set, leaving debugpy's default otherwise.
+func TestDebugpyAdapter_BuildLaunchArgs_JustMyCode(t *testing.T) {
+	cfg := config.DefaultConfig()
+	reg := adapters.NewRegistry(cfg)
+	adapter, _ := reg.Get(types.LanguagePython)
+
+	args := adapter.BuildLaunchArgs("/path/to/script.py", map[string]interface{}{"justMyCode": false})
+	if args["justMyCode"] != false {
+		t.Errorf("expected justMyCode false, got %v", args["justMyCode"])
+	}
+	if _, ok := adapter.BuildLaunchArgs("/path/to/script.py", map[string]interface{}{})["justMyCode"]; ok {
+		t.Error("expected no justMyCode when it isn't set")
+	}
+	if args := adapter.BuildAttachArgs(map[string]interface{}{"port": float64(5678), "justMyCode": false}); args["justMyCode"] != false {
+		t.Errorf("expected attach justMyCode false, got %v", args["justMyCode"])
+	}
+}
+
+// newFakeBrowser serves a Chrome DevTools /json/list endpoint and returns its host and port.
+func newFakeBrowser(t *testing.T, targets string) (string, int) {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json/list" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(targets))
+	}))
+	t.Cleanup(srv.Close)
+
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+const fakeBrowserTargets = `[
+	{"id": "1", "type": "page", "title": "App", "url": "http://localhost:3000/"},
+	{"id": "2", "type": "page", "title": "Settings", "url": "http://localhost:3000/settings"},
+	{"id": "3", "type": "service_worker", "title": "sw", "url": "http://localhost:3000/sw.js"},
+	{"id": "4", "type": "page", "title": "Docs", "url": "https://example.com/docs"}
+]`
+
+// TestListBrowserTabs verifies only page targets are returned.
+func TestListBrowserTabs(t *testing.T) {
+	host, port := newFakeBrowser(t, fakeBrowserTargets)
+
+	tabs, err := adapters.ListBrowserTabs(context.Background(), host, port)
+	if err != nil {
+		t.Fatalf("ListBrowserTabs failed: %v", err)
+	}
+	if len(tabs) != 3 {
+		t.Fatalf("expected 3 page tabs, got %d: %+v", len(tabs), tabs)
+	}
+	if tabs[1].Title != "Settings" || tabs[1].URL != "http://localhost:3000/settings" {
+		t.Errorf("unexpected tab: %+v", tabs[1])
+	}
+}
+
+// TestMatchBrowserTabs verifies exact, wildcard, and substring url matching.
+func TestMatchBrowserTabs(t *testing.T) {
+	tabs := []adapters.BrowserTab{
+		{Title: "App", URL: "http://localhost:3000/"},
+		{Title: "Settings", URL: "http://localhost:3000/settings"},
+		{Title: "Docs", URL: "https://example.com/docs"},
+	}
+
+	tests := []struct {
+		pattern string
+		exact   string
+		matches int
+	}{
+		{"http://localhost:3000", "http://localhost:3000/", 2},
+		{"http://localhost:3000/*", "", 2},
+		{"*/docs", "", 1},
+		{"example.com", "", 1},
+		{"http://localhost:8080/*", "", 0},
+	}
+	for _, tt := range tests {
+		exact, matches := adapters.MatchBrowserTabs(tabs, tt.pattern)
+		gotExact := ""
+		if exact != nil {
+			gotExact = exact.URL
+		}
+		if gotExact != tt.exact || len(matches) != tt.matches {
+			t.Errorf("MatchBrowserTabs(%q) = %q, %d matches; want %q, %d", tt.pattern, gotExact, len(matches), tt.exact, tt.matches)
+		}
+	}
+}
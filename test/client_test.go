@@ -0,0 +1,503 @@
+package test
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-dap"
+
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	"github.com/ctagard/dap-mcp/internal/errors"
+)
+
+// TestClient_ProcessEventConfirmsLaunch verifies that a process event records the
+// debuggee and confirms the launch without waiting for the launch response.
+func TestClient_ProcessEventConfirmsLaunch(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+
+	// No launch handler: the adapter never answers the launch request
+	respCh, err := client.LaunchAsync(map[string]interface{}{"program": "/path/to/app"})
+	if err != nil {
+		t.Fatalf("LaunchAsync failed: %v", err)
+	}
+
+	if client.ProcessInfo() != nil {
+		t.Fatal("expected no process info before the process event")
+	}
+
+	fake.sendEvent(&dap.ProcessEvent{
+		Event: dap.Event{Event: "process"},
+		Body: dap.ProcessEventBody{
+			Name:            "/path/to/app",
+			SystemProcessId: 4321,
+			IsLocalProcess:  true,
+			StartMethod:     "launch",
+		},
+	})
+
+	start := time.Now()
+	if err := client.WaitForLaunch(respCh, 5*time.Second); err != nil {
+		t.Fatalf("WaitForLaunch failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected process event to confirm launch quickly, took %v", elapsed)
+	}
+
+	info := client.ProcessInfo()
+	if info == nil {
+		t.Fatal("expected process info after process event")
+	}
+	if info.PID != 4321 {
+		t.Errorf("expected debuggee PID 4321, got %d", info.PID)
+	}
+	if info.Name != "/path/to/app" {
+		t.Errorf("expected debuggee name /path/to/app, got %s", info.Name)
+	}
+}
+
+// TestClient_WaitForLaunch_FailedResponse verifies a failed launch response is
+// reported with the adapter's reason, even after the process event.
+func TestClient_WaitForLaunch_FailedResponse(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	fake.handle("launch", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ErrorResponse{Response: dap.Response{Message: "program not found"}}
+	})
+
+	respCh, err := client.LaunchAsync(map[string]interface{}{"program": "/missing"})
+	if err != nil {
+		t.Fatalf("LaunchAsync failed: %v", err)
+	}
+
+	if err := client.WaitForLaunch(respCh, 5*time.Second); err == nil || !strings.Contains(err.Error(), "program not found") {
+		t.Errorf("expected the adapter's reason for the failed launch, got %v", err)
+	}
+
+	// The process event arrives before the failed launch response
+	fake, client = newFakeAdapter(t)
+	fake.handle("launch", func(req dap.RequestMessage) dap.ResponseMessage {
+		fake.sendEvent(&dap.ProcessEvent{
+			Event: dap.Event{Event: "process"},
+			Body:  dap.ProcessEventBody{Name: "/src/main.go", SystemProcessId: 4321},
+		})
+		return &dap.ErrorResponse{Body: dap.ErrorResponseBody{Error: &dap.ErrorMessage{
+			Format: "Build error: main.go:3:1: syntax error",
+		}}}
+	})
+
+	respCh, err = client.LaunchAsync(map[string]interface{}{"program": "/src/main.go"})
+	if err != nil {
+		t.Fatalf("LaunchAsync failed: %v", err)
+	}
+
+	if err := client.WaitForLaunch(respCh, 5*time.Second); err == nil || !strings.Contains(err.Error(), "syntax error") {
+		t.Errorf("expected the build error after the process event, got %v", err)
+	}
+}
+
+// TestClient_RequestTimeoutNamesCommand verifies timeouts report the hung request
+// and the other requests still pending.
+func TestClient_RequestTimeoutNamesCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the 10s request timeout")
+	}
+
+	// No handlers: the adapter never answers
+	_, client := newFakeAdapter(t)
+
+	// The launch response is still outstanding when threads times out
+	if _, err := client.LaunchAsync(map[string]interface{}{"program": "/path/to/program"}); err != nil {
+		t.Fatalf("LaunchAsync failed: %v", err)
+	}
+
+	_, err := client.Threads()
+	if err == nil {
+		t.Fatal("expected threads request to time out")
+	}
+	var debugErr *errors.DebugError
+	if !stderrors.As(err, &debugErr) {
+		t.Fatalf("expected DebugError, got %T: %v", err, err)
+	}
+	if debugErr.Code != errors.CodeDAPTimeout {
+		t.Errorf("expected code %s, got %s", errors.CodeDAPTimeout, debugErr.Code)
+	}
+	if !strings.Contains(debugErr.Message, "threads request timed out after 10s") {
+		t.Errorf("expected command in timeout message, got %q", debugErr.Message)
+	}
+	pending, _ := debugErr.Details["pendingRequests"].([]string)
+	if len(pending) != 1 || !strings.HasPrefix(pending[0], "launch") {
+		t.Errorf("expected pending launch request, got %v", debugErr.Details["pendingRequests"])
+	}
+}
+
+// TestClient_DistinctSeqs verifies async and sync requests get distinct seqs
+// and each response reaches the request it answers.
+func TestClient_DistinctSeqs(t *testing.T) {
+	// No handlers: the test answers the requests itself
+	fake, client := newFakeAdapter(t)
+
+	launchCh, err := client.LaunchAsync(map[string]interface{}{"program": "/path/to/app"})
+	if err != nil {
+		t.Fatalf("LaunchAsync failed: %v", err)
+	}
+	attachCh, err := client.AttachAsync(map[string]interface{}{"pid": 42})
+	if err != nil {
+		t.Fatalf("AttachAsync failed: %v", err)
+	}
+	threadsErr := make(chan error, 1)
+	go func() {
+		_, err := client.Threads()
+		threadsErr <- err
+	}()
+
+	var launch, attach, threads []dap.RequestMessage
+	for deadline := time.Now().Add(5 * time.Second); len(threads) == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("threads request never reached the adapter")
+		}
+		launch, attach, threads = fake.received("launch"), fake.received("attach"), fake.received("threads")
+	}
+	seqs := map[int]bool{launch[0].GetSeq(): true, attach[0].GetSeq(): true, threads[0].GetSeq(): true}
+	if len(seqs) != 3 {
+		t.Fatalf("expected 3 distinct seqs, got launch %d, attach %d, threads %d", launch[0].GetSeq(), attach[0].GetSeq(), threads[0].GetSeq())
+	}
+
+	// Answered out of order, each response still reaches its own request
+	fake.respond(threads[0], &dap.ThreadsResponse{})
+	fake.respond(attach[0], &dap.AttachResponse{})
+	fake.respond(launch[0], &dap.LaunchResponse{})
+	if err := <-threadsErr; err != nil {
+		t.Errorf("threads request failed: %v", err)
+	}
+	for name, ch := range map[string]chan dap.Message{"launch": launchCh, "attach": attachCh} {
+		select {
+		case resp := <-ch:
+			if got := resp.(dap.ResponseMessage).GetResponse().Command; got != name {
+				t.Errorf("expected the %s response, got %s", name, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("no response delivered to the %s request", name)
+		}
+	}
+}
+
+// TestClient_MaxInFlight verifies a slow adapter never has more than the
+// configured number of requests outstanding, with the rest queued.
+func TestClient_MaxInFlight(t *testing.T) {
+	// No threads handler: the test answers the requests it has received
+	fake, client := newFakeAdapter(t)
+	client.SetMaxInFlight(2)
+
+	const requests = 5
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			_, err := client.Threads()
+			errs <- err
+		}()
+	}
+
+	answered := 0
+	for deadline := time.Now().Add(5 * time.Second); answered < requests; {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d requests reached the adapter", answered, requests)
+		}
+		time.Sleep(50 * time.Millisecond)
+		received := fake.received("threads")
+		if outstanding := len(received) - answered; outstanding > 2 {
+			t.Fatalf("expected at most 2 outstanding requests, got %d", outstanding)
+		}
+		for _, req := range received[answered:] {
+			fake.respond(req, &dap.ThreadsResponse{})
+		}
+		answered = len(received)
+	}
+
+	for i := 0; i < requests; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("threads request failed: %v", err)
+		}
+	}
+}
+
+// TestClient_CapabilitiesEventMerges verifies capabilities sent after initialize are
+// merged into the initialize capabilities rather than replacing them.
+func TestClient_CapabilitiesEventMerges(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+
+	fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.InitializeResponse{Body: dap.Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsSetVariable:              true,
+		}}
+	})
+	if _, err := client.Initialize("test", "test"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	fake.sendEvent(&dap.CapabilitiesEvent{
+		Event: dap.Event{Event: "capabilities"},
+		Body: dap.CapabilitiesEventBody{Capabilities: dap.Capabilities{
+			SupportsExceptionInfoRequest: true,
+			ExceptionBreakpointFilters: []dap.ExceptionBreakpointsFilter{
+				{Filter: "raised", Label: "Raised Exceptions"},
+				{Filter: "uncaught", Label: "Uncaught Exceptions"},
+			},
+		}},
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.Capabilities().SupportsExceptionInfoRequest && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	caps := client.Capabilities()
+	if !caps.SupportsExceptionInfoRequest || len(caps.ExceptionBreakpointFilters) != 2 {
+		t.Errorf("expected capabilities from the event, got %+v", caps)
+	}
+	if !caps.SupportsConfigurationDoneRequest || !caps.SupportsSetVariable {
+		t.Errorf("expected initialize capabilities to be kept, got %+v", caps)
+	}
+}
+
+// TestClient_SendRaw verifies that requests and responses for commands go-dap
+// doesn't know are sent and routed by request seq.
+func TestClient_SendRaw(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	fake.handle("dlvCommand", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &internaldap.RawResponse{Body: json.RawMessage(`{"result": "Goroutine 1 - main.main"}`)}
+	})
+	fake.handle("hotReload", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ErrorResponse{Response: dap.Response{Message: "no app running"}}
+	})
+
+	resp, err := client.SendRaw("dlvCommand", json.RawMessage(`{"command": "goroutines"}`), 5*time.Second)
+	if err != nil {
+		t.Fatalf("SendRaw failed: %v", err)
+	}
+	if !resp.Success || resp.Command != "dlvCommand" {
+		t.Errorf("expected a successful dlvCommand response, got %+v", resp)
+	}
+	var body struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil || body.Result != "Goroutine 1 - main.main" {
+		t.Errorf("expected the raw response body, got %s (%v)", resp.Body, err)
+	}
+
+	received := fake.received("dlvCommand")
+	if len(received) != 1 {
+		t.Fatalf("expected one dlvCommand request, got %d", len(received))
+	}
+	if args := string(received[0].(*customRequest).Arguments); args != `{"command":"goroutines"}` {
+		t.Errorf("expected the raw arguments to be sent, got %s", args)
+	}
+
+	resp, err = client.SendRaw("hotReload", nil, 5*time.Second)
+	if err != nil {
+		t.Fatalf("SendRaw failed: %v", err)
+	}
+	if resp.Success || resp.Message != "no app running" {
+		t.Errorf("expected a failed response with the adapter's message, got %+v", resp)
+	}
+
+	// Typed responses keep working alongside raw ones
+	fake.handle("threads", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ThreadsResponse{Body: dap.ThreadsResponseBody{Threads: []dap.Thread{{Id: 1, Name: "main"}}}}
+	})
+	if threads, err := client.Threads(); err != nil || len(threads) != 1 {
+		t.Errorf("expected threads after raw requests, got %v (%v)", threads, err)
+	}
+}
+
+// TestConnect_RetriesInitializeAfterEarlyClose verifies an adapter that closes
+// connections before answering initialize is re-dialed, while one that answers
+// initialize with an error is not.
+func TestConnect_RetriesInitializeAfterEarlyClose(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// The first connection is closed unanswered, like an adapter still starting
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if accepted.Add(1) == 1 {
+				_ = conn.Close()
+				continue
+			}
+			fake := newFakeAdapterConn(conn)
+			fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+				if accepted.Load() > 2 {
+					return &dap.ErrorResponse{Response: dap.Response{Message: "unsupported client"}}
+				}
+				return &dap.InitializeResponse{Body: dap.Capabilities{SupportsConfigurationDoneRequest: true}}
+			})
+			go fake.serve()
+		}
+	}()
+
+	client, err := adapters.Connect(listener.Addr().String(), 3)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	if n := accepted.Load(); n != 2 {
+		t.Errorf("expected 2 connections, got %d", n)
+	}
+	if !client.Capabilities().SupportsConfigurationDoneRequest {
+		t.Error("expected the capabilities from the second connection's initialize")
+	}
+	// The handshake is done, so a later Initialize doesn't send another
+	if _, err := client.Initialize(internaldap.ClientID, internaldap.ClientName); err != nil {
+		t.Errorf("repeated initialize failed: %v", err)
+	}
+
+	// A genuine initialize failure is reported without reconnecting
+	_, err = adapters.Connect(listener.Addr().String(), 3)
+	var debugErr *errors.DebugError
+	if !stderrors.As(err, &debugErr) || debugErr.Code != errors.CodeDAPInitFailed {
+		t.Fatalf("expected %s, got %v", errors.CodeDAPInitFailed, err)
+	}
+	if closed, _ := debugErr.Details["connectionClosed"].(bool); closed {
+		t.Error("expected connectionClosed to be false for a failed initialize")
+	}
+	if n := accepted.Load(); n != 3 {
+		t.Errorf("expected 3 connections, got %d", n)
+	}
+}
+
+// TestClient_LogpointAutoContinue verifies that a stop at a logpoint on an
+// adapter without logpoint support is logged and continued past, while other
+// stops, adapters with logpoint support, and disabled emulation are reported.
+func TestClient_LogpointAutoContinue(t *testing.T) {
+	const path = "/src/main.go"
+	setup := func(t *testing.T, native, emulate bool) (*fakeAdapter, *internaldap.Client) {
+		fake, client := newFakeAdapter(t)
+		fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.InitializeResponse{Body: dap.Capabilities{SupportsLogPoints: native}}
+		})
+		fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{
+				Breakpoints: []dap.Breakpoint{{Id: 1, Verified: true, Line: 10}, {Id: 2, Verified: true, Line: 20}},
+			}}
+		})
+		fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+				StackFrames: []dap.StackFrame{{Id: 1000, Name: "main.loop", Line: 10, Source: &dap.Source{Path: path}}},
+			}}
+		})
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "42"}}
+		})
+		fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.ContinueResponse{}
+		})
+
+		client.SetEmulateLogpoints(emulate)
+		if _, err := client.Initialize("test", "test"); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		if _, err := client.SetBreakpoints(dap.Source{Path: path}, []dap.SourceBreakpoint{
+			{Line: 10, LogMessage: "x = {x}"},
+			{Line: 20},
+		}); err != nil {
+			t.Fatalf("SetBreakpoints failed: %v", err)
+		}
+		return fake, client
+	}
+	stopAt := func(fake *fakeAdapter, hit int) {
+		fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, HitBreakpointIds: []int{hit}},
+		})
+	}
+	waitForStop := func(t *testing.T, client *internaldap.Client) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); client.LastStop() == nil; {
+			if time.Now().After(deadline) {
+				t.Fatal("the stop was not reported")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	t.Run("emulated", func(t *testing.T) {
+		fake, client := setup(t, false, true)
+
+		stopAt(fake, 1)
+		for deadline := time.Now().Add(2 * time.Second); len(fake.received("continue")) == 0; {
+			if time.Now().After(deadline) {
+				t.Fatal("the logpoint stop was not continued")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if client.LastStop() != nil {
+			t.Error("the logpoint stop should not be reported")
+		}
+		entries, _ := client.Output()
+		if len(entries) != 1 || entries[0].Output != "x = 42\n" || entries[0].Category != "console" || entries[0].Line != 10 {
+			t.Errorf("expected the logged message in the output, got %+v", entries)
+		}
+
+		// A stop at a breakpoint that isn't a logpoint pauses as usual
+		stopAt(fake, 2)
+		waitForStop(t, client)
+		if n := len(fake.received("continue")); n != 1 {
+			t.Errorf("expected no further continue, got %d", n)
+		}
+	})
+
+	t.Run("refused evaluation", func(t *testing.T) {
+		fake, client := setup(t, false, true)
+		client.SetLogpointEvaluator(func(expression string, frameID int) (string, bool) {
+			return "", false
+		})
+
+		stopAt(fake, 1)
+		for deadline := time.Now().Add(2 * time.Second); len(fake.received("continue")) == 0; {
+			if time.Now().After(deadline) {
+				t.Fatal("the logpoint stop was not continued")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		entries, _ := client.Output()
+		if len(entries) != 1 || entries[0].Output != "x = {x}\n" {
+			t.Errorf("expected the message logged as written, got %+v", entries)
+		}
+		if n := len(fake.received("evaluate")); n != 0 {
+			t.Errorf("expected no evaluation, got %d requests", n)
+		}
+	})
+
+	t.Run("native logpoints", func(t *testing.T) {
+		fake, client := setup(t, true, true)
+
+		stopAt(fake, 1)
+		waitForStop(t, client)
+		if n := len(fake.received("continue")) + len(fake.received("stackTrace")); n != 0 {
+			t.Errorf("expected the stop to be left alone, got %d requests", n)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		fake, client := setup(t, false, false)
+
+		stopAt(fake, 1)
+		waitForStop(t, client)
+		if n := len(fake.received("continue")); n != 0 {
+			t.Errorf("expected no continue, got %d", n)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +32,9 @@ func TestDefaultConfig(t *testing.T) {
 	if !cfg.AllowExecute {
 		t.Error("expected AllowExecute to be true by default")
 	}
+	if !cfg.LogpointAutoContinue {
+		t.Error("expected LogpointAutoContinue to be true by default")
+	}
 
 	// Verify safety limits
 	if cfg.MaxSessions != 10 {
@@ -39,11 +43,20 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.SessionTimeout != 30*time.Minute {
 		t.Errorf("expected SessionTimeout 30m, got %v", cfg.SessionTimeout)
 	}
+	if cfg.MaxVariableValueLength != 2048 {
+		t.Errorf("expected MaxVariableValueLength 2048, got %d", cfg.MaxVariableValueLength)
+	}
+	if cfg.MaxSourceSize != 1<<20 {
+		t.Errorf("expected MaxSourceSize 1 MiB, got %d", cfg.MaxSourceSize)
+	}
 
 	// Verify adapter defaults
 	if cfg.Adapters.Go.Path != "dlv" {
 		t.Errorf("expected Go adapter path 'dlv', got %s", cfg.Adapters.Go.Path)
 	}
+	if cfg.Adapters.Go.EntryFunction != "main.main" {
+		t.Errorf("expected Go entry function 'main.main', got %s", cfg.Adapters.Go.EntryFunction)
+	}
 	if cfg.Adapters.Python.PythonPath != "python3" {
 		t.Errorf("expected Python path 'python3', got %s", cfg.Adapters.Python.PythonPath)
 	}
@@ -277,6 +290,166 @@ func TestCanEvaluate(t *testing.T) {
 	if cfg.CanEvaluate() {
 		t.Error("disabled execute should not allow evaluate")
 	}
+
+	// Read-only evaluation is allowed without execute
+	cfg.EvaluateReadOnly = true
+	if !cfg.CanEvaluate() {
+		t.Error("evaluateReadOnly should allow evaluate")
+	}
+}
+
+// TestEvaluationLevel verifies the evaluation field, its derivation from the
+// permission flags, and that readonly mode never evaluates in full.
+func TestEvaluationLevel(t *testing.T) {
+	tests := []struct {
+		name             string
+		mode             config.CapabilityMode
+		evaluation       string
+		allowExecute     bool
+		evaluateReadOnly bool
+		expected         string
+	}{
+		{"full mode default", config.ModeFull, "", true, false, config.EvaluationFull},
+		{"full mode without execute", config.ModeFull, "", false, false, config.EvaluationNone},
+		{"full mode evaluateReadOnly", config.ModeFull, "", false, true, config.EvaluationReadOnly},
+		{"readonly mode default", config.ModeReadOnly, "", true, false, config.EvaluationReadOnly},
+		{"readonly mode without execute", config.ModeReadOnly, "", false, false, config.EvaluationNone},
+		{"readonly mode no evaluation", config.ModeReadOnly, config.EvaluationNone, true, true, config.EvaluationNone},
+		{"readonly mode read-only evaluation", config.ModeReadOnly, config.EvaluationReadOnly, false, false, config.EvaluationReadOnly},
+		{"readonly mode full evaluation", config.ModeReadOnly, config.EvaluationFull, true, false, config.EvaluationReadOnly},
+		{"full mode read-only evaluation", config.ModeFull, config.EvaluationReadOnly, true, false, config.EvaluationReadOnly},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Mode = tc.mode
+			cfg.Evaluation = tc.evaluation
+			cfg.AllowExecute = tc.allowExecute
+			cfg.EvaluateReadOnly = tc.evaluateReadOnly
+			if got := cfg.EvaluationLevel(); got != tc.expected {
+				t.Errorf("EvaluationLevel() = %s, want %s", got, tc.expected)
+			}
+			if cfg.CanEvaluate() != (tc.expected != config.EvaluationNone) {
+				t.Errorf("CanEvaluate() = %v for level %s", cfg.CanEvaluate(), tc.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluationContext verifies the default evaluation context is looked up
+// by native debugger, then language, then the default.
+func TestEvaluationContext(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, tc := range []struct{ language, debugger, want string }{
+		{"python", "", "repl"},
+		{"typescript", "", "repl"},
+		{"go", "", "watch"},
+		{"rust", "lldb", "watch"},
+	} {
+		if got := cfg.EvaluationContext(tc.language, tc.debugger); got != tc.want {
+			t.Errorf("EvaluationContext(%q, %q) = %q, want %q", tc.language, tc.debugger, got, tc.want)
+		}
+	}
+
+	cfg.EvaluationContexts["gdb"] = "repl"
+	if got := cfg.EvaluationContext("c", "gdb"); got != "repl" {
+		t.Errorf("expected the gdb entry, got %q", got)
+	}
+	cfg.EvaluationContexts = nil
+	if got := cfg.EvaluationContext("python", ""); got != "watch" {
+		t.Errorf("expected watch without any entries, got %q", got)
+	}
+}
+
+// TestMaxInFlight verifies the request limit is looked up by native debugger,
+// then language, then the default.
+func TestMaxInFlight(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if got := cfg.MaxInFlight("go", ""); got != 16 {
+		t.Errorf("expected the default limit 16, got %d", got)
+	}
+
+	cfg.MaxInFlightRequests["elixir"] = 1
+	cfg.MaxInFlightRequests["rust"] = 4
+	cfg.MaxInFlightRequests["lldb"] = 2
+	if got := cfg.MaxInFlight("elixir", ""); got != 1 {
+		t.Errorf("expected the elixir limit 1, got %d", got)
+	}
+	if got := cfg.MaxInFlight("rust", "lldb"); got != 2 {
+		t.Errorf("expected the lldb limit to win over the language, got %d", got)
+	}
+	if got := cfg.MaxInFlight("rust", "gdb"); got != 4 {
+		t.Errorf("expected the rust limit under gdb, got %d", got)
+	}
+}
+
+// TestConfigDefaultEnv verifies each language reads the defaultEnv of its
+// adapter config, and native languages that of their debugger.
+func TestConfigDefaultEnv(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Adapters.Node.DefaultEnv = map[string]string{"NODE_OPTIONS": "--enable-source-maps"}
+	cfg.Adapters.LLDB.DefaultEnv = map[string]string{"DEBUGGER": "lldb"}
+	cfg.Adapters.GDB.DefaultEnv = map[string]string{"DEBUGGER": "gdb"}
+
+	if got := cfg.DefaultEnv("typescript", ""); got["NODE_OPTIONS"] != "--enable-source-maps" {
+		t.Errorf("expected typescript to use the node defaultEnv, got %v", got)
+	}
+	if got := cfg.DefaultEnv("cpp", "lldb"); got["DEBUGGER"] != "lldb" {
+		t.Errorf("expected cpp under lldb to use the lldb defaultEnv, got %v", got)
+	}
+	if got := cfg.DefaultEnv("c", "gdb"); got["DEBUGGER"] != "gdb" {
+		t.Errorf("expected c under gdb to use the gdb defaultEnv, got %v", got)
+	}
+	if got := cfg.DefaultEnv("go", ""); len(got) != 0 {
+		t.Errorf("expected no go defaultEnv, got %v", got)
+	}
+}
+
+// TestSetFileValue verifies dotted keys are written into a config file
+// without disturbing its other settings, and that LoadConfig records the path.
+func TestSetFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"mode": "readonly", "adapters": {"go": {"buildFlags": "-tags x"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.SetFileValue(path, "adapters.go.path", "/opt/bin/dlv"); err != nil {
+		t.Fatalf("SetFileValue failed: %v", err)
+	}
+	if err := config.SetFileValue(path, "adapters.node.jsDebugPath", "/opt/js-debug/src/dapDebugServer.js"); err != nil {
+		t.Fatalf("SetFileValue failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Path != path {
+		t.Errorf("expected Path %q, got %q", path, cfg.Path)
+	}
+	if cfg.Mode != config.ModeReadOnly {
+		t.Errorf("expected the mode to be kept, got %s", cfg.Mode)
+	}
+	if cfg.Adapters.Go.Path != "/opt/bin/dlv" || cfg.Adapters.Go.BuildFlags != "-tags x" {
+		t.Errorf("unexpected go adapter config: %+v", cfg.Adapters.Go)
+	}
+	if cfg.Adapters.Node.JsDebugPath != "/opt/js-debug/src/dapDebugServer.js" {
+		t.Errorf("unexpected jsDebugPath %q", cfg.Adapters.Node.JsDebugPath)
+	}
+
+	// A missing file is created
+	fresh := filepath.Join(t.TempDir(), "new.json")
+	if err := config.SetFileValue(fresh, "adapters.python.pythonPath", "/usr/bin/python3"); err != nil {
+		t.Fatalf("SetFileValue on a new file failed: %v", err)
+	}
+	cfg, err = config.LoadConfig(fresh)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Adapters.Python.PythonPath != "/usr/bin/python3" {
+		t.Errorf("unexpected pythonPath %q", cfg.Adapters.Python.PythonPath)
+	}
 }
 
 // TestCapabilityModes verifies the capability mode constants.
@@ -288,3 +461,92 @@ func TestCapabilityModes(t *testing.T) {
 		t.Errorf("expected ModeFull='full', got %s", config.ModeFull)
 	}
 }
+
+// TestConfigValidate verifies each validation rule: unusable settings are
+// errors, and ignored or contradictory settings are warnings.
+func TestConfigValidate(t *testing.T) {
+	jsDebug := filepath.Join(t.TempDir(), "dapDebugServer.js")
+	if err := os.WriteFile(jsDebug, []byte("// js-debug"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	valid := func() *config.Config {
+		cfg := config.DefaultConfig()
+		cfg.Adapters.Node.JsDebugPath = jsDebug
+		return cfg
+	}
+
+	if warnings, err := valid().Validate(); err != nil || len(warnings) != 0 {
+		t.Fatalf("expected the defaults with js-debug to be valid, got warnings %v and error %v", warnings, err)
+	}
+
+	errorCases := []struct {
+		name   string
+		modify func(*config.Config)
+		want   string
+	}{
+		{"unknown mode", func(c *config.Config) { c.Mode = "Full" }, `mode "Full"`},
+		{"unknown evaluation", func(c *config.Config) { c.Evaluation = "readonly" }, `evaluation "readonly"`},
+		{"no sessions", func(c *config.Config) { c.MaxSessions = 0 }, "maxSessions is 0"},
+		{"negative timeout", func(c *config.Config) { c.SessionTimeout = -time.Minute }, "sessionTimeout"},
+		{"timeout in seconds", func(c *config.Config) { c.SessionTimeout = 1800 }, "nanoseconds"},
+		{"negative value length", func(c *config.Config) { c.MaxVariableValueLength = -1 }, "maxVariableValueLength is -1"},
+		{"negative source size", func(c *config.Config) { c.MaxSourceSize = -1 }, "maxSourceSize is -1"},
+		{"negative in-flight limit", func(c *config.Config) { c.MaxInFlightRequests["gdb"] = -2 }, `maxInFlightRequests["gdb"]`},
+		{"unknown evaluation context", func(c *config.Config) { c.EvaluationContexts["go"] = "console" }, `evaluationContexts["go"] is "console"`},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := valid()
+			tc.modify(cfg)
+			_, err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	// Every problem is listed at once
+	cfg := valid()
+	cfg.MaxSessions = -1
+	cfg.MaxSourceSize = -1
+	if _, err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "maxSessions") || !strings.Contains(err.Error(), "maxSourceSize") {
+		t.Errorf("expected both problems listed, got %v", err)
+	}
+
+	warningCases := []struct {
+		name   string
+		modify func(*config.Config)
+		want   string
+	}{
+		{"readonly with allowModify", func(c *config.Config) { c.Mode = config.ModeReadOnly }, "allowModify"},
+		{"readonly with full evaluation", func(c *config.Config) {
+			c.Mode = config.ModeReadOnly
+			c.AllowModify = false
+			c.Evaluation = config.EvaluationFull
+		}, `evaluation "full"`},
+		{"evaluateReadOnly with evaluation", func(c *config.Config) {
+			c.Evaluation = config.EvaluationNone
+			c.EvaluateReadOnly = true
+		}, "evaluateReadOnly is ignored"},
+		{"no spawn or attach", func(c *config.Config) {
+			c.AllowSpawn = false
+			c.AllowAttach = false
+		}, "no debug session"},
+		{"empty hidden frame", func(c *config.Config) { c.SnapshotHiddenFrames = append(c.SnapshotHiddenFrames, "") }, "snapshotHiddenFrames"},
+		{"missing jsDebugPath", func(c *config.Config) { c.Adapters.Node.JsDebugPath = "" }, "jsDebugPath is not set"},
+		{"unreadable jsDebugPath", func(c *config.Config) { c.Adapters.Node.JsDebugPath = jsDebug + ".missing" }, "can't be read"},
+	}
+	for _, tc := range warningCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := valid()
+			tc.modify(cfg)
+			warnings, err := cfg.Validate()
+			if err != nil {
+				t.Fatalf("expected only a warning, got error %v", err)
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tc.want) {
+				t.Errorf("expected one warning mentioning %q, got %v", tc.want, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-dap"
+
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	"github.com/ctagard/dap-mcp/internal/config"
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	dapmcp "github.com/ctagard/dap-mcp/internal/mcp"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// fakeAdapter is a scripted in-process DAP server. Tests register a handler per
+// request command; requests without a handler get no response. Like a real
+// adapter, it acknowledges disconnect and then closes the connection.
+type fakeAdapter struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	seq      int
+	handlers map[string]func(req dap.RequestMessage) dap.ResponseMessage
+	requests []dap.RequestMessage
+}
+
+// newFakeAdapter starts a fake adapter and returns it with a client connected to it.
+func newFakeAdapter(t *testing.T) (*fakeAdapter, *internaldap.Client) {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+	f := newFakeAdapterConn(serverConn)
+	go f.serve()
+
+	client := internaldap.NewClient(internaldap.NewStdioTransport(clientConn, clientConn))
+	t.Cleanup(func() {
+		// Close the adapter side first so the client's read loop unblocks
+		_ = serverConn.Close()
+		_ = client.Close()
+	})
+
+	return f, client
+}
+
+// newFakeAdapterConn returns a fake adapter for a connection. Register its
+// handlers, then start it with serve.
+func newFakeAdapterConn(conn net.Conn) *fakeAdapter {
+	f := &fakeAdapter{
+		conn:     conn,
+		reader:   bufio.NewReader(conn),
+		handlers: make(map[string]func(req dap.RequestMessage) dap.ResponseMessage),
+	}
+	f.handle("disconnect", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.DisconnectResponse{}
+	})
+	return f
+}
+
+// customRequest is a request for a command go-dap has no typed request for.
+type customRequest struct {
+	dap.Request
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// readRequest reads a DAP message, decoding requests for unknown (custom)
+// commands as *customRequest.
+func readRequest(r *bufio.Reader) (dap.Message, error) {
+	content, err := dap.ReadBaseMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := dap.DecodeProtocolMessage(content)
+	var fieldErr *dap.DecodeProtocolMessageFieldError
+	if stderrors.As(err, &fieldErr) && fieldErr.SubType == "Request" {
+		var req customRequest
+		if err := json.Unmarshal(content, &req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	}
+	return msg, err
+}
+
+// handle registers the response builder for a request command.
+func (f *fakeAdapter) handle(command string, handler func(req dap.RequestMessage) dap.ResponseMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[command] = handler
+}
+
+// received returns the requests received so far with the given command.
+func (f *fakeAdapter) received(command string) []dap.RequestMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reqs []dap.RequestMessage
+	for _, r := range f.requests {
+		if r.GetRequest().Command == command {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// sendEvent sends an event to the client.
+func (f *fakeAdapter) sendEvent(event dap.EventMessage) {
+	f.mu.Lock()
+	f.seq++
+	ev := event.GetEvent()
+	ev.Seq = f.seq
+	ev.Type = "event"
+	f.mu.Unlock()
+
+	f.write(event)
+}
+
+func (f *fakeAdapter) write(msg dap.Message) {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+	_ = dap.WriteProtocolMessage(f.conn, msg)
+}
+
+func (f *fakeAdapter) serve() {
+	for {
+		msg, err := readRequest(f.reader)
+		if err != nil {
+			return
+		}
+		req, ok := msg.(dap.RequestMessage)
+		if !ok {
+			continue
+		}
+
+		f.mu.Lock()
+		f.requests = append(f.requests, req)
+		handler := f.handlers[req.GetRequest().Command]
+		f.mu.Unlock()
+		if handler == nil {
+			continue
+		}
+
+		f.respond(req, handler(req))
+		if req.GetRequest().Command == "disconnect" {
+			_ = f.conn.Close()
+			return
+		}
+	}
+}
+
+// respond sends the response to a request, e.g. one received without a
+// handler that the test answers later.
+func (f *fakeAdapter) respond(req dap.RequestMessage, resp dap.ResponseMessage) {
+	f.mu.Lock()
+	f.seq++
+	r := resp.GetResponse()
+	r.Seq = f.seq
+	r.Type = "response"
+	r.RequestSeq = req.GetSeq()
+	r.Command = req.GetRequest().Command
+	if _, isError := resp.(*dap.ErrorResponse); !isError {
+		r.Success = true
+	}
+	f.mu.Unlock()
+
+	f.write(resp)
+}
+
+// launchableAdapter is an adapters.StdioAdapter whose "spawned" adapter is a
+// fake, so debug_launch can run its full launch sequence in tests.
+type launchableAdapter struct {
+	lang   types.Language
+	client *internaldap.Client
+}
+
+var _ adapters.StdioAdapter = (*launchableAdapter)(nil)
+
+func (a *launchableAdapter) Language() types.Language { return a.lang }
+func (a *launchableAdapter) DefaultTarget() string    { return "" }
+func (a *launchableAdapter) SupportedTargets() []string {
+	return nil
+}
+func (a *launchableAdapter) RequiresSpawn() bool { return false }
+func (a *launchableAdapter) IsStdio() bool       { return true }
+
+func (a *launchableAdapter) Spawn(ctx context.Context, program string, args map[string]interface{}) (string, *exec.Cmd, error) {
+	return "", nil, nil
+}
+
+func (a *launchableAdapter) SpawnStdio(ctx context.Context, program string, args map[string]interface{}) (*internaldap.Client, *exec.Cmd, error) {
+	return a.client, nil, nil
+}
+
+func (a *launchableAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"program": program, "args": args["args"]}
+}
+
+func (a *launchableAdapter) BuildAttachArgs(args map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+// entryAdapter is a launchableAdapter that reports a fixed stopOnEntry behavior
+type entryAdapter struct {
+	*launchableAdapter
+	behavior adapters.EntryBehavior
+}
+
+func (a *entryAdapter) EntryBehavior(args map[string]interface{}) adapters.EntryBehavior {
+	return a.behavior
+}
+
+// entryFunctionAdapter is a launchableAdapter whose stopOnEntry runs on to a
+// function, as Delve's does to main.main
+type entryFunctionAdapter struct {
+	*launchableAdapter
+	function string
+}
+
+func (a *entryFunctionAdapter) EntryFunction(args map[string]interface{}) string {
+	return a.function
+}
+
+// syncAdapter is a launchableAdapter that doesn't require the
+// initialized/configurationDone handshake
+type syncAdapter struct {
+	*launchableAdapter
+}
+
+func (a *syncAdapter) RequiresConfigurationDone() bool {
+	return false
+}
+
+// waitForAdapter is a launchableAdapter that can wait for a process to start,
+// as lldb can
+type waitForAdapter struct {
+	*launchableAdapter
+}
+
+func (a *waitForAdapter) SupportsWaitFor() bool {
+	return true
+}
+
+// nativeArgsAdapter is a launchableAdapter that builds its requests and setup
+// commands like a native debugger adapter
+type nativeArgsAdapter struct {
+	*launchableAdapter
+	native adapters.Adapter
+}
+
+func (a *nativeArgsAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	return a.native.BuildLaunchArgs(program, args)
+}
+
+func (a *nativeArgsAdapter) SetupCommands(args map[string]interface{}) []string {
+	return adapters.SetupCommands(a.native, args)
+}
+
+// newLaunchServer creates an MCP server whose adapter for lang connects
+// debug_launch to the given client, and scripts the fake's launch sequence. Tests may
+// override the scripted handlers afterwards.
+func newLaunchServer(t *testing.T, f *fakeAdapter, client *internaldap.Client, lang types.Language) *dapmcp.Server {
+	t.Helper()
+	return newLaunchServerWithConfig(t, config.DefaultConfig(), f, client, lang)
+}
+
+// newLaunchServerWithConfig is newLaunchServer with a server configuration.
+func newLaunchServerWithConfig(t *testing.T, cfg *config.Config, f *fakeAdapter, client *internaldap.Client, lang types.Language) *dapmcp.Server {
+	t.Helper()
+
+	f.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			f.sendEvent(&dap.InitializedEvent{Event: dap.Event{Event: "initialized"}})
+		}()
+		return &dap.InitializeResponse{Body: dap.Capabilities{SupportsConfigurationDoneRequest: true}}
+	})
+	f.handle("launch", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.LaunchResponse{}
+	})
+	f.handle("configurationDone", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ConfigurationDoneResponse{}
+	})
+
+	srv := dapmcp.NewServer(cfg, nil)
+	t.Cleanup(srv.Close)
+	srv.GetAdapterRegistry().Register(lang, &launchableAdapter{lang: lang, client: client})
+	return srv
+}
+
+// newTestServer creates an MCP server with one session wired to the given client.
+func newTestServer(t *testing.T, client *internaldap.Client, lang types.Language) (*dapmcp.Server, string) {
+	t.Helper()
+	return newTestServerWithConfig(t, config.DefaultConfig(), client, lang)
+}
+
+// newTestServerWithConfig is newTestServer with a server configuration.
+func newTestServerWithConfig(t *testing.T, cfg *config.Config, client *internaldap.Client, lang types.Language) (*dapmcp.Server, string) {
+	t.Helper()
+
+	srv := dapmcp.NewServer(cfg, nil)
+	t.Cleanup(srv.Close)
+
+	sm := srv.GetSessionManager()
+	session, err := sm.CreateSession(lang, "/path/to/program")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	_ = sm.SetSessionClient(session.ID, client)
+	_ = sm.UpdateSessionStatus(session.ID, types.SessionStatusStopped)
+
+	return srv, session.ID
+}
+
+// callTool invokes an MCP tool and returns its text content and error flag.
+func callTool(t *testing.T, srv *dapmcp.Server, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      name,
+			"arguments": args,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp := srv.GetMCPServer().HandleMessage(ctx, raw)
+	body, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+
+	var decoded struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error interface{} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if decoded.Error != nil {
+		t.Fatalf("tool %s returned protocol error: %v", name, decoded.Error)
+	}
+	if len(decoded.Result.Content) == 0 {
+		t.Fatalf("tool %s returned no content", name)
+	}
+
+	return decoded.Result.Content[0].Text, decoded.Result.IsError
+}
+
+// readResource reads an MCP resource and returns its text content, or the
+// protocol error message if the read failed.
+func readResource(t *testing.T, srv *dapmcp.Server, uri string) (string, bool) {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "resources/read",
+		"params":  map[string]interface{}{"uri": uri},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp := srv.GetMCPServer().HandleMessage(ctx, raw)
+	body, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+
+	var decoded struct {
+		Result struct {
+			Contents []struct {
+				URI      string `json:"uri"`
+				MIMEType string `json:"mimeType"`
+				Text     string `json:"text"`
+			} `json:"contents"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if decoded.Error != nil {
+		return decoded.Error.Message, true
+	}
+	if len(decoded.Result.Contents) == 0 {
+		t.Fatalf("resource %s returned no contents", uri)
+	}
+	if decoded.Result.Contents[0].MIMEType != "application/json" {
+		t.Errorf("expected resource %s to be application/json, got %q", uri, decoded.Result.Contents[0].MIMEType)
+	}
+
+	return decoded.Result.Contents[0].Text, false
+}
+
+// decodeResult unmarshals a tool's JSON text result.
+func decodeResult(t *testing.T, text string) map[string]interface{} {
+	t.Helper()
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("failed to decode tool result %q: %v", text, err)
+	}
+	return result
+}
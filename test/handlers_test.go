@@ -0,0 +1,5202 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-dap"
+
+	"github.com/ctagard/dap-mcp/internal/adapters"
+	"github.com/ctagard/dap-mcp/internal/config"
+	internaldap "github.com/ctagard/dap-mcp/internal/dap"
+	dapmcp "github.com/ctagard/dap-mcp/internal/mcp"
+	"github.com/ctagard/dap-mcp/pkg/types"
+)
+
+// scriptStoppedProgram registers responses for a single stopped thread with one
+// frame and a Locals scope whose variables come from vars.
+func scriptStoppedProgram(f *fakeAdapter, line *int32, vars func() []dap.Variable) {
+	f.handle("threads", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ThreadsResponse{Body: dap.ThreadsResponseBody{
+			Threads: []dap.Thread{{Id: 1, Name: "main"}},
+		}}
+	})
+	f.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+			StackFrames: []dap.StackFrame{{Id: 1000, Name: "main.loop", Line: int(atomic.LoadInt32(line))}},
+			TotalFrames: 1,
+		}}
+	})
+	f.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ScopesResponse{Body: dap.ScopesResponseBody{
+			Scopes: []dap.Scope{{Name: "Locals", VariablesReference: 2000}},
+		}}
+	})
+	f.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: vars()}}
+	})
+}
+
+// TestDebugSnapshot_Delta verifies that delta snapshots only report changed state.
+func TestDebugSnapshot_Delta(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	var i int32
+	line := int32(10)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable {
+		return []dap.Variable{
+			{Name: "i", Value: string(rune('0' + atomic.LoadInt32(&i))), Type: "int"},
+			{Name: "total", Value: "42", Type: "int"},
+		}
+	})
+
+	// First delta snapshot has no baseline, so everything is returned
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId": sessionID,
+		"delta":     true,
+	})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	first := decodeResult(t, text)
+	summary := first["delta"].(map[string]interface{})
+	if summary["baseline"] != true {
+		t.Errorf("expected first delta snapshot to be a baseline, got %v", summary)
+	}
+	if vars := first["variables"].(map[string]interface{})["2000"].([]interface{}); len(vars) != 2 {
+		t.Errorf("expected 2 variables in baseline, got %d", len(vars))
+	}
+
+	// Only i changes; the stack shape stays the same
+	atomic.StoreInt32(&i, 1)
+	text, isErr = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId": sessionID,
+		"delta":     true,
+	})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	second := decodeResult(t, text)
+	summary = second["delta"].(map[string]interface{})
+	if summary["baseline"] != false {
+		t.Errorf("expected delta against previous snapshot, got %v", summary)
+	}
+	if summary["unchangedVariables"] != float64(1) {
+		t.Errorf("expected 1 unchanged variable, got %v", summary["unchangedVariables"])
+	}
+	// The changed local's thread and stack come with it, so its scope can be
+	// tied to a frame, although the stack itself is unchanged
+	if summary["unchangedThreads"] != float64(0) || summary["unchangedStacks"] != float64(1) {
+		t.Errorf("expected the thread reported with an unchanged stack, got %v", summary)
+	}
+	if threads := second["threads"].([]interface{}); len(threads) != 1 {
+		t.Errorf("expected the thread with the changed local in delta, got %v", threads)
+	}
+	if stacks := second["stacks"].(map[string]interface{}); len(stacks) != 1 {
+		t.Errorf("expected the stack of the changed local in delta, got %v", stacks)
+	}
+	if scopes := second["scopes"].(map[string]interface{}); len(scopes) != 1 {
+		t.Errorf("expected the changed local's scope in delta, got %v", scopes)
+	}
+	vars := second["variables"].(map[string]interface{})["2000"].([]interface{})
+	if len(vars) != 1 || vars[0].(map[string]interface{})["name"] != "i" {
+		t.Errorf("expected only i in delta, got %v", vars)
+	}
+
+	// Nothing changes, so the thread is left out
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId": sessionID,
+		"delta":     true,
+	})
+	unchanged := decodeResult(t, text)
+	if n := unchanged["delta"].(map[string]interface{})["unchangedThreads"]; n != float64(1) {
+		t.Errorf("expected 1 unchanged thread, got %v", n)
+	}
+	if stacks := unchanged["stacks"].(map[string]interface{}); len(stacks) != 0 {
+		t.Errorf("expected no unchanged stacks in delta, got %v", stacks)
+	}
+
+	// A new line changes the stack, so the thread is reported again
+	atomic.StoreInt32(&line, 11)
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId": sessionID,
+		"delta":     true,
+	})
+	third := decodeResult(t, text)
+	if stacks := third["stacks"].(map[string]interface{}); len(stacks) != 1 {
+		t.Errorf("expected changed stack in delta, got %v", stacks)
+	}
+	if vars, ok := third["variables"].(map[string]interface{})["2000"]; ok {
+		t.Errorf("expected no changed variables, got %v", vars)
+	}
+}
+
+// TestSessionResources verifies that sessions and their snapshots can be read
+// as resources, and that reading a snapshot leaves the delta baseline alone.
+func TestSessionResources(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	line := int32(10)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable {
+		return []dap.Variable{{Name: "total", Value: "42", Type: "int"}}
+	})
+
+	text, isErr := readResource(t, srv, "dap://sessions")
+	if isErr {
+		t.Fatalf("reading sessions failed: %s", text)
+	}
+	if !strings.Contains(text, sessionID) {
+		t.Errorf("expected session %s in the sessions resource, got %s", sessionID, text)
+	}
+
+	text, isErr = readResource(t, srv, "dap://sessions/"+sessionID+"/snapshot")
+	if isErr {
+		t.Fatalf("reading snapshot failed: %s", text)
+	}
+	snapshot := decodeResult(t, text)
+	if vars := snapshot["variables"].(map[string]interface{})["2000"].([]interface{}); len(vars) != 1 {
+		t.Errorf("expected 1 variable in the snapshot resource, got %v", vars)
+	}
+	if _, ok := snapshot["snapshotId"]; ok {
+		t.Errorf("expected a resource read not to record a snapshot, got snapshotId %v", snapshot["snapshotId"])
+	}
+
+	// The resource read isn't a baseline for delta snapshots
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId": sessionID,
+		"delta":     true,
+	})
+	summary := decodeResult(t, text)["delta"].(map[string]interface{})
+	if summary["baseline"] != true {
+		t.Errorf("expected the first delta snapshot to be a baseline, got %v", summary)
+	}
+
+	if text, isErr := readResource(t, srv, "dap://sessions/nope/snapshot"); !isErr {
+		t.Errorf("expected reading an unknown session's snapshot to fail, got %s", text)
+	}
+}
+
+// TestPrompts verifies the debugging workflow prompts are listed and render
+// their arguments into tool guidance.
+func TestPrompts(t *testing.T) {
+	srv := dapmcp.NewServer(config.DefaultConfig(), nil)
+	t.Cleanup(srv.Close)
+
+	handle := func(message string) string {
+		resp := srv.GetMCPServer().HandleMessage(context.Background(), []byte(message))
+		body, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	list := handle(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)
+	for _, name := range []string{"diagnose_crash", "find_variable_change", "trace_exception"} {
+		if !strings.Contains(list, name) {
+			t.Errorf("expected prompt %s in %s", name, list)
+		}
+	}
+
+	var got struct {
+		Result struct {
+			Messages []struct {
+				Content struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"messages"`
+		} `json:"result"`
+	}
+	body := handle(`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"find_variable_change","arguments":{"sessionId":"s1","variable":"total","file":"main.go"}}}`)
+	if err := json.Unmarshal([]byte(body), &got); err != nil || len(got.Result.Messages) != 1 {
+		t.Fatalf("expected one prompt message, got %s", body)
+	}
+	text := got.Result.Messages[0].Content.Text
+	for _, want := range []string{`"s1"`, `"total"`, "main.go", "debug_break_when", "debug_diff"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %s in the prompt, got %s", want, text)
+		}
+	}
+
+	body = handle(`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"find_variable_change","arguments":{"sessionId":"s1"}}}`)
+	if !strings.Contains(body, `"error"`) || !strings.Contains(body, "variable") {
+		t.Errorf("expected an error without the required variable, got %s", body)
+	}
+}
+
+// scriptThreads registers a threads response with the given thread IDs.
+func scriptThreads(f *fakeAdapter, ids ...int) {
+	f.handle("threads", func(req dap.RequestMessage) dap.ResponseMessage {
+		threads := make([]dap.Thread, len(ids))
+		for i, id := range ids {
+			threads[i] = dap.Thread{Id: id, Name: fmt.Sprintf("thread-%d", id)}
+		}
+		return &dap.ThreadsResponse{Body: dap.ThreadsResponseBody{Threads: threads}}
+	})
+}
+
+// TestDebugContinue_ContinueAll verifies continueAll resumes every thread on
+// adapters that only resume the requested thread.
+func TestDebugContinue_ContinueAll(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+
+	scriptThreads(fake, 1, 2, 3)
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ContinueResponse{Body: dap.ContinueResponseBody{AllThreadsContinued: false}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_continue", map[string]interface{}{
+		"sessionId":   sessionID,
+		"continueAll": true,
+	})
+	if isErr {
+		t.Fatalf("continue failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["allThreadsContinued"] != true {
+		t.Errorf("expected allThreadsContinued, got %v", result)
+	}
+
+	reqs := fake.received("continue")
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 continue requests, got %d", len(reqs))
+	}
+	for _, r := range reqs {
+		if r.(*dap.ContinueRequest).Arguments.SingleThread {
+			t.Error("expected singleThread to be false for continueAll")
+		}
+	}
+}
+
+// TestDebugContinue_SingleThreadUnsupported verifies singleThread honors adapter capabilities.
+func TestDebugContinue_SingleThreadUnsupported(t *testing.T) {
+	_, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+
+	text, isErr := callTool(t, srv, "debug_continue", map[string]interface{}{
+		"sessionId":    sessionID,
+		"threadId":     1,
+		"singleThread": true,
+	})
+	if !isErr {
+		t.Fatalf("expected error when adapter lacks single-thread support, got %s", text)
+	}
+}
+
+// TestDebugPause_PauseAll verifies pauseAll relies on the all-stop stopped event when available.
+func TestDebugPause_PauseAll(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+
+	scriptThreads(fake, 1, 2)
+	fake.handle("pause", func(req dap.RequestMessage) dap.ResponseMessage {
+		go fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body: dap.StoppedEventBody{
+				Reason:            "pause",
+				ThreadId:          req.(*dap.PauseRequest).Arguments.ThreadId,
+				AllThreadsStopped: true,
+			},
+		})
+		return &dap.PauseResponse{}
+	})
+
+	text, isErr := callTool(t, srv, "debug_pause", map[string]interface{}{
+		"sessionId": sessionID,
+		"pauseAll":  true,
+	})
+	if isErr {
+		t.Fatalf("pause failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["allThreadsStopped"] != true {
+		t.Errorf("expected allThreadsStopped, got %v", result)
+	}
+	if reqs := fake.received("pause"); len(reqs) != 1 {
+		t.Errorf("expected a single pause request on an all-stop adapter, got %d", len(reqs))
+	}
+}
+
+// TestDebugEvaluate_PagesLargeResults verifies evaluate reports child counts and
+// pages indexed children via the returned variablesReference.
+func TestDebugEvaluate_PagesLargeResults(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{
+			Result:             "[]int len: 1000000",
+			Type:               "[]int",
+			VariablesReference: 12,
+			IndexedVariables:   1000000,
+		}}
+	})
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.VariablesRequest).Arguments
+		n := args.Count
+		if args.Start+n > 1000000 {
+			n = 1000000 - args.Start
+		}
+		vars := make([]dap.Variable, n)
+		for i := range vars {
+			vars[i] = dap.Variable{Name: fmt.Sprintf("[%d]", args.Start+i), Value: "0", Type: "int"}
+		}
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: vars}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":  sessionID,
+		"expression": "samples",
+	})
+	if isErr {
+		t.Fatalf("evaluate failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["indexedVariables"] != float64(1000000) {
+		t.Errorf("expected indexedVariables 1000000, got %v", result["indexedVariables"])
+	}
+
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 12,
+		"start":              500,
+		"count":              20,
+	})
+	if isErr {
+		t.Fatalf("paging failed: %s", text)
+	}
+	page := decodeResult(t, text)
+	vars := page["variables"].([]interface{})
+	if len(vars) != 20 {
+		t.Fatalf("expected 20 variables, got %d", len(vars))
+	}
+	if name := vars[0].(map[string]interface{})["name"]; name != "[500]" {
+		t.Errorf("expected first element [500], got %v", name)
+	}
+	if page["nextStart"] != float64(520) {
+		t.Errorf("expected nextStart 520, got %v", page["nextStart"])
+	}
+	// The total comes from the evaluation that returned the reference
+	if page["indexedVariables"] != float64(1000000) || page["count"] != float64(20) || page["hasMore"] != true {
+		t.Errorf("expected indexedVariables 1000000, count 20 and hasMore, got %v", page)
+	}
+
+	reqs := fake.received("variables")
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 variables request, got %d", len(reqs))
+	}
+	args := reqs[0].(*dap.VariablesRequest).Arguments
+	if args.Filter != "indexed" || args.Start != 500 || args.Count != 20 {
+		t.Errorf("expected indexed filter with start 500 count 20, got %+v", args)
+	}
+
+	// Page sizes are capped so a collection is never dumped at once
+	text, _ = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 12,
+		"count":              1000000,
+	})
+	if vars := decodeResult(t, text)["variables"].([]interface{}); len(vars) != 1000 {
+		t.Errorf("expected page capped at 1000, got %d", len(vars))
+	}
+
+	// A full last page doesn't claim more pages
+	text, _ = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 12,
+		"start":              999980,
+		"count":              20,
+	})
+	page = decodeResult(t, text)
+	if page["hasMore"] != false || page["nextStart"] != nil {
+		t.Errorf("expected the last page to have no more, got hasMore %v nextStart %v", page["hasMore"], page["nextStart"])
+	}
+}
+
+// TestDebugEvaluate_ReadOnly verifies evaluateReadOnly: adapters that can avoid
+// side effects are asked to, and other adapters' expressions are checked.
+func TestDebugEvaluate_ReadOnly(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Mode = config.ModeReadOnly
+	cfg.AllowExecute = false
+	cfg.EvaluateReadOnly = true
+
+	evaluate := func(fake *fakeAdapter) {
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "1", Type: "int"}}
+		})
+	}
+
+	// debugpy can't prevent side effects, so expressions are checked
+	fake, client := newFakeAdapter(t)
+	evaluate(fake)
+	srv, sessionID := newTestServerWithConfig(t, cfg, client, types.LanguagePython)
+
+	for _, expr := range []string{`items[0] + 1`, `a == "f(x)"`, `not (a or b)`, `x >= 2 and y != 3`} {
+		text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": expr})
+		if isErr {
+			t.Errorf("expected %s to be evaluated, got %s", expr, text)
+			continue
+		}
+		if mode := decodeResult(t, text)["evaluateMode"]; mode != "readOnlyChecked" {
+			t.Errorf("expected evaluateMode readOnlyChecked for %s, got %v", expr, mode)
+		}
+	}
+	for _, expr := range []string{`len(items)`, `x = 1`, `total += 1`, `obj.items[0].pop()`, `(y := 2)`} {
+		if text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": expr}); !isErr {
+			t.Errorf("expected %s to be rejected, got %s", expr, text)
+		}
+	}
+	if got := len(fake.received("evaluate")); got != 4 {
+		t.Errorf("expected only the 4 read-only expressions to reach the adapter, got %d", got)
+	}
+
+	// Batch mode reports rejected expressions individually
+	text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId": sessionID, "expressions": `["x", "f(x)"]`, "frameId": 1,
+	})
+	if isErr {
+		t.Fatalf("batch evaluate failed: %s", text)
+	}
+	batch := decodeResult(t, text)
+	evaluations := batch["evaluations"].([]interface{})
+	if evaluations[0].(map[string]interface{})["error"] != nil || evaluations[1].(map[string]interface{})["error"] == nil {
+		t.Errorf("expected only f(x) to be rejected, got %v", evaluations)
+	}
+	if batch["evaluateMode"] != "readOnlyChecked" {
+		t.Errorf("expected evaluateMode readOnlyChecked, got %v", batch["evaluateMode"])
+	}
+
+	// js-debug evaluates in the hover context, where it throws on side effects
+	jsFake, jsClient := newFakeAdapter(t)
+	evaluate(jsFake)
+	jsSrv, jsSessionID := newTestServerWithConfig(t, cfg, jsClient, types.LanguageJavaScript)
+	text, isErr = callTool(t, jsSrv, "debug_evaluate", map[string]interface{}{"sessionId": jsSessionID, "expression": "items.slice(1)"})
+	if isErr {
+		t.Fatalf("evaluate failed: %s", text)
+	}
+	if mode := decodeResult(t, text)["evaluateMode"]; mode != "readOnly" {
+		t.Errorf("expected evaluateMode readOnly, got %v", mode)
+	}
+	if reqs := jsFake.received("evaluate"); len(reqs) != 1 || reqs[0].(*dap.EvaluateRequest).Arguments.Context != "hover" {
+		t.Errorf("expected one evaluate request in the hover context, got %v", reqs)
+	}
+}
+
+// TestDebugEvaluate_DefaultContext verifies expressions are evaluated in the
+// language's default context unless a context is passed.
+func TestDebugEvaluate_DefaultContext(t *testing.T) {
+	contexts := func(fake *fakeAdapter) []string {
+		var got []string
+		for _, req := range fake.received("evaluate") {
+			got = append(got, req.(*dap.EvaluateRequest).Arguments.Context)
+		}
+		return got
+	}
+	evaluate := func(srv *dapmcp.Server, args map[string]interface{}) {
+		t.Helper()
+		args["frameId"] = 1
+		if text, isErr := callTool(t, srv, "debug_evaluate", args); isErr {
+			t.Fatalf("evaluate failed: %s", text)
+		}
+	}
+	newServer := func(cfg *config.Config, lang types.Language) (*fakeAdapter, *dapmcp.Server, string) {
+		fake, client := newFakeAdapter(t)
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "1"}}
+		})
+		srv, sessionID := newTestServerWithConfig(t, cfg, client, lang)
+		return fake, srv, sessionID
+	}
+
+	// Python runs statements in the repl context; an explicit context wins
+	fake, srv, sessionID := newServer(config.DefaultConfig(), types.LanguagePython)
+	evaluate(srv, map[string]interface{}{"sessionId": sessionID, "expression": "import os"})
+	evaluate(srv, map[string]interface{}{"sessionId": sessionID, "expressions": `["x"]`})
+	evaluate(srv, map[string]interface{}{"sessionId": sessionID, "expression": "x", "context": "watch"})
+	if got := contexts(fake); strings.Join(got, ",") != "repl,repl,watch" {
+		t.Errorf("expected repl, repl, then the explicit watch, got %v", got)
+	}
+
+	// Go keeps watch, and the setting overrides per language
+	cfg := config.DefaultConfig()
+	fake, srv, sessionID = newServer(cfg, types.LanguageGo)
+	evaluate(srv, map[string]interface{}{"sessionId": sessionID, "expression": "x"})
+	cfg.EvaluationContexts["go"] = "hover"
+	evaluate(srv, map[string]interface{}{"sessionId": sessionID, "expression": "x"})
+	if got := contexts(fake); strings.Join(got, ",") != "watch,hover" {
+		t.Errorf("expected watch, then the configured hover, got %v", got)
+	}
+
+	// Read-only evaluation refuses repl, so the default falls back to watch
+	cfg = config.DefaultConfig()
+	cfg.Evaluation = config.EvaluationReadOnly
+	fake, srv, sessionID = newServer(cfg, types.LanguagePython)
+	evaluate(srv, map[string]interface{}{"sessionId": sessionID, "expression": "x"})
+	if got := contexts(fake); strings.Join(got, ",") != "watch" {
+		t.Errorf("expected watch under read-only evaluation, got %v", got)
+	}
+}
+
+// TestDebugEvaluate_ErrorResults verifies results that are error messages are
+// reported as failed evaluations, unless rawResult is set.
+func TestDebugEvaluate_ErrorResults(t *testing.T) {
+	results := map[string]dap.EvaluateResponseBody{
+		"missing": {Result: "NameError: name 'missing' is not defined"},
+		"tb":      {Result: "Traceback (most recent call last):\n  File \"<string>\", line 1"},
+		"marked":  {Result: "could not read memory", PresentationHint: &dap.VariablePresentationHint{Attributes: []string{"failedEvaluation"}}},
+		"msg":     {Result: "'ValueError: bad input'", Type: "str"},
+		"exc":     {Result: "ValueError: bad input", Type: "ValueError", VariablesReference: 7},
+	}
+	fake, client := newFakeAdapter(t)
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: results[req.(*dap.EvaluateRequest).Arguments.Expression]}
+	})
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	for _, expr := range []string{"missing", "tb", "marked"} {
+		text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": expr, "frameId": 1})
+		if !isErr || !strings.Contains(text, "returned an error as its result") || !strings.Contains(text, "rawResult") {
+			t.Errorf("expected %s to fail with the adapter's error, got %s", expr, text)
+		}
+	}
+	// A string that mentions an error, and an exception object, are values
+	for _, expr := range []string{"msg", "exc"} {
+		if text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": expr, "frameId": 1}); isErr {
+			t.Errorf("expected %s to be a value, got %s", expr, text)
+		}
+	}
+
+	// rawResult returns the error text as the value
+	text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": "missing", "frameId": 1, "rawResult": true})
+	if isErr || decodeResult(t, text)["result"] != results["missing"].Result {
+		t.Errorf("expected the raw result, got %s", text)
+	}
+
+	// Batch mode reports the error for that expression only
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expressions": `["missing", "msg"]`, "frameId": 1})
+	if isErr {
+		t.Fatalf("batch evaluate failed: %s", text)
+	}
+	evaluations := decodeResult(t, text)["evaluations"].([]interface{})
+	if evaluations[0].(map[string]interface{})["error"] == nil || evaluations[1].(map[string]interface{})["error"] != nil {
+		t.Errorf("expected only the NameError to be reported as an error, got %v", evaluations)
+	}
+
+	// Error formats are per adapter: Go values aren't checked against debugpy's
+	goFake, goClient := newFakeAdapter(t)
+	goFake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "NameError: x"}}
+	})
+	goSrv, goSessionID := newTestServer(t, goClient, types.LanguageGo)
+	if text, isErr := callTool(t, goSrv, "debug_evaluate", map[string]interface{}{"sessionId": goSessionID, "expression": "s", "frameId": 1}); isErr {
+		t.Errorf("expected a Go value to be returned, got %s", text)
+	}
+}
+
+// TestDebugEvaluate_ReadOnlyMode verifies readonly mode evaluates read-only in
+// the watch and hover contexts, refuses the repl, and can disable evaluation.
+func TestDebugEvaluate_ReadOnlyMode(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Mode = config.ModeReadOnly
+
+	fake, client := newFakeAdapter(t)
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "3", Type: "int"}}
+	})
+	srv, sessionID := newTestServerWithConfig(t, cfg, client, types.LanguagePython)
+
+	for _, context := range []string{"watch", "hover"} {
+		text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+			"sessionId": sessionID, "expression": "len_items + 1", "context": context,
+		})
+		if isErr {
+			t.Fatalf("expected evaluation in the %s context, got %s", context, text)
+		}
+		if mode := decodeResult(t, text)["evaluateMode"]; mode != "readOnlyChecked" {
+			t.Errorf("expected evaluateMode readOnlyChecked, got %v", mode)
+		}
+	}
+	text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId": sessionID, "expression": "x", "context": "repl",
+	})
+	if !isErr || !strings.Contains(text, "watch") {
+		t.Errorf("expected the repl context to be refused with a hint, got %s", text)
+	}
+	if text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId": sessionID, "expression": "items.pop()",
+	}); !isErr {
+		t.Errorf("expected a side effect to be rejected, got %s", text)
+	}
+	if got := len(fake.received("evaluate")); got != 2 {
+		t.Errorf("expected only the watch and hover evaluations to reach the adapter, got %d", got)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.Mode = config.ModeReadOnly
+	cfg.Evaluation = config.EvaluationNone
+	_, client = newFakeAdapter(t)
+	srv, sessionID = newTestServerWithConfig(t, cfg, client, types.LanguagePython)
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": "x"})
+	if !isErr || !strings.Contains(text, "readOnly") {
+		t.Errorf("expected evaluation to be disabled with a hint, got %s", text)
+	}
+}
+
+// TestDebugListConfigs verifies launch.json discovery and validation warnings.
+func TestDebugListConfigs(t *testing.T) {
+	_, client := newFakeAdapter(t)
+	srv, _ := newTestServer(t, client, types.LanguageGo)
+
+	workspace := t.TempDir()
+	vscodeDir := filepath.Join(workspace, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatalf("failed to create .vscode: %v", err)
+	}
+	launchJSON := `{
+		"version": "0.2.0",
+		"configurations": [
+			{"type": "go", "request": "launch", "name": "Server", "program": "${input:pkg}"}
+		],
+		"compounds": [
+			{"name": "All", "configurations": ["Server", "Client"]}
+		]
+	}`
+	if err := os.WriteFile(filepath.Join(vscodeDir, "launch.json"), []byte(launchJSON), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+
+	text, isErr := callTool(t, srv, "debug_list_configs", map[string]interface{}{
+		"workspace": workspace,
+	})
+	if isErr {
+		t.Fatalf("list configs failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if configs := result["configurations"].([]interface{}); len(configs) != 1 {
+		t.Errorf("expected 1 configuration, got %v", configs)
+	}
+	if compounds := result["compounds"].([]interface{}); len(compounds) != 1 {
+		t.Errorf("expected 1 compound, got %v", compounds)
+	}
+	warnings, ok := result["validationWarnings"].([]interface{})
+	if !ok || len(warnings) != 2 {
+		t.Errorf("expected unknown compound member and undefined input warnings, got %v", result["validationWarnings"])
+	}
+}
+
+// TestDebugCapabilities verifies the adapter's initialize capabilities are returned.
+func TestDebugCapabilities(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.InitializeResponse{Body: dap.Capabilities{
+			SupportsConditionalBreakpoints: true,
+			SupportsSetVariable:            true,
+			ExceptionBreakpointFilters: []dap.ExceptionBreakpointsFilter{
+				{Filter: "panic", Label: "Panics"},
+			},
+		}}
+	})
+	if _, err := client.Initialize("test", "test"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	text, isErr := callTool(t, srv, "debug_capabilities", map[string]interface{}{
+		"sessionId": sessionID,
+	})
+	if isErr {
+		t.Fatalf("capabilities failed: %s", text)
+	}
+	caps := decodeResult(t, text)["capabilities"].(map[string]interface{})
+	if caps["supportsConditionalBreakpoints"] != true || caps["supportsSetVariable"] != true {
+		t.Errorf("expected conditional breakpoints and set variable support, got %v", caps)
+	}
+	if _, ok := caps["supportsDisassembleRequest"]; ok {
+		t.Errorf("expected unsupported capabilities to be omitted, got %v", caps)
+	}
+	if filters := caps["exceptionBreakpointFilters"].([]interface{}); len(filters) != 1 {
+		t.Errorf("expected 1 exception filter, got %v", filters)
+	}
+}
+
+// TestDebugInspectTree verifies values are rendered as an indented text tree
+// with markers where the depth or child limits cut them short.
+func TestDebugInspectTree(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	line := int32(1)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{
+			Result: "User(name='Ann')", Type: "User", VariablesReference: 10,
+		}}
+	})
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.VariablesRequest).Arguments
+		var vars []dap.Variable
+		switch args.VariablesReference {
+		case 10:
+			vars = []dap.Variable{
+				{Name: "name", Value: "'Ann'", Type: "str"},
+				{Name: "address", Value: "Address(...)", Type: "Address", VariablesReference: 11},
+				{Name: "tags", Value: "[...]", Type: "list", VariablesReference: 12, IndexedVariables: 30},
+			}
+		case 11:
+			vars = []dap.Variable{{Name: "city", Value: "'Oslo'", Type: "str", VariablesReference: 13}}
+		case 12:
+			for i := 0; i < args.Count; i++ {
+				vars = append(vars, dap.Variable{Name: fmt.Sprintf("[%d]", i), Value: fmt.Sprintf("'t%d'", i), Type: "str"})
+			}
+		}
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: vars}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_inspect_tree", map[string]interface{}{
+		"sessionId":   sessionID,
+		"expression":  "user",
+		"maxDepth":    2,
+		"maxChildren": 3,
+	})
+	if isErr {
+		t.Fatalf("inspect tree failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	want := strings.Join([]string{
+		"user: User(name='Ann') (User)",
+		"  name: 'Ann' (str)",
+		"  address: Address(...) (Address)",
+		"    city: 'Oslo' (str) [ref 13] ...",
+		"  tags: [...] (list)",
+		"    [0]: 't0' (str)",
+		"    [1]: 't1' (str)",
+		"    [2]: 't2' (str)",
+		"    ... 27 more [ref 12]",
+	}, "\n")
+	if result["tree"] != want {
+		t.Errorf("unexpected tree:\n%v\nwant:\n%s", result["tree"], want)
+	}
+	if result["truncated"] != true || result["nodes"] != float64(8) {
+		t.Errorf("expected 8 nodes and truncated, got %v and %v", result["nodes"], result["truncated"])
+	}
+	if reqs := fake.received("variables"); len(reqs) != 3 {
+		t.Errorf("expected 3 variables requests, got %d", len(reqs))
+	}
+}
+
+func TestDebugReadVariableBytes(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	if text, isErr := callTool(t, srv, "debug_read_variable_bytes", map[string]interface{}{"sessionId": sessionID, "expression": "buf"}); !isErr || !strings.Contains(text, "reading memory") {
+		t.Errorf("expected an error without the readMemory capability, got %s", text)
+	}
+
+	fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.InitializeResponse{Body: dap.Capabilities{SupportsReadMemoryRequest: true}}
+	})
+	if _, err := client.Initialize("test", "test"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		switch req.(*dap.EvaluateRequest).Arguments.Expression {
+		case "buf":
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "[]uint8 len: 11", Type: "[]uint8", MemoryReference: "0x1000", IndexedVariables: 11}}
+		case "raw":
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "[]uint8 len: 3", Type: "[]uint8", MemoryReference: "0x2000", IndexedVariables: 3}}
+		default:
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "1", Type: "int"}}
+		}
+	})
+	memory := map[string][]byte{"0x1000": []byte("hello world"), "0x2000": {0xff, 0x00, 0xfe}}
+	fake.handle("readMemory", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.ReadMemoryRequest).Arguments
+		data := memory[args.MemoryReference]
+		end := min(args.Offset+args.Count, len(data))
+		return &dap.ReadMemoryResponse{Body: dap.ReadMemoryResponseBody{
+			Address: args.MemoryReference,
+			Data:    base64.StdEncoding.EncodeToString(data[args.Offset:end]),
+		}}
+	})
+
+	// The length defaults to the value's element count
+	text, isErr := callTool(t, srv, "debug_read_variable_bytes", map[string]interface{}{"sessionId": sessionID, "expression": "buf", "frameId": 1000})
+	if isErr {
+		t.Fatalf("debug_read_variable_bytes failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["data"] != "hello world" || result["encoding"] != "utf-8" || result["bytesRead"] != float64(11) {
+		t.Errorf("expected the full text, got %v", result)
+	}
+	reads := fake.received("readMemory")
+	if args := reads[len(reads)-1].(*dap.ReadMemoryRequest).Arguments; args.Count != 11 || args.Offset != 0 {
+		t.Errorf("expected 11 bytes read from offset 0, got %+v", args)
+	}
+
+	text, _ = callTool(t, srv, "debug_read_variable_bytes", map[string]interface{}{"sessionId": sessionID, "expression": "buf", "frameId": 1000, "offset": 6})
+	if result := decodeResult(t, text); result["data"] != "world" {
+		t.Errorf("expected the bytes after offset 6, got %v", result)
+	}
+
+	// Binary data stays base64
+	text, _ = callTool(t, srv, "debug_read_variable_bytes", map[string]interface{}{"sessionId": sessionID, "expression": "raw", "frameId": 1000})
+	if result := decodeResult(t, text); result["encoding"] != "base64" || result["data"] != base64.StdEncoding.EncodeToString(memory["0x2000"]) {
+		t.Errorf("expected base64 data, got %v", result)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_read_variable_bytes", map[string]interface{}{"sessionId": sessionID, "expression": "n", "frameId": 1000}); !isErr || !strings.Contains(text, "memoryReference") {
+		t.Errorf("expected an error for a value without a memoryReference, got %s", text)
+	}
+}
+
+// TestDebugGetFullValue verifies that a value comes back in full as plain text:
+// a string evaluated in the clipboard context and unquoted, a collection read
+// page by page one element per line, and a value the adapter cut short read
+// from memory.
+func TestDebugGetFullValue(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.InitializeResponse{Body: dap.Capabilities{SupportsClipboardContext: true, SupportsReadMemoryRequest: true}}
+	})
+	if _, err := client.Initialize("test", "test"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.EvaluateRequest).Arguments
+		if args.Context != "clipboard" {
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "'Traceback...'"}}
+		}
+		switch args.Expression {
+		case "traceback.format_exc()":
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{
+				Result: `'Traceback (most recent call last):\n  File "app.py", line 3, in <module>\nValueError: it\'s "bad"\n'`,
+			}}
+		case "lines":
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "[...]", VariablesReference: 7, IndexedVariables: 1500}}
+		default:
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: `"hello...+6 more"`, MemoryReference: "0x1000", IndexedVariables: 11}}
+		}
+	})
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.VariablesRequest).Arguments
+		var children []dap.Variable
+		for i := args.Start; i < args.Start+args.Count; i++ {
+			children = append(children, dap.Variable{Name: strconv.Itoa(i), Value: fmt.Sprintf("'line %d\\n'", i)})
+		}
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: children}}
+	})
+	fake.handle("readMemory", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ReadMemoryResponse{Body: dap.ReadMemoryResponseBody{Data: base64.StdEncoding.EncodeToString([]byte("hello world"))}}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		text, isErr := callTool(t, srv, "debug_get_full_value", map[string]interface{}{"sessionId": sessionID, "expression": "traceback.format_exc()", "frameId": 1000})
+		if isErr {
+			t.Fatalf("debug_get_full_value failed: %s", text)
+		}
+		want := "Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\nValueError: it's \"bad\"\n"
+		if text != want {
+			t.Errorf("expected the unquoted traceback %q, got %q", want, text)
+		}
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		text, isErr := callTool(t, srv, "debug_get_full_value", map[string]interface{}{"sessionId": sessionID, "expression": "lines", "frameId": 1000})
+		if isErr {
+			t.Fatalf("debug_get_full_value failed: %s", text)
+		}
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+		if len(lines) != 1500 || lines[0] != "line 0" || lines[1499] != "line 1499" {
+			t.Errorf("expected 1500 lines from line 0 to line 1499, got %d: %q ... %q", len(lines), lines[0], lines[len(lines)-1])
+		}
+		if pages := len(fake.received("variables")); pages != 2 {
+			t.Errorf("expected the elements in 2 pages, got %d requests", pages)
+		}
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		text, isErr := callTool(t, srv, "debug_get_full_value", map[string]interface{}{"sessionId": sessionID, "expression": "greeting", "frameId": 1000})
+		if isErr || text != "hello world" {
+			t.Errorf("expected the value read from memory, got %q", text)
+		}
+	})
+}
+
+// TestDebugExecuteCommand_GDB verifies GDB sessions send CLI commands without the LLDB backtick prefix.
+func TestDebugExecuteCommand_GDB(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+	_ = srv.GetSessionManager().SetSessionDebugger(sessionID, "gdb")
+
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "Dump of assembler code"}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_execute_command", map[string]interface{}{
+		"sessionId": sessionID,
+		"command":   "disassemble main",
+		"frameId":   1,
+	})
+	if isErr {
+		t.Fatalf("execute command failed: %s", text)
+	}
+
+	reqs := fake.received("evaluate")
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 evaluate request, got %d", len(reqs))
+	}
+	args := reqs[0].(*dap.EvaluateRequest).Arguments
+	if args.Expression != "disassemble main" || args.Context != "repl" {
+		t.Errorf("expected raw repl command, got %q in context %q", args.Expression, args.Context)
+	}
+}
+
+// TestDebugSnapshot_MaxThreads verifies snapshots of runtimes with many threads are truncated.
+func TestDebugSnapshot_MaxThreads(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageElixir)
+
+	line := int32(1)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+	ids := make([]int, 120)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	scriptThreads(fake, ids...)
+
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId":       sessionID,
+		"expandVariables": false,
+		"maxThreads":      10,
+	})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if threads := result["threads"].([]interface{}); len(threads) != 10 {
+		t.Errorf("expected 10 threads, got %d", len(threads))
+	}
+	if result["totalThreads"] != float64(120) || result["threadsOmitted"] != float64(110) {
+		t.Errorf("expected 110 of 120 threads omitted, got total %v omitted %v", result["totalThreads"], result["threadsOmitted"])
+	}
+	if reqs := fake.received("stackTrace"); len(reqs) != 10 {
+		t.Errorf("expected 10 stackTrace requests, got %d", len(reqs))
+	}
+}
+
+// TestDebugSnapshot_MissingTotalFrames verifies the stopped thread's stack is
+// counted page by page when the adapter leaves totalFrames out, and other
+// stacks only with countFrames.
+func TestDebugSnapshot_MissingTotalFrames(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	line := int32(1)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+	scriptThreads(fake, 1, 2)
+	depth := 250
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.StackTraceRequest).Arguments
+		var frames []dap.StackFrame
+		for i := args.StartFrame; i < depth && (args.Levels == 0 || i < args.StartFrame+args.Levels); i++ {
+			frames = append(frames, dap.StackFrame{Id: args.ThreadId*1000 + i, Name: fmt.Sprintf("frame%d", i), Line: 1})
+		}
+		// Like some adapters, report no total however deep the stack is
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: frames}}
+	})
+	fake.sendEvent(&dap.StoppedEvent{
+		Event: dap.Event{Event: "stopped"},
+		Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1},
+	})
+	for deadline := time.Now().Add(2 * time.Second); client.LastStop() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("the stop was not reported")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	requestsFor := func(threadID int, reqs []dap.RequestMessage) int {
+		n := 0
+		for _, req := range reqs {
+			if req.(*dap.StackTraceRequest).Arguments.ThreadId == threadID {
+				n++
+			}
+		}
+		return n
+	}
+	snapshot := func(args map[string]interface{}) map[int]map[string]interface{} {
+		t.Helper()
+		args["sessionId"] = sessionID
+		args["expandVariables"] = false
+		text, isErr := callTool(t, srv, "debug_snapshot", args)
+		if isErr {
+			t.Fatalf("snapshot failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		maxStackDepth := args["maxStackDepth"].(int)
+		if frames := result["stacks"].(map[string]interface{})["1"].([]interface{}); len(frames) != min(maxStackDepth, depth) {
+			t.Errorf("expected %d frames, got %d", min(maxStackDepth, depth), len(frames))
+		}
+		threads := make(map[int]map[string]interface{})
+		for _, th := range result["threads"].([]interface{}) {
+			thread := th.(map[string]interface{})
+			threads[int(thread["id"].(float64))] = thread
+		}
+		return threads
+	}
+
+	// Only the stopped thread's stack is counted; the other is probed
+	before := len(fake.received("stackTrace"))
+	threads := snapshot(map[string]interface{}{"maxStackDepth": 10})
+	if threads[1]["totalFrames"] != float64(depth) || threads[1]["hasMore"] != true {
+		t.Errorf("expected 250 frames in all and more to come, got %v", threads[1])
+	}
+	if _, counted := threads[2]["totalFrames"]; counted || threads[2]["hasMore"] != true {
+		t.Errorf("expected more frames for thread 2 without a count, got %v", threads[2])
+	}
+	if reqs := requestsFor(2, fake.received("stackTrace")[before:]); reqs != 2 {
+		t.Errorf("expected a page and a one-frame probe for thread 2, got %d requests", reqs)
+	}
+
+	// countFrames counts every stack
+	threads = snapshot(map[string]interface{}{"maxStackDepth": 10, "countFrames": true})
+	if threads[2]["totalFrames"] != float64(depth) {
+		t.Errorf("expected thread 2 to be counted, got %v", threads[2])
+	}
+
+	// A short page ends the stack without counting further
+	before = len(fake.received("stackTrace"))
+	threads = snapshot(map[string]interface{}{"maxStackDepth": 300})
+	if threads[1]["totalFrames"] != float64(depth) || threads[1]["hasMore"] != false {
+		t.Errorf("expected the whole stack of 250 frames, got %v", threads[1])
+	}
+	if reqs := requestsFor(1, fake.received("stackTrace")[before:]); reqs != 1 {
+		t.Errorf("expected 1 stackTrace request for a short page, got %d", reqs)
+	}
+}
+
+// TestDebugSnapshot_MaxBytes verifies a snapshot is cut to its byte budget,
+// keeping threads and top frames before variables.
+func TestDebugSnapshot_MaxBytes(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	line := int32(12)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable {
+		vars := make([]dap.Variable, 50)
+		for i := range vars {
+			vars[i] = dap.Variable{Name: fmt.Sprintf("v%d", i), Value: strings.Repeat("x", 100), Type: "string"}
+		}
+		return vars
+	})
+	scriptThreads(fake, 1, 2, 3, 4, 5)
+
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID, "maxBytes": 2000})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(text)); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if compact.Len() > 2000 {
+		t.Errorf("expected at most 2000 bytes, got %d", compact.Len())
+	}
+	result := decodeResult(t, text)
+	if threads := result["threads"].([]interface{}); len(threads) != 5 {
+		t.Errorf("expected all 5 threads to be kept, got %d", len(threads))
+	}
+	if stacks := result["stacks"].(map[string]interface{}); len(stacks) != 5 {
+		t.Errorf("expected the top frame of every thread, got %v", stacks)
+	}
+	truncated, ok := result["truncated"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a truncated summary, got %v", result)
+	}
+	if truncated["omittedThreads"] != float64(0) || truncated["omittedFrames"] != float64(0) {
+		t.Errorf("expected only scopes and variables to be cut, got %v", truncated)
+	}
+	if omitted, _ := truncated["omittedVariables"].(float64); omitted <= 0 {
+		t.Errorf("expected omitted variables to be counted, got %v", truncated)
+	}
+	if hint, _ := truncated["hint"].(string); !strings.Contains(hint, "variablesReference") {
+		t.Errorf("expected a hint on fetching what was left out, got %v", truncated)
+	}
+
+	// A cut snapshot doesn't become the delta baseline
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID, "delta": true})
+	if delta := decodeResult(t, text)["delta"].(map[string]interface{}); delta["baseline"] != true {
+		t.Errorf("expected no baseline from the cut snapshot, got %v", delta)
+	}
+
+	// Without a budget the snapshot is much larger
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID})
+	if len(text) < 5000 {
+		t.Errorf("expected a large snapshot without maxBytes, got %d bytes", len(text))
+	}
+	if _, ok := decodeResult(t, text)["truncated"]; ok {
+		t.Error("expected no truncated summary without maxBytes")
+	}
+}
+
+// TestDebugSnapshot_HideSystemThreads verifies that threads parked in runtime
+// functions are skipped and counted.
+func TestDebugSnapshot_HideSystemThreads(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	line := int32(1)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+	scriptThreads(fake, 1, 2, 3, 4)
+	topFrames := map[int]string{
+		1: "main.main",
+		2: "runtime.gopark",
+		3: "internal/poll.runtime_pollWait",
+		4: "main.worker",
+	}
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		threadID := req.(*dap.StackTraceRequest).Arguments.ThreadId
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+			StackFrames: []dap.StackFrame{{Id: threadID * 1000, Name: topFrames[threadID], Line: 1}},
+			TotalFrames: 1,
+		}}
+	})
+
+	threadIDs := func(result map[string]interface{}) []int {
+		var ids []int
+		for _, th := range result["threads"].([]interface{}) {
+			ids = append(ids, int(th.(map[string]interface{})["id"].(float64)))
+		}
+		return ids
+	}
+
+	// The default list hides goroutines parked by the runtime
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId":         sessionID,
+		"expandVariables":   false,
+		"hideSystemThreads": true,
+	})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if ids := threadIDs(result); fmt.Sprint(ids) != "[1 3 4]" {
+		t.Errorf("expected threads [1 3 4], got %v", ids)
+	}
+	if result["hiddenThreads"] != float64(1) {
+		t.Errorf("expected 1 hidden thread, got %v", result["hiddenThreads"])
+	}
+
+	// hiddenFrames replaces the configured list
+	text, isErr = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId":       sessionID,
+		"expandVariables": false,
+		"hiddenFrames":    `["runtime_pollWait", "worker"]`,
+	})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if ids := threadIDs(result); fmt.Sprint(ids) != "[1 2]" {
+		t.Errorf("expected threads [1 2], got %v", ids)
+	}
+	if result["hiddenThreads"] != float64(2) {
+		t.Errorf("expected 2 hidden threads, got %v", result["hiddenThreads"])
+	}
+
+	// Checking stops once maxThreads visible threads are found
+	before := len(fake.received("stackTrace"))
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId":         sessionID,
+		"expandVariables":   false,
+		"hideSystemThreads": true,
+		"maxThreads":        1,
+	})
+	result = decodeResult(t, text)
+	if ids := threadIDs(result); fmt.Sprint(ids) != "[1]" || result["threadsOmitted"] != float64(3) || result["totalThreads"] != float64(4) {
+		t.Errorf("expected thread 1 with 3 omitted, got %v (%v)", ids, result)
+	}
+	for _, req := range fake.received("stackTrace")[before:] {
+		if id := req.(*dap.StackTraceRequest).Arguments.ThreadId; id != 1 {
+			t.Errorf("expected only thread 1 to be checked, got a stackTrace for thread %d", id)
+		}
+	}
+
+	// Without the option every thread is reported
+	text, _ = callTool(t, srv, "debug_snapshot", map[string]interface{}{
+		"sessionId":       sessionID,
+		"expandVariables": false,
+	})
+	result = decodeResult(t, text)
+	if ids := threadIDs(result); len(ids) != 4 || result["hiddenThreads"] != nil {
+		t.Errorf("expected all 4 threads and no hiddenThreads, got %v (%v)", ids, result["hiddenThreads"])
+	}
+}
+
+// TestDebugDisconnect_AdapterAlreadyGone verifies a session whose adapter crashed can still be cleaned up.
+func TestDebugDisconnect_AdapterAlreadyGone(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	// Simulate a crashed adapter
+	_ = fake.conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for client.Alive() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.Alive() {
+		t.Fatal("expected client to notice the closed connection")
+	}
+
+	start := time.Now()
+	text, isErr := callTool(t, srv, "debug_disconnect", map[string]interface{}{
+		"sessionId": sessionID,
+	})
+	if isErr {
+		t.Fatalf("disconnect failed: %s", text)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected disconnect to skip the DAP request, took %v", elapsed)
+	}
+	result := decodeResult(t, text)
+	if result["status"] != "disconnected" || result["note"] == nil {
+		t.Errorf("expected disconnected with a note, got %v", result)
+	}
+	if _, err := srv.GetSessionManager().GetSession(sessionID); err == nil {
+		t.Error("expected session to be removed")
+	}
+}
+
+// TestDebugEvaluate_TruncatesLongValues verifies long values are truncated except in the clipboard context.
+func TestDebugEvaluate_TruncatesLongValues(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	long := strings.Repeat("x", 5000)
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: long, Type: "str"}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":  sessionID,
+		"expression": "blob",
+	})
+	if isErr {
+		t.Fatalf("evaluate failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["truncated"] != true {
+		t.Errorf("expected truncated flag, got %v", result["truncated"])
+	}
+	want := strings.Repeat("x", 2048) + "...(truncated, 5000 bytes)"
+	if result["result"] != want {
+		t.Errorf("expected value truncated to 2048 bytes, got %d bytes", len(result["result"].(string)))
+	}
+
+	text, _ = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":  sessionID,
+		"expression": "blob",
+		"context":    "clipboard",
+	})
+	result = decodeResult(t, text)
+	if result["result"] != long || result["truncated"] != nil {
+		t.Errorf("expected full value in clipboard context, got %d bytes", len(result["result"].(string)))
+	}
+}
+
+// TestDebugBreakpoints_ConditionReasons verifies unverified breakpoints report why and echo their condition.
+func TestDebugBreakpoints_ConditionReasons(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: []dap.Breakpoint{
+			{Id: 1, Verified: true, Line: 10},
+			{Id: 2, Verified: false, Line: 20, Message: "SyntaxError in condition: x >"},
+			{Id: 3, Verified: false, Line: 30},
+		}}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/tmp/app.py",
+		"breakpoints": `[{"line": 10}, {"line": 20, "condition": "x >"}, {"line": 30}]`,
+	})
+	if isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+	bps := decodeResult(t, text)["breakpoints"].([]interface{})
+	if len(bps) != 3 {
+		t.Fatalf("expected 3 breakpoints, got %d", len(bps))
+	}
+	if reason, ok := bps[0].(map[string]interface{})["reason"]; ok {
+		t.Errorf("expected no reason for verified breakpoint, got %v", reason)
+	}
+	bad := bps[1].(map[string]interface{})
+	if bad["reason"] != "invalidCondition" || bad["condition"] != "x >" {
+		t.Errorf("expected invalidCondition with original condition, got %v", bad)
+	}
+	if reason := bps[2].(map[string]interface{})["reason"]; reason != "noCode" {
+		t.Errorf("expected noCode, got %v", reason)
+	}
+}
+
+// TestDebugBreakpoints_Column verifies columns reach the adapter and columns
+// it moved are reported.
+func TestDebugBreakpoints_Column(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageJavaScript)
+
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		var bps []dap.Breakpoint
+		for i, bp := range req.(*dap.SetBreakpointsRequest).Arguments.Breakpoints {
+			column := bp.Column
+			if column == 40 {
+				column = 38 // The start of the statement
+			}
+			bps = append(bps, dap.Breakpoint{Id: i + 1, Verified: true, Line: bp.Line, Column: column})
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/tmp/app.min.js",
+		"breakpoints": `[{"line": 1, "column": 17}, {"line": 1, "column": 40}, {"line": 2}]`,
+	})
+	if isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+	reqs := fake.received("setBreakpoints")
+	if sent := reqs[0].(*dap.SetBreakpointsRequest).Arguments.Breakpoints; sent[0].Column != 17 || sent[1].Column != 40 || sent[2].Column != 0 {
+		t.Errorf("expected columns 17, 40, and none, got %v", sent)
+	}
+	result := decodeResult(t, text)
+	bps := result["breakpoints"].([]interface{})
+	if bp := bps[0].(map[string]interface{}); bp["column"] != float64(17) || bp["requestedColumn"] != nil {
+		t.Errorf("expected column 17 as requested, got %v", bp)
+	}
+	if bp := bps[1].(map[string]interface{}); bp["column"] != float64(38) || bp["requestedColumn"] != float64(40) {
+		t.Errorf("expected column 40 moved to 38, got %v", bp)
+	}
+	if bp := bps[2].(map[string]interface{}); bp["column"] != nil {
+		t.Errorf("expected no column for a line breakpoint, got %v", bp)
+	}
+	// The fake adapter doesn't report supportsBreakpointLocationsRequest
+	if note, _ := result["note"].(string); !strings.Contains(note, "column") {
+		t.Errorf("expected a note that columns may be ignored, got %v", result["note"])
+	}
+
+	text, _ = callTool(t, srv, "debug_list_breakpoints", map[string]interface{}{"sessionId": sessionID})
+	listed := decodeResult(t, text)["breakpoints"].([]interface{})
+	if bp := listed[1].(map[string]interface{}); bp["column"] != float64(38) || bp["requestedColumn"] != float64(40) {
+		t.Errorf("expected the listed breakpoint at column 38 of 40, got %v", bp)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/tmp/app.min.js",
+		"breakpoints": `[{"line": 1, "column": -1}]`,
+	}); !isErr {
+		t.Errorf("expected a negative column to be rejected, got %s", text)
+	}
+}
+
+// TestDebugBreakpoints_RequireVerified verifies requireVerified turns unverified
+// breakpoints into an error in debug_breakpoints and debug_run_to_line.
+func TestDebugBreakpoints_RequireVerified(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	cfg := config.DefaultConfig()
+	cfg.RequireVerifiedBreakpoints = true
+	srv, sessionID := newTestServerWithConfig(t, cfg, client, types.LanguagePython)
+
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		var bps []dap.Breakpoint
+		for _, bp := range req.(*dap.SetBreakpointsRequest).Arguments.Breakpoints {
+			if bp.Line == 10 {
+				bps = append(bps, dap.Breakpoint{Verified: true, Line: bp.Line})
+			} else {
+				bps = append(bps, dap.Breakpoint{Verified: false, Message: "no code at this line"})
+			}
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+
+	args := map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/tmp/app.py",
+		"breakpoints": `[{"line": 10}, {"line": 12}]`,
+	}
+	text, isErr := callTool(t, srv, "debug_breakpoints", args)
+	if !isErr || !strings.Contains(text, "line 12: no code at this line") {
+		t.Errorf("expected an error naming the unverified line and the adapter's message, got %s", text)
+	}
+
+	// The parameter overrides the setting
+	args["requireVerified"] = false
+	text, isErr = callTool(t, srv, "debug_breakpoints", args)
+	if isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+	if result := decodeResult(t, text); len(result["breakpoints"].([]interface{})) != 2 {
+		t.Errorf("expected both breakpoints to be returned, got %v", result)
+	}
+
+	text, isErr = callTool(t, srv, "debug_run_to_line", map[string]interface{}{"sessionId": sessionID, "path": "/tmp/app.py", "line": 12})
+	if !isErr || !strings.Contains(text, "no code at this line") {
+		t.Errorf("expected debug_run_to_line to fail on an unverified breakpoint, got %s", text)
+	}
+	if reqs := fake.received("continue"); len(reqs) != 0 {
+		t.Errorf("expected the program not to be resumed, got %d continue requests", len(reqs))
+	}
+}
+
+// TestDebugRunToLine_FrameScoped verifies a run scoped to a frame resumes from
+// hits in recursive calls and removes its temporary breakpoint afterward.
+func TestDebugRunToLine_FrameScoped(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	scriptThreads(fake, 1)
+	var depth int32 = 2
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		n := int(atomic.LoadInt32(&depth))
+		frames := make([]dap.StackFrame, n)
+		for i := range frames {
+			frames[i] = dap.StackFrame{Id: 100 + n - 1 - i, Name: "main.walk", Line: 8, Source: &dap.Source{Path: "/src/walk.go"}}
+		}
+		frames[n-1].Name = "main.main"
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: frames, TotalFrames: n}}
+	})
+	fake.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ScopesResponse{}
+	})
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		var bps []dap.Breakpoint
+		for _, bp := range req.(*dap.SetBreakpointsRequest).Arguments.Breakpoints {
+			bps = append(bps, dap.Breakpoint{Id: 7, Verified: true, Line: bp.Line})
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+	// The first hit is in a recursive call two frames deeper, the second in the frame's own call
+	depths := []int32{4, 2}
+	var continues int32
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		n := atomic.AddInt32(&continues, 1)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			atomic.StoreInt32(&depth, depths[n-1])
+			fake.sendEvent(&dap.StoppedEvent{
+				Event: dap.Event{Event: "stopped"},
+				Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, HitBreakpointIds: []int{7}},
+			})
+		}()
+		return &dap.ContinueResponse{}
+	})
+
+	// Frame 101 is the outer main.walk call, on top of the stack
+	text, isErr := callTool(t, srv, "debug_run_to_line", map[string]interface{}{
+		"sessionId": sessionID, "path": "/src/walk.go", "line": 8, "frameId": 101,
+	})
+	if isErr {
+		t.Fatalf("debug_run_to_line failed: %s", text)
+	}
+	if n := atomic.LoadInt32(&continues); n != 2 {
+		t.Errorf("expected the recursive hit to be resumed (2 continues), got %d", n)
+	}
+	if stack := decodeResult(t, text)["stack"].([]interface{}); len(stack) != 2 {
+		t.Errorf("expected to stop with the frame on top of a 2-frame stack, got %v", stack)
+	}
+
+	sets := fake.received("setBreakpoints")
+	if last := sets[len(sets)-1].(*dap.SetBreakpointsRequest); len(last.Arguments.Breakpoints) != 0 {
+		t.Errorf("expected the temporary breakpoint to be removed, got %v", last.Arguments.Breakpoints)
+	}
+}
+
+// TestDebugRunToLine_KeepsBreakpoints verifies the file's existing breakpoints
+// stay set during a run to a line and survive it, without the temporary one.
+func TestDebugRunToLine_KeepsBreakpoints(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	line := int32(8)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		var bps []dap.Breakpoint
+		for _, bp := range req.(*dap.SetBreakpointsRequest).Arguments.Breakpoints {
+			bps = append(bps, dap.Breakpoint{Id: bp.Line, Verified: true, Line: bp.Line})
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		go fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, HitBreakpointIds: []int{8}},
+		})
+		return &dap.ContinueResponse{}
+	})
+
+	if text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId": sessionID, "path": "/src/app.py", "breakpoints": `[{"line": 3}, {"line": 12, "condition": "n > 1"}]`,
+	}); isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+	if text, isErr := callTool(t, srv, "debug_run_to_line", map[string]interface{}{
+		"sessionId": sessionID, "path": "/src/app.py", "line": 8,
+	}); isErr {
+		t.Fatalf("debug_run_to_line failed: %s", text)
+	}
+
+	lines := func(req dap.RequestMessage) []int {
+		var lines []int
+		for _, bp := range req.(*dap.SetBreakpointsRequest).Arguments.Breakpoints {
+			lines = append(lines, bp.Line)
+		}
+		return lines
+	}
+	sets := fake.received("setBreakpoints")
+	if len(sets) != 3 {
+		t.Fatalf("expected set, run, and restore requests, got %d", len(sets))
+	}
+	if got := lines(sets[1]); fmt.Sprint(got) != "[3 12 8]" {
+		t.Errorf("expected the temporary breakpoint added to the file's, got %v", got)
+	}
+	restored := sets[2].(*dap.SetBreakpointsRequest).Arguments.Breakpoints
+	if fmt.Sprint(lines(sets[2])) != "[3 12]" || restored[1].Condition != "n > 1" {
+		t.Errorf("expected the original breakpoints restored, got %+v", restored)
+	}
+
+	text, _ := callTool(t, srv, "debug_list_breakpoints", map[string]interface{}{"sessionId": sessionID})
+	if bps := decodeResult(t, text)["breakpoints"].([]interface{}); len(bps) != 2 {
+		t.Errorf("expected the two original breakpoints listed, got %v", bps)
+	}
+}
+
+// TestDebugRunToLine_Timeout verifies a run that doesn't reach its line in
+// time pauses the program and reports it.
+func TestDebugRunToLine_Timeout(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	scriptThreads(fake, 1)
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: []dap.Breakpoint{{Id: 1, Verified: true, Line: 8}}}}
+	})
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ContinueResponse{}
+	})
+	fake.handle("pause", func(req dap.RequestMessage) dap.ResponseMessage {
+		go fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body:  dap.StoppedEventBody{Reason: "pause", ThreadId: 1},
+		})
+		return &dap.PauseResponse{}
+	})
+
+	text, isErr := callTool(t, srv, "debug_run_to_line", map[string]interface{}{
+		"sessionId": sessionID, "path": "/src/main.go", "line": 8, "timeout": 0.2,
+	})
+	if !isErr || !strings.Contains(text, "not reached") || !strings.Contains(text, "paused") {
+		t.Errorf("expected a timeout that paused the program, got %s", text)
+	}
+	if len(fake.received("pause")) != 1 {
+		t.Errorf("expected one pause request")
+	}
+}
+
+// TestDebugTraceExpression verifies an expression is recorded after each
+// step, and that the trace ends at a breakpoint or when the program exits.
+func TestDebugTraceExpression(t *testing.T) {
+	setup := func(t *testing.T, onStep func(fake *fakeAdapter, line int32)) (*dapmcp.Server, string, *fakeAdapter) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+		line := int32(10)
+		scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			// total doubles every other line
+			value := 1 << ((atomic.LoadInt32(&line) - 10) / 2)
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: fmt.Sprint(value), Type: "int"}}
+		})
+		fake.handle("next", func(req dap.RequestMessage) dap.ResponseMessage {
+			go onStep(fake, atomic.AddInt32(&line, 1))
+			return &dap.NextResponse{}
+		})
+		return srv, sessionID, fake
+	}
+	stopped := func(fake *fakeAdapter, reason string) {
+		fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body:  dap.StoppedEventBody{Reason: reason, ThreadId: 1},
+		})
+	}
+
+	t.Run("steps", func(t *testing.T) {
+		srv, sessionID, fake := setup(t, func(fake *fakeAdapter, line int32) { stopped(fake, "step") })
+
+		text, isErr := callTool(t, srv, "debug_trace_expression", map[string]interface{}{
+			"sessionId": sessionID, "expression": "total", "steps": 4, "threadId": 1,
+		})
+		if isErr {
+			t.Fatalf("trace failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		if result["endedBy"] != "steps" || result["stepsTaken"] != float64(4) {
+			t.Errorf("expected 4 steps, got endedBy=%v stepsTaken=%v", result["endedBy"], result["stepsTaken"])
+		}
+		trace := result["trace"].([]interface{})
+		if len(trace) != 5 {
+			t.Fatalf("expected the start and 4 steps, got %v", trace)
+		}
+		var values []string
+		var changed []bool
+		for _, e := range trace {
+			entry := e.(map[string]interface{})
+			values = append(values, fmt.Sprint(entry["line"], "=", entry["value"]))
+			changed = append(changed, entry["changed"] == true)
+		}
+		if got := strings.Join(values, " "); got != "10=1 11=1 12=2 13=2 14=4" {
+			t.Errorf("unexpected trace %s", got)
+		}
+		if !changed[2] || changed[3] || !changed[4] {
+			t.Errorf("expected changes at steps 2 and 4, got %v", changed)
+		}
+		if len(fake.received("next")) != 4 {
+			t.Errorf("expected 4 next requests, got %d", len(fake.received("next")))
+		}
+	})
+
+	t.Run("breakpoint", func(t *testing.T) {
+		srv, sessionID, _ := setup(t, func(fake *fakeAdapter, line int32) {
+			if line == 12 {
+				stopped(fake, "breakpoint")
+				return
+			}
+			stopped(fake, "step")
+		})
+
+		text, _ := callTool(t, srv, "debug_trace_expression", map[string]interface{}{
+			"sessionId": sessionID, "expression": "total", "steps": 10, "threadId": 1,
+		})
+		result := decodeResult(t, text)
+		if result["endedBy"] != "breakpoint" || result["stepsTaken"] != float64(2) {
+			t.Errorf("expected the trace to end at the breakpoint after 2 steps, got endedBy=%v stepsTaken=%v", result["endedBy"], result["stepsTaken"])
+		}
+	})
+
+	t.Run("exited", func(t *testing.T) {
+		srv, sessionID, _ := setup(t, func(fake *fakeAdapter, line int32) {
+			if line == 11 {
+				stopped(fake, "step")
+				return
+			}
+			fake.sendEvent(&dap.ExitedEvent{Event: dap.Event{Event: "exited"}, Body: dap.ExitedEventBody{ExitCode: 3}})
+			fake.sendEvent(&dap.TerminatedEvent{Event: dap.Event{Event: "terminated"}})
+		})
+
+		text, _ := callTool(t, srv, "debug_trace_expression", map[string]interface{}{
+			"sessionId": sessionID, "expression": "total", "steps": 10, "threadId": 1,
+		})
+		result := decodeResult(t, text)
+		if result["endedBy"] != "exited" || result["exitCode"] != float64(3) || result["stepsTaken"] != float64(1) {
+			t.Errorf("expected the trace to end when the program exited, got %v", result)
+		}
+	})
+
+	t.Run("invalid steps", func(t *testing.T) {
+		srv, sessionID, _ := setup(t, func(fake *fakeAdapter, line int32) {})
+		if text, isErr := callTool(t, srv, "debug_trace_expression", map[string]interface{}{
+			"sessionId": sessionID, "expression": "total", "steps": 1000,
+		}); !isErr {
+			t.Errorf("expected too many steps to be rejected, got %s", text)
+		}
+	})
+}
+
+// TestDebugBreakpoints_WholeRequestRejected verifies a bad condition that fails the
+// whole request is isolated and the remaining breakpoints are still set.
+func TestDebugBreakpoints_WholeRequestRejected(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageJavaScript)
+
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.SetBreakpointsRequest).Arguments
+		var bps []dap.Breakpoint
+		for i, bp := range args.Breakpoints {
+			if bp.Condition == "x ===" {
+				return &dap.ErrorResponse{Response: dap.Response{Message: "Unexpected end of input"}}
+			}
+			bps = append(bps, dap.Breakpoint{Id: i + 1, Verified: true, Line: bp.Line})
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/tmp/app.js",
+		"breakpoints": `[{"line": 5, "condition": "y > 1"}, {"line": 10, "condition": "x ==="}, {"line": 15}]`,
+	})
+	if isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["note"] == nil {
+		t.Error("expected a note about the rejected condition")
+	}
+	bps := result["breakpoints"].([]interface{})
+	if len(bps) != 3 {
+		t.Fatalf("expected 3 breakpoint results, got %d", len(bps))
+	}
+	for i, line := range []float64{5, 10, 15} {
+		if got := bps[i].(map[string]interface{})["line"]; got != line {
+			t.Errorf("breakpoint %d: expected line %v, got %v", i, line, got)
+		}
+	}
+	bad := bps[1].(map[string]interface{})
+	if bad["verified"] != false || bad["reason"] != "invalidCondition" || bad["condition"] != "x ===" {
+		t.Errorf("expected rejected condition to be reported, got %v", bad)
+	}
+
+	reqs := fake.received("setBreakpoints")
+	last := reqs[len(reqs)-1].(*dap.SetBreakpointsRequest).Arguments.Breakpoints
+	if len(last) != 2 || last[0].Line != 5 || last[1].Line != 15 {
+		t.Errorf("expected final request to set lines 5 and 15, got %+v", last)
+	}
+}
+
+// TestDebugBreakWhen verifies the condition is built from the current value and
+// existing breakpoints in the file are kept.
+func TestDebugBreakWhen(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	values := map[string]dap.EvaluateResponseBody{
+		"count":     {Result: "42", Type: "int"},
+		"name":      {Result: `"alice"`, Type: "string"},
+		"user":      {Result: "main.User {...}", Type: "main.User", VariablesReference: 7},
+		"flags & 4": {Result: "4", Type: "int"},
+		"mask":      {Result: "0xff", Type: "uint8"},
+		"ratio":     {Result: "NaN", Type: "float64"},
+		"limit":     {Result: "+Inf", Type: "float64"},
+		"scale":     {Result: "Infinity", Type: "float64"},
+		"total":     {Result: "1_000", Type: "int"},
+	}
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: values[req.(*dap.EvaluateRequest).Arguments.Expression]}
+	})
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		var bps []dap.Breakpoint
+		for _, bp := range req.(*dap.SetBreakpointsRequest).Arguments.Breakpoints {
+			bps = append(bps, dap.Breakpoint{Verified: true, Line: bp.Line})
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+
+	if text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/src/main.go",
+		"breakpoints": `[{"line": 5}]`,
+	}); isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+
+	// Ordered so the last call's condition is known
+	for _, tc := range []struct{ expr, want string }{
+		{"count", "(count) == 42"},
+		{"flags & 4", "(flags & 4) == 4"},
+		{"mask", "(mask) == 0xff"},
+		{"name", `(name) == "alice"`},
+	} {
+		expr, want := tc.expr, tc.want
+		text, isErr := callTool(t, srv, "debug_break_when", map[string]interface{}{
+			"sessionId":  sessionID,
+			"expression": expr,
+			"path":       "/src/main.go",
+			"line":       12,
+		})
+		if isErr {
+			t.Fatalf("break_when %s failed: %s", expr, text)
+		}
+		if got := decodeResult(t, text)["condition"]; got != want {
+			t.Errorf("expected condition %q, got %v", want, got)
+		}
+	}
+
+	reqs := fake.received("setBreakpoints")
+	last := reqs[len(reqs)-1].(*dap.SetBreakpointsRequest).Arguments.Breakpoints
+	if len(last) != 2 || last[0].Line != 5 || last[1].Condition != `(name) == "alice"` {
+		t.Errorf("expected line 5 kept and line 12 condition replaced, got %+v", last)
+	}
+
+	text, isErr := callTool(t, srv, "debug_break_when", map[string]interface{}{
+		"sessionId":  sessionID,
+		"expression": "user",
+		"path":       "/src/main.go",
+		"line":       12,
+	})
+	if !isErr || !strings.Contains(text, "debug_breakpoints") {
+		t.Errorf("expected error suggesting a manual condition for structs, got %s", text)
+	}
+
+	// Values that parse as floats but aren't literals in a condition
+	for _, expr := range []string{"ratio", "limit", "scale", "total"} {
+		text, isErr := callTool(t, srv, "debug_break_when", map[string]interface{}{
+			"sessionId":  sessionID,
+			"expression": expr,
+			"path":       "/src/main.go",
+			"line":       12,
+		})
+		if !isErr {
+			t.Errorf("expected %s (%s) to be rejected, got %s", expr, values[expr].Result, text)
+		}
+	}
+}
+
+// TestDebugAdapterLog verifies the adapter's captured stderr is returned per session.
+func TestDebugAdapterLog(t *testing.T) {
+	_, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	text, isErr := callTool(t, srv, "debug_adapter_log", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("adapter_log failed: %s", text)
+	}
+	if result := decodeResult(t, text); result["captured"] != false {
+		t.Errorf("expected captured=false without a spawned adapter, got %v", result)
+	}
+
+	log := internaldap.NewAdapterLog()
+	_, _ = log.Write([]byte("starting adapter\nTraceback: boom\n"))
+	_ = srv.GetSessionManager().SetSessionProcess(sessionID, &exec.Cmd{Stderr: log}, 0)
+
+	text, isErr = callTool(t, srv, "debug_adapter_log", map[string]interface{}{
+		"sessionId": sessionID,
+		"lines":     1,
+	})
+	if isErr {
+		t.Fatalf("adapter_log failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	lines := result["lines"].([]interface{})
+	if len(lines) != 1 || lines[0] != "Traceback: boom" || result["totalLines"] != float64(2) {
+		t.Errorf("expected last captured line, got %v", result)
+	}
+}
+
+// TestDebugLaunch_MaxRestartsBounded verifies restartOnExit can't be configured unbounded.
+func TestDebugLaunch_MaxRestartsBounded(t *testing.T) {
+	_, client := newFakeAdapter(t)
+	srv, _ := newTestServer(t, client, types.LanguagePython)
+
+	for _, n := range []int{0, 11} {
+		text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language":      "python",
+			"program":       "app.py",
+			"restartOnExit": true,
+			"maxRestarts":   n,
+		})
+		if !isErr || !strings.Contains(text, "maxRestarts") {
+			t.Errorf("expected maxRestarts=%d to be rejected, got %s", n, text)
+		}
+	}
+	if sessions := srv.GetSessionManager().ListSessions(); len(sessions) != 1 {
+		t.Errorf("expected no session to be created, got %d sessions", len(sessions))
+	}
+}
+
+// TestDebugLaunch_UnsupportedTarget verifies a target the adapter doesn't
+// support is rejected before anything is spawned.
+func TestDebugLaunch_UnsupportedTarget(t *testing.T) {
+	_, client := newFakeAdapter(t)
+	srv, _ := newTestServer(t, client, types.LanguageGo)
+
+	text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+		"language": "go",
+		"program":  "./cmd/app",
+		"target":   "chrome",
+	})
+	if !isErr || !strings.Contains(text, "'target'") {
+		t.Errorf("expected target to be rejected, got %s", text)
+	}
+
+	text, isErr = callTool(t, srv, "debug_attach", map[string]interface{}{
+		"language": "javascript",
+		"target":   "firefox",
+		"port":     9222,
+	})
+	if !isErr || !strings.Contains(text, "node, chrome, edge") {
+		t.Errorf("expected supported targets in error, got %s", text)
+	}
+
+	if sessions := srv.GetSessionManager().ListSessions(); len(sessions) != 1 {
+		t.Errorf("expected no session to be created, got %d sessions", len(sessions))
+	}
+}
+
+// TestDebugAttach_BrowserTabNotFound verifies a url matching no open tab lists the discoverable tabs.
+func TestDebugAttach_BrowserTabNotFound(t *testing.T) {
+	_, client := newFakeAdapter(t)
+	srv, _ := newTestServer(t, client, types.LanguageJavaScript)
+	host, port := newFakeBrowser(t, fakeBrowserTargets)
+
+	text, isErr := callTool(t, srv, "debug_attach", map[string]interface{}{
+		"language": "javascript",
+		"target":   "chrome",
+		"host":     host,
+		"port":     port,
+		"url":      "http://localhost:8080/*",
+	})
+	if !isErr {
+		t.Fatalf("expected error for unmatched url, got %s", text)
+	}
+	for _, want := range []string{"http://localhost:3000/settings", "https://example.com/docs"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected open tab %s in error, got %s", want, text)
+		}
+	}
+	if strings.Contains(text, "sw.js") {
+		t.Errorf("expected non-page targets to be omitted, got %s", text)
+	}
+
+	// Nothing listening: the error explains how to start the browser
+	text, isErr = callTool(t, srv, "debug_attach", map[string]interface{}{
+		"language": "javascript",
+		"target":   "edge",
+		"host":     "127.0.0.1",
+		"port":     1,
+	})
+	if !isErr || !strings.Contains(text, "--remote-debugging-port") {
+		t.Errorf("expected remote debugging hint, got %s", text)
+	}
+	if sessions := srv.GetSessionManager().ListSessions(); len(sessions) != 1 {
+		t.Errorf("expected failed attaches to clean up their sessions, got %d sessions", len(sessions))
+	}
+}
+
+// TestDebugAttach_FailureDetaches verifies an attach that fails part-way
+// detaches from the process instead of terminating it.
+func TestDebugAttach_FailureDetaches(t *testing.T) {
+	f, client := newFakeAdapter(t)
+	srv := newLaunchServer(t, f, client, types.LanguageGo)
+	f.handle("configurationDone", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ErrorResponse{Response: dap.Response{Message: "configuration rejected"}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_attach", map[string]interface{}{
+		"language": "go",
+		"pid":      4242,
+	})
+	if !isErr || !strings.Contains(text, "configuration failed") {
+		t.Fatalf("expected configuration failure, got %s", text)
+	}
+
+	disconnects := f.received("disconnect")
+	if len(disconnects) != 1 {
+		t.Fatalf("expected one disconnect, got %d", len(disconnects))
+	}
+	if req := disconnects[0].(*dap.DisconnectRequest); req.Arguments == nil || req.Arguments.TerminateDebuggee {
+		t.Errorf("expected terminateDebuggee=false for a failed attach, got %+v", req.Arguments)
+	}
+	if sessions := srv.GetSessionManager().ListSessions(); len(sessions) != 0 {
+		t.Errorf("expected the failed attach to clean up its session, got %d sessions", len(sessions))
+	}
+}
+
+// TestDebugAttach_WaitFor verifies attaching with waitFor waits for the named
+// process and reports it, and gives up after the timeout.
+func TestDebugAttach_WaitFor(t *testing.T) {
+	waitForServer := func(t *testing.T) (*fakeAdapter, *dapmcp.Server) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguageGo)
+		srv.GetAdapterRegistry().Register(types.LanguageGo, &waitForAdapter{
+			launchableAdapter: &launchableAdapter{lang: types.LanguageGo, client: client},
+		})
+		return fake, srv
+	}
+
+	t.Run("attached", func(t *testing.T) {
+		fake, srv := waitForServer(t)
+		fake.handle("attach", func(req dap.RequestMessage) dap.ResponseMessage {
+			fake.sendEvent(&dap.ProcessEvent{
+				Event: dap.Event{Event: "process"},
+				Body:  dap.ProcessEventBody{Name: "/usr/local/bin/worker", SystemProcessId: 4321, StartMethod: "attach"},
+			})
+			return &dap.AttachResponse{}
+		})
+
+		text, isErr := callTool(t, srv, "debug_attach", map[string]interface{}{
+			"language": "go",
+			"program":  "worker",
+			"waitFor":  true,
+		})
+		if isErr {
+			t.Fatalf("debug_attach failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		if result["waitedFor"] != "worker" || result["pid"] != float64(4321) {
+			t.Errorf("expected the started process to be reported, got %v", result)
+		}
+		if process, _ := result["process"].(map[string]interface{}); process["name"] != "/usr/local/bin/worker" {
+			t.Errorf("expected the process name, got %v", result["process"])
+		}
+	})
+
+	t.Run("not started", func(t *testing.T) {
+		_, srv := waitForServer(t)
+
+		text, isErr := callTool(t, srv, "debug_attach", map[string]interface{}{
+			"language": "go",
+			"program":  "worker",
+			"waitFor":  true,
+			"timeout":  0.2,
+		})
+		if !isErr || !strings.Contains(text, `no process named "worker"`) {
+			t.Errorf("expected a not started error, got %s", text)
+		}
+		if sessions := srv.GetSessionManager().ListSessions(); len(sessions) != 0 {
+			t.Errorf("expected the failed attach to clean up its session, got %d sessions", len(sessions))
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguageGo)
+
+		text, isErr := callTool(t, srv, "debug_attach", map[string]interface{}{
+			"language": "go", "program": "worker", "waitFor": true,
+		})
+		if !isErr || !strings.Contains(text, "lldb") {
+			t.Errorf("expected waitFor to need lldb, got %s", text)
+		}
+
+		_, srv = waitForServer(t)
+		text, isErr = callTool(t, srv, "debug_attach", map[string]interface{}{
+			"language": "go", "waitFor": true,
+		})
+		if !isErr || !strings.Contains(text, "'program'") {
+			t.Errorf("expected program to be required, got %s", text)
+		}
+	})
+}
+
+// TestDebugSnapshot_ScopesFilter verifies only locals and arguments are expanded by
+// default, expensive scopes need to be named, and skipped scopes are reported.
+func TestDebugSnapshot_ScopesFilter(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+
+	line := int32(5)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable {
+		return []dap.Variable{{Name: "x", Value: "1", Type: "int"}}
+	})
+	fake.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ScopesResponse{Body: dap.ScopesResponseBody{Scopes: []dap.Scope{
+			{Name: "Locals", VariablesReference: 1},
+			{Name: "Arguments", VariablesReference: 2, PresentationHint: "arguments"},
+			{Name: "Globals", VariablesReference: 3},
+			{Name: "Registers", VariablesReference: 4, PresentationHint: "registers", Expensive: true},
+		}}}
+	})
+
+	expanded := func(args map[string]interface{}) (map[string]interface{}, []interface{}) {
+		t.Helper()
+		args["sessionId"] = sessionID
+		text, isErr := callTool(t, srv, "debug_snapshot", args)
+		if isErr {
+			t.Fatalf("snapshot failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		skipped, _ := result["skippedScopes"].([]interface{})
+		return result["variables"].(map[string]interface{}), skipped
+	}
+
+	vars, skipped := expanded(map[string]interface{}{})
+	if len(vars) != 2 || vars["1"] == nil || vars["2"] == nil {
+		t.Errorf("expected Locals and Arguments expanded by default, got %v", vars)
+	}
+	if fmt.Sprint(skipped) != "[Globals Registers]" {
+		t.Errorf("expected Globals and Registers skipped, got %v", skipped)
+	}
+
+	vars, skipped = expanded(map[string]interface{}{"scopes": `["*"]`})
+	if len(vars) != 3 || vars["4"] != nil || fmt.Sprint(skipped) != "[Registers]" {
+		t.Errorf("expected every non-expensive scope expanded with *, got %v (skipped %v)", vars, skipped)
+	}
+
+	vars, skipped = expanded(map[string]interface{}{"scopes": `["registers"]`})
+	if len(vars) != 1 || vars["4"] == nil || len(skipped) != 3 {
+		t.Errorf("expected only the named expensive scope expanded, got %v (skipped %v)", vars, skipped)
+	}
+}
+
+// TestDebugListBreakpoints_LateVerification verifies breakpoint events update the
+// state reported after breakpoints were set.
+func TestDebugListBreakpoints_LateVerification(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: []dap.Breakpoint{
+			{Id: 7, Verified: false, Line: 10},
+			{Id: 8, Verified: false, Line: 20},
+		}}}
+	})
+	if text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/src/plugin.py",
+		"breakpoints": `[{"line": 10, "condition": "n > 3"}, {"line": 20}]`,
+	}); isErr {
+		t.Fatalf("breakpoints failed: %s", text)
+	}
+
+	// The module loads: one breakpoint binds to the next executable line, the other is dropped
+	fake.sendEvent(&dap.BreakpointEvent{
+		Event: dap.Event{Event: "breakpoint"},
+		Body:  dap.BreakpointEventBody{Reason: "changed", Breakpoint: dap.Breakpoint{Id: 7, Verified: true, Line: 12}},
+	})
+	fake.sendEvent(&dap.BreakpointEvent{
+		Event: dap.Event{Event: "breakpoint"},
+		Body:  dap.BreakpointEventBody{Reason: "removed", Breakpoint: dap.Breakpoint{Id: 8}},
+	})
+
+	var bps []interface{}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		text, isErr := callTool(t, srv, "debug_list_breakpoints", map[string]interface{}{"sessionId": sessionID})
+		if isErr {
+			t.Fatalf("list_breakpoints failed: %s", text)
+		}
+		bps = decodeResult(t, text)["breakpoints"].([]interface{})
+		if len(bps) == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(bps) != 1 {
+		t.Fatalf("expected removed breakpoint to be dropped, got %v", bps)
+	}
+	bp := bps[0].(map[string]interface{})
+	if bp["verified"] != true || bp["line"] != float64(12) || bp["path"] != "/src/plugin.py" {
+		t.Errorf("expected breakpoint verified at line 12, got %v", bp)
+	}
+	if bp["reason"] != nil {
+		t.Errorf("expected no reason for a verified breakpoint, got %v", bp["reason"])
+	}
+}
+
+// TestDebugBreakpoints_RelativePath verifies relative breakpoint paths are
+// resolved against the launch's cwd, and that a source path the adapter
+// reports differently is recorded.
+func TestDebugBreakpoints_RelativePath(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv := newLaunchServer(t, fake, client, types.LanguagePython)
+
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.SetBreakpointsRequest).Arguments
+		bps := make([]dap.Breakpoint, len(args.Breakpoints))
+		for i, bp := range args.Breakpoints {
+			bps[i] = dap.Breakpoint{Id: i + 1, Verified: true, Line: bp.Line}
+			// The adapter reports the source with its symlink resolved
+			if args.Source.Path == "/proj/lib/util.py" {
+				bps[i].Source = &dap.Source{Path: "/real/proj/lib/util.py"}
+			}
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+		"language":    "python",
+		"program":     "/proj/app.py",
+		"cwd":         "/proj",
+		"breakpoints": `[{"path": "src/main.py", "line": 3}]`,
+	})
+	if isErr {
+		t.Fatalf("debug_launch failed: %s", text)
+	}
+	sessionID := decodeResult(t, text)["sessionId"].(string)
+
+	paths := func() []string {
+		var paths []string
+		for _, req := range fake.received("setBreakpoints") {
+			paths = append(paths, req.(*dap.SetBreakpointsRequest).Arguments.Source.Path)
+		}
+		return paths
+	}
+	if got := paths(); len(got) != 1 || got[0] != "/proj/src/main.py" {
+		t.Errorf("expected the launch breakpoint in /proj/src/main.py, got %v", got)
+	}
+
+	text, isErr = callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "./lib/util.py",
+		"breakpoints": `[{"line": 7}]`,
+	})
+	if isErr {
+		t.Fatalf("debug_breakpoints failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["path"] != "/proj/lib/util.py" || result["requestedPath"] != "./lib/util.py" {
+		t.Errorf("expected ./lib/util.py resolved to /proj/lib/util.py, got %v", result)
+	}
+	if result["adapterPath"] != "/real/proj/lib/util.py" {
+		t.Errorf("expected the adapter's path for the source, got %v", result)
+	}
+
+	text, _ = callTool(t, srv, "debug_list_breakpoints", map[string]interface{}{"sessionId": sessionID})
+	for _, entry := range decodeResult(t, text)["breakpoints"].([]interface{}) {
+		bp := entry.(map[string]interface{})
+		if bp["path"] == "/proj/lib/util.py" && bp["adapterPath"] != "/real/proj/lib/util.py" {
+			t.Errorf("expected debug_list_breakpoints to show the adapter's path, got %v", bp)
+		}
+		if bp["path"] == "/proj/src/main.py" && bp["adapterPath"] != nil {
+			t.Errorf("expected no adapterPath where the adapter kept the path, got %v", bp)
+		}
+	}
+
+	// Absolute paths are sent as given
+	if text, isErr := callTool(t, srv, "debug_breakpoints", map[string]interface{}{
+		"sessionId":   sessionID,
+		"path":        "/elsewhere/tool.py",
+		"breakpoints": `[{"line": 1}]`,
+	}); isErr || decodeResult(t, text)["requestedPath"] != nil {
+		t.Errorf("expected an absolute path to be used as given, got %s", text)
+	}
+}
+
+// TestDebugThreads_Lifecycle verifies thread events are tracked and snapshots skip exited threads.
+func TestDebugThreads_Lifecycle(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	line := int32(1)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+	scriptThreads(fake, 1, 2)
+
+	fake.sendEvent(&dap.ThreadEvent{Event: dap.Event{Event: "thread"}, Body: dap.ThreadEventBody{Reason: "started", ThreadId: 3}})
+	fake.sendEvent(&dap.ThreadEvent{Event: dap.Event{Event: "thread"}, Body: dap.ThreadEventBody{Reason: "exited", ThreadId: 2}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.ThreadExited(2) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	text, isErr := callTool(t, srv, "debug_threads", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("threads failed: %s", text)
+	}
+	statuses := map[float64]interface{}{}
+	for _, th := range decodeResult(t, text)["threads"].([]interface{}) {
+		m := th.(map[string]interface{})
+		statuses[m["id"].(float64)] = m["status"]
+	}
+	want := map[float64]string{1: "running", 2: "exited", 3: "started"}
+	for id, status := range want {
+		if statuses[id] != status {
+			t.Errorf("thread %v: expected %s, got %v", id, status, statuses[id])
+		}
+	}
+
+	text, _ = callTool(t, srv, "debug_threads", map[string]interface{}{"sessionId": sessionID, "includeExited": false})
+	if threads := decodeResult(t, text)["threads"].([]interface{}); len(threads) != 2 {
+		t.Errorf("expected exited thread to be omitted, got %v", threads)
+	}
+
+	text, isErr = callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID, "expandVariables": false})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["exitedThreads"] != float64(1) {
+		t.Errorf("expected 1 exited thread skipped, got %v", result["exitedThreads"])
+	}
+	if stacks := result["stacks"].(map[string]interface{}); stacks["2"] != nil || stacks["1"] == nil {
+		t.Errorf("expected only thread 1 in stacks, got %v", stacks)
+	}
+}
+
+// TestDebugModules verifies module events keep the module list live and modules
+// loaded since the previous call are flagged new.
+func TestDebugModules(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	module := func(reason string, id int, name, path string) {
+		fake.sendEvent(&dap.ModuleEvent{
+			Event: dap.Event{Event: "module"},
+			Body:  dap.ModuleEventBody{Reason: reason, Module: dap.Module{Id: id, Name: name, Path: path}},
+		})
+	}
+	waitModules := func(seq int) {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, last := client.LoadedModules(); last >= seq {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	modules := func() map[string]map[string]interface{} {
+		t.Helper()
+		text, isErr := callTool(t, srv, "debug_modules", map[string]interface{}{"sessionId": sessionID})
+		if isErr {
+			t.Fatalf("debug_modules failed: %s", text)
+		}
+		byName := map[string]map[string]interface{}{}
+		for _, m := range decodeResult(t, text)["modules"].([]interface{}) {
+			byName[m.(map[string]interface{})["name"].(string)] = m.(map[string]interface{})
+		}
+		return byName
+	}
+
+	module("new", 1, "app", "/src/app.py")
+	module("new", 2, "json", "/lib/json/__init__.py")
+	waitModules(2)
+	got := modules()
+	if len(got) != 2 || got["app"]["new"] != true || got["json"]["new"] != true {
+		t.Errorf("expected two new modules, got %v", got)
+	}
+
+	// One module changed, a plugin loaded at runtime, and one module unloaded
+	module("changed", 2, "json", "/lib/python3/json/__init__.py")
+	module("new", 3, "plugin", "/plugins/plugin.py")
+	module("removed", 1, "app", "")
+	waitModules(3)
+	deadline := time.Now().Add(2 * time.Second)
+	for list, _ := client.LoadedModules(); len(list) != 2 && time.Now().Before(deadline); list, _ = client.LoadedModules() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got = modules()
+	if len(got) != 2 || got["app"] != nil {
+		t.Fatalf("expected the removed module to be gone, got %v", got)
+	}
+	if got["plugin"]["new"] != true || got["json"]["new"] != nil {
+		t.Errorf("expected only the plugin to be new, got %v", got)
+	}
+	if got["json"]["path"] != "/lib/python3/json/__init__.py" {
+		t.Errorf("expected the changed module's new path, got %v", got["json"])
+	}
+}
+
+// TestIntegerParams verifies number parameters are accepted as numeric
+// strings, and rejected with a clear error when they aren't whole numbers.
+func TestIntegerParams(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+	for _, text := range []string{"one\n", "two\n", "three\n"} {
+		fake.sendEvent(&dap.OutputEvent{
+			Event: dap.Event{Event: "output"},
+			Body:  dap.OutputEventBody{Category: "stdout", Output: text},
+		})
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if entries, _ := client.Output(); len(entries) == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, lines := range []interface{}{2, "2", " 2 ", 2.0} {
+		text, isErr := callTool(t, srv, "debug_get_output", map[string]interface{}{"sessionId": sessionID, "lines": lines})
+		if isErr {
+			t.Fatalf("lines=%#v failed: %s", lines, text)
+		}
+		if entries := decodeResult(t, text)["output"].([]interface{}); len(entries) != 2 {
+			t.Errorf("lines=%#v: expected 2 entries, got %d", lines, len(entries))
+		}
+	}
+
+	for _, lines := range []interface{}{1.5, "two", true} {
+		text, isErr := callTool(t, srv, "debug_get_output", map[string]interface{}{"sessionId": sessionID, "lines": lines})
+		if !isErr || !strings.Contains(text, "invalid value for parameter 'lines'") {
+			t.Errorf("lines=%#v: expected an invalid parameter error, got %s", lines, text)
+		}
+	}
+}
+
+// TestDebugGetOutput verifies that output events are tagged with their
+// category and source location, and can be filtered and polled.
+func TestDebugGetOutput(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageJavaScript)
+
+	output := func(category, text string, source *dap.Source, line int) {
+		fake.sendEvent(&dap.OutputEvent{
+			Event: dap.Event{Event: "output"},
+			Body:  dap.OutputEventBody{Category: category, Output: text, Source: source, Line: line},
+		})
+	}
+	output("stdout", "starting\n", nil, 0)
+	output("telemetry", "{}", nil, 0)
+	output("stderr", "Assertion failed\n", &dap.Source{Path: "/src/app.js"}, 42)
+	output("", "console message\n", nil, 0)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if entries, _ := client.Output(); len(entries) == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	text, isErr := callTool(t, srv, "debug_get_output", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("get output failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	entries := result["output"].([]interface{})
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries without telemetry, got %v", entries)
+	}
+	if last := entries[2].(map[string]interface{}); last["category"] != "console" {
+		t.Errorf("expected a missing category to be console, got %v", last["category"])
+	}
+	if result["errorCount"] != float64(1) || result["lastSeq"] != float64(3) {
+		t.Errorf("expected 1 error and lastSeq 3, got %v and %v", result["errorCount"], result["lastSeq"])
+	}
+
+	// Only errors, with their source location
+	text, _ = callTool(t, srv, "debug_get_output", map[string]interface{}{
+		"sessionId":  sessionID,
+		"categories": `["stderr", "important"]`,
+	})
+	entries = decodeResult(t, text)["output"].([]interface{})
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 stderr entry, got %v", entries)
+	}
+	entry := entries[0].(map[string]interface{})
+	source, _ := entry["source"].(map[string]interface{})
+	if entry["error"] != true || source["path"] != "/src/app.js" || source["line"] != float64(42) {
+		t.Errorf("expected a flagged error at /src/app.js:42, got %v", entry)
+	}
+
+	// Polling from lastSeq returns nothing new
+	text, _ = callTool(t, srv, "debug_get_output", map[string]interface{}{
+		"sessionId": sessionID,
+		"since":     3,
+	})
+	result = decodeResult(t, text)
+	if entries := result["output"].([]interface{}); len(entries) != 0 || result["lastSeq"] != float64(3) {
+		t.Errorf("expected no new output and lastSeq 3, got %v", result)
+	}
+}
+
+// TestDebugSource_DiskFallback verifies that sources on disk are read directly
+// and only sources without a file are requested from the adapter.
+func TestDebugSource_DiskFallback(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.handle("source", func(req dap.RequestMessage) dap.ResponseMessage {
+		if req.(*dap.SourceRequest).Arguments.SourceReference == 0 {
+			return &dap.ErrorResponse{Response: dap.Response{Message: "source request not supported"}}
+		}
+		return &dap.SourceResponse{Body: dap.SourceResponseBody{Content: "// generated\n", MimeType: "text/javascript"}}
+	})
+
+	root := t.TempDir()
+	session, _ := srv.GetSessionManager().GetSession(sessionID)
+	session.SetSourceRoot(root)
+
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	text, isErr := callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": path})
+	if isErr {
+		t.Fatalf("debug_source failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["origin"] != "disk" || result["content"] != "package main\n\nfunc main() {}\n" {
+		t.Errorf("expected file content from disk, got %v", result)
+	}
+	if n := len(fake.received("source")); n != 0 {
+		t.Errorf("expected no source request for a file on disk, got %d", n)
+	}
+
+	text, isErr = callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": "<eval>/VM42", "sourceReference": 7})
+	if isErr {
+		t.Fatalf("debug_source failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if result["origin"] != "adapter" || result["content"] != "// generated\n" || result["mimeType"] != "text/javascript" {
+		t.Errorf("expected content from the adapter, got %v", result)
+	}
+
+	// A missing file with no sourceReference is still asked of the adapter
+	text, isErr = callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": filepath.Join(root, "gone.go")})
+	if !isErr || !strings.Contains(text, "could not get source") {
+		t.Errorf("expected source error, got %s", text)
+	}
+
+	// Large files are cut at a line break within maxSourceSize
+	large := filepath.Join(root, "large.go")
+	if err := os.WriteFile(large, []byte(strings.Repeat("// padding line\n", 100000)), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	text, _ = callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": large})
+	result = decodeResult(t, text)
+	content, _ := result["content"].(string)
+	if result["truncated"] != true || len(content) > 1<<20 || !strings.HasSuffix(content, "\n") {
+		t.Errorf("expected content truncated at a line break, got %d bytes, truncated=%v", len(content), result["truncated"])
+	}
+	if result["size"] != float64(1600000) {
+		t.Errorf("expected full file size, got %v", result["size"])
+	}
+}
+
+// TestDebugSource_OutsideSession verifies debug_source only reads files of the
+// debuggee: under the session's source root or in a stopped thread's stack.
+func TestDebugSource_OutsideSession(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+	session, _ := srv.GetSessionManager().GetSession(sessionID)
+	session.SetSourceRoot(t.TempDir())
+
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	library := filepath.Join(outside, "lib.go")
+	for _, path := range []string{secret, library} {
+		if err := os.WriteFile(path, []byte("contents\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	// A link under the source root doesn't make its target readable
+	link := filepath.Join(session.SourceRoot(), "link.txt")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+			StackFrames: []dap.StackFrame{{Id: 1000, Name: "lib.Call", Line: 1, Source: &dap.Source{Path: library}}},
+			TotalFrames: 1,
+		}}
+	})
+	fake.sendEvent(&dap.StoppedEvent{
+		Event: dap.Event{Event: "stopped"},
+		Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1},
+	})
+	for deadline := time.Now().Add(2 * time.Second); client.LastStop() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("the stop was not reported")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, path := range []string{secret, link, "/etc/passwd", filepath.Join(session.SourceRoot(), "..", filepath.Base(outside), "secret.txt")} {
+		text, isErr := callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": path})
+		if !isErr || !strings.Contains(text, "sourceReference") {
+			t.Errorf("expected %s to be refused, got %s", path, text)
+		}
+	}
+	if n := len(fake.received("source")); n != 0 {
+		t.Errorf("expected no source request for refused paths, got %d", n)
+	}
+
+	// The source of a frame the program stopped in can be read
+	text, isErr := callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": library})
+	if isErr || decodeResult(t, text)["content"] != "contents\n" {
+		t.Errorf("expected the frame's source to be read, got %s", text)
+	}
+}
+
+// TestDebugSource_LineRange verifies startLine/endLine slice both disk and
+// adapter sources and report absolute line numbers.
+func TestDebugSource_LineRange(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageJavaScript)
+
+	var numbered strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&numbered, "line %d\n", i)
+	}
+	fake.handle("source", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.SourceResponse{Body: dap.SourceResponseBody{Content: numbered.String()}}
+	})
+
+	root := t.TempDir()
+	session, _ := srv.GetSessionManager().GetSession(sessionID)
+	session.SetSourceRoot(root)
+	path := filepath.Join(root, "app.js")
+	if err := os.WriteFile(path, []byte(numbered.String()), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	for _, source := range []map[string]interface{}{
+		{"path": path},
+		{"sourceReference": 3},
+	} {
+		args := map[string]interface{}{"sessionId": sessionID, "startLine": 4, "endLine": 6}
+		for k, v := range source {
+			args[k] = v
+		}
+		text, isErr := callTool(t, srv, "debug_source", args)
+		if isErr {
+			t.Fatalf("debug_source %v failed: %s", source, text)
+		}
+		result := decodeResult(t, text)
+		if result["content"] != "line 4\nline 5\nline 6\n" {
+			t.Errorf("%v: expected lines 4-6, got %q", source, result["content"])
+		}
+		if result["startLine"] != float64(4) || result["endLine"] != float64(6) || result["totalLines"] != float64(10) {
+			t.Errorf("%v: expected absolute line numbers 4-6 of 10, got %v", source, result)
+		}
+	}
+
+	// endLine past the end is clamped
+	text, _ := callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": path, "startLine": 9, "endLine": 50})
+	if result := decodeResult(t, text); result["content"] != "line 9\nline 10\n" || result["endLine"] != float64(10) {
+		t.Errorf("expected lines 9-10, got %v", result)
+	}
+
+	// Without a range the whole file is returned
+	text, _ = callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": path})
+	if result := decodeResult(t, text); result["content"] != numbered.String() {
+		t.Errorf("expected whole file, got %q", result["content"])
+	}
+
+	if text, isErr := callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": path, "startLine": 11}); !isErr {
+		t.Errorf("expected error for startLine past the end, got %s", text)
+	}
+	if text, isErr := callTool(t, srv, "debug_source", map[string]interface{}{"sessionId": sessionID, "path": path, "startLine": 5, "endLine": 2}); !isErr {
+		t.Errorf("expected error for endLine before startLine, got %s", text)
+	}
+}
+
+// TestDebugFindSource verifies Python modules are resolved through the
+// interpreter and Go packages through Delve, with loaded sources searched too.
+func TestDebugFindSource(t *testing.T) {
+	t.Run("Python", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+		fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.InitializeResponse{Body: dap.Capabilities{SupportsLoadedSourcesRequest: true}}
+		})
+		if _, err := client.Initialize("test", "test"); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		scriptStoppedProgram(fake, new(int32), func() []dap.Variable { return nil })
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			switch req.(*dap.EvaluateRequest).Arguments.Expression {
+			case "__import__('sys').modules['requests.sessions'].__file__":
+				return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "'/site/requests/sessions.py'", Type: "str"}}
+			case "__import__('sys').modules['requests.sessions'].Session.send.__code__.co_firstlineno":
+				return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "612", Type: "int"}}
+			}
+			return &dap.ErrorResponse{Response: dap.Response{Message: "KeyError"}}
+		})
+		fake.handle("loadedSources", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.LoadedSourcesResponse{Body: dap.LoadedSourcesResponseBody{Sources: []dap.Source{
+				{Name: "sessions.py", Path: "/site/requests/sessions.py"},
+				{Name: "adapters.py", Path: "/site/requests/adapters.py"},
+			}}}
+		})
+
+		text, isErr := callTool(t, srv, "debug_find_source", map[string]interface{}{"sessionId": sessionID, "name": "requests.sessions.Session.send"})
+		if isErr {
+			t.Fatalf("debug_find_source failed: %s", text)
+		}
+		candidates := decodeResult(t, text)["candidates"].([]interface{})
+		if len(candidates) != 1 {
+			t.Fatalf("expected one candidate without duplicates, got %v", candidates)
+		}
+		if c := candidates[0].(map[string]interface{}); c["path"] != "/site/requests/sessions.py" || c["line"] != float64(612) || c["origin"] != "python" {
+			t.Errorf("expected the module file and the function's first line, got %v", c)
+		}
+
+		// Names are never put in an expression unless they are dotted identifiers
+		text, _ = callTool(t, srv, "debug_find_source", map[string]interface{}{"sessionId": sessionID, "name": "adapters"})
+		if candidates := decodeResult(t, text)["candidates"].([]interface{}); len(candidates) != 1 || candidates[0].(map[string]interface{})["origin"] != "loadedSources" {
+			t.Errorf("expected the loaded source matching by name, got %v", candidates)
+		}
+		before := len(fake.received("evaluate"))
+		callTool(t, srv, "debug_find_source", map[string]interface{}{"sessionId": sessionID, "name": "x'] or exit() or ['"})
+		if after := len(fake.received("evaluate")); after != before {
+			t.Errorf("expected no evaluation of an invalid name, got %d requests", after-before)
+		}
+	})
+
+	t.Run("Go", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+		scriptStoppedProgram(fake, new(int32), func() []dap.Variable { return nil })
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			args := req.(*dap.EvaluateRequest).Arguments
+			if args.Context != "repl" || args.Expression != `dlv sources /http/[^/]+\.go$` {
+				return &dap.ErrorResponse{Response: dap.Response{Message: "unexpected " + args.Expression}}
+			}
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "/go/src/net/http/client.go\n/go/src/net/http/server.go\n"}}
+		})
+
+		text, isErr := callTool(t, srv, "debug_find_source", map[string]interface{}{"sessionId": sessionID, "name": "net/http.Get"})
+		if isErr {
+			t.Fatalf("debug_find_source failed: %s", text)
+		}
+		candidates := decodeResult(t, text)["candidates"].([]interface{})
+		if len(candidates) != 2 || candidates[0].(map[string]interface{})["module"] != "net/http" {
+			t.Errorf("expected the package's two files, got %v", candidates)
+		}
+	})
+}
+
+// TestDebugContinueToReturn verifies the return value is read after stepping
+// out, and a note is given when the adapter exposes none.
+func TestDebugContinueToReturn(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	var returned atomic.Bool
+	scriptThreads(fake, 1)
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		frame := dap.StackFrame{Id: 1000, Name: "main.sum", Line: 8}
+		if returned.Load() {
+			frame = dap.StackFrame{Id: 1001, Name: "main.main", Line: 21}
+		}
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: []dap.StackFrame{frame}, TotalFrames: 1}}
+	})
+	fake.handle("stepOut", func(req dap.RequestMessage) dap.ResponseMessage {
+		returned.Store(true)
+		go fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body:  dap.StoppedEventBody{Reason: "step", ThreadId: 1},
+		})
+		return &dap.StepOutResponse{}
+	})
+	fake.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ScopesResponse{Body: dap.ScopesResponseBody{
+			Scopes: []dap.Scope{{Name: "Locals", VariablesReference: 2000}},
+		}}
+	})
+	locals := []dap.Variable{
+		{Name: "~r0", Value: "42", Type: "int"},
+		{Name: "total", Value: "7", Type: "int"},
+	}
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: locals}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_continue_to_return", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("continue_to_return failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["function"] != "main.sum" || result["returnValue"] != "42" || result["returnType"] != "int" {
+		t.Errorf("expected main.sum to return 42 (int), got %v", result)
+	}
+	if values := result["returnValues"].([]interface{}); len(values) != 1 {
+		t.Errorf("expected only the return value, got %v", values)
+	}
+	if location := result["location"].(map[string]interface{}); location["name"] != "main.main" {
+		t.Errorf("expected to stop in the caller, got %v", location)
+	}
+
+	// No return value exposed
+	returned.Store(false)
+	locals = []dap.Variable{{Name: "total", Value: "7", Type: "int"}}
+	text, isErr = callTool(t, srv, "debug_continue_to_return", map[string]interface{}{"sessionId": sessionID, "threadId": 1})
+	if isErr {
+		t.Fatalf("continue_to_return failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if _, ok := result["returnValue"]; ok || result["note"] == nil {
+		t.Errorf("expected a note instead of a return value, got %v", result)
+	}
+}
+
+// TestPresentationHints verifies variable presentation hints are reported in
+// snapshots, evaluations, and variable pages, and omitted when empty.
+func TestPresentationHints(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageTypeScript)
+
+	line := int32(3)
+	vars := []dap.Variable{
+		{Name: "MAX", Value: "10", Type: "number", PresentationHint: &dap.VariablePresentationHint{Kind: "data", Attributes: []string{"readOnly", "constant"}}},
+		{Name: "greet", Value: "ƒ greet()", Type: "function", PresentationHint: &dap.VariablePresentationHint{Kind: "method", Visibility: "private"}},
+		{Name: "count", Value: "1", Type: "number", PresentationHint: &dap.VariablePresentationHint{}},
+	}
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return vars })
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{
+			Result: "get size", Type: "number",
+			PresentationHint: &dap.VariablePresentationHint{Kind: "property", Lazy: true},
+		}}
+	})
+
+	hints := func(list []interface{}) map[string]interface{} {
+		byName := map[string]interface{}{}
+		for _, v := range list {
+			m := v.(map[string]interface{})
+			byName[m["name"].(string)] = m["presentationHint"]
+		}
+		return byName
+	}
+	checkHints := func(where string, got map[string]interface{}) {
+		t.Helper()
+		maxHint, _ := got["MAX"].(map[string]interface{})
+		if maxHint == nil || maxHint["kind"] != "data" || len(maxHint["attributes"].([]interface{})) != 2 {
+			t.Errorf("%s: expected readOnly constant hint for MAX, got %v", where, got["MAX"])
+		}
+		greetHint, _ := got["greet"].(map[string]interface{})
+		if greetHint == nil || greetHint["kind"] != "method" || greetHint["visibility"] != "private" {
+			t.Errorf("%s: expected private method hint for greet, got %v", where, got["greet"])
+		}
+		if got["count"] != nil {
+			t.Errorf("%s: expected empty hint to be omitted, got %v", where, got["count"])
+		}
+	}
+
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("snapshot failed: %s", text)
+	}
+	variables := decodeResult(t, text)["variables"].(map[string]interface{})
+	checkHints("snapshot", hints(variables["2000"].([]interface{})))
+
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "variablesReference": 2000, "filter": "named"})
+	if isErr {
+		t.Fatalf("variables page failed: %s", text)
+	}
+	checkHints("variables page", hints(decodeResult(t, text)["variables"].([]interface{})))
+
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": "obj.size", "frameId": 1000})
+	if isErr {
+		t.Fatalf("evaluate failed: %s", text)
+	}
+	hint, _ := decodeResult(t, text)["presentationHint"].(map[string]interface{})
+	if hint == nil || hint["kind"] != "property" || hint["lazy"] != true {
+		t.Errorf("expected lazy property hint, got %v", hint)
+	}
+}
+
+func TestDebugLaunch_VerifyBreakpoints(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv := newLaunchServer(t, fake, client, types.LanguagePython)
+
+	nextID := int32(0)
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.SetBreakpointsRequest).Arguments
+		bps := make([]dap.Breakpoint, len(args.Breakpoints))
+		for i, bp := range args.Breakpoints {
+			bps[i] = dap.Breakpoint{Id: int(atomic.AddInt32(&nextID, 1)), Line: bp.Line, Message: "pending: module not yet loaded"}
+		}
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{Breakpoints: bps}}
+	})
+	fake.handle("configurationDone", func(req dap.RequestMessage) dap.ResponseMessage {
+		// The first breakpoint is verified once the program loads; the second never is
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			fake.sendEvent(&dap.BreakpointEvent{
+				Event: dap.Event{Event: "breakpoint"},
+				Body:  dap.BreakpointEventBody{Reason: "changed", Breakpoint: dap.Breakpoint{Id: 1, Line: 10, Verified: true}},
+			})
+		}()
+		return &dap.ConfigurationDoneResponse{}
+	})
+
+	// Invalid breakpoints are rejected before anything is spawned
+	for _, bps := range []string{`not json`, `[{"line": 10}]`, `[{"path": "/src/app.py", "line": 0}]`} {
+		if text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "python", "program": "/src/app.py", "breakpoints": bps,
+		}); !isErr {
+			t.Fatalf("expected breakpoints %s to be rejected, got %s", bps, text)
+		}
+	}
+	if got := len(srv.GetSessionManager().ListSessions()); got != 0 {
+		t.Fatalf("expected no sessions after rejected launches, got %d", got)
+	}
+
+	text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+		"language":          "python",
+		"program":           "/src/app.py",
+		"breakpoints":       `[{"path": "/src/app.py", "line": 10}, {"path": "/src/app.py", "line": 20, "condition": "x > 1"}]`,
+		"verifyBreakpoints": true,
+	})
+	if isErr {
+		t.Fatalf("debug_launch failed: %s", text)
+	}
+
+	if got := len(fake.received("setBreakpoints")); got != 1 {
+		t.Fatalf("expected breakpoints to be set once during configuration, got %d", got)
+	}
+
+	report, ok := decodeResult(t, text)["breakpointReport"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a breakpointReport, got %s", text)
+	}
+	bps, _ := report["breakpoints"].([]interface{})
+	if report["verified"] != float64(1) || report["unverified"] != float64(1) || len(bps) != 2 {
+		t.Fatalf("expected 1 verified and 1 unverified breakpoint, got %s", text)
+	}
+	if bp := bps[0].(map[string]interface{}); bp["line"] != float64(10) || bp["verified"] != true {
+		t.Errorf("expected line 10 to be verified, got %v", bp)
+	}
+	if bp := bps[1].(map[string]interface{}); bp["line"] != float64(20) || bp["verified"] != false || bp["condition"] != "x > 1" || bp["reason"] != "pending" {
+		t.Errorf("expected line 20 to be pending with its condition, got %v", bp)
+	}
+}
+
+func TestDebugLaunch_StringArgs(t *testing.T) {
+	testCases := []struct {
+		args string
+		want string
+	}{
+		{`--name "John Smith" --dir '/tmp/my dir' -v`, `["--name","John Smith","--dir","/tmp/my dir","-v"]`},
+		{`["--name", "John Smith"]`, `["--name","John Smith"]`},
+	}
+
+	for _, tc := range testCases {
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguagePython)
+
+		if text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "python", "program": "/src/app.py", "args": tc.args,
+		}); isErr {
+			t.Fatalf("debug_launch with args %s failed: %s", tc.args, text)
+		}
+
+		launches := fake.received("launch")
+		if len(launches) != 1 {
+			t.Fatalf("expected one launch request, got %d", len(launches))
+		}
+		if got := string(launches[0].(*dap.LaunchRequest).Arguments); !strings.Contains(got, `"args":`+tc.want) {
+			t.Errorf("args %s: expected launch arguments to contain %s, got %s", tc.args, tc.want, got)
+		}
+	}
+
+	// Malformed args are rejected before the adapter is spawned
+	fake, client := newFakeAdapter(t)
+	srv := newLaunchServer(t, fake, client, types.LanguagePython)
+	for _, args := range []string{`--name "John`, `["--name", 1]`} {
+		if text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "python", "program": "/src/app.py", "args": args,
+		}); !isErr {
+			t.Errorf("expected args %s to be rejected, got %s", args, text)
+		}
+	}
+	if got := len(fake.received("initialize")); got != 0 {
+		t.Errorf("expected no launch after rejected args, got %d initialize requests", got)
+	}
+}
+
+// TestDebugLaunch_WithoutConfigurationDone verifies that adapters answering
+// launch without sending initialized are launched without configurationDone.
+func TestDebugLaunch_WithoutConfigurationDone(t *testing.T) {
+	launch := func(t *testing.T, hint bool) *fakeAdapter {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguagePython)
+		if hint {
+			srv.GetAdapterRegistry().Register(types.LanguagePython, &syncAdapter{
+				launchableAdapter: &launchableAdapter{lang: types.LanguagePython, client: client},
+			})
+		}
+		// No initialized event
+		fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.InitializeResponse{}
+		})
+
+		start := time.Now()
+		text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "python", "program": "/src/app.py",
+		})
+		if isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("expected the launch not to wait for initialized, took %v", elapsed)
+		}
+		if result := decodeResult(t, text); result["status"] != "launched" {
+			t.Errorf("expected a launched session, got %v", result)
+		}
+		if got := len(fake.received("configurationDone")); got != 0 {
+			t.Errorf("expected no configurationDone, got %d", got)
+		}
+		return fake
+	}
+
+	t.Run("hint", func(t *testing.T) {
+		launch(t, true)
+	})
+
+	// Adapters without the hint fall back when launch is answered first
+	t.Run("fallback", func(t *testing.T) {
+		launch(t, false)
+	})
+}
+
+// TestDebugLaunch_ConfigurationDoneUnsupported verifies configurationDone is
+// not sent to adapters that don't report supportsConfigurationDoneRequest.
+func TestDebugLaunch_ConfigurationDoneUnsupported(t *testing.T) {
+	limited := func(t *testing.T, lang types.Language) (*fakeAdapter, *dapmcp.Server) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, lang)
+		fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				fake.sendEvent(&dap.InitializedEvent{Event: dap.Event{Event: "initialized"}})
+			}()
+			return &dap.InitializeResponse{}
+		})
+		fake.handle("configurationDone", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.ErrorResponse{Response: dap.Response{Message: "unrecognized request"}}
+		})
+		return fake, srv
+	}
+
+	t.Run("launch", func(t *testing.T) {
+		fake, srv := limited(t, types.LanguagePython)
+		text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "python", "program": "/src/app.py",
+		})
+		if isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		if got := len(fake.received("configurationDone")); got != 0 {
+			t.Errorf("expected no configurationDone, got %d", got)
+		}
+	})
+
+	t.Run("attach", func(t *testing.T) {
+		fake, srv := limited(t, types.LanguageGo)
+		fake.handle("attach", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.AttachResponse{}
+		})
+		text, isErr := callTool(t, srv, "debug_attach", map[string]interface{}{
+			"language": "go", "pid": 4242,
+		})
+		if isErr {
+			t.Fatalf("debug_attach failed: %s", text)
+		}
+		if got := len(fake.received("configurationDone")); got != 0 {
+			t.Errorf("expected no configurationDone, got %d", got)
+		}
+	})
+}
+
+func TestDebugLaunch_StopOnEntry(t *testing.T) {
+	// stopAfterConfigurationDone scripts an adapter whose first stop has the
+	// given reason, "entry" for a stop at entry
+	stopAfterConfigurationDone := func(fake *fakeAdapter, reason string) {
+		fake.handle("configurationDone", func(req dap.RequestMessage) dap.ResponseMessage {
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				fake.sendEvent(&dap.StoppedEvent{
+					Event: dap.Event{Event: "stopped"},
+					Body:  dap.StoppedEventBody{Reason: reason, ThreadId: 1, AllThreadsStopped: true},
+				})
+			}()
+			return &dap.ConfigurationDoneResponse{}
+		})
+		fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.ContinueResponse{Body: dap.ContinueResponseBody{AllThreadsContinued: true}}
+		})
+	}
+	launchStoppingFor := func(t *testing.T, behavior adapters.EntryBehavior, stopOnEntry bool, reason string) (*fakeAdapter, map[string]interface{}) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguagePython)
+		srv.GetAdapterRegistry().Register(types.LanguagePython, &entryAdapter{
+			launchableAdapter: &launchableAdapter{lang: types.LanguagePython, client: client},
+			behavior:          behavior,
+		})
+		if behavior != adapters.EntryIgnored {
+			stopAfterConfigurationDone(fake, reason)
+		}
+
+		text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "python", "program": "/src/app.py", "stopOnEntry": stopOnEntry,
+		})
+		if isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		return fake, decodeResult(t, text)
+	}
+	launch := func(t *testing.T, behavior adapters.EntryBehavior, stopOnEntry bool) (*fakeAdapter, map[string]interface{}) {
+		t.Helper()
+		return launchStoppingFor(t, behavior, stopOnEntry, "entry")
+	}
+
+	t.Run("honored", func(t *testing.T) {
+		fake, result := launch(t, adapters.EntryHonored, true)
+		if result["stoppedAtEntry"] != true || result["reason"] != "entry" || result["status"] != "stopped" {
+			t.Errorf("expected a stop at entry, got %v", result)
+		}
+		if result["threadId"] != float64(1) {
+			t.Errorf("expected threadId 1, got %v", result["threadId"])
+		}
+		if got := len(fake.received("continue")); got != 0 {
+			t.Errorf("expected the program to stay paused, got %d continue requests", got)
+		}
+	})
+
+	t.Run("forced without stopOnEntry", func(t *testing.T) {
+		fake, result := launch(t, adapters.EntryForced, false)
+		if result["stoppedAtEntry"] != nil || result["status"] != "launched" {
+			t.Errorf("expected a running launch, got %v", result)
+		}
+		reqs := fake.received("continue")
+		if len(reqs) != 1 {
+			t.Fatalf("expected the forced entry stop to be continued, got %d continue requests", len(reqs))
+		}
+		if got := reqs[0].(*dap.ContinueRequest).Arguments.ThreadId; got != 1 {
+			t.Errorf("expected continue on thread 1, got %d", got)
+		}
+	})
+
+	// A breakpoint in code that runs first pauses the program before the
+	// forced entry stop, so the session stays paused there
+	t.Run("forced with a breakpoint first", func(t *testing.T) {
+		fake, result := launchStoppingFor(t, adapters.EntryForced, false, "breakpoint")
+		if result["status"] != "stopped" || result["reason"] != "breakpoint" || result["stoppedAtEntry"] != nil {
+			t.Errorf("expected the session paused at the breakpoint, got %v", result)
+		}
+		if got := len(fake.received("continue")); got != 0 {
+			t.Errorf("expected the breakpoint stop not to be continued, got %d continue requests", got)
+		}
+	})
+
+	t.Run("honored with a breakpoint first", func(t *testing.T) {
+		_, result := launchStoppingFor(t, adapters.EntryHonored, true, "breakpoint")
+		if result["status"] != "stopped" || result["reason"] != "breakpoint" || result["stoppedAtEntry"] != nil {
+			t.Errorf("expected the session paused at the breakpoint, not at entry, got %v", result)
+		}
+	})
+
+	t.Run("ignored without an entry point", func(t *testing.T) {
+		_, result := launch(t, adapters.EntryIgnored, true)
+		if result["stoppedAtEntry"] != nil {
+			t.Errorf("expected no stop at entry, got %v", result)
+		}
+		if note, _ := result["entryNote"].(string); note == "" {
+			t.Errorf("expected an entryNote explaining the missing stop, got %v", result)
+		}
+	})
+}
+
+// TestDebugLaunch_StopOnEntryFunction verifies stopOnEntry continues from an
+// adapter's entry stop in the runtime to its entry function.
+func TestDebugLaunch_StopOnEntryFunction(t *testing.T) {
+	launch := func(t *testing.T, next dap.StackFrame, reason string) (*fakeAdapter, map[string]interface{}) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguageGo)
+		srv.GetAdapterRegistry().Register(types.LanguageGo, &entryFunctionAdapter{
+			launchableAdapter: &launchableAdapter{lang: types.LanguageGo, client: client},
+			function:          "main.main",
+		})
+
+		var continued atomic.Bool
+		fake.handle("setFunctionBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.SetFunctionBreakpointsResponse{}
+		})
+		fake.handle("configurationDone", func(req dap.RequestMessage) dap.ResponseMessage {
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				fake.sendEvent(&dap.StoppedEvent{
+					Event: dap.Event{Event: "stopped"},
+					Body:  dap.StoppedEventBody{Reason: "entry", ThreadId: 1, AllThreadsStopped: true},
+				})
+			}()
+			return &dap.ConfigurationDoneResponse{}
+		})
+		fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+			frame := dap.StackFrame{Id: 1000, Name: "runtime.rt0_go", Line: 16}
+			if continued.Load() {
+				frame = next
+			}
+			return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: []dap.StackFrame{frame}, TotalFrames: 1}}
+		})
+		fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+			continued.Store(true)
+			go fake.sendEvent(&dap.StoppedEvent{
+				Event: dap.Event{Event: "stopped"},
+				Body:  dap.StoppedEventBody{Reason: reason, ThreadId: 1, AllThreadsStopped: true},
+			})
+			return &dap.ContinueResponse{Body: dap.ContinueResponseBody{AllThreadsContinued: true}}
+		})
+
+		text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "go", "program": "/src/app", "stopOnEntry": true,
+		})
+		if isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		return fake, decodeResult(t, text)
+	}
+
+	t.Run("main", func(t *testing.T) {
+		main := dap.StackFrame{Id: 1001, Name: "main.main", Line: 5, Source: &dap.Source{Path: "/src/app/main.go"}}
+		fake, result := launch(t, main, "function breakpoint")
+		if result["stoppedAtEntry"] != true || result["reason"] != "entry" || result["entryNote"] != nil {
+			t.Errorf("expected a stop at entry, got %v", result)
+		}
+		frame, _ := result["entryFrame"].(map[string]interface{})
+		if frame["name"] != "main.main" || frame["line"] != float64(5) || frame["path"] != "/src/app/main.go" {
+			t.Errorf("expected the entry frame to be main.main, got %v", result["entryFrame"])
+		}
+		if got := len(fake.received("continue")); got != 1 {
+			t.Errorf("expected one continue past the runtime entry stop, got %d", got)
+		}
+
+		reqs := fake.received("setFunctionBreakpoints")
+		if len(reqs) != 2 {
+			t.Fatalf("expected the entry breakpoint to be set and cleared, got %d requests", len(reqs))
+		}
+		if bps := reqs[0].(*dap.SetFunctionBreakpointsRequest).Arguments.Breakpoints; len(bps) != 1 || bps[0].Name != "main.main" {
+			t.Errorf("expected a breakpoint on main.main, got %v", bps)
+		}
+		if bps := reqs[1].(*dap.SetFunctionBreakpointsRequest).Arguments.Breakpoints; len(bps) != 0 {
+			t.Errorf("expected the entry breakpoint to be cleared, got %v", bps)
+		}
+	})
+
+	// A breakpoint in an init function is hit before main.main
+	t.Run("breakpoint first", func(t *testing.T) {
+		init := dap.StackFrame{Id: 1002, Name: "main.init.0", Line: 9}
+		_, result := launch(t, init, "breakpoint")
+		if result["status"] != "stopped" || result["reason"] != "breakpoint" {
+			t.Errorf("expected the session paused at the breakpoint, got %v", result)
+		}
+		if note, _ := result["entryNote"].(string); !strings.Contains(note, "before reaching main.main") {
+			t.Errorf("expected an entryNote about stopping before main.main, got %v", result)
+		}
+	})
+}
+
+// TestDebugEvaluate_NameContains verifies children are searched by name
+// across pages, up to maxPages pages, reporting whether the scan finished.
+func TestDebugEvaluate_NameContains(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		if req.(*dap.EvaluateRequest).Arguments.Expression == "samples" {
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "[]int len: 2500", VariablesReference: 12, IndexedVariables: 2500}}
+		}
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "main.Config {...}", VariablesReference: 14, NamedVariables: 3}}
+	})
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.VariablesRequest).Arguments
+		if args.VariablesReference == 14 {
+			return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: []dap.Variable{
+				{Name: "ReadTimeout", Value: "5s"}, {Name: "WriteTimeout", Value: "10s"}, {Name: "Addr", Value: "\":8080\""},
+			}}}
+		}
+		var vars []dap.Variable
+		for i := args.Start; i < args.Start+args.Count && i < 2500; i++ {
+			vars = append(vars, dap.Variable{Name: fmt.Sprintf("[%d]", i), Value: fmt.Sprint(i)})
+		}
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: vars}}
+	})
+
+	for _, expr := range []string{"cfg", "samples"} {
+		if text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": expr}); isErr {
+			t.Fatalf("evaluate %s failed: %s", expr, text)
+		}
+	}
+
+	text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 14,
+		"nameContains":       "timeout",
+	})
+	if isErr {
+		t.Fatalf("named search failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["matches"] != float64(2) || result["scanComplete"] != true || result["scanned"] != float64(3) {
+		t.Errorf("expected 2 of 3 fields matched in a complete scan, got %v", result)
+	}
+
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 12,
+		"nameContains":       `^\[7\d*\]$`,
+		"nameRegex":          true,
+		"maxPages":           2,
+	})
+	if isErr {
+		t.Fatalf("indexed search failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	// [7], [70]-[79], and [700]-[799] are in the first 2000 elements
+	if result["matches"] != float64(111) || result["scanned"] != float64(2000) {
+		t.Errorf("expected 111 matches in 2000 elements, got %v matches in %v", result["matches"], result["scanned"])
+	}
+	if result["scanComplete"] != false || result["nextStart"] != float64(2000) {
+		t.Errorf("expected an incomplete scan resuming at 2000, got %v and %v", result["scanComplete"], result["nextStart"])
+	}
+
+	// maxPages is capped at 100 pages
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 12,
+		"nameContains":       "missing",
+		"count":              10,
+		"maxPages":           1000,
+	})
+	if isErr {
+		t.Fatalf("capped search failed: %s", text)
+	}
+	if result = decodeResult(t, text); result["pages"] != float64(100) || result["nextStart"] != float64(1000) {
+		t.Errorf("expected maxPages capped at 100 pages, got %v pages resuming at %v", result["pages"], result["nextStart"])
+	}
+
+	// At most 500 matches are returned, resuming after the last one
+	text, isErr = callTool(t, srv, "debug_evaluate", map[string]interface{}{
+		"sessionId":          sessionID,
+		"variablesReference": 12,
+		"nameContains":       "[",
+	})
+	if isErr {
+		t.Fatalf("truncated search failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if result["matches"] != float64(500) || result["truncated"] != true || result["nextStart"] != float64(500) {
+		t.Errorf("expected 500 matches resuming at 500, got %v matches, truncated=%v, nextStart=%v", result["matches"], result["truncated"], result["nextStart"])
+	}
+}
+
+// TestDebugWaitForOutput verifies the program is resumed until a line of new
+// output matches, and that a stop before any match ends the wait.
+func TestDebugWaitForOutput(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.sendEvent(&dap.OutputEvent{Event: dap.Event{Event: "output"}, Body: dap.OutputEventBody{Category: "stdout", Output: "listening on :9090 (old)\n"}})
+	deadline := time.Now().Add(2 * time.Second)
+	for client.OutputSeq() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var stopNext atomic.Bool
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			if stopNext.Load() {
+				fake.sendEvent(&dap.StoppedEvent{Event: dap.Event{Event: "stopped"}, Body: dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1}})
+				return
+			}
+			fake.sendEvent(&dap.OutputEvent{Event: dap.Event{Event: "output"}, Body: dap.OutputEventBody{Category: "stdout", Output: "starting\nlistening on :8080\n"}})
+		}()
+		return &dap.ContinueResponse{Body: dap.ContinueResponseBody{AllThreadsContinued: true}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_wait_for_output", map[string]interface{}{
+		"sessionId": sessionID,
+		"pattern":   `listening on :\d+$`,
+		"threadId":  1,
+		"timeoutMs": 2000,
+	})
+	if isErr {
+		t.Fatalf("wait for output failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["matched"] != true || result["line"] != "listening on :8080" || result["status"] != "running" {
+		t.Errorf("expected the new listening line while running, got %v", result)
+	}
+
+	// Stop the program so the next call resumes it again
+	fake.sendEvent(&dap.StoppedEvent{Event: dap.Event{Event: "stopped"}, Body: dap.StoppedEventBody{Reason: "pause", ThreadId: 1}})
+	deadline = time.Now().Add(2 * time.Second)
+	for client.Running() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stopNext.Store(true)
+
+	text, isErr = callTool(t, srv, "debug_wait_for_output", map[string]interface{}{
+		"sessionId": sessionID,
+		"pattern":   "ready",
+		"timeoutMs": 2000,
+	})
+	if isErr {
+		t.Fatalf("wait for output failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if result["matched"] != false || result["status"] != "stopped" || result["reason"] != "breakpoint" {
+		t.Errorf("expected the wait to end at the breakpoint, got %v", result)
+	}
+	if reqs := fake.received("continue"); len(reqs) != 2 {
+		t.Errorf("expected 2 continue requests, got %d", len(reqs))
+	}
+}
+
+// TestDebugCallFunction verifies calls are sent as Delve call commands on the
+// stopped thread's top frame, and that unsupported sessions are reported.
+func TestDebugCallFunction(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	scriptThreads(fake, 7)
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+			StackFrames: []dap.StackFrame{{Id: 1007, Name: "main.main", Line: 12}},
+			TotalFrames: 1,
+		}}
+	})
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		if req.(*dap.EvaluateRequest).Arguments.Expression == "call main.broken()" {
+			return &dap.ErrorResponse{Response: dap.Response{Message: "backend does not support function calls"}}
+		}
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: `"HELLO"`, Type: "string"}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_call_function", map[string]interface{}{
+		"sessionId": sessionID,
+		"function":  "strings.ToUpper",
+		"args":      `["name"]`,
+	})
+	if isErr {
+		t.Fatalf("call function failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if result["result"] != `"HELLO"` || result["type"] != "string" || result["frameId"] != float64(1007) {
+		t.Errorf("unexpected result: %v", result)
+	}
+	reqs := fake.received("evaluate")
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 evaluate request, got %d", len(reqs))
+	}
+	args := reqs[0].(*dap.EvaluateRequest).Arguments
+	if args.Expression != "call strings.ToUpper(name)" || args.Context != "repl" || args.FrameId != 1007 {
+		t.Errorf("expected call command in repl on frame 1007, got %q in %q on %d", args.Expression, args.Context, args.FrameId)
+	}
+
+	text, isErr = callTool(t, srv, "debug_call_function", map[string]interface{}{
+		"sessionId": sessionID,
+		"function":  "main.broken",
+		"frameId":   1007,
+	})
+	if !isErr || !strings.Contains(text, "Delve can't call functions") {
+		t.Errorf("expected unsupported call error, got %s", text)
+	}
+
+	_, pyClient := newFakeAdapter(t)
+	pySrv, pySession := newTestServer(t, pyClient, types.LanguagePython)
+	text, isErr = callTool(t, pySrv, "debug_call_function", map[string]interface{}{
+		"sessionId": pySession,
+		"function":  "print",
+	})
+	if !isErr || !strings.Contains(text, "only works with Go sessions") {
+		t.Errorf("expected Go-only error, got %s", text)
+	}
+}
+
+func TestDebugSetDebugOptions(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv := newLaunchServer(t, fake, client, types.LanguagePython)
+
+	text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+		"language": "python", "program": "/src/app.py", "justMyCode": true,
+	})
+	if isErr {
+		t.Fatalf("debug_launch failed: %s", text)
+	}
+	sessionID := decodeResult(t, text)["sessionId"].(string)
+
+	setJustMyCode := func(value bool) map[string]interface{} {
+		t.Helper()
+		text, isErr := callTool(t, srv, "debug_set_debug_options", map[string]interface{}{
+			"sessionId": sessionID, "justMyCode": value,
+		})
+		if isErr {
+			t.Fatalf("debug_set_debug_options failed: %s", text)
+		}
+		return decodeResult(t, text)
+	}
+
+	// The running program keeps the setting it was launched with
+	for i := 0; i < 2; i++ {
+		result := setJustMyCode(false)
+		if got := result["options"].(map[string]interface{})["justMyCode"]; got != false {
+			t.Errorf("expected justMyCode false for later launches, got %v", got)
+		}
+		if got := result["running"].(map[string]interface{})["justMyCode"]; got != true {
+			t.Errorf("expected the running program to keep justMyCode true, got %v", got)
+		}
+		if result["pending"] != true || result["note"] == nil {
+			t.Errorf("expected a pending change with a note, got %v", result)
+		}
+	}
+
+	// Setting it back to what is running leaves nothing pending
+	if result := setJustMyCode(true); result["pending"] != nil {
+		t.Errorf("expected nothing pending, got %v", result)
+	}
+
+	// Only debugpy has justMyCode
+	_, goClient := newFakeAdapter(t)
+	goSrv, goSessionID := newTestServer(t, goClient, types.LanguageGo)
+	if text, isErr := callTool(t, goSrv, "debug_set_debug_options", map[string]interface{}{
+		"sessionId": goSessionID, "justMyCode": false,
+	}); !isErr {
+		t.Errorf("expected justMyCode to be rejected for a Go session, got %s", text)
+	}
+}
+
+// TestDebugSetFunctionBreakpoints verifies function breakpoints by name and,
+// in native sessions, by regex with the resolved locations.
+func TestDebugSetFunctionBreakpoints(t *testing.T) {
+	t.Run("names", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+		text, isErr := callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+			"sessionId": sessionID,
+			"functions": `["main.run"]`,
+		})
+		if !isErr || !strings.Contains(text, "function breakpoints") {
+			t.Errorf("expected an error without the capability, got %s", text)
+		}
+
+		fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.InitializeResponse{Body: dap.Capabilities{SupportsFunctionBreakpoints: true}}
+		})
+		if _, err := client.Initialize("test", "test"); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		fake.handle("setFunctionBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.SetFunctionBreakpointsResponse{Body: dap.SetFunctionBreakpointsResponseBody{
+				Breakpoints: []dap.Breakpoint{
+					{Id: 1, Verified: true, Source: &dap.Source{Path: "/src/main.go"}, Line: 12},
+					{Id: 2, Verified: false, Message: "could not find function"},
+				},
+			}}
+		})
+
+		text, isErr = callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+			"sessionId": sessionID,
+			"functions": `["main.run", {"name": "main.missing", "condition": "n > 1"}]`,
+		})
+		if isErr {
+			t.Fatalf("set function breakpoints failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		if result["verified"] != float64(1) {
+			t.Errorf("expected 1 verified breakpoint, got %v", result["verified"])
+		}
+		first := result["breakpoints"].([]interface{})[0].(map[string]interface{})
+		if first["function"] != "main.run" || first["line"] != float64(12) {
+			t.Errorf("expected main.run at line 12, got %v", first)
+		}
+
+		args := fake.received("setFunctionBreakpoints")[0].(*dap.SetFunctionBreakpointsRequest).Arguments
+		if len(args.Breakpoints) != 2 || args.Breakpoints[1].Condition != "n > 1" {
+			t.Errorf("expected both breakpoints with the condition, got %+v", args.Breakpoints)
+		}
+		session, _ := srv.GetSessionManager().GetSession(sessionID)
+		if got := session.FunctionBreakpoints(); len(got) != 2 {
+			t.Errorf("expected the session to track 2 function breakpoints, got %v", got)
+		}
+	})
+
+	t.Run("regex lldb", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguageCpp)
+		_ = srv.GetSessionManager().SetSessionDebugger(sessionID, "lldb")
+
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			expr := req.(*dap.EvaluateRequest).Arguments.Expression
+			out := "Breakpoint 4: 2 locations."
+			if strings.HasPrefix(expr, "`breakpoint list") {
+				out = "4: regex = 'Foo::bar', locations = 2\n" +
+					"  4.1: where = app`Foo::bar(int) + 8 at foo.cpp:10:3, address = 0x0000000100003f28, unresolved, hit count = 0\n" +
+					"  4.2: where = app`Foo::bar(double) + 12 at foo.cpp:14:3, address = 0x0000000100003f50, unresolved, hit count = 0"
+			}
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: out}}
+		})
+
+		text, isErr := callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+			"sessionId": sessionID,
+			"functions": `["Foo::bar\\("]`,
+			"regex":     true,
+		})
+		if isErr {
+			t.Fatalf("regex breakpoints failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		if result["locations"] != float64(2) {
+			t.Errorf("expected 2 locations, got %v", result["locations"])
+		}
+		bp := result["breakpoints"].([]interface{})[0].(map[string]interface{})
+		locations := bp["locations"].([]interface{})
+		if len(locations) != 2 || locations[1] != "app`Foo::bar(double) + 12 at foo.cpp:14:3" {
+			t.Errorf("unexpected locations: %v", locations)
+		}
+		if expr := fake.received("evaluate")[0].(*dap.EvaluateRequest).Arguments.Expression; expr != "`breakpoint set --func-regex 'Foo::bar\\('" {
+			t.Errorf("unexpected command %q", expr)
+		}
+	})
+
+	t.Run("regex gdb", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguageC)
+		_ = srv.GetSessionManager().SetSessionDebugger(sessionID, "gdb")
+
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "Breakpoint 2 at 0x1149: file util.c, line 5.\n" +
+				"int util_parse(const char *);\n" +
+				"Breakpoint 3 at 0x1170: file util.c, line 12.\n" +
+				"int util_print(int);"}}
+		})
+
+		text, isErr := callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+			"sessionId": sessionID,
+			"functions": `["^util_"]`,
+			"regex":     true,
+		})
+		if isErr {
+			t.Fatalf("regex breakpoints failed: %s", text)
+		}
+		bp := decodeResult(t, text)["breakpoints"].([]interface{})[0].(map[string]interface{})
+		if bp["count"] != float64(2) || fmt.Sprint(bp["ids"]) != "[2 3]" {
+			t.Errorf("expected breakpoints 2 and 3, got %v", bp)
+		}
+		if expr := fake.received("evaluate")[0].(*dap.EvaluateRequest).Arguments.Expression; expr != "rbreak ^util_" {
+			t.Errorf("unexpected command %q", expr)
+		}
+	})
+
+	t.Run("regex unsupported", func(t *testing.T) {
+		_, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguagePython)
+
+		text, isErr := callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+			"sessionId": sessionID,
+			"functions": `["handle_.*"]`,
+			"regex":     true,
+		})
+		if !isErr || !strings.Contains(text, "GDB or LLDB") {
+			t.Errorf("expected regex to be rejected outside native sessions, got %s", text)
+		}
+	})
+
+	t.Run("regex injection", func(t *testing.T) {
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguageC)
+		_ = srv.GetSessionManager().SetSessionDebugger(sessionID, "gdb")
+
+		for _, pattern := range []string{`^util_\nshell rm -rf /`, `^util_\rshell id`, `foo\u0000`} {
+			text, isErr := callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+				"sessionId": sessionID,
+				"functions": `["` + pattern + `"]`,
+				"regex":     true,
+			})
+			if !isErr || !strings.Contains(text, "control characters") {
+				t.Errorf("expected %s to be rejected, got %s", pattern, text)
+			}
+		}
+		if got := fake.received("evaluate"); len(got) != 0 {
+			t.Errorf("expected no debugger command, got %d evaluate requests", len(got))
+		}
+
+		// debug_launch checks its regexBreakpoints before starting anything
+		launchSrv := dapmcp.NewServer(config.DefaultConfig(), nil)
+		t.Cleanup(launchSrv.Close)
+		text, isErr := callTool(t, launchSrv, "debug_launch", map[string]interface{}{
+			"language":         "c",
+			"debugger":         "gdb",
+			"program":          "/path/to/program",
+			"regexBreakpoints": `["^util_\nshell id"]`,
+		})
+		if !isErr || !strings.Contains(text, "control characters") {
+			t.Errorf("expected debug_launch to reject the pattern, got %s", text)
+		}
+	})
+
+	t.Run("regex read-only evaluation", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.AllowExecute = false
+		cfg.EvaluateReadOnly = true
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServerWithConfig(t, cfg, client, types.LanguageC)
+		_ = srv.GetSessionManager().SetSessionDebugger(sessionID, "gdb")
+
+		text, isErr := callTool(t, srv, "debug_set_function_breakpoints", map[string]interface{}{
+			"sessionId": sessionID,
+			"functions": `["^util_"]`,
+			"regex":     true,
+		})
+		if !isErr || !strings.Contains(text, "repl evaluation") {
+			t.Errorf("expected regex breakpoints to be refused, got %s", text)
+		}
+		if got := fake.received("evaluate"); len(got) != 0 {
+			t.Errorf("expected no debugger command, got %d evaluate requests", len(got))
+		}
+
+		launchSrv := dapmcp.NewServer(cfg, nil)
+		t.Cleanup(launchSrv.Close)
+		text, isErr = callTool(t, launchSrv, "debug_launch", map[string]interface{}{
+			"language":         "c",
+			"debugger":         "gdb",
+			"program":          "/path/to/program",
+			"regexBreakpoints": `["^util_"]`,
+		})
+		if !isErr || !strings.Contains(text, "repl evaluation") {
+			t.Errorf("expected debug_launch to refuse regexBreakpoints, got %s", text)
+		}
+	})
+}
+
+// TestDebugExportImportSession verifies a launched session's export carries
+// its launch arguments and breakpoints, and that importing it launches a new
+// session with them set before the program runs.
+func TestDebugExportImportSession(t *testing.T) {
+	launchServer := func(t *testing.T) (*fakeAdapter, *dapmcp.Server) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguagePython)
+		fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.SetBreakpointsResponse{}
+		})
+		fake.handle("setFunctionBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.SetFunctionBreakpointsResponse{}
+		})
+		return fake, srv
+	}
+
+	_, srv := launchServer(t)
+	text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+		"language":            "python",
+		"program":             "/src/app.py",
+		"args":                `["--verbose"]`,
+		"breakpoints":         `[{"path": "/src/app.py", "line": 10, "condition": "n > 1"}]`,
+		"functionBreakpoints": `["handler"]`,
+	})
+	if isErr {
+		t.Fatalf("debug_launch failed: %s", text)
+	}
+	sessionID, _ := decodeResult(t, text)["sessionId"].(string)
+
+	text, isErr = callTool(t, srv, "debug_export_session", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("debug_export_session failed: %s", text)
+	}
+	exported, _ := decodeResult(t, text)["session"].(map[string]interface{})
+	launch, _ := exported["launch"].(map[string]interface{})
+	if exported["version"] != float64(1) || exported["language"] != "python" || exported["program"] != "/src/app.py" {
+		t.Errorf("unexpected export header: %v", exported)
+	}
+	if launch["args"] != `["--verbose"]` {
+		t.Errorf("expected the launch args in the export, got %v", launch)
+	}
+	if _, ok := launch["breakpoints"]; ok {
+		t.Errorf("expected breakpoints outside the launch arguments, got %v", launch)
+	}
+	if got := fmt.Sprint(exported["breakpoints"]); got != "[map[condition:n > 1 line:10 path:/src/app.py]]" {
+		t.Errorf("unexpected exported breakpoints: %s", got)
+	}
+	if got := fmt.Sprint(exported["functionBreakpoints"]); got != "[map[name:handler]]" {
+		t.Errorf("unexpected exported function breakpoints: %s", got)
+	}
+
+	blob, err := json.Marshal(exported)
+	if err != nil {
+		t.Fatalf("marshal export: %v", err)
+	}
+	fake, srv := launchServer(t)
+	text, isErr = callTool(t, srv, "debug_import_session", map[string]interface{}{"session": string(blob)})
+	if isErr {
+		t.Fatalf("debug_import_session failed: %s", text)
+	}
+	if result := decodeResult(t, text); result["status"] != "launched" {
+		t.Errorf("expected a launched session, got %v", result)
+	}
+	if got := len(fake.received("launch")); got != 1 {
+		t.Errorf("expected one launch request, got %d", got)
+	}
+	setBreakpoints := fake.received("setBreakpoints")
+	if len(setBreakpoints) != 1 {
+		t.Fatalf("expected one setBreakpoints request, got %d", len(setBreakpoints))
+	}
+	if args := setBreakpoints[0].(*dap.SetBreakpointsRequest).Arguments; args.Source.Path != "/src/app.py" ||
+		len(args.Breakpoints) != 1 || args.Breakpoints[0].Line != 10 || args.Breakpoints[0].Condition != "n > 1" {
+		t.Errorf("unexpected setBreakpoints arguments: %+v", args)
+	}
+	setFunctions := fake.received("setFunctionBreakpoints")
+	if len(setFunctions) != 1 {
+		t.Fatalf("expected one setFunctionBreakpoints request, got %d", len(setFunctions))
+	}
+	if args := setFunctions[0].(*dap.SetFunctionBreakpointsRequest).Arguments; len(args.Breakpoints) != 1 || args.Breakpoints[0].Name != "handler" {
+		t.Errorf("unexpected setFunctionBreakpoints arguments: %+v", args)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_import_session", map[string]interface{}{"session": `{"version": 2}`}); !isErr {
+		t.Errorf("expected an unknown version to be rejected, got %s", text)
+	}
+}
+
+// TestDebugFrame verifies a frame is returned with its source window and
+// locals, selected by index or frameId, and that up/down move along the stack.
+func TestDebugFrame(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	var src strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&src, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(src.String()), 0644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	fake.handle("threads", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ThreadsResponse{Body: dap.ThreadsResponseBody{Threads: []dap.Thread{{Id: 1, Name: "main"}}}}
+	})
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+			StackFrames: []dap.StackFrame{
+				{Id: 1000, Name: "main.parse", Line: 12, Source: &dap.Source{Path: path}},
+				{Id: 1001, Name: "main.main", Line: 25, Source: &dap.Source{Path: path}},
+			},
+			TotalFrames: 2,
+		}}
+	})
+	fake.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		ref := 2000 + req.(*dap.ScopesRequest).Arguments.FrameId - 1000
+		return &dap.ScopesResponse{Body: dap.ScopesResponseBody{Scopes: []dap.Scope{
+			{Name: "Locals", VariablesReference: ref},
+			{Name: "Globals", VariablesReference: 3000, Expensive: true},
+		}}}
+	})
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		name := fmt.Sprintf("v%d", req.(*dap.VariablesRequest).Arguments.VariablesReference)
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: []dap.Variable{{Name: name, Value: "1", Type: "int"}}}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_frame", map[string]interface{}{"sessionId": sessionID, "contextLines": 2})
+	if isErr {
+		t.Fatalf("debug_frame failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if frame := result["frame"].(map[string]interface{}); frame["name"] != "main.parse" || result["index"] != float64(0) {
+		t.Errorf("expected the innermost frame, got %v", result)
+	}
+	source := result["source"].(map[string]interface{})
+	if source["startLine"] != float64(10) || source["endLine"] != float64(14) {
+		t.Errorf("expected lines 10-14, got %v", source)
+	}
+	if content := source["content"].(string); !strings.Contains(content, ">   12  line 12\n") || !strings.Contains(content, "    10  line 10\n") {
+		t.Errorf("expected numbered lines with line 12 marked, got %q", content)
+	}
+	scopes := result["scopes"].([]interface{})
+	locals := scopes[0].(map[string]interface{})
+	if vars := locals["variables"].([]interface{}); len(vars) != 1 || vars[0].(map[string]interface{})["name"] != "v2000" {
+		t.Errorf("expected the frame's locals, got %v", locals)
+	}
+	if globals := scopes[1].(map[string]interface{}); globals["expensive"] != true || globals["variables"] != nil {
+		t.Errorf("expected the expensive scope not to be expanded, got %v", globals)
+	}
+	if result["hasCaller"] != true || result["hasCallee"] != false {
+		t.Errorf("expected a caller and no callee, got %v", result)
+	}
+
+	// Up from frame 1000 is its caller
+	text, isErr = callTool(t, srv, "debug_frame", map[string]interface{}{"sessionId": sessionID, "frameId": 1000, "direction": "up"})
+	if isErr {
+		t.Fatalf("debug_frame up failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if frame := result["frame"].(map[string]interface{}); frame["id"] != float64(1001) || result["index"] != float64(1) || result["threadId"] != float64(1) {
+		t.Errorf("expected the caller frame 1001, got %v", result)
+	}
+	if vars := result["scopes"].([]interface{})[0].(map[string]interface{})["variables"].([]interface{}); vars[0].(map[string]interface{})["name"] != "v2001" {
+		t.Errorf("expected the caller's locals, got %v", vars)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_frame", map[string]interface{}{"sessionId": sessionID, "index": 1, "direction": "up"}); !isErr {
+		t.Errorf("expected moving up from the outermost frame to fail, got %s", text)
+	}
+	if text, isErr := callTool(t, srv, "debug_frame", map[string]interface{}{"sessionId": sessionID, "frameId": 42}); !isErr {
+		t.Errorf("expected an unknown frameId to fail, got %s", text)
+	}
+}
+
+func TestDebugFocus(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.handle("threads", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ThreadsResponse{Body: dap.ThreadsResponseBody{Threads: []dap.Thread{{Id: 1, Name: "main"}, {Id: 2, Name: "worker"}}}}
+	})
+	stacks := map[int][]dap.StackFrame{
+		1: {{Id: 1000, Name: "main.parse", Line: 12}, {Id: 1001, Name: "main.main", Line: 25}},
+		2: {{Id: 2000, Name: "main.work", Line: 40}},
+	}
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		frames := stacks[req.(*dap.StackTraceRequest).Arguments.ThreadId]
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: frames, TotalFrames: len(frames)}}
+	})
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "1", Type: "int"}}
+	})
+
+	focus := func(args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		args["sessionId"] = sessionID
+		text, isErr := callTool(t, srv, "debug_focus", args)
+		if isErr {
+			t.Fatalf("debug_focus failed: %s", text)
+		}
+		return decodeResult(t, text)
+	}
+
+	// Before any selection the focus is the first thread's top frame
+	result := focus(map[string]interface{}{})
+	if result["threadId"] != float64(1) || result["selected"] != false || result["frame"].(map[string]interface{})["id"] != float64(1000) {
+		t.Errorf("expected the default focus on frame 1000, got %v", result)
+	}
+
+	result = focus(map[string]interface{}{"frameId": 1001})
+	if result["threadId"] != float64(1) || result["index"] != float64(1) || result["selected"] != true {
+		t.Errorf("expected the focus on frame 1001 of thread 1, got %v", result)
+	}
+
+	result = focus(map[string]interface{}{"threadId": 2})
+	if result["frame"].(map[string]interface{})["id"] != float64(2000) {
+		t.Errorf("expected the focus on thread 2's top frame, got %v", result)
+	}
+
+	// Tools use the focused frame when frameId is omitted
+	if text, isErr := callTool(t, srv, "debug_evaluate", map[string]interface{}{"sessionId": sessionID, "expression": "x"}); isErr {
+		t.Fatalf("debug_evaluate failed: %s", text)
+	}
+	evals := fake.received("evaluate")
+	if frameID := evals[len(evals)-1].(*dap.EvaluateRequest).Arguments.FrameId; frameID != 2000 {
+		t.Errorf("expected the evaluation in the focused frame 2000, got %d", frameID)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_focus", map[string]interface{}{"sessionId": sessionID, "threadId": 2, "index": 3}); !isErr {
+		t.Errorf("expected an out of range index to fail, got %s", text)
+	}
+
+	// A new stop resets the focus to the stopped thread's top frame
+	stops := client.StopCount()
+	fake.sendEvent(&dap.StoppedEvent{
+		Event: dap.Event{Event: "stopped"},
+		Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1},
+	})
+	deadline := time.Now().Add(2 * time.Second)
+	for client.StopCount() == stops && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	result = focus(map[string]interface{}{})
+	if result["threadId"] != float64(1) || result["selected"] != false || result["frame"].(map[string]interface{})["id"] != float64(1000) {
+		t.Errorf("expected the focus to move to the stopped thread, got %v", result)
+	}
+}
+
+// TestInspection_RunningSession verifies inspection tools refuse a running
+// program at once, and work again once a stopped event arrives.
+func TestInspection_RunningSession(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+	_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusStopped)
+
+	line := int32(10)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable {
+		return []dap.Variable{{Name: "x", Value: "1", Type: "int"}}
+	})
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ContinueResponse{Body: dap.ContinueResponseBody{AllThreadsContinued: true}}
+	})
+
+	if text, isErr := callTool(t, srv, "debug_continue", map[string]interface{}{"sessionId": sessionID, "threadId": 1}); isErr {
+		t.Fatalf("debug_continue failed: %s", text)
+	}
+	status := func() interface{} {
+		text, _ := callTool(t, srv, "debug_list_sessions", map[string]interface{}{})
+		var result map[string][]map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &result); err != nil || len(result["sessions"]) != 1 {
+			t.Fatalf("unexpected debug_list_sessions result: %s", text)
+		}
+		return result["sessions"][0]["status"]
+	}
+	if got := status(); got != "running" {
+		t.Errorf("expected status running after continue, got %v", got)
+	}
+
+	for _, tool := range []string{"debug_snapshot", "debug_frame", "debug_evaluate", "debug_inspect_tree"} {
+		start := time.Now()
+		text, isErr := callTool(t, srv, tool, map[string]interface{}{"sessionId": sessionID, "expression": "x"})
+		if !isErr || !strings.Contains(text, "is running") || !strings.Contains(text, "debug_pause") {
+			t.Errorf("expected %s to report the running session with a hint, got %s", tool, text)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected %s to fail at once, took %v", tool, elapsed)
+		}
+	}
+	if got := len(fake.received("stackTrace")) + len(fake.received("evaluate")); got != 0 {
+		t.Errorf("expected no inspection requests to reach the adapter, got %d", got)
+	}
+
+	// A breakpoint hit stops the program again
+	fake.sendEvent(&dap.StoppedEvent{
+		Event: dap.Event{Event: "stopped"},
+		Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, AllThreadsStopped: true},
+	})
+	for deadline := time.Now().Add(2 * time.Second); client.Running() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID}); isErr {
+		t.Errorf("expected a snapshot once stopped, got %s", text)
+	}
+	if got := status(); got != "stopped" {
+		t.Errorf("expected status stopped after the stopped event, got %v", got)
+	}
+	if stop := client.LastStop(); stop == nil || stop.Reason != "breakpoint" {
+		t.Errorf("expected the breakpoint stop to be recorded, got %+v", stop)
+	}
+}
+
+// TestInspection_ContinuedEvent verifies a continued event from the adapter
+// marks a stopped session running again.
+func TestInspection_ContinuedEvent(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+	_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusStopped)
+
+	waitFor := func(running bool) {
+		for deadline := time.Now().Add(2 * time.Second); client.Running() != running && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	fake.sendEvent(&dap.StoppedEvent{
+		Event: dap.Event{Event: "stopped"},
+		Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1},
+	})
+	for deadline := time.Now().Add(2 * time.Second); client.LastStop() == nil && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.LastStop() == nil {
+		t.Fatal("expected the stop to be recorded")
+	}
+
+	// The adapter resumes the program on its own
+	fake.sendEvent(&dap.ContinuedEvent{
+		Event: dap.Event{Event: "continued"},
+		Body:  dap.ContinuedEventBody{ThreadId: 1, AllThreadsContinued: true},
+	})
+	waitFor(true)
+	if !client.Running() {
+		t.Fatal("expected the continued event to mark the program running")
+	}
+	if stop := client.LastStop(); stop != nil {
+		t.Errorf("expected the last stop to be cleared, got %+v", stop)
+	}
+
+	text, _ := callTool(t, srv, "debug_list_sessions", map[string]interface{}{})
+	if !strings.Contains(text, `"status":"running"`) && !strings.Contains(text, `"status": "running"`) {
+		t.Errorf("expected the session to be listed as running, got %s", text)
+	}
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID})
+	if !isErr || !strings.Contains(text, "is running") {
+		t.Errorf("expected debug_snapshot to report the running session, got %s", text)
+	}
+	if got := len(fake.received("stackTrace")); got != 0 {
+		t.Errorf("expected no stackTrace request, got %d", got)
+	}
+}
+
+// TestSnapshot_StoppedThreads verifies debug_snapshot lists every thread of
+// an all-stop with the reason of its own stopped event, until the program resumes.
+func TestSnapshot_StoppedThreads(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+	_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusStopped)
+
+	scriptThreads(fake, 1, 2, 3)
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: []dap.StackFrame{}}}
+	})
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ContinueResponse{Body: dap.ContinueResponseBody{AllThreadsContinued: true}}
+	})
+
+	stop := func(body dap.StoppedEventBody) {
+		t.Helper()
+		before := len(client.StoppedThreads())
+		fake.sendEvent(&dap.StoppedEvent{Event: dap.Event{Event: "stopped"}, Body: body})
+		for deadline := time.Now().Add(2 * time.Second); len(client.StoppedThreads()) == before && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	stop(dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, AllThreadsStopped: true, HitBreakpointIds: []int{4}})
+	stop(dap.StoppedEventBody{Reason: "exception", ThreadId: 3, AllThreadsStopped: true, Text: "SIGSEGV"})
+
+	snapshot := func() []interface{} {
+		t.Helper()
+		text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID})
+		if isErr {
+			t.Fatalf("debug_snapshot failed: %s", text)
+		}
+		stopped, _ := decodeResult(t, text)["stoppedThreads"].([]interface{})
+		return stopped
+	}
+
+	stopped := snapshot()
+	if len(stopped) != 2 {
+		t.Fatalf("expected threads 1 and 3 in stoppedThreads, got %v", stopped)
+	}
+	first, second := stopped[0].(map[string]interface{}), stopped[1].(map[string]interface{})
+	if first["threadId"] != float64(1) || first["reason"] != "breakpoint" {
+		t.Errorf("expected thread 1 stopped at a breakpoint, got %v", first)
+	}
+	if ids, _ := first["hitBreakpointIds"].([]interface{}); len(ids) != 1 || ids[0] != float64(4) {
+		t.Errorf("expected thread 1 to have hit breakpoint 4, got %v", first)
+	}
+	if second["threadId"] != float64(3) || second["reason"] != "exception" || second["text"] != "SIGSEGV" {
+		t.Errorf("expected thread 3 stopped on an exception, got %v", second)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_continue", map[string]interface{}{"sessionId": sessionID, "threadId": 1}); isErr {
+		t.Fatalf("debug_continue failed: %s", text)
+	}
+	if got := client.StoppedThreads(); len(got) != 0 {
+		t.Errorf("expected no stopped threads after continuing, got %v", got)
+	}
+
+	// The first stop after resuming starts a new set
+	_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusStopped)
+	stop(dap.StoppedEventBody{Reason: "step", ThreadId: 2})
+	if stopped := snapshot(); len(stopped) != 1 || stopped[0].(map[string]interface{})["threadId"] != float64(2) {
+		t.Errorf("expected only thread 2 after the next stop, got %v", stopped)
+	}
+}
+
+// TestDebugDiff verifies debug_diff reports only what changed between two
+// stored snapshots, matching frames across a step into a call.
+func TestDebugDiff(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+	_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusStopped)
+
+	var stepped atomic.Bool
+	scriptThreads(fake, 1)
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		frames := []dap.StackFrame{{Id: 1000, Name: "main.loop", Line: 10}, {Id: 1001, Name: "main.main", Line: 4}}
+		if stepped.Load() {
+			frames = []dap.StackFrame{{Id: 1002, Name: "main.step", Line: 3}, {Id: 1000, Name: "main.loop", Line: 11}, {Id: 1001, Name: "main.main", Line: 4}}
+		}
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: frames, TotalFrames: len(frames)}}
+	})
+	fake.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		frameID := req.(*dap.ScopesRequest).Arguments.FrameId
+		return &dap.ScopesResponse{Body: dap.ScopesResponseBody{
+			Scopes: []dap.Scope{{Name: "Locals", VariablesReference: frameID + 1000}},
+		}}
+	})
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		var vars []dap.Variable
+		if req.(*dap.VariablesRequest).Arguments.VariablesReference == 2000 { // main.loop
+			vars = []dap.Variable{{Name: "i", Value: "1", Type: "int"}, {Name: "total", Value: "0", Type: "int"}}
+			if stepped.Load() {
+				vars = []dap.Variable{{Name: "i", Value: "2", Type: "int"}, {Name: "total", Value: "0", Type: "int"}, {Name: "err", Value: "nil", Type: "error"}}
+			}
+		}
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: vars}}
+	})
+
+	snapshot := func() string {
+		t.Helper()
+		text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID})
+		if isErr {
+			t.Fatalf("debug_snapshot failed: %s", text)
+		}
+		id, _ := decodeResult(t, text)["snapshotId"].(string)
+		if id == "" {
+			t.Fatalf("expected a snapshotId, got %s", text)
+		}
+		return id
+	}
+
+	first := snapshot()
+	if text, isErr := callTool(t, srv, "debug_diff", map[string]interface{}{"sessionId": sessionID}); !isErr || !strings.Contains(text, "1 stored snapshot") {
+		t.Errorf("expected an error with one snapshot stored, got %s", text)
+	}
+
+	stepped.Store(true)
+	second := snapshot()
+
+	text, isErr := callTool(t, srv, "debug_diff", map[string]interface{}{"sessionId": sessionID, "from": first, "to": second})
+	if isErr {
+		t.Fatalf("debug_diff failed: %s", text)
+	}
+	var result struct {
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Threads []struct {
+			ThreadID    int `json:"threadId"`
+			FramesAdded []struct {
+				Frame int    `json:"frame"`
+				Name  string `json:"name"`
+			} `json:"framesAdded"`
+			FramesRemoved []interface{} `json:"framesRemoved"`
+			FramesMoved   []struct {
+				Name     string `json:"name"`
+				FromLine int    `json:"fromLine"`
+				ToLine   int    `json:"toLine"`
+			} `json:"framesMoved"`
+			Variables []struct {
+				Frame  int    `json:"frame"`
+				Name   string `json:"name"`
+				Change string `json:"change"`
+				From   string `json:"from"`
+				To     string `json:"to"`
+			} `json:"variables"`
+		} `json:"threads"`
+		Unchanged bool `json:"unchanged"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("failed to decode %s: %v", text, err)
+	}
+	if result.From != first || result.To != second || len(result.Threads) != 1 {
+		t.Fatalf("expected one changed thread between %s and %s, got %s", first, second, text)
+	}
+	thread := result.Threads[0]
+	if len(thread.FramesAdded) != 1 || thread.FramesAdded[0].Name != "main.step" || thread.FramesAdded[0].Frame != 0 {
+		t.Errorf("expected main.step pushed as frame 0, got %s", text)
+	}
+	if len(thread.FramesRemoved) != 0 {
+		t.Errorf("expected no frames popped, got %s", text)
+	}
+	if len(thread.FramesMoved) != 1 || thread.FramesMoved[0].Name != "main.loop" || thread.FramesMoved[0].FromLine != 10 || thread.FramesMoved[0].ToLine != 11 {
+		t.Errorf("expected main.loop moved from line 10 to 11, got %s", text)
+	}
+	changes := map[string]string{}
+	for _, v := range thread.Variables {
+		if v.Frame != 1 {
+			t.Errorf("expected main.loop's variables at frame 1, got %+v", v)
+		}
+		changes[v.Name] = v.Change + ":" + v.From + "->" + v.To
+	}
+	if changes["i"] != "changed:1->2" || changes["err"] != "added:->nil" || len(changes) != 2 {
+		t.Errorf("expected i changed and err added, got %v", changes)
+	}
+
+	// Comparing a snapshot with itself finds nothing
+	text, _ = callTool(t, srv, "debug_diff", map[string]interface{}{"sessionId": sessionID, "from": second, "to": second})
+	if !strings.Contains(text, `"unchanged":true`) {
+		t.Errorf("expected no changes, got %s", text)
+	}
+
+	if text, isErr := callTool(t, srv, "debug_diff", map[string]interface{}{"sessionId": sessionID, "from": "snap-99"}); !isErr || !strings.Contains(text, second) {
+		t.Errorf("expected an unknown snapshotId to list the stored ones, got %s", text)
+	}
+}
+
+// TestDebugException verifies debug_exception turns exceptionInfo details
+// into a chain of exceptions and falls back to the stopped event.
+func TestDebugException(t *testing.T) {
+	type level struct {
+		Type     string `json:"type"`
+		Message  string `json:"message"`
+		Relation string `json:"relation"`
+		Frames   []struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+			Line int    `json:"line"`
+		} `json:"frames"`
+	}
+	decodeChain := func(t *testing.T, text string) []level {
+		t.Helper()
+		var result struct {
+			Chain []level `json:"chain"`
+		}
+		if err := json.Unmarshal([]byte(text), &result); err != nil {
+			t.Fatalf("failed to decode %s: %v", text, err)
+		}
+		return result.Chain
+	}
+
+	// stopOn starts a session stopped on thread 1 for reason, with the
+	// exceptionInfo capability if details are given
+	stopOn := func(t *testing.T, reason, text string, details *dap.ExceptionDetails) (*dapmcp.Server, string) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv, sessionID := newTestServer(t, client, types.LanguagePython)
+		_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusStopped)
+
+		if details != nil {
+			fake.handle("initialize", func(req dap.RequestMessage) dap.ResponseMessage {
+				return &dap.InitializeResponse{Body: dap.Capabilities{SupportsExceptionInfoRequest: true}}
+			})
+			if _, err := client.Initialize("test", "test"); err != nil {
+				t.Fatalf("Initialize failed: %v", err)
+			}
+			fake.handle("exceptionInfo", func(req dap.RequestMessage) dap.ResponseMessage {
+				return &dap.ExceptionInfoResponse{Body: dap.ExceptionInfoResponseBody{
+					ExceptionId: details.TypeName,
+					Description: details.Message,
+					BreakMode:   "unhandled",
+					Details:     details,
+				}}
+			})
+		}
+
+		fake.sendEvent(&dap.StoppedEvent{
+			Event: dap.Event{Event: "stopped"},
+			Body:  dap.StoppedEventBody{Reason: reason, ThreadId: 1, Description: "Paused on " + reason, Text: text},
+		})
+		for deadline := time.Now().Add(2 * time.Second); client.LastStop() == nil && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return srv, sessionID
+	}
+
+	t.Run("python chain", func(t *testing.T) {
+		traceback := `Traceback (most recent call last):
+  File "/src/db.py", line 8, in connect
+    raise ValueError("bad port")
+ValueError: bad port
+
+The above exception was the direct cause of the following exception:
+
+Traceback (most recent call last):
+  File "/src/app.py", line 20, in main
+    start()
+  File "/src/app.py", line 12, in start
+    raise RuntimeError("startup failed") from err
+RuntimeError: startup failed
+`
+		srv, sessionID := stopOn(t, "exception", "", &dap.ExceptionDetails{
+			TypeName:   "RuntimeError",
+			Message:    "startup failed",
+			StackTrace: traceback,
+		})
+		text, isErr := callTool(t, srv, "debug_exception", map[string]interface{}{"sessionId": sessionID})
+		if isErr {
+			t.Fatalf("debug_exception failed: %s", text)
+		}
+		if result := decodeResult(t, text); result["breakMode"] != "unhandled" || result["exceptionId"] != "RuntimeError" {
+			t.Errorf("unexpected exception summary: %v", result)
+		}
+
+		chain := decodeChain(t, text)
+		if len(chain) != 2 {
+			t.Fatalf("expected two levels, got %+v", chain)
+		}
+		if chain[0].Type != "RuntimeError" || chain[0].Message != "startup failed" || chain[0].Relation != "" {
+			t.Errorf("unexpected outer exception: %+v", chain[0])
+		}
+		if len(chain[0].Frames) != 2 || chain[0].Frames[0].Name != "start" || chain[0].Frames[0].Line != 12 || chain[0].Frames[1].Path != "/src/app.py" {
+			t.Errorf("expected the outer frames innermost first, got %+v", chain[0].Frames)
+		}
+		if chain[1].Type != "ValueError" || chain[1].Message != "bad port" || chain[1].Relation != "cause" {
+			t.Errorf("unexpected cause: %+v", chain[1])
+		}
+		if len(chain[1].Frames) != 1 || chain[1].Frames[0].Name != "connect" || chain[1].Frames[0].Line != 8 {
+			t.Errorf("unexpected cause frames: %+v", chain[1].Frames)
+		}
+	})
+
+	t.Run("java caused by", func(t *testing.T) {
+		srv, sessionID := stopOn(t, "exception", "", &dap.ExceptionDetails{
+			TypeName:     "IllegalStateException",
+			FullTypeName: "java.lang.IllegalStateException",
+			Message:      "cannot start",
+			StackTrace: "java.lang.IllegalStateException: cannot start\n" +
+				"\tat com.example.App.start(App.java:42)\n" +
+				"\tat com.example.App.main(App.java:10)\n" +
+				"Caused by: java.io.IOException: disk full\n" +
+				"\tat com.example.Store.write(Store.java:7)\n" +
+				"\t... 2 more",
+		})
+		text, isErr := callTool(t, srv, "debug_exception", map[string]interface{}{"sessionId": sessionID, "threadId": 1})
+		if isErr {
+			t.Fatalf("debug_exception failed: %s", text)
+		}
+		chain := decodeChain(t, text)
+		if len(chain) != 2 {
+			t.Fatalf("expected two levels, got %+v", chain)
+		}
+		if chain[0].Type != "java.lang.IllegalStateException" || len(chain[0].Frames) != 2 || chain[0].Frames[0].Path != "App.java" || chain[0].Frames[0].Line != 42 {
+			t.Errorf("unexpected outer exception: %+v", chain[0])
+		}
+		if chain[1].Type != "java.io.IOException" || chain[1].Message != "disk full" || chain[1].Relation != "cause" ||
+			len(chain[1].Frames) != 1 || chain[1].Frames[0].Name != "com.example.Store.write" {
+			t.Errorf("unexpected cause: %+v", chain[1])
+		}
+	})
+
+	t.Run("flat message", func(t *testing.T) {
+		srv, sessionID := stopOn(t, "exception", "TypeError: Cannot read properties of undefined (reading 'id')", nil)
+		text, isErr := callTool(t, srv, "debug_exception", map[string]interface{}{"sessionId": sessionID})
+		if isErr {
+			t.Fatalf("debug_exception failed: %s", text)
+		}
+		chain := decodeChain(t, text)
+		if len(chain) != 1 || chain[0].Type != "TypeError" || chain[0].Message != "Cannot read properties of undefined (reading 'id')" {
+			t.Errorf("expected one level from the stopped event, got %+v", chain)
+		}
+		if note, _ := decodeResult(t, text)["note"].(string); !strings.Contains(note, "stopped event") {
+			t.Errorf("expected a note about the fallback, got %q", note)
+		}
+	})
+
+	t.Run("not an exception", func(t *testing.T) {
+		srv, sessionID := stopOn(t, "breakpoint", "", nil)
+		text, isErr := callTool(t, srv, "debug_exception", map[string]interface{}{"sessionId": sessionID})
+		if !isErr || !strings.Contains(text, "not stopped on an exception (stopped on breakpoint)") {
+			t.Errorf("expected a no-exception error, got %s", text)
+		}
+	})
+}
+
+// envAdapter is a launchableAdapter that records the default environment
+// given to the adapter process and passes env on to the launch request
+type envAdapter struct {
+	*launchableAdapter
+	spawnEnv map[string]string
+}
+
+func (a *envAdapter) SpawnStdio(ctx context.Context, program string, args map[string]interface{}) (*internaldap.Client, *exec.Cmd, error) {
+	a.spawnEnv, _ = args[adapters.DefaultEnvArg].(map[string]string)
+	return a.launchableAdapter.SpawnStdio(ctx, program, args)
+}
+
+func (a *envAdapter) BuildLaunchArgs(program string, args map[string]interface{}) map[string]interface{} {
+	launchArgs := a.launchableAdapter.BuildLaunchArgs(program, args)
+	launchArgs["env"] = args["env"]
+	return launchArgs
+}
+
+// TestDefaultEnv verifies an adapter config's defaultEnv reaches the adapter
+// process and the debuggee, that launch.json env overrides it, and that its
+// variables are resolved against the launch.json workspace.
+func TestDefaultEnv(t *testing.T) {
+	workspace := t.TempDir()
+	vscodeDir := filepath.Join(workspace, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	launchJSON := `{
+		"version": "0.2.0",
+		"configurations": [{
+			"name": "app",
+			"type": "debugpy",
+			"request": "launch",
+			"program": "${workspaceFolder}/app.py",
+			"env": {"LOG_LEVEL": "debug"}
+		}]
+	}`
+	if err := os.WriteFile(filepath.Join(vscodeDir, "launch.json"), []byte(launchJSON), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Adapters.Python.DefaultEnv = map[string]string{
+		"PYTHONPATH": "${workspaceFolder}/lib",
+		"LOG_LEVEL":  "info",
+	}
+	cfg.Adapters.Go.DefaultEnv = map[string]string{"GOFLAGS": "-mod=mod"}
+
+	launch := func(t *testing.T, args map[string]interface{}) (*fakeAdapter, *envAdapter) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServerWithConfig(t, cfg, fake, client, types.LanguagePython)
+		adapter := &envAdapter{launchableAdapter: &launchableAdapter{lang: types.LanguagePython, client: client}}
+		srv.GetAdapterRegistry().Register(types.LanguagePython, adapter)
+
+		if text, isErr := callTool(t, srv, "debug_launch", args); isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		return fake, adapter
+	}
+	launchEnv := func(t *testing.T, fake *fakeAdapter) map[string]string {
+		t.Helper()
+		launches := fake.received("launch")
+		if len(launches) != 1 {
+			t.Fatalf("expected one launch request, got %d", len(launches))
+		}
+		var args struct {
+			Env map[string]string `json:"env"`
+		}
+		if err := json.Unmarshal(launches[0].(*dap.LaunchRequest).Arguments, &args); err != nil {
+			t.Fatalf("failed to decode launch arguments: %v", err)
+		}
+		return args.Env
+	}
+
+	t.Run("launch.json", func(t *testing.T) {
+		fake, adapter := launch(t, map[string]interface{}{"configName": "app", "workspace": workspace})
+
+		env := launchEnv(t, fake)
+		if env["LOG_LEVEL"] != "debug" {
+			t.Errorf("expected the launch env to override defaultEnv, got LOG_LEVEL=%q", env["LOG_LEVEL"])
+		}
+		if want := workspace + "/lib"; env["PYTHONPATH"] != want {
+			t.Errorf("expected PYTHONPATH %q, got %q", want, env["PYTHONPATH"])
+		}
+		if _, ok := env["GOFLAGS"]; ok {
+			t.Error("expected only the python adapter's defaultEnv")
+		}
+		if adapter.spawnEnv["PYTHONPATH"] != workspace+"/lib" || adapter.spawnEnv["LOG_LEVEL"] != "info" {
+			t.Errorf("expected the adapter process to get the resolved defaultEnv, got %v", adapter.spawnEnv)
+		}
+	})
+
+	t.Run("direct", func(t *testing.T) {
+		fake, adapter := launch(t, map[string]interface{}{"language": "python", "program": "/src/app.py"})
+
+		// Without a workspace, values are used as written
+		env := launchEnv(t, fake)
+		if env["LOG_LEVEL"] != "info" || env["PYTHONPATH"] != "${workspaceFolder}/lib" {
+			t.Errorf("expected the defaultEnv as written, got %v", env)
+		}
+		if adapter.spawnEnv["LOG_LEVEL"] != "info" {
+			t.Errorf("expected the adapter process to get the defaultEnv, got %v", adapter.spawnEnv)
+		}
+	})
+}
+
+// TestDebugInstallAdapter verifies debug_install_adapter is only offered
+// with allowInstall and reports installer failures and writeConfig misuse.
+func TestDebugInstallAdapter(t *testing.T) {
+	toolNames := func(srv *dapmcp.Server) string {
+		resp := srv.GetMCPServer().HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		body, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	cfg := config.DefaultConfig()
+	srv := dapmcp.NewServer(cfg, nil)
+	t.Cleanup(srv.Close)
+	if strings.Contains(toolNames(srv), "debug_install_adapter") {
+		t.Fatal("expected debug_install_adapter to be hidden without allowInstall")
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.AllowInstall = true
+	srv = dapmcp.NewServer(cfg, nil)
+	t.Cleanup(srv.Close)
+	if !strings.Contains(toolNames(srv), "debug_install_adapter") {
+		t.Fatal("expected debug_install_adapter with allowInstall")
+	}
+
+	text, isErr := callTool(t, srv, "debug_install_adapter", map[string]interface{}{"language": "elixir"})
+	if !isErr || !strings.Contains(text, "failed to install debug adapter for elixir") || !strings.Contains(text, "elixir-ls/releases") {
+		t.Errorf("expected an install failure with manual instructions, got %q", text)
+	}
+
+	text, isErr = callTool(t, srv, "debug_install_adapter", map[string]interface{}{"language": "go", "writeConfig": true})
+	if !isErr || !strings.Contains(text, "without -config") {
+		t.Errorf("expected writeConfig to need a config file, got %q", text)
+	}
+
+	// The language is case-insensitive, and python installs with the configured interpreter
+	cfg.Adapters.Python.PythonPath = "/nonexistent/dap-mcp-python"
+	text, isErr = callTool(t, srv, "debug_install_adapter", map[string]interface{}{"language": "Python"})
+	if !isErr || !strings.Contains(text, "/nonexistent/dap-mcp-python") {
+		t.Errorf("expected the install to use the configured pythonPath, got %q", text)
+	}
+}
+
+// TestDebugPing verifies debug_ping reports adapter and debuggee liveness
+// without fetching stacks or variables.
+func TestDebugPing(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+	_ = srv.GetSessionManager().UpdateSessionStatus(sessionID, types.SessionStatusRunning)
+
+	line := int32(10)
+	scriptStoppedProgram(fake, &line, func() []dap.Variable { return nil })
+
+	ping := func() map[string]interface{} {
+		t.Helper()
+		text, isErr := callTool(t, srv, "debug_ping", map[string]interface{}{"sessionId": sessionID, "timeoutMs": 500})
+		if isErr {
+			t.Fatalf("debug_ping failed: %s", text)
+		}
+		return decodeResult(t, text)
+	}
+
+	result := ping()
+	if result["adapterAlive"] != true || result["adapterResponsive"] != true || result["debuggeeAlive"] != true {
+		t.Errorf("expected a live session, got %v", result)
+	}
+	if _, ok := result["latencyMs"]; !ok {
+		t.Errorf("expected latencyMs, got %v", result)
+	}
+	if _, ok := result["lastActivity"]; !ok {
+		t.Errorf("expected lastActivity after the threads response, got %v", result)
+	}
+	if got := len(fake.received("stackTrace")) + len(fake.received("variables")); got != 0 {
+		t.Errorf("expected no stack or variable requests, got %d", got)
+	}
+
+	// The debuggee exits
+	fake.sendEvent(&dap.ExitedEvent{Event: dap.Event{Event: "exited"}, Body: dap.ExitedEventBody{ExitCode: 3}})
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, exited := client.DebuggeeExited(); exited {
+			break
+		}
+	}
+	result = ping()
+	if result["debuggeeAlive"] != false || result["exitCode"] != float64(3) {
+		t.Errorf("expected the exited debuggee with its exit code, got %v", result)
+	}
+
+	// The adapter crashes: ping still answers, and inspection fails fast
+	_ = fake.conn.Close()
+	for deadline := time.Now().Add(2 * time.Second); client.Alive() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	result = ping()
+	if result["adapterAlive"] != false {
+		t.Errorf("expected adapterAlive false after the connection closed, got %v", result)
+	}
+	if _, ok := result["adapterResponsive"]; ok {
+		t.Errorf("expected no threads request to a closed adapter, got %v", result)
+	}
+	text, isErr := callTool(t, srv, "debug_snapshot", map[string]interface{}{"sessionId": sessionID})
+	if !isErr || !strings.Contains(text, "no longer connected") {
+		t.Errorf("expected debug_snapshot to report the closed adapter, got %s", text)
+	}
+}
+
+// TestDebugServerInfo verifies debug_server_info reports the mode,
+// permissions, limits, and languages, and lists what the configuration
+// doesn't allow.
+func TestDebugServerInfo(t *testing.T) {
+	info := func(t *testing.T, cfg *config.Config) map[string]interface{} {
+		t.Helper()
+		_, client := newFakeAdapter(t)
+		srv, _ := newTestServerWithConfig(t, cfg, client, types.LanguageGo)
+		text, isErr := callTool(t, srv, "debug_server_info", map[string]interface{}{})
+		if isErr {
+			t.Fatalf("debug_server_info failed: %s", text)
+		}
+		return decodeResult(t, text)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Mode = config.ModeFull
+	cfg.AllowModify = true
+	cfg.AllowExecute = true
+	cfg.AllowCommands = true
+	cfg.MaxSessions = 4
+	result := info(t, cfg)
+	if result["version"] == "" || result["mode"] != "full" || result["controlTools"] != true {
+		t.Errorf("expected full mode with control tools, got %v", result)
+	}
+	permissions, _ := result["permissions"].(map[string]interface{})
+	if permissions["modify"] != true || permissions["execute"] != true || permissions["evaluation"] != "full" {
+		t.Errorf("expected every permission, got %v", permissions)
+	}
+	limits, _ := result["limits"].(map[string]interface{})
+	if limits["maxSessions"] != float64(4) || limits["activeSessions"] != float64(1) {
+		t.Errorf("expected the session limits, got %v", limits)
+	}
+	if languages := fmt.Sprint(result["languages"]); !strings.Contains(languages, "go") || !strings.Contains(languages, "python") {
+		t.Errorf("expected the supported languages, got %v", result["languages"])
+	}
+	if _, ok := result["restrictions"]; ok {
+		t.Errorf("expected no restrictions, got %v", result["restrictions"])
+	}
+	if update, _ := result["update"].(map[string]interface{}); update["checked"] != false {
+		t.Errorf("expected no update check without a checker, got %v", update)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.Mode = config.ModeReadOnly
+	result = info(t, cfg)
+	restrictions := fmt.Sprint(result["restrictions"])
+	for _, want := range []string{"Readonly mode", "side effects"} {
+		if !strings.Contains(restrictions, want) {
+			t.Errorf("expected a %s restriction, got %s", want, restrictions)
+		}
+	}
+}
+
+// TestDebugGoroutines verifies goroutines are read from Delve's threads with
+// their location and start function, and any goroutine's stack can be paged.
+func TestDebugGoroutines(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	fake.handle("threads", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ThreadsResponse{Body: dap.ThreadsResponseBody{Threads: []dap.Thread{
+			{Id: 1, Name: "* [Go 1] main.main (Thread 9)"},
+			{Id: 7, Name: "[Go 7] main.worker"},
+			{Id: 8, Name: "[Go 8] main.worker"},
+		}}}
+	})
+	stacks := map[int][]string{
+		1: {"main.main", "runtime.main", "runtime.goexit"},
+		7: {"runtime.gopark", "runtime.chanrecv1", "main.worker", "runtime.goexit"},
+	}
+	// Goroutine 8 is deeper than a page, from an adapter that reports no total
+	for i := 0; i < 28; i++ {
+		stacks[8] = append(stacks[8], fmt.Sprintf("main.recurse%d", i))
+	}
+	stacks[8] = append(stacks[8], "main.spawn", "runtime.goexit")
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		args := req.(*dap.StackTraceRequest).Arguments
+		names := stacks[args.ThreadId]
+		var frames []dap.StackFrame
+		for i := args.StartFrame; i < len(names) && i < args.StartFrame+args.Levels; i++ {
+			frames = append(frames, dap.StackFrame{Id: args.ThreadId*1000 + i, Name: names[i], Line: i + 1,
+				Source: &dap.Source{Path: "/src/main.go"}})
+		}
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: frames}}
+	})
+
+	text, isErr := callTool(t, srv, "debug_goroutines", map[string]interface{}{"sessionId": sessionID})
+	if isErr {
+		t.Fatalf("goroutines failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	goroutines := result["goroutines"].([]interface{})
+	if len(goroutines) != 3 || result["total"] != float64(3) {
+		t.Fatalf("expected 3 goroutines, got %v", result)
+	}
+	first := goroutines[0].(map[string]interface{})
+	if first["id"] != float64(1) || first["selected"] != true || first["state"] != "running" || first["thread"] != float64(9) ||
+		first["function"] != "main.main" || first["startFunction"] != "runtime.main" {
+		t.Errorf("unexpected main goroutine: %v", first)
+	}
+	worker := goroutines[1].(map[string]interface{})
+	location, _ := worker["location"].(map[string]interface{})
+	if worker["state"] != "waiting" || worker["topFunction"] != "runtime.gopark" || location["name"] != "main.worker" ||
+		worker["startFunction"] != "main.worker" {
+		t.Errorf("expected worker 7 parked in runtime.gopark, located in main.worker, got %v", worker)
+	}
+	if deep := goroutines[2].(map[string]interface{}); deep["startFunction"] != "main.spawn" || deep["totalFrames"] != float64(30) {
+		t.Errorf("expected goroutine 8 started in main.spawn with 30 frames, got %v", deep)
+	}
+
+	text, _ = callTool(t, srv, "debug_goroutines", map[string]interface{}{
+		"sessionId": sessionID, "filter": "WORKER", "maxGoroutines": 1, "includeLocation": false,
+	})
+	result = decodeResult(t, text)
+	if len(result["goroutines"].([]interface{})) != 1 || result["matched"] != float64(2) || result["omitted"] != float64(1) {
+		t.Errorf("expected 1 of 2 matching goroutines listed, got %v", result)
+	}
+
+	// Any goroutine's stack, not only the stopped one's, a page at a time
+	text, isErr = callTool(t, srv, "debug_goroutine_stack", map[string]interface{}{
+		"sessionId": sessionID, "goroutineId": 8, "startFrame": 10, "maxStackDepth": 5,
+	})
+	if isErr {
+		t.Fatalf("goroutine stack failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	frames := result["frames"].([]interface{})
+	if len(frames) != 5 || frames[0].(map[string]interface{})["name"] != "main.recurse10" ||
+		result["totalFrames"] != float64(30) || result["hasMore"] != true {
+		t.Errorf("expected frames 10-14 of 30, got %v", result)
+	}
+
+	_, pyClient := newFakeAdapter(t)
+	pySrv, pySessionID := newTestServer(t, pyClient, types.LanguagePython)
+	if text, isErr := callTool(t, pySrv, "debug_goroutines", map[string]interface{}{"sessionId": pySessionID}); !isErr {
+		t.Errorf("expected debug_goroutines to refuse a Python session, got %s", text)
+	}
+}
+
+func TestDebugRegisters(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	srv, sessionID := newTestServer(t, client, types.LanguageC)
+
+	line := int32(10)
+	scriptStoppedProgram(fake, &line, nil)
+	fake.handle("scopes", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ScopesResponse{Body: dap.ScopesResponseBody{Scopes: []dap.Scope{
+			{Name: "Locals", VariablesReference: 2000},
+			{Name: "Registers", VariablesReference: 3000, Expensive: true},
+		}}}
+	})
+	// LLDB-style register sets
+	fake.handle("variables", func(req dap.RequestMessage) dap.ResponseMessage {
+		var vars []dap.Variable
+		switch req.(*dap.VariablesRequest).Arguments.VariablesReference {
+		case 3000:
+			vars = []dap.Variable{
+				{Name: "General Purpose Registers", VariablesReference: 3001},
+				{Name: "Floating Point Registers", VariablesReference: 3002},
+			}
+		case 3001:
+			vars = []dap.Variable{{Name: "rip", Value: "0x401136"}, {Name: "rsp", Value: "0x7ffc1000"}}
+		case 3002:
+			vars = []dap.Variable{{Name: "xmm0", Value: "{0x00 0x00}"}}
+		}
+		return &dap.VariablesResponse{Body: dap.VariablesResponseBody{Variables: vars}}
+	})
+
+	registerNames := func(result map[string]interface{}) []string {
+		var names []string
+		for _, r := range result["registers"].([]interface{}) {
+			names = append(names, r.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	// Only the general purpose set is expanded by default
+	text, isErr := callTool(t, srv, "debug_registers", map[string]interface{}{
+		"sessionId": sessionID,
+		"hex":       true,
+	})
+	if isErr {
+		t.Fatalf("debug_registers failed: %s", text)
+	}
+	result := decodeResult(t, text)
+	if got := registerNames(result); strings.Join(got, ",") != "rip,rsp" {
+		t.Errorf("expected rip and rsp, got %v", got)
+	}
+	if groups := result["otherGroups"].([]interface{}); len(groups) != 1 || groups[0] != "Floating Point Registers" {
+		t.Errorf("expected the floating point set in otherGroups, got %v", groups)
+	}
+	if result["format"] != "hex" {
+		t.Errorf("expected hex format, got %v", result["format"])
+	}
+	for _, req := range fake.received("variables") {
+		if args := req.(*dap.VariablesRequest).Arguments; args.Format == nil || !args.Format.Hex {
+			t.Errorf("expected hex formatting requested for reference %d", args.VariablesReference)
+		}
+	}
+
+	// Names reach registers in every set
+	text, isErr = callTool(t, srv, "debug_registers", map[string]interface{}{
+		"sessionId": sessionID,
+		"names":     `["RIP", "xmm0", "r99"]`,
+	})
+	if isErr {
+		t.Fatalf("debug_registers with names failed: %s", text)
+	}
+	result = decodeResult(t, text)
+	if got := registerNames(result); strings.Join(got, ",") != "rip,xmm0" {
+		t.Errorf("expected rip and xmm0, got %v", got)
+	}
+	if missing := result["notFound"].([]interface{}); len(missing) != 1 || missing[0] != "r99" {
+		t.Errorf("expected r99 not found, got %v", missing)
+	}
+
+	// Registers are only available from native debuggers
+	_, pyClient := newFakeAdapter(t)
+	pySrv, pySessionID := newTestServer(t, pyClient, types.LanguagePython)
+	if text, isErr := callTool(t, pySrv, "debug_registers", map[string]interface{}{"sessionId": pySessionID}); !isErr {
+		t.Errorf("expected debug_registers to be rejected for a Python session, got %s", text)
+	}
+}
+
+func TestDebugCustomRequest(t *testing.T) {
+	fake, client := newFakeAdapter(t)
+	fake.handle("loadedSources", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.LoadedSourcesResponse{Body: dap.LoadedSourcesResponseBody{
+			Sources: []dap.Source{{Name: "main.go", Path: "/src/main.go"}},
+		}}
+	})
+	fake.handle("completions", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ErrorResponse{Response: dap.Response{Message: "completions not supported"}}
+	})
+	srv, sessionID := newTestServer(t, client, types.LanguageGo)
+
+	text, isErr := callTool(t, srv, "debug_custom_request", map[string]interface{}{
+		"sessionId": sessionID,
+		"command":   "loadedSources",
+		"arguments": `{}`,
+	})
+	if isErr {
+		t.Fatalf("debug_custom_request failed: %s", text)
+	}
+	body, _ := decodeResult(t, text)["body"].(map[string]interface{})
+	sources, _ := body["sources"].([]interface{})
+	if len(sources) != 1 || sources[0].(map[string]interface{})["path"] != "/src/main.go" {
+		t.Errorf("expected the raw loadedSources body, got %s", text)
+	}
+
+	text, isErr = callTool(t, srv, "debug_custom_request", map[string]interface{}{
+		"sessionId": sessionID,
+		"command":   "completions",
+		"arguments": `{"text": "x", "column": 1}`,
+	})
+	if !isErr || !strings.Contains(text, "completions not supported") {
+		t.Errorf("expected the adapter's error, got %s", text)
+	}
+
+	// Commands go-dap has no types for, like Delve's dlvCommand, work too
+	fake.handle("dlvCommand", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &internaldap.RawResponse{Body: []byte(`{"result": "Goroutine 1 - main.main"}`)}
+	})
+	text, isErr = callTool(t, srv, "debug_custom_request", map[string]interface{}{
+		"sessionId": sessionID,
+		"command":   "dlvCommand",
+		"arguments": `{"command": "goroutines"}`,
+	})
+	if isErr {
+		t.Fatalf("debug_custom_request failed: %s", text)
+	}
+	if body, _ := decodeResult(t, text)["body"].(map[string]interface{}); body["result"] != "Goroutine 1 - main.main" {
+		t.Errorf("expected the raw dlvCommand body, got %s", text)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"sessionId": sessionID, "command": "loadedSources", "arguments": `[1, 2]`},
+		{"sessionId": sessionID, "command": "disconnect"},
+	} {
+		if text, isErr := callTool(t, srv, "debug_custom_request", args); !isErr {
+			t.Errorf("expected %v to be rejected, got %s", args, text)
+		}
+	}
+	if got := len(fake.received("disconnect")); got != 0 {
+		t.Errorf("expected no disconnect to reach the adapter, got %d", got)
+	}
+}
+
+// TestDebugLaunch_Compound verifies a compound launches each configuration,
+// and that a failed configuration rolls back the sessions already started
+// unless onPartialFailure is "keep".
+func TestDebugLaunch_Compound(t *testing.T) {
+	workspace := t.TempDir()
+	vscodeDir := filepath.Join(workspace, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	launchJSON := `{
+		"version": "0.2.0",
+		"configurations": [
+			{"name": "api", "type": "debugpy", "request": "launch", "program": "${workspaceFolder}/api.py"},
+			{"name": "worker", "type": "debugpy", "request": "attach", "port": 5678}
+		],
+		"compounds": [
+			{"name": "api only", "configurations": ["api"]},
+			{"name": "all", "configurations": ["api", "worker", "api"]}
+		]
+	}`
+	if err := os.WriteFile(filepath.Join(vscodeDir, "launch.json"), []byte(launchJSON), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+	launchCompound := func(t *testing.T, args map[string]interface{}) (*dapmcp.Server, string, bool) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguagePython)
+		args["workspace"] = workspace
+		text, isErr := callTool(t, srv, "debug_launch", args)
+		return srv, text, isErr
+	}
+
+	t.Run("launched", func(t *testing.T) {
+		srv, text, isErr := launchCompound(t, map[string]interface{}{"compoundName": "api only"})
+		if isErr {
+			t.Fatalf("compound launch failed: %s", text)
+		}
+		result := decodeResult(t, text)
+		sessions, _ := result["sessions"].([]interface{})
+		if result["status"] != "launched" || len(sessions) != 1 {
+			t.Fatalf("expected one launched session, got %v", result)
+		}
+		if session := sessions[0].(map[string]interface{}); session["configName"] != "api" || session["sessionId"] == nil {
+			t.Errorf("expected the api session, got %v", session)
+		}
+		if got := len(srv.GetSessionManager().ListSessions()); got != 1 {
+			t.Errorf("expected 1 session, got %d", got)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		srv, text, isErr := launchCompound(t, map[string]interface{}{"compoundName": "all"})
+		if !isErr {
+			t.Fatalf("expected the compound launch to fail, got %s", text)
+		}
+		for _, want := range []string{"worker: ", "attach configuration", `"rolledBack":["`, `"notLaunched":["api"]`} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %s in the report, got %s", want, text)
+			}
+		}
+		if got := len(srv.GetSessionManager().ListSessions()); got != 0 {
+			t.Errorf("expected the started session to be rolled back, got %d sessions", got)
+		}
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		srv, text, isErr := launchCompound(t, map[string]interface{}{"compoundName": "all", "onPartialFailure": "keep"})
+		if isErr {
+			t.Fatalf("expected the compound launch to keep its sessions, got %s", text)
+		}
+		result := decodeResult(t, text)
+		sessions, _ := result["sessions"].([]interface{})
+		failed, _ := result["failed"].([]interface{})
+		if result["status"] != "partial" || len(sessions) != 2 || len(failed) != 1 {
+			t.Fatalf("expected two sessions and one failure, got %v", result)
+		}
+		if f := failed[0].(map[string]interface{}); f["configName"] != "worker" {
+			t.Errorf("expected worker to fail, got %v", f)
+		}
+		if got := len(srv.GetSessionManager().ListSessions()); got != 2 {
+			t.Errorf("expected the started sessions to keep running, got %d", got)
+		}
+		if compound, ok := srv.GetSessionManager().GetCompoundSession("all"); !ok || len(compound.SessionIDs) != 2 {
+			t.Errorf("expected the kept sessions to be tracked as the compound, got %+v", compound)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, text, isErr := launchCompound(t, map[string]interface{}{"compoundName": "all", "onPartialFailure": "ignore"})
+		if !isErr || !strings.Contains(text, "'onPartialFailure'") {
+			t.Errorf("expected onPartialFailure to be rejected, got %s", text)
+		}
+		_, text, isErr = launchCompound(t, map[string]interface{}{"compoundName": "missing"})
+		if !isErr || !strings.Contains(text, "api only, all") {
+			t.Errorf("expected the compounds to be listed, got %s", text)
+		}
+	})
+}
+
+// TestDebugLaunch_SourceMap verifies that debug_launch's sourceMap reaches
+// lldb's launch request and becomes substitute-path commands for GDB, run
+// before the launch.
+func TestDebugLaunch_SourceMap(t *testing.T) {
+	launch := func(t *testing.T, native adapters.Adapter, sourceMap string) (*fakeAdapter, string, bool) {
+		t.Helper()
+		fake, client := newFakeAdapter(t)
+		srv := newLaunchServer(t, fake, client, types.LanguageC)
+		srv.GetAdapterRegistry().Register(types.LanguageC, &nativeArgsAdapter{
+			launchableAdapter: &launchableAdapter{lang: types.LanguageC, client: client},
+			native:            native,
+		})
+		fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+			return &dap.EvaluateResponse{}
+		})
+		text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+			"language": "c", "program": "/src/app", "sourceMap": sourceMap,
+		})
+		return fake, text, isErr
+	}
+
+	t.Run("lldb", func(t *testing.T) {
+		fake, text, isErr := launch(t, adapters.NewLLDBAdapter(config.LLDBConfig{}), `{"/build/src": "/home/me/src"}`)
+		if isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		launches := fake.received("launch")
+		if len(launches) != 1 {
+			t.Fatalf("expected one launch request, got %d", len(launches))
+		}
+		var args struct {
+			SourceMap [][]string `json:"sourceMap"`
+		}
+		if err := json.Unmarshal(launches[0].(*dap.LaunchRequest).Arguments, &args); err != nil {
+			t.Fatalf("failed to decode launch arguments: %v", err)
+		}
+		if len(args.SourceMap) != 1 || args.SourceMap[0][0] != "/build/src" || args.SourceMap[0][1] != "/home/me/src" {
+			t.Errorf("expected the source map in the launch request, got %v", args.SourceMap)
+		}
+	})
+
+	t.Run("gdb", func(t *testing.T) {
+		fake, text, isErr := launch(t, adapters.NewGDBAdapter(config.GDBConfig{}), `[["/build/src", "/home/me/src"]]`)
+		if isErr {
+			t.Fatalf("debug_launch failed: %s", text)
+		}
+		evaluates := fake.received("evaluate")
+		if len(evaluates) != 1 {
+			t.Fatalf("expected one setup command, got %d", len(evaluates))
+		}
+		req := evaluates[0].(*dap.EvaluateRequest)
+		if req.Arguments.Expression != `set substitute-path "/build/src" "/home/me/src"` || req.Arguments.Context != "repl" {
+			t.Errorf("unexpected setup command %+v", req.Arguments)
+		}
+		if req.Seq > fake.received("launch")[0].GetRequest().Seq {
+			t.Error("expected the setup command before the launch request")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fake, text, isErr := launch(t, adapters.NewLLDBAdapter(config.LLDBConfig{}), `[["/build/src"]]`)
+		if !isErr || !strings.Contains(text, "sourceMap") {
+			t.Errorf("expected a sourceMap error, got %s", text)
+		}
+		if n := len(fake.received("launch")); n != 0 {
+			t.Errorf("expected no launch, got %d", n)
+		}
+	})
+}
+
+// TestDebugLaunch_LogpointEvaluationPolicy verifies that emulated logpoints
+// evaluate their messages under the evaluation policy: with read-only
+// evaluation, expressions with side effects are logged as written.
+func TestDebugLaunch_LogpointEvaluationPolicy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.AllowExecute = false
+	cfg.EvaluateReadOnly = true
+
+	fake, client := newFakeAdapter(t)
+	srv := newLaunchServerWithConfig(t, cfg, fake, client, types.LanguagePython)
+	fake.handle("setBreakpoints", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.SetBreakpointsResponse{Body: dap.SetBreakpointsResponseBody{
+			Breakpoints: []dap.Breakpoint{{Id: 1, Verified: true, Line: 10}},
+		}}
+	})
+	fake.handle("stackTrace", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.StackTraceResponse{Body: dap.StackTraceResponseBody{
+			StackFrames: []dap.StackFrame{{Id: 1000, Name: "loop", Line: 10, Source: &dap.Source{Path: "/src/app.py"}}},
+		}}
+	})
+	fake.handle("evaluate", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: "42"}}
+	})
+	fake.handle("continue", func(req dap.RequestMessage) dap.ResponseMessage {
+		return &dap.ContinueResponse{}
+	})
+
+	if text, isErr := callTool(t, srv, "debug_launch", map[string]interface{}{
+		"language":    "python",
+		"program":     "/src/app.py",
+		"breakpoints": `[{"path": "/src/app.py", "line": 10, "logMessage": "n = {n}, next = {advance()}"}]`,
+	}); isErr {
+		t.Fatalf("debug_launch failed: %s", text)
+	}
+
+	fake.sendEvent(&dap.StoppedEvent{
+		Event: dap.Event{Event: "stopped"},
+		Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, HitBreakpointIds: []int{1}},
+	})
+	for deadline := time.Now().Add(2 * time.Second); len(fake.received("continue")) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the logpoint stop was not continued")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	entries, _ := client.Output()
+	if len(entries) != 1 || entries[0].Output != "n = 42, next = {advance()}\n" {
+		t.Errorf("expected the call to be logged as written, got %+v", entries)
+	}
+	evaluated := fake.received("evaluate")
+	if len(evaluated) != 1 || evaluated[0].(*dap.EvaluateRequest).Arguments.Expression != "n" {
+		t.Errorf("expected only n to be evaluated, got %d requests", len(evaluated))
+	}
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ctagard/dap-mcp/internal/install"
+)
+
+// tarGz builds a gzipped tarball from file names and contents.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// serveJSDebugRelease serves a GitHub release with a js-debug-dap asset.
+func serveJSDebugRelease(t *testing.T, archive []byte) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/release":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"tag_name": "v1.2.3",
+				"assets": []map[string]string{
+					{"name": "js-debug-1.2.3.vsix", "browser_download_url": srv.URL + "/wrong"},
+					{"name": "js-debug-dap-v1.2.3.tar.gz", "browser_download_url": srv.URL + "/asset"},
+				},
+			})
+		case "/asset":
+			w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestInstallJSDebug verifies vscode-js-debug is downloaded from the latest
+// release and unpacked, replacing an earlier install.
+func TestInstallJSDebug(t *testing.T) {
+	archive := tarGz(t, map[string]string{
+		"js-debug/src/dapDebugServer.js": "// server",
+		"js-debug/package.json":          "{}",
+	})
+	srv := serveJSDebugRelease(t, archive)
+
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "js-debug", "stale.js")
+	os.MkdirAll(filepath.Dir(stale), 0o755)
+	os.WriteFile(stale, nil, 0o644)
+
+	var progress bytes.Buffer
+	result, err := install.Install(context.Background(), "typescript", install.Options{
+		Dir:        dir,
+		ReleaseURL: srv.URL + "/release",
+		Progress:   &progress,
+	})
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	want := filepath.Join(dir, "js-debug", "src", "dapDebugServer.js")
+	if result.Path != want {
+		t.Errorf("expected path %s, got %s", want, result.Path)
+	}
+	if result.ConfigKey != "adapters.node.jsDebugPath" || result.Adapter != "vscode-js-debug" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("expected the earlier install to be removed")
+	}
+	if !strings.Contains(progress.String(), "js-debug-dap-v1.2.3.tar.gz") {
+		t.Errorf("expected progress to name the asset, got %q", progress.String())
+	}
+}
+
+// TestInstallJSDebug_Rejected verifies archives without the DAP server, or
+// with entries escaping the install directory, fail the install.
+func TestInstallJSDebug_Rejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"missing server", map[string]string{"js-debug/package.json": "{}"}, "did not contain"},
+		{"path traversal", map[string]string{"../escape.js": "x"}, "outside the install directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveJSDebugRelease(t, tarGz(t, tt.files))
+			dir := filepath.Join(t.TempDir(), "install")
+
+			_, err := install.Install(context.Background(), "javascript", install.Options{
+				Dir:        dir,
+				ReleaseURL: srv.URL + "/release",
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want, err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.js")); err == nil {
+				t.Error("archive entry was written outside the install directory")
+			}
+		})
+	}
+}
+
+// TestInstall_Unsupported verifies languages without an installer get
+// manual instructions.
+func TestInstall_Unsupported(t *testing.T) {
+	tests := map[string]string{
+		"elixir": "elixir-ls/releases",
+		"rust":   "package manager",
+		"cobol":  "no installer",
+	}
+	for lang, want := range tests {
+		_, err := install.Install(context.Background(), lang, install.Options{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", lang, want, err)
+		}
+	}
+}
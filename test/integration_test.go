@@ -369,6 +369,108 @@ func TestPythonDebugSession(t *testing.T) {
 	})
 }
 
+func TestGoAttachByPID(t *testing.T) {
+	// Find server binary
+	serverPath := filepath.Join("..", "bin", "dap-mcp")
+	if _, err := os.Stat(serverPath); os.IsNotExist(err) {
+		t.Skip("Server binary not found. Run 'make build' first.")
+	}
+	if _, err := exec.LookPath("dlv"); err != nil {
+		t.Skip("dlv not found. Install Delve to run this test.")
+	}
+
+	// Build a long-running Go program to attach to
+	tmpDir := t.TempDir()
+	source := "package main\n\nimport \"time\"\n\nfunc main() {\n\tfor {\n\t\ttime.Sleep(100 * time.Millisecond)\n\t}\n}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write target program: %v", err)
+	}
+	binPath := filepath.Join(tmpDir, "target")
+	build := exec.Command("go", "build", "-gcflags=all=-N -l", "-o", binPath, "main.go")
+	build.Dir = tmpDir
+	build.Env = append(os.Environ(), "GO111MODULE=off")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Skipf("Failed to build target program: %v\n%s", err, out)
+	}
+
+	target := exec.Command(binPath)
+	if err := target.Start(); err != nil {
+		t.Fatalf("Failed to start target program: %v", err)
+	}
+	defer func() {
+		_ = target.Process.Kill()
+		_ = target.Wait()
+	}()
+
+	// Start client
+	client, err := NewMCPClient(serverPath)
+	if err != nil {
+		t.Fatalf("Failed to start MCP client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.SendRequest("initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "test",
+			"version": "1.0.0",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if resp["error"] != nil {
+		t.Fatalf("Initialize error: %v", resp["error"])
+	}
+
+	// Attach by PID only; no port means a local Delve is spawned
+	resp, err = client.SendRequest("tools/call", map[string]interface{}{
+		"name": "debug_attach",
+		"arguments": map[string]interface{}{
+			"language": "go",
+			"pid":      target.Process.Pid,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if resp["error"] != nil {
+		t.Fatalf("Attach returned error: %v", resp["error"])
+	}
+
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if isErr, _ := result["isError"].(bool); isErr {
+		t.Fatalf("Attach tool error: %s", text)
+	}
+
+	var attachResult map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &attachResult); err != nil {
+		t.Fatalf("Failed to parse attach result: %v", err)
+	}
+	sessionID, ok := attachResult["sessionId"].(string)
+	if !ok || sessionID == "" {
+		t.Fatalf("No session ID in attach result: %v", attachResult)
+	}
+	t.Logf("Attached to pid %d: %s", target.Process.Pid, text)
+
+	// Detach without killing the target
+	resp, err = client.SendRequest("tools/call", map[string]interface{}{
+		"name": "debug_disconnect",
+		"arguments": map[string]interface{}{
+			"sessionId":         sessionID,
+			"terminateDebuggee": false,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Disconnect failed: %v", err)
+	}
+	if resp["error"] != nil {
+		t.Fatalf("Disconnect returned error: %v", resp["error"])
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -1,9 +1,13 @@
 package test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ctagard/dap-mcp/internal/launchconfig"
 )
@@ -84,6 +88,72 @@ func TestLoadFromPath_InvalidJSON(t *testing.T) {
 	}
 }
 
+// TestLoadFromPath_Comments verifies that VS Code style comments and trailing
+// commas are accepted.
+func TestLoadFromPath_Comments(t *testing.T) {
+	tmpDir := t.TempDir()
+	launchPath := filepath.Join(tmpDir, "launch.json")
+	content := `{
+	// Use IntelliSense to learn about possible attributes.
+	// For more information, visit: https://go.microsoft.com/fwlink/?linkid=830387
+	"version": "0.2.0",
+	"configurations": [
+		{
+			"type": "node",
+			"request": "launch",
+			"name": "Launch App", /* inline comment */
+			"program": "${workspaceFolder}/app.js",
+			"url": "http://localhost:3000/*not-a-comment*/",
+			"args": ["--quote=\"//\"", "a,]"],
+		},
+		/*
+		{
+			"type": "go",
+			"name": "Disabled"
+		},
+		*/
+	],
+}
+`
+	if err := os.WriteFile(launchPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+
+	lj, err := launchconfig.LoadFromPath(launchPath)
+	if err != nil {
+		t.Fatalf("failed to load commented launch.json: %v", err)
+	}
+
+	if len(lj.Configurations) != 1 {
+		t.Fatalf("expected 1 configuration, got %d", len(lj.Configurations))
+	}
+	cfg := lj.Configurations[0]
+	if cfg.Name != "Launch App" {
+		t.Errorf("expected name 'Launch App', got %q", cfg.Name)
+	}
+	if cfg.URL != "http://localhost:3000/*not-a-comment*/" {
+		t.Errorf("expected comment-like text in strings to be kept, got url %q", cfg.URL)
+	}
+	if len(cfg.Args) != 2 || cfg.Args[0] != `--quote="//"` || cfg.Args[1] != "a,]" {
+		t.Errorf("expected string contents to be kept, got args %#v", cfg.Args)
+	}
+}
+
+// TestLoadFromPath_CommentedInvalidJSON verifies that malformed comments are still errors.
+func TestLoadFromPath_CommentedInvalidJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	launchPath := filepath.Join(tmpDir, "launch.json")
+	// An unterminated block comment swallows the rest of the file
+	content := `{"version": "0.2.0", /* "configurations": []}`
+	if err := os.WriteFile(launchPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+
+	if _, err := launchconfig.LoadFromPath(launchPath); err == nil {
+		t.Error("expected error for unterminated comment")
+	}
+}
+
 // TestLoadFromPath_NonExistent verifies error handling for missing files.
 func TestLoadFromPath_NonExistent(t *testing.T) {
 	_, err := launchconfig.LoadFromPath("/nonexistent/path/launch.json")
@@ -256,6 +326,63 @@ func TestResolveVariables_EmptyEnv(t *testing.T) {
 	}
 }
 
+// TestResolveVariables_AdapterVariables verifies variables an adapter defines
+// resolve alongside the launch.json ones.
+func TestResolveVariables_AdapterVariables(t *testing.T) {
+	ctx := &launchconfig.ResolutionContext{
+		WorkspaceFolder: "/home/user/project",
+		Variables:       map[string]string{"webRoot": "/home/user/project/web"},
+	}
+
+	result, err := launchconfig.ResolveVariables("${webRoot}/src/*|${workspaceFolder}/lib/*", ctx)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if result != "/home/user/project/web/src/*|/home/user/project/lib/*" {
+		t.Errorf("unexpected result %q", result)
+	}
+
+	if _, err := launchconfig.ResolveVariables("${outDir}", ctx); err == nil {
+		t.Error("expected error for a variable nobody defines")
+	}
+}
+
+// TestResolveVariables_NamedWorkspaceFolder verifies ${workspaceFolder:name}
+// resolution for multi-root workspaces.
+func TestResolveVariables_NamedWorkspaceFolder(t *testing.T) {
+	ctx := &launchconfig.ResolutionContext{
+		WorkspaceFolder: "/repo/web",
+		WorkspaceFolders: map[string]string{
+			"backend": "/repo/services/api",
+		},
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"${workspaceFolder:backend}/main.go", "/repo/services/api/main.go"},
+		{"${workspaceFolderBasename:backend}", "api"},
+		// The workspace folder can be named without being listed
+		{"${workspaceFolder:web}/index.js", "/repo/web/index.js"},
+	}
+	for _, tt := range tests {
+		result, err := launchconfig.ResolveVariables(tt.input, ctx)
+		if err != nil {
+			t.Errorf("ResolveVariables(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("ResolveVariables(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+
+	_, err := launchconfig.ResolveVariables("${workspaceFolder:frontend}/app", ctx)
+	if err == nil || !strings.Contains(err.Error(), `"frontend"`) || !strings.Contains(err.Error(), "backend") {
+		t.Errorf("expected an unknown folder error listing the known folders, got %v", err)
+	}
+}
+
 // TestResolveConfiguration verifies full configuration resolution with variables.
 func TestResolveConfiguration(t *testing.T) {
 	cfg := &launchconfig.DebugConfiguration{
@@ -341,6 +468,13 @@ func TestTypeToLanguageMapping(t *testing.T) {
 		{"chrome", "javascript"},
 		{"pwa-chrome", "javascript"},
 		{"msedge", "javascript"},
+		{"swift", "swift"},
+		{"mix_task", "elixir"},
+		{"native", "native"},
+		{"node-terminal", "javascript"},
+		{"node2", "javascript"},
+		{"lldb-vscode", "c"},
+		{"swift-lldb", "swift"},
 		{"unknown", "unknown"},
 	}
 
@@ -355,6 +489,57 @@ func TestTypeToLanguageMapping(t *testing.T) {
 	}
 }
 
+// TestGetTarget_LessCommonTypes verifies the targets and debuggers of the
+// less common VS Code types.
+func TestGetTarget_LessCommonTypes(t *testing.T) {
+	tests := []struct {
+		cfgType  string
+		target   string
+		debugger string
+	}{
+		{"node-terminal", "terminal", ""},
+		{"node2", "node", ""},
+		{"lldb-vscode", "", "lldb"},
+		{"swift-lldb", "", "lldb"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.cfgType, func(t *testing.T) {
+			cfg := &launchconfig.DebugConfiguration{Type: tc.cfgType}
+			if got := cfg.GetTarget(); got != tc.target {
+				t.Errorf("GetTarget for type %q = %q, want %q", tc.cfgType, got, tc.target)
+			}
+			if got := cfg.GetNativeDebugger(); got != tc.debugger {
+				t.Errorf("GetNativeDebugger for type %q = %q, want %q", tc.cfgType, got, tc.debugger)
+			}
+		})
+	}
+}
+
+// TestCheckType verifies unsupported and unknown types are reported with
+// the list of supported types.
+func TestCheckType(t *testing.T) {
+	if err := (&launchconfig.DebugConfiguration{Type: "node-terminal"}).CheckType(); err != nil {
+		t.Errorf("expected node-terminal to be supported, got %v", err)
+	}
+
+	err := (&launchconfig.DebugConfiguration{Type: "extensionHost"}).CheckType()
+	if err == nil || !strings.Contains(err.Error(), "only be started by VS Code") || !strings.Contains(err.Error(), "pwa-node") {
+		t.Errorf("expected an unsupported type error with the reason and supported types, got %v", err)
+	}
+
+	err = (&launchconfig.DebugConfiguration{Type: "rdbg"}).CheckType()
+	if err == nil || !strings.Contains(err.Error(), `unknown debug type "rdbg"`) || !strings.Contains(err.Error(), "debugpy") {
+		t.Errorf("expected an unknown type error listing supported types, got %v", err)
+	}
+
+	// Validation reports the type too
+	err = launchconfig.ValidateConfiguration(&launchconfig.DebugConfiguration{Name: "ext", Type: "extensionHost", Request: "launch"})
+	if err == nil {
+		t.Error("expected an extensionHost configuration to fail validation")
+	}
+}
+
 // TestIsBrowserTarget verifies browser target detection.
 func TestIsBrowserTarget(t *testing.T) {
 	browserTypes := []string{"chrome", "pwa-chrome", "msedge", "pwa-msedge"}
@@ -413,6 +598,33 @@ func TestToLaunchArgs(t *testing.T) {
 	}
 }
 
+// TestToLaunchArgs_GDB verifies GDB-specific fields survive resolution.
+func TestToLaunchArgs_GDB(t *testing.T) {
+	cfg := &launchconfig.DebugConfiguration{
+		Type:                            "gdb",
+		Request:                         "launch",
+		Name:                            "GDB",
+		Program:                         "${workspaceFolder}/prog",
+		StopAtBeginningOfMainSubprogram: true,
+		SourceMap:                       [][]string{{"/build", "${workspaceFolder}/src"}},
+	}
+	resolved, err := launchconfig.ResolveConfiguration(cfg, &launchconfig.ResolutionContext{WorkspaceFolder: "/ws"})
+	if err != nil {
+		t.Fatalf("ResolveConfiguration failed: %v", err)
+	}
+
+	args := resolved.ToLaunchArgs()
+	if args["stopAtBeginningOfMainSubprogram"] != true {
+		t.Errorf("expected stopAtBeginningOfMainSubprogram, got %v", args["stopAtBeginningOfMainSubprogram"])
+	}
+	if args["program"] != "/ws/prog" {
+		t.Errorf("expected resolved program, got %v", args["program"])
+	}
+	if got, ok := args["sourceMap"].([][]string); !ok || len(got) != 1 || got[0][1] != "/ws/src" {
+		t.Errorf("expected the resolved source map, got %v", args["sourceMap"])
+	}
+}
+
 // TestToAttachArgs verifies conversion to attach arguments map.
 func TestToAttachArgs(t *testing.T) {
 	resolved := &launchconfig.ResolvedConfiguration{
@@ -438,6 +650,19 @@ func TestToAttachArgs(t *testing.T) {
 	if args["processId"] != 1234 {
 		t.Errorf("expected processId 1234, got %v", args["processId"])
 	}
+
+	// waitFor attaches to the next process started with the program's name
+	resolved = &launchconfig.ResolvedConfiguration{
+		DebugConfiguration: &launchconfig.DebugConfiguration{
+			Program: "/usr/local/bin/worker",
+			WaitFor: true,
+		},
+		Language: "c",
+	}
+	args = resolved.ToAttachArgs()
+	if args["waitFor"] != true || args["program"] != "/usr/local/bin/worker" {
+		t.Errorf("expected waitFor with the program, got %v", args)
+	}
 }
 
 // TestMergeOverrides verifies configuration override merging.
@@ -455,7 +680,10 @@ func TestMergeOverrides(t *testing.T) {
 		"newField": "value",
 	}
 
-	merged := launchconfig.MergeOverrides(cfg, overrides)
+	merged, err := launchconfig.MergeOverrides(cfg, overrides)
+	if err != nil {
+		t.Fatalf("MergeOverrides failed: %v", err)
+	}
 
 	if merged.Program != "/override/path.py" {
 		t.Errorf("expected overridden program, got %s", merged.Program)
@@ -475,6 +703,107 @@ func TestMergeOverrides(t *testing.T) {
 	}
 }
 
+// TestMergeOverrides_StringArgs verifies that a string args override is shell-split.
+func TestMergeOverrides_StringArgs(t *testing.T) {
+	cfg := &launchconfig.DebugConfiguration{Type: "python", Request: "launch", Args: []string{"--original"}}
+
+	merged, err := launchconfig.MergeOverrides(cfg, map[string]interface{}{
+		"args": `--name "John Smith" --path '/tmp/my dir'`,
+	})
+	if err != nil {
+		t.Fatalf("MergeOverrides failed: %v", err)
+	}
+	want := []string{"--name", "John Smith", "--path", "/tmp/my dir"}
+	if strings.Join(merged.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("expected args %q, got %q", want, merged.Args)
+	}
+
+	// An unterminated quote is reported, as it is for launch.json and debug_launch
+	if _, err := launchconfig.MergeOverrides(cfg, map[string]interface{}{"args": `--name "John`}); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+// TestSplitArgs verifies shell-style splitting of command-line strings.
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  []string
+	}{
+		{"", []string{}},
+		{"  -v   --port 8080 ", []string{"-v", "--port", "8080"}},
+		{`--name "John Smith"`, []string{"--name", "John Smith"}},
+		{`--msg 'it''s'`, []string{"--msg", "its"}},
+		{`'a "quoted" word'`, []string{`a "quoted" word`}},
+		{`"say \"hi\" \\ \n"`, []string{`say "hi" \ \n`}},
+		{`one\ arg two`, []string{"one arg", "two"}},
+		{`--empty "" x`, []string{"--empty", "", "x"}},
+		{`--opt="a b"c`, []string{"--opt=a bc"}},
+	}
+
+	for _, tc := range testCases {
+		got, err := launchconfig.SplitArgs(tc.input)
+		if err != nil {
+			t.Errorf("SplitArgs(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if len(got) != len(tc.want) || strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("SplitArgs(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+
+	for _, input := range []string{`"unterminated`, `it's`} {
+		if _, err := launchconfig.SplitArgs(input); err == nil {
+			t.Errorf("expected an error for unterminated quote in %q", input)
+		}
+	}
+}
+
+// TestLoadFromPath_StringArgs verifies that launch.json args may be a single string.
+func TestLoadFromPath_StringArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	launchPath := filepath.Join(tmpDir, "launch.json")
+	content := `{
+		"version": "0.2.0",
+		"configurations": [
+			{"type": "go", "request": "launch", "name": "String", "program": "${workspaceFolder}", "args": "serve --config \"${workspaceFolder}/my config.yaml\""},
+			{"type": "go", "request": "launch", "name": "Array", "program": "${workspaceFolder}", "args": ["serve", "-v"]}
+		]
+	}`
+	if err := os.WriteFile(launchPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+
+	lj, err := launchconfig.LoadFromPath(launchPath)
+	if err != nil {
+		t.Fatalf("failed to load launch.json: %v", err)
+	}
+
+	cfg, err := launchconfig.FindConfiguration(lj, "String")
+	if err != nil {
+		t.Fatalf("FindConfiguration failed: %v", err)
+	}
+	resolved, err := launchconfig.ResolveConfiguration(cfg, &launchconfig.ResolutionContext{WorkspaceFolder: "/ws"})
+	if err != nil {
+		t.Fatalf("ResolveConfiguration failed: %v", err)
+	}
+	args, ok := resolved.ToLaunchArgs()["args"].([]string)
+	if !ok || len(args) != 3 || args[0] != "serve" || args[2] != "/ws/my config.yaml" {
+		t.Errorf("expected split and resolved args, got %#v", resolved.ToLaunchArgs()["args"])
+	}
+
+	if cfg, err := launchconfig.FindConfiguration(lj, "Array"); err != nil || len(cfg.Args) != 2 {
+		t.Errorf("expected array args to still load, got %v (%v)", cfg, err)
+	}
+
+	if err := os.WriteFile(launchPath, []byte(`{"configurations": [{"type": "go", "request": "launch", "name": "Bad", "args": "\"unterminated"}]}`), 0644); err != nil {
+		t.Fatalf("failed to write launch.json: %v", err)
+	}
+	if _, err := launchconfig.LoadFromPath(launchPath); err == nil {
+		t.Error("expected an error for args with an unterminated quote")
+	}
+}
+
 // TestFindAllRequiredInputsInConfig verifies input variable detection.
 func TestFindAllRequiredInputsInConfig(t *testing.T) {
 	cfg := &launchconfig.DebugConfiguration{
@@ -652,6 +981,45 @@ func TestListCompounds(t *testing.T) {
 	}
 }
 
+// TestValidationWarnings verifies pre-flight checks for version, compounds, and inputs.
+func TestValidationWarnings(t *testing.T) {
+	valid := &launchconfig.LaunchJSON{
+		Version: "0.2.0",
+		Configurations: []launchconfig.DebugConfiguration{
+			{Type: "python", Request: "launch", Name: "Tests", Program: "${input:testFile}"},
+			{Type: "go", Request: "launch", Name: "Server"},
+		},
+		Compounds: []launchconfig.CompoundConfig{
+			{Name: "All", Configurations: []string{"Tests", "Server"}},
+		},
+		Inputs: []launchconfig.InputConfig{
+			{ID: "testFile", Type: "promptString"},
+		},
+	}
+	if warnings := launchconfig.ValidationWarnings(valid); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+
+	invalid := &launchconfig.LaunchJSON{
+		Version: "0.1.0",
+		Configurations: []launchconfig.DebugConfiguration{
+			{Type: "python", Request: "launch", Name: "Tests", Args: []string{"${input:missing}"}},
+		},
+		Compounds: []launchconfig.CompoundConfig{
+			{Name: "All", Configurations: []string{"Tests", "Client"}},
+		},
+	}
+	warnings := launchconfig.ValidationWarnings(invalid)
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %d: %v", len(warnings), warnings)
+	}
+	for i, want := range []string{`"0.1.0"`, `"Client"`, `"missing"`} {
+		if !strings.Contains(warnings[i], want) {
+			t.Errorf("warning %d: expected %s in %q", i, want, warnings[i])
+		}
+	}
+}
+
 // TestGetWorkspaceFolder verifies workspace folder extraction from launch.json path.
 func TestGetWorkspaceFolder(t *testing.T) {
 	tests := []struct {
@@ -694,6 +1062,65 @@ func TestMissingInputsError(t *testing.T) {
 	}
 }
 
+// TestResolveConfiguration_InputFallbacks verifies input defaults and command inputs.
+func TestResolveConfiguration_InputFallbacks(t *testing.T) {
+	cfg := &launchconfig.DebugConfiguration{
+		Type:    "python",
+		Request: "launch",
+		Name:    "Tests",
+		Program: "${input:testFile}",
+		Args:    []string{"--filter", "${input:filter}"},
+	}
+	inputs := []launchconfig.InputConfig{
+		{ID: "testFile", Type: "pickString", Description: "Test file", Options: []string{"a.py", "b.py"}, Default: "a.py"},
+		{ID: "filter", Type: "command", Description: "Test filter", Command: "echo smoke"},
+	}
+
+	// Commands are not run unless allowed
+	_, err := launchconfig.ResolveConfiguration(cfg, &launchconfig.ResolutionContext{Inputs: inputs})
+	missing, ok := launchconfig.IsMissingInputsError(err)
+	if !ok {
+		t.Fatalf("expected MissingInputsError, got %v", err)
+	}
+	if len(missing.Inputs) != 1 || missing.Inputs[0] != "filter" {
+		t.Fatalf("expected only filter to be missing, got %v", missing.Inputs)
+	}
+	if missing.Definitions[0].Description != "Test filter" || missing.Definitions[0].Type != "command" {
+		t.Errorf("expected filter definition in error, got %+v", missing.Definitions[0])
+	}
+
+	ctx := &launchconfig.ResolutionContext{
+		Inputs:        inputs,
+		AllowCommands: true,
+		InputValues:   map[string]string{},
+	}
+	resolved, err := launchconfig.ResolveConfiguration(cfg, ctx)
+	if err != nil {
+		t.Fatalf("ResolveConfiguration failed: %v", err)
+	}
+	if resolved.Program != "a.py" {
+		t.Errorf("expected default a.py, got %s", resolved.Program)
+	}
+	if len(resolved.Args) != 2 || resolved.Args[1] != "smoke" {
+		t.Errorf("expected command output in args, got %v", resolved.Args)
+	}
+	if len(ctx.InputValues) != 0 {
+		t.Errorf("expected caller's input values to be left unchanged, got %v", ctx.InputValues)
+	}
+
+	// Provided values take precedence over defaults
+	resolved, err = launchconfig.ResolveConfiguration(cfg, &launchconfig.ResolutionContext{
+		Inputs:      inputs,
+		InputValues: map[string]string{"testFile": "b.py", "filter": "all"},
+	})
+	if err != nil {
+		t.Fatalf("ResolveConfiguration failed: %v", err)
+	}
+	if resolved.Program != "b.py" || resolved.Args[1] != "all" {
+		t.Errorf("expected provided values, got program %s args %v", resolved.Program, resolved.Args)
+	}
+}
+
 // TestResolveExtraFields verifies resolution of variables in Extra fields.
 func TestResolveExtraFields(t *testing.T) {
 	ctx := &launchconfig.ResolutionContext{
@@ -747,3 +1174,224 @@ func TestResolveExtraFields(t *testing.T) {
 		t.Errorf("expected bool to pass through, got %v", resolved.Extra["boolField"])
 	}
 }
+
+// TestResolveExtraFields_DepthLimit verifies deeply nested and self-referential
+// Extra fields fail to resolve instead of exhausting the stack.
+func TestResolveExtraFields_DepthLimit(t *testing.T) {
+	ctx := &launchconfig.ResolutionContext{WorkspaceFolder: "/home/user/project"}
+
+	nest := func(levels int) interface{} {
+		var v interface{} = "${workspaceFolder}"
+		for i := 0; i < levels; i++ {
+			if i%2 == 0 {
+				v = map[string]interface{}{"next": v}
+			} else {
+				v = []interface{}{v}
+			}
+		}
+		return v
+	}
+	resolve := func(extra map[string]interface{}) error {
+		_, err := launchconfig.ResolveConfiguration(&launchconfig.DebugConfiguration{
+			Type:    "custom",
+			Request: "launch",
+			Name:    "Custom",
+			Extra:   extra,
+		}, ctx)
+		return err
+	}
+
+	if err := resolve(map[string]interface{}{"field": nest(10)}); err != nil {
+		t.Errorf("expected moderate nesting to resolve, got %v", err)
+	}
+
+	err := resolve(map[string]interface{}{"field": nest(1000)})
+	if err == nil || !strings.Contains(err.Error(), "nested more than") {
+		t.Errorf("expected a nesting error, got %v", err)
+	}
+
+	self := map[string]interface{}{}
+	self["self"] = self
+	err = resolve(map[string]interface{}{"field": self})
+	if err == nil || !strings.Contains(err.Error(), "nested more than") {
+		t.Errorf("expected a nesting error for a self-referential map, got %v", err)
+	}
+}
+
+// TestResolveVariables_SizeLimit verifies a value that resolves to an
+// oversized string is rejected.
+func TestResolveVariables_SizeLimit(t *testing.T) {
+	ctx := &launchconfig.ResolutionContext{
+		Variables: map[string]string{"big": strings.Repeat("x", 1<<19)},
+	}
+
+	if _, err := launchconfig.ResolveVariables("${big}", ctx); err != nil {
+		t.Errorf("expected a value under the limit to resolve, got %v", err)
+	}
+	if _, err := launchconfig.ResolveVariables("${big}${big}${big}", ctx); err == nil {
+		t.Error("expected an error for a value over the limit")
+	}
+}
+
+// TestLoadTasks verifies tasks.json is found next to launch.json and its
+// background ready patterns are read.
+func TestLoadTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	vscodeDir := filepath.Join(tmpDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatalf("failed to create .vscode dir: %v", err)
+	}
+	tasksJSON := `{
+		// Comments and trailing commas are allowed
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "build", "type": "shell", "command": "make"},
+			{"label": "watch", "type": "shell", "command": "webpack --watch", "isBackground": true,
+			 "problemMatcher": {"owner": "webpack", "background": {"beginsPattern": "compiling", "endsPattern": "compiled successfully"}}},
+			{"label": "tsc", "type": "shell", "command": "tsc -w", "isBackground": true,
+			 "problemMatcher": ["$tsc", {"background": {"endsPattern": {"regexp": "Watching for file changes"}}}]},
+			{"label": "serve", "type": "process", "command": "serve", "isBackground": true,
+			 "readyPattern": "listening on", "problemMatcher": {"background": {"endsPattern": "ignored"}}},
+			{"label": "named", "type": "shell", "command": "tsc -w", "isBackground": true, "problemMatcher": "$tsc-watch"},
+		],
+	}`
+	if err := os.WriteFile(filepath.Join(vscodeDir, "tasks.json"), []byte(tasksJSON), 0644); err != nil {
+		t.Fatalf("failed to write tasks.json: %v", err)
+	}
+
+	path := launchconfig.TasksPathFor(filepath.Join(vscodeDir, "launch.json"))
+	tj, err := launchconfig.LoadTasksFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadTasksFromPath failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"build": "",
+		"watch": "compiled successfully",
+		"tsc":   "Watching for file changes",
+		"serve": "listening on",
+		"named": "",
+	}
+	for label, want := range tests {
+		task, err := launchconfig.FindTask(tj, label)
+		if err != nil {
+			t.Fatalf("FindTask(%q) failed: %v", label, err)
+		}
+		if got := task.BackgroundReadyPattern(); got != want {
+			t.Errorf("task %q: expected ready pattern %q, got %q", label, want, got)
+		}
+	}
+	if _, err := launchconfig.FindTask(tj, "missing"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
+
+// TestRunTask verifies foreground tasks are awaited and report their output
+// when they fail.
+func TestRunTask(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	dir := t.TempDir()
+
+	task := &launchconfig.TaskConfig{Label: "build", Type: "shell", Command: "echo built > out.txt"}
+	if err := launchconfig.RunTask(task, dir, 10*time.Second); err != nil {
+		t.Fatalf("RunTask failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "out.txt")); err != nil || strings.TrimSpace(string(data)) != "built" {
+		t.Errorf("expected the task to run in the workspace, got %q (%v)", data, err)
+	}
+
+	task = &launchconfig.TaskConfig{Label: "broken", Type: "shell", Command: "echo 'main.go:3: undefined: x' >&2; exit 2"}
+	err := launchconfig.RunTask(task, dir, 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "undefined: x") || !strings.Contains(err.Error(), "exit status 2") {
+		t.Errorf("expected the failure with its output, got %v", err)
+	}
+
+	// A compiler can print errors and still exit 0
+	quiet := "echo 'main.c:3:5: warning: unused x'; echo 'main.c:4:1: error: expected ;'; exit 0"
+	task = &launchconfig.TaskConfig{Label: "exit-code", Type: "shell", Command: quiet}
+	if err := launchconfig.RunTask(task, dir, 10*time.Second); err != nil {
+		t.Errorf("expected a task without a failure pattern to pass on its exit status, got %v", err)
+	}
+	task = &launchconfig.TaskConfig{Label: "pattern", Type: "shell", Command: quiet, FailurePattern: `: error: `}
+	err = launchconfig.RunTask(task, dir, 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "expected ;") || strings.Contains(err.Error(), "unused x") {
+		t.Errorf("expected the error line to fail the task, got %v", err)
+	}
+	task = &launchconfig.TaskConfig{Label: "matcher", Type: "shell", Command: quiet, ProblemMatcher: json.RawMessage(`["$gcc", {
+		"owner": "cpp",
+		"pattern": {"regexp": "^(.*):(\\d+):(\\d+):\\s+(warning|error):\\s+(.*)$", "file": 1, "line": 2, "severity": 4, "message": 5}
+	}]`)}
+	err = launchconfig.RunTask(task, dir, 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "expected ;") || strings.Contains(err.Error(), "unused x") {
+		t.Errorf("expected the problem matcher's error line to fail the task, got %v", err)
+	}
+	task = &launchconfig.TaskConfig{Label: "warnings", Type: "shell", Command: "echo 'main.c:3:5: warning: unused x'",
+		ProblemMatcher: json.RawMessage(`{"severity": "warning", "pattern": {"regexp": "warning: (.*)"}}`)}
+	if err := launchconfig.RunTask(task, dir, 10*time.Second); err != nil {
+		t.Errorf("expected warnings not to fail the task, got %v", err)
+	}
+
+	task = &launchconfig.TaskConfig{Label: "slow", Type: "shell", Command: "sleep 30"}
+	start := time.Now()
+	if err := launchconfig.RunTask(task, dir, 200*time.Millisecond); err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the timed out task to be killed, took %v", elapsed)
+	}
+}
+
+// TestStartTask verifies background tasks are left running once they print
+// their ready line, and are killed by Stop.
+func TestStartTask(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	dir := t.TempDir()
+
+	task := &launchconfig.TaskConfig{
+		Label:        "watch",
+		Type:         "shell",
+		Command:      "echo compiling; sleep 0.2; echo compiled successfully; sleep 30",
+		IsBackground: true,
+		ReadyPattern: "compiled successfully",
+	}
+	start := time.Now()
+	running, err := launchconfig.StartTask(task, dir, 10*time.Second)
+	if err != nil {
+		t.Fatalf("StartTask failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected StartTask to return once ready, took %v", elapsed)
+	}
+	if running.Exited() {
+		t.Error("expected the background task to keep running")
+	}
+	running.Stop()
+	if !running.Exited() {
+		t.Error("expected Stop to kill the background task")
+	}
+	running.Stop() // Safe to call again
+
+	// A task that exits before it is ready fails with its output
+	task = &launchconfig.TaskConfig{Label: "crash", Type: "shell", Command: "echo port in use; exit 1", IsBackground: true, ReadyPattern: "listening"}
+	if _, err := launchconfig.StartTask(task, dir, 10*time.Second); err == nil || !strings.Contains(err.Error(), "port in use") {
+		t.Errorf("expected an exited-before-ready error with output, got %v", err)
+	}
+
+	// A task that never gets ready is killed after the timeout
+	task = &launchconfig.TaskConfig{Label: "stuck", Type: "shell", Command: "sleep 30", IsBackground: true, ReadyPattern: "listening"}
+	if _, err := launchconfig.StartTask(task, dir, 200*time.Millisecond); err == nil || !strings.Contains(err.Error(), "listening") {
+		t.Errorf("expected a readiness timeout, got %v", err)
+	}
+
+	// Without a ready pattern the task is started without waiting
+	task = &launchconfig.TaskConfig{Label: "nopattern", Type: "shell", Command: "sleep 30", IsBackground: true}
+	running, err = launchconfig.StartTask(task, dir, 10*time.Second)
+	if err != nil {
+		t.Fatalf("StartTask without a pattern failed: %v", err)
+	}
+	running.Stop()
+}
@@ -163,6 +163,43 @@ func TestGDBAdapterRegistry(t *testing.T) {
 	}
 }
 
+// TestGDBAdapter_BuildLaunchArgs verifies launch args from tool JSON and resolved launch.json values
+func TestGDBAdapter_BuildLaunchArgs(t *testing.T) {
+	adapter := adapters.NewGDBAdapter(config.GDBConfig{})
+
+	args := adapter.BuildLaunchArgs("/path/to/prog", map[string]interface{}{
+		"args":                            []string{"--verbose"},
+		"env":                             map[string]string{"FOO": "bar"},
+		"stopAtBeginningOfMainSubprogram": true,
+	})
+	if got, ok := args["args"].([]string); !ok || len(got) != 1 || got[0] != "--verbose" {
+		t.Errorf("expected args [--verbose], got %v", args["args"])
+	}
+	if env, ok := args["env"].(map[string]string); !ok || env["FOO"] != "bar" {
+		t.Errorf("expected env FOO=bar, got %v", args["env"])
+	}
+	if args["stopAtBeginningOfMainSubprogram"] != true {
+		t.Errorf("expected stopAtBeginningOfMainSubprogram, got %v", args["stopAtBeginningOfMainSubprogram"])
+	}
+
+	args = adapter.BuildLaunchArgs("/path/to/prog", map[string]interface{}{
+		"args": []interface{}{"a", 1},
+	})
+	if got, ok := args["args"].([]string); !ok || len(got) != 2 || got[1] != "1" {
+		t.Errorf("expected args [a 1], got %v", args["args"])
+	}
+}
+
+// TestGDBAdapter_BuildAttachArgs verifies attach by process ID
+func TestGDBAdapter_BuildAttachArgs(t *testing.T) {
+	adapter := adapters.NewGDBAdapter(config.GDBConfig{})
+
+	args := adapter.BuildAttachArgs(map[string]interface{}{"pid": float64(4242)})
+	if args["pid"] != 4242 {
+		t.Errorf("expected pid 4242, got %v", args["pid"])
+	}
+}
+
 // findLLDBDap searches for lldb-dap in common locations
 func findLLDBDap() string {
 	// Check PATH first
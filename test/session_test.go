@@ -1,9 +1,12 @@
 package test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	godap "github.com/google/go-dap"
+
 	"github.com/ctagard/dap-mcp/internal/dap"
 	"github.com/ctagard/dap-mcp/pkg/types"
 )
@@ -332,6 +335,32 @@ func TestSession_GetInfo(t *testing.T) {
 	}
 }
 
+// TestSessionManager_SetSessionDebuggee verifies the debuggee process is reported separately.
+func TestSessionManager_SetSessionDebuggee(t *testing.T) {
+	sm := dap.NewSessionManager(10, 30*time.Minute)
+	defer sm.Close()
+
+	session, _ := sm.CreateSession(types.LanguagePython, "/path/to/app.py")
+	if err := sm.SetSessionDebuggee(session.ID, 4321, "app.py"); err != nil {
+		t.Fatalf("SetSessionDebuggee failed: %v", err)
+	}
+
+	info := session.GetInfo()
+	if info.DebuggeePID != 4321 {
+		t.Errorf("expected debuggee PID 4321, got %d", info.DebuggeePID)
+	}
+	if info.DebuggeeName != "app.py" {
+		t.Errorf("expected debuggee name app.py, got %s", info.DebuggeeName)
+	}
+	if info.PID != 0 {
+		t.Errorf("expected adapter PID to be unset, got %d", info.PID)
+	}
+
+	if err := sm.SetSessionDebuggee("nonexistent", 1, "x"); err == nil {
+		t.Error("expected error for non-existent session")
+	}
+}
+
 // TestSessionManager_ConcurrentAccess verifies thread safety.
 func TestSessionManager_ConcurrentAccess(t *testing.T) {
 	sm := dap.NewSessionManager(100, 30*time.Minute)
@@ -378,3 +407,147 @@ func TestSessionManager_Close(t *testing.T) {
 		t.Errorf("expected 0 sessions after close, got %d", len(sessions))
 	}
 }
+
+// TestSessionManager_CloseDetachesAttached verifies Close terminates launched
+// debuggees but only detaches from attached ones, unless configured otherwise.
+func TestSessionManager_CloseDetachesAttached(t *testing.T) {
+	for _, terminateAttached := range []bool{false, true} {
+		sm := dap.NewSessionManager(10, 30*time.Minute)
+		sm.SetTerminateAttached(terminateAttached)
+
+		launchedFake, launchedClient := newFakeAdapter(t)
+		launched, _ := sm.CreateSession(types.LanguageGo, "/path/main.go")
+		_ = sm.SetSessionClient(launched.ID, launchedClient)
+
+		attachedFake, attachedClient := newFakeAdapter(t)
+		attached, _ := sm.CreateSession(types.LanguagePython, "attached")
+		_ = sm.SetSessionClient(attached.ID, attachedClient)
+		_ = sm.SetSessionAttached(attached.ID)
+
+		if !attached.GetInfo().Attached || launched.GetInfo().Attached {
+			t.Fatal("expected only the attached session to be marked attached")
+		}
+
+		sm.Close()
+
+		terminated := func(f *fakeAdapter) bool {
+			reqs := f.received("disconnect")
+			if len(reqs) != 1 {
+				t.Fatalf("expected one disconnect request, got %d", len(reqs))
+			}
+			return reqs[0].(*godap.DisconnectRequest).Arguments.TerminateDebuggee
+		}
+		if !terminated(launchedFake) {
+			t.Error("expected the launched debuggee to be terminated")
+		}
+		if got := terminated(attachedFake); got != terminateAttached {
+			t.Errorf("terminateAttached=%v: expected attached debuggee terminate=%v, got %v", terminateAttached, terminateAttached, got)
+		}
+	}
+}
+
+// TestAdapterLog verifies captured adapter output keeps recent lines and a short tail for errors.
+func TestAdapterLog(t *testing.T) {
+	log := dap.NewAdapterLog()
+	for i := 0; i < 600; i++ {
+		_, _ = fmt.Fprintf(log, "line %d\n", i)
+	}
+	_, _ = log.Write([]byte("partial"))
+
+	lines := log.Lines()
+	if len(lines) != 501 || lines[0] != "line 100" || lines[500] != "partial" {
+		t.Errorf("expected lines 100-599 plus partial, got %d lines starting %q", len(lines), lines[0])
+	}
+	if log.Dropped() != 100 {
+		t.Errorf("expected 100 dropped lines, got %d", log.Dropped())
+	}
+	if tail := log.Tail(); len(tail) != 20 || tail[19] != "partial" {
+		t.Errorf("expected 20-line tail ending with partial, got %v", tail)
+	}
+}
+
+// TestSessionManager_ReplaceSessionAdapter verifies relaunching swaps the client and counts restarts.
+func TestSessionManager_ReplaceSessionAdapter(t *testing.T) {
+	sm := dap.NewSessionManager(10, 30*time.Minute)
+	defer sm.Close()
+
+	session, _ := sm.CreateSession(types.LanguagePython, "/path/to/program.py")
+	_ = sm.SetSessionDebuggee(session.ID, 1234, "program.py")
+	_ = sm.UpdateSessionStatus(session.ID, types.SessionStatusTerminated)
+
+	restarts, err := sm.ReplaceSessionAdapter(session.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("ReplaceSessionAdapter failed: %v", err)
+	}
+	if restarts != 1 {
+		t.Errorf("expected 1 restart, got %d", restarts)
+	}
+
+	info := session.GetInfo()
+	if info.Restarts != 1 || info.Status != types.SessionStatusRunning || info.DebuggeePID != 0 {
+		t.Errorf("expected running session with 1 restart and no stale debuggee, got %+v", info)
+	}
+
+	if _, err := sm.ReplaceSessionAdapter("missing", nil, nil); err == nil {
+		t.Error("expected error for unknown session")
+	}
+}
+
+// TestSession_OnEnd verifies cleanups registered on a session run once when it ends.
+func TestSession_OnEnd(t *testing.T) {
+	sm := dap.NewSessionManager(10, 30*time.Minute)
+	defer sm.Close()
+
+	session, err := sm.CreateSession(types.LanguageGo, "/path/to/main.go")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	calls := 0
+	session.OnEnd(func() { calls++ })
+
+	if err := sm.TerminateSession(session.ID, true); err != nil {
+		t.Fatalf("TerminateSession failed: %v", err)
+	}
+	_ = sm.TerminateSession(session.ID, true)
+	if calls != 1 {
+		t.Errorf("expected the cleanup to run once, ran %d times", calls)
+	}
+}
+
+// TestSession_ClientSwap verifies the client can be replaced, as a restart
+// does, while other goroutines read it (run with -race).
+func TestSession_ClientSwap(t *testing.T) {
+	sm := dap.NewSessionManager(10, 30*time.Minute)
+	defer sm.Close()
+
+	session, err := sm.CreateSession(types.LanguageGo, "/path/to/program")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	_, first := newFakeAdapter(t)
+	_, second := newFakeAdapter(t)
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			client := first
+			if i%2 == 1 {
+				client = second
+			}
+			_ = sm.SetSessionClient(session.ID, client)
+			session.SetFocus(1, 1000)
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		if client := session.GetClient(); client != nil && client != first && client != second {
+			t.Fatal("GetClient returned a client that was never set")
+		}
+		session.Focus()
+	}
+	<-done
+
+	if got := session.GetClient(); got != second {
+		t.Error("expected the last client set")
+	}
+}